@@ -0,0 +1,144 @@
+// cloud-sim stands in for the go-reachy cloud during local development. It
+// accepts robot connections, logs and optionally records what they send,
+// and sends commands typed on stdin or from the web UI:
+//
+//	go run ./cmd/cloud-sim -addr :8080 -record session.jsonl
+//	go run ./cmd/go-eva -mock -cloud ws://localhost:8080/ws/robot
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/cloudsim"
+)
+
+var (
+	addr       = flag.String("addr", ":8080", "listen address")
+	path       = flag.String("path", "/ws/robot", "robot WebSocket path")
+	record     = flag.String("record", "", "append every message to this JSONL file")
+	token      = flag.String("token", "", "require this bearer token from robots")
+	hmacSecret = flag.String("hmac-secret", "", "verify hello signatures with this secret")
+	follow     = flag.Bool("follow", false, "turn the head toward speech like the real cloud")
+	noBinary   = flag.Bool("no-binary", false, "refuse binary frames (robots fall back to base64 JSON)")
+	debug      = flag.Bool("debug", false, "log every DOA, frame and mic message")
+	protoVer   = flag.Int("protocol", 0, "announce this protocol version instead of negotiating (to test incompatible clouds)")
+)
+
+func main() {
+	flag.Parse()
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	var rec *cloudsim.Recorder
+	if *record != "" {
+		var err error
+		if rec, err = cloudsim.OpenRecorder(*record); err != nil {
+			logger.Error("recording unavailable", "error", err)
+			os.Exit(1)
+		}
+		defer rec.Close()
+	}
+
+	cfg := cloudsim.DefaultConfig()
+	cfg.Path = *path
+	cfg.Token = *token
+	cfg.HMACSecret = *hmacSecret
+	cfg.Follow = *follow
+	cfg.BinaryFrames = !*noBinary
+	cfg.Protocol = *protoVer
+	sim := cloudsim.New(cfg, rec, logger)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           sim,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info("cloud-sim listening", "addr", *addr, "robot_path", cfg.Path, "record", *record)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("cloud-sim failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	go readCommands(sim)
+
+	// Keep the recording current for tail -f
+	if rec != nil {
+		go func() {
+			for range time.Tick(time.Second) {
+				rec.Flush()
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv.Shutdown(ctx)
+}
+
+// readCommands sends commands typed on stdin
+func readCommands(sim *cloudsim.Server) {
+	fmt.Println(cloudsim.CommandHelp)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// "@robot-id command" addresses one robot
+		robotID := ""
+		if strings.HasPrefix(line, "@") {
+			id, rest, _ := strings.Cut(line[1:], " ")
+			robotID, line = id, strings.TrimSpace(rest)
+		}
+
+		switch line {
+		case "":
+			continue
+		case "help":
+			fmt.Println(cloudsim.CommandHelp)
+			continue
+		case "robots":
+			robots := sim.Robots()
+			if len(robots) == 0 {
+				fmt.Println("no robots connected")
+			}
+			for _, r := range robots {
+				fmt.Printf("%s  version=%s  since=%s  received=%v  acks=%d  nacks=%d\n",
+					r.ID, r.Version, r.ConnectedAt.Format(time.TimeOnly), r.Received, r.Acks, r.Nacks)
+			}
+			continue
+		}
+
+		msg, err := cloudsim.ParseCommand(line)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		sent, err := sim.Send(robotID, msg)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		fmt.Printf("sent %s %s to %d robot(s)\n", msg.Type, msg.ID, sent)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/calibration"
+)
+
+// runCalibrate runs a distance (-distance) or mounting (-bearing) calibration
+// against the live source and saves it to calibration.file, or shows or
+// resets the stored one. The daemon picks it up on its next start.
+func runCalibrate(args []string) error {
+	fs, tf := newToolFlags("calibrate", "", "Run a distance or mounting calibration")
+	distance := fs.Float64("distance", 0, "speaker distance in meters, for a distance calibration")
+	bearing := fs.Float64("bearing", math.NaN(), "speaker bearing in degrees (0 = front, + = left), for a mounting calibration")
+	duration := fs.Duration("duration", 0, "how long to collect speech (0 = calibration.duration)")
+	reset := fs.Bool("reset", false, "discard the stored calibration")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, logger, err := tf.setup()
+	if err != nil {
+		return err
+	}
+
+	measure := *distance != 0 || !math.IsNaN(*bearing)
+	if !measure {
+		calibrator, err := loadCalibration(cfg, logger)
+		if err != nil {
+			return err
+		}
+		if *reset {
+			if err := calibrator.Reset(); err != nil {
+				return err
+			}
+			fmt.Println("Calibration reset")
+		}
+		printCalibration(calibrator.Status())
+		return nil
+	}
+	if *distance != 0 && !math.IsNaN(*bearing) {
+		return errors.New("give either -distance or -bearing, not both")
+	}
+
+	// Calibrating against a fallback (mock) source would save nonsense
+	if tf.source == "" {
+		tf.source = cfg.Audio.Transport
+	}
+	source, err := tf.openSource(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tracker, calibrator := startTracker(ctx, cfg, source, logger)
+	if calibrator == nil {
+		return fmt.Errorf("stored calibration in %s is unreadable; fix or remove it first", cfg.Calibration.File)
+	}
+	results := tracker.Subscribe()
+
+	if *distance != 0 {
+		err = calibrator.Start(*distance, *duration)
+		fmt.Printf("Speak from %.2f m in front of the robot\n", *distance)
+	} else {
+		err = calibrator.StartMounting(*bearing*math.Pi/180, *duration)
+		fmt.Printf("Speak from %.0f° (0 = front, + = left)\n", *bearing)
+	}
+	if err != nil {
+		return err
+	}
+	go calibrator.Run(ctx, results)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.New("interrupted; nothing saved")
+		case <-ticker.C:
+		}
+
+		status := calibrator.Status()
+		switch status.State {
+		case calibration.StateCollecting:
+			fmt.Printf("  %d speech samples, %.0fs left\n", status.Samples, float64(status.RemainingMs)/1000)
+		case calibration.StateFailed:
+			return fmt.Errorf("calibration failed: %s", status.Error)
+		default:
+			fmt.Println("Calibration saved; restart go-eva to apply it")
+			printCalibration(status)
+			return nil
+		}
+	}
+}
+
+// printCalibration shows the active distance and mounting fit
+func printCalibration(status calibration.Status) {
+	if status.Default {
+		fmt.Printf("Reference energy: %.4g (factory default)\n", status.ReferenceEnergy)
+	} else {
+		fmt.Printf("Reference energy: %.4g (%d points)\n", status.ReferenceEnergy, len(status.Points))
+	}
+	for _, p := range status.Points {
+		fmt.Printf("  %.2f m  k %.4g  %d samples  %s\n", p.Distance, p.K, p.Samples, p.At.Format(time.RFC3339))
+	}
+
+	source := "configured"
+	if status.MountingFit != nil {
+		source = "calibrated"
+	}
+	fmt.Printf("Mounting: offset %+.1f°, mirror %v (%s)\n", degrees(status.Mounting.Offset), status.Mounting.Mirror, source)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/calibration"
+	"github.com/teslashibe/go-eva/internal/config"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/xvf3800"
+)
+
+// command is a go-eva subcommand. The tools open the DOA source themselves,
+// so they need the daemon stopped when the device is USB or I2C.
+type command struct {
+	name    string
+	args    string // Positional arguments, for usage
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the subcommands; running go-eva without one starts the
+// daemon, so existing service files keep working
+var commands = []command{
+	{name: "run", summary: "Run the daemon (the default)"},
+	{name: "doa", summary: "Print DOA readings from the source", run: runDOA},
+	{name: "probe", summary: "Find the XVF3800 and check its firmware and parameters", run: runProbe},
+	{name: "calibrate", summary: "Run a distance or mounting calibration", run: runCalibrate},
+	{name: "replay", args: "<file>", summary: "Run a recorded session through the tracker", run: runReplay},
+}
+
+func main() {
+	flag.Usage = usage
+
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		runDaemon(args)
+		return
+	}
+
+	name := args[0]
+	if name == "help" {
+		usage()
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if cmd.run == nil {
+			runDaemon(args[1:])
+			return
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "go-eva %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "go-eva: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// usage prints the subcommands and the daemon's flags
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: go-eva [command] [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nRun \"go-eva <command> -h\" for a command's flags. Daemon flags:\n")
+	flag.PrintDefaults()
+}
+
+// toolFlags are the flags every tool subcommand takes
+type toolFlags struct {
+	config string
+	source string
+	debug  bool
+}
+
+// newToolFlags creates a subcommand's flag set with the shared flags
+func newToolFlags(name, args, summary string) (*flag.FlagSet, *toolFlags) {
+	fs := flag.NewFlagSet("go-eva "+name, flag.ContinueOnError)
+	tf := &toolFlags{}
+	fs.StringVar(&tf.config, "config", "/etc/go-eva/config.yaml", "config file path")
+	fs.StringVar(&tf.source, "source", "", "DOA source (overrides source.backend): auto, usb, i2c, python, mock, wave or replay:<file>")
+	fs.BoolVar(&tf.debug, "debug", false, "log debug messages")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-eva %s [flags] %s\n\n%s.\n\nFlags:\n", name, args, summary)
+		fs.PrintDefaults()
+	}
+	return fs, tf
+}
+
+// setup loads and validates the config and builds a logger that only
+// reports problems, so it doesn't drown the tool's output
+func (tf *toolFlags) setup() (*config.Config, *slog.Logger, error) {
+	cfg, err := config.Load(tf.config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load config from %s: %v\n", tf.config, err)
+		cfg = config.Default()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	level := slog.LevelWarn
+	if tf.debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	return cfg, logger, nil
+}
+
+// openSource opens -source, or the source the daemon would use
+func (tf *toolFlags) openSource(cfg *config.Config, logger *slog.Logger) (doa.Source, error) {
+	spec := cfg.Source.Backend
+	if tf.source != "" {
+		spec = tf.source
+	}
+	return xvf3800.Open(sourceSpec(cfg, spec), sourceOptions(cfg), logger)
+}
+
+// signalContext is cancelled on SIGINT or SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// startTracker runs a tracker over source until ctx is cancelled, with the
+// stored calibration applied so angles match the daemon's. The calibrator is
+// nil if the stored calibration couldn't be read.
+func startTracker(ctx context.Context, cfg *config.Config, source doa.Source, logger *slog.Logger) (*doa.Tracker, *calibration.Calibrator) {
+	calibrator, err := loadCalibration(cfg, logger)
+	if err != nil {
+		logger.Warn("calibration unavailable, using defaults", "error", err)
+	}
+	trackerCfg := trackerConfig(cfg.Audio)
+	if trackerCfg.Processors, err = trackerProcessors(cfg.Audio, trackerCfg); err != nil {
+		logger.Warn("tracker processors unavailable, running without", "error", err)
+	}
+	tracker := doa.NewTracker(source, trackerCfg, logger)
+	go tracker.Run(ctx)
+	return tracker, calibrator
+}
+
+// formatResult renders a tracker result as one terminal line
+func formatResult(r doa.Result) string {
+	speaking := "quiet"
+	if r.SpeakingLatched {
+		speaking = "speaking"
+	}
+	line := fmt.Sprintf("%s  angle %+7.1f°  smoothed %+7.1f°  conf %.2f  %-8s  energy %.3g",
+		r.Timestamp.Format("15:04:05.000"),
+		degrees(r.Angle),
+		degrees(r.SmoothedAngle),
+		r.Confidence,
+		speaking,
+		r.TotalEnergy,
+	)
+	if r.Zone != "" {
+		line += "  zone " + r.Zone
+	}
+	if r.SpeakerID != 0 {
+		line += fmt.Sprintf("  speaker %d", r.SpeakerID)
+	}
+	return line
+}
+
+// degrees converts radians for display
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// waitFor polls fn until it succeeds or timeout passes, returning the last
+// error
+func waitFor(ctx context.Context, timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := fn()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
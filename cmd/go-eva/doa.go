@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runDOA prints tracker results from the DOA source: the first one, or
+// every one until interrupted with -watch
+func runDOA(args []string) error {
+	fs, tf := newToolFlags("doa", "", "Print DOA readings from the source")
+	watch := fs.Bool("watch", false, "keep printing readings until interrupted")
+	asJSON := fs.Bool("json", false, "print results as JSON lines (replayable with go-eva replay)")
+	timeout := fs.Duration("timeout", 5*time.Second, "how long to wait for the first reading")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, logger, err := tf.setup()
+	if err != nil {
+		return err
+	}
+	source, err := tf.openSource(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tracker, _ := startTracker(ctx, cfg, source, logger)
+	results := tracker.Subscribe()
+	if !*asJSON {
+		fmt.Fprintf(os.Stderr, "Reading from %s (healthy: %v)\n", source.Name(), source.Healthy())
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	first := time.After(*timeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-first:
+			return errors.New("no reading from the source; see -debug")
+		case r := <-results:
+			first = nil
+			if *asJSON {
+				if err := enc.Encode(r); err != nil {
+					return err
+				}
+			} else {
+				fmt.Println(formatResult(r))
+			}
+			if !*watch {
+				return nil
+			}
+		}
+	}
+}
@@ -4,21 +4,54 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"math"
+	"net"
 	"os"
 	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/teslashibe/go-eva/internal/animation"
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/behavior"
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/calibration"
 	"github.com/teslashibe/go-eva/internal/camera"
+	"github.com/teslashibe/go-eva/internal/choreo"
 	"github.com/teslashibe/go-eva/internal/cloud"
 	"github.com/teslashibe/go-eva/internal/config"
 	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/expression"
+	"github.com/teslashibe/go-eva/internal/gesture"
+	evagrpc "github.com/teslashibe/go-eva/internal/grpc"
+	"github.com/teslashibe/go-eva/internal/health"
+	"github.com/teslashibe/go-eva/internal/identity"
+	"github.com/teslashibe/go-eva/internal/influx"
+	"github.com/teslashibe/go-eva/internal/latency"
+	"github.com/teslashibe/go-eva/internal/micdiag"
 	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/privacy"
 	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/recorder"
 	"github.com/teslashibe/go-eva/internal/server"
+	"github.com/teslashibe/go-eva/internal/state"
+	"github.com/teslashibe/go-eva/internal/store"
+	"github.com/teslashibe/go-eva/internal/stt"
+	"github.com/teslashibe/go-eva/internal/systemd"
+	"github.com/teslashibe/go-eva/internal/trace"
+	"github.com/teslashibe/go-eva/internal/tts"
+	"github.com/teslashibe/go-eva/internal/vision"
+	"github.com/teslashibe/go-eva/internal/webhook"
 	"github.com/teslashibe/go-eva/internal/xvf3800"
 )
 
@@ -27,13 +60,18 @@ var (
 	configPath  = flag.String("config", "/etc/go-eva/config.yaml", "config file path")
 	showVersion = flag.Bool("version", false, "print version and exit")
 	debug       = flag.Bool("debug", false, "enable debug logging")
-	useMock     = flag.Bool("mock", false, "use mock DOA source (for testing)")
+	useMock     = flag.Bool("mock", false, "use the sweeping mock DOA source (same as -source=wave)")
+	sourceFlag  = flag.String("source", "", "DOA source (overrides source.backend): auto, usb, python, mock, wave or replay:<file.jsonl|file.csv>")
+	replaySpeed = flag.Float64("replay-speed", 1, "replay playback rate (1 = original timing, 0 = one reading per poll; overrides source.replay.speed)")
+	replayLoop  = flag.Bool("replay-loop", false, "restart the replay when it reaches the end (overrides source.replay.loop)")
 	cloudURL    = flag.String("cloud", "", "cloud WebSocket URL (overrides config)")
 	pollenURL   = flag.String("pollen", "", "Pollen daemon URL (overrides config)")
 )
 
-func main() {
-	flag.Parse()
+// runDaemon runs the full daemon; args are the flags after the program name
+// (or after "run")
+func runDaemon(args []string) {
+	flag.CommandLine.Parse(args)
 
 	if *showVersion {
 		fmt.Printf("go-eva %s\n", version)
@@ -53,6 +91,7 @@ func main() {
 	}
 	if *cloudURL != "" {
 		cfg.Cloud.URL = *cloudURL
+		cfg.Cloud.URLs = nil
 		cfg.Cloud.Enabled = true
 	}
 	if *pollenURL != "" {
@@ -79,14 +118,44 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Initialize DOA source
-	var source doa.Source
+	// Initialize DOA source: -source, then -mock, then source.backend
+	spec := cfg.Source.Backend
 	if *useMock {
-		logger.Info("using mock DOA source")
-		source = xvf3800.NewMockSourceWithWave()
-	} else {
-		logger.Info("initializing DOA source")
-		source = xvf3800.NewSourceWithFallback(logger)
+		spec = "wave"
+	}
+	if *sourceFlag != "" {
+		spec = *sourceFlag
+	}
+	spec = sourceSpec(cfg, spec)
+	usbMetrics := xvf3800.NewUSBMetrics()
+	sourceOpts := sourceOptions(cfg)
+	sourceOpts.USB.Metrics = usbMetrics
+	if flagSet("replay-speed") {
+		sourceOpts.Replay.Speed = *replaySpeed
+	}
+	if flagSet("replay-loop") {
+		sourceOpts.Replay.Loop = *replayLoop
+	}
+
+	logger.Info("initializing DOA source", "source", spec)
+	source, err := xvf3800.Open(spec, sourceOpts, logger)
+	if err != nil {
+		logger.Error("no DOA source available", "source", spec, "error", err)
+		os.Exit(1)
+	}
+	if replaySource, ok := source.(*xvf3800.ReplaySource); ok {
+		logger.Info("replaying recorded DOA session",
+			"file", strings.TrimPrefix(spec, "replay:"),
+			"readings", replaySource.Len(),
+			"speed", sourceOpts.Replay.Speed,
+		)
+
+		// A finished replay ends the run, so regression sessions exit on their own
+		go func() {
+			<-replaySource.Done()
+			logger.Info("replay finished, shutting down")
+			syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		}()
 	}
 	defer source.Close()
 
@@ -95,153 +164,1554 @@ func main() {
 		"healthy", source.Healthy(),
 	)
 
-	// Create tracker configuration from config
-	trackerCfg := doa.TrackerConfig{
-		PollInterval:     time.Duration(1000/cfg.Audio.PollHz) * time.Millisecond,
-		SpeakingLatchDur: time.Duration(cfg.Audio.SpeakingLatchMs) * time.Millisecond,
-		EMAAlpha:         cfg.Audio.EMAAlpha,
-		HistorySize:      cfg.Audio.HistorySize,
-		Confidence: doa.ConfidenceConfig{
-			Base:           cfg.Audio.Confidence.Base,
-			SpeakingBonus:  cfg.Audio.Confidence.SpeakingBonus,
-			StabilityBonus: cfg.Audio.Confidence.StabilityBonus,
-		},
+	// Probe the device on its own schedule, independent of DOA polling
+	var prober *xvf3800.Prober
+	if ctrl, ok := source.(xvf3800.ParamController); ok && cfg.Source.Health.Enabled {
+		prober = xvf3800.NewProber(ctrl, xvf3800.ProberConfig{
+			Interval:  cfg.Source.Health.Interval,
+			Timeout:   cfg.Source.Health.Timeout,
+			FailAfter: cfg.Source.Health.FailAfter,
+		}, logger)
+		go prober.Run(ctx)
+	}
+
+	// Load the stored distance and mounting calibration before the first reading
+	calibrator, err := loadCalibration(cfg, logger)
+	if err != nil {
+		logger.Warn("distance calibration unavailable, using default", "error", err)
+	}
+
+	// Create tracker configuration from config
+	trackerCfg := trackerConfig(cfg.Audio)
+	trackerCfg.Processors, err = trackerProcessors(cfg.Audio, trackerCfg)
+	if err != nil {
+		logger.Error("invalid tracker processor", "error", err)
+		os.Exit(1)
+	}
+
+	// Subsystems exchange events over the bus instead of direct callbacks
+	events := bus.New(logger)
+
+	// Create tracker
+	tracker := doa.NewTracker(source, trackerCfg, logger)
+	tracker.PublishTo(events)
+
+	// Pick up where the last run left off so the head doesn't snap to front
+	if p := cfg.Audio.Persist; p.Enabled {
+		snap, err := doa.LoadSnapshot(p.File)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			logger.Warn("tracker state unavailable", "error", err)
+		case tracker.Restore(snap, p.MaxAge):
+			logger.Info("restored tracker state",
+				"saved_at", snap.SavedAt,
+				"angle", snap.SmoothedAngle,
+				"speakers", len(snap.Speakers),
+			)
+		default:
+			logger.Info("tracker state is stale or from another mounting, ignoring", "saved_at", snap.SavedAt)
+		}
+	}
+
+	// Stage latencies from sound capture to head move (GET /api/latency)
+	lat := latency.NewRecorder(latency.DefaultConfig())
+	bus.Handle(ctx, events, doa.TopicResults, 0, func(r doa.Result) {
+		lat.Since(latency.StageTrack, r.Timestamp)
+	})
+
+	// Start tracker in background
+	go func() {
+		if err := tracker.Run(ctx); err != nil && err != context.Canceled {
+			logger.Error("tracker error", "error", err)
+		}
+	}()
+
+	if calibrator != nil {
+		go calibrator.Run(ctx, bus.Subscribe(events, doa.TopicResults, 0).C)
+	}
+
+	// Initialize Pollen client
+	pollenCfg := pollen.Config{
+		BaseURL:     cfg.Pollen.BaseURL,
+		Timeout:     cfg.Pollen.Timeout,
+		RateLimitHz: cfg.Pollen.RateLimitHz,
+	}
+	if cfg.Pollen.Breaker.Enabled {
+		pollenCfg.BreakerFailures = cfg.Pollen.Breaker.Failures
+		pollenCfg.BreakerCooldown = cfg.Pollen.Breaker.Cooldown
+	}
+	pollenClient := pollen.NewClient(pollenCfg, logger)
+	defer pollenClient.Close()
+
+	// All head and antenna commands go through the safety limiter
+	deg := math.Pi / 180
+	safetyCfg := pollen.SafetyConfig{
+		Enabled:         cfg.Pollen.Safety.Enabled,
+		Yaw:             pollen.JointLimit{Min: cfg.Pollen.Safety.YawMin * deg, Max: cfg.Pollen.Safety.YawMax * deg},
+		Pitch:           pollen.JointLimit{Min: cfg.Pollen.Safety.PitchMin * deg, Max: cfg.Pollen.Safety.PitchMax * deg},
+		Roll:            pollen.JointLimit{Min: cfg.Pollen.Safety.RollMin * deg, Max: cfg.Pollen.Safety.RollMax * deg},
+		MaxVelocity:     cfg.Pollen.Safety.MaxVelocity * deg,
+		MaxAcceleration: cfg.Pollen.Safety.MaxAcceleration * deg,
+		Rate:            time.Second / time.Duration(cfg.Pollen.Safety.RateHz),
+	}
+	motor := pollen.NewLimiter(pollenClient, safetyCfg, logger)
+	go motor.Run(ctx)
+
+	// Tamper-evident record of every motor and emotion command
+	var auditLog *audit.Log
+	if cfg.Audit.Enabled {
+		auditLog, err = audit.Open(audit.Config{
+			Dir:          cfg.Audit.Dir,
+			MaxFileBytes: cfg.Audit.MaxFileBytes,
+			MaxFiles:     cfg.Audit.MaxFiles,
+		}, logger)
+		if err != nil {
+			logger.Error("audit log unavailable", "error", err)
+		}
+	}
+
+	// Cached measured pose for relative moves, /api/motor/state and cloud state
+	var pose *pollen.PosePoller
+	if cfg.Pollen.Pose.Enabled {
+		pose = pollen.NewPosePoller(pollenClient, pollen.PoseConfig{
+			Interval:   cfg.Pollen.Pose.Interval,
+			StaleAfter: cfg.Pollen.Pose.StaleAfter,
+			Fallback:   safetyCfg.SafetyLimits(),
+		}, logger)
+		go pose.Run(ctx)
+	}
+
+	// DOA in the world frame: the measured body yaw while the pose is fresh,
+	// otherwise the last commanded one
+	tracker.SetBodyYaw(func() (float64, bool) {
+		if pose != nil {
+			if joints, fresh := pose.Latest(); fresh {
+				return joints.BodyYaw, true
+			}
+		}
+		return motor.BodyYaw(), true
+	})
+
+	// Emotions from every source play one at a time from the local library
+	emotionCfg := emotion.DefaultConfig()
+	emotionCfg.AllowUnknown = cfg.Emotion.AllowUnknown
+	emotionCfg.DefaultDuration = cfg.Emotion.DefaultDuration
+	emotionCfg.QueueSize = cfg.Emotion.QueueSize
+	emotionCfg.IdleAfter = cfg.Emotion.IdleAfter
+	emotionCfg.Overlap = cfg.Emotion.Overlap
+	if len(cfg.Emotion.Library) > 0 {
+		emotionCfg.Library = make([]emotion.Emotion, len(cfg.Emotion.Library))
+		for i, e := range cfg.Emotion.Library {
+			emotionCfg.Library[i] = emotion.Emotion{
+				Name:      e.Name,
+				Duration:  e.Duration,
+				Conflicts: e.Conflicts,
+				Idle:      e.Idle,
+			}
+		}
+	}
+	emotions := emotion.New(emotionCfg, motor, logger)
+	emotions.PublishTo(events)
+	go emotions.Run(ctx)
+	bus.Handle(ctx, events, doa.TopicResults, 0, func(result doa.Result) {
+		if result.SpeakingLatched {
+			emotions.NoteActivity()
+		}
+	})
+
+	// Start procedural antenna animation if enabled
+	var animator *animation.Animator
+	if cfg.Animation.Enabled {
+		animCfg := animation.DefaultConfig()
+		animCfg.Rate = cfg.Animation.Rate
+		animCfg.SwayAmplitude = cfg.Animation.SwayAmplitude
+		animCfg.TwitchGain = cfg.Animation.TwitchGain
+		animCfg.TargetHold = cfg.Animation.TargetHold
+
+		animator = animation.NewAnimator(animCfg, motor, logger)
+
+		bus.Handle(ctx, events, doa.TopicResults, 0, animator.Feed)
+		go animator.Run(ctx)
+
+		logger.Info("antenna animation enabled", "rate", animCfg.Rate)
+	}
+
+	// Start antenna listening feedback if enabled
+	var expr *expression.Expression
+	if cfg.Expression.Enabled {
+		exprCfg := expression.DefaultConfig()
+		exprCfg.Rest = [2]float64{cfg.Expression.Rest[0], cfg.Expression.Rest[1]}
+		exprCfg.Perk = [2]float64{cfg.Expression.Perk[0], cfg.Expression.Perk[1]}
+		exprCfg.Listen = [2]float64{cfg.Expression.Listen[0], cfg.Expression.Listen[1]}
+		exprCfg.PerkHold = cfg.Expression.PerkHold
+		exprCfg.Lean = cfg.Expression.Lean
+		exprCfg.ReleaseAfter = cfg.Expression.ReleaseAfter
+		exprCfg.MaxVelocity = cfg.Expression.MaxVelocity
+
+		// With animation on, poses blend into the procedural motion
+		var actuator expression.Actuator = motor
+		if animator != nil {
+			actuator = animator
+		}
+		expr = expression.New(exprCfg, actuator, logger)
+
+		bus.Handle(ctx, events, doa.TopicResults, 0, expr.Feed)
+		go expr.Run(ctx)
+
+		logger.Info("antenna expression enabled", "lean", exprCfg.Lean)
+	}
+
+	// Start head auto-tracking of the active speaker if enabled
+	var autoTracker *behavior.AutoTracker
+	if cfg.Behavior.AutoTrack.Enabled {
+		trackCfg := behavior.DefaultAutoTrackConfig()
+		trackCfg.DeadBand = cfg.Behavior.AutoTrack.DeadBand * math.Pi / 180
+		trackCfg.MaxVelocity = cfg.Behavior.AutoTrack.MaxVelocity * math.Pi / 180
+		trackCfg.MaxYaw = cfg.Behavior.AutoTrack.MaxYaw * math.Pi / 180
+		trackCfg.MinConfidence = cfg.Behavior.AutoTrack.MinConfidence
+		trackCfg.ReturnAfter = cfg.Behavior.AutoTrack.ReturnAfter
+		if body := cfg.Behavior.AutoTrack.Body; body.Enabled {
+			trackCfg.MaxBodyYaw = body.MaxYaw * math.Pi / 180
+			trackCfg.BodyThreshold = body.Threshold * math.Pi / 180
+			trackCfg.BodyHysteresis = body.Hysteresis * math.Pi / 180
+			trackCfg.BodyMaxVelocity = body.MaxVelocity * math.Pi / 180
+		}
+
+		autoTracker = behavior.NewAutoTracker(trackCfg, motor, logger)
+		autoTracker.SetLatency(lat)
+		if animator != nil {
+			autoTracker.SetAntennaSource(animator.Current)
+		} else if expr != nil {
+			autoTracker.SetAntennaSource(expr.Current)
+		}
+		go autoTracker.Run(ctx, bus.Subscribe(events, doa.TopicResults, 0).C)
+
+		logger.Info("head auto-tracking enabled", "max_velocity_deg", cfg.Behavior.AutoTrack.MaxVelocity)
+	}
+
+	// Initialize audio bridge (speaker playback for cloud audio and local TTS)
+	audioCfg := audio.DefaultConfig()
+	audioCfg.DeviceRate = cfg.Playback.DeviceRate
+	audioCfg.MaxQueue = cfg.Playback.MaxQueue
+	audioCfg.MP3Command = cfg.Playback.MP3Command
+	audioBridge := audio.NewBridge(audioCfg, logger)
+	defer audioBridge.Close()
+	audioBridge.PublishTo(events)
+
+	// Speak audio the cloud may replay by reference
+	var speakCache *audio.Cache
+	if cfg.Playback.Cache.Enabled {
+		speakCache, err = audio.OpenCache(audio.CacheConfig{
+			Dir:      cfg.Playback.Cache.Dir,
+			MaxBytes: cfg.Playback.Cache.MaxBytes,
+		}, logger)
+		if err != nil {
+			logger.Error("speak cache unavailable", "error", err)
+		}
+	}
+
+	// The tracker ignores the robot's own voice while the speaker plays
+	bus.Handle(ctx, events, audio.TopicPlayback, 0, func(ev audio.PlaybackEvent) {
+		tracker.SetPlayback(ev.Active)
+	})
+
+	// Initialize local TTS if enabled
+	var speaker *tts.Speaker
+	if cfg.TTS.Enabled {
+		engine, err := tts.NewEngine(tts.Config{
+			Engine:  cfg.TTS.Engine,
+			Command: cfg.TTS.Command,
+			Voice:   cfg.TTS.Voice,
+			Model:   cfg.TTS.Model,
+			Speed:   cfg.TTS.Speed,
+		})
+		if err != nil {
+			logger.Error("tts unavailable", "error", err)
+		} else {
+			player := audio.PriorityPlayer{Bridge: audioBridge, Priority: audio.PriorityTTS}
+			speaker = tts.NewSpeaker(engine, player, cfg.TTS.Timeout, logger)
+			logger.Info("local tts enabled", "engine", engine.Name())
+		}
+	}
+
+	// Global privacy switch, enforced at the cloud send path
+	privacyGuard := privacy.NewGuard(privacy.Config{
+		ButtonPath:      cfg.Privacy.ButtonPath,
+		ButtonActiveLow: cfg.Privacy.ButtonActiveLow,
+		LEDPath:         cfg.Privacy.LEDPath,
+	}, logger)
+
+	// The face detector is loaded before the cloud client, so the hello only
+	// advertises vision when it can run
+	var faceDetector vision.Detector
+	if cfg.Vision.Enabled && cfg.Camera.Enabled {
+		var err error
+		faceDetector, err = vision.NewDetector(vision.DetectorConfig{
+			CascadePath: cfg.Vision.CascadePath,
+			MinSize:     cfg.Vision.MinFaceSize,
+		})
+		if err != nil {
+			logger.Error("face detection disabled", "error", err)
+		}
+	}
+
+	// Initialize cloud client if enabled
+	var cloudClient *cloud.Client
+	var offlineQueue *cloud.Queue
+	var (
+		cameraClient *camera.Client
+		micStream    *audio.OpusStream // Mic audio from the camera's WebRTC session
+		wake         *audio.WakeDetector
+	)
+
+	if cfg.Cloud.Enabled {
+		logger.Info("cloud mode enabled", "url", cfg.Cloud.URL, "urls", cfg.Cloud.URLs)
+
+		// Create cloud client. The robot ID lets one cloud serve several
+		// robots; the hardware serial identifies the unit across renames.
+		var xvfSerial, firmware string
+		active := source
+		if composite, ok := source.(*xvf3800.CompositeSource); ok {
+			active = composite.Active()
+		}
+		if device, ok := active.(*xvf3800.USBSource); ok {
+			xvfSerial = device.Serial()
+			firmware = device.Firmware().String()
+		}
+		serial, serialSource := identity.HardwareSerial(xvfSerial)
+
+		robotID := cfg.Cloud.RobotID
+		if robotID == "" {
+			robotID, _ = os.Hostname()
+		}
+		if robotID == "" {
+			robotID = serial
+		}
+		logger.Info("robot identity", "robot_id", robotID, "serial", serial, "serial_source", serialSource)
+
+		cloudClient = cloud.NewClient(cloud.Config{
+			URL:              cfg.Cloud.URL,
+			URLs:             cfg.Cloud.URLs,
+			FailoverAfter:    cfg.Cloud.FailoverAfter,
+			PrimaryRetry:     cfg.Cloud.PrimaryRetry,
+			ReconnectBackoff: cfg.Cloud.ReconnectBackoff,
+			MaxBackoff:       cfg.Cloud.MaxBackoff,
+			PingInterval:     cfg.Cloud.PingInterval,
+			ClockSync:        cfg.Cloud.ClockSync,
+			DOABatch:         cfg.Cloud.DOABatch,
+			WriteTimeout:     5 * time.Second,
+			BinaryFrames:     cfg.Cloud.BinaryFrames,
+			RobotID:          robotID,
+			HardwareSerial:   serial,
+			Firmware:         firmware,
+			SerialSource:     serialSource,
+			Capabilities:     capabilities(cfg, faceDetector != nil),
+			Version:          version,
+			AudioCodecs:      cfg.Cloud.AudioCodecs,
+			OpusBitrate:      cfg.Cloud.OpusBitrate,
+			StrictProtocol:   cfg.Cloud.StrictProtocol,
+			SendQueueSize:    cfg.Cloud.Send.QueueSize,
+			SendRate:         cfg.Cloud.Send.Rate,
+			SendBurst:        cfg.Cloud.Send.Burst,
+			Compression: cloud.CompressionConfig{
+				Deflate:       cfg.Cloud.Compression.Deflate,
+				Level:         cfg.Cloud.Compression.Level,
+				GzipThreshold: cfg.Cloud.Compression.GzipThreshold,
+			},
+			Auth: cloud.AuthConfig{
+				Token:      cfg.Cloud.Auth.Token,
+				HMACSecret: cfg.Cloud.Auth.HMACSecret,
+				CertFile:   cfg.Cloud.Auth.CertFile,
+				KeyFile:    cfg.Cloud.Auth.KeyFile,
+				CAFile:     cfg.Cloud.Auth.CAFile,
+			},
+		}, logger)
+		cloudClient.SetSendGate(privacyGuard)
+
+		// Buffer non-video messages while offline and replay them on reconnect
+		if cfg.Cloud.Queue.Enabled {
+			queueCfg := cloud.QueueConfig{
+				Path:        cfg.Cloud.Queue.Path,
+				MaxMessages: cfg.Cloud.Queue.MaxMessages,
+				MaxAge:      cfg.Cloud.Queue.MaxAge,
+				DropPolicy:  cfg.Cloud.Queue.DropPolicy,
+			}
+			offlineQueue, err = cloud.OpenQueue(queueCfg)
+			if err != nil {
+				logger.Warn("offline queue spool unavailable, buffering in memory", "path", queueCfg.Path, "error", err)
+				queueCfg.Path = ""
+				offlineQueue, _ = cloud.OpenQueue(queueCfg)
+			}
+			cloudClient.SetQueue(offlineQueue)
+			logger.Info("offline queue enabled", "queued", offlineQueue.Len())
+		}
+		cloudClient.PublishTo(events)
+
+		// Forward DOA updates to cloud (with enhanced 3D positioning data)
+		go func() {
+			ticker := time.NewTicker(50 * time.Millisecond) // 20 Hz DOA updates
+			defer ticker.Stop()
+
+			var lastSent time.Time
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if cloudClient.IsConnected() || offlineQueue != nil {
+						reading := tracker.GetLatest()
+						data := protocol.DOAData{
+							Angle:           reading.Angle,
+							SmoothedAngle:   reading.SmoothedAngle,
+							Speaking:        reading.Speaking,
+							SpeakingLatched: reading.SpeakingLatched,
+							Confidence:      reading.Confidence,
+							WorldAngle:      reading.WorldAngle,
+							BodyYaw:         reading.BodyYaw,
+							EstX:            reading.EstX,
+							EstY:            reading.EstY,
+							TotalEnergy:     reading.TotalEnergy,
+							MicEnergy:       reading.SpeechEnergy,
+						}
+						if !reading.Timestamp.IsZero() {
+							data.CapturedAt = reading.Timestamp.UnixMilli()
+						}
+						// Resending an unchanged reading would only measure its age
+						if cloudClient.SendDOAData(data) == nil && reading.Timestamp.After(lastSent) {
+							lat.Since(latency.StageUplink, reading.Timestamp)
+							lastSent = reading.Timestamp
+						}
+					}
+				}
+			}
+		}()
+
+		bus.Handle(ctx, events, doa.TopicUtterances, 0, func(ev doa.UtteranceEvent) {
+			if !cloudClient.IsConnected() && offlineQueue == nil {
+				return
+			}
+			data := protocol.UtteranceData{
+				Event:      string(ev.Type),
+				ID:         ev.ID,
+				StartMs:    ev.Start.UnixMilli(),
+				DurationMs: ev.DurationMs,
+				AvgAngle:   ev.AvgAngle,
+				PeakEnergy: ev.PeakEnergy,
+				SpeakerID:  ev.SpeakerID,
+			}
+			if ev.Type == doa.UtteranceEnd {
+				data.EndMs = ev.End.UnixMilli()
+			}
+			if err := cloudClient.SendUtterance(data); err != nil {
+				logger.Debug("utterance send failed", "error", err)
+			}
+		})
+	}
+
+	// Tell the cloud when emotions start and finish so it can time speech
+	// around them; like barge-in, a stale event is meaningless offline
+	bus.Handle(ctx, events, emotion.TopicEvents, 0, func(ev emotion.Event) {
+		if cloudClient == nil || !cloudClient.IsConnected() {
+			return
+		}
+		msgType := protocol.TypeEmotionStarted
+		if ev.Type == emotion.EventFinished {
+			msgType = protocol.TypeEmotionFinished
+		}
+		err := cloudClient.SendEmotionEvent(msgType, protocol.EmotionEventData{
+			ID:         ev.ID,
+			Name:       ev.Name,
+			DurationMs: ev.DurationMs,
+			StartMs:    ev.Start.UnixMilli(),
+			EndMs:      ev.End.UnixMilli(),
+			Idle:       ev.Idle,
+			Error:      ev.Error,
+		})
+		if err != nil {
+			logger.Debug("emotion event send failed", "error", err)
+		}
+	})
+
+	// Someone talking over the robot's own playback takes the floor
+	bus.Handle(ctx, events, doa.TopicBargeIn, 0, func(ev doa.BargeInEvent) {
+		var interrupted bool
+		var flushed int
+		if cfg.Audio.Echo.InterruptPlayback {
+			interrupted, flushed = audioBridge.StopPlayback(true)
+		}
+		logger.Info("barge-in", "angle", ev.Angle, "energy", ev.Energy, "interrupted", interrupted, "flushed", flushed)
+
+		// A stale barge-in is meaningless, so it is never queued offline
+		if cloudClient == nil || !cloudClient.IsConnected() {
+			return
+		}
+		err := cloudClient.SendBargeIn(protocol.BargeInData{
+			Angle:       ev.Angle,
+			Energy:      ev.Energy,
+			EchoLevel:   ev.EchoLevel,
+			Timestamp:   ev.Timestamp.UnixMilli(),
+			Interrupted: interrupted,
+			Flushed:     flushed,
+		})
+		if err != nil {
+			logger.Debug("barge-in send failed", "error", err)
+		}
+	})
+
+	// Initialize camera client if enabled; frames feed the local preview
+	// endpoints and, in cloud mode, the cloud connection
+	if cfg.Camera.Enabled {
+		logger.Info("camera capture enabled",
+			"framerate", cfg.Camera.Framerate,
+			"resolution", fmt.Sprintf("%dx%d", cfg.Camera.Width, cfg.Camera.Height),
+		)
+
+		cameraClient = camera.NewClient(camera.Config{
+			PollenURL:        cfg.Pollen.BaseURL,
+			Framerate:        cfg.Camera.Framerate,
+			Width:            cfg.Camera.Width,
+			Height:           cfg.Camera.Height,
+			Quality:          cfg.Camera.Quality,
+			Timeout:          2 * time.Second,
+			SnapshotFallback: cfg.Camera.SnapshotFallback,
+		}, logger)
+
+		cameraClient.PublishTo(events)
+		if cfg.Camera.Annotate.Enabled {
+			cameraClient.SetAnnotator(camera.NewAnnotator(camera.AnnotateConfig{
+				FOV: cfg.Camera.FOVDeg * math.Pi / 180,
+			}, func() camera.Bearing {
+				latest := tracker.GetLatest()
+				return camera.Bearing{Angle: latest.SmoothedAngle, Confidence: latest.Confidence, Speaking: latest.Speaking}
+			}))
+		}
+
+		// Capture the mic from the WebRTC audio track instead of arecord
+		if cfg.Capture.Source == "webrtc" {
+			micStream, err = audio.NewOpusStream(audioCfg.SampleRate, audioCfg.Channels)
+			if err != nil {
+				logger.Error("webrtc mic capture unavailable, using arecord", "error", err)
+			} else {
+				defer micStream.Close()
+				cameraClient.OnAudio(micStream.WritePacket)
+				audioBridge.SetCaptureSource(micStream)
+				logger.Info("mic capture from webrtc audio track")
+			}
+		}
+
+		if err := cameraClient.Start(ctx); err != nil {
+			logger.Error("camera start failed", "error", err)
+		}
+	}
+
+	// Face detection fused with DOA for audio-visual speaker localization
+	var (
+		faceWorker *vision.Worker
+		fuser      *vision.Fuser
+	)
+	if faceDetector != nil && cameraClient != nil {
+		fov := cfg.Camera.FOVDeg * math.Pi / 180
+		faceWorker = vision.NewWorker(faceDetector, vision.Config{
+			FOV:           fov,
+			Interval:      cfg.Vision.Interval,
+			MinConfidence: cfg.Vision.MinConfidence,
+		}, logger)
+		faceWorker.PublishTo(events)
+		go faceWorker.Run(ctx, bus.Subscribe(events, camera.TopicFrames, 1).C)
+
+		fuser = vision.NewFuser(vision.FusionConfig{
+			FOV:           fov,
+			MatchAngle:    cfg.Vision.MatchDeg * math.Pi / 180,
+			MaxFaceAge:    cfg.Vision.MaxFaceAge,
+			UnseenPenalty: cfg.Vision.UnseenPenalty,
+		})
+		fuser.PublishTo(events)
+		bus.Handle(ctx, events, doa.TopicResults, 0, func(result doa.Result) {
+			fuser.Update(result, faceWorker.Latest())
+		})
+		logger.Info("face detection enabled", "interval", cfg.Vision.Interval)
+	}
+
+	// Cloud commands: privacy, motion, emotions and speech
+	bus.Handle(ctx, events, cloud.TopicPrivacyCommands, 0, func(cmd protocol.PrivacyCommand) {
+		privacyGuard.Set(cmd.Enabled, "cloud")
+	})
+
+	// Live reconfiguration from cloud config messages, acknowledged with the
+	// settings in effect (the mic codec is applied by the cloud client)
+	if cloudClient != nil {
+		bus.Handle(ctx, events, cloud.TopicConfigUpdates, 0, func(update protocol.ConfigUpdate) {
+			var ack protocol.ConfigAppliedData
+			if cc := update.Camera; cc != nil {
+				if cameraClient == nil {
+					ack.Error = "camera disabled"
+				} else {
+					applied, err := cameraClient.Reconfigure(cc.Preset, camera.Settings{
+						Framerate: cc.Framerate,
+						Width:     cc.Width,
+						Height:    cc.Height,
+						Quality:   cc.Quality,
+					})
+					if err != nil {
+						ack.Error = err.Error()
+						logger.Warn("camera reconfigure rejected", "error", err)
+					}
+					ack.Camera = &protocol.CameraConfig{
+						Framerate: applied.Framerate,
+						Width:     applied.Width,
+						Height:    applied.Height,
+						Quality:   applied.Quality,
+					}
+				}
+			}
+			if update.Audio != nil {
+				ack.Audio = &protocol.AudioConfig{MicCodec: cloudClient.MicCodec()}
+			}
+			if err := cloudClient.SendConfigApplied(ack); err != nil {
+				logger.Debug("config ack send failed", "error", err)
+			}
+		})
+	}
+
+	// ackCommand tells the cloud whether a command that asked for an ack
+	// ran, echoing the command's trace ID
+	ackCommand := func(ctx context.Context, id string, err error) {
+		if cloudClient == nil {
+			return
+		}
+		if aerr := cloudClient.AckContext(ctx, id, err); aerr != nil {
+			logger.DebugContext(ctx, "command ack send failed", "id", id, "error", aerr)
+		}
+	}
+
+	// auditCloud records a cloud command and its outcome
+	auditCloud := func(kind audit.Kind, id string, cmd any, err error) {
+		if auditLog == nil {
+			return
+		}
+		if aerr := auditLog.Record(audit.SourceCloud, kind, id, cmd, err); aerr != nil {
+			logger.Warn("audit record failed", "kind", kind, "error", aerr)
+		}
+	}
+
+	// moveHead runs an explicit motor command through the safety limiter.
+	// Explicit commands take priority over local auto-tracking, and their
+	// antenna targets are blended into the animation.
+	moveHead := func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+		if autoTracker != nil {
+			autoTracker.Yield()
+		}
+		if animator != nil {
+			animator.SetTarget(antennas)
+			antennas = animator.Current()
+		}
+		return motor.SetTarget(ctx, head, antennas, bodyYaw)
+	}
+
+	// runMotorCommand carries out a cloud or local motor command
+	runMotorCommand := func(ctx context.Context, cmd protocol.MotorCommand) error {
+		logger.DebugContext(ctx, "received motor command",
+			"yaw", cmd.Head.Yaw,
+			"pitch", cmd.Head.Pitch,
+			"roll", cmd.Head.Roll,
+		)
+
+		head := pollen.HeadTarget{
+			X:     cmd.Head.X,
+			Y:     cmd.Head.Y,
+			Z:     cmd.Head.Z,
+			Yaw:   cmd.Head.Yaw,
+			Pitch: cmd.Head.Pitch,
+			Roll:  cmd.Head.Roll,
+		}
+		return moveHead(ctx, head, cmd.Antennas, cmd.BodyYaw)
+	}
+
+	bus.Handle(ctx, events, cloud.TopicMotorCommands, 0, func(cmd protocol.MotorCommand) {
+		cmdCtx := trace.WithID(ctx, cmd.TraceID)
+		var capturedAt time.Time
+		if cmd.SourceTS > 0 {
+			capturedAt = time.UnixMilli(cmd.SourceTS)
+			lat.Since(latency.StageCloud, capturedAt)
+		}
+
+		start := time.Now()
+		err := runMotorCommand(cmdCtx, cmd)
+		if err != nil {
+			if errors.Is(err, pollen.ErrStopped) {
+				logger.DebugContext(cmdCtx, "motor command ignored: emergency stop engaged")
+			} else {
+				logger.WarnContext(cmdCtx, "motor command failed", "error", err)
+			}
+		} else {
+			lat.Since(latency.StageMotor, start)
+			lat.Since(latency.StageEndToEnd, capturedAt)
+		}
+		auditCloud(audit.KindMotor, cmd.ID, cmd, err)
+		ackCommand(cmdCtx, cmd.ID, err)
+	})
+
+	// Keyframed choreography, interpolated locally and sent through the
+	// same path as cloud motor commands
+	choreoPlayer := choreo.New(choreo.Config{
+		Rate:        time.Second / time.Duration(cfg.Choreo.RateHz),
+		MaxDuration: cfg.Choreo.MaxDuration,
+	}, choreo.MoverFunc(moveHead), logger)
+	go choreoPlayer.Run(ctx)
+
+	bus.Handle(ctx, events, cloud.TopicChoreoCommands, 0, func(cmd protocol.ChoreoCommand) {
+		cmdCtx := trace.WithID(ctx, cmd.TraceID)
+		seq := choreo.Sequence{Name: cmd.Name, Loops: cmd.Loops}
+		for _, k := range cmd.Keyframes {
+			seq.Keyframes = append(seq.Keyframes, choreo.Keyframe{
+				Pose: choreo.Pose{
+					Head: pollen.HeadTarget{
+						X: k.Head.X, Y: k.Head.Y, Z: k.Head.Z,
+						Roll: k.Head.Roll, Pitch: k.Head.Pitch, Yaw: k.Head.Yaw,
+					},
+					Antennas: k.Antennas,
+					BodyYaw:  k.BodyYaw,
+				},
+				DurationMs: k.DurationMs,
+				Easing:     choreo.Easing(k.Easing),
+			})
+		}
+		err := choreoPlayer.Play(seq)
+		if err != nil {
+			logger.WarnContext(cmdCtx, "choreo command failed", "error", err)
+		}
+		auditCloud(audit.KindChoreo, cmd.ID, cmd, err)
+		ackCommand(cmdCtx, cmd.ID, err)
+	})
+
+	bus.Handle(ctx, events, cloud.TopicEmotionCommands, 0, func(cmd protocol.EmotionCommand) {
+		cmdCtx := trace.WithID(ctx, cmd.TraceID)
+		logger.InfoContext(cmdCtx, "queueing emotion", "name", cmd.Name)
+		err := emotions.PlayRequest(cmd.ID, cmd.Name, time.Duration(cmd.Duration*float64(time.Second)))
+		if err != nil {
+			logger.WarnContext(cmdCtx, "emotion command failed", "error", err)
+		}
+		auditCloud(audit.KindEmotion, cmd.ID, cmd, err)
+		ackCommand(cmdCtx, cmd.ID, err)
+	})
+
+	// Speech from the cloud: PCM audio, a reference to cached audio, or text
+	// for local TTS. Commands with an ID are acked once the clip is queued.
+	playSpeak := func(ctx context.Context, data protocol.SpeakData) error {
+		if data.IsText() {
+			if speaker == nil {
+				return fmt.Errorf("local tts disabled")
+			}
+			speaker.SpeakAsync(data.Text)
+			return nil
+		}
+
+		var payload []byte
+		if data.IsCacheRef() {
+			var ok bool
+			if speakCache != nil {
+				payload, ok = speakCache.Get(data.CacheKey)
+			}
+			if !ok {
+				return protocol.ErrSpeakNotCached
+			}
+		} else {
+			var err error
+			if payload, err = data.DecodeSpeakData(); err != nil {
+				return fmt.Errorf("speak data decode failed: %w", err)
+			}
+			if data.Cache && speakCache != nil {
+				if _, err := speakCache.Put(payload); err != nil {
+					logger.WarnContext(ctx, "speak audio not cached", "error", err)
+				}
+			}
+		}
+
+		// Opus packets decode to raw PCM16; containers are unpacked by the bridge
+		format := data.Format
+		if data.Codec == audio.CodecOpus {
+			sampleRate := data.SampleRate
+			if sampleRate == 0 {
+				sampleRate = audio.DefaultConfig().SampleRate
+			}
+			channels := max(data.Channels, 1)
+			var err error
+			if payload, err = audio.DecodePayload(data.Codec, payload, sampleRate, channels); err != nil {
+				return fmt.Errorf("speak audio decode failed (codec %s): %w", data.Codec, err)
+			}
+			format = audio.FormatPCM16
+		}
+
+		decodeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		clip, err := audioBridge.DecodeClip(decodeCtx, format, payload, data.SampleRate, data.Channels)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("speak audio decode failed (format %s): %w", data.Format, err)
+		}
+		priority, err := audio.ParsePriority(data.Priority)
+		if err != nil {
+			logger.WarnContext(ctx, "speak priority ignored", "error", err)
+		}
+		clip.Priority = priority
+		if _, err := audioBridge.Enqueue(clip); err != nil {
+			return fmt.Errorf("speak audio dropped (priority %s): %w", priority, err)
+		}
+		return nil
+	}
+	bus.Handle(ctx, events, cloud.TopicSpeakData, 64, func(data protocol.SpeakData) {
+		cmdCtx := trace.WithID(ctx, data.TraceID)
+		err := playSpeak(cmdCtx, data)
+		if err != nil && !errors.Is(err, protocol.ErrSpeakNotCached) {
+			logger.WarnContext(cmdCtx, "speak command failed", "error", err)
+		}
+		ackCommand(cmdCtx, data.ID, err)
+	})
+
+	bus.Handle(ctx, events, cloud.TopicStopSpeak, 0, func(cmd protocol.StopSpeakCommand) {
+		interrupted, flushed := audioBridge.StopPlayback(!cmd.KeepQueue)
+		logger.Info("playback stopped by cloud", "interrupted", interrupted, "flushed", flushed)
+	})
+
+	// Forward frames to cloud; a small buffer drops stale frames on a slow link
+	var frameChanges *camera.ChangeDetector
+	if cloudClient != nil {
+		if cd := cfg.Camera.ChangeDetection; cd.Enabled {
+			frameChanges = camera.NewChangeDetector(camera.ChangeConfig{
+				Threshold:  cd.Threshold,
+				PixelDelta: cd.PixelDelta,
+				Keepalive:  cd.Keepalive,
+			})
+		}
+		bus.Handle(ctx, events, camera.TopicFrames, 2, func(frame camera.Frame) {
+			if !cloudClient.IsConnected() {
+				if frameChanges != nil {
+					frameChanges.Reset() // Send the first frame after reconnecting
+				}
+				return
+			}
+			if frameChanges != nil && !frameChanges.ShouldSend(frame) {
+				return
+			}
+			if err := cloudClient.SendFrameAt(frame.Width, frame.Height, frame.Data, frame.FrameID, frame.Timestamp); err != nil {
+				logger.Debug("frame send failed", "error", err)
+			}
+		})
+	}
+
+	// Step the camera down while frames back up the uplink
+	var frameAdapter *camera.Adapter
+	if cloudClient != nil && cameraClient != nil && cfg.Camera.Adaptive.Enabled {
+		ad := cfg.Camera.Adaptive
+		frameAdapter = camera.NewAdapter(camera.AdaptConfig{
+			Interval:     ad.Interval,
+			HighDelay:    ad.HighDelay,
+			LowDelay:     ad.LowDelay,
+			RecoverAfter: ad.RecoverAfter,
+			MaxQueue:     ad.MaxQueue,
+			MinFramerate: ad.MinFramerate,
+			MinQuality:   ad.MinQuality,
+		}, cameraClient, func() camera.UplinkLoad {
+			u := cloudClient.Uplink()
+			return camera.UplinkLoad{Connected: u.Connected, QueueLength: u.QueueLength, QueueDelay: u.QueueDelay}
+		}, logger)
+		go frameAdapter.Run(ctx)
+	}
+
+	// Initialize history: the configured store when enabled, else just the
+	// full-rate window behind DOA trend lines and SSE resume
+	historyCfg := store.Config{
+		Backend:        cfg.History.Backend,
+		Path:           cfg.History.Path,
+		SampleInterval: cfg.History.SampleInterval,
+		FlushInterval:  cfg.History.FlushInterval,
+		Retention:      cfg.History.Retention,
+		MaxRows:        cfg.History.MaxRows,
+		Window:         cfg.Audio.HistorySize,
+	}
+	var history store.History = store.NewWindow(historyCfg)
+	if cfg.History.Enabled {
+		var upload store.Uploader
+		if cloudClient != nil {
+			upload = cloudClient.SendHistory
+		}
+		h, err := store.New(historyCfg, upload, logger)
+		if err != nil {
+			logger.Error("history store unavailable", "backend", cfg.History.Backend, "error", err)
+		} else {
+			history = h
+		}
+	}
+
+	// Initialize full-rate DOA recorder if enabled; history then answers
+	// full-rate queries from the recording
+	var doaRecorder *recorder.Recorder
+	if cfg.Recorder.Enabled {
+		doaRecorder, err = recorder.Open(recorder.Config{
+			Dir:             cfg.Recorder.Dir,
+			SegmentDuration: cfg.Recorder.SegmentDuration,
+			Retention:       cfg.Recorder.Retention,
+			MaxBytes:        cfg.Recorder.MaxBytes,
+		}, logger)
+		if err != nil {
+			logger.Error("doa recorder unavailable", "error", err)
+		} else {
+			history = store.WithRecording(history, doaRecorder, logger)
+		}
+	}
+	go store.Run(ctx, history, historyCfg, bus.Subscribe(events, doa.TopicResults, 64).C, logger)
+	if cfg.History.Enabled {
+		store.RecordEvents(ctx, history, events, logger)
+	}
+
+	// Archive camera stills on an interval and when someone starts talking
+	var snapshotArchive *camera.Archive
+	if ar := cfg.Camera.Archive; ar.Enabled && cameraClient != nil {
+		snapshotArchive, err = camera.OpenArchive(camera.ArchiveConfig{
+			Dir:       ar.Dir,
+			Interval:  ar.Interval,
+			MinGap:    ar.MinGap,
+			Retention: ar.Retention,
+			MaxBytes:  ar.MaxBytes,
+		}, logger)
+		if err != nil {
+			logger.Error("camera archive unavailable", "error", err)
+		} else {
+			go snapshotArchive.Run(ctx, bus.Subscribe(events, camera.TopicFrames, 1).C)
+			if ar.OnSpeech {
+				bus.Handle(ctx, events, doa.TopicUtterances, 8, func(ev doa.UtteranceEvent) {
+					if ev.Type == doa.UtteranceStart {
+						snapshotArchive.Trigger("speech")
+					}
+				})
+			}
+		}
+	}
+
+	// Watch the mic channels for dead or degraded mics
+	var micMonitor *micdiag.Monitor
+	if cfg.Audio.Mics.Enabled {
+		micMonitor = micdiag.New(micdiag.Config{
+			Window:    cfg.Audio.Mics.Window,
+			LowRatio:  cfg.Audio.Mics.LowRatio,
+			DeadRatio: cfg.Audio.Mics.DeadRatio,
+		}, logger)
+		go micMonitor.Run(ctx, bus.Subscribe(events, doa.TopicResults, 64).C)
+	}
+
+	// Start line-protocol metrics export if enabled; sources created later
+	// (the wake detector) add themselves once they exist
+	var exporter *influx.Exporter
+	if cfg.Influx.Enabled {
+		exporter = influx.NewExporter(influx.Config{
+			URL:      cfg.Influx.URL,
+			Token:    cfg.Influx.Token,
+			Interval: cfg.Influx.Interval,
+			Prefix:   influx.DefaultConfig().Prefix,
+			Tags:     cfg.Influx.Tags,
+		}, logger)
+
+		exporter.AddSource("tracker", func() map[string]interface{} {
+			return influx.StructFields(tracker.Stats())
+		})
+		exporter.AddSource("pollen", func() map[string]interface{} {
+			return influx.StructFields(pollenClient.GetStats())
+		})
+		if cloudClient != nil {
+			exporter.AddSource("cloud", func() map[string]interface{} {
+				return influx.StructFields(cloudClient.GetStats())
+			})
+		}
+		if cameraClient != nil {
+			exporter.AddSource("camera", func() map[string]interface{} {
+				return influx.StructFields(cameraClient.Stats())
+			})
+		}
+		if doaRecorder != nil {
+			exporter.AddSource("recorder", func() map[string]interface{} {
+				return influx.StructFields(doaRecorder.GetStats())
+			})
+		}
+		if snapshotArchive != nil {
+			exporter.AddSource("camera_archive", func() map[string]interface{} {
+				return influx.StructFields(snapshotArchive.GetStats())
+			})
+		}
+		if auditLog != nil {
+			exporter.AddSource("audit", func() map[string]interface{} {
+				return influx.StructFields(auditLog.GetStats())
+			})
+		}
+		exporter.AddSource("history", func() map[string]interface{} {
+			return influx.StructFields(history.GetStats())
+		})
+		if speakCache != nil {
+			exporter.AddSource("speak_cache", func() map[string]interface{} {
+				return influx.StructFields(speakCache.GetStats())
+			})
+		}
+		if micStream != nil {
+			exporter.AddSource("webrtc_mic", func() map[string]interface{} {
+				return influx.StructFields(micStream.GetStats())
+			})
+		}
+
+		go exporter.Run(ctx)
+	}
+
+	// POST speech, zone and cloud events to home-automation webhooks
+	if cfg.Webhooks.Enabled {
+		hookCfg := webhook.Config{
+			RobotID:        cfg.Cloud.RobotID,
+			Timeout:        cfg.Webhooks.Timeout,
+			MaxRetries:     cfg.Webhooks.MaxRetries,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			QueueSize:      cfg.Webhooks.QueueSize,
+		}
+		if hookCfg.RobotID == "" {
+			hookCfg.RobotID, _ = os.Hostname()
+		}
+		for _, h := range cfg.Webhooks.Hooks {
+			hookCfg.Hooks = append(hookCfg.Hooks, webhook.Hook{URL: h.URL, Events: h.Events, Headers: h.Headers})
+		}
+		dispatcher, err := webhook.NewDispatcher(hookCfg, logger)
+		if err != nil {
+			logger.Error("webhooks unavailable", "error", err)
+		} else {
+			dispatcher.SetPrivacy(privacyGuard)
+			dispatcher.ForwardEvents(ctx, events)
+			go dispatcher.Run(ctx)
+			logger.Info("webhooks enabled", "hooks", len(hookCfg.Hooks))
+		}
+	}
+
+	// Create server
+	srv := server.New(cfg.Server, tracker, logger, version)
+	srv.ForwardEvents(ctx, events)
+	srv.SetHistory(history)
+	if snapshotArchive != nil {
+		srv.SetArchive(snapshotArchive)
+	}
+	if calibrator != nil {
+		srv.SetCalibrator(calibrator)
+	}
+	if micMonitor != nil {
+		srv.SetMics(micMonitor)
+	}
+	if cfg.XVF3800.ControlEnabled {
+		if ctrl, ok := source.(xvf3800.ParamController); ok {
+			params := xvf3800.DefaultParams()
+			if len(cfg.XVF3800.Params) > 0 {
+				params = make([]xvf3800.Param, len(cfg.XVF3800.Params))
+				for i, p := range cfg.XVF3800.Params {
+					params[i] = xvf3800.Param{
+						Name:     p.Name,
+						ResID:    p.ResID,
+						CmdID:    p.CmdID,
+						Type:     xvf3800.ParamType(p.Type),
+						Count:    p.Count,
+						Writable: p.Writable,
+					}
+				}
+			}
+			allow, err := xvf3800.NewAllowlist(params)
+			if err != nil {
+				logger.Error("invalid xvf3800 allowlist", "error", err)
+			} else {
+				srv.SetXVFControl(ctrl, allow)
+				logger.Info("xvf3800 parameter control enabled", "params", len(params))
+
+				if len(cfg.XVF3800.Profiles) > 0 {
+					setupXVFProfiles(ctx, cfg.XVF3800, ctrl, allow, srv, logger)
+				}
+			}
+		} else {
+			logger.Warn("xvf3800 control unavailable for source", "type", source.Name())
+		}
+	}
+	if speaker != nil {
+		srv.SetSpeaker(speaker)
+	}
+	srv.SetPrivacy(privacyGuard)
+
+	// Robot state snapshots for /api/state and the cloud
+	robotState := state.NewAggregator(state.Config{
+		Interval:      cfg.State.Interval,
+		SourceTimeout: time.Second,
+	}, version, logger)
+	robotState.AddSource("tracker", func(ctx context.Context) interface{} {
+		return tracker.Stats()
+	})
+	if pose != nil {
+		robotState.SetPose(func() *protocol.PoseState {
+			joints, _ := pose.Latest()
+			if joints.Timestamp.IsZero() {
+				return nil
+			}
+			return &protocol.PoseState{
+				Head:     protocol.HeadTarget(joints.HeadPose),
+				Antennas: joints.Antennas,
+				BodyYaw:  joints.BodyYaw,
+				AgeMs:    time.Since(joints.Timestamp).Milliseconds(),
+			}
+		})
+	}
+	robotState.AddSource("usb", func(ctx context.Context) interface{} {
+		usb := map[string]interface{}{
+			"source":  source.Name(),
+			"healthy": source.Healthy(),
+		}
+		active := source
+		if composite, ok := source.(*xvf3800.CompositeSource); ok {
+			usb["backends"] = composite.Status()
+			active = composite.Active()
+		}
+		switch device := active.(type) {
+		case *xvf3800.USBSource:
+			usb["device"] = device.Stats()
+		case *xvf3800.I2CSource:
+			usb["device"] = device.Stats()
+		}
+		if prober != nil {
+			usb["probe"] = prober.Status()
+		}
+		return usb
+	})
+	robotState.AddSource("pollen", func(ctx context.Context) interface{} {
+		pollenState := map[string]interface{}{
+			"stats":  pollenClient.GetStats(),
+			"motor":  motor.Status(),
+			"daemon": nil,
+		}
+		if pose != nil {
+			pollenState["pose"] = pose.GetStats()
+		}
+		if status, err := pollenClient.GetStatus(ctx); err == nil {
+			pollenState["daemon"] = status
+		}
+		return pollenState
+	})
+	if cameraClient != nil {
+		robotState.AddSource("camera", func(ctx context.Context) interface{} {
+			return cameraClient.Stats()
+		})
+	}
+	robotState.AddSource("audio", func(ctx context.Context) interface{} {
+		audioState := map[string]interface{}{"bridge": audioBridge.GetStats()}
+		if micStream != nil {
+			audioState["webrtc_mic"] = micStream.GetStats()
+		}
+		if wake != nil {
+			audioState["wake"] = wake.GetStats()
+		}
+		return audioState
+	})
+	if faceWorker != nil {
+		robotState.AddSource("vision", func(ctx context.Context) interface{} {
+			return map[string]interface{}{
+				"faces":  faceWorker.GetStats(),
+				"fusion": fuser.GetStats(),
+			}
+		})
+	}
+	robotState.AddSource("emotion", func(ctx context.Context) interface{} {
+		return emotions.GetStats()
+	})
+	if expr != nil {
+		robotState.AddSource("expression", func(ctx context.Context) interface{} {
+			return expr.GetStats()
+		})
+	}
+	if cloudClient != nil {
+		robotState.AddSource("cloud", func(ctx context.Context) interface{} {
+			return cloudClient.GetStats()
+		})
+		robotState.PublishTo(events)
+		bus.Handle(ctx, events, state.TopicSnapshots, 1, func(snapshot protocol.StateData) {
+			if cloudClient.IsConnected() {
+				if err := cloudClient.SendState(snapshot); err != nil {
+					logger.Debug("state send failed", "error", err)
+				}
+			}
+		})
+		// A (re)connected cloud gets the current state without waiting a period
+		cloudClient.OnConnect(func() {
+			go func() {
+				if err := cloudClient.SendState(robotState.Snapshot(ctx)); err != nil {
+					logger.Debug("state send failed", "error", err)
+				}
+			}()
+		})
+
+		// Connect to cloud only now that the callbacks are in place, so the
+		// first connection gets its snapshot too
+		if err := cloudClient.Connect(ctx); err != nil {
+			logger.Error("cloud connection failed", "error", err)
+		}
+		go robotState.Run(ctx)
 	}
+	srv.SetState(robotState)
+	srv.SetLatency(lat)
 
-	// Create tracker
-	tracker := doa.NewTracker(source, trackerCfg, logger)
-
-	// Start tracker in background
+	// Runtime-reloadable settings (SIGHUP, config file edits, PUT /api/config)
+	configWatcher := config.NewWatcher(*configPath, cfg, logger)
+	configWatcher.Subscribe(func(oldCfg, newCfg *config.Config) {
+		if newCfg.Logging.Level != oldCfg.Logging.Level {
+			logLevel.Set(parseLogLevel(newCfg.Logging.Level))
+		}
+		if !reflect.DeepEqual(newCfg.Audio, oldCfg.Audio) {
+			if err := tracker.UpdateConfig(trackerConfig(newCfg.Audio)); err != nil {
+				logger.Warn("tracker reconfigure failed", "error", err)
+			}
+		}
+		if cameraClient != nil && newCfg.Camera.Framerate != oldCfg.Camera.Framerate {
+			cameraClient.SetFramerate(newCfg.Camera.Framerate)
+		}
+	})
+	srv.SetConfigWatcher(configWatcher)
 	go func() {
-		if err := tracker.Run(ctx); err != nil && err != context.Canceled {
-			logger.Error("tracker error", "error", err)
+		if err := configWatcher.Watch(ctx); err != nil && err != context.Canceled {
+			logger.Warn("config watcher stopped", "error", err)
 		}
 	}()
 
-	// Initialize Pollen client
-	pollenClient := pollen.NewClient(pollen.Config{
-		BaseURL:     cfg.Pollen.BaseURL,
-		Timeout:     cfg.Pollen.Timeout,
-		RateLimitHz: cfg.Pollen.RateLimitHz,
-	}, logger)
+	// Subsystem collectors for /metrics
+	pollenClient.RegisterMetrics(srv.Metrics())
+	events.RegisterMetrics(srv.Metrics())
+	lat.RegisterMetrics(srv.Metrics())
+	usbMetrics.RegisterMetrics(srv.Metrics())
+	if micMonitor != nil {
+		micMonitor.RegisterMetrics(srv.Metrics())
+	}
+	srv.SetMotor(motor)
+	srv.SetMotorCommands(runMotorCommand)
+	if auditLog != nil {
+		srv.SetAudit(auditLog)
+	}
+	if pose != nil {
+		srv.SetPose(pose)
+	}
+	srv.SetEmotions(emotions)
+	srv.SetChoreo(choreoPlayer)
+	srv.SetPlayback(audioBridge)
+	audioBridge.RegisterMetrics(srv.Metrics())
 
-	// Initialize cloud client if enabled
-	var cloudClient *cloud.Client
-	var cameraClient *camera.Client
+	// Mic levels for VU meters, while capture runs
+	levelMeter := audio.NewLevelMeter(audio.DefaultLevelWindow)
+	bus.Handle(ctx, events, audio.TopicChunks, 64, levelMeter.Feed)
+	srv.SetLevels(levelMeter)
+	if cloudClient != nil {
+		cloudClient.RegisterMetrics(srv.Metrics())
+	}
+	if cameraClient != nil {
+		cameraClient.RegisterMetrics(srv.Metrics())
+		srv.SetCamera(cameraClient)
+		srv.WSHub().SetProvider(server.TopicCamera, func() interface{} { return cameraClient.Stats() })
+	}
+	if frameChanges != nil {
+		frameChanges.RegisterMetrics(srv.Metrics())
+	}
+	if frameAdapter != nil {
+		frameAdapter.RegisterMetrics(srv.Metrics())
+	}
 
-	if cfg.Cloud.Enabled {
-		logger.Info("cloud mode enabled", "url", cfg.Cloud.URL)
+	// Start on-robot speech-to-text if enabled (mic capture gated by VAD)
+	if cfg.STT.Enabled {
+		sttCfg := stt.DefaultConfig()
+		sttCfg.Command = cfg.STT.Command
+		sttCfg.Args = cfg.STT.Args
+		sttCfg.MinUtterance = cfg.STT.MinUtterance
+		sttCfg.MaxUtterance = cfg.STT.MaxUtterance
+		sttCfg.Timeout = cfg.STT.Timeout
 
-		// Create cloud client
-		cloudClient = cloud.NewClient(cloud.Config{
-			URL:              cfg.Cloud.URL,
-			ReconnectBackoff: cfg.Cloud.ReconnectBackoff,
-			MaxBackoff:       cfg.Cloud.MaxBackoff,
-			PingInterval:     cfg.Cloud.PingInterval,
-			WriteTimeout:     5 * time.Second,
+		pipeline := stt.NewPipeline(sttCfg, &stt.CommandTranscriber{
+			Command: sttCfg.Command,
+			Args:    sttCfg.Args,
 		}, logger)
 
-		// Set up motor command callback
-		cloudClient.OnMotorCommand(func(cmd protocol.MotorCommand) {
-			logger.Debug("received motor command",
-				"yaw", cmd.Head.Yaw,
-				"pitch", cmd.Head.Pitch,
-				"roll", cmd.Head.Roll,
-			)
-
-			head := pollen.HeadTarget{
-				X:     cmd.Head.X,
-				Y:     cmd.Head.Y,
-				Z:     cmd.Head.Z,
-				Yaw:   cmd.Head.Yaw,
-				Pitch: cmd.Head.Pitch,
-				Roll:  cmd.Head.Roll,
+		pipeline.OnTranscript(func(tr stt.Transcript) {
+			if privacyGuard.Enabled() {
+				return
 			}
+			bus.Publish(events, stt.TopicTranscripts, tr)
+		})
+
+		if cloudClient != nil {
+			bus.Handle(ctx, events, stt.TopicTranscripts, 0, func(tr stt.Transcript) {
+				if !cloudClient.IsConnected() && offlineQueue == nil {
+					return
+				}
+				if err := cloudClient.SendTranscript(protocol.TranscriptData{
+					Text:       tr.Text,
+					StartMs:    tr.Start.UnixMilli(),
+					EndMs:      tr.End.UnixMilli(),
+					DurationMs: tr.DurationMs,
+					LatencyMs:  tr.LatencyMs,
+				}); err != nil {
+					logger.Debug("transcript send failed", "error", err)
+				}
+			})
+		}
 
-			if err := pollenClient.SetTarget(ctx, head, cmd.Antennas, cmd.BodyYaw); err != nil {
-				logger.Warn("motor command failed", "error", err)
+		bus.Handle(ctx, events, audio.TopicChunks, 64, func(chunk audio.AudioChunk) {
+			if privacyGuard.Enabled() {
+				return
 			}
+			pipeline.Feed(chunk)
 		})
 
-		// Set up emotion command callback
-		cloudClient.OnEmotionCommand(func(cmd protocol.EmotionCommand) {
-			logger.Info("playing emotion", "name", cmd.Name)
-			if err := pollenClient.PlayEmotion(ctx, cmd.Name, cmd.Duration); err != nil {
-				logger.Warn("emotion command failed", "error", err)
-			}
+		bus.Handle(ctx, events, doa.TopicResults, 0, func(result doa.Result) {
+			pipeline.SetSpeaking(result.SpeakingLatched)
 		})
 
-		// Connect to cloud
-		if err := cloudClient.Connect(ctx); err != nil {
-			logger.Error("cloud connection failed", "error", err)
+		logger.Info("local stt enabled", "command", sttCfg.Command)
+	}
+
+	// Stream mic audio to cloud in the codec it selected
+	streamMic := cloudClient != nil && cfg.Cloud.StreamMic
+	sendMic := func(chunk audio.AudioChunk) {
+		if err := cloudClient.SendMic(chunk.Data, chunk.SampleRate, chunk.Channels, micBeam(chunk.Beam)); err != nil {
+			logger.Debug("mic send failed", "error", err)
 		}
+	}
+	if streamMic {
+		bus.Handle(ctx, events, audio.TopicChunks, 64, func(chunk audio.AudioChunk) {
+			if privacyGuard.Enabled() || !cloudClient.IsConnected() {
+				return
+			}
+			sendMic(chunk)
+		})
+		logger.Info("mic streaming enabled", "codecs", cfg.Cloud.AudioCodecs)
+	}
 
-		// Forward DOA updates to cloud (with enhanced 3D positioning data)
-		go func() {
-			ticker := time.NewTicker(50 * time.Millisecond) // 20 Hz DOA updates
-			defer ticker.Stop()
+	// Hand the cloud the audio around energy onsets that may be the wake word
+	if cloudClient != nil && cfg.Audio.Wake.Enabled {
+		wakeCfg := audio.DefaultWakeConfig()
+		wakeCfg.PreRoll = cfg.Audio.Wake.PreRoll
+		wakeCfg.Window = cfg.Audio.Wake.Window
+		wakeCfg.SpikeDB = cfg.Audio.Wake.SpikeDB
+		wakeCfg.MinDBFS = cfg.Audio.Wake.MinDBFS
+		wakeCfg.Stream = cfg.Audio.Wake.Stream
+		wakeCfg.Cooldown = cfg.Audio.Wake.Cooldown
 
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					if cloudClient.IsConnected() {
-						reading := tracker.GetLatest()
-						cloudClient.SendEnhancedDOA(
-							reading.Angle,
-							reading.SmoothedAngle,
-							reading.Speaking,
-							reading.SpeakingLatched,
-							reading.Confidence,
-							reading.EstX,
-							reading.EstY,
-							reading.TotalEnergy,
-							reading.SpeechEnergy,
-						)
-					}
+		wake = audio.NewWakeDetector(wakeCfg, logger)
+		// Sent from the callback, not the bus, so it goes out ahead of its audio
+		wake.OnCandidate(func(c audio.WakeCandidate) {
+			bus.Publish(events, audio.TopicWake, c)
+			data := protocol.WakeCandidateData{
+				ID:         c.ID,
+				CapturedAt: c.Timestamp.UnixMilli(),
+				LevelDBFS:  c.LevelDBFS,
+				FloorDBFS:  c.FloorDBFS,
+				Beam:       micBeam(c.Beam),
+			}
+			if !streamMic {
+				data.PreRollMs = c.PreRoll.Milliseconds()
+				data.StreamMs = wakeCfg.Stream.Milliseconds()
+			}
+			if err := cloudClient.SendWakeCandidate(data); err != nil {
+				logger.Debug("wake candidate send failed", "error", err)
+			}
+		})
+		if !streamMic {
+			// The mic isn't streamed anyway, so send the candidate's audio
+			wake.OnAudio(func(id uint64, chunk audio.AudioChunk) {
+				if cloudClient.IsConnected() {
+					sendMic(chunk)
 				}
+			})
+		}
+		bus.Handle(ctx, events, audio.TopicChunks, 64, func(chunk audio.AudioChunk) {
+			if privacyGuard.Enabled() {
+				return
 			}
-		}()
+			wake.Feed(chunk)
+		})
+		if exporter != nil {
+			exporter.AddSource("wake", func() map[string]interface{} {
+				return influx.StructFields(wake.GetStats())
+			})
+		}
+		logger.Info("wake candidates enabled", "pre_roll", wakeCfg.PreRoll, "spike_db", wakeCfg.SpikeDB)
+	}
 
-		// Initialize camera client if enabled
-		if cfg.Camera.Enabled {
-			logger.Info("camera capture enabled",
-				"framerate", cfg.Camera.Framerate,
-				"resolution", fmt.Sprintf("%dx%d", cfg.Camera.Width, cfg.Camera.Height),
-			)
+	captureMic := cfg.STT.Enabled || streamMic || wake != nil
+	if captureMic {
+		// Tag captured audio with the talker direction from the tracker
+		audioBridge.SetBeamSource(func() (audio.Beam, bool) {
+			latest := tracker.GetLatest()
+			if latest.Timestamp.IsZero() {
+				return audio.Beam{}, false
+			}
+			return audio.Beam{
+				Angle:       latest.SmoothedAngle,
+				Confidence:  latest.Confidence,
+				DominantMic: latest.DominantMic(),
+				Speaking:    latest.SpeakingLatched,
+				Timestamp:   latest.Timestamp,
+			}, true
+		})
+		if err := audioBridge.StartCapture(ctx); err != nil {
+			logger.Error("mic capture failed", "error", err)
+		}
+	}
 
-			cameraClient = camera.NewClient(camera.Config{
-				PollenURL: cfg.Pollen.BaseURL,
-				Framerate: cfg.Camera.Framerate,
-				Width:     cfg.Camera.Width,
-				Height:    cfg.Camera.Height,
-				Quality:   cfg.Camera.Quality,
-				Timeout:   2 * time.Second,
-			}, logger)
-
-			// Forward frames to cloud
-			cameraClient.OnFrame(func(frame camera.Frame) {
-				if cloudClient.IsConnected() {
-					if err := cloudClient.SendFrame(frame.Width, frame.Height, frame.Data, frame.FrameID); err != nil {
-						logger.Debug("frame send failed", "error", err)
-					}
+	// Start DOA-triggered gestures if enabled
+	if cfg.Gesture.Enabled {
+		gestureCfg := gesture.DefaultConfig()
+		gestureCfg.NewSpeakerAngle = cfg.Gesture.NewSpeakerAngle * math.Pi / 180
+		gestureCfg.BehindAngle = cfg.Gesture.BehindAngle * math.Pi / 180
+		gestureCfg.LoudRatio = cfg.Gesture.LoudRatio
+		if len(cfg.Gesture.Rules) > 0 {
+			gestureCfg.Rules = make([]gesture.Rule, len(cfg.Gesture.Rules))
+			for i, r := range cfg.Gesture.Rules {
+				gestureCfg.Rules[i] = gesture.Rule{
+					Event:    r.Event,
+					Emotion:  r.Emotion,
+					Duration: r.Duration,
+					Antennas: r.Antennas,
+					Lean:     r.Lean,
+					Cooldown: r.Cooldown,
 				}
-			})
+			}
+		}
+
+		var gestureActuator gesture.Actuator = motor
+		var gestureEmotions gesture.EmotionPlayer = emotions
+		if auditLog != nil {
+			gestureActuator = auditLog.Motor(motor, audit.SourceBehavior)
+			gestureEmotions = auditLog.Emotions(emotions, audit.SourceBehavior)
+		}
+		gestures := gesture.NewEngine(gestureCfg, gestureActuator, logger)
+		gestures.SetEmotionPlayer(gestureEmotions)
+		// With animation on, antenna moves blend into the procedural motion
+		if animator != nil {
+			gestures.SetAntennaSetter(animator)
+		}
+		gestures.PublishTo(events)
+		go gestures.Run(ctx, bus.Subscribe(events, doa.TopicResults, 0).C)
 
-			if err := cameraClient.Start(ctx); err != nil {
-				logger.Error("camera start failed", "error", err)
+		logger.Info("gesture mapping enabled", "rules", len(gestureCfg.Rules))
+	}
+
+	// Privacy mode suspends mic and camera capture; the send gate drops anything in flight
+	privacyGuard.PublishTo(events)
+	bus.Handle(ctx, events, privacy.TopicChanges, 0, func(status privacy.Status) {
+		if status.Enabled {
+			audioBridge.StopCapture()
+			if cameraClient != nil {
+				cameraClient.Stop()
+			}
+		} else {
+			if captureMic {
+				if err := audioBridge.StartCapture(ctx); err != nil {
+					logger.Error("mic capture failed", "error", err)
+				}
+			}
+			if cameraClient != nil {
+				if err := cameraClient.Start(ctx); err != nil {
+					logger.Error("camera start failed", "error", err)
+				}
 			}
 		}
+	})
+	go privacyGuard.WatchButton(ctx)
+
+	if cfg.Privacy.StartEnabled {
+		privacyGuard.Set(true, "config")
 	}
 
-	// Create server
-	srv := server.New(cfg.Server, tracker, logger, version)
+	// Component health probes for /health; critical failures return 503
+	healthChecker := health.NewChecker(version)
+	critical := make(map[string]bool, len(cfg.Health.Critical))
+	for _, name := range cfg.Health.Critical {
+		critical[name] = true
+	}
+	healthChecker.Register("doa_source", critical["doa_source"], func(ctx context.Context) error {
+		if !source.Healthy() {
+			return fmt.Errorf("%s source unhealthy", source.Name())
+		}
+		active := source
+		if composite, ok := source.(*xvf3800.CompositeSource); ok {
+			active = composite.Active()
+		}
+		if device, ok := active.(*xvf3800.USBSource); ok {
+			if fw := device.Firmware(); fw.Version != "" {
+				return health.Note("firmware " + fw.String())
+			}
+		}
+		return nil
+	})
+	if prober != nil {
+		healthChecker.Register("doa_device", critical["doa_device"], prober.Check)
+	}
+	var lastPolls int64
+	healthChecker.Register("tracker", critical["tracker"], func(ctx context.Context) error {
+		polls := tracker.Stats().PollCount
+		defer func() { lastPolls = polls }()
+		if polls == lastPolls {
+			return fmt.Errorf("tracker not polling")
+		}
+		return nil
+	})
+	healthChecker.Register("pollen", critical["pollen"], func(ctx context.Context) error {
+		if _, err := pollenClient.GetStatus(ctx); err != nil {
+			return fmt.Errorf("daemon unreachable (circuit %s): %w", pollenClient.BreakerState(), err)
+		}
+		// Reported, not failed: an unhealthy critical component stops the
+		// watchdog, and the restart would clear the stop latch
+		if motor.Status().Stopped {
+			return health.Note("emergency stop engaged")
+		}
+		return nil
+	})
+	if cloudClient != nil {
+		healthChecker.Register("cloud", critical["cloud"], func(ctx context.Context) error {
+			if !cloudClient.IsConnected() {
+				return fmt.Errorf("disconnected (next try %s)", cloudClient.Endpoint())
+			}
+			if !cloudClient.OnPrimary() {
+				return health.Note("connected to fallback " + cloudClient.Endpoint())
+			}
+			return health.Note("connected to " + cloudClient.Endpoint())
+		})
+	}
+	if cameraClient != nil {
+		healthChecker.Register("camera", critical["camera"], func(ctx context.Context) error {
+			if privacyGuard.Enabled() {
+				return nil // Stopped on purpose
+			}
+			if !cameraClient.Stats().Connected {
+				return fmt.Errorf("webrtc disconnected")
+			}
+			return nil
+		})
+	}
+	healthChecker.Register("audio", critical["audio"], func(ctx context.Context) error {
+		if !audioBridge.IsAvailable() {
+			return fmt.Errorf("audio commands not found")
+		}
+		wantCapture := captureMic && !privacyGuard.Enabled()
+		if wantCapture && !audioBridge.GetStats().Capturing {
+			return fmt.Errorf("mic capture stopped")
+		}
+		return nil
+	})
+	if micMonitor != nil {
+		healthChecker.Register("mics", critical["mics"], func(ctx context.Context) error {
+			return micMonitor.Check()
+		})
+	}
+	srv.SetHealth(healthChecker)
+	go healthChecker.Run(ctx, cfg.Health.Interval, cfg.Health.Timeout)
 
 	// Start WebSocket hub in background
 	go srv.WSHub().Run(ctx)
 
 	// Start server in background
+	listening := make(chan struct{})
+	srv.OnListen(func() { close(listening) })
 	go func() {
 		if err := srv.Start(); err != nil {
 			logger.Error("server error", "error", err)
@@ -249,15 +1719,94 @@ func main() {
 		}
 	}()
 
+	// Typed API for other on-robot services
+	var grpcSrv *evagrpc.Server
+	if cfg.GRPC.Enabled {
+		var grpcMotor evagrpc.Motor
+		if cfg.GRPC.Motor {
+			grpcMotor = motor
+			if auditLog != nil {
+				grpcMotor = auditLog.Motor(motor, audit.SourceLocal)
+			}
+		}
+		grpcCfg := evagrpc.Config{
+			Bind:    cfg.GRPC.Bind,
+			Port:    cfg.GRPC.Port,
+			APIKeys: cfg.Server.Auth.APIKeys,
+		}
+		grpcSrv = evagrpc.NewServer(grpcCfg, tracker, grpcMotor, logger)
+		if cfg.GRPC.Motor {
+			// Head moves take the local command path: they yield auto-tracking
+			// and blend antennas into the animation
+			grpcSrv.SetMove(func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+				err := moveHead(ctx, head, antennas, bodyYaw)
+				if auditLog != nil {
+					cmd := audit.TargetCommand{Head: head, Antennas: antennas, BodyYaw: bodyYaw}
+					if aerr := auditLog.Record(audit.SourceLocal, audit.KindMotor, "", cmd, err); aerr != nil {
+						logger.Warn("audit record failed", "kind", audit.KindMotor, "error", aerr)
+					}
+				}
+				return err
+			})
+			// Emotions queue with cloud and gesture ones
+			var grpcEmotions evagrpc.EmotionPlayer = emotions
+			if auditLog != nil {
+				grpcEmotions = auditLog.Emotions(emotions, audit.SourceLocal)
+			}
+			grpcSrv.SetEmotions(grpcEmotions)
+		}
+		go func() {
+			if err := grpcSrv.Start(); err != nil {
+				logger.Error("grpc server error", "error", err)
+			}
+		}()
+	}
+
 	// Print startup info
 	printStartupBanner(cfg, version, cloudClient)
 
-	// Wait for shutdown signal
+	// systemd readiness once the HTTP server is up, then watchdog pings
+	// while health checks run and no critical component is down
+	notifier := systemd.NewNotifier(logger)
+	go func() {
+		select {
+		case <-listening:
+		case <-ctx.Done():
+			return
+		}
+		if err := notifier.Ready(fmt.Sprintf("serving on :%d", cfg.Server.Port)); err != nil {
+			logger.Warn("systemd notify failed", "error", err)
+		}
+		notifier.RunWatchdog(ctx, func() error {
+			if last := healthChecker.LastRun(); time.Since(last) > 3*cfg.Health.Interval+cfg.Health.Timeout {
+				return fmt.Errorf("health checks stalled since %s", last.Format(time.RFC3339))
+			}
+			var down []string
+			for name, check := range healthChecker.GetStatus().Components {
+				if check.Critical && !check.Healthy {
+					down = append(down, name)
+				}
+			}
+			if len(down) > 0 {
+				slices.Sort(down)
+				return fmt.Errorf("critical components down: %s", strings.Join(down, ", "))
+			}
+			return nil
+		})
+	}()
+
+	// Wait for shutdown signal, or a subsystem failing on its own
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-quit
-
-	logger.Info("received shutdown signal", "signal", sig.String())
+	select {
+	case sig := <-quit:
+		logger.Info("received shutdown signal", "signal", sig.String())
+	case <-ctx.Done():
+		logger.Error("subsystem failed, shutting down")
+	}
+	if err := notifier.Stopping(); err != nil {
+		logger.Warn("systemd notify failed", "error", err)
+	}
 
 	// Graceful shutdown with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(
@@ -266,15 +1815,33 @@ func main() {
 	)
 	defer shutdownCancel()
 
-	// Stop in order: camera -> cloud -> server -> tracker -> source
+	// Stop in order: camera -> audio -> cloud -> server -> tracker -> source
 	if cameraClient != nil {
 		logger.Info("stopping camera client...")
 		cameraClient.Stop()
 	}
 
+	// The last mic chunks reach the cloud's send queue before it drains
+	logger.Info("stopping audio...")
+	if err := audioBridge.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("audio shutdown error", "error", err)
+	}
+
 	if cloudClient != nil {
 		logger.Info("disconnecting from cloud...")
-		cloudClient.Close()
+		if err := cloudClient.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("cloud shutdown error", "error", err)
+		}
+	}
+	if offlineQueue != nil {
+		if err := offlineQueue.Close(); err != nil {
+			logger.Warn("offline queue close error", "error", err)
+		}
+	}
+
+	if grpcSrv != nil {
+		logger.Info("stopping grpc server...")
+		grpcSrv.Stop(shutdownCtx)
 	}
 
 	logger.Info("shutting down server...")
@@ -284,32 +1851,277 @@ func main() {
 
 	logger.Info("stopping tracker...")
 	tracker.Stop()
+	events.Close()
+	if cfg.Audio.Persist.Enabled {
+		if err := doa.SaveSnapshot(cfg.Audio.Persist.File, tracker.Snapshot()); err != nil {
+			logger.Warn("tracker state save error", "error", err)
+		}
+	}
+
+	// Closes the recorder too
+	if err := history.Close(); err != nil {
+		logger.Warn("history flush error", "error", err)
+	}
+
+	if auditLog != nil {
+		if err := auditLog.Close(); err != nil {
+			logger.Warn("audit log close error", "error", err)
+		}
+	}
 
 	logger.Info("go-eva stopped")
 }
 
+// logLevel is shared by the handler so the level can change at runtime
+var logLevel = new(slog.LevelVar)
+
+// micBeam converts a chunk's beam tag for mic messages
+func micBeam(b *audio.Beam) *protocol.MicBeam {
+	if b == nil {
+		return nil
+	}
+	return &protocol.MicBeam{
+		Angle:       b.Angle,
+		Confidence:  b.Confidence,
+		DominantMic: b.DominantMic,
+		Speaking:    b.Speaking,
+	}
+}
+
+// capabilities lists the protocol capabilities enabled by cfg; vision also
+// needs a loaded face detector
+func capabilities(cfg *config.Config, vision bool) []string {
+	caps := []string{protocol.CapabilityMotor, protocol.CapabilitySpeaker}
+	if cfg.Camera.Enabled {
+		caps = append(caps, protocol.CapabilityCamera)
+	}
+	if cfg.Cloud.StreamMic {
+		caps = append(caps, protocol.CapabilityMic)
+	}
+	if cfg.Audio.Wake.Enabled {
+		caps = append(caps, protocol.CapabilityWake)
+	}
+	if cfg.TTS.Enabled {
+		caps = append(caps, protocol.CapabilityTTS)
+	}
+	if cfg.STT.Enabled {
+		caps = append(caps, protocol.CapabilitySTT)
+	}
+	if vision {
+		caps = append(caps, protocol.CapabilityVision)
+	}
+	if cfg.Playback.Cache.Enabled {
+		caps = append(caps, protocol.CapabilitySpeakCache)
+	}
+	return caps
+}
+
+// sourceOptions builds the DOA source options from the config
+func sourceOptions(cfg *config.Config) xvf3800.Options {
+	opts := xvf3800.DefaultOptions()
+	opts.I2C.Bus = cfg.Audio.I2C.Bus
+	opts.I2C.Address = cfg.Audio.I2C.Address
+	opts.Python.Command = cfg.Source.Python.Command
+	opts.Python.Args = cfg.Source.Python.Args
+	opts.Python.StartTimeout = cfg.Source.Python.StartTimeout
+	opts.Replay.Speed = cfg.Source.Replay.Speed
+	opts.Replay.Loop = cfg.Source.Replay.Loop
+	opts.Composite = xvf3800.CompositeConfig{
+		ProbeInterval: cfg.Source.ProbeInterval,
+		FailAfter:     cfg.Source.FailAfter,
+	}
+	opts.Chain = []string{"usb"}
+	if cfg.Audio.Transport == "i2c" {
+		// The device is only reachable over I2C, so it takes USB's place
+		opts.Chain[0] = "i2c"
+	}
+	if cfg.Source.Python.Enabled {
+		opts.Chain = append(opts.Chain, "python")
+	}
+	if cfg.Source.MockFallback {
+		opts.Chain = append(opts.Chain, "mock")
+	}
+	return opts
+}
+
+// sourceSpec maps "usb" to "i2c" when the device is only reachable over I2C
+func sourceSpec(cfg *config.Config, spec string) string {
+	if cfg.Audio.Transport == "i2c" && spec == "usb" {
+		return "i2c"
+	}
+	return spec
+}
+
+// loadCalibration applies the configured mounting, then loads and applies
+// the stored distance and mounting calibration
+func loadCalibration(cfg *config.Config, logger *slog.Logger) (*calibration.Calibrator, error) {
+	mounting := doa.Mounting{
+		Offset: cfg.Audio.Mounting.OffsetDeg * math.Pi / 180,
+		Mirror: cfg.Audio.Mounting.Mirror,
+	}
+	doa.SetMounting(mounting)
+	return calibration.New(calibration.Config{
+		File:       cfg.Calibration.File,
+		Duration:   cfg.Calibration.Duration,
+		MinSamples: cfg.Calibration.MinSamples,
+		Mounting:   mounting,
+	}, logger)
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func setupLogger(cfg config.LoggingConfig) *slog.Logger {
 	var handler slog.Handler
 
-	level := slog.LevelInfo
-	switch cfg.Level {
+	logLevel.Set(parseLogLevel(cfg.Level))
+	opts := &slog.HandlerOptions{Level: logLevel}
+
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	// Lines logged with a traced context carry its trace_id
+	return slog.New(trace.NewHandler(handler))
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch level {
 	case "debug":
-		level = slog.LevelDebug
+		return slog.LevelDebug
 	case "warn":
-		level = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		level = slog.LevelError
+		return slog.LevelError
 	}
+	return slog.LevelInfo
+}
 
-	opts := &slog.HandlerOptions{Level: level}
+// trackerConfig builds the DOA tracker configuration from the audio settings
+func trackerConfig(audio config.AudioConfig) doa.TrackerConfig {
+	return doa.TrackerConfig{
+		PollInterval:     time.Duration(1000/audio.PollHz) * time.Millisecond,
+		SpeakingLatchDur: time.Duration(audio.SpeakingLatchMs) * time.Millisecond,
+		EMAAlpha:         audio.EMAAlpha,
+		Confidence: doa.ConfidenceConfig{
+			Base:           audio.Confidence.Base,
+			SpeakingBonus:  audio.Confidence.SpeakingBonus,
+			StabilityBonus: audio.Confidence.StabilityBonus,
+		},
+		Smoothing: doa.SmoothingConfig{
+			Mode:             audio.Smoothing,
+			EMAAlpha:         audio.EMAAlpha,
+			MedianWindow:     audio.MedianWindow,
+			ProcessNoise:     audio.Kalman.ProcessNoise,
+			MeasurementNoise: audio.Kalman.MeasurementNoise,
+		},
+		Utterance: doa.UtteranceConfig{
+			Hangover:    time.Duration(audio.UtteranceHangoverMs) * time.Millisecond,
+			MaxDuration: time.Duration(audio.MaxUtteranceMs) * time.Millisecond,
+		},
+		Zones: zoneConfig(audio),
+		Noise: doa.NoiseConfig{
+			Enabled:      audio.NoiseFloor.Enabled,
+			Alpha:        audio.NoiseFloor.Alpha,
+			Margin:       audio.NoiseFloor.Margin,
+			MinThreshold: audio.NoiseFloor.MinThreshold,
+			Warmup:       audio.NoiseFloor.Warmup,
+		},
+		Echo: doa.EchoConfig{
+			Enabled:      audio.Echo.Enabled,
+			Tail:         time.Duration(audio.Echo.TailMs) * time.Millisecond,
+			BargeIn:      audio.Echo.BargeIn,
+			BargeInRatio: audio.Echo.BargeInRatio,
+		},
+		IdlePoll: doa.IdlePollConfig{
+			Enabled:  audio.Idle.Enabled,
+			After:    audio.Idle.After,
+			Interval: time.Second / time.Duration(max(audio.Idle.PollHz, 1)),
+		},
+		Outliers: doa.OutlierConfig{
+			Enabled: audio.Outliers.Enabled,
+			MaxJump: audio.Outliers.MaxJumpDeg * math.Pi / 180,
+			Confirm: audio.Outliers.Confirm,
+		},
+		Speakers: doa.SpeakerConfig{
+			GateAngle:   audio.Speakers.GateDeg * math.Pi / 180,
+			Alpha:       doa.DefaultSpeakerConfig().Alpha,
+			Timeout:     audio.Speakers.Timeout,
+			MaxSpeakers: audio.Speakers.MaxSpeakers,
+		},
+	}
+}
 
-	if cfg.Format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
-	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+// trackerProcessors builds the configured result processors for a tracker
+// using cfg
+func trackerProcessors(audio config.AudioConfig, cfg doa.TrackerConfig) ([]doa.Processor, error) {
+	var processors []doa.Processor
+	for _, entry := range audio.Processors {
+		p, err := doa.NewProcessor(entry.Name, entry.Params, cfg)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, p)
+	}
+	return processors, nil
+}
+
+// zoneConfig converts the configured zones from degrees
+func zoneConfig(audio config.AudioConfig) doa.ZoneConfig {
+	cfg := doa.ZoneConfig{
+		Hysteresis: audio.ZoneHysteresisDeg * math.Pi / 180,
+		MinDwell:   time.Duration(audio.ZoneDwellMs) * time.Millisecond,
+		World:      audio.ZoneWorld,
+	}
+	for _, z := range audio.Zones {
+		cfg.Zones = append(cfg.Zones, doa.Zone{
+			Name:   z.Name,
+			Center: z.CenterDeg * math.Pi / 180,
+			Width:  z.WidthDeg * math.Pi / 180,
+		})
+	}
+	return cfg
+}
+
+// setupXVFProfiles resolves the configured profiles against the allowlist,
+// serves them and restores the last applied (or configured) profile
+func setupXVFProfiles(ctx context.Context, cfg config.XVF3800Config, ctrl xvf3800.ParamController, allow *xvf3800.Allowlist, srv *server.Server, logger *slog.Logger) {
+	profiles := make([]xvf3800.Profile, 0, len(cfg.Profiles))
+	for _, spec := range cfg.Profiles {
+		profile := xvf3800.Profile{Name: spec.Name}
+		for _, setting := range spec.Settings {
+			param, ok := allow.ByName(setting.Param)
+			if !ok {
+				logger.Error("xvf3800 profile uses a param not in the allowlist", "profile", spec.Name, "param", setting.Param)
+				return
+			}
+			profile.Settings = append(profile.Settings, xvf3800.ProfileSetting{Param: param, Values: setting.Values})
+		}
+		profiles = append(profiles, profile)
 	}
 
-	return slog.New(handler)
+	xvfProfiles, err := xvf3800.NewProfiles(ctrl, profiles, cfg.ProfileFile, logger)
+	if err != nil {
+		logger.Error("invalid xvf3800 profiles", "error", err)
+		return
+	}
+	srv.SetXVFProfiles(xvfProfiles)
+
+	go func() {
+		if err := xvfProfiles.Restore(ctx, cfg.Profile); err != nil {
+			logger.Warn("failed to restore xvf3800 profile", "error", err)
+		}
+	}()
 }
 
 func printStartupBanner(cfg *config.Config, version string, cloudClient *cloud.Client) {
@@ -323,13 +2135,65 @@ func printStartupBanner(cfg *config.Config, version string, cloudClient *cloud.C
 	fmt.Println("   GET  /health              - Health check")
 	fmt.Println("   GET  /api/audio/doa       - Current DOA reading")
 	fmt.Println("   WS   /api/audio/doa/stream - Real-time DOA stream")
+	fmt.Println("   GET  /api/audio/sources   - Active speaker tracks")
+	fmt.Println("   GET  /api/audio/mics      - Per-mic energy and dead-mic diagnosis")
+	fmt.Println("   POST /api/audio/calibrate/start - Calibrate distance at a known range")
+	fmt.Println("   POST /api/audio/mounting/measure - Measure the mic array mounting offset")
 	fmt.Println("   GET  /api/stats           - Tracker statistics")
+	fmt.Println("   GET  /api/state           - Robot state snapshot")
+	fmt.Println("   GET  /api/latency         - Reaction latency per stage")
+	fmt.Println("   GET  /api/config          - Live config, credentials masked")
+	fmt.Println("   PUT  /api/config          - Apply runtime config changes (also SIGHUP)")
 	fmt.Println("   GET  /metrics             - Prometheus metrics")
+	fmt.Println("   GET/POST /api/privacy     - Privacy mode (mic/camera off)")
+	fmt.Println("   GET  /api/motor/state     - Measured head pose and joint limits")
+	fmt.Println("   POST /api/motor/target    - Move the head through the safety limiter (also WS \"motor\")")
+	fmt.Println("   POST /api/motor/stop      - Emergency stop (POST /api/motor/resume to release)")
+	fmt.Println("   POST /api/choreo/play     - Play a keyframed motion sequence (JSON or YAML)")
+	fmt.Println("   POST /api/audio/stop      - Stop speaker playback and clear the queue")
+	fmt.Println("   GET  /api/openapi.json    - OpenAPI document for the versioned /api/v1 routes")
+	if cfg.Camera.Enabled {
+		fmt.Println("   GET  /api/camera/stream   - Live MJPEG camera preview")
+		fmt.Println("   GET  /api/camera/snapshot - Latest camera frame (JPEG)")
+	}
+	if cfg.Camera.Enabled && cfg.Camera.Archive.Enabled {
+		fmt.Println("   GET  /api/camera/archive  - Archived stills (from, to, limit); /api/camera/archive/:name for one JPEG")
+	}
+	if cfg.History.Enabled {
+		fmt.Println("   GET  /api/history/query   - Stored readings, segments, events")
+	}
+	if cfg.XVF3800.ControlEnabled {
+		fmt.Println("   POST /api/xvf3800/param   - Read/write allowlisted XVF3800 parameters")
+	}
+	fmt.Println("   GET  /api/audio/doa/history - Full-rate DOA results (from, to, format=jsonl; seconds, resolution)")
+	if cfg.Audit.Enabled {
+		fmt.Println("   GET  /api/audit           - Motor and emotion command audit log (since, limit)")
+	}
+	if cfg.TTS.Enabled {
+		fmt.Println("   POST /api/speak           - Speak text with local TTS")
+	}
+
+	if cfg.GRPC.Enabled {
+		fmt.Println()
+		bind := cfg.GRPC.Bind
+		if bind == "" {
+			bind = "0.0.0.0"
+		}
+		fmt.Printf("   🔌 gRPC: %s (DOAService", net.JoinHostPort(bind, strconv.Itoa(cfg.GRPC.Port)))
+		if cfg.GRPC.Motor {
+			fmt.Print(", MotorService")
+		}
+		fmt.Println(")")
+	}
 
 	if cfg.Cloud.Enabled {
 		fmt.Println()
 		fmt.Println("   ☁️  Cloud Mode:")
-		fmt.Printf("      URL: %s\n", cfg.Cloud.URL)
+		if cloudClient != nil {
+			fmt.Printf("      URL: %s\n", cloudClient.Endpoint())
+		} else {
+			fmt.Printf("      URL: %s\n", cfg.Cloud.URL)
+		}
 		if cloudClient != nil && cloudClient.IsConnected() {
 			fmt.Println("      Status: ✅ Connected")
 		} else {
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/xvf3800"
+)
+
+// runProbe finds the XVF3800, reads its firmware version and the default
+// parameters, and checks the values are sane. It fails if any step does.
+func runProbe(args []string) error {
+	fs, tf := newToolFlags("probe", "", "Find the XVF3800 and check its firmware and parameters")
+	timeout := fs.Duration("timeout", 2*time.Second, "timeout for each device read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, logger, err := tf.setup()
+	if err != nil {
+		return err
+	}
+	// Probe the device itself, not whatever the fallback chain lands on
+	if tf.source == "" {
+		tf.source = cfg.Audio.Transport
+	}
+
+	var failures int
+	fail := func(format string, a ...any) {
+		failures++
+		fmt.Printf("  FAIL  "+format+"\n", a...)
+	}
+
+	if tf.source == "usb" {
+		fmt.Printf("USB devices (VID 0x%04X, PID 0x%04X):\n", xvf3800.VendorID, xvf3800.ProductID)
+		devices, err := xvf3800.ListUSBDevices()
+		switch {
+		case err != nil:
+			fail("enumerate: %v", err)
+		case len(devices) == 0:
+			fail("no XVF3800 attached")
+		}
+		for _, d := range devices {
+			fmt.Printf("  bus %d address %d  %s speed  device version %s  serial %q\n", d.Bus, d.Address, d.Speed, d.Version, d.Serial)
+		}
+	}
+
+	source, err := tf.openSource(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	fmt.Printf("Source: %s (healthy: %v)\n", source.Name(), source.Healthy())
+	if device, ok := source.(*xvf3800.USBSource); ok {
+		fw := device.Firmware()
+		if fw.Version == "" {
+			fail("firmware version not readable")
+		} else {
+			fmt.Printf("  ok    firmware %s\n", fw.Version)
+		}
+		if fw.Build != "" {
+			fmt.Printf("        build    %s\n", fw.Build)
+		}
+		if fw.RepoHash != "" {
+			fmt.Printf("        revision %s\n", fw.RepoHash)
+		}
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	var reading doa.Reading
+	err = waitFor(ctx, *timeout, func() (err error) {
+		reading, err = source.GetDOA(ctx)
+		return err
+	})
+	if err != nil {
+		fail("DOA reading: %v", err)
+	} else {
+		fmt.Printf("  ok    DOA reading: angle %+.1f°, speaking %v, energy %.3g\n", degrees(reading.Angle), reading.Speaking, reading.TotalEnergy)
+	}
+
+	ctrl, ok := source.(xvf3800.ParamController)
+	if !ok {
+		fmt.Printf("Source %s has no parameter control; skipping parameter checks\n", source.Name())
+	} else {
+		fmt.Println("Parameters:")
+		for _, p := range xvf3800.DefaultParams() {
+			rctx, rcancel := context.WithTimeout(ctx, *timeout)
+			values, err := ctrl.ReadParam(rctx, p)
+			rcancel()
+			switch {
+			case errors.Is(err, xvf3800.ErrNoControl):
+				fmt.Printf("  skip  %-20s %v\n", p.Name, err)
+			case err != nil:
+				fail("%-20s %v", p.Name, err)
+			default:
+				if problem := checkParam(p, values); problem != "" {
+					fail("%-20s %s: %s", p.Name, formatValues(values), problem)
+				} else if p.Name == xvf3800.VersionParam.Name {
+					fmt.Printf("  ok    %-20s firmware %d.%d.%d\n", p.Name, int(values[0]), int(values[1]), int(values[2]))
+				} else {
+					fmt.Printf("  ok    %-20s %s\n", p.Name, formatValues(values))
+				}
+			}
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d check(s) failed", failures)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// checkParam returns why a parameter's values look wrong ("" if they don't)
+func checkParam(p xvf3800.Param, values []float64) string {
+	if len(values) != p.Count {
+		return fmt.Sprintf("got %d values, want %d", len(values), p.Count)
+	}
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "not a finite number"
+		}
+	}
+	switch p.Name {
+	case xvf3800.VersionParam.Name:
+		if values[0] == 0 && values[1] == 0 && values[2] == 0 {
+			return "firmware version 0.0.0"
+		}
+	case "DOA_VALUE_RADIANS":
+		if math.Abs(values[0]) > 2*math.Pi {
+			return "angle out of range"
+		}
+	case "AEC_SPENERGY_VALUES":
+		for _, v := range values {
+			if v < 0 {
+				return "negative speech energy"
+			}
+		}
+	}
+	return ""
+}
+
+// formatValues renders parameter values compactly
+func formatValues(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'g', 5, 64)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/xvf3800"
+)
+
+// runReplay plays a recorded session (recorder export or CSV trace) through
+// the tracker with the configured tuning and reports utterances, zone
+// changes and speakers, or every result with -json
+func runReplay(args []string) error {
+	fs, tf := newToolFlags("replay", "<file>", "Run a recorded session through the tracker")
+	speed := fs.Float64("speed", 1, "playback rate (1 = original timing, 0 = one reading per poll)")
+	verbose := fs.Bool("v", false, "print every result, not just events")
+	asJSON := fs.Bool("json", false, "print every result as a JSON line")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Flags may also follow the file
+	file := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[min(1, fs.NArg()):]); err != nil {
+		return err
+	}
+	if file == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("expected one replay file")
+	}
+
+	cfg, logger, err := tf.setup()
+	if err != nil {
+		return err
+	}
+	opts := sourceOptions(cfg)
+	opts.Replay.Speed = *speed
+	opts.Replay.Loop = false
+	source, err := xvf3800.Open("replay:"+file, opts, logger)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	replay := source.(*xvf3800.ReplaySource)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	tracker, _ := startTracker(ctx, cfg, source, logger)
+	results := tracker.Subscribe()
+	utterances := tracker.SubscribeUtterances()
+	zones := tracker.SubscribeZones()
+
+	if !*asJSON {
+		fmt.Fprintf(os.Stderr, "Replaying %d readings from %s at %gx\n", replay.Len(), file, *speed)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	var count, speaking, turns int
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			return errors.New("interrupted")
+		case <-replay.Done():
+			done = true
+		case r := <-results:
+			count++
+			if r.SpeakingLatched {
+				speaking++
+			}
+			switch {
+			case *asJSON:
+				if err := enc.Encode(r); err != nil {
+					return err
+				}
+			case *verbose:
+				fmt.Println(formatResult(r))
+			}
+		case ev := <-utterances:
+			if ev.Type == doa.UtteranceEnd {
+				turns++
+			}
+			if !*asJSON {
+				printUtterance(ev)
+			}
+		case ev := <-zones:
+			if !*asJSON {
+				fmt.Printf("%s  zone %q -> %q at %+.1f°\n", ev.Timestamp.Format("15:04:05.000"), ev.From, ev.To, degrees(ev.Angle))
+			}
+		}
+	}
+	if *asJSON {
+		return nil
+	}
+
+	fmt.Printf("\n%d results, %d speaking (%.0f%%), %d utterances\n", count, speaking, percent(speaking, count), turns)
+	for _, s := range tracker.GetSpeakers() {
+		fmt.Printf("  speaker %d at %+.1f°: %d turns\n", s.ID, degrees(s.Angle), s.Turns)
+	}
+	return nil
+}
+
+// printUtterance renders an utterance boundary as one line
+func printUtterance(ev doa.UtteranceEvent) {
+	if ev.Type == doa.UtteranceStart {
+		fmt.Printf("%s  utterance %d started (speaker %d)\n", ev.Start.Format("15:04:05.000"), ev.ID, ev.SpeakerID)
+		return
+	}
+	fmt.Printf("%s  utterance %d ended: %dms at %+.1f°, peak energy %.3g (speaker %d)\n",
+		ev.End.Format("15:04:05.000"), ev.ID, ev.DurationMs, degrees(ev.AvgAngle), ev.PeakEnergy, ev.SpeakerID)
+}
+
+// percent returns n as a percentage of total (0 when total is 0)
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
@@ -0,0 +1,67 @@
+// pollen-sim serves a simulated Pollen daemon API, for running go-eva and
+// its integration tests on a laptop:
+//
+//	go run ./cmd/pollen-sim -addr :8000
+//	go run ./cmd/go-eva -mock -pollen http://localhost:8000
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollensim"
+)
+
+var (
+	addr     = flag.String("addr", ":8000", "listen address")
+	width    = flag.Int("width", 640, "snapshot width")
+	height   = flag.Int("height", 480, "snapshot height")
+	latency  = flag.Duration("latency", 0, "delay added to every response")
+	maxSpeed = flag.Float64("max-speed", 3, "head speed toward the target in rad/s (0 = instant)")
+	debug    = flag.Bool("debug", false, "log every request")
+)
+
+func main() {
+	flag.Parse()
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+
+	cfg := pollensim.DefaultConfig()
+	cfg.Width = *width
+	cfg.Height = *height
+	cfg.Latency = *latency
+	cfg.MaxSpeed = *maxSpeed
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           pollensim.New(cfg, logger),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	logger.Info("pollen-sim listening", "addr", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("pollen-sim failed", "error", err)
+		os.Exit(1)
+	}
+}
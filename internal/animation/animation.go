@@ -0,0 +1,288 @@
+// Package animation generates procedural antenna motion (noise-driven idle
+// sway and speech-reactive twitches) blended with explicit antenna targets.
+package animation
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Config holds animation configuration
+type Config struct {
+	Rate          time.Duration // Frame interval
+	SwayAmplitude float64       // Idle sway amplitude (radians)
+	SwayPeriod    time.Duration // Approximate period of the idle sway
+	TwitchGain    float64       // Twitch amplitude at full speech energy (radians)
+	TwitchDecay   time.Duration // Time constant for twitch decay
+	TwitchFreq    float64       // Twitch oscillation frequency (Hz)
+	EnergyRef     float64       // Speech energy treated as full scale
+	TargetHold    time.Duration // How long an explicit target fully overrides procedural motion
+	TargetBlend   time.Duration // Crossfade time back to procedural motion after the hold
+	Seed          int64         // Noise seed (0 = fixed default)
+	MinAntennaRad float64       // Lower output clamp
+	MaxAntennaRad float64       // Upper output clamp
+}
+
+// DefaultConfig returns gentle lifelike defaults
+func DefaultConfig() Config {
+	return Config{
+		Rate:          50 * time.Millisecond,
+		SwayAmplitude: 0.15,
+		SwayPeriod:    4 * time.Second,
+		TwitchGain:    0.25,
+		TwitchDecay:   300 * time.Millisecond,
+		TwitchFreq:    6,
+		EnergyRef:     2e6,
+		TargetHold:    2 * time.Second,
+		TargetBlend:   1 * time.Second,
+		MinAntennaRad: -1.2,
+		MaxAntennaRad: 1.2,
+	}
+}
+
+// Actuator moves the antennas (implemented by pollen.Client)
+type Actuator interface {
+	SetAntennas(ctx context.Context, antennas [2]float64) error
+}
+
+// Animator produces antenna frames at a fixed rate
+type Animator struct {
+	cfg      Config
+	actuator Actuator
+	logger   *slog.Logger
+	noise    *noise1D
+	start    time.Time
+
+	mu        sync.Mutex
+	target    [2]float64
+	targetAt  time.Time
+	hasTarget bool
+	twitch    float64
+	twitchAt  time.Time
+	lastFrame [2]float64
+
+	// Stats
+	framesSent  atomic.Uint64
+	sendErrors  atomic.Uint64
+	twitchCount atomic.Uint64
+}
+
+// NewAnimator creates a new antenna animator
+func NewAnimator(cfg Config, actuator Actuator, logger *slog.Logger) *Animator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultConfig().Rate
+	}
+	if cfg.SwayPeriod <= 0 {
+		cfg.SwayPeriod = DefaultConfig().SwayPeriod
+	}
+
+	return &Animator{
+		cfg:      cfg,
+		actuator: actuator,
+		logger:   logger,
+		noise:    newNoise1D(cfg.Seed),
+		start:    time.Now(),
+	}
+}
+
+// SetTarget applies an explicit antenna target (e.g., from the cloud)
+func (a *Animator) SetTarget(antennas [2]float64) {
+	a.mu.Lock()
+	a.target = antennas
+	a.targetAt = time.Now()
+	a.hasTarget = true
+	a.mu.Unlock()
+}
+
+// SetAntennas applies an explicit target so other antenna drivers blend
+// into the animation instead of fighting it
+func (a *Animator) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	a.SetTarget(antennas)
+	return nil
+}
+
+// Feed updates twitch state from a tracker result
+func (a *Animator) Feed(result doa.Result) {
+	if !result.Speaking || a.cfg.EnergyRef <= 0 {
+		return
+	}
+
+	level := math.Min(result.TotalEnergy/a.cfg.EnergyRef, 1)
+	now := result.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Only re-trigger when the new impulse exceeds what is still ringing
+	current := a.decayedTwitch(now)
+	if level > current {
+		a.twitch = level
+		a.twitchAt = now
+		a.twitchCount.Add(1)
+	}
+}
+
+// decayedTwitch returns the twitch level at time t (caller holds mu)
+func (a *Animator) decayedTwitch(t time.Time) float64 {
+	if a.twitch == 0 || a.cfg.TwitchDecay <= 0 {
+		return 0
+	}
+	return a.twitch * math.Exp(-t.Sub(a.twitchAt).Seconds()/a.cfg.TwitchDecay.Seconds())
+}
+
+// Frame computes the antenna positions at time t
+func (a *Animator) Frame(t time.Time) [2]float64 {
+	elapsed := t.Sub(a.start).Seconds()
+	x := elapsed / a.cfg.SwayPeriod.Seconds()
+
+	// Idle sway: independent noise per antenna so they don't move in lockstep
+	var frame [2]float64
+	frame[0] = a.cfg.SwayAmplitude * a.noise.at(x)
+	frame[1] = -a.cfg.SwayAmplitude * a.noise.at(x+17.3)
+
+	a.mu.Lock()
+	twitch := a.decayedTwitch(t)
+	target, targetAt, hasTarget := a.target, a.targetAt, a.hasTarget
+	a.mu.Unlock()
+
+	// Reactive twitch: a fast decaying oscillation, antennas in opposition
+	if twitch > 0.001 {
+		osc := twitch * a.cfg.TwitchGain * math.Sin(2*math.Pi*a.cfg.TwitchFreq*t.Sub(a.start).Seconds())
+		frame[0] += osc
+		frame[1] -= osc
+	}
+
+	// Blend with an explicit target
+	if hasTarget {
+		w := targetWeight(t.Sub(targetAt), a.cfg.TargetHold, a.cfg.TargetBlend)
+		for i := range frame {
+			frame[i] = w*target[i] + (1-w)*frame[i]
+		}
+	}
+
+	for i := range frame {
+		frame[i] = math.Max(a.cfg.MinAntennaRad, math.Min(a.cfg.MaxAntennaRad, frame[i]))
+	}
+	return frame
+}
+
+// targetWeight is 1 during the hold, then fades linearly to 0 over blend
+func targetWeight(age, hold, blend time.Duration) float64 {
+	if age <= hold {
+		return 1
+	}
+	if blend <= 0 || age >= hold+blend {
+		return 0
+	}
+	return 1 - float64(age-hold)/float64(blend)
+}
+
+// Current returns the most recently sent frame
+func (a *Animator) Current() [2]float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastFrame
+}
+
+// Run sends frames to the actuator until ctx is cancelled
+func (a *Animator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			frame := a.Frame(now)
+
+			a.mu.Lock()
+			a.lastFrame = frame
+			a.mu.Unlock()
+
+			if a.actuator == nil {
+				continue
+			}
+			if err := a.actuator.SetAntennas(ctx, frame); err != nil {
+				a.sendErrors.Add(1)
+				a.logger.Debug("antenna frame failed", "error", err)
+				continue
+			}
+			a.framesSent.Add(1)
+		}
+	}
+}
+
+// Stats contains animator statistics
+type Stats struct {
+	FramesSent uint64 `json:"frames_sent"`
+	SendErrors uint64 `json:"send_errors"`
+	Twitches   uint64 `json:"twitches"`
+}
+
+// GetStats returns animator statistics
+func (a *Animator) GetStats() Stats {
+	return Stats{
+		FramesSent: a.framesSent.Load(),
+		SendErrors: a.sendErrors.Load(),
+		Twitches:   a.twitchCount.Load(),
+	}
+}
+
+// noise1D is a 1D Perlin gradient noise generator with output in [-1, 1]
+type noise1D struct {
+	perm [512]uint8
+}
+
+func newNoise1D(seed int64) *noise1D {
+	n := &noise1D{}
+	var p [256]uint8
+	for i := range p {
+		p[i] = uint8(i)
+	}
+
+	// Deterministic Fisher-Yates shuffle with a small LCG
+	state := uint64(seed) ^ 0x9E3779B97F4A7C15
+	for i := 255; i > 0; i-- {
+		state = state*6364136223846793005 + 1442695040888963407
+		j := int((state >> 33) % uint64(i+1))
+		p[i], p[j] = p[j], p[i]
+	}
+
+	for i := range n.perm {
+		n.perm[i] = p[i&255]
+	}
+	return n
+}
+
+func (n *noise1D) at(x float64) float64 {
+	xf := math.Floor(x)
+	i := int(xf) & 255
+	f := x - xf
+
+	g0 := grad(n.perm[i], f)
+	g1 := grad(n.perm[i+1], f-1)
+
+	// Quintic fade curve
+	u := f * f * f * (f*(f*6-15) + 10)
+
+	// 1D gradient noise peaks at ±0.5; scale to ±1
+	return 2 * (g0 + u*(g1-g0))
+}
+
+func grad(hash uint8, x float64) float64 {
+	g := float64(hash&15)/7.5 - 1 // [-1, 1]
+	return g * x
+}
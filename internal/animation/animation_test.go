@@ -0,0 +1,125 @@
+package animation
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+type fakeActuator struct {
+	mu     sync.Mutex
+	frames [][2]float64
+}
+
+func (f *fakeActuator) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames = append(f.frames, antennas)
+	return nil
+}
+
+func TestNoise_BoundedAndSmooth(t *testing.T) {
+	n := newNoise1D(42)
+
+	prev := n.at(0)
+	for i := 1; i < 2000; i++ {
+		v := n.at(float64(i) * 0.01)
+		if v < -1 || v > 1 {
+			t.Fatalf("noise out of range at %d: %f", i, v)
+		}
+		if math.Abs(v-prev) > 0.1 {
+			t.Fatalf("noise not smooth at %d: %f -> %f", i, prev, v)
+		}
+		prev = v
+	}
+
+	if n.at(3) != 0 {
+		t.Error("gradient noise should be zero at integer lattice points")
+	}
+}
+
+func TestFrame_SwayWithinAmplitude(t *testing.T) {
+	cfg := DefaultConfig()
+	a := NewAnimator(cfg, nil, nil)
+
+	for i := 0; i < 500; i++ {
+		frame := a.Frame(a.start.Add(time.Duration(i) * 37 * time.Millisecond))
+		for _, v := range frame {
+			if math.Abs(v) > cfg.SwayAmplitude+1e-9 {
+				t.Fatalf("idle frame %v exceeds sway amplitude", frame)
+			}
+		}
+	}
+}
+
+func TestFrame_TargetBlend(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SwayAmplitude = 0
+	a := NewAnimator(cfg, nil, nil)
+
+	a.SetTarget([2]float64{0.8, -0.8})
+	at := a.targetAt
+
+	if got := a.Frame(at.Add(cfg.TargetHold / 2)); got != [2]float64{0.8, -0.8} {
+		t.Errorf("expected target during hold, got %v", got)
+	}
+
+	mid := a.Frame(at.Add(cfg.TargetHold + cfg.TargetBlend/2))
+	if math.Abs(mid[0]-0.4) > 1e-9 {
+		t.Errorf("expected half-blended target, got %v", mid)
+	}
+
+	if got := a.Frame(at.Add(cfg.TargetHold + cfg.TargetBlend)); got != [2]float64{0, 0} {
+		t.Errorf("expected procedural motion after blend, got %v", got)
+	}
+}
+
+func TestFeed_TwitchScalesWithEnergy(t *testing.T) {
+	cfg := DefaultConfig()
+	a := NewAnimator(cfg, nil, nil)
+	now := time.Now()
+
+	a.Feed(doa.Result{Reading: doa.Reading{Speaking: false, TotalEnergy: cfg.EnergyRef, Timestamp: now}})
+	if a.GetStats().Twitches != 0 {
+		t.Error("silence should not twitch")
+	}
+
+	a.Feed(doa.Result{Reading: doa.Reading{Speaking: true, TotalEnergy: cfg.EnergyRef / 4, Timestamp: now}})
+	a.Feed(doa.Result{Reading: doa.Reading{Speaking: true, TotalEnergy: cfg.EnergyRef / 8, Timestamp: now}})
+	a.Feed(doa.Result{Reading: doa.Reading{Speaking: true, TotalEnergy: cfg.EnergyRef * 10, Timestamp: now}})
+
+	if a.GetStats().Twitches != 2 {
+		t.Errorf("expected 2 twitches, got %d", a.GetStats().Twitches)
+	}
+	if a.twitch != 1 {
+		t.Errorf("twitch level should clamp to 1, got %f", a.twitch)
+	}
+
+	if got := a.decayedTwitch(now.Add(10 * cfg.TwitchDecay)); got > 0.001 {
+		t.Errorf("twitch should decay, got %f", got)
+	}
+}
+
+func TestRun_SendsFrames(t *testing.T) {
+	act := &fakeActuator{}
+	cfg := DefaultConfig()
+	cfg.Rate = 5 * time.Millisecond
+	a := NewAnimator(cfg, act, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	a.Run(ctx)
+
+	if a.GetStats().FramesSent == 0 {
+		t.Error("expected frames to be sent")
+	}
+	act.mu.Lock()
+	defer act.mu.Unlock()
+	if len(act.frames) == 0 || act.frames[len(act.frames)-1] != a.Current() {
+		t.Error("Current() should match the last sent frame")
+	}
+}
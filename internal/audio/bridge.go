@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,6 +13,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
 )
 
 // Config holds audio bridge configuration
@@ -20,7 +23,12 @@ type Config struct {
 	Channels      int           // Number of channels (default: 1 for mono)
 	ChunkDuration time.Duration // Duration of each audio chunk (default: 100ms)
 	PlaybackCmd   string        // Command for audio playback (default: "aplay")
-	CaptureCmd    string        // Command for audio capture (default: "arecord")
+	CaptureCmd    string        // Command for audio capture (default: "arecord"), run once as a continuous stream
+
+	MaxQueue        int           // Clips waiting for the speaker before ErrQueueFull
+	PlaybackTimeout time.Duration // Longest a single clip may play
+	DeviceRate      int           // ALSA playback rate clips are resampled to (0 = each clip's own rate)
+	MP3Command      string        // MP3 decoder (ffmpeg-compatible arguments)
 }
 
 // DefaultConfig returns sensible defaults for Raspberry Pi
@@ -31,6 +39,11 @@ func DefaultConfig() Config {
 		ChunkDuration: 100 * time.Millisecond,
 		PlaybackCmd:   "aplay",
 		CaptureCmd:    "arecord",
+
+		MaxQueue:        16,
+		PlaybackTimeout: 60 * time.Second,
+		DeviceRate:      16000,
+		MP3Command:      "ffmpeg",
 	}
 }
 
@@ -40,6 +53,25 @@ type AudioChunk struct {
 	SampleRate int       // Sample rate
 	Channels   int       // Channel count
 	Timestamp  time.Time // Capture timestamp
+	Beam       *Beam     // Talker direction while captured (nil if unknown)
+}
+
+// Duration returns how much audio the chunk holds
+func (c AudioChunk) Duration() time.Duration {
+	if c.SampleRate <= 0 || c.Channels <= 0 {
+		return 0
+	}
+	frames := len(c.Data) / (2 * c.Channels)
+	return time.Duration(int64(frames) * int64(time.Second) / int64(c.SampleRate))
+}
+
+// Beam describes where speech is coming from, from the mic array's DOA
+type Beam struct {
+	Angle       float64   // Radians (0=front, +left, -right)
+	Confidence  float64   // 0-1
+	DominantMic int       // Mic with the most speech energy (-1 = none)
+	Speaking    bool      // Voice activity
+	Timestamp   time.Time // When the DOA reading was taken
 }
 
 // Bridge handles bidirectional audio streaming
@@ -47,19 +79,42 @@ type Bridge struct {
 	cfg    Config
 	logger *slog.Logger
 
-	mu           sync.Mutex
-	capturing    bool
-	captureCmd   *exec.Cmd
-	cancelFunc   context.CancelFunc
+	mu            sync.Mutex
+	capturing     bool
+	captureCmd    *exec.Cmd
+	captureSource CaptureSource // Replaces CaptureCmd when set
+	cancelFunc    context.CancelFunc
+	captureDone   chan struct{} // Closed when the capture loop exits
 
 	// Callbacks
 	onAudioChunk func(AudioChunk)
+	onPlayback   func(PlaybackEvent)
+	beamSource   func() (Beam, bool)
+
+	// Clips being played through the speaker (guarded by mu)
+	speakerActive int
+
+	// Playback queue, ordered by priority then arrival
+	playMu        sync.Mutex
+	queue         []Clip
+	playing       *Clip
+	cancelPlay    context.CancelCauseFunc
+	playWake      chan struct{}
+	playClosed    bool
+	startPlayback sync.Once
+	playDone      chan struct{} // Closed when the playback loop exits
 
 	// Stats
 	chunksCaptured atomic.Uint64
 	chunksPlayed   atomic.Uint64
 	captureErrors  atomic.Uint64
 	playbackErrors atomic.Uint64
+
+	captureRestarts atomic.Uint64
+	captureGaps     atomic.Uint64
+
+	playInterrupted atomic.Uint64
+	playDropped     atomic.Uint64
 }
 
 // NewBridge creates a new audio bridge
@@ -67,10 +122,18 @@ func NewBridge(cfg Config, logger *slog.Logger) *Bridge {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if cfg.MaxQueue <= 0 {
+		cfg.MaxQueue = DefaultConfig().MaxQueue
+	}
+	if cfg.PlaybackTimeout <= 0 {
+		cfg.PlaybackTimeout = DefaultConfig().PlaybackTimeout
+	}
 
 	return &Bridge{
-		cfg:    cfg,
-		logger: logger,
+		cfg:      cfg,
+		logger:   logger,
+		playWake: make(chan struct{}, 1),
+		playDone: make(chan struct{}),
 	}
 }
 
@@ -81,6 +144,69 @@ func (b *Bridge) OnAudioChunk(callback func(AudioChunk)) {
 	b.mu.Unlock()
 }
 
+// PlaybackEvent reports the speaker starting or stopping, so listeners can
+// tell the robot's own voice from someone talking
+type PlaybackEvent struct {
+	Active    bool      // Audio is coming out of the speaker
+	Timestamp time.Time // When playback started or stopped
+}
+
+// TopicPlayback carries speaker start/stop events
+var TopicPlayback = bus.NewTopic[PlaybackEvent]("audio.playback")
+
+// OnPlayback sets the callback for speaker start/stop events
+func (b *Bridge) OnPlayback(callback func(PlaybackEvent)) {
+	b.mu.Lock()
+	b.onPlayback = callback
+	b.mu.Unlock()
+}
+
+// speakerStarted and speakerStopped bracket each clip sent to the speaker;
+// only the first start and last stop are reported
+func (b *Bridge) speakerStarted() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.speakerActive++
+	if b.speakerActive == 1 && b.onPlayback != nil {
+		b.onPlayback(PlaybackEvent{Active: true, Timestamp: time.Now()})
+	}
+}
+
+func (b *Bridge) speakerStopped() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.speakerActive--
+	if b.speakerActive == 0 && b.onPlayback != nil {
+		b.onPlayback(PlaybackEvent{Active: false, Timestamp: time.Now()})
+	}
+}
+
+// SetBeamSource sets where captured chunks get their Beam annotation; fn
+// reports false when no direction is known
+func (b *Bridge) SetBeamSource(fn func() (Beam, bool)) {
+	b.mu.Lock()
+	b.beamSource = fn
+	b.mu.Unlock()
+}
+
+// SetCaptureSource captures from src instead of running CaptureCmd (nil =
+// CaptureCmd). It takes effect the next time the capture stream starts.
+func (b *Bridge) SetCaptureSource(src CaptureSource) {
+	b.mu.Lock()
+	b.captureSource = src
+	b.mu.Unlock()
+}
+
+// TopicChunks carries captured microphone audio
+var TopicChunks = bus.NewTopic[AudioChunk]("audio.chunks")
+
+// PublishTo publishes captured audio and speaker start/stop events to the
+// event bus, replacing the OnAudioChunk and OnPlayback callbacks
+func (b *Bridge) PublishTo(events *bus.Bus) {
+	b.OnAudioChunk(bus.Publisher(events, TopicChunks))
+	b.OnPlayback(bus.Publisher(events, TopicPlayback))
+}
+
 // StartCapture begins capturing audio from the microphone
 func (b *Bridge) StartCapture(ctx context.Context) error {
 	b.mu.Lock()
@@ -91,14 +217,20 @@ func (b *Bridge) StartCapture(ctx context.Context) error {
 	b.capturing = true
 
 	ctx, b.cancelFunc = context.WithCancel(ctx)
+	done := make(chan struct{})
+	b.captureDone = done
 	b.mu.Unlock()
 
 	b.logger.Info("starting audio capture",
+		"source", b.captureSourceName(),
 		"sample_rate", b.cfg.SampleRate,
 		"channels", b.cfg.Channels,
 	)
 
-	go b.captureLoop(ctx)
+	go func() {
+		defer close(done)
+		b.captureLoop(ctx)
+	}()
 	return nil
 }
 
@@ -121,68 +253,8 @@ func (b *Bridge) StopCapture() {
 	b.logger.Info("audio capture stopped")
 }
 
-// captureLoop runs the audio capture loop
-func (b *Bridge) captureLoop(ctx context.Context) {
-	chunkSize := b.cfg.SampleRate * b.cfg.Channels * 2 * int(b.cfg.ChunkDuration.Milliseconds()) / 1000
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		chunk, err := b.captureChunk(ctx, chunkSize)
-		if err != nil {
-			b.captureErrors.Add(1)
-			b.logger.Debug("capture error", "error", err)
-			time.Sleep(100 * time.Millisecond)
-			continue
-		}
-
-		b.chunksCaptured.Add(1)
-
-		b.mu.Lock()
-		callback := b.onAudioChunk
-		b.mu.Unlock()
-
-		if callback != nil {
-			callback(*chunk)
-		}
-	}
-}
-
-// captureChunk captures a single audio chunk
-func (b *Bridge) captureChunk(ctx context.Context, size int) (*AudioChunk, error) {
-	// Use arecord to capture audio
-	// arecord -f S16_LE -r 16000 -c 1 -d 0.1 -t raw -q
-	duration := float64(b.cfg.ChunkDuration.Milliseconds()) / 1000.0
-
-	cmd := exec.CommandContext(ctx, b.cfg.CaptureCmd,
-		"-f", "S16_LE",
-		"-r", fmt.Sprintf("%d", b.cfg.SampleRate),
-		"-c", fmt.Sprintf("%d", b.cfg.Channels),
-		"-d", fmt.Sprintf("%.3f", duration),
-		"-t", "raw",
-		"-q",
-	)
-
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("capture command failed: %w", err)
-	}
-
-	return &AudioChunk{
-		Data:       stdout.Bytes(),
-		SampleRate: b.cfg.SampleRate,
-		Channels:   b.cfg.Channels,
-		Timestamp:  time.Now(),
-	}, nil
-}
-
-// PlayAudio plays audio data through the speaker
+// PlayAudio plays audio data through the speaker immediately, bypassing the
+// playback queue
 func (b *Bridge) PlayAudio(ctx context.Context, data []byte, format string, sampleRate int) error {
 	// Decode base64 if needed
 	audioData := data
@@ -195,6 +267,11 @@ func (b *Bridge) PlayAudio(ctx context.Context, data []byte, format string, samp
 		}
 	}
 
+	if b.cfg.DeviceRate > 0 && sampleRate != b.cfg.DeviceRate {
+		audioData = Resample(audioData, sampleRate, b.cfg.DeviceRate)
+		sampleRate = b.cfg.DeviceRate
+	}
+
 	// Use aplay to play audio
 	// aplay -f S16_LE -r <rate> -c 1 -t raw -q
 	cmd := exec.CommandContext(ctx, b.cfg.PlaybackCmd,
@@ -215,6 +292,8 @@ func (b *Bridge) PlayAudio(ctx context.Context, data []byte, format string, samp
 		b.playbackErrors.Add(1)
 		return fmt.Errorf("start playback: %w", err)
 	}
+	b.speakerStarted()
+	defer b.speakerStopped()
 
 	go func() {
 		io.Copy(stdin, bytes.NewReader(audioData))
@@ -222,6 +301,9 @@ func (b *Bridge) PlayAudio(ctx context.Context, data []byte, format string, samp
 	}()
 
 	if err := cmd.Wait(); err != nil {
+		if errors.Is(context.Cause(ctx), ErrInterrupted) {
+			return ErrInterrupted
+		}
 		b.playbackErrors.Add(1)
 		return fmt.Errorf("playback wait: %w", err)
 	}
@@ -230,15 +312,20 @@ func (b *Bridge) PlayAudio(ctx context.Context, data []byte, format string, samp
 	return nil
 }
 
-// PlayAudioAsync plays audio in the background
+// PlayAudioAsync queues audio at TTS priority without waiting for it
 func (b *Bridge) PlayAudioAsync(data []byte, format string, sampleRate int) {
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := b.PlayAudio(ctx, data, format, sampleRate); err != nil {
-			b.logger.Warn("async playback error", "error", err)
+	if format == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			b.playbackErrors.Add(1)
+			b.logger.Warn("async playback error", "error", fmt.Errorf("decode base64: %w", err))
+			return
 		}
-	}()
+		data = decoded
+	}
+	if _, err := b.Enqueue(Clip{Data: data, SampleRate: sampleRate, Priority: PriorityTTS}); err != nil {
+		b.logger.Warn("async playback error", "error", err)
+	}
 }
 
 // Stats contains audio bridge statistics
@@ -248,6 +335,12 @@ type Stats struct {
 	CaptureErrors  uint64 `json:"capture_errors"`
 	PlaybackErrors uint64 `json:"playback_errors"`
 	Capturing      bool   `json:"capturing"`
+	CaptureSource  string `json:"capture_source"`
+
+	CaptureRestarts uint64 `json:"capture_restarts"` // Capture stream restarted after exiting
+	CaptureGaps     uint64 `json:"capture_gaps"`     // Stream clock re-anchored to wall time
+
+	Playback PlaybackStats `json:"playback"`
 }
 
 // GetStats returns bridge statistics
@@ -262,21 +355,83 @@ func (b *Bridge) GetStats() Stats {
 		CaptureErrors:  b.captureErrors.Load(),
 		PlaybackErrors: b.playbackErrors.Load(),
 		Capturing:      capturing,
+		CaptureSource:  b.captureSourceName(),
+
+		CaptureRestarts: b.captureRestarts.Load(),
+		CaptureGaps:     b.captureGaps.Load(),
+		Playback:        b.playbackStats(),
+	}
+}
+
+// Shutdown stops capture and waits for the last chunk to be delivered, drops
+// queued clips and lets the one playing finish. Whatever is still running
+// when ctx ends is cut off.
+func (b *Bridge) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	captureDone := b.captureDone
+	b.mu.Unlock()
+	b.StopCapture()
+	if captureDone != nil {
+		select {
+		case <-captureDone:
+		case <-ctx.Done():
+		}
+	}
+
+	b.playMu.Lock()
+	b.playClosed = true
+	if n := b.flushLocked(); n > 0 {
+		b.logger.Info("dropped queued audio on shutdown", "clips", n)
+	}
+	select {
+	case b.playWake <- struct{}{}:
+	default:
+	}
+	b.playMu.Unlock()
+
+	// Nothing was ever queued, so there is no loop to wait for
+	b.startPlayback.Do(func() { close(b.playDone) })
+
+	select {
+	case <-b.playDone:
+		return nil
+	case <-ctx.Done():
+		b.Interrupt()
+		<-b.playDone
+		return fmt.Errorf("audio shutdown: %w", ctx.Err())
 	}
 }
 
 // Close stops all audio operations
 func (b *Bridge) Close() error {
 	b.StopCapture()
+	b.closePlayback()
 	return nil
 }
 
-// IsAvailable checks if audio commands are available
+// captureSourceName names where mic audio comes from
+func (b *Bridge) captureSourceName() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.captureSource != nil {
+		return b.captureSource.Name()
+	}
+	return b.cfg.CaptureCmd
+}
+
+// IsAvailable checks if audio commands are available (the capture command
+// only when no capture source replaces it)
 func (b *Bridge) IsAvailable() bool {
 	_, err := exec.LookPath(b.cfg.PlaybackCmd)
 	if err != nil {
 		return false
 	}
+	b.mu.Lock()
+	source := b.captureSource
+	b.mu.Unlock()
+	if source != nil {
+		return true
+	}
 	_, err = exec.LookPath(b.cfg.CaptureCmd)
 	return err == nil
 }
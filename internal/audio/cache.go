@@ -0,0 +1,253 @@
+package audio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheConfig holds speak audio cache configuration
+type CacheConfig struct {
+	Dir      string // Directory holding cached payloads
+	MaxBytes int64  // Evict least recently used payloads beyond this total size
+}
+
+// DefaultCacheConfig returns sensible defaults
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Dir:      "/var/lib/go-eva/speak-cache",
+		MaxBytes: 64 << 20,
+	}
+}
+
+const cacheSuffix = ".audio"
+
+// cacheEntry is one cached payload
+type cacheEntry struct {
+	size int64
+	used time.Time
+}
+
+// Cache keeps speak payloads on disk, keyed by CacheKey, so the cloud can
+// replay a phrase it has sent before by reference instead of resending it.
+// Payloads are stored exactly as they arrived (before any decoding), and the
+// least recently used are evicted once the cache outgrows MaxBytes. A file's
+// modification time records its last use, so the order survives a restart.
+type Cache struct {
+	cfg    CacheConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	total   int64
+
+	// Stats
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	stored    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// CacheKey returns the key a payload is cached under: its hex SHA-256
+func CacheKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validCacheKey reports whether key looks like a CacheKey, which also keeps
+// it from naming a path outside the cache directory
+func validCacheKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil && strings.ToLower(key) == key
+}
+
+// OpenCache creates the cache directory and indexes payloads already in it
+func OpenCache(cfg CacheConfig, logger *slog.Logger) (*Cache, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultCacheConfig().MaxBytes
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create speak cache dir: %w", err)
+	}
+
+	c := &Cache{
+		cfg:     cfg,
+		logger:  logger,
+		entries: make(map[string]cacheEntry),
+	}
+
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read speak cache dir: %w", err)
+	}
+	for _, e := range entries {
+		key, ok := strings.CutSuffix(e.Name(), cacheSuffix)
+		if !ok || e.IsDir() || !validCacheKey(key) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[key] = cacheEntry{size: info.Size(), used: info.ModTime()}
+		c.total += info.Size()
+	}
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+
+	logger.Info("speak cache opened",
+		"dir", cfg.Dir,
+		"entries", len(c.entries),
+		"bytes", c.total,
+	)
+
+	return c, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.cfg.Dir, key+cacheSuffix)
+}
+
+// Put stores data and returns its key. Payloads larger than the whole cache
+// are not stored.
+func (c *Cache) Put(data []byte) (string, error) {
+	key := CacheKey(data)
+	size := int64(len(data))
+	if size > c.cfg.MaxBytes {
+		return key, fmt.Errorf("payload of %d bytes exceeds the %d byte cache", size, c.cfg.MaxBytes)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := c.entries[key]; ok {
+		c.touchLocked(key, e, now)
+		return key, nil
+	}
+
+	// Write then rename so a crash never leaves a truncated payload behind
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return key, fmt.Errorf("write speak cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path(key)); err != nil {
+		os.Remove(tmp)
+		return key, fmt.Errorf("write speak cache: %w", err)
+	}
+
+	c.entries[key] = cacheEntry{size: size, used: now}
+	c.total += size
+	c.stored.Add(1)
+	c.evictLocked()
+	return key, nil
+}
+
+// Get returns the payload cached under key
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if !validCacheKey(key) {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || CacheKey(data) != key {
+		// Deleted or corrupted behind our back
+		c.logger.Warn("speak cache entry unreadable", "key", key, "error", err)
+		c.removeLocked(key)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.touchLocked(key, e, time.Now())
+	c.hits.Add(1)
+	return data, true
+}
+
+// touchLocked marks an entry used now, on disk too
+func (c *Cache) touchLocked(key string, e cacheEntry, now time.Time) {
+	e.used = now
+	c.entries[key] = e
+	os.Chtimes(c.path(key), now, now)
+}
+
+// evictLocked removes the least recently used entries until the cache fits
+func (c *Cache) evictLocked() {
+	if c.total <= c.cfg.MaxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].used.Before(c.entries[keys[j]].used) })
+
+	for _, key := range keys {
+		if c.total <= c.cfg.MaxBytes {
+			break
+		}
+		c.removeLocked(key)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *Cache) removeLocked(key string) {
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		c.logger.Warn("speak cache evict failed", "key", key, "error", err)
+	}
+	c.total -= c.entries[key].size
+	delete(c.entries, key)
+}
+
+// CacheStats contains speak cache statistics
+type CacheStats struct {
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	MaxBytes  int64  `json:"max_bytes"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Stored    uint64 `json:"stored"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// GetStats returns speak cache statistics
+func (c *Cache) GetStats() CacheStats {
+	c.mu.Lock()
+	entries, total := len(c.entries), c.total
+	c.mu.Unlock()
+
+	return CacheStats{
+		Entries:   entries,
+		Bytes:     total,
+		MaxBytes:  c.cfg.MaxBytes,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Stored:    c.stored.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
@@ -0,0 +1,95 @@
+package audio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_PutGet(t *testing.T) {
+	c, err := OpenCache(CacheConfig{Dir: t.TempDir(), MaxBytes: 1 << 20}, nil)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+
+	payload := []byte("I didn't catch that")
+	key, err := c.Put(payload)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if key != CacheKey(payload) {
+		t.Errorf("Put() key = %s, want CacheKey of the payload", key)
+	}
+
+	got, ok := c.Get(key)
+	if !ok || !bytes.Equal(got, payload) {
+		t.Fatalf("Get() = %q, %v", got, ok)
+	}
+	if _, ok := c.Get(CacheKey([]byte("never sent"))); ok {
+		t.Error("Get() hit for a key never stored")
+	}
+	if _, ok := c.Get("../../etc/passwd"); ok {
+		t.Error("Get() hit for a malformed key")
+	}
+
+	stats := c.GetStats()
+	if stats.Entries != 1 || stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := OpenCache(CacheConfig{Dir: t.TempDir(), MaxBytes: 250}, nil)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+
+	a, _ := c.Put(bytes.Repeat([]byte{'a'}, 100))
+	time.Sleep(time.Millisecond)
+	b, _ := c.Put(bytes.Repeat([]byte{'b'}, 100))
+	time.Sleep(time.Millisecond)
+	c.Get(a) // a is now more recent than b
+	time.Sleep(time.Millisecond)
+	cKey, _ := c.Put(bytes.Repeat([]byte{'c'}, 100))
+
+	if _, ok := c.Get(b); ok {
+		t.Error("least recently used entry was kept")
+	}
+	for _, key := range []string{a, cKey} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("entry %s evicted", key[:8])
+		}
+	}
+	if stats := c.GetStats(); stats.Evictions != 1 || stats.Bytes != 200 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if _, err := c.Put(bytes.Repeat([]byte{'d'}, 300)); err == nil {
+		t.Error("expected error for a payload larger than the cache")
+	}
+}
+
+func TestCache_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := OpenCache(CacheConfig{Dir: dir, MaxBytes: 1 << 20}, nil)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	key, _ := c.Put([]byte("hello again"))
+
+	// Stray files are ignored
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644)
+
+	c, err = OpenCache(CacheConfig{Dir: dir, MaxBytes: 1 << 20}, nil)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	if stats := c.GetStats(); stats.Entries != 1 {
+		t.Errorf("entries after reopen = %d, want 1", stats.Entries)
+	}
+	if got, ok := c.Get(key); !ok || string(got) != "hello again" {
+		t.Errorf("Get() after reopen = %q, %v", got, ok)
+	}
+}
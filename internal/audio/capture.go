@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Capture stream restart backoff
+const (
+	captureRetryMin = 100 * time.Millisecond
+	captureRetryMax = 5 * time.Second
+)
+
+// maxCaptureDrift is how far the stream clock may fall behind wall time
+// (an overrun or a stalled device) before chunk timestamps are re-anchored
+const maxCaptureDrift = 500 * time.Millisecond
+
+// maxBeamAge is how much older than a chunk a DOA reading may be and still
+// annotate it
+const maxBeamAge = 500 * time.Millisecond
+
+// captureLoop keeps a capture stream running, restarting it with backoff
+// whenever it exits
+func (b *Bridge) captureLoop(ctx context.Context) {
+	retry := captureRetryMin
+	for {
+		chunks, err := b.captureStream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.captureErrors.Add(1)
+		b.captureRestarts.Add(1)
+		if chunks > 0 {
+			retry = captureRetryMin
+		}
+		b.logger.Debug("capture stream ended", "error", err, "chunks", chunks, "retry_in", retry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(2*retry, captureRetryMax)
+	}
+}
+
+// captureStream runs one long-lived capture process (or opens the capture
+// source) and splits its output into chunks until it exits or ctx is
+// cancelled. Chunk timestamps follow the sample count, so consecutive
+// chunks are exactly ChunkDuration apart.
+func (b *Bridge) captureStream(ctx context.Context) (int, error) {
+	frameSize := 2 * b.cfg.Channels
+	frames := int(int64(b.cfg.SampleRate) * int64(b.cfg.ChunkDuration) / int64(time.Second))
+	if frames < 1 || frameSize < 2 {
+		return 0, fmt.Errorf("invalid capture format: %d Hz, %d channels, %v chunks", b.cfg.SampleRate, b.cfg.Channels, b.cfg.ChunkDuration)
+	}
+	chunkDuration := time.Duration(int64(frames) * int64(time.Second) / int64(b.cfg.SampleRate))
+
+	stream, wait, err := b.openCapture(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		anchor  time.Time // Wall time of the first sample since the last re-anchor
+		samples int64     // Frames read since anchor
+		chunks  int
+	)
+	for {
+		buf := make([]byte, frames*frameSize)
+		if _, err := io.ReadFull(stream, buf); err != nil {
+			// Reap the process; its exit status explains a short read
+			if waitErr := wait(); waitErr != nil {
+				err = waitErr
+			}
+			return chunks, fmt.Errorf("capture stream: %w", err)
+		}
+
+		now := time.Now()
+		samples += int64(frames)
+		at := anchor.Add(time.Duration(samples * int64(time.Second) / int64(b.cfg.SampleRate)))
+		if anchor.IsZero() || now.Sub(at) > maxCaptureDrift {
+			// First chunk, or samples were lost: restart the clock so this
+			// chunk ends now
+			if !anchor.IsZero() {
+				b.captureGaps.Add(1)
+			}
+			anchor, samples, at = now.Add(-chunkDuration), int64(frames), now
+		}
+
+		chunks++
+		b.chunksCaptured.Add(1)
+
+		b.mu.Lock()
+		callback := b.onAudioChunk
+		beamSource := b.beamSource
+		b.mu.Unlock()
+
+		if callback != nil {
+			callback(AudioChunk{
+				Data:       buf,
+				SampleRate: b.cfg.SampleRate,
+				Channels:   b.cfg.Channels,
+				Timestamp:  at,
+				Beam:       beamAt(beamSource, at),
+			})
+		}
+	}
+}
+
+// openCapture opens the capture source if one is set, or starts the
+// capture command. wait ends the stream and reports why it stopped.
+func (b *Bridge) openCapture(ctx context.Context) (stream io.Reader, wait func() error, err error) {
+	b.mu.Lock()
+	source := b.captureSource
+	b.mu.Unlock()
+
+	if source != nil {
+		rc, err := source.Open(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open %s capture: %w", source.Name(), err)
+		}
+		return rc, rc.Close, nil
+	}
+
+	// arecord -f S16_LE -r 16000 -c 1 -t raw -q (no duration: stream until killed)
+	cmd := exec.CommandContext(ctx, b.cfg.CaptureCmd,
+		"-f", "S16_LE",
+		"-r", strconv.Itoa(b.cfg.SampleRate),
+		"-c", strconv.Itoa(b.cfg.Channels),
+		"-t", "raw",
+		"-q",
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start capture: %w", err)
+	}
+
+	b.mu.Lock()
+	b.captureCmd = cmd
+	b.mu.Unlock()
+
+	return stdout, func() error {
+		err := cmd.Wait()
+		b.mu.Lock()
+		if b.captureCmd == cmd {
+			b.captureCmd = nil
+		}
+		b.mu.Unlock()
+		return err
+	}, nil
+}
+
+// beamAt returns the beam from source if it is recent enough to describe a
+// chunk captured at t
+func beamAt(source func() (Beam, bool), t time.Time) *Beam {
+	if source == nil {
+		return nil
+	}
+	beam, ok := source()
+	if !ok || t.Sub(beam.Timestamp) > maxBeamAge {
+		return nil
+	}
+	return &beam
+}
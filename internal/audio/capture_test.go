@@ -0,0 +1,188 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRecorder writes a capture command that records its arguments to a
+// log file and then runs script
+func fakeRecorder(t *testing.T, script string) (cmd, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	cmd = filepath.Join(dir, "arecord")
+	log = filepath.Join(dir, "args.log")
+	body := "#!/bin/sh\necho \"$*\" >> " + log + "\n" + script + "\n"
+	if err := os.WriteFile(cmd, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return cmd, log
+}
+
+// collectChunks captures until n chunks arrive or the timeout expires
+func collectChunks(t *testing.T, bridge *Bridge, n int) []AudioChunk {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		chunks []AudioChunk
+		full   = make(chan struct{})
+	)
+	bridge.OnAudioChunk(func(chunk AudioChunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, chunk)
+		if len(chunks) == n {
+			close(full)
+		}
+	})
+
+	if err := bridge.StartCapture(context.Background()); err != nil {
+		t.Fatalf("StartCapture() error = %v", err)
+	}
+	defer bridge.StopCapture()
+
+	select {
+	case <-full:
+	case <-time.After(3 * time.Second):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]AudioChunk(nil), chunks...)
+}
+
+func TestCaptureStream_Continuous(t *testing.T) {
+	// Three chunks at once (3 × 3200 bytes), then keep the stream open
+	cmd, log := fakeRecorder(t, "head -c 9600 /dev/zero\nexec sleep 10")
+	cfg := DefaultConfig()
+	cfg.CaptureCmd = cmd
+	bridge := NewBridge(cfg, nil)
+
+	chunks := collectChunks(t, bridge, 3)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk.Data) != 3200 {
+			t.Errorf("chunk %d has %d bytes, want 3200", i, len(chunk.Data))
+		}
+		if i > 0 {
+			if gap := chunk.Timestamp.Sub(chunks[i-1].Timestamp); gap != 100*time.Millisecond {
+				t.Errorf("chunk %d is %v after the previous one, want 100ms", i, gap)
+			}
+		}
+	}
+
+	args, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(args)), "\n"); len(lines) != 1 {
+		t.Errorf("capture command started %d times, want 1", len(lines))
+	}
+	if strings.Contains(string(args), "-d") {
+		t.Errorf("capture command should stream without a duration: %s", args)
+	}
+	if stats := bridge.GetStats(); stats.ChunksCaptured != 3 || stats.CaptureRestarts != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCaptureStream_Restarts(t *testing.T) {
+	// Each run produces one chunk and exits
+	cmd, log := fakeRecorder(t, "head -c 3200 /dev/zero")
+	cfg := DefaultConfig()
+	cfg.CaptureCmd = cmd
+	bridge := NewBridge(cfg, nil)
+
+	if chunks := collectChunks(t, bridge, 2); len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	args, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(args), "\n"); runs < 2 {
+		t.Errorf("capture command ran %d times, want a restart", runs)
+	}
+	if bridge.GetStats().CaptureRestarts == 0 {
+		t.Error("CaptureRestarts should count the exited stream")
+	}
+}
+
+func TestCaptureStream_Beam(t *testing.T) {
+	cmd, _ := fakeRecorder(t, "head -c 3200 /dev/zero\nexec sleep 10")
+	cfg := DefaultConfig()
+	cfg.CaptureCmd = cmd
+	bridge := NewBridge(cfg, nil)
+	bridge.SetBeamSource(func() (Beam, bool) {
+		return Beam{Angle: 0.4, DominantMic: 2, Speaking: true, Timestamp: time.Now()}, true
+	})
+
+	chunks := collectChunks(t, bridge, 1)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if beam := chunks[0].Beam; beam == nil || beam.Angle != 0.4 || beam.DominantMic != 2 || !beam.Speaking {
+		t.Errorf("Beam = %+v", beam)
+	}
+}
+
+func TestCaptureStream_Source(t *testing.T) {
+	// The capture command doesn't exist; the source replaces it
+	cfg := DefaultConfig()
+	cfg.CaptureCmd = filepath.Join(t.TempDir(), "missing")
+	bridge := NewBridge(cfg, nil)
+	stream := &OpusStream{decode: func(p []byte) ([]byte, error) { return p, nil }}
+	bridge.SetCaptureSource(stream)
+
+	// Feed 20ms packets (640 bytes) until the stream is open and read
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				stream.WritePacket(make([]byte, 640))
+			}
+		}
+	}()
+
+	chunks := collectChunks(t, bridge, 2)
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	if len(chunks[0].Data) != 3200 {
+		t.Errorf("chunk has %d bytes, want 3200", len(chunks[0].Data))
+	}
+	if stats := bridge.GetStats(); stats.CaptureSource != CodecOpus || stats.CaptureRestarts != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestBeamAt(t *testing.T) {
+	now := time.Now()
+	source := func(at time.Time) func() (Beam, bool) {
+		return func() (Beam, bool) { return Beam{Timestamp: at}, true }
+	}
+
+	if beamAt(nil, now) != nil {
+		t.Error("no source should mean no beam")
+	}
+	if beamAt(func() (Beam, bool) { return Beam{}, false }, now) != nil {
+		t.Error("unknown direction should mean no beam")
+	}
+	if beamAt(source(now.Add(-time.Second)), now) != nil {
+		t.Error("stale reading should not annotate the chunk")
+	}
+	if beamAt(source(now.Add(-100*time.Millisecond)), now) == nil {
+		t.Error("recent reading should annotate the chunk")
+	}
+}
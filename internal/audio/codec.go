@@ -0,0 +1,178 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Codecs for audio payloads exchanged with the cloud
+const (
+	CodecPCM16 = "pcm16" // Raw little-endian PCM16
+	CodecOpus  = "opus"  // Length-prefixed Opus packets
+)
+
+// ErrOpusUnavailable is returned when the binary was built without Opus support
+var ErrOpusUnavailable = errors.New("opus support not compiled in (build with -tags opus)")
+
+// OpusFrameDuration is the audio duration of each Opus packet
+const OpusFrameDuration = 20 * time.Millisecond
+
+// DefaultOpusBitrate suits 16kHz mono speech
+const DefaultOpusBitrate = 24000
+
+// SupportedCodecs returns the codecs this build can encode and decode,
+// most preferred first
+func SupportedCodecs() []string {
+	if opusAvailable {
+		return []string{CodecOpus, CodecPCM16}
+	}
+	return []string{CodecPCM16}
+}
+
+// CodecSupported reports whether codec can be used in this build; an empty
+// codec means raw PCM16
+func CodecSupported(codec string) bool {
+	switch codec {
+	case "", CodecPCM16:
+		return true
+	case CodecOpus:
+		return opusAvailable
+	}
+	return false
+}
+
+// PayloadEncoder turns captured PCM16 chunks into payloads for one codec.
+// Opus input that doesn't fill a whole frame is held for the next chunk.
+type PayloadEncoder struct {
+	codec      string
+	opus       *opusEncoder
+	frameBytes int
+	pending    []byte
+}
+
+// NewPayloadEncoder creates an encoder; bitrate applies to Opus only
+// (DefaultOpusBitrate if <= 0)
+func NewPayloadEncoder(codec string, sampleRate, channels, bitrate int) (*PayloadEncoder, error) {
+	switch codec {
+	case "", CodecPCM16:
+		return &PayloadEncoder{codec: CodecPCM16}, nil
+	case CodecOpus:
+		if bitrate <= 0 {
+			bitrate = DefaultOpusBitrate
+		}
+		enc, err := newOpusEncoder(sampleRate, channels, bitrate)
+		if err != nil {
+			return nil, err
+		}
+		samples := sampleRate * int(OpusFrameDuration/time.Millisecond) / 1000
+		return &PayloadEncoder{
+			codec:      CodecOpus,
+			opus:       enc,
+			frameBytes: samples * channels * 2,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported audio codec %q", codec)
+}
+
+// Codec returns the codec name
+func (e *PayloadEncoder) Codec() string {
+	return e.codec
+}
+
+// Encode converts pcm to a payload. For Opus it may return nil while a frame
+// is still being filled.
+func (e *PayloadEncoder) Encode(pcm []byte) ([]byte, error) {
+	if e.opus == nil {
+		return append([]byte(nil), pcm...), nil
+	}
+
+	e.pending = append(e.pending, pcm...)
+
+	var packets [][]byte
+	for len(e.pending) >= e.frameBytes {
+		packet, err := e.opus.encode(e.pending[:e.frameBytes])
+		if err != nil {
+			return nil, fmt.Errorf("opus encode: %w", err)
+		}
+		packets = append(packets, packet)
+		e.pending = e.pending[e.frameBytes:]
+	}
+	e.pending = append([]byte(nil), e.pending...)
+
+	if len(packets) == 0 {
+		return nil, nil
+	}
+	return PackOpus(packets), nil
+}
+
+// Close releases codec resources
+func (e *PayloadEncoder) Close() {
+	if e.opus != nil {
+		e.opus.close()
+		e.opus = nil
+	}
+}
+
+// DecodePayload converts a payload in codec back to PCM16
+func DecodePayload(codec string, data []byte, sampleRate, channels int) ([]byte, error) {
+	switch codec {
+	case "", CodecPCM16:
+		return data, nil
+	case CodecOpus:
+		packets, err := UnpackOpus(data)
+		if err != nil {
+			return nil, err
+		}
+		dec, err := newOpusDecoder(sampleRate, channels)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.close()
+
+		var pcm []byte
+		for _, packet := range packets {
+			frame, err := dec.decode(packet)
+			if err != nil {
+				return nil, fmt.Errorf("opus decode: %w", err)
+			}
+			pcm = append(pcm, frame...)
+		}
+		return pcm, nil
+	}
+	return nil, fmt.Errorf("unsupported audio codec %q", codec)
+}
+
+// PackOpus frames packets as a 2-byte big-endian length followed by the packet
+func PackOpus(packets [][]byte) []byte {
+	size := 0
+	for _, p := range packets {
+		size += 2 + len(p)
+	}
+
+	out := make([]byte, 0, size)
+	for _, p := range packets {
+		out = binary.BigEndian.AppendUint16(out, uint16(len(p)))
+		out = append(out, p...)
+	}
+	return out
+}
+
+// UnpackOpus splits a PackOpus payload into packets
+func UnpackOpus(data []byte) ([][]byte, error) {
+	var packets [][]byte
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("truncated opus packet header")
+		}
+		n := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if n == 0 || n > len(data) {
+			return nil, fmt.Errorf("invalid opus packet length %d", n)
+		}
+		packets = append(packets, data[:n])
+		data = data[n:]
+	}
+	return packets, nil
+}
@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPackUnpackOpus(t *testing.T) {
+	packets := [][]byte{{1, 2, 3}, {4}, bytes.Repeat([]byte{9}, 300)}
+
+	got, err := UnpackOpus(PackOpus(packets))
+	if err != nil {
+		t.Fatalf("UnpackOpus() error = %v", err)
+	}
+	if len(got) != len(packets) {
+		t.Fatalf("got %d packets, want %d", len(got), len(packets))
+	}
+	for i := range packets {
+		if !bytes.Equal(got[i], packets[i]) {
+			t.Errorf("packet %d = %v, want %v", i, got[i], packets[i])
+		}
+	}
+
+	for _, bad := range [][]byte{{0}, {0, 5, 1}, {0, 0}} {
+		if _, err := UnpackOpus(bad); err == nil {
+			t.Errorf("UnpackOpus(%v) should fail", bad)
+		}
+	}
+}
+
+func TestPCM16Passthrough(t *testing.T) {
+	enc, err := NewPayloadEncoder(CodecPCM16, 16000, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+
+	pcm := []byte{1, 2, 3, 4}
+	payload, err := enc.Encode(pcm)
+	if err != nil || !bytes.Equal(payload, pcm) {
+		t.Errorf("Encode() = %v, %v", payload, err)
+	}
+
+	decoded, err := DecodePayload("", payload, 16000, 1)
+	if err != nil || !bytes.Equal(decoded, pcm) {
+		t.Errorf("DecodePayload() = %v, %v", decoded, err)
+	}
+
+	if _, err := NewPayloadEncoder("mp3", 16000, 1, 0); err == nil {
+		t.Error("unknown codec should fail")
+	}
+	if CodecSupported("mp3") {
+		t.Error("mp3 reported as supported")
+	}
+}
+
+func TestOpusRoundTrip(t *testing.T) {
+	enc, err := NewPayloadEncoder(CodecOpus, 16000, 1, 0)
+	if !opusAvailable {
+		if !errors.Is(err, ErrOpusUnavailable) {
+			t.Errorf("error = %v, want ErrOpusUnavailable", err)
+		}
+		if CodecSupported(CodecOpus) || len(SupportedCodecs()) != 1 {
+			t.Error("opus advertised without opus support")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+
+	frameBytes := 16000 * 20 / 1000 * 2 // 20ms mono
+
+	// Half a frame is held back
+	payload, err := enc.Encode(make([]byte, frameBytes/2))
+	if err != nil || payload != nil {
+		t.Fatalf("partial frame: payload %v, err %v", payload, err)
+	}
+
+	// 100ms more completes 5 frames with half a frame left over
+	payload, err = enc.Encode(make([]byte, 5*frameBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, err := UnpackOpus(payload)
+	if err != nil || len(packets) != 5 {
+		t.Fatalf("got %d packets (%v), want 5", len(packets), err)
+	}
+
+	pcm, err := DecodePayload(CodecOpus, payload, 16000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pcm) != 5*frameBytes {
+		t.Errorf("decoded %d bytes, want %d", len(pcm), 5*frameBytes)
+	}
+}
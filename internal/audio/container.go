@@ -0,0 +1,224 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Container formats accepted for speaker playback
+const (
+	FormatPCM16 = "pcm16" // Raw little-endian PCM16 at a given rate and channel count
+	FormatWAV   = "wav"   // RIFF/WAVE with PCM16 samples
+	FormatMP3   = "mp3"   // Decoded by an external command (Config.MP3Command)
+)
+
+// DetectFormat sniffs the container from the leading bytes; anything
+// unrecognized is treated as raw PCM16
+func DetectFormat(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return FormatWAV
+	case len(data) >= 3 && string(data[0:3]) == "ID3":
+		return FormatMP3
+	}
+	return FormatPCM16
+}
+
+// normalizeFormat maps a speak format name to a container; names that only
+// describe the transport encoding mean raw PCM16
+func normalizeFormat(format string, data []byte) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		return DetectFormat(data), nil
+	case FormatPCM16, "pcm", "raw", "s16le", "base64":
+		return FormatPCM16, nil
+	case FormatWAV, "wave":
+		return FormatWAV, nil
+	case FormatMP3, "mpeg":
+		return FormatMP3, nil
+	}
+	return "", fmt.Errorf("unsupported audio format %q (valid: pcm16, wav, mp3)", format)
+}
+
+// DecodeClip turns a speak payload into a mono PCM16 clip. sampleRate and
+// channels describe raw PCM16 only; containers carry their own.
+func (b *Bridge) DecodeClip(ctx context.Context, format string, data []byte, sampleRate, channels int) (Clip, error) {
+	container, err := normalizeFormat(format, data)
+	if err != nil {
+		return Clip{}, err
+	}
+
+	switch container {
+	case FormatWAV:
+		pcm, rate, err := ParseWAV(data)
+		if err != nil {
+			return Clip{}, err
+		}
+		return Clip{Data: pcm, SampleRate: rate}, nil
+
+	case FormatMP3:
+		rate := b.cfg.DeviceRate
+		if rate <= 0 {
+			rate = b.cfg.SampleRate
+		}
+		pcm, err := DecodeMP3(ctx, b.cfg.MP3Command, data, rate)
+		if err != nil {
+			return Clip{}, err
+		}
+		return Clip{Data: pcm, SampleRate: rate}, nil
+	}
+
+	if sampleRate <= 0 {
+		sampleRate = b.cfg.SampleRate
+	}
+	if channels > 1 {
+		data = downmix(data, channels)
+	}
+	return Clip{Data: data, SampleRate: sampleRate}, nil
+}
+
+// ParseWAV extracts PCM16 samples from a RIFF/WAVE file, mixing multiple
+// channels down to mono
+func ParseWAV(data []byte) (pcm []byte, sampleRate int, err error) {
+	if DetectFormat(data) != FormatWAV {
+		return nil, 0, fmt.Errorf("not a WAV file")
+	}
+
+	channels := 0
+	offset := 12
+	for offset+8 <= len(data) {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if body+16 > len(data) {
+				return nil, 0, fmt.Errorf("truncated fmt chunk")
+			}
+			tag := binary.LittleEndian.Uint16(data[body : body+2])
+			channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			bits := binary.LittleEndian.Uint16(data[body+14 : body+16])
+			// 1 = PCM, 0xFFFE = WAVE_FORMAT_EXTENSIBLE (PCM subformat assumed)
+			if (tag != 1 && tag != 0xFFFE) || bits != 16 || channels < 1 || sampleRate <= 0 {
+				return nil, 0, fmt.Errorf("unsupported WAV format: tag %d, %d channels, %d bits, %d Hz", tag, channels, bits, sampleRate)
+			}
+		case "data":
+			if channels == 0 {
+				return nil, 0, fmt.Errorf("data chunk before fmt chunk")
+			}
+			end := body + size
+			// Streaming writers (espeak-ng --stdout) leave the size as
+			// 0xFFFFFFFF
+			if end > len(data) || end < body {
+				end = len(data)
+			}
+			pcm = data[body:end]
+			if channels > 1 {
+				pcm = downmix(pcm, channels)
+			}
+			return pcm, sampleRate, nil
+		}
+
+		offset = body + size + size%2
+	}
+
+	return nil, 0, fmt.Errorf("WAV data chunk not found")
+}
+
+// EncodeWAV wraps PCM16 mono samples in a RIFF/WAVE header
+func EncodeWAV(pcm []byte, sampleRate int) []byte {
+	buf := make([]byte, 44+len(pcm))
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+len(pcm)))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], 1) // PCM
+	binary.LittleEndian.PutUint16(buf[22:24], 1) // mono
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(buf[28:32], uint32(sampleRate*2))
+	binary.LittleEndian.PutUint16(buf[32:34], 2)
+	binary.LittleEndian.PutUint16(buf[34:36], 16)
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(len(pcm)))
+	copy(buf[44:], pcm)
+	return buf
+}
+
+// DecodeMP3 decodes MP3 data to mono PCM16 at sampleRate with an external
+// decoder (ffmpeg-compatible arguments)
+func DecodeMP3(ctx context.Context, command string, data []byte, sampleRate int) ([]byte, error) {
+	if command == "" {
+		command = DefaultConfig().MP3Command
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("mp3 decoder %q not available: %w", command, err)
+	}
+
+	cmd := exec.CommandContext(ctx, command,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "mp3", "-i", "pipe:0",
+		"-f", "s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate),
+		"pipe:1",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("mp3 decode: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("mp3 decode produced no audio")
+	}
+	return stdout.Bytes(), nil
+}
+
+// downmix averages interleaved PCM16 channels into mono
+func downmix(pcm []byte, channels int) []byte {
+	frame := 2 * channels
+	out := make([]byte, len(pcm)/frame*2)
+	for i := 0; i+frame <= len(pcm); i += frame {
+		var sum int
+		for c := 0; c < channels; c++ {
+			sum += int(int16(binary.LittleEndian.Uint16(pcm[i+2*c:])))
+		}
+		binary.LittleEndian.PutUint16(out[i/channels:], uint16(int16(sum/channels)))
+	}
+	return out
+}
+
+// Resample converts mono PCM16 from one rate to another by linear
+// interpolation
+func Resample(pcm []byte, from, to int) []byte {
+	if from <= 0 || to <= 0 || from == to || len(pcm) < 4 {
+		return pcm
+	}
+
+	in := len(pcm) / 2
+	n := int(int64(in) * int64(to) / int64(from))
+	out := make([]byte, n*2)
+	sample := func(i int) float64 {
+		return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	for j := 0; j < n; j++ {
+		pos := float64(j) * float64(from) / float64(to)
+		i := int(pos)
+		if i >= in-1 {
+			binary.LittleEndian.PutUint16(out[2*j:], uint16(int16(sample(in-1))))
+			continue
+		}
+		frac := pos - float64(i)
+		v := sample(i) + frac*(sample(i+1)-sample(i))
+		binary.LittleEndian.PutUint16(out[2*j:], uint16(int16(v)))
+	}
+	return out
+}
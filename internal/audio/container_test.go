@@ -0,0 +1,196 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildWAV returns a PCM16 WAV file holding samples
+func buildWAV(sampleRate, channels int, samples []int16) []byte {
+	data := make([]byte, 2*len(samples))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(v))
+	}
+
+	var b []byte
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(36+len(data)))
+	b = append(b, "WAVE"...)
+	b = append(b, "fmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(sampleRate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(sampleRate*channels*2))
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels*2))
+	b = binary.LittleEndian.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func samples(pcm []byte) []int16 {
+	out := make([]int16, len(pcm)/2)
+	for i := range out {
+		out[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+	}
+	return out
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string][]byte{
+		FormatWAV:   buildWAV(16000, 1, []int16{1}),
+		FormatMP3:   []byte("ID3\x04\x00\x00\x00\x00\x00\x00"),
+		FormatPCM16: {1, 2, 3, 4},
+	}
+	for want, data := range tests {
+		if got := DetectFormat(data); got != want {
+			t.Errorf("DetectFormat() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestParseWAV(t *testing.T) {
+	pcm, rate, err := ParseWAV(buildWAV(22050, 1, []int16{100, -100, 300}))
+	if err != nil {
+		t.Fatalf("ParseWAV() error = %v", err)
+	}
+	if rate != 22050 || len(samples(pcm)) != 3 || samples(pcm)[2] != 300 {
+		t.Errorf("got %v at %d Hz", samples(pcm), rate)
+	}
+
+	// Stereo is mixed down to mono
+	pcm, _, err = ParseWAV(buildWAV(44100, 2, []int16{100, 300, -200, -400}))
+	if err != nil {
+		t.Fatalf("ParseWAV() stereo error = %v", err)
+	}
+	if got := samples(pcm); len(got) != 2 || got[0] != 200 || got[1] != -300 {
+		t.Errorf("downmix = %v, want [200 -300]", got)
+	}
+
+	wav := buildWAV(16000, 1, []int16{1})
+	binary.LittleEndian.PutUint16(wav[34:], 8) // 8-bit samples
+	if _, _, err := ParseWAV(wav); err == nil {
+		t.Error("8-bit WAV should be rejected")
+	}
+	if _, _, err := ParseWAV([]byte("definitely not a wav")); err == nil {
+		t.Error("non-WAV data should be rejected")
+	}
+}
+
+func TestParseWAV_StreamingSize(t *testing.T) {
+	wav := EncodeWAV([]byte{1, 2, 3, 4, 5, 6}, 16000)
+	binary.LittleEndian.PutUint32(wav[40:44], 0xFFFFFFFF)
+
+	pcm, rate, err := ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("ParseWAV() error = %v", err)
+	}
+	if rate != 16000 || len(pcm) != 6 {
+		t.Errorf("got %d bytes at %d Hz, want 6 at 16000", len(pcm), rate)
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	wav := EncodeWAV([]byte{1, 2, 3, 4}, 16000)
+	if len(wav) != 48 {
+		t.Fatalf("expected 48 bytes, got %d", len(wav))
+	}
+
+	pcm, rate, err := ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("ParseWAV() error = %v", err)
+	}
+	if rate != 16000 || string(pcm) != "\x01\x02\x03\x04" {
+		t.Errorf("round trip = %v at %d Hz", pcm, rate)
+	}
+}
+
+func TestResample(t *testing.T) {
+	pcm := make([]byte, 0, 8)
+	for _, v := range []int16{0, 100, 200, 300} {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(v))
+	}
+
+	up := samples(Resample(pcm, 8000, 16000))
+	if len(up) != 8 || up[1] != 50 || up[2] != 100 {
+		t.Errorf("upsampled = %v", up)
+	}
+	down := samples(Resample(pcm, 16000, 8000))
+	if len(down) != 2 || down[1] != 200 {
+		t.Errorf("downsampled = %v", down)
+	}
+	if got := Resample(pcm, 16000, 16000); len(got) != len(pcm) {
+		t.Error("same rate should be unchanged")
+	}
+}
+
+func TestDecodeClip(t *testing.T) {
+	bridge := NewBridge(DefaultConfig(), nil)
+	ctx := context.Background()
+
+	// WAV is detected without a format and keeps its own rate
+	clip, err := bridge.DecodeClip(ctx, "", buildWAV(24000, 1, []int16{1, 2}), 16000, 1)
+	if err != nil {
+		t.Fatalf("DecodeClip() wav error = %v", err)
+	}
+	if clip.SampleRate != 24000 || len(clip.Data) != 4 {
+		t.Errorf("wav clip = %d bytes at %d Hz", len(clip.Data), clip.SampleRate)
+	}
+
+	// Raw stereo PCM is mixed down
+	clip, err = bridge.DecodeClip(ctx, "pcm16", []byte{10, 0, 30, 0}, 8000, 2)
+	if err != nil {
+		t.Fatalf("DecodeClip() pcm error = %v", err)
+	}
+	if clip.SampleRate != 8000 || len(clip.Data) != 2 || samples(clip.Data)[0] != 20 {
+		t.Errorf("pcm clip = %v at %d Hz", samples(clip.Data), clip.SampleRate)
+	}
+
+	if _, err := bridge.DecodeClip(ctx, "flac", []byte{0, 0}, 16000, 1); err == nil {
+		t.Error("unsupported format should fail")
+	}
+}
+
+func TestDecodeClip_MP3(t *testing.T) {
+	// Stand-in decoder: checks it was asked for mono at the device rate
+	dir := t.TempDir()
+	cmd := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\ncat > /dev/null\ncase \"$*\" in *'-ac 1 -ar 16000'*) printf '\\001\\000\\002\\000' ;; *) exit 1 ;; esac\n"
+	if err := os.WriteFile(cmd, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MP3Command = cmd
+	bridge := NewBridge(cfg, nil)
+
+	clip, err := bridge.DecodeClip(context.Background(), "mp3", []byte("ID3 fake"), 0, 0)
+	if err != nil {
+		t.Fatalf("DecodeClip() mp3 error = %v", err)
+	}
+	if clip.SampleRate != 16000 || len(clip.Data) != 4 {
+		t.Errorf("mp3 clip = %d bytes at %d Hz", len(clip.Data), clip.SampleRate)
+	}
+
+	cfg.MP3Command = "nonexistent_mp3_decoder_12345"
+	if _, err := NewBridge(cfg, nil).DecodeClip(context.Background(), "mp3", []byte("ID3"), 0, 0); err == nil {
+		t.Error("missing decoder should fail")
+	}
+}
+
+func TestPlayAudio_ResamplesToDeviceRate(t *testing.T) {
+	cfg, log := fakePlayer(t, "0")
+	cfg.DeviceRate = 48000
+	bridge := NewBridge(cfg, nil)
+
+	if err := bridge.PlayAudio(context.Background(), []byte{0, 0, 1, 0}, "pcm16", 24000); err != nil {
+		t.Fatalf("PlayAudio() error = %v", err)
+	}
+	if got := played(t, log); len(got) != 1 || got[0] != "48000" {
+		t.Errorf("played at %v, want 48000", got)
+	}
+}
@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+)
+
+// MinDBFS is the level reported for digital silence; 16-bit PCM resolves
+// nothing quieter
+const MinDBFS = -96.0
+
+// DefaultLevelWindow is the span of audio behind each level reading (20Hz)
+const DefaultLevelWindow = 50 * time.Millisecond
+
+// maxLevelAge is how long the last reading stays current once capture stops
+const maxLevelAge = time.Second
+
+// ChannelLevel is one channel's level over a window
+type ChannelLevel struct {
+	Channel int     `json:"channel"`
+	RMS     float64 `json:"rms_dbfs"`
+	Peak    float64 `json:"peak_dbfs"`
+}
+
+// Levels is a level reading for every captured channel
+type Levels struct {
+	Channels  []ChannelLevel `json:"channels"`
+	WindowMs  int64          `json:"window_ms"`
+	Timestamp time.Time      `json:"timestamp"` // End of the window
+}
+
+// LevelMeter turns captured audio into per-channel RMS and peak levels in
+// dBFS, one reading per window. Windows span chunk boundaries, so readings
+// come at the window rate whatever the capture chunk size.
+type LevelMeter struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	rate     int
+	channels int
+	frames   int       // Frames in the current window
+	sumSq    []float64 // Per channel, current window
+	peak     []int     // Per channel, current window
+	latest   Levels
+}
+
+// NewLevelMeter creates a meter producing one reading per window
+func NewLevelMeter(window time.Duration) *LevelMeter {
+	if window <= 0 {
+		window = DefaultLevelWindow
+	}
+	return &LevelMeter{window: window}
+}
+
+// Feed accumulates a captured chunk, completing a reading each time a
+// window fills
+func (m *LevelMeter) Feed(chunk AudioChunk) {
+	if chunk.SampleRate <= 0 || chunk.Channels <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if chunk.SampleRate != m.rate || chunk.Channels != m.channels {
+		m.rate, m.channels = chunk.SampleRate, chunk.Channels
+		m.sumSq = make([]float64, m.channels)
+		m.peak = make([]int, m.channels)
+		m.frames = 0
+	}
+
+	size := max(1, int(int64(m.rate)*int64(m.window)/int64(time.Second)))
+	frameSize := 2 * m.channels
+	total := len(chunk.Data) / frameSize
+	for f := 0; f < total; f++ {
+		for ch := 0; ch < m.channels; ch++ {
+			v := int(int16(binary.LittleEndian.Uint16(chunk.Data[f*frameSize+2*ch:])))
+			m.sumSq[ch] += float64(v * v)
+			if v < 0 {
+				v = -v
+			}
+			m.peak[ch] = max(m.peak[ch], v)
+		}
+		m.frames++
+		if m.frames == size {
+			// Chunk timestamps mark the end of the chunk
+			left := time.Duration(int64(total-f-1) * int64(time.Second) / int64(m.rate))
+			m.completeLocked(chunk.Timestamp.Add(-left))
+		}
+	}
+}
+
+// completeLocked turns the current window into the latest reading
+func (m *LevelMeter) completeLocked(end time.Time) {
+	levels := Levels{
+		Channels:  make([]ChannelLevel, m.channels),
+		WindowMs:  m.window.Milliseconds(),
+		Timestamp: end,
+	}
+	for ch := range levels.Channels {
+		levels.Channels[ch] = ChannelLevel{
+			Channel: ch,
+			RMS:     dbfs(math.Sqrt(m.sumSq[ch] / float64(m.frames))),
+			Peak:    dbfs(float64(m.peak[ch])),
+		}
+		m.sumSq[ch] = 0
+		m.peak[ch] = 0
+	}
+	m.frames = 0
+	m.latest = levels
+}
+
+// Levels returns the latest reading, or false if no audio has been captured
+// recently
+func (m *LevelMeter) Levels() (Levels, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.latest.Timestamp.IsZero() || time.Since(m.latest.Timestamp) > maxLevelAge {
+		return Levels{}, false
+	}
+	levels := m.latest
+	levels.Channels = append([]ChannelLevel(nil), m.latest.Channels...)
+	return levels, true
+}
+
+// dbfs converts a 16-bit sample amplitude to dB relative to full scale
+func dbfs(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return MinDBFS
+	}
+	return max(20*math.Log10(amplitude/32768), MinDBFS)
+}
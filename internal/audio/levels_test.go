@@ -0,0 +1,95 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// stereoPCM builds frames of PCM16 with a constant value per channel
+func stereoPCM(frames int, left, right int16) []byte {
+	data := make([]byte, 4*frames)
+	for i := 0; i < frames; i++ {
+		binary.LittleEndian.PutUint16(data[4*i:], uint16(left))
+		binary.LittleEndian.PutUint16(data[4*i+2:], uint16(right))
+	}
+	return data
+}
+
+func TestLevelMeter_Levels(t *testing.T) {
+	meter := NewLevelMeter(50 * time.Millisecond)
+	if _, ok := meter.Levels(); ok {
+		t.Fatal("Levels() ok before any audio")
+	}
+
+	// 100ms of half-scale left, silent right: two windows
+	now := time.Now()
+	meter.Feed(AudioChunk{
+		Data:       stereoPCM(1600, -16384, 0),
+		SampleRate: 16000,
+		Channels:   2,
+		Timestamp:  now,
+	})
+
+	levels, ok := meter.Levels()
+	if !ok {
+		t.Fatal("Levels() not ok after a chunk")
+	}
+	if len(levels.Channels) != 2 {
+		t.Fatalf("channels = %d, want 2", len(levels.Channels))
+	}
+	want := 20 * math.Log10(0.5)
+	if got := levels.Channels[0].RMS; math.Abs(got-want) > 0.01 {
+		t.Errorf("left RMS = %.2f dBFS, want %.2f", got, want)
+	}
+	if got := levels.Channels[0].Peak; math.Abs(got-want) > 0.01 {
+		t.Errorf("left peak = %.2f dBFS, want %.2f", got, want)
+	}
+	if got := levels.Channels[1].RMS; got != MinDBFS {
+		t.Errorf("right RMS = %.2f dBFS, want %v", got, MinDBFS)
+	}
+	if !levels.Timestamp.Equal(now) {
+		t.Errorf("timestamp = %v, want end of chunk %v", levels.Timestamp, now)
+	}
+	if levels.WindowMs != 50 {
+		t.Errorf("window_ms = %d, want 50", levels.WindowMs)
+	}
+}
+
+func TestLevelMeter_WindowSpansChunks(t *testing.T) {
+	meter := NewLevelMeter(50 * time.Millisecond)
+	now := time.Now()
+
+	// 30ms loud then 30ms quiet: the first window ends 20ms into the
+	// second chunk and sees the loud peak
+	meter.Feed(AudioChunk{Data: stereoPCM(480, 32767, 32767), SampleRate: 16000, Channels: 2, Timestamp: now.Add(-30 * time.Millisecond)})
+	if _, ok := meter.Levels(); ok {
+		t.Fatal("Levels() ok before a window filled")
+	}
+	meter.Feed(AudioChunk{Data: stereoPCM(480, 100, 100), SampleRate: 16000, Channels: 2, Timestamp: now})
+
+	levels, ok := meter.Levels()
+	if !ok {
+		t.Fatal("Levels() not ok after a full window")
+	}
+	if got := levels.Channels[0].Peak; got < -0.01 {
+		t.Errorf("peak = %.2f dBFS, want ~0", got)
+	}
+	if want := now.Add(-10 * time.Millisecond); !levels.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", levels.Timestamp, want)
+	}
+}
+
+func TestLevelMeter_Stale(t *testing.T) {
+	meter := NewLevelMeter(50 * time.Millisecond)
+	meter.Feed(AudioChunk{
+		Data:       stereoPCM(800, 1000, 1000),
+		SampleRate: 16000,
+		Channels:   2,
+		Timestamp:  time.Now().Add(-2 * maxLevelAge),
+	})
+	if _, ok := meter.Levels(); ok {
+		t.Error("Levels() ok for audio captured long ago")
+	}
+}
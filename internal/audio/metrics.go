@@ -0,0 +1,53 @@
+package audio
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers audio bridge collectors
+func (b *Bridge) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_chunks_captured_total",
+			Help: "Microphone chunks captured",
+		}, func() float64 { return float64(b.chunksCaptured.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_capture_restarts_total",
+			Help: "Microphone capture stream restarts",
+		}, func() float64 { return float64(b.captureRestarts.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_chunks_played_total",
+			Help: "Audio clips played on the speaker",
+		}, func() float64 { return float64(b.chunksPlayed.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_capture_errors_total",
+			Help: "Microphone capture errors",
+		}, func() float64 { return float64(b.captureErrors.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_playback_errors_total",
+			Help: "Speaker playback errors",
+		}, func() float64 { return float64(b.playbackErrors.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_audio_playback_queued",
+			Help: "Clips waiting for the speaker",
+		}, func() float64 { return float64(b.playbackStats().Queued) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_playback_interrupted_total",
+			Help: "Clips cut off by a higher-priority clip or a stop command",
+		}, func() float64 { return float64(b.playInterrupted.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_audio_playback_dropped_total",
+			Help: "Clips dropped because the playback queue was full",
+		}, func() float64 { return float64(b.playDropped.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_audio_capturing",
+			Help: "Microphone capture active (1=capturing, 0=stopped)",
+		}, func() float64 { return boolToFloat(b.GetStats().Capturing) }),
+	)
+}
+
+// boolToFloat converts a flag to 1 or 0 for gauges
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+//go:build opus && cgo
+
+package audio
+
+/*
+#cgo pkg-config: opus
+#include <opus.h>
+
+static int go_eva_opus_set_bitrate(OpusEncoder *enc, opus_int32 bitrate) {
+	return opus_encoder_ctl(enc, OPUS_SET_BITRATE(bitrate));
+}
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+)
+
+// opusAvailable is true when built with -tags opus against libopus
+const opusAvailable = true
+
+// maxOpusPacket bounds a single encoded packet (libopus recommends 4000)
+const maxOpusPacket = 4000
+
+// maxOpusFrame is the longest frame libopus can return (120ms at 48kHz)
+const maxOpusFrame = 5760
+
+type opusEncoder struct {
+	enc      *C.OpusEncoder
+	channels int
+	buf      []byte
+}
+
+func newOpusEncoder(sampleRate, channels, bitrate int) (*opusEncoder, error) {
+	var errCode C.int
+	enc := C.opus_encoder_create(C.opus_int32(sampleRate), C.int(channels), C.OPUS_APPLICATION_VOIP, &errCode)
+	if errCode != C.OPUS_OK {
+		return nil, fmt.Errorf("opus encoder create: %s", C.GoString(C.opus_strerror(errCode)))
+	}
+	if rc := C.go_eva_opus_set_bitrate(enc, C.opus_int32(bitrate)); rc != C.OPUS_OK {
+		C.opus_encoder_destroy(enc)
+		return nil, fmt.Errorf("opus set bitrate: %s", C.GoString(C.opus_strerror(rc)))
+	}
+
+	return &opusEncoder{
+		enc:      enc,
+		channels: channels,
+		buf:      make([]byte, maxOpusPacket),
+	}, nil
+}
+
+// encode compresses one frame of PCM16 little-endian samples
+func (e *opusEncoder) encode(pcm []byte) ([]byte, error) {
+	samples := pcmToInt16(pcm)
+	frameSize := len(samples) / e.channels
+
+	n := C.opus_encode(e.enc,
+		(*C.opus_int16)(unsafe.Pointer(&samples[0])), C.int(frameSize),
+		(*C.uchar)(unsafe.Pointer(&e.buf[0])), C.opus_int32(len(e.buf)))
+	if n < 0 {
+		return nil, fmt.Errorf("%s", C.GoString(C.opus_strerror(C.int(n))))
+	}
+	return append([]byte(nil), e.buf[:n]...), nil
+}
+
+func (e *opusEncoder) close() {
+	if e.enc != nil {
+		C.opus_encoder_destroy(e.enc)
+		e.enc = nil
+	}
+}
+
+type opusDecoder struct {
+	dec      *C.OpusDecoder
+	channels int
+	buf      []int16
+}
+
+func newOpusDecoder(sampleRate, channels int) (*opusDecoder, error) {
+	var errCode C.int
+	dec := C.opus_decoder_create(C.opus_int32(sampleRate), C.int(channels), &errCode)
+	if errCode != C.OPUS_OK {
+		return nil, fmt.Errorf("opus decoder create: %s", C.GoString(C.opus_strerror(errCode)))
+	}
+
+	return &opusDecoder{
+		dec:      dec,
+		channels: channels,
+		buf:      make([]int16, maxOpusFrame*channels),
+	}, nil
+}
+
+// decode expands one packet to PCM16 little-endian samples
+func (d *opusDecoder) decode(packet []byte) ([]byte, error) {
+	n := C.opus_decode(d.dec,
+		(*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)),
+		(*C.opus_int16)(unsafe.Pointer(&d.buf[0])), C.int(maxOpusFrame), 0)
+	if n < 0 {
+		return nil, fmt.Errorf("%s", C.GoString(C.opus_strerror(n)))
+	}
+	return int16ToPCM(d.buf[:int(n)*d.channels]), nil
+}
+
+func (d *opusDecoder) close() {
+	if d.dec != nil {
+		C.opus_decoder_destroy(d.dec)
+		d.dec = nil
+	}
+}
+
+// pcmToInt16 converts little-endian PCM16 bytes to samples
+func pcmToInt16(pcm []byte) []int16 {
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+	}
+	return samples
+}
+
+// int16ToPCM converts samples to little-endian PCM16 bytes
+func int16ToPCM(samples []int16) []byte {
+	pcm := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(s))
+	}
+	return pcm
+}
@@ -0,0 +1,24 @@
+//go:build !opus || !cgo
+
+package audio
+
+// opusAvailable is false unless built with -tags opus (requires libopus)
+const opusAvailable = false
+
+type opusEncoder struct{}
+
+func newOpusEncoder(sampleRate, channels, bitrate int) (*opusEncoder, error) {
+	return nil, ErrOpusUnavailable
+}
+
+func (e *opusEncoder) encode(pcm []byte) ([]byte, error) { return nil, ErrOpusUnavailable }
+func (e *opusEncoder) close()                            {}
+
+type opusDecoder struct{}
+
+func newOpusDecoder(sampleRate, channels int) (*opusDecoder, error) {
+	return nil, ErrOpusUnavailable
+}
+
+func (d *opusDecoder) decode(packet []byte) ([]byte, error) { return nil, ErrOpusUnavailable }
+func (d *opusDecoder) close()                               {}
@@ -0,0 +1,284 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Priority orders queued playback; a higher priority interrupts a lower one
+type Priority int
+
+// Playback priorities
+const (
+	PriorityAmbient Priority = iota // Background sounds, played when nothing else is queued
+	PriorityTTS                     // Speech (cloud audio and local TTS)
+	PriorityAlert                   // Alerts cut off anything less urgent
+)
+
+// String returns the priority name
+func (p Priority) String() string {
+	switch p {
+	case PriorityAmbient:
+		return "ambient"
+	case PriorityTTS:
+		return "tts"
+	case PriorityAlert:
+		return "alert"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// ParsePriority parses a priority name; empty means PriorityTTS
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(name) {
+	case "", "tts":
+		return PriorityTTS, nil
+	case "ambient":
+		return PriorityAmbient, nil
+	case "alert":
+		return PriorityAlert, nil
+	}
+	return PriorityTTS, fmt.Errorf("unknown playback priority %q (valid: alert, tts, ambient)", name)
+}
+
+// Playback errors
+var (
+	ErrInterrupted = errors.New("playback interrupted")
+	ErrQueueFull   = errors.New("playback queue full")
+)
+
+// Clip is PCM16 audio waiting to be played
+type Clip struct {
+	Data       []byte
+	SampleRate int
+	Priority   Priority
+
+	done chan error // Receives the outcome (nil = played to the end)
+}
+
+// Enqueue adds a clip to the playback queue without waiting for it. A clip
+// with a higher priority than the one playing interrupts it; within a
+// priority clips play in order. The returned channel receives the outcome.
+func (b *Bridge) Enqueue(clip Clip) (<-chan error, error) {
+	clip.done = make(chan error, 1)
+
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+
+	if b.playClosed {
+		return nil, fmt.Errorf("audio bridge closed")
+	}
+
+	if len(b.queue) >= b.cfg.MaxQueue {
+		// Make room by dropping the newest clip of the lowest priority, if
+		// it is less urgent than this one
+		victim := -1
+		for i, queued := range b.queue {
+			if queued.Priority < clip.Priority && (victim < 0 || queued.Priority <= b.queue[victim].Priority) {
+				victim = i
+			}
+		}
+		if victim < 0 {
+			b.playDropped.Add(1)
+			return nil, ErrQueueFull
+		}
+		b.queue[victim].done <- ErrQueueFull
+		b.queue = append(b.queue[:victim], b.queue[victim+1:]...)
+		b.playDropped.Add(1)
+	}
+
+	// Insert after every clip of the same or higher priority
+	i := len(b.queue)
+	for i > 0 && b.queue[i-1].Priority < clip.Priority {
+		i--
+	}
+	b.queue = append(b.queue, Clip{})
+	copy(b.queue[i+1:], b.queue[i:])
+	b.queue[i] = clip
+
+	if b.playing != nil && clip.Priority > b.playing.Priority {
+		b.interruptLocked()
+	}
+
+	b.startPlayback.Do(func() { go b.playbackLoop() })
+	select {
+	case b.playWake <- struct{}{}:
+	default:
+	}
+	return clip.done, nil
+}
+
+// Play queues a clip and waits until it has played, been interrupted, or
+// ctx is done
+func (b *Bridge) Play(ctx context.Context, clip Clip) error {
+	done, err := b.Enqueue(clip)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		b.cancelClip(done)
+		return ctx.Err()
+	}
+}
+
+// cancelClip removes a clip from the queue, or interrupts it if it is playing
+func (b *Bridge) cancelClip(done <-chan error) {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+
+	if b.playing != nil && (<-chan error)(b.playing.done) == done {
+		b.interruptLocked()
+		return
+	}
+	for i, clip := range b.queue {
+		if (<-chan error)(clip.done) == done {
+			b.queue = append(b.queue[:i], b.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Interrupt stops the clip that is playing; queued clips continue
+func (b *Bridge) Interrupt() bool {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+	return b.interruptLocked()
+}
+
+// interruptLocked cancels the current clip (caller holds playMu)
+func (b *Bridge) interruptLocked() bool {
+	if b.playing == nil || b.cancelPlay == nil {
+		return false
+	}
+	b.cancelPlay(ErrInterrupted)
+	b.cancelPlay = nil
+	b.playInterrupted.Add(1)
+	return true
+}
+
+// Flush drops every queued clip without touching the one playing, and
+// returns how many were dropped
+func (b *Bridge) Flush() int {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+	return b.flushLocked()
+}
+
+// flushLocked implements Flush (caller holds playMu)
+func (b *Bridge) flushLocked() int {
+	n := len(b.queue)
+	for _, clip := range b.queue {
+		clip.done <- ErrInterrupted
+	}
+	b.queue = nil
+	return n
+}
+
+// StopPlayback interrupts the current clip and, if flush is set, clears the
+// queue. It reports whether a clip was playing and how many were dropped.
+func (b *Bridge) StopPlayback(flush bool) (interrupted bool, flushed int) {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+
+	if flush {
+		flushed = b.flushLocked()
+	}
+	return b.interruptLocked(), flushed
+}
+
+// playbackLoop plays queued clips one at a time until the bridge is closed
+func (b *Bridge) playbackLoop() {
+	defer close(b.playDone)
+	for {
+		b.playMu.Lock()
+		for len(b.queue) == 0 && !b.playClosed {
+			b.playMu.Unlock()
+			<-b.playWake
+			b.playMu.Lock()
+		}
+		if b.playClosed {
+			b.playMu.Unlock()
+			return
+		}
+
+		clip := b.queue[0]
+		b.queue = b.queue[1:]
+		ctx, cancel := context.WithCancelCause(context.Background())
+		ctx, cancelTimeout := context.WithTimeout(ctx, b.cfg.PlaybackTimeout)
+		b.playing = &clip
+		b.cancelPlay = cancel
+		b.playMu.Unlock()
+
+		err := b.PlayAudio(ctx, clip.Data, "pcm16", clip.SampleRate)
+		cancelTimeout()
+		cancel(nil)
+
+		b.playMu.Lock()
+		b.playing = nil
+		b.cancelPlay = nil
+		b.playMu.Unlock()
+
+		if err != nil && !errors.Is(err, ErrInterrupted) {
+			b.logger.Warn("playback error", "priority", clip.Priority, "error", err)
+		}
+		clip.done <- err
+	}
+}
+
+// closePlayback stops the playback loop and drops queued clips
+func (b *Bridge) closePlayback() {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+
+	b.playClosed = true
+	b.flushLocked()
+	b.interruptLocked()
+	select {
+	case b.playWake <- struct{}{}:
+	default:
+	}
+}
+
+// PlaybackStats describes the playback queue
+type PlaybackStats struct {
+	Playing     string `json:"playing,omitempty"` // Priority of the clip playing
+	Queued      int    `json:"queued"`
+	Interrupted uint64 `json:"interrupted"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// playbackStats returns playback queue statistics
+func (b *Bridge) playbackStats() PlaybackStats {
+	b.playMu.Lock()
+	defer b.playMu.Unlock()
+
+	stats := PlaybackStats{
+		Queued:      len(b.queue),
+		Interrupted: b.playInterrupted.Load(),
+		Dropped:     b.playDropped.Load(),
+	}
+	if b.playing != nil {
+		stats.Playing = b.playing.Priority.String()
+	}
+	return stats
+}
+
+// PriorityPlayer plays through a bridge's queue at a fixed priority
+// (satisfies tts.Player)
+type PriorityPlayer struct {
+	Bridge   *Bridge
+	Priority Priority
+}
+
+// PlayAudio queues PCM16 audio and waits until it has played
+func (p PriorityPlayer) PlayAudio(ctx context.Context, data []byte, format string, sampleRate int) error {
+	if format != "pcm16" {
+		return fmt.Errorf("unsupported queued format %q", format)
+	}
+	return p.Bridge.Play(ctx, Clip{Data: data, SampleRate: sampleRate, Priority: p.Priority})
+}
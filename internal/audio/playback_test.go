@@ -0,0 +1,254 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePlayer returns a config whose playback command logs each clip's
+// sample rate and then plays for the given duration. Resampling is off so
+// the rates identify the clips.
+func fakePlayer(t *testing.T, duration string) (cfg Config, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	log = filepath.Join(dir, "played.log")
+	cmd := filepath.Join(dir, "aplay")
+	script := "#!/bin/sh\ncat > /dev/null\necho \"$4\" >> " + log + "\nexec sleep " + duration + "\n"
+	if err := os.WriteFile(cmd, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.PlaybackCmd = cmd
+	cfg.DeviceRate = 0
+	return cfg, log
+}
+
+func played(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+func TestParsePriority(t *testing.T) {
+	for name, want := range map[string]Priority{"": PriorityTTS, "alert": PriorityAlert, "Ambient": PriorityAmbient} {
+		got, err := ParsePriority(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("loud"); err == nil {
+		t.Error("unknown priority should fail")
+	}
+}
+
+func TestPlayback_PriorityOrder(t *testing.T) {
+	cfg, log := fakePlayer(t, "0.1")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	// The first clip starts at once; the rest queue by priority
+	first, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000, Priority: PriorityTTS})
+	time.Sleep(20 * time.Millisecond)
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000, Priority: PriorityAmbient})
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 3000, Priority: PriorityTTS})
+	last, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 4000, Priority: PriorityTTS})
+
+	if err := <-first; err != nil {
+		t.Fatalf("first clip error = %v", err)
+	}
+	select {
+	case <-last:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for queued clips")
+	}
+	time.Sleep(200 * time.Millisecond) // Let the ambient clip finish
+
+	got := strings.Join(played(t, log), ",")
+	if got != "1000,3000,4000,2000" {
+		t.Errorf("played %s, want 1000,3000,4000,2000", got)
+	}
+}
+
+func TestPlayback_AlertInterrupts(t *testing.T) {
+	cfg, log := fakePlayer(t, "5")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	speech, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000, Priority: PriorityTTS})
+	time.Sleep(50 * time.Millisecond)
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000, Priority: PriorityAlert})
+
+	select {
+	case err := <-speech:
+		if !errors.Is(err, ErrInterrupted) {
+			t.Errorf("speech error = %v, want ErrInterrupted", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert did not interrupt speech")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(played(t, log)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := played(t, log); len(got) != 2 || got[1] != "2000" {
+		t.Errorf("played %v, want the alert after the interrupted clip", got)
+	}
+
+	stats := bridge.GetStats()
+	if stats.Playback.Playing != "alert" || stats.Playback.Interrupted != 1 || stats.PlaybackErrors != 0 {
+		t.Errorf("stats = %+v", stats.Playback)
+	}
+}
+
+func TestPlayback_StopAndFlush(t *testing.T) {
+	cfg, _ := fakePlayer(t, "5")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	playing, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	time.Sleep(50 * time.Millisecond)
+	queued, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000})
+
+	interrupted, flushed := bridge.StopPlayback(true)
+	if !interrupted || flushed != 1 {
+		t.Errorf("StopPlayback() = %v, %d; want true, 1", interrupted, flushed)
+	}
+	for _, done := range []<-chan error{playing, queued} {
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrInterrupted) {
+				t.Errorf("clip error = %v, want ErrInterrupted", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("clip was not stopped")
+		}
+	}
+}
+
+func TestPlayback_QueueFull(t *testing.T) {
+	cfg, _ := fakePlayer(t, "5")
+	cfg.MaxQueue = 1
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	time.Sleep(50 * time.Millisecond)
+
+	ambient, err := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000, Priority: PriorityAmbient})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 3000, Priority: PriorityAmbient}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue() error = %v, want ErrQueueFull", err)
+	}
+
+	// A more urgent clip evicts the ambient one
+	if _, err := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 4000, Priority: PriorityTTS}); err != nil {
+		t.Errorf("Enqueue() error = %v", err)
+	}
+	if err := <-ambient; !errors.Is(err, ErrQueueFull) {
+		t.Errorf("evicted clip error = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestPlay_ContextCancelRemovesClip(t *testing.T) {
+	cfg, _ := fakePlayer(t, "5")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	player := PriorityPlayer{Bridge: bridge, Priority: PriorityTTS}
+	if err := player.PlayAudio(ctx, []byte{0, 0}, "pcm16", 2000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PlayAudio() error = %v, want deadline exceeded", err)
+	}
+	if queued := bridge.GetStats().Playback.Queued; queued != 0 {
+		t.Errorf("Queued = %d, want 0 after cancel", queued)
+	}
+}
+
+func TestPlayback_Events(t *testing.T) {
+	cfg, _ := fakePlayer(t, "0.05")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	events := make(chan PlaybackEvent, 4)
+	bridge.OnPlayback(func(ev PlaybackEvent) { events <- ev })
+
+	// Each clip reports its start and stop before its outcome
+	bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	done, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000})
+	if err := <-done; err != nil {
+		t.Fatalf("playback error = %v", err)
+	}
+
+	var got []bool
+	for len(events) > 0 {
+		got = append(got, (<-events).Active)
+	}
+	if want := []bool{true, false, true, false}; !slices.Equal(got, want) {
+		t.Errorf("playback events = %v, want %v", got, want)
+	}
+}
+
+func TestBridge_Shutdown(t *testing.T) {
+	cfg, log := fakePlayer(t, "0.2")
+	bridge := NewBridge(cfg, nil)
+	defer bridge.Close()
+
+	playing, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	time.Sleep(50 * time.Millisecond)
+	queued, _ := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 2000})
+
+	// The clip playing finishes; the queued one is dropped
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := bridge.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-playing; err != nil {
+		t.Errorf("playing clip error = %v, want it played to the end", err)
+	}
+	if err := <-queued; !errors.Is(err, ErrInterrupted) {
+		t.Errorf("queued clip error = %v, want ErrInterrupted", err)
+	}
+	if got := played(t, log); !slices.Equal(got, []string{"1000"}) {
+		t.Errorf("played %v, want only the first clip", got)
+	}
+	if _, err := bridge.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 3000}); err == nil {
+		t.Error("Enqueue() after Shutdown should fail")
+	}
+
+	// Out of time: the clip is cut off
+	cfg, _ = fakePlayer(t, "5")
+	slow := NewBridge(cfg, nil)
+	cut, _ := slow.Enqueue(Clip{Data: []byte{0, 0}, SampleRate: 1000})
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := slow.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	if err := <-cut; !errors.Is(err, ErrInterrupted) {
+		t.Errorf("cut clip error = %v, want ErrInterrupted", err)
+	}
+
+	// A bridge that never played shuts down at once
+	if err := NewBridge(DefaultConfig(), nil).Shutdown(context.Background()); err != nil {
+		t.Errorf("idle Shutdown() error = %v", err)
+	}
+}
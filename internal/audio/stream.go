@@ -0,0 +1,152 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// CaptureSource supplies microphone audio in place of the capture command.
+// Open returns a stream of PCM16 little-endian samples at the bridge's
+// sample rate and channel count; the bridge reads it until it fails or ctx
+// is cancelled, then reopens it with backoff.
+type CaptureSource interface {
+	Name() string
+	Open(ctx context.Context) (io.ReadCloser, error)
+}
+
+// streamQueue is how many decoded packets may wait for the reader (a
+// second of 20ms packets) before new ones are dropped
+const streamQueue = 50
+
+// OpusStream is a CaptureSource fed with Opus packets as they arrive, such
+// as the robot mic track of the camera's WebRTC session. Packets are
+// decoded on arrival and dropped while the stream isn't open.
+type OpusStream struct {
+	decode func([]byte) ([]byte, error)
+	close  func()
+
+	mu  sync.Mutex
+	out chan []byte // Queue of the open reader (nil when closed)
+
+	// Stats
+	packets      atomic.Uint64
+	dropped      atomic.Uint64
+	decodeErrors atomic.Uint64
+}
+
+// NewOpusStream creates a stream decoding to sampleRate and channels,
+// whatever the packets were encoded at. It fails with ErrOpusUnavailable
+// when the binary was built without Opus support.
+func NewOpusStream(sampleRate, channels int) (*OpusStream, error) {
+	dec, err := newOpusDecoder(sampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+	return &OpusStream{decode: dec.decode, close: dec.close}, nil
+}
+
+// Name identifies the source in logs and stats
+func (s *OpusStream) Name() string {
+	return CodecOpus
+}
+
+// WritePacket decodes one Opus packet (an RTP payload) into the open stream
+func (s *OpusStream) WritePacket(packet []byte) {
+	s.packets.Add(1)
+	if len(packet) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.out == nil {
+		s.dropped.Add(1)
+		return
+	}
+	pcm, err := s.decode(packet)
+	if err != nil {
+		s.decodeErrors.Add(1)
+		return
+	}
+	select {
+	case s.out <- pcm:
+	default:
+		s.dropped.Add(1) // Reader fell behind
+	}
+}
+
+// Open starts a new stream, ending any previous one
+func (s *OpusStream) Open(ctx context.Context) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.decode == nil {
+		return nil, fmt.Errorf("opus stream closed")
+	}
+	out := make(chan []byte, streamQueue)
+	s.out = out
+	return &streamReader{ctx: ctx, stream: s, out: out}, nil
+}
+
+// Close ends the stream and releases the decoder
+func (s *OpusStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.out = nil
+	if s.close != nil {
+		s.close()
+	}
+	s.decode, s.close = nil, nil
+}
+
+// OpusStreamStats contains Opus capture stream statistics
+type OpusStreamStats struct {
+	Packets      uint64 `json:"packets"`
+	Dropped      uint64 `json:"dropped"` // Arrived while closed or while the reader was behind
+	DecodeErrors uint64 `json:"decode_errors"`
+}
+
+// GetStats returns stream statistics
+func (s *OpusStream) GetStats() OpusStreamStats {
+	return OpusStreamStats{
+		Packets:      s.packets.Load(),
+		Dropped:      s.dropped.Load(),
+		DecodeErrors: s.decodeErrors.Load(),
+	}
+}
+
+// streamReader reads decoded packets from one Open of an OpusStream
+type streamReader struct {
+	ctx     context.Context
+	stream  *OpusStream
+	out     chan []byte
+	pending []byte
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case pcm := <-r.out:
+			r.pending = pcm
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *streamReader) Close() error {
+	r.stream.mu.Lock()
+	if r.stream.out == r.out {
+		r.stream.out = nil
+	}
+	r.stream.mu.Unlock()
+	return nil
+}
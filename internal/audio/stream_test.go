@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestOpusStream(t *testing.T) {
+	stream := &OpusStream{decode: func(p []byte) ([]byte, error) {
+		if p[0] == 0xFF {
+			return nil, errors.New("corrupt packet")
+		}
+		return p, nil
+	}}
+
+	// Nothing is reading yet
+	stream.WritePacket([]byte{1, 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := stream.Open(ctx)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	stream.WritePacket([]byte{3, 4})
+	stream.WritePacket([]byte{0xFF})
+	stream.WritePacket([]byte{5, 6})
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if buf[0] != 3 || buf[3] != 6 {
+		t.Errorf("read %v, want the packets written while open", buf)
+	}
+
+	stats := stream.GetStats()
+	if stats.Packets != 4 || stats.Dropped != 1 || stats.DecodeErrors != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Cancelling ends the read; closing the reader stops queueing
+	cancel()
+	if _, err := r.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("Read() after cancel error = %v", err)
+	}
+	r.Close()
+	stream.WritePacket([]byte{7, 8})
+	if stream.GetStats().Dropped != 2 {
+		t.Error("packets after Close should be dropped")
+	}
+}
+
+func TestNewOpusStream(t *testing.T) {
+	stream, err := NewOpusStream(16000, 1)
+	if !opusAvailable {
+		if !errors.Is(err, ErrOpusUnavailable) {
+			t.Errorf("NewOpusStream() error = %v, want ErrOpusUnavailable", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("NewOpusStream() error = %v", err)
+	}
+	stream.Close()
+}
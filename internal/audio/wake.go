@@ -0,0 +1,254 @@
+package audio
+
+import (
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// WakeConfig configures the wake candidate detector
+type WakeConfig struct {
+	PreRoll  time.Duration // Audio kept from before an onset and sent with it
+	Window   time.Duration // Frame the level is measured over
+	SpikeDB  float64       // Rise above the background level that counts as an onset
+	MinDBFS  float64       // Quietest level that can trigger
+	Stream   time.Duration // Live audio passed on after an onset
+	Cooldown time.Duration // No new candidate this soon after the last one
+	FloorTau time.Duration // Time constant of the background level
+	Warmup   time.Duration // Audio heard before the background level is trusted
+}
+
+// DefaultWakeConfig returns sensible defaults
+func DefaultWakeConfig() WakeConfig {
+	return WakeConfig{
+		PreRoll:  time.Second,
+		Window:   20 * time.Millisecond,
+		SpikeDB:  15,
+		MinDBFS:  -50,
+		Stream:   2 * time.Second,
+		Cooldown: 3 * time.Second,
+		FloorTau: 2 * time.Second,
+		Warmup:   500 * time.Millisecond,
+	}
+}
+
+// WakeCandidate is a sudden rise in mic energy that may be someone starting
+// to say the wake word
+type WakeCandidate struct {
+	ID        uint64        `json:"id"`
+	Timestamp time.Time     `json:"timestamp"` // Onset
+	LevelDBFS float64       `json:"level_dbfs"`
+	FloorDBFS float64       `json:"floor_dbfs"` // Background level before the onset
+	PreRoll   time.Duration `json:"pre_roll"`   // Buffered audio passed on ahead of the onset chunk
+	Beam      *Beam         `json:"beam,omitempty"`
+}
+
+// TopicWake carries wake candidates
+var TopicWake = bus.NewTopic[WakeCandidate]("audio.wake")
+
+// WakeDetector watches captured audio for energy onsets. It keeps the last
+// PreRoll of audio so that, when one fires, the audio from just before the
+// onset can be handed on with it, followed by Stream of live audio; a
+// recognizer then gets the whole wake word instead of its tail.
+type WakeDetector struct {
+	cfg    WakeConfig
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	preRoll     []AudioChunk
+	buffered    time.Duration
+	floor       float64 // Background level (dBFS)
+	heard       time.Duration
+	lastTrigger time.Time
+	streamUntil time.Time
+	streamID    uint64
+
+	onCandidate func(WakeCandidate)
+	onAudio     func(id uint64, chunk AudioChunk)
+
+	// Stats
+	candidates atomic.Uint64
+	streamed   atomic.Uint64
+}
+
+// NewWakeDetector creates a wake candidate detector
+func NewWakeDetector(cfg WakeConfig, logger *slog.Logger) *WakeDetector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	defaults := DefaultWakeConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.FloorTau <= 0 {
+		cfg.FloorTau = defaults.FloorTau
+	}
+	return &WakeDetector{cfg: cfg, logger: logger, floor: MinDBFS}
+}
+
+// OnCandidate sets the callback for new candidates. It runs before the
+// candidate's audio is passed to OnAudio.
+func (d *WakeDetector) OnCandidate(callback func(WakeCandidate)) {
+	d.mu.Lock()
+	d.onCandidate = callback
+	d.mu.Unlock()
+}
+
+// OnAudio sets the callback for a candidate's audio: the pre-roll, the
+// onset chunk, then live chunks until Stream has passed
+func (d *WakeDetector) OnAudio(callback func(id uint64, chunk AudioChunk)) {
+	d.mu.Lock()
+	d.onAudio = callback
+	d.mu.Unlock()
+}
+
+// PublishTo publishes candidates on b
+func (d *WakeDetector) PublishTo(b *bus.Bus) {
+	d.OnCandidate(bus.Publisher(b, TopicWake))
+}
+
+// Feed checks a captured chunk for an onset and buffers it as pre-roll
+func (d *WakeDetector) Feed(chunk AudioChunk) {
+	if chunk.SampleRate <= 0 || chunk.Channels <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	var (
+		candidate *WakeCandidate
+		send      []AudioChunk
+		id        uint64
+	)
+	if !chunk.Timestamp.Before(d.streamUntil) {
+		candidate = d.detectLocked(chunk)
+	}
+	if candidate != nil {
+		send = append(d.preRoll, chunk)
+		d.preRoll, d.buffered = nil, 0
+	} else if chunk.Timestamp.Before(d.streamUntil) {
+		send = []AudioChunk{chunk}
+	} else {
+		d.bufferLocked(chunk)
+	}
+	id = d.streamID
+	onCandidate, onAudio := d.onCandidate, d.onAudio
+	d.mu.Unlock()
+
+	if candidate != nil {
+		d.candidates.Add(1)
+		d.logger.Debug("wake candidate", "id", candidate.ID, "level_dbfs", candidate.LevelDBFS, "floor_dbfs", candidate.FloorDBFS)
+		if onCandidate != nil {
+			onCandidate(*candidate)
+		}
+	}
+	for _, c := range send {
+		d.streamed.Add(1)
+		if onAudio != nil {
+			onAudio(id, c)
+		}
+	}
+}
+
+// detectLocked measures chunk frame by frame, updating the background
+// level, and returns a candidate at the first frame that rises SpikeDB above
+// it. Caller holds mu.
+func (d *WakeDetector) detectLocked(chunk AudioChunk) *WakeCandidate {
+	frameSize := 2 * chunk.Channels
+	total := len(chunk.Data) / frameSize
+	window := max(1, int(int64(chunk.SampleRate)*int64(d.cfg.Window)/int64(time.Second)))
+	start := chunk.Timestamp.Add(-chunk.Duration()) // Chunk timestamps mark their end
+
+	for f := 0; f+window <= total; f += window {
+		level := frameDBFS(chunk.Data[f*frameSize : (f+window)*frameSize])
+		floor := d.floor
+		d.updateFloorLocked(level)
+
+		if d.heard < d.cfg.Warmup || level < d.cfg.MinDBFS || level-floor < d.cfg.SpikeDB {
+			continue
+		}
+		at := start.Add(time.Duration(int64(f) * int64(time.Second) / int64(chunk.SampleRate)))
+		if !d.lastTrigger.IsZero() && at.Sub(d.lastTrigger) < d.cfg.Cooldown {
+			continue
+		}
+
+		d.lastTrigger = at
+		d.streamUntil = at.Add(d.cfg.Stream)
+		d.streamID++
+		return &WakeCandidate{
+			ID:        d.streamID,
+			Timestamp: at,
+			LevelDBFS: level,
+			FloorDBFS: floor,
+			PreRoll:   d.buffered,
+			Beam:      chunk.Beam,
+		}
+	}
+	return nil
+}
+
+// updateFloorLocked moves the background level toward a frame's level
+func (d *WakeDetector) updateFloorLocked(level float64) {
+	if d.heard == 0 {
+		d.floor = level
+	} else {
+		alpha := 1 - math.Exp(-d.cfg.Window.Seconds()/d.cfg.FloorTau.Seconds())
+		d.floor += alpha * (level - d.floor)
+	}
+	d.heard += d.cfg.Window
+}
+
+// bufferLocked adds chunk to the pre-roll, dropping chunks older than
+// PreRoll. Caller holds mu.
+func (d *WakeDetector) bufferLocked(chunk AudioChunk) {
+	if d.cfg.PreRoll <= 0 {
+		return
+	}
+	d.preRoll = append(d.preRoll, chunk)
+	d.buffered += chunk.Duration()
+	for len(d.preRoll) > 1 && d.buffered-d.preRoll[0].Duration() >= d.cfg.PreRoll {
+		d.buffered -= d.preRoll[0].Duration()
+		d.preRoll = d.preRoll[1:]
+	}
+}
+
+// frameDBFS returns the RMS level of PCM16 samples (all channels) in dBFS
+func frameDBFS(pcm []byte) float64 {
+	var sumSq float64
+	n := len(pcm) / 2
+	for i := 0; i < n; i++ {
+		v := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		sumSq += v * v
+	}
+	if n == 0 || sumSq == 0 {
+		return MinDBFS
+	}
+	return max(MinDBFS, 20*math.Log10(math.Sqrt(sumSq/float64(n))/32768))
+}
+
+// WakeStats contains wake detector statistics
+type WakeStats struct {
+	Candidates     uint64  `json:"candidates"`
+	ChunksStreamed uint64  `json:"chunks_streamed"`
+	FloorDBFS      float64 `json:"floor_dbfs"`
+	Streaming      bool    `json:"streaming"`
+}
+
+// GetStats returns detector statistics
+func (d *WakeDetector) GetStats() WakeStats {
+	d.mu.Lock()
+	floor := d.floor
+	streaming := d.streamID != 0 && time.Now().Before(d.streamUntil)
+	d.mu.Unlock()
+
+	return WakeStats{
+		Candidates:     d.candidates.Load(),
+		ChunksStreamed: d.streamed.Load(),
+		FloorDBFS:      floor,
+		Streaming:      streaming,
+	}
+}
@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// monoPCM builds frames of PCM16 with a constant value, switching to loud
+// from frame onset on
+func monoPCM(frames int, quiet, loud int16, onset int) []byte {
+	data := make([]byte, 2*frames)
+	for i := 0; i < frames; i++ {
+		v := quiet
+		if i >= onset {
+			v = loud
+		}
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(v))
+	}
+	return data
+}
+
+func TestWakeDetector(t *testing.T) {
+	d := NewWakeDetector(DefaultWakeConfig(), nil)
+
+	var (
+		candidates []WakeCandidate
+		audio      []time.Time
+	)
+	d.OnCandidate(func(c WakeCandidate) { candidates = append(candidates, c) })
+	d.OnAudio(func(id uint64, chunk AudioChunk) {
+		if len(candidates) == 0 || id != candidates[len(candidates)-1].ID {
+			t.Errorf("audio for candidate %d arrived before it", id)
+		}
+		audio = append(audio, chunk.Timestamp)
+	})
+
+	// 100ms chunks ending at base + (i+1)*100ms
+	base := time.Now()
+	feed := func(i int, data []byte) {
+		d.Feed(AudioChunk{Data: data, SampleRate: 16000, Channels: 1, Timestamp: base.Add(time.Duration(i+1) * 100 * time.Millisecond)})
+	}
+
+	// 1.5s of quiet room (about -60 dBFS)
+	for i := 0; i < 15; i++ {
+		feed(i, monoPCM(1600, 30, 30, 1600))
+	}
+	if len(candidates) != 0 {
+		t.Fatalf("quiet audio triggered %+v", candidates)
+	}
+
+	// Speech starts 60ms into chunk 15
+	feed(15, monoPCM(1600, 30, 8000, 960))
+	if len(candidates) != 1 {
+		t.Fatalf("got %d candidates, want 1", len(candidates))
+	}
+	c := candidates[0]
+	if want := base.Add(1560 * time.Millisecond); !c.Timestamp.Equal(want) {
+		t.Errorf("onset = %v, want %v", c.Timestamp.Sub(base), want.Sub(base))
+	}
+	if c.PreRoll != time.Second || c.LevelDBFS-c.FloorDBFS < 15 {
+		t.Errorf("unexpected candidate %+v", c)
+	}
+	// The pre-roll (chunks 5-14) and the onset chunk follow at once
+	if len(audio) != 11 || !audio[0].Equal(base.Add(600*time.Millisecond)) {
+		t.Fatalf("got %d chunks starting at %v, want 11 from 600ms", len(audio), audio[0].Sub(base))
+	}
+
+	// Live chunks follow until 2s after the onset (those ending at 1.7s to
+	// 3.5s); loud audio during the stream and the cooldown doesn't trigger
+	// again
+	for i := 16; i < 40; i++ {
+		feed(i, monoPCM(1600, 8000, 8000, 0))
+	}
+	if len(audio) != 11+19 {
+		t.Errorf("got %d chunks, want the pre-roll, onset and 19 live", len(audio))
+	}
+	if len(candidates) != 1 {
+		t.Errorf("got %d candidates during the cooldown, want 1", len(candidates))
+	}
+	if stats := d.GetStats(); stats.Candidates != 1 || stats.ChunksStreamed != 30 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,504 @@
+// Package audit keeps an append-only, tamper-evident record of every motor
+// and emotion command, for safety review of what the robot was told to do
+// and by whom.
+//
+// Entries are JSONL in rotating files. Each entry carries the SHA-256 hash
+// of the one before it, so editing or deleting a line breaks the chain and
+// shows up in Verify.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// Config holds audit log configuration
+type Config struct {
+	Dir          string // Directory holding log files
+	MaxFileBytes int64  // Start a new file beyond this size
+	MaxFiles     int    // Delete the oldest files beyond this count (0 = keep all)
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Dir:          "/var/lib/go-eva/audit",
+		MaxFileBytes: 16 << 20,
+		MaxFiles:     32,
+	}
+}
+
+// Source is who issued a command
+type Source string
+
+// Command sources
+const (
+	SourceCloud    Source = "cloud"    // Cloud connection
+	SourceLocal    Source = "local"    // HTTP or gRPC API on the robot
+	SourceBehavior Source = "behavior" // go-eva's own behaviors (gestures)
+)
+
+// Kind is what a command asked for
+type Kind string
+
+// Command kinds
+const (
+	KindMotor    Kind = "motor"    // Head, antenna and body target
+	KindAntennas Kind = "antennas" // Antennas only
+	KindEmotion  Kind = "emotion"
+	KindChoreo   Kind = "choreo" // Keyframed motion sequence
+	KindStop     Kind = "stop"   // Emergency stop
+	KindResume   Kind = "resume"
+)
+
+// Command results
+const (
+	ResultOK       = "ok"
+	ResultRejected = "rejected" // Refused by the emergency stop
+	ResultError    = "error"
+)
+
+// Entry is one audited command
+type Entry struct {
+	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Source  Source          `json:"source"`
+	Kind    Kind            `json:"kind"`
+	ID      string          `json:"id,omitempty"` // Cloud envelope ID
+	Command json.RawMessage `json:"command,omitempty"`
+	Result  string          `json:"result"`
+	Error   string          `json:"error,omitempty"`
+	Prev    string          `json:"prev"` // Hash of the previous entry
+	Hash    string          `json:"hash"` // SHA-256 of this entry with Hash empty
+}
+
+// hash computes the chain hash of e
+func (e Entry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".jsonl"
+)
+
+// logFile is one file of the log
+type logFile struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+// Log appends audited commands to rotating JSONL files
+type Log struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	files []logFile // Oldest first; the last one is open for writing
+	file  *os.File
+	seq   uint64
+	prev  string
+
+	// Stats
+	recorded    atomic.Uint64
+	writeErrors atomic.Uint64
+	rotations   atomic.Uint64
+	pruned      atomic.Uint64
+}
+
+// Open creates the log directory and continues the hash chain from the
+// newest existing entry
+func Open(cfg Config, logger *slog.Logger) (*Log, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.MaxFileBytes <= 0 {
+		cfg.MaxFileBytes = DefaultConfig().MaxFileBytes
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+
+	l := &Log{
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	files, err := l.scan()
+	if err != nil {
+		return nil, err
+	}
+	l.files = files
+
+	// The newest file may be empty if we crashed right after rotating
+	for i := len(files) - 1; i >= 0; i-- {
+		last, ok, err := lastEntry(files[i].path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			l.seq, l.prev = last.Seq, last.Hash
+			break
+		}
+	}
+
+	logger.Info("audit log opened",
+		"dir", cfg.Dir,
+		"files", len(files),
+		"seq", l.seq,
+	)
+
+	return l, nil
+}
+
+// scan lists existing log files sorted by start time
+func (l *Log) scan() ([]logFile, error) {
+	entries, err := os.ReadDir(l.cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read audit dir: %w", err)
+	}
+
+	var files []logFile
+	for _, e := range entries {
+		start, ok := parseFileName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{
+			path:  filepath.Join(l.cfg.Dir, e.Name()),
+			start: start,
+			size:  info.Size(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].start.Before(files[j].start) })
+	return files, nil
+}
+
+func fileName(start time.Time) string {
+	return filePrefix + strconv.FormatInt(start.UnixMilli(), 10) + fileSuffix
+}
+
+func parseFileName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+// resultOf classifies a command error
+func resultOf(err error) string {
+	switch {
+	case err == nil:
+		return ResultOK
+	case errors.Is(err, pollen.ErrStopped):
+		return ResultRejected
+	default:
+		return ResultError
+	}
+}
+
+// Record appends a command and its outcome. id is the cloud envelope ID, if
+// any; command is stored as JSON. Every entry is written through to the file
+// before Record returns.
+func (l *Log) Record(source Source, kind Kind, id string, command any, cmdErr error) error {
+	e := Entry{
+		Time:   time.Now().UTC(),
+		Source: source,
+		Kind:   kind,
+		ID:     id,
+		Result: resultOf(cmdErr),
+	}
+	if cmdErr != nil {
+		e.Error = cmdErr.Error()
+	}
+	if command != nil {
+		payload, err := json.Marshal(command)
+		if err != nil {
+			l.writeErrors.Add(1)
+			return fmt.Errorf("marshal command: %w", err)
+		}
+		e.Command = payload
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	e.Prev = l.prev
+	hash, err := e.hash()
+	if err != nil {
+		l.writeErrors.Add(1)
+		return fmt.Errorf("hash entry: %w", err)
+	}
+	e.Hash = hash
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		l.writeErrors.Add(1)
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if l.file == nil || l.files[len(l.files)-1].size+int64(len(line)) > l.cfg.MaxFileBytes {
+		if err := l.rotateLocked(e.Time); err != nil {
+			l.writeErrors.Add(1)
+			return err
+		}
+	}
+
+	if _, err := l.file.Write(line); err != nil {
+		l.writeErrors.Add(1)
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	l.files[len(l.files)-1].size += int64(len(line))
+	l.seq, l.prev = e.Seq, e.Hash
+	l.recorded.Add(1)
+	return nil
+}
+
+// rotateLocked closes the current file, opens a new one, and prunes old
+// files. A restart always starts a new file, so a line torn by a crash is
+// never appended to.
+func (l *Log) rotateLocked(now time.Time) error {
+	if err := l.closeLocked(); err != nil {
+		return err
+	}
+
+	// Keep names unique and ordered even if two rotations share a millisecond
+	start := now.Truncate(time.Millisecond)
+	if n := len(l.files); n > 0 && !start.After(l.files[n-1].start) {
+		start = l.files[n-1].start.Add(time.Millisecond)
+	}
+
+	path := filepath.Join(l.cfg.Dir, fileName(start))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+
+	l.file = f
+	l.files = append(l.files, logFile{path: path, start: start})
+	l.rotations.Add(1)
+
+	l.pruneLocked()
+	return nil
+}
+
+// pruneLocked deletes the oldest files beyond MaxFiles
+func (l *Log) pruneLocked() {
+	for l.cfg.MaxFiles > 0 && len(l.files) > l.cfg.MaxFiles {
+		oldest := l.files[0]
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			l.logger.Warn("audit file prune failed", "path", oldest.path, "error", err)
+			return
+		}
+		l.files = l.files[1:]
+		l.pruned.Add(1)
+	}
+}
+
+func (l *Log) closeLocked() error {
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("close audit file: %w", err)
+	}
+	return nil
+}
+
+// Query returns entries recorded at or after since, oldest first. limit <= 0
+// returns everything.
+func (l *Log) Query(since time.Time, limit int) ([]Entry, error) {
+	entries := []Entry{}
+	err := l.each(func(e Entry) bool {
+		if e.Time.Before(since) {
+			return true
+		}
+		entries = append(entries, e)
+		return limit <= 0 || len(entries) < limit
+	})
+	return entries, err
+}
+
+// VerifyResult reports whether the hash chain is intact
+type VerifyResult struct {
+	OK       bool   `json:"ok"`
+	Entries  uint64 `json:"entries"`             // Entries checked
+	FirstSeq uint64 `json:"first_seq,omitempty"` // Oldest entry still on disk
+	LastSeq  uint64 `json:"last_seq,omitempty"`
+	BrokenAt uint64 `json:"broken_at,omitempty"` // Seq of the first entry that fails
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify walks the whole log and checks every entry's hash, its link to the
+// previous entry, and that no sequence numbers are missing. The oldest entry
+// on disk anchors the chain, since pruned files can't be checked.
+func (l *Log) Verify() (VerifyResult, error) {
+	var res VerifyResult
+	var prev Entry
+	err := l.each(func(e Entry) bool {
+		fail := func(reason string) bool {
+			res.BrokenAt, res.Reason = e.Seq, reason
+			return false
+		}
+
+		if hash, err := e.hash(); err != nil || hash != e.Hash {
+			return fail("hash mismatch")
+		}
+		if res.Entries > 0 {
+			if e.Seq != prev.Seq+1 {
+				return fail(fmt.Sprintf("expected seq %d", prev.Seq+1))
+			}
+			if e.Prev != prev.Hash {
+				return fail("previous hash mismatch")
+			}
+		} else {
+			res.FirstSeq = e.Seq
+		}
+
+		res.Entries++
+		res.LastSeq = e.Seq
+		prev = e
+		return true
+	})
+	if err != nil {
+		return res, err
+	}
+	res.OK = res.Reason == ""
+	return res, nil
+}
+
+// each streams every entry to fn, oldest first, until it returns false
+func (l *Log) each(fn func(Entry) bool) error {
+	l.mu.Lock()
+	files := make([]logFile, len(l.files))
+	copy(files, l.files)
+	l.mu.Unlock()
+
+	for _, f := range files {
+		more, err := readFile(f.path, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+func readFile(path string, fn func(Entry) bool) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil // Pruned while we were reading
+		}
+		return false, fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Tolerate a torn final line after a crash
+		}
+		if !fn(e) {
+			return false, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read audit file: %w", err)
+	}
+	return true, nil
+}
+
+// lastEntry returns the newest complete entry in a file
+func lastEntry(path string) (Entry, bool, error) {
+	var last Entry
+	var found bool
+	_, err := readFile(path, func(e Entry) bool {
+		last, found = e, true
+		return true
+	})
+	return last, found, err
+}
+
+// Stats contains audit log statistics
+type Stats struct {
+	Recorded     uint64 `json:"recorded"`
+	WriteErrors  uint64 `json:"write_errors"`
+	Rotations    uint64 `json:"rotations"`
+	PrunedFiles  uint64 `json:"pruned_files"`
+	Files        int    `json:"files"`
+	Bytes        int64  `json:"bytes"`
+	LastSeq      uint64 `json:"last_seq"`
+	Dir          string `json:"dir"`
+	MaxFileBytes int64  `json:"max_file_bytes"`
+}
+
+// GetStats returns audit log statistics
+func (l *Log) GetStats() Stats {
+	l.mu.Lock()
+	var total int64
+	for _, f := range l.files {
+		total += f.size
+	}
+	count, seq := len(l.files), l.seq
+	l.mu.Unlock()
+
+	return Stats{
+		Recorded:     l.recorded.Load(),
+		WriteErrors:  l.writeErrors.Load(),
+		Rotations:    l.rotations.Load(),
+		PrunedFiles:  l.pruned.Load(),
+		Files:        count,
+		Bytes:        total,
+		LastSeq:      seq,
+		Dir:          l.cfg.Dir,
+		MaxFileBytes: l.cfg.MaxFileBytes,
+	}
+}
+
+// Close closes the open file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closeLocked()
+}
@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+func openTestLog(t *testing.T, cfg Config) *Log {
+	t.Helper()
+
+	l, err := Open(cfg, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dir = t.TempDir()
+	l := openTestLog(t, cfg)
+
+	start := time.Now()
+	if err := l.Record(SourceCloud, KindMotor, "cmd-1", TargetCommand{Head: pollen.HeadTarget{Yaw: 0.5}}, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := l.Record(SourceLocal, KindEmotion, "", EmotionCommand{Name: "happy"}, pollen.ErrStopped); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := l.Record(SourceBehavior, KindAntennas, "", AntennasCommand{}, errors.New("pollen down")); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	entries, err := l.Query(start.Add(-time.Second), 0)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Query() returned %d entries, want 3", len(entries))
+	}
+
+	first := entries[0]
+	if first.Seq != 1 || first.Source != SourceCloud || first.ID != "cmd-1" || first.Result != ResultOK || first.Prev != "" {
+		t.Errorf("first entry = %+v", first)
+	}
+	if !strings.Contains(string(first.Command), `"yaw":0.5`) {
+		t.Errorf("command = %s", first.Command)
+	}
+	if entries[1].Result != ResultRejected || entries[2].Result != ResultError || entries[2].Error != "pollen down" {
+		t.Errorf("results = %q, %q", entries[1].Result, entries[2].Result)
+	}
+	if entries[1].Prev != first.Hash {
+		t.Error("entries are not chained")
+	}
+
+	if limited, _ := l.Query(time.Time{}, 2); len(limited) != 2 {
+		t.Errorf("limit not applied: got %d", len(limited))
+	}
+	if later, _ := l.Query(time.Now().Add(time.Second), 0); len(later) != 0 {
+		t.Errorf("since not applied: got %d", len(later))
+	}
+}
+
+func TestLog_ChainSurvivesRotationAndRestart(t *testing.T) {
+	cfg := Config{Dir: t.TempDir(), MaxFileBytes: 600}
+	l := openTestLog(t, cfg)
+
+	for i := range 10 {
+		if err := l.Record(SourceCloud, KindEmotion, "", EmotionCommand{Name: fmt.Sprint("e", i)}, nil); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	l.Close()
+
+	l = openTestLog(t, cfg)
+	if err := l.Record(SourceLocal, KindStop, "", nil, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if stats := l.GetStats(); stats.Files < 3 || stats.LastSeq != 11 {
+		t.Errorf("stats = %+v, want several files and seq 11", stats)
+	}
+
+	res, err := l.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !res.OK || res.Entries != 11 || res.FirstSeq != 1 || res.LastSeq != 11 {
+		t.Errorf("Verify() = %+v", res)
+	}
+}
+
+func TestLog_VerifyDetectsTampering(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dir = t.TempDir()
+	l := openTestLog(t, cfg)
+
+	for _, name := range []string{"happy", "sad", "yes"} {
+		l.Record(SourceCloud, KindEmotion, "", EmotionCommand{Name: name}, nil)
+	}
+
+	path := l.files[0].path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewriting a command breaks its hash
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), `"sad"`, `"yes"`, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := l.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if res.OK || res.BrokenAt != 2 || res.Reason != "hash mismatch" {
+		t.Errorf("edited log: Verify() = %+v", res)
+	}
+
+	// Deleting a line breaks the sequence
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(path, []byte(lines[0]+lines[2]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = l.Verify()
+	if res.OK || res.BrokenAt != 3 {
+		t.Errorf("truncated log: Verify() = %+v", res)
+	}
+}
+
+func TestLog_PrunesOldFiles(t *testing.T) {
+	l := openTestLog(t, Config{Dir: t.TempDir(), MaxFileBytes: 1, MaxFiles: 3})
+
+	for range 6 {
+		l.Record(SourceLocal, KindResume, "", nil, nil)
+	}
+
+	stats := l.GetStats()
+	if stats.Files != 3 || stats.PrunedFiles != 3 {
+		t.Errorf("stats = %+v, want 3 files after pruning 3", stats)
+	}
+
+	// The oldest surviving entry anchors the chain
+	res, _ := l.Verify()
+	if !res.OK || res.FirstSeq != 4 || res.Entries != 3 {
+		t.Errorf("Verify() = %+v", res)
+	}
+}
+
+type fakeMover struct {
+	err error
+}
+
+func (f *fakeMover) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	return f.err
+}
+
+func (f *fakeMover) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	return f.err
+}
+
+func (f *fakeMover) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	return f.err
+}
+
+func TestMotor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dir = t.TempDir()
+	l := openTestLog(t, cfg)
+	ctx := context.Background()
+
+	mover := &fakeMover{}
+	m := l.Motor(mover, SourceLocal)
+	m.SetTarget(ctx, pollen.HeadTarget{Pitch: 0.1}, [2]float64{}, 0)
+	m.SetAntennas(ctx, [2]float64{0.2, 0.2})
+
+	mover.err = pollen.ErrStopped
+	if err := l.Emotions(mover, SourceBehavior).PlayEmotion(ctx, "curious", 3); !errors.Is(err, pollen.ErrStopped) {
+		t.Errorf("PlayEmotion() error = %v, want the wrapped error", err)
+	}
+
+	entries, _ := l.Query(time.Time{}, 0)
+	if len(entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(entries))
+	}
+	want := []struct {
+		source Source
+		kind   Kind
+		result string
+	}{
+		{SourceLocal, KindMotor, ResultOK},
+		{SourceLocal, KindAntennas, ResultOK},
+		{SourceBehavior, KindEmotion, ResultRejected},
+	}
+	for i, w := range want {
+		if e := entries[i]; e.Source != w.source || e.Kind != w.kind || e.Result != w.result {
+			t.Errorf("entry %d = %s/%s/%s, want %s/%s/%s", i, e.Source, e.Kind, e.Result, w.source, w.kind, w.result)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// Mover moves the robot (implemented by pollen.Limiter)
+type Mover interface {
+	SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+	SetAntennas(ctx context.Context, antennas [2]float64) error
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// EmotionPlayer plays emotions (implemented by emotion.Scheduler)
+type EmotionPlayer interface {
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// TargetCommand is the audited form of a head move
+type TargetCommand struct {
+	Head     pollen.HeadTarget `json:"head"`
+	Antennas [2]float64        `json:"antennas"`
+	BodyYaw  float64           `json:"body_yaw"`
+}
+
+// AntennasCommand is the audited form of an antenna move
+type AntennasCommand struct {
+	Antennas [2]float64 `json:"antennas"`
+}
+
+// EmotionCommand is the audited form of an emotion request
+type EmotionCommand struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// Motor audits every command passed through to a Mover
+type Motor struct {
+	log    *Log
+	source Source
+	next   Mover
+}
+
+// Motor wraps next so its commands are recorded under source
+func (l *Log) Motor(next Mover, source Source) *Motor {
+	return &Motor{log: l, source: source, next: next}
+}
+
+// SetTarget moves the head and records the command
+func (m *Motor) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	err := m.next.SetTarget(ctx, head, antennas, bodyYaw)
+	m.record(KindMotor, TargetCommand{Head: head, Antennas: antennas, BodyYaw: bodyYaw}, err)
+	return err
+}
+
+// SetAntennas moves the antennas and records the command
+func (m *Motor) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	err := m.next.SetAntennas(ctx, antennas)
+	m.record(KindAntennas, AntennasCommand{Antennas: antennas}, err)
+	return err
+}
+
+// PlayEmotion plays an emotion and records the command
+func (m *Motor) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	err := m.next.PlayEmotion(ctx, name, duration)
+	m.record(KindEmotion, EmotionCommand{Name: name, Duration: duration}, err)
+	return err
+}
+
+func (m *Motor) record(kind Kind, command any, cmdErr error) {
+	if err := m.log.Record(m.source, kind, "", command, cmdErr); err != nil {
+		m.log.logger.Warn("audit record failed", "kind", kind, "error", err)
+	}
+}
+
+// Emotions audits every request passed through to an EmotionPlayer
+type Emotions struct {
+	log    *Log
+	source Source
+	next   EmotionPlayer
+}
+
+// Emotions wraps next so its requests are recorded under source
+func (l *Log) Emotions(next EmotionPlayer, source Source) *Emotions {
+	return &Emotions{log: l, source: source, next: next}
+}
+
+// PlayEmotion queues an emotion and records the request
+func (e *Emotions) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	err := e.next.PlayEmotion(ctx, name, duration)
+	if rerr := e.log.Record(e.source, KindEmotion, "", EmotionCommand{Name: name, Duration: duration}, err); rerr != nil {
+		e.log.logger.Warn("audit record failed", "kind", KindEmotion, "error", rerr)
+	}
+	return err
+}
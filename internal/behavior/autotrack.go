@@ -0,0 +1,292 @@
+// Package behavior provides autonomous robot behaviors driven by local sensing.
+package behavior
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/latency"
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// AutoTrackConfig configures the face-the-speaker controller
+type AutoTrackConfig struct {
+	Rate          time.Duration // Control loop interval
+	DeadBand      float64       // Ignore yaw errors smaller than this (radians)
+	MaxVelocity   float64       // Max head yaw speed (radians/second)
+	MaxYaw        float64       // Head yaw limit, relative to the body (radians)
+	MinConfidence float64       // Minimum DOA confidence to follow
+	ReturnAfter   time.Duration // Return to center after this much silence (0 = stay)
+	YieldFor      time.Duration // Pause after an external (cloud) motor command
+
+	// The body turns toward speakers the head can't comfortably reach
+	MaxBodyYaw      float64 // Body yaw limit (radians); 0 keeps the body still
+	BodyThreshold   float64 // Head yaw, relative to the body, the body turns to keep within (radians)
+	BodyHysteresis  float64 // Margin around BodyThreshold before the body turns or re-centers (radians)
+	BodyMaxVelocity float64 // Max body yaw speed (radians/second)
+}
+
+// DefaultAutoTrackConfig returns conservative defaults
+func DefaultAutoTrackConfig() AutoTrackConfig {
+	return AutoTrackConfig{
+		Rate:          50 * time.Millisecond,
+		DeadBand:      8 * math.Pi / 180,
+		MaxVelocity:   90 * math.Pi / 180,
+		MaxYaw:        60 * math.Pi / 180,
+		MinConfidence: 0.6,
+		ReturnAfter:   10 * time.Second,
+		YieldFor:      2 * time.Second,
+
+		BodyThreshold:   50 * math.Pi / 180,
+		BodyHysteresis:  10 * math.Pi / 180,
+		BodyMaxVelocity: 45 * math.Pi / 180,
+	}
+}
+
+// Mover drives the head (implemented by pollen.Client)
+type Mover interface {
+	SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+}
+
+// AutoTracker turns the head toward the active speaker. With MaxBodyYaw
+// set, a speaker far to the side is faced by head and body together: the
+// body turns just enough to keep the head within BodyThreshold of it, and
+// the head counter-rotates as the body moves so the gaze stays on target.
+type AutoTracker struct {
+	cfg    AutoTrackConfig
+	mover  Mover
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	yaw        float64   // Commanded heading (body yaw + head yaw)
+	goal       float64   // Desired heading
+	body       float64   // Commanded body yaw
+	bodyGoal   float64   // Desired body yaw
+	lastHeard  time.Time // Last confident speech
+	yieldUntil time.Time
+	antennas   func() [2]float64
+	goalAt     time.Time // Capture time of the reading that set the goal, until a move toward it lands
+	latency    *latency.Recorder
+
+	// Stats
+	commandsSent atomic.Uint64
+	commandErrs  atomic.Uint64
+}
+
+// NewAutoTracker creates a face-the-speaker controller
+func NewAutoTracker(cfg AutoTrackConfig, mover Mover, logger *slog.Logger) *AutoTracker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultAutoTrackConfig().Rate
+	}
+
+	return &AutoTracker{
+		cfg:    cfg,
+		mover:  mover,
+		logger: logger,
+	}
+}
+
+// SetAntennaSource supplies antenna positions to send alongside head targets
+// (e.g., from the animation engine); antennas default to neutral
+func (a *AutoTracker) SetAntennaSource(fn func() [2]float64) {
+	a.mu.Lock()
+	a.antennas = fn
+	a.mu.Unlock()
+}
+
+// SetLatency records motor and end-to-end latency of tracking moves
+func (a *AutoTracker) SetLatency(rec *latency.Recorder) {
+	a.mu.Lock()
+	a.latency = rec
+	a.mu.Unlock()
+}
+
+// Yield pauses tracking so an external motor command isn't immediately overridden
+func (a *AutoTracker) Yield() {
+	a.mu.Lock()
+	a.yieldUntil = time.Now().Add(a.cfg.YieldFor)
+	a.mu.Unlock()
+}
+
+// Observe updates the goal from a tracker result
+func (a *AutoTracker) Observe(result doa.Result) {
+	if !result.SpeakingLatched || result.Confidence < a.cfg.MinConfidence {
+		return
+	}
+
+	// The heading is measured from body yaw 0, so once the tracker knows the
+	// body yaw, turning the body doesn't move the goal
+	angle := result.SmoothedAngle
+	if result.WorldFrame {
+		angle = result.WorldAngle
+	}
+	reach := a.cfg.MaxYaw + max(a.cfg.MaxBodyYaw, 0)
+	goal := doa.Clamp(angle, -reach, reach)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastHeard = time.Now()
+	if math.Abs(goal-a.yaw) >= a.cfg.DeadBand {
+		if goal != a.goal {
+			a.goalAt = result.Timestamp
+		}
+		a.goal = goal
+	}
+}
+
+// Step advances the commanded heading by at most MaxVelocity*dt and the body
+// by at most BodyMaxVelocity*dt, returning the head yaw (relative to the
+// body) and body yaw to send and whether they changed
+func (a *AutoTracker) Step(now time.Time, dt time.Duration) (yaw, bodyYaw float64, move bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.Before(a.yieldUntil) {
+		return a.headLocked(), a.body, false
+	}
+
+	if a.cfg.ReturnAfter > 0 && !a.lastHeard.IsZero() && now.Sub(a.lastHeard) > a.cfg.ReturnAfter {
+		a.goal = 0
+	}
+	a.updateBodyGoalLocked()
+
+	turned := approach(&a.yaw, a.goal, a.cfg.MaxVelocity*dt.Seconds())
+	bodyTurned := approach(&a.body, a.bodyGoal, a.cfg.BodyMaxVelocity*dt.Seconds())
+	return a.headLocked(), a.body, turned || bodyTurned
+}
+
+// updateBodyGoalLocked decides where the body should face. It turns once
+// the head would have to pass BodyThreshold by BodyHysteresis, going just
+// far enough to bring the head back to BodyThreshold, and re-centers once
+// the speaker is BodyHysteresis inside BodyThreshold of straight ahead.
+func (a *AutoTracker) updateBodyGoalLocked() {
+	if a.cfg.MaxBodyYaw <= 0 {
+		a.bodyGoal = 0
+		return
+	}
+
+	rel := a.goal - a.bodyGoal
+	switch {
+	case math.Abs(a.goal) < a.cfg.BodyThreshold-a.cfg.BodyHysteresis:
+		a.bodyGoal = 0
+	case math.Abs(rel) > a.cfg.BodyThreshold+a.cfg.BodyHysteresis:
+		a.bodyGoal = a.goal - math.Copysign(a.cfg.BodyThreshold, rel)
+	}
+	a.bodyGoal = doa.Clamp(a.bodyGoal, -a.cfg.MaxBodyYaw, a.cfg.MaxBodyYaw)
+}
+
+// headLocked returns the head yaw, relative to the body, that keeps the
+// commanded heading
+func (a *AutoTracker) headLocked() float64 {
+	return doa.Clamp(a.yaw-a.body, -a.cfg.MaxYaw, a.cfg.MaxYaw)
+}
+
+// approach moves *pos toward goal by at most maxStep (no limit if maxStep
+// is not positive), reporting whether it moved
+func approach(pos *float64, goal, maxStep float64) bool {
+	diff := goal - *pos
+	if diff == 0 {
+		return false
+	}
+	if maxStep > 0 && math.Abs(diff) > maxStep {
+		diff = math.Copysign(maxStep, diff)
+	}
+	*pos += diff
+	return true
+}
+
+// Run consumes tracker results and drives the head until ctx is cancelled
+func (a *AutoTracker) Run(ctx context.Context, results <-chan doa.Result) {
+	ticker := time.NewTicker(a.cfg.Rate)
+	defer ticker.Stop()
+
+	a.logger.Info("autotrack started",
+		"dead_band", a.cfg.DeadBand,
+		"max_velocity", a.cfg.MaxVelocity,
+		"max_body_yaw", a.cfg.MaxBodyYaw,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			a.Observe(result)
+		case now := <-ticker.C:
+			yaw, bodyYaw, move := a.Step(now, a.cfg.Rate)
+			if !move || a.mover == nil {
+				continue
+			}
+
+			a.mu.Lock()
+			antennaFn := a.antennas
+			rec, goalAt := a.latency, a.goalAt
+			a.mu.Unlock()
+
+			var antennas [2]float64
+			if antennaFn != nil {
+				antennas = antennaFn()
+			}
+
+			start := time.Now()
+			if err := a.mover.SetTarget(ctx, pollen.HeadTarget{Yaw: yaw}, antennas, bodyYaw); err != nil {
+				a.commandErrs.Add(1)
+				a.logger.Debug("autotrack move failed", "error", err)
+				continue
+			}
+			a.commandsSent.Add(1)
+
+			if rec != nil {
+				rec.Observe(latency.StageMotor, time.Since(start))
+				if !goalAt.IsZero() {
+					// The first move toward a new speaker is the reaction
+					rec.Since(latency.StageEndToEnd, goalAt)
+					a.mu.Lock()
+					if a.goalAt.Equal(goalAt) {
+						a.goalAt = time.Time{}
+					}
+					a.mu.Unlock()
+				}
+			}
+		}
+	}
+}
+
+// AutoTrackStats contains controller statistics
+type AutoTrackStats struct {
+	Yaw           float64 `json:"yaw"`  // Head yaw, relative to the body
+	Goal          float64 `json:"goal"` // Desired heading
+	BodyYaw       float64 `json:"body_yaw"`
+	BodyGoal      float64 `json:"body_goal"`
+	CommandsSent  uint64  `json:"commands_sent"`
+	CommandErrors uint64  `json:"command_errors"`
+}
+
+// GetStats returns controller statistics
+func (a *AutoTracker) GetStats() AutoTrackStats {
+	a.mu.Lock()
+	yaw, goal := a.headLocked(), a.goal
+	body, bodyGoal := a.body, a.bodyGoal
+	a.mu.Unlock()
+
+	return AutoTrackStats{
+		Yaw:           yaw,
+		Goal:          goal,
+		BodyYaw:       body,
+		BodyGoal:      bodyGoal,
+		CommandsSent:  a.commandsSent.Load(),
+		CommandErrors: a.commandErrs.Load(),
+	}
+}
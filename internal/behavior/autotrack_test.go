@@ -0,0 +1,240 @@
+package behavior
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/latency"
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+type fakeMover struct {
+	mu     sync.Mutex
+	yaws   []float64
+	bodies []float64
+}
+
+func (f *fakeMover) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.yaws = append(f.yaws, head.Yaw)
+	f.bodies = append(f.bodies, bodyYaw)
+	return nil
+}
+
+func speaking(angle float64) doa.Result {
+	return doa.Result{SmoothedAngle: angle, Confidence: 0.9, SpeakingLatched: true}
+}
+
+func TestStep_VelocityLimited(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	a := NewAutoTracker(cfg, nil, nil)
+	a.Observe(speaking(1.0))
+
+	now := time.Now()
+	yaw, _, move := a.Step(now, 100*time.Millisecond)
+	if !move {
+		t.Fatal("expected a move toward the speaker")
+	}
+	if want := cfg.MaxVelocity * 0.1; math.Abs(yaw-want) > 1e-9 {
+		t.Errorf("first step yaw = %f, want %f", yaw, want)
+	}
+
+	for i := 0; i < 50; i++ {
+		yaw, _, _ = a.Step(now, 100*time.Millisecond)
+	}
+	if math.Abs(yaw-1.0) > 1e-9 {
+		t.Errorf("yaw should converge to goal, got %f", yaw)
+	}
+	if _, _, move := a.Step(now, 100*time.Millisecond); move {
+		t.Error("no move expected once at goal")
+	}
+}
+
+func TestObserve_DeadBandAndConfidence(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	a := NewAutoTracker(cfg, nil, nil)
+
+	a.Observe(speaking(cfg.DeadBand / 2))
+	if _, _, move := a.Step(time.Now(), cfg.Rate); move {
+		t.Error("angles inside the dead band should not move the head")
+	}
+
+	a.Observe(doa.Result{SmoothedAngle: 1.0, Confidence: 0.1, SpeakingLatched: true})
+	if _, _, move := a.Step(time.Now(), cfg.Rate); move {
+		t.Error("low-confidence results should be ignored")
+	}
+
+	a.Observe(doa.Result{SmoothedAngle: 1.0, Confidence: 0.9})
+	if _, _, move := a.Step(time.Now(), cfg.Rate); move {
+		t.Error("results without speech should be ignored")
+	}
+}
+
+func TestObserve_WorldFrame(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	a := NewAutoTracker(cfg, nil, nil)
+
+	// The body has turned 0.3 rad toward a speaker at 0.5 rad: the
+	// robot-relative angle reads 0.2, but the goal stays at 0.5
+	result := speaking(0.2)
+	result.WorldAngle, result.BodyYaw, result.WorldFrame = 0.5, 0.3, true
+	a.Observe(result)
+	if goal := a.GetStats().Goal; goal != 0.5 {
+		t.Errorf("goal = %v, want the world angle 0.5", goal)
+	}
+}
+
+func TestObserve_ClampsToMaxYaw(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	a := NewAutoTracker(cfg, nil, nil)
+	a.Observe(speaking(-math.Pi))
+
+	if got := a.GetStats().Goal; got != -cfg.MaxYaw {
+		t.Errorf("goal = %f, want %f", got, -cfg.MaxYaw)
+	}
+}
+
+// settle steps until nothing moves, returning the final head and body yaw
+func settle(a *AutoTracker) (yaw, bodyYaw float64) {
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		var move bool
+		if yaw, bodyYaw, move = a.Step(now, 50*time.Millisecond); !move {
+			break
+		}
+	}
+	return yaw, bodyYaw
+}
+
+func deg(d float64) float64 { return d * math.Pi / 180 }
+
+func TestStep_BodyFollowsFarSpeaker(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	cfg.MaxBodyYaw = deg(90)
+	a := NewAutoTracker(cfg, nil, nil)
+
+	// Within reach of the head alone: the body stays put
+	a.Observe(speaking(deg(55)))
+	if yaw, body := settle(a); math.Abs(yaw-deg(55)) > 1e-9 || body != 0 {
+		t.Errorf("speaker at 55°: head %.1f°, body %.1f°, want head only", yaw*180/math.Pi, body*180/math.Pi)
+	}
+
+	// Far to the side: the body turns so the head is back at the threshold
+	a.Observe(speaking(deg(100)))
+	yaw, body := settle(a)
+	if math.Abs(yaw-cfg.BodyThreshold) > 1e-9 || math.Abs(body-deg(50)) > 1e-9 {
+		t.Errorf("speaker at 100°: head %.1f°, body %.1f°, want 50° and 50°", yaw*180/math.Pi, body*180/math.Pi)
+	}
+
+	// Small moves inside the hysteresis leave the body where it is
+	a.Observe(speaking(deg(85)))
+	if _, body := settle(a); math.Abs(body-deg(50)) > 1e-9 {
+		t.Errorf("speaker at 85°: body %.1f°, want it to stay at 50°", body*180/math.Pi)
+	}
+
+	// Back in front: the body re-centers
+	a.Observe(speaking(deg(20)))
+	if yaw, body := settle(a); math.Abs(yaw-deg(20)) > 1e-9 || body != 0 {
+		t.Errorf("speaker at 20°: head %.1f°, body %.1f°, want body centered", yaw*180/math.Pi, body*180/math.Pi)
+	}
+}
+
+func TestStep_HeadCounterRotatesWithBody(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	cfg.MaxBodyYaw = deg(90)
+	cfg.MaxVelocity = 0 // Heading jumps; only the body is rate limited
+	a := NewAutoTracker(cfg, nil, nil)
+	a.Observe(speaking(deg(-110)))
+
+	now := time.Now()
+	for i := 0; i < 40; i++ {
+		yaw, body, _ := a.Step(now, 50*time.Millisecond)
+		if math.Abs(yaw) > cfg.MaxYaw+1e-9 {
+			t.Fatalf("step %d: head yaw %.1f° beyond the head limit", i, yaw*180/math.Pi)
+		}
+		if body < -cfg.MaxBodyYaw-1e-9 {
+			t.Fatalf("step %d: body yaw %.1f° beyond the body limit", i, body*180/math.Pi)
+		}
+		// Once the body is far enough round, the head keeps the gaze on target
+		if heading := yaw + body; body <= deg(-50) && math.Abs(heading-deg(-110)) > 1e-9 {
+			t.Fatalf("step %d: heading %.1f° drifted off the speaker", i, heading*180/math.Pi)
+		}
+	}
+	if stats := a.GetStats(); math.Abs(stats.BodyYaw-deg(-60)) > 1e-9 {
+		t.Errorf("body yaw = %.1f°, want -60°", stats.BodyYaw*180/math.Pi)
+	}
+}
+
+func TestYield_PausesTracking(t *testing.T) {
+	a := NewAutoTracker(DefaultAutoTrackConfig(), nil, nil)
+	a.Observe(speaking(1.0))
+	a.Yield()
+
+	if _, _, move := a.Step(time.Now(), 50*time.Millisecond); move {
+		t.Error("tracking should pause after an external command")
+	}
+	if _, _, move := a.Step(time.Now().Add(3*time.Second), 50*time.Millisecond); !move {
+		t.Error("tracking should resume after the yield window")
+	}
+}
+
+func TestRun_DrivesMover(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	cfg.Rate = 5 * time.Millisecond
+	mover := &fakeMover{}
+	a := NewAutoTracker(cfg, mover, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results := make(chan doa.Result, 1)
+	results <- speaking(0.5)
+	a.Run(ctx, results)
+
+	mover.mu.Lock()
+	defer mover.mu.Unlock()
+	if len(mover.yaws) == 0 {
+		t.Fatal("expected head targets to be sent")
+	}
+	for i := 1; i < len(mover.yaws); i++ {
+		if step := mover.yaws[i] - mover.yaws[i-1]; step > cfg.MaxVelocity*cfg.Rate.Seconds()+1e-9 {
+			t.Fatalf("step %d exceeds velocity limit: %f", i, step)
+		}
+	}
+	if a.GetStats().CommandsSent == 0 {
+		t.Error("commands_sent should be counted")
+	}
+}
+
+func TestRun_RecordsLatency(t *testing.T) {
+	cfg := DefaultAutoTrackConfig()
+	cfg.Rate = 5 * time.Millisecond
+	a := NewAutoTracker(cfg, &fakeMover{}, nil)
+	rec := latency.NewRecorder(latency.DefaultConfig())
+	a.SetLatency(rec)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := speaking(0.5)
+	result.Timestamp = time.Now().Add(-20 * time.Millisecond)
+	results := make(chan doa.Result, 1)
+	results <- result
+	a.Run(ctx, results)
+
+	stages := map[string]latency.StageStats{}
+	for _, s := range rec.Breakdown().Stages {
+		stages[s.Stage] = s
+	}
+	if e2e := stages[latency.StageEndToEnd]; e2e.Count != 1 || e2e.LastMs < 20 {
+		t.Errorf("end_to_end = %+v, want one sample of at least 20ms", e2e)
+	}
+	if motor := stages[latency.StageMotor]; motor.Count < 2 {
+		t.Errorf("motor = %+v, want a sample per move", motor)
+	}
+}
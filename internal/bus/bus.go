@@ -0,0 +1,341 @@
+// Package bus provides an in-process publish/subscribe event bus with typed
+// topics, so producers and consumers don't need to know about each other
+package bus
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultBuffer is the subscription channel size used when none is given
+const DefaultBuffer = 16
+
+// Topic names a stream of events of type T
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic declares a topic; producers export it for consumers to subscribe
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the topic name
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Shared is implemented by events backed by a reference-counted buffer. The
+// bus retains one for every subscriber it delivers to, so the publisher can
+// release its own reference as soon as Publish returns; a subscriber reading
+// C directly must Release each event when done with it.
+type Shared interface {
+	Retain()
+	Release()
+}
+
+// subscriber is the type-erased side of a Subscription
+type subscriber interface {
+	deliver(v any) bool
+	send(v any) bool
+	close()
+}
+
+// topicState holds one topic's subscribers and counters
+type topicState struct {
+	subs      map[subscriber]struct{}
+	published uint64
+	dropped   uint64
+}
+
+// Bus routes published events to every subscriber of the topic. Publishing
+// never blocks: a subscriber whose buffer is full misses the event. Events
+// that must not be lost, such as commands, are delivered with Send instead.
+type Bus struct {
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	topics map[string]*topicState
+	closed bool
+
+	publishedTotal *prometheus.CounterVec
+	droppedTotal   *prometheus.CounterVec
+}
+
+// New creates an empty bus
+func New(logger *slog.Logger) *Bus {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Bus{
+		logger: logger,
+		topics: make(map[string]*topicState),
+		publishedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_bus_published_total",
+			Help: "Events published on the internal bus by topic",
+		}, []string{"topic"}),
+		droppedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_bus_dropped_total",
+			Help: "Events dropped for slow bus subscribers by topic",
+		}, []string{"topic"}),
+	}
+}
+
+// topicLocked returns the state for name, creating it. Caller holds mu.
+func (b *Bus) topicLocked(name string) *topicState {
+	ts, ok := b.topics[name]
+	if !ok {
+		ts = &topicState{subs: make(map[subscriber]struct{})}
+		b.topics[name] = ts
+	}
+	return ts
+}
+
+// Subscription receives events for one topic on C until Close
+type Subscription[T any] struct {
+	C <-chan T
+
+	bus   *Bus
+	topic string
+	ch    chan T
+	once  sync.Once
+
+	done   chan struct{} // Closed first on close, to release blocked senders
+	sendMu sync.RWMutex  // Held by senders so ch isn't closed under them
+}
+
+func (s *Subscription[T]) deliver(v any) bool {
+	shared, _ := v.(Shared)
+	if shared != nil {
+		shared.Retain()
+	}
+	select {
+	case s.ch <- v.(T):
+		return true
+	default:
+		if shared != nil {
+			shared.Release()
+		}
+		return false
+	}
+}
+
+// send waits for room in the buffer; it fails only once the subscription
+// closes
+func (s *Subscription[T]) send(v any) bool {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+	shared, _ := v.(Shared)
+	if shared != nil {
+		shared.Retain()
+	}
+	select {
+	case s.ch <- v.(T):
+		return true
+	case <-s.done:
+		if shared != nil {
+			shared.Release()
+		}
+		return false
+	}
+}
+
+func (s *Subscription[T]) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.sendMu.Lock()
+		close(s.ch)
+		s.sendMu.Unlock()
+	})
+}
+
+// Close unsubscribes and closes C
+func (s *Subscription[T]) Close() {
+	s.bus.mu.Lock()
+	if ts, ok := s.bus.topics[s.topic]; ok {
+		delete(ts.subs, s)
+	}
+	s.bus.mu.Unlock()
+	s.close()
+}
+
+// Subscribe registers a consumer for topic with the given channel buffer
+// (DefaultBuffer if <= 0). On a closed bus the channel is already closed.
+func Subscribe[T any](b *Bus, topic Topic[T], buffer int) *Subscription[T] {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan T, buffer)
+	sub := &Subscription[T]{C: ch, bus: b, topic: topic.name, ch: ch, done: make(chan struct{})}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.close()
+		return sub
+	}
+	b.topicLocked(topic.name).subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers v to every current subscriber of topic without blocking
+func Publish[T any](b *Bus, topic Topic[T], v T) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	ts := b.topicLocked(topic.name)
+	ts.published++
+	dropped := 0
+	for sub := range ts.subs {
+		if !sub.deliver(v) {
+			dropped++
+		}
+	}
+	ts.dropped += uint64(dropped)
+	b.mu.Unlock()
+
+	b.publishedTotal.WithLabelValues(topic.name).Inc()
+	if dropped > 0 {
+		b.droppedTotal.WithLabelValues(topic.name).Add(float64(dropped))
+		b.logger.Debug("bus subscriber too slow, event dropped", "topic", topic.name, "dropped", dropped)
+	}
+}
+
+// Send delivers v to every current subscriber of topic, waiting for room in
+// each buffer, so no subscriber misses it. A subscription that closes while
+// Send waits is skipped.
+func Send[T any](b *Bus, topic Topic[T], v T) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	ts := b.topicLocked(topic.name)
+	ts.published++
+	subs := make([]subscriber, 0, len(ts.subs))
+	for sub := range ts.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	b.publishedTotal.WithLabelValues(topic.name).Inc()
+	for _, sub := range subs {
+		sub.send(v)
+	}
+}
+
+// Sender is Publisher for events delivered with Send
+func Sender[T any](b *Bus, topic Topic[T]) func(T) {
+	return func(v T) { Send(b, topic, v) }
+}
+
+// Publisher returns a callback that publishes to topic, for wiring producers
+// that expose OnX(func(T)) hooks
+func Publisher[T any](b *Bus, topic Topic[T]) func(T) {
+	return func(v T) { Publish(b, topic, v) }
+}
+
+// Forward publishes everything received on ch until ch closes or ctx ends
+func Forward[T any](ctx context.Context, b *Bus, topic Topic[T], ch <-chan T) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case v, ok := <-ch:
+			if !ok {
+				return
+			}
+			Publish(b, topic, v)
+		}
+	}
+}
+
+// Handle subscribes to topic and calls fn for each event in a new goroutine
+// until ctx ends or the bus closes. Shared events are released after fn
+// returns.
+func Handle[T any](ctx context.Context, b *Bus, topic Topic[T], buffer int, fn func(T)) {
+	sub := Subscribe(b, topic, buffer)
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				fn(v)
+				if shared, ok := any(v).(Shared); ok {
+					shared.Release()
+				}
+			}
+		}
+	}()
+}
+
+// Close closes every subscription; later publishes are ignored
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, ts := range b.topics {
+		for sub := range ts.subs {
+			sub.close()
+		}
+		ts.subs = nil
+	}
+}
+
+// TopicStats contains per-topic counters
+type TopicStats struct {
+	Topic       string `json:"topic"`
+	Subscribers int    `json:"subscribers"`
+	Published   uint64 `json:"published"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// Stats contains bus statistics
+type Stats struct {
+	Topics []TopicStats `json:"topics"`
+}
+
+// GetStats returns per-topic statistics sorted by topic name
+func (b *Bus) GetStats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := Stats{Topics: make([]TopicStats, 0, len(b.topics))}
+	for name, ts := range b.topics {
+		stats.Topics = append(stats.Topics, TopicStats{
+			Topic:       name,
+			Subscribers: len(ts.subs),
+			Published:   ts.published,
+			Dropped:     ts.dropped,
+		})
+	}
+	sort.Slice(stats.Topics, func(i, j int) bool { return stats.Topics[i].Topic < stats.Topics[j].Topic })
+	return stats
+}
+
+// RegisterMetrics registers bus collectors
+func (b *Bus) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(b.publishedTotal, b.droppedTotal)
+}
@@ -0,0 +1,217 @@
+package bus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testNumbers = NewTopic[int]("test.numbers")
+	testWords   = NewTopic[string]("test.words")
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	a := Subscribe(b, testNumbers, 4)
+	c := Subscribe(b, testNumbers, 4)
+	words := Subscribe(b, testWords, 4)
+
+	Publish(b, testNumbers, 1)
+	Publish(b, testNumbers, 2)
+
+	for _, sub := range []*Subscription[int]{a, c} {
+		if got := <-sub.C; got != 1 {
+			t.Errorf("first event = %d, want 1", got)
+		}
+		if got := <-sub.C; got != 2 {
+			t.Errorf("second event = %d, want 2", got)
+		}
+	}
+
+	select {
+	case w := <-words.C:
+		t.Errorf("words subscriber got %q from another topic", w)
+	default:
+	}
+}
+
+func TestPublishDropsForSlowSubscriber(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	slow := Subscribe(b, testNumbers, 2)
+	fast := Subscribe(b, testNumbers, 10)
+
+	for i := 0; i < 10; i++ {
+		Publish(b, testNumbers, i)
+	}
+
+	if len(slow.C) != 2 || len(fast.C) != 10 {
+		t.Errorf("queued slow=%d fast=%d, want 2 and 10", len(slow.C), len(fast.C))
+	}
+
+	stats := b.GetStats()
+	if len(stats.Topics) != 1 {
+		t.Fatalf("topics = %+v", stats.Topics)
+	}
+	if ts := stats.Topics[0]; ts.Published != 10 || ts.Dropped != 8 || ts.Subscribers != 2 {
+		t.Errorf("stats = %+v", ts)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	sub := Subscribe(b, testNumbers, 1)
+	sub.Close()
+	sub.Close() // Idempotent
+
+	Publish(b, testNumbers, 1)
+	if _, ok := <-sub.C; ok {
+		t.Error("closed subscription received an event")
+	}
+	if n := b.GetStats().Topics[0].Subscribers; n != 0 {
+		t.Errorf("subscribers = %d after close", n)
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	b := New(nil)
+	sub := Subscribe(b, testNumbers, 1)
+
+	b.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription open after bus close")
+	}
+	sub.Close()
+
+	Publish(b, testNumbers, 1) // Ignored
+	late := Subscribe(b, testNumbers, 1)
+	if _, ok := <-late.C; ok {
+		t.Error("subscription on closed bus should be closed")
+	}
+}
+
+func TestSendWaitsForSlowSubscriber(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	sub := Subscribe(b, testNumbers, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			Send(b, testNumbers, i)
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		if got := <-sub.C; got != i {
+			t.Fatalf("event %d = %d", i, got)
+		}
+	}
+	<-done
+	if ts := b.GetStats().Topics[0]; ts.Published != 10 || ts.Dropped != 0 {
+		t.Errorf("stats = %+v, want 10 published and none dropped", ts)
+	}
+}
+
+func TestSendReleasedByClose(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	sub := Subscribe(b, testNumbers, 1)
+	Send(b, testNumbers, 1)
+	done := make(chan struct{})
+	go func() {
+		Send(b, testNumbers, 2) // Buffer full
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	sub.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Send still blocked after the subscription closed")
+	}
+}
+
+func TestHandleAndForward(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan string, 3)
+	Handle(ctx, b, testWords, 0, func(w string) { got <- w })
+
+	src := make(chan string, 3)
+	src <- "a"
+	src <- "b"
+	close(src)
+	go Forward(ctx, b, testWords, src)
+
+	Publisher(b, testWords)("c")
+
+	seen := make(map[string]bool)
+	timeout := time.After(time.Second)
+	for len(seen) < 3 {
+		select {
+		case w := <-got:
+			seen[w] = true
+		case <-timeout:
+			t.Fatalf("handled %v, want a, b and c", seen)
+		}
+	}
+}
+
+// refEvent counts references like a pooled camera frame
+type refEvent struct {
+	refs *atomic.Int32
+}
+
+func (e refEvent) Retain()  { e.refs.Add(1) }
+func (e refEvent) Release() { e.refs.Add(-1) }
+
+func TestPublishShared(t *testing.T) {
+	b := New(nil)
+	defer b.Close()
+	topic := NewTopic[refEvent]("test.shared")
+
+	sub := Subscribe(b, topic, 1)
+	ev := refEvent{refs: new(atomic.Int32)}
+	Publish(b, topic, ev)
+	Publish(b, topic, ev) // Dropped: the buffer is full
+
+	if got := ev.refs.Load(); got != 1 {
+		t.Fatalf("refs = %d after delivery and a drop, want 1", got)
+	}
+	(<-sub.C).Release()
+	if got := ev.refs.Load(); got != 0 {
+		t.Errorf("refs = %d after release, want 0", got)
+	}
+	sub.Close()
+
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Handle(ctx, b, topic, 1, func(refEvent) { close(done) })
+	time.Sleep(10 * time.Millisecond)
+	Publish(b, topic, ev)
+	<-done
+
+	deadline := time.Now().Add(time.Second)
+	for ev.refs.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("refs = %d, Handle should release after fn", ev.refs.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,514 @@
+// Package calibration fits the speech-energy distance model from samples
+// collected while someone speaks at a known distance from the robot.
+//
+// Speech energy falls off with the square of distance, so each sample gives
+// k = energy × distance², the energy a speaker would produce at 1 meter. The
+// median k across calibration points becomes the reference energy used by
+// doa.Reading.EstimatedDistance, and is persisted across restarts.
+//
+// The same runs measure how the mic array is mounted: with someone speaking
+// at a known bearing from the head, the mean difference between that bearing
+// and the measured one becomes the mounting offset (see doa.Mounting).
+package calibration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Config holds calibration configuration
+type Config struct {
+	File       string        // JSON file the fit is persisted to ("" = memory only)
+	Duration   time.Duration // Default sample window per calibration run
+	MinSamples int           // Speaking samples required for a run to count
+	Mounting   doa.Mounting  // Configured mounting, used until one is set or measured
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		File:       "/var/lib/go-eva/calibration.json",
+		Duration:   10 * time.Second,
+		MinSamples: 20,
+	}
+}
+
+// Distance limits accepted for a calibration run (meters)
+const (
+	MinDistance = 0.3
+	MaxDistance = 5.0
+)
+
+// Kind is what a calibration run measures
+type Kind string
+
+const (
+	KindDistance Kind = "distance"
+	KindMounting Kind = "mounting"
+)
+
+// minMountingConsistency is the minimum mean resultant length of the
+// bearing errors in a mounting run (1 = all identical, 0 = uniform)
+const minMountingConsistency = 0.8
+
+// State is the calibration run state
+type State string
+
+const (
+	StateIdle       State = "idle"
+	StateCollecting State = "collecting"
+	StateDone       State = "done"
+	StateFailed     State = "failed"
+)
+
+// ErrBusy is returned when a run is already collecting
+var ErrBusy = errors.New("calibration already in progress")
+
+// Point is the result of one calibration run
+type Point struct {
+	Distance float64   `json:"distance_m"`
+	K        float64   `json:"k"` // Median energy × distance²
+	Samples  int       `json:"samples"`
+	At       time.Time `json:"at"`
+}
+
+// MountingFit is a mounting set through the API or measured from a
+// speaker at a known bearing
+type MountingFit struct {
+	doa.Mounting
+	Bearing float64   `json:"bearing"`           // Speaker bearing it was measured against (radians)
+	Samples int       `json:"samples,omitempty"` // 0 when set directly
+	At      time.Time `json:"at"`
+}
+
+// Calibration is the persisted fit
+type Calibration struct {
+	ReferenceEnergy float64      `json:"reference_energy"`
+	Points          []Point      `json:"points"`
+	Mounting        *MountingFit `json:"mounting,omitempty"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// Status describes the current run and the active fit
+type Status struct {
+	State           State        `json:"state"`
+	Kind            Kind         `json:"kind,omitempty"`
+	Distance        float64      `json:"distance_m,omitempty"`
+	Bearing         float64      `json:"bearing"`
+	Samples         int          `json:"samples"`
+	RemainingMs     int64        `json:"remaining_ms,omitempty"`
+	Error           string       `json:"error,omitempty"`
+	ReferenceEnergy float64      `json:"reference_energy"`
+	Default         bool         `json:"default"`
+	Points          []Point      `json:"points"`
+	Mounting        doa.Mounting `json:"mounting"`               // Active mounting
+	MountingFit     *MountingFit `json:"mounting_fit,omitempty"` // nil = configured mounting
+}
+
+// Calibrator collects energy samples and maintains the reference energy
+type Calibrator struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	cal      Calibration
+	state    State
+	kind     Kind
+	distance float64
+	bearing  float64
+	deadline time.Time
+	samples  []float64
+	lastErr  string
+}
+
+// New creates a calibrator, loading and applying any persisted fit
+func New(cfg Config, logger *slog.Logger) (*Calibrator, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = DefaultConfig().Duration
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = DefaultConfig().MinSamples
+	}
+
+	c := &Calibrator{
+		cfg:    cfg,
+		logger: logger,
+		state:  StateIdle,
+	}
+
+	if cfg.File != "" {
+		data, err := os.ReadFile(cfg.File)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("read calibration: %w", err)
+		default:
+			if err := json.Unmarshal(data, &c.cal); err != nil {
+				return nil, fmt.Errorf("parse calibration %s: %w", cfg.File, err)
+			}
+			doa.SetReferenceEnergy(c.cal.ReferenceEnergy)
+			logger.Info("loaded distance calibration",
+				"reference_energy", c.cal.ReferenceEnergy,
+				"points", len(c.cal.Points),
+			)
+		}
+	}
+
+	if c.cal.Mounting != nil {
+		doa.SetMounting(c.cal.Mounting.Mounting)
+		logger.Info("loaded mic array mounting",
+			"offset_deg", c.cal.Mounting.Offset*180/math.Pi,
+			"mirror", c.cal.Mounting.Mirror,
+		)
+	} else {
+		doa.SetMounting(cfg.Mounting)
+	}
+
+	return c, nil
+}
+
+// Start begins collecting samples for a speaker at distance meters.
+// A zero duration uses the configured default.
+func (c *Calibrator) Start(distance float64, duration time.Duration) error {
+	if distance < MinDistance || distance > MaxDistance {
+		return fmt.Errorf("distance must be between %.1f and %.1f meters, got %.2f", MinDistance, MaxDistance, distance)
+	}
+	if duration <= 0 {
+		duration = c.cfg.Duration
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateCollecting {
+		return ErrBusy
+	}
+
+	c.startLocked(KindDistance, duration)
+	c.distance = distance
+
+	c.logger.Info("distance calibration started", "distance_m", distance, "duration", duration)
+	return nil
+}
+
+// StartMounting begins measuring the mounting offset from a speaker at
+// bearing radians from the head (0 = straight ahead, + = left). A zero
+// duration uses the configured default.
+func (c *Calibrator) StartMounting(bearing float64, duration time.Duration) error {
+	if math.IsNaN(bearing) || bearing < -math.Pi || bearing > math.Pi {
+		return fmt.Errorf("bearing must be between -180 and 180 degrees, got %.1f", bearing*180/math.Pi)
+	}
+	if duration <= 0 {
+		duration = c.cfg.Duration
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateCollecting {
+		return ErrBusy
+	}
+
+	c.startLocked(KindMounting, duration)
+	c.bearing = bearing
+
+	c.logger.Info("mounting calibration started", "bearing_deg", bearing*180/math.Pi, "duration", duration)
+	return nil
+}
+
+// startLocked resets the run state for a new run
+func (c *Calibrator) startLocked(kind Kind, duration time.Duration) {
+	c.state = StateCollecting
+	c.kind = kind
+	c.deadline = time.Now().Add(duration)
+	c.samples = c.samples[:0]
+	c.lastErr = ""
+}
+
+// SetMounting replaces the mounting without measuring it
+func (c *Calibrator) SetMounting(m doa.Mounting) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateCollecting && c.kind == KindMounting {
+		return ErrBusy
+	}
+	fit := &MountingFit{Mounting: m, At: time.Now()}
+	return c.applyMountingLocked(fit)
+}
+
+// ResetMounting discards the set or measured mounting and restores the
+// configured one
+func (c *Calibrator) ResetMounting() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateCollecting && c.kind == KindMounting {
+		return ErrBusy
+	}
+	return c.applyMountingLocked(nil)
+}
+
+// applyMountingLocked persists fit (nil = configured) and makes it active
+func (c *Calibrator) applyMountingLocked(fit *MountingFit) error {
+	next := c.cal
+	next.Mounting = fit
+	next.UpdatedAt = time.Now()
+	if err := c.save(next); err != nil {
+		return err
+	}
+
+	c.cal = next
+	m := c.cfg.Mounting
+	if fit != nil {
+		m = fit.Mounting
+	}
+	doa.SetMounting(m)
+
+	c.logger.Info("mic array mounting updated",
+		"offset_deg", doa.CurrentMounting().Offset*180/math.Pi,
+		"mirror", m.Mirror,
+		"measured", fit != nil && fit.Samples > 0,
+	)
+	return nil
+}
+
+// Feed records a tracker result while a run is collecting
+func (c *Calibrator) Feed(result doa.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != StateCollecting {
+		return
+	}
+	if time.Now().After(c.deadline) {
+		c.finishLocked()
+		return
+	}
+	switch {
+	case c.kind == KindMounting && result.Speaking:
+		// Bearing error in the current mounting
+		c.samples = append(c.samples, doa.NormalizeAngle(c.bearing-result.Angle))
+	case c.kind == KindDistance && result.Speaking && result.TotalEnergy > 0:
+		c.samples = append(c.samples, result.TotalEnergy)
+	}
+}
+
+// Run feeds results until the channel closes or ctx is cancelled, and ends
+// runs on time even when no results arrive (blocking)
+func (c *Calibrator) Run(ctx context.Context, results <-chan doa.Result) {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			c.Feed(result)
+		case <-ticker.C:
+			c.mu.Lock()
+			if c.state == StateCollecting && time.Now().After(c.deadline) {
+				c.finishLocked()
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// finishLocked ends the run and applies its result
+func (c *Calibrator) finishLocked() {
+	if len(c.samples) < c.cfg.MinSamples {
+		c.state = StateFailed
+		c.lastErr = fmt.Sprintf("only %d speaking samples, need %d", len(c.samples), c.cfg.MinSamples)
+		c.logger.Warn("calibration failed", "kind", c.kind, "error", c.lastErr)
+		return
+	}
+	if c.kind == KindMounting {
+		c.finishMountingLocked()
+		return
+	}
+
+	point := Point{
+		Distance: c.distance,
+		K:        median(c.samples) * c.distance * c.distance,
+		Samples:  len(c.samples),
+		At:       time.Now(),
+	}
+
+	// A repeat run at the same distance replaces the earlier one
+	points := make([]Point, 0, len(c.cal.Points)+1)
+	for _, p := range c.cal.Points {
+		if p.Distance != point.Distance {
+			points = append(points, p)
+		}
+	}
+	points = append(points, point)
+	sort.Slice(points, func(i, j int) bool { return points[i].Distance < points[j].Distance })
+
+	ks := make([]float64, len(points))
+	for i, p := range points {
+		ks[i] = p.K
+	}
+
+	next := Calibration{
+		ReferenceEnergy: median(ks),
+		Points:          points,
+		Mounting:        c.cal.Mounting,
+		UpdatedAt:       point.At,
+	}
+
+	if err := c.save(next); err != nil {
+		c.state = StateFailed
+		c.lastErr = err.Error()
+		c.logger.Error("failed to persist distance calibration", "error", err)
+		return
+	}
+
+	c.cal = next
+	c.state = StateDone
+	doa.SetReferenceEnergy(next.ReferenceEnergy)
+
+	c.logger.Info("distance calibration updated",
+		"distance_m", point.Distance,
+		"samples", point.Samples,
+		"k", point.K,
+		"reference_energy", next.ReferenceEnergy,
+	)
+}
+
+// finishMountingLocked turns the collected bearing errors into a new offset
+func (c *Calibrator) finishMountingLocked() {
+	// Circular mean, so errors either side of ±180° don't cancel out
+	var sin, cos float64
+	for _, e := range c.samples {
+		sin += math.Sin(e)
+		cos += math.Cos(e)
+	}
+	n := float64(len(c.samples))
+	if consistency := math.Hypot(sin, cos) / n; consistency < minMountingConsistency {
+		c.state = StateFailed
+		c.lastErr = fmt.Sprintf("bearings too scattered (consistency %.2f, need %.2f)", consistency, minMountingConsistency)
+		c.logger.Warn("mounting calibration failed", "error", c.lastErr)
+		return
+	}
+
+	m := doa.CurrentMounting()
+	m.Offset = doa.NormalizeAngle(m.Offset + math.Atan2(sin, cos))
+	fit := &MountingFit{Mounting: m, Bearing: c.bearing, Samples: len(c.samples), At: time.Now()}
+	if err := c.applyMountingLocked(fit); err != nil {
+		c.state = StateFailed
+		c.lastErr = err.Error()
+		c.logger.Error("failed to persist mounting calibration", "error", err)
+		return
+	}
+	c.state = StateDone
+}
+
+// save writes the calibration atomically
+func (c *Calibrator) save(cal Calibration) error {
+	if c.cfg.File == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode calibration: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cfg.File), 0755); err != nil {
+		return fmt.Errorf("create calibration dir: %w", err)
+	}
+
+	tmp := c.cfg.File + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write calibration: %w", err)
+	}
+	if err := os.Rename(tmp, c.cfg.File); err != nil {
+		return fmt.Errorf("write calibration: %w", err)
+	}
+	return nil
+}
+
+// Reset discards all points and the mounting, restoring the default
+// reference energy and the configured mounting
+func (c *Calibrator) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cfg.File != "" {
+		if err := os.Remove(c.cfg.File); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove calibration: %w", err)
+		}
+	}
+
+	c.cal = Calibration{}
+	c.state = StateIdle
+	c.samples = c.samples[:0]
+	c.lastErr = ""
+	doa.SetReferenceEnergy(0)
+	doa.SetMounting(c.cfg.Mounting)
+
+	c.logger.Info("calibration reset to defaults")
+	return nil
+}
+
+// Status returns the current run state and active fit
+func (c *Calibrator) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := Status{
+		State:           c.state,
+		Samples:         len(c.samples),
+		Error:           c.lastErr,
+		ReferenceEnergy: doa.ReferenceEnergy(),
+		Default:         len(c.cal.Points) == 0 && c.cal.ReferenceEnergy == 0,
+		Points:          append([]Point{}, c.cal.Points...),
+		Mounting:        doa.CurrentMounting(),
+		MountingFit:     c.cal.Mounting,
+	}
+	if c.state != StateIdle {
+		status.Kind = c.kind
+		if c.kind == KindMounting {
+			status.Bearing = c.bearing
+		} else {
+			status.Distance = c.distance
+		}
+	}
+	if c.state == StateCollecting {
+		if remaining := time.Until(c.deadline); remaining > 0 {
+			status.RemainingMs = remaining.Milliseconds()
+		}
+	}
+	return status
+}
+
+// median returns the median of values (which it sorts)
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
@@ -0,0 +1,195 @@
+package calibration
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func speaking(energy float64) doa.Result {
+	return doa.Result{Reading: doa.Reading{Speaking: true, TotalEnergy: energy}}
+}
+
+func TestCalibrator_FitAndPersist(t *testing.T) {
+	defer doa.SetReferenceEnergy(0)
+
+	cfg := DefaultConfig()
+	cfg.File = filepath.Join(t.TempDir(), "calibration.json")
+	cfg.MinSamples = 3
+
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Speaker with a reference energy of 1e6 at 1m: E = 1e6 / d²
+	for _, d := range []float64{0.5, 1, 2} {
+		if err := c.Start(d, 20*time.Millisecond); err != nil {
+			t.Fatalf("Start(%v) error = %v", d, err)
+		}
+		if err := c.Start(d, 0); err != ErrBusy {
+			t.Errorf("second Start() error = %v, want ErrBusy", err)
+		}
+		for i := 0; i < 5; i++ {
+			c.Feed(speaking(1e6 / (d * d)))
+		}
+		c.Feed(doa.Result{}) // Silence is ignored
+		time.Sleep(30 * time.Millisecond)
+		c.Feed(speaking(1)) // Past the deadline: finishes the run
+
+		if s := c.Status(); s.State != StateDone || s.Samples != 5 {
+			t.Fatalf("after %vm: status = %+v", d, s)
+		}
+	}
+
+	if got := doa.ReferenceEnergy(); math.Abs(got-1e6) > 1 {
+		t.Errorf("ReferenceEnergy() = %v, want 1e6", got)
+	}
+	reading := doa.Reading{Speaking: true, TotalEnergy: 1e6 / 4}
+	if got := reading.EstimatedDistance(); math.Abs(got-2) > 1e-6 {
+		t.Errorf("EstimatedDistance() = %v, want 2", got)
+	}
+
+	// A fresh calibrator picks up the persisted fit
+	doa.SetReferenceEnergy(0)
+	reloaded, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() reload error = %v", err)
+	}
+	if got := doa.ReferenceEnergy(); math.Abs(got-1e6) > 1 {
+		t.Errorf("reloaded ReferenceEnergy() = %v, want 1e6", got)
+	}
+	if n := len(reloaded.Status().Points); n != 3 {
+		t.Errorf("reloaded %d points, want 3", n)
+	}
+
+	if err := reloaded.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if doa.ReferenceEnergy() != doa.DefaultReferenceEnergy || !reloaded.Status().Default {
+		t.Error("Reset() did not restore the default")
+	}
+}
+
+func TestCalibrator_TooFewSamples(t *testing.T) {
+	defer doa.SetReferenceEnergy(0)
+
+	cfg := DefaultConfig()
+	cfg.File = ""
+	cfg.MinSamples = 10
+	c, _ := New(cfg, nil)
+
+	if err := c.Start(1, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	c.Feed(speaking(5e6))
+	time.Sleep(20 * time.Millisecond)
+	c.Feed(speaking(5e6))
+
+	s := c.Status()
+	if s.State != StateFailed || s.Error == "" {
+		t.Errorf("status = %+v, want failed", s)
+	}
+	if doa.ReferenceEnergy() != doa.DefaultReferenceEnergy {
+		t.Error("failed run changed the reference energy")
+	}
+}
+
+func TestCalibrator_StartValidation(t *testing.T) {
+	c, _ := New(Config{}, nil)
+	for _, d := range []float64{0, 0.1, 10} {
+		if err := c.Start(d, 0); err == nil {
+			t.Errorf("Start(%v) expected error", d)
+		}
+	}
+}
+
+func TestCalibrator_Mounting(t *testing.T) {
+	defer doa.SetMounting(doa.Mounting{})
+
+	cfg := DefaultConfig()
+	cfg.File = filepath.Join(t.TempDir(), "calibration.json")
+	cfg.MinSamples = 3
+	cfg.Mounting = doa.Mounting{Mirror: true}
+
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if m := doa.CurrentMounting(); !m.Mirror || m.Offset != 0 {
+		t.Fatalf("configured mounting not applied: %+v", m)
+	}
+
+	// Speaker 10° left of the head, measured 5° right: the array is
+	// rotated 15° left
+	bearing := 10 * math.Pi / 180
+	if err := c.StartMounting(bearing, 20*time.Millisecond); err != nil {
+		t.Fatalf("StartMounting() error = %v", err)
+	}
+	for _, deg := range []float64{-4, -5, -6} {
+		c.Feed(doa.Result{Reading: doa.Reading{Angle: deg * math.Pi / 180, Speaking: true}})
+	}
+	time.Sleep(30 * time.Millisecond)
+	c.Feed(doa.Result{})
+
+	s := c.Status()
+	if s.State != StateDone || s.Kind != KindMounting || s.MountingFit == nil || s.MountingFit.Samples != 3 {
+		t.Fatalf("status = %+v", s)
+	}
+	if m := doa.CurrentMounting(); math.Abs(m.Offset-15*math.Pi/180) > 1e-6 || !m.Mirror {
+		t.Errorf("measured mounting = %+v, want 15° offset, mirrored", m)
+	}
+
+	// The measured mounting survives a restart
+	doa.SetMounting(doa.Mounting{})
+	if _, err := New(cfg, nil); err != nil {
+		t.Fatalf("New() reload error = %v", err)
+	}
+	if m := doa.CurrentMounting(); math.Abs(m.Offset-15*math.Pi/180) > 1e-6 {
+		t.Errorf("reloaded mounting = %+v", m)
+	}
+
+	if err := c.SetMounting(doa.Mounting{Offset: -0.2}); err != nil {
+		t.Fatalf("SetMounting() error = %v", err)
+	}
+	if m := doa.CurrentMounting(); m.Offset != -0.2 || m.Mirror {
+		t.Errorf("set mounting = %+v", m)
+	}
+	if err := c.ResetMounting(); err != nil {
+		t.Fatalf("ResetMounting() error = %v", err)
+	}
+	if m := doa.CurrentMounting(); m != cfg.Mounting || c.Status().MountingFit != nil {
+		t.Errorf("ResetMounting() left %+v", m)
+	}
+}
+
+func TestCalibrator_MountingScattered(t *testing.T) {
+	defer doa.SetMounting(doa.Mounting{})
+
+	cfg := DefaultConfig()
+	cfg.File = ""
+	cfg.MinSamples = 3
+	c, _ := New(cfg, nil)
+
+	if err := c.StartMounting(0, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	for _, angle := range []float64{0, 2, -2, math.Pi} {
+		c.Feed(doa.Result{Reading: doa.Reading{Angle: angle, Speaking: true}})
+	}
+	time.Sleep(20 * time.Millisecond)
+	c.Feed(doa.Result{})
+
+	if s := c.Status(); s.State != StateFailed || s.Error == "" {
+		t.Errorf("status = %+v, want failed", s)
+	}
+	if m := doa.CurrentMounting(); m != (doa.Mounting{}) {
+		t.Errorf("failed run changed the mounting: %+v", m)
+	}
+	if err := c.StartMounting(4, 0); err == nil {
+		t.Error("StartMounting() with an out-of-range bearing should fail")
+	}
+}
@@ -0,0 +1,231 @@
+package camera
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Each adaptation level scales the framerate by adaptFramerateFactor and
+// lowers JPEG quality by adaptQualityStep
+const (
+	adaptFramerateFactor = 0.7
+	adaptQualityStep     = 10
+)
+
+// AdaptConfig configures uplink-driven capture adaptation
+type AdaptConfig struct {
+	Interval     time.Duration // How often the uplink is checked (at most one step per check)
+	HighDelay    time.Duration // Step down when the send queue delay exceeds this
+	LowDelay     time.Duration // Step back up once the delay stays below this...
+	RecoverAfter time.Duration // ...for this long
+	MaxQueue     int           // Also step down when more messages than this wait to be sent (0 = ignore)
+	MinFramerate int           // Floor for the adapted framerate
+	MinQuality   int           // Floor for the adapted JPEG quality
+}
+
+// DefaultAdaptConfig returns sensible defaults
+func DefaultAdaptConfig() AdaptConfig {
+	return AdaptConfig{
+		Interval:     2 * time.Second,
+		HighDelay:    500 * time.Millisecond,
+		LowDelay:     100 * time.Millisecond,
+		RecoverAfter: 10 * time.Second,
+		MaxQueue:     32,
+		MinFramerate: 2,
+		MinQuality:   40,
+	}
+}
+
+// UplinkLoad is a snapshot of how backed up the cloud uplink is
+type UplinkLoad struct {
+	Connected   bool
+	QueueLength int           // Messages waiting to be sent
+	QueueDelay  time.Duration // How long messages wait before they are written
+}
+
+// Tunable is capture whose settings can change while running; *Client
+// implements it
+type Tunable interface {
+	Settings() Settings
+	Reconfigure(preset string, s Settings) (Settings, error)
+}
+
+// Adapter lowers the capture framerate and JPEG quality while the uplink is
+// backed up, so frames don't delay DOA and other small messages, and raises
+// them again once it recovers. Settings changed by anyone else (config, the
+// cloud) become the new ceiling.
+type Adapter struct {
+	cfg    AdaptConfig
+	target Tunable
+	uplink func() UplinkLoad
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	base      Settings // Settings adaptation started from
+	applied   Settings // Settings the adapter last applied
+	level     int      // Steps below base (0 = not adapting)
+	calmSince time.Time
+
+	// Stats
+	stepsDown atomic.Uint64
+	stepsUp   atomic.Uint64
+	errors    atomic.Uint64
+}
+
+// NewAdapter creates an adapter that tunes target from uplink samples
+func NewAdapter(cfg AdaptConfig, target Tunable, uplink func() UplinkLoad, logger *slog.Logger) *Adapter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultAdaptConfig().Interval
+	}
+	current := target.Settings()
+	return &Adapter{
+		cfg:     cfg,
+		target:  target,
+		uplink:  uplink,
+		logger:  logger,
+		base:    current,
+		applied: current,
+	}
+}
+
+// Run checks the uplink every Interval until ctx is cancelled
+func (a *Adapter) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.check(now)
+		}
+	}
+}
+
+// check samples the uplink and steps capture down or up at most once
+func (a *Adapter) check(now time.Time) {
+	load := a.uplink()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if current := a.target.Settings(); current != a.applied {
+		if a.level > 0 {
+			a.logger.Info("camera settings changed, adaptation reset", "framerate", current.Framerate, "quality", current.Quality)
+		}
+		a.base, a.applied, a.level = current, current, 0
+		a.calmSince = time.Time{}
+	}
+	if !load.Connected {
+		a.calmSince = time.Time{}
+		return
+	}
+
+	congested := load.QueueDelay > a.cfg.HighDelay || (a.cfg.MaxQueue > 0 && load.QueueLength > a.cfg.MaxQueue)
+	switch {
+	case congested:
+		a.calmSince = time.Time{}
+		if next := a.settingsAt(a.level + 1); next != a.applied {
+			a.apply(a.level+1, next, load)
+		}
+	case load.QueueDelay < a.cfg.LowDelay && a.level > 0:
+		if a.calmSince.IsZero() {
+			a.calmSince = now
+		} else if now.Sub(a.calmSince) >= a.cfg.RecoverAfter {
+			a.apply(a.level-1, a.settingsAt(a.level-1), load)
+			a.calmSince = now
+		}
+	default:
+		a.calmSince = time.Time{}
+	}
+}
+
+// settingsAt returns the capture settings for an adaptation level; floors
+// never raise a setting above base
+func (a *Adapter) settingsAt(level int) Settings {
+	s := a.base
+	if level <= 0 {
+		return s
+	}
+	minFPS := min(a.cfg.MinFramerate, s.Framerate)
+	minQuality := min(a.cfg.MinQuality, s.Quality)
+	s.Framerate = max(minFPS, int(math.Round(float64(s.Framerate)*math.Pow(adaptFramerateFactor, float64(level)))))
+	s.Quality = max(minQuality, s.Quality-adaptQualityStep*level)
+	return s
+}
+
+// apply reconfigures the capture; callers hold mu
+func (a *Adapter) apply(level int, next Settings, load UplinkLoad) {
+	applied, err := a.target.Reconfigure("", next)
+	if err != nil {
+		a.errors.Add(1)
+		a.logger.Warn("camera adaptation failed", "error", err)
+		return
+	}
+
+	if level > a.level {
+		a.stepsDown.Add(1)
+	} else {
+		a.stepsUp.Add(1)
+	}
+	a.logger.Info("camera adapted to uplink",
+		"level", level,
+		"framerate", applied.Framerate,
+		"quality", applied.Quality,
+		"queue_delay", load.QueueDelay,
+		"queue_length", load.QueueLength,
+	)
+	a.level, a.applied = level, applied
+}
+
+// AdaptStats contains adaptation statistics
+type AdaptStats struct {
+	Level     int      `json:"level"` // Steps below the configured settings (0 = full quality)
+	Base      Settings `json:"base"`
+	Current   Settings `json:"current"`
+	StepsDown uint64   `json:"steps_down"`
+	StepsUp   uint64   `json:"steps_up"`
+	Errors    uint64   `json:"errors"`
+}
+
+// GetStats returns adaptation statistics
+func (a *Adapter) GetStats() AdaptStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AdaptStats{
+		Level:     a.level,
+		Base:      a.base,
+		Current:   a.applied,
+		StepsDown: a.stepsDown.Load(),
+		StepsUp:   a.stepsUp.Load(),
+		Errors:    a.errors.Load(),
+	}
+}
+
+// RegisterMetrics registers adaptation collectors
+func (a *Adapter) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_camera_adapt_level",
+			Help: "Steps the camera framerate and quality are below their configured settings",
+		}, func() float64 { return float64(a.GetStats().Level) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_adapt_steps_down_total",
+			Help: "Times the camera was stepped down because the cloud uplink backed up",
+		}, func() float64 { return float64(a.stepsDown.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_adapt_steps_up_total",
+			Help: "Times the camera was stepped back up after the cloud uplink recovered",
+		}, func() float64 { return float64(a.stepsUp.Load()) }),
+	)
+}
@@ -0,0 +1,95 @@
+package camera
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeCapture records reconfigurations
+type fakeCapture struct {
+	settings Settings
+}
+
+func (f *fakeCapture) Settings() Settings { return f.settings }
+
+func (f *fakeCapture) Reconfigure(_ string, s Settings) (Settings, error) {
+	f.settings = s
+	return s, nil
+}
+
+func TestAdapter_StepsDownAndRecovers(t *testing.T) {
+	capture := &fakeCapture{settings: Settings{Framerate: 10, Width: 640, Height: 480, Quality: 80}}
+	load := UplinkLoad{Connected: true, QueueDelay: time.Second}
+	cfg := DefaultAdaptConfig()
+	a := NewAdapter(cfg, capture, func() UplinkLoad { return load }, nil)
+
+	now := time.Now()
+	a.check(now)
+	if got := capture.settings; got.Framerate != 7 || got.Quality != 70 || got.Width != 640 {
+		t.Fatalf("after one step = %+v", got)
+	}
+
+	// Keeps stepping down to the floors, then holds
+	for range 10 {
+		now = now.Add(cfg.Interval)
+		a.check(now)
+	}
+	if got := capture.settings; got.Framerate != cfg.MinFramerate || got.Quality != cfg.MinQuality {
+		t.Fatalf("at the floor = %+v", got)
+	}
+	level := a.GetStats().Level
+
+	// Recovery needs a calm uplink for RecoverAfter
+	load.QueueDelay = 10 * time.Millisecond
+	a.check(now)
+	now = now.Add(cfg.RecoverAfter / 2)
+	a.check(now)
+	if a.GetStats().Level != level {
+		t.Fatal("stepped up before RecoverAfter")
+	}
+	for range 2 * level {
+		now = now.Add(cfg.RecoverAfter)
+		a.check(now)
+	}
+	if got := capture.settings; got.Framerate != 10 || got.Quality != 80 {
+		t.Errorf("after recovery = %+v", got)
+	}
+	if s := a.GetStats(); s.Level != 0 || s.StepsDown != uint64(level) || s.StepsUp != uint64(level) {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestAdapter_QueueDepthAndDisconnect(t *testing.T) {
+	capture := &fakeCapture{settings: Settings{Framerate: 10, Quality: 80}}
+	load := UplinkLoad{Connected: false, QueueLength: 100}
+	a := NewAdapter(DefaultAdaptConfig(), capture, func() UplinkLoad { return load }, nil)
+
+	a.check(time.Now())
+	if capture.settings.Framerate != 10 {
+		t.Error("adapted while disconnected")
+	}
+
+	load.Connected = true
+	a.check(time.Now())
+	if capture.settings.Framerate >= 10 {
+		t.Error("a deep send queue should step down")
+	}
+}
+
+func TestAdapter_ExternalChangeResets(t *testing.T) {
+	capture := &fakeCapture{settings: Settings{Framerate: 10, Quality: 80}}
+	load := UplinkLoad{Connected: true, QueueDelay: time.Second}
+	a := NewAdapter(DefaultAdaptConfig(), capture, func() UplinkLoad { return load }, nil)
+
+	now := time.Now()
+	a.check(now)
+	a.check(now.Add(time.Second))
+
+	// The cloud asks for new settings mid-adaptation: they become the base
+	capture.settings = Settings{Framerate: 15, Quality: 90}
+	load.QueueDelay = 200 * time.Millisecond // Neither congested nor calm
+	a.check(now.Add(2 * time.Second))
+	if s := a.GetStats(); s.Level != 0 || s.Base.Framerate != 15 || capture.settings.Framerate != 15 {
+		t.Errorf("after external change: stats %+v, settings %+v", s, capture.settings)
+	}
+}
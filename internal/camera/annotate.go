@@ -0,0 +1,185 @@
+package camera
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Bearing is the speaker direction drawn onto annotated frames
+type Bearing struct {
+	Angle      float64 // Radians in Eva's frame (0 = front, + = left)
+	Confidence float64 // 0-1
+	Speaking   bool
+}
+
+// AnnotateConfig configures the DOA overlay
+type AnnotateConfig struct {
+	FOV float64 // Horizontal camera field of view (radians); bearings inside it are also marked in the image
+}
+
+// DefaultAnnotateConfig returns sensible defaults
+func DefaultAnnotateConfig() AnnotateConfig {
+	return AnnotateConfig{
+		FOV: 80 * math.Pi / 180,
+	}
+}
+
+// Overlay colors
+var (
+	annotateSpeaking = color.RGBA{R: 40, G: 220, B: 90, A: 255}
+	annotateSilent   = color.RGBA{R: 160, G: 160, B: 160, A: 255}
+	annotateDial     = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	annotateShade    = color.RGBA{A: 140}
+)
+
+// Annotator composites the current speaker bearing onto frames: a dial in
+// the top-left corner with an arrow toward the speaker (up = front, length =
+// confidence), a confidence bar under it, and a vertical marker where the
+// bearing falls inside the camera's view. Green means voice activity.
+type Annotator struct {
+	cfg     AnnotateConfig
+	bearing func() Bearing
+
+	// Stats
+	annotated atomic.Uint64
+	errors    atomic.Uint64
+	totalNs   atomic.Int64
+}
+
+// NewAnnotator creates an annotator that reads the bearing from bearing
+func NewAnnotator(cfg AnnotateConfig, bearing func() Bearing) *Annotator {
+	if cfg.FOV <= 0 || cfg.FOV >= math.Pi {
+		cfg.FOV = DefaultAnnotateConfig().FOV
+	}
+	return &Annotator{cfg: cfg, bearing: bearing}
+}
+
+// Annotate returns a copy of frame with the overlay drawn, re-encoded at
+// quality into its own pooled buffer; frame keeps its reference. On error
+// frame itself is returned.
+func (a *Annotator) Annotate(frame Frame, quality int) (Frame, error) {
+	start := time.Now()
+
+	src, err := jpeg.Decode(bytes.NewReader(frame.Data))
+	if err != nil {
+		a.errors.Add(1)
+		return frame, fmt.Errorf("decode frame: %w", err)
+	}
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	a.draw(img, a.bearing())
+
+	annotated, err := encodeFrame(img, quality)
+	if err != nil {
+		a.errors.Add(1)
+		return frame, fmt.Errorf("encode frame: %w", err)
+	}
+	annotated.Width, annotated.Height = frame.Width, frame.Height
+	annotated.Timestamp, annotated.FrameID = frame.Timestamp, frame.FrameID
+
+	a.annotated.Add(1)
+	a.totalNs.Add(int64(time.Since(start)))
+	return annotated, nil
+}
+
+// draw renders the overlay for b onto img
+func (a *Annotator) draw(img *image.RGBA, b Bearing) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	fg := annotateSilent
+	if b.Speaking {
+		fg = annotateSpeaking
+	}
+	conf := math.Max(0, math.Min(1, b.Confidence))
+
+	// Bearing marker inside the field of view: positive angles are left
+	if half := a.cfg.FOV / 2; math.Abs(b.Angle) < half {
+		x := bounds.Min.X + int(float64(w)/2*(1-math.Tan(b.Angle)/math.Tan(half)))
+		fillRect(img, image.Rect(x-1, bounds.Min.Y, x+2, bounds.Max.Y), fg)
+	}
+
+	// Dial with arrow, on a shaded square so it reads over any scene
+	r := max(min(w, h)/8, 12)
+	cx, cy := bounds.Min.X+r+8, bounds.Min.Y+r+8
+	blend(img, image.Rect(cx-r-4, cy-r-4, cx+r+5, cy+r+14), annotateShade)
+	drawCircle(img, cx, cy, r, annotateDial)
+
+	length := float64(r) * (0.3 + 0.7*conf)
+	tx := cx + int(math.Round(-math.Sin(b.Angle)*length))
+	ty := cy + int(math.Round(-math.Cos(b.Angle)*length))
+	drawLine(img, cx, cy, tx, ty, 2, fg)
+	fillRect(img, image.Rect(tx-2, ty-2, tx+3, ty+3), fg)
+
+	// Confidence bar
+	bar := image.Rect(cx-r, cy+r+6, cx+r+1, cy+r+10)
+	fillRect(img, bar, annotateSilent)
+	bar.Max.X = bar.Min.X + int(float64(bar.Dx())*conf)
+	fillRect(img, bar, fg)
+}
+
+// fillRect paints rect (clipped to img) with c
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// blend composites a translucent c over rect
+func blend(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// drawLine draws a line of the given thickness
+func drawLine(img *image.RGBA, x0, y0, x1, y1, thickness int, c color.RGBA) {
+	steps := max(abs(x1-x0), abs(y1-y0), 1)
+	for i := 0; i <= steps; i++ {
+		x := x0 + (x1-x0)*i/steps
+		y := y0 + (y1-y0)*i/steps
+		fillRect(img, image.Rect(x-thickness/2, y-thickness/2, x+thickness-thickness/2, y+thickness-thickness/2), c)
+	}
+}
+
+// drawCircle draws a one pixel circle outline
+func drawCircle(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	steps := int(2 * math.Pi * float64(r))
+	for i := 0; i < steps; i++ {
+		t := 2 * math.Pi * float64(i) / float64(steps)
+		x := cx + int(math.Round(float64(r)*math.Cos(t)))
+		y := cy + int(math.Round(float64(r)*math.Sin(t)))
+		if (image.Point{X: x, Y: y}).In(img.Bounds()) {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// AnnotateStats contains annotator statistics
+type AnnotateStats struct {
+	Annotated uint64  `json:"annotated"`
+	Errors    uint64  `json:"errors"`
+	AvgMs     float64 `json:"avg_ms"` // Mean decode + draw + encode time
+}
+
+// GetStats returns annotator statistics
+func (a *Annotator) GetStats() AnnotateStats {
+	stats := AnnotateStats{
+		Annotated: a.annotated.Load(),
+		Errors:    a.errors.Load(),
+	}
+	if stats.Annotated > 0 {
+		stats.AvgMs = float64(a.totalNs.Load()) / float64(stats.Annotated) / 1e6
+	}
+	return stats
+}
@@ -0,0 +1,87 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+// grayJPEG encodes a uniform mid-gray frame
+func grayJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 60, G: 60, B: 60, A: 255}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// greenish reports whether the pixel is dominated by the speaking color
+func greenish(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return g > 2*r && g > 2*b
+}
+
+func TestAnnotator(t *testing.T) {
+	bearing := Bearing{Angle: 20 * math.Pi / 180, Confidence: 0.8, Speaking: true}
+	a := NewAnnotator(AnnotateConfig{FOV: 90 * math.Pi / 180}, func() Bearing { return bearing })
+
+	frame := Frame{Data: grayJPEG(t, 320, 240), Width: 320, Height: 240, FrameID: 7}
+	out, err := a.Annotate(frame, 90)
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if out.FrameID != 7 || out.Width != 320 {
+		t.Errorf("frame metadata changed: %+v", out)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out.Data))
+	if err != nil {
+		t.Fatalf("annotated frame does not decode: %v", err)
+	}
+
+	// 20° left with a 90° FOV lands left of center: 160·(1 - tan20°/tan45°) ≈ 102
+	x := int(160 * (1 - math.Tan(bearing.Angle)))
+	if !greenish(img, x, 200) {
+		t.Errorf("no bearing marker at x=%d", x)
+	}
+	if greenish(img, 240, 200) {
+		t.Error("marker drawn on the wrong side")
+	}
+
+	// Behind the robot: only the dial, no marker in the image
+	bearing = Bearing{Angle: math.Pi, Confidence: 0.5}
+	out, _ = a.Annotate(frame, 90)
+	img, _ = jpeg.Decode(bytes.NewReader(out.Data))
+	for x := 0; x < 320; x += 4 {
+		if greenish(img, x, 200) {
+			t.Fatalf("unexpected marker at x=%d for a bearing outside the view", x)
+		}
+	}
+
+	if stats := a.GetStats(); stats.Annotated != 2 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAnnotator_BadFrame(t *testing.T) {
+	a := NewAnnotator(DefaultAnnotateConfig(), func() Bearing { return Bearing{} })
+
+	frame := Frame{Data: []byte("not a jpeg")}
+	out, err := a.Annotate(frame, 80)
+	if err == nil {
+		t.Fatal("expected an error for an undecodable frame")
+	}
+	if !bytes.Equal(out.Data, frame.Data) {
+		t.Error("undecodable frame should pass through unchanged")
+	}
+	if a.GetStats().Errors != 1 {
+		t.Errorf("Errors = %d, want 1", a.GetStats().Errors)
+	}
+}
@@ -0,0 +1,322 @@
+package camera
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSnapshotNotFound is returned for a name that isn't in the archive
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ArchiveConfig configures the on-disk snapshot archive
+type ArchiveConfig struct {
+	Dir       string        // Directory holding the JPEGs
+	Interval  time.Duration // Save a frame this often (0 = only on triggers)
+	MinGap    time.Duration // Ignore triggers this soon after the last save
+	Retention time.Duration // Delete snapshots older than this (0 = no age limit)
+	MaxBytes  int64         // Delete oldest snapshots beyond this total size (0 = no size limit)
+}
+
+// DefaultArchiveConfig returns sensible defaults
+func DefaultArchiveConfig() ArchiveConfig {
+	return ArchiveConfig{
+		Dir:       "/var/lib/go-eva/snapshots",
+		Interval:  time.Minute,
+		MinGap:    5 * time.Second,
+		Retention: 7 * 24 * time.Hour,
+		MaxBytes:  256 << 20,
+	}
+}
+
+const (
+	snapshotPrefix = "snap-"
+	snapshotSuffix = ".jpg"
+)
+
+// ArchiveEntry describes one archived snapshot
+type ArchiveEntry struct {
+	Name   string    `json:"name"`
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"` // "interval" or the trigger's reason, e.g. "speech"
+	Size   int64     `json:"size"`
+}
+
+// Archive keeps periodic and event-triggered stills in a size-capped
+// directory, for reviewing what the robot saw after the fact
+type Archive struct {
+	cfg    ArchiveConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	entries  []ArchiveEntry // Oldest first
+	bytes    int64
+	lastSave time.Time
+	lastTick time.Time
+	trigger  string // Pending trigger reason ("" = none)
+
+	// Stats
+	saved       atomic.Uint64
+	triggered   atomic.Uint64
+	writeErrors atomic.Uint64
+	pruned      atomic.Uint64
+}
+
+// OpenArchive creates the archive directory and indexes existing snapshots
+func OpenArchive(cfg ArchiveConfig, logger *slog.Logger) (*Archive, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create archive dir: %w", err)
+	}
+
+	a := &Archive{cfg: cfg, logger: logger}
+	if err := a.scan(); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	a.pruneLocked(time.Now())
+	a.mu.Unlock()
+
+	logger.Info("camera archive opened",
+		"dir", cfg.Dir,
+		"snapshots", len(a.entries),
+		"interval", cfg.Interval,
+		"retention", cfg.Retention,
+	)
+	return a, nil
+}
+
+// scan indexes existing snapshot files
+func (a *Archive) scan() error {
+	dirEntries, err := os.ReadDir(a.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("read archive dir: %w", err)
+	}
+
+	for _, e := range dirEntries {
+		t, reason, ok := parseSnapshotName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		a.entries = append(a.entries, ArchiveEntry{Name: e.Name(), Time: t, Reason: reason, Size: info.Size()})
+		a.bytes += info.Size()
+	}
+
+	sort.Slice(a.entries, func(i, j int) bool { return a.entries[i].Time.Before(a.entries[j].Time) })
+	return nil
+}
+
+func snapshotName(t time.Time, reason string) string {
+	return snapshotPrefix + strconv.FormatInt(t.UnixMilli(), 10) + "-" + reason + snapshotSuffix
+}
+
+func parseSnapshotName(name string) (time.Time, string, bool) {
+	if !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+		return time.Time{}, "", false
+	}
+	ms, reason, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix), "-")
+	if !ok || reason == "" {
+		return time.Time{}, "", false
+	}
+	v, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.UnixMilli(v), reason, true
+}
+
+// Run saves frames from the channel as they come due until ctx is cancelled
+// or the channel closes. Each frame is released once handled.
+func (a *Archive) Run(ctx context.Context, frames <-chan Frame) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-frames:
+			if !ok {
+				return
+			}
+			if reason := a.due(time.Now()); reason != "" {
+				if err := a.Save(frame, reason); err != nil {
+					a.logger.Warn("camera snapshot save failed", "error", err)
+				}
+			}
+			frame.Release()
+		}
+	}
+}
+
+// Trigger saves the next frame with the given reason, unless a snapshot was
+// saved less than MinGap ago
+func (a *Archive) Trigger(reason string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.lastSave) < a.cfg.MinGap {
+		return
+	}
+	a.trigger = reason
+	a.triggered.Add(1)
+}
+
+// due returns why the frame arriving at now should be saved ("" = it
+// shouldn't)
+func (a *Archive) due(now time.Time) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if reason := a.trigger; reason != "" {
+		a.trigger = ""
+		return reason
+	}
+	if a.cfg.Interval > 0 && now.Sub(a.lastTick) >= a.cfg.Interval {
+		a.lastTick = now
+		return "interval"
+	}
+	return ""
+}
+
+// Save writes frame to the archive and prunes old snapshots
+func (a *Archive) Save(frame Frame, reason string) error {
+	t := frame.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	name := snapshotName(t, reason)
+	path := filepath.Join(a.cfg.Dir, name)
+
+	// Write under a temporary name so a crash never leaves a torn JPEG
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, frame.Data, 0o644); err != nil {
+		a.writeErrors.Add(1)
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		a.writeErrors.Add(1)
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, ArchiveEntry{Name: name, Time: t, Reason: reason, Size: int64(len(frame.Data))})
+	a.bytes += int64(len(frame.Data))
+	a.lastSave = time.Now()
+	a.saved.Add(1)
+	a.pruneLocked(a.lastSave)
+	return nil
+}
+
+// pruneLocked deletes snapshots past the retention window or size budget,
+// oldest first (caller holds mu)
+func (a *Archive) pruneLocked(now time.Time) {
+	for len(a.entries) > 0 {
+		oldest := a.entries[0]
+		expired := a.cfg.Retention > 0 && oldest.Time.Before(now.Add(-a.cfg.Retention))
+		oversize := a.cfg.MaxBytes > 0 && a.bytes > a.cfg.MaxBytes
+		if !expired && !oversize {
+			break
+		}
+
+		if err := os.Remove(filepath.Join(a.cfg.Dir, oldest.Name)); err != nil && !os.IsNotExist(err) {
+			a.logger.Warn("camera snapshot prune failed", "name", oldest.Name, "error", err)
+			break
+		}
+		a.bytes -= oldest.Size
+		a.entries = a.entries[1:]
+		a.pruned.Add(1)
+	}
+}
+
+// List returns snapshots with from <= time <= to, newest first. limit <= 0
+// returns everything in range.
+func (a *Archive) List(from, to time.Time, limit int) []ArchiveEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries := []ArchiveEntry{}
+	for i := len(a.entries) - 1; i >= 0; i-- {
+		e := a.entries[i]
+		if e.Time.After(to) {
+			continue
+		}
+		if e.Time.Before(from) {
+			break
+		}
+		entries = append(entries, e)
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+	}
+	return entries
+}
+
+// Read returns the JPEG of the named snapshot
+func (a *Archive) Read(name string) ([]byte, error) {
+	a.mu.Lock()
+	found := false
+	for _, e := range a.entries {
+		if e.Name == name {
+			found = true
+			break
+		}
+	}
+	a.mu.Unlock()
+	if !found {
+		return nil, ErrSnapshotNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.cfg.Dir, name))
+	if os.IsNotExist(err) {
+		return nil, ErrSnapshotNotFound // Pruned since the lookup
+	}
+	return data, err
+}
+
+// ArchiveStats contains snapshot archive statistics
+type ArchiveStats struct {
+	Saved       uint64    `json:"saved"`
+	Triggered   uint64    `json:"triggered"`
+	WriteErrors uint64    `json:"write_errors"`
+	Pruned      uint64    `json:"pruned"`
+	Snapshots   int       `json:"snapshots"`
+	Bytes       int64     `json:"bytes"`
+	Oldest      time.Time `json:"oldest,omitempty"`
+	Dir         string    `json:"dir"`
+}
+
+// GetStats returns archive statistics
+func (a *Archive) GetStats() ArchiveStats {
+	a.mu.Lock()
+	count, total := len(a.entries), a.bytes
+	var oldest time.Time
+	if count > 0 {
+		oldest = a.entries[0].Time
+	}
+	a.mu.Unlock()
+
+	return ArchiveStats{
+		Saved:       a.saved.Load(),
+		Triggered:   a.triggered.Load(),
+		WriteErrors: a.writeErrors.Load(),
+		Pruned:      a.pruned.Load(),
+		Snapshots:   count,
+		Bytes:       total,
+		Oldest:      oldest,
+		Dir:         a.cfg.Dir,
+	}
+}
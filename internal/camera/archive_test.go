@@ -0,0 +1,125 @@
+package camera
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func openTestArchive(t *testing.T, cfg ArchiveConfig) *Archive {
+	t.Helper()
+
+	cfg.Dir = t.TempDir()
+	a, err := OpenArchive(cfg, nil)
+	if err != nil {
+		t.Fatalf("OpenArchive() error = %v", err)
+	}
+	return a
+}
+
+func TestArchive_SaveListRead(t *testing.T) {
+	a := openTestArchive(t, DefaultArchiveConfig())
+
+	base := time.Now().Add(-time.Minute)
+	for i, reason := range []string{"interval", "speech", "interval"} {
+		frame := Frame{Data: []byte{0xFF, 0xD8, byte(i)}, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := a.Save(frame, reason); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	entries := a.List(base, base.Add(time.Hour), 0)
+	if len(entries) != 3 {
+		t.Fatalf("List() returned %d snapshots, want 3", len(entries))
+	}
+	if entries[0].Reason != "interval" || entries[1].Reason != "speech" || !entries[0].Time.After(entries[2].Time) {
+		t.Errorf("List() = %+v, want newest first with reasons", entries)
+	}
+	if limited := a.List(base, base.Add(time.Hour), 1); len(limited) != 1 {
+		t.Errorf("limit not applied: got %d", len(limited))
+	}
+
+	data, err := a.Read(entries[1].Name)
+	if err != nil || len(data) != 3 || data[2] != 1 {
+		t.Errorf("Read() = %v, %v, want the speech snapshot", data, err)
+	}
+	if _, err := a.Read("../config.yaml"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Read() of a name outside the archive = %v, want ErrSnapshotNotFound", err)
+	}
+
+	// Reopening indexes what is on disk
+	reopened, err := OpenArchive(a.cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.GetStats(); got.Snapshots != 3 || got.Bytes != 9 {
+		t.Errorf("reopened stats = %+v, want 3 snapshots of 9 bytes", got)
+	}
+}
+
+func TestArchive_Prunes(t *testing.T) {
+	cfg := DefaultArchiveConfig()
+	cfg.MaxBytes = 25
+	cfg.Retention = time.Hour
+	a := openTestArchive(t, cfg)
+
+	now := time.Now()
+	a.Save(Frame{Data: make([]byte, 10), Timestamp: now.Add(-2 * time.Hour)}, "interval")
+	if got := a.GetStats().Snapshots; got != 0 {
+		t.Fatalf("snapshot past retention kept: %d", got)
+	}
+
+	for i := 0; i < 4; i++ {
+		a.Save(Frame{Data: make([]byte, 10), Timestamp: now.Add(time.Duration(i) * time.Second)}, "interval")
+	}
+	stats := a.GetStats()
+	if stats.Snapshots != 2 || stats.Bytes != 20 || stats.Pruned != 3 {
+		t.Errorf("stats = %+v, want the 2 newest kept under max_bytes", stats)
+	}
+
+	dirFiles, _ := os.ReadDir(a.cfg.Dir)
+	if len(dirFiles) != 2 {
+		t.Errorf("%d files on disk, want 2", len(dirFiles))
+	}
+}
+
+func TestArchive_RunIntervalAndTrigger(t *testing.T) {
+	cfg := DefaultArchiveConfig()
+	cfg.Interval = time.Hour
+	cfg.MinGap = 0
+	a := openTestArchive(t, cfg)
+
+	frames := make(chan Frame)
+	done := make(chan struct{})
+	go func() {
+		a.Run(context.Background(), frames)
+		close(done)
+	}()
+
+	send := func() {
+		buf := frameBuffers.Get()
+		*buf = append(*buf, 0xFF, 0xD8)
+		frame := newPooledFrame(frameBuffers, buf)
+		frame.Timestamp = time.Now()
+		frames <- frame
+		time.Sleep(5 * time.Millisecond) // Distinct names
+	}
+
+	send() // First interval snapshot
+	send() // Not due
+	a.Trigger("speech")
+	send()
+	send()
+	close(frames)
+	<-done
+
+	stats := a.GetStats()
+	if stats.Saved != 2 || stats.Triggered != 1 {
+		t.Errorf("stats = %+v, want one interval and one triggered snapshot", stats)
+	}
+	if entries := a.List(time.Time{}, time.Now(), 0); len(entries) != 2 || entries[0].Reason != "speech" {
+		t.Errorf("List() = %+v, want the speech snapshot newest", entries)
+	}
+}
@@ -0,0 +1,128 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"sync"
+	"sync/atomic"
+)
+
+// frameBufferSize is the starting capacity of a pooled frame buffer, enough
+// for a 640x480 JPEG at the default quality
+const frameBufferSize = 128 * 1024
+
+// maxPooledBuffer keeps the odd huge frame from pinning memory in the pool
+const maxPooledBuffer = 4 << 20
+
+// BufferPool recycles JPEG buffers so the capture path doesn't allocate a
+// new slice for every frame
+type BufferPool struct {
+	pool sync.Pool
+
+	gets   atomic.Uint64
+	allocs atomic.Uint64
+	puts   atomic.Uint64
+	inUse  atomic.Int64
+}
+
+// NewBufferPool creates an empty buffer pool
+func NewBufferPool() *BufferPool {
+	p := &BufferPool{}
+	p.pool.New = func() any {
+		p.allocs.Add(1)
+		buf := make([]byte, 0, frameBufferSize)
+		return &buf
+	}
+	return p
+}
+
+// frameBuffers backs every frame the camera produces
+var frameBuffers = NewBufferPool()
+
+// Get returns an empty buffer
+func (p *BufferPool) Get() *[]byte {
+	p.gets.Add(1)
+	p.inUse.Add(1)
+	buf := p.pool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// Put returns a buffer for reuse; it must not be used afterwards
+func (p *BufferPool) Put(buf *[]byte) {
+	p.inUse.Add(-1)
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	p.puts.Add(1)
+	p.pool.Put(buf)
+}
+
+// BufferStats contains buffer pool statistics
+type BufferStats struct {
+	Gets   uint64 `json:"gets"`
+	Allocs uint64 `json:"allocs"` // Gets the pool couldn't serve from a released buffer
+	Puts   uint64 `json:"puts"`
+	InUse  int64  `json:"in_use"` // Buffers held by frames not yet released
+}
+
+// GetStats returns buffer pool statistics
+func (p *BufferPool) GetStats() BufferStats {
+	return BufferStats{
+		Gets:   p.gets.Load(),
+		Allocs: p.allocs.Load(),
+		Puts:   p.puts.Load(),
+		InUse:  p.inUse.Load(),
+	}
+}
+
+// frameRef counts the holders of a pooled frame buffer
+type frameRef struct {
+	refs atomic.Int32
+	buf  *[]byte
+	pool *BufferPool
+}
+
+// newPooledFrame wraps a pooled buffer holding a JPEG. The caller holds the
+// only reference.
+func newPooledFrame(pool *BufferPool, buf *[]byte) Frame {
+	ref := &frameRef{buf: buf, pool: pool}
+	ref.refs.Store(1)
+	return Frame{Data: *buf, ref: ref}
+}
+
+// Retain adds a reference to the frame's buffer, for a holder that keeps
+// Data past the call it received the frame in. Each Retain needs a matching
+// Release.
+func (f Frame) Retain() {
+	if f.ref != nil {
+		f.ref.refs.Add(1)
+	}
+}
+
+// Release drops a reference. The last one returns the buffer to the pool,
+// after which Data must not be used. A frame nobody releases is garbage
+// collected like any other slice, only without its buffer being reused.
+// Frames not built on a pooled buffer ignore Retain and Release.
+func (f Frame) Release() {
+	if f.ref != nil && f.ref.refs.Add(-1) == 0 {
+		f.ref.pool.Put(f.ref.buf)
+	}
+}
+
+// encodeFrame JPEG-encodes img into a pooled buffer
+func encodeFrame(img image.Image, quality int) (Frame, error) {
+	buf := frameBuffers.Get()
+	w := bytes.NewBuffer(*buf)
+	if err := jpeg.Encode(w, img, &jpeg.Options{Quality: quality}); err != nil {
+		frameBuffers.Put(buf)
+		return Frame{}, err
+	}
+	*buf = w.Bytes()
+
+	bounds := img.Bounds()
+	frame := newPooledFrame(frameBuffers, buf)
+	frame.Width, frame.Height = bounds.Dx(), bounds.Dy()
+	return frame, nil
+}
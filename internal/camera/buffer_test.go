@@ -0,0 +1,64 @@
+package camera
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBufferPool_ReleaseRecycles(t *testing.T) {
+	pool := NewBufferPool()
+
+	buf := pool.Get()
+	*buf = append(*buf, "jpeg"...)
+	frame := newPooledFrame(pool, buf)
+
+	// A second holder keeps the buffer out of the pool
+	frame.Retain()
+	frame.Release()
+	if stats := pool.GetStats(); stats.InUse != 1 || stats.Puts != 0 {
+		t.Fatalf("stats = %+v after one of two releases, want the buffer still in use", stats)
+	}
+
+	frame.Release()
+	stats := pool.GetStats()
+	if stats.InUse != 0 || stats.Puts != 1 {
+		t.Fatalf("stats = %+v after the last release, want the buffer returned", stats)
+	}
+
+	next := pool.Get()
+	if len(*next) != 0 {
+		t.Errorf("reused buffer has %d bytes, want it emptied", len(*next))
+	}
+	pool.Put(next)
+}
+
+func TestFrame_UnpooledIgnoresRelease(t *testing.T) {
+	frame := Frame{Data: []byte("jpeg")}
+	frame.Retain()
+	frame.Release()
+	frame.Release()
+	if string(frame.Data) != "jpeg" {
+		t.Error("releasing an unpooled frame changed its data")
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 48))
+	before := frameBuffers.GetStats().InUse
+
+	frame, err := encodeFrame(img, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.Width != 64 || frame.Height != 48 {
+		t.Errorf("size = %dx%d, want 64x48", frame.Width, frame.Height)
+	}
+	if len(frame.Data) < 2 || frame.Data[0] != 0xFF || frame.Data[1] != 0xD8 {
+		t.Error("Data is not a JPEG")
+	}
+
+	frame.Release()
+	if got := frameBuffers.GetStats().InUse; got != before {
+		t.Errorf("in use = %d after release, want %d", got, before)
+	}
+}
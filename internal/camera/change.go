@@ -0,0 +1,170 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Downsampled grid the detector compares frames on
+const (
+	changeGridW = 32
+	changeGridH = 24
+)
+
+// ChangeConfig configures frame change detection
+type ChangeConfig struct {
+	Threshold  float64       // Fraction of grid cells that must change (0-1)
+	PixelDelta int           // Brightness change (0-255) for a cell to count as changed
+	Keepalive  time.Duration // Send a frame at least this often, even if static (0 = never)
+}
+
+// DefaultChangeConfig returns sensible defaults
+func DefaultChangeConfig() ChangeConfig {
+	return ChangeConfig{
+		Threshold:  0.01,
+		PixelDelta: 12,
+		Keepalive:  5 * time.Second,
+	}
+}
+
+// ChangeDetector skips frames that barely differ from the last one sent,
+// comparing downsampled grayscale thumbnails
+type ChangeDetector struct {
+	cfg ChangeConfig
+
+	mu       sync.Mutex
+	ref      []uint8 // Thumbnail of the last sent frame
+	lastSent time.Time
+
+	// Stats
+	sent       atomic.Uint64
+	skipped    atomic.Uint64
+	keepalives atomic.Uint64
+}
+
+// NewChangeDetector creates a change detector
+func NewChangeDetector(cfg ChangeConfig) *ChangeDetector {
+	return &ChangeDetector{cfg: cfg}
+}
+
+// ShouldSend reports whether frame changed enough (or the keepalive is due),
+// and if so makes it the new reference. Undecodable frames are always sent.
+func (d *ChangeDetector) ShouldSend(frame Frame) bool {
+	thumb, err := thumbnail(frame.Data)
+	if err != nil {
+		d.sent.Add(1)
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case d.ref == nil:
+	case d.changed(thumb) >= d.cfg.Threshold:
+	case d.cfg.Keepalive > 0 && frame.Timestamp.Sub(d.lastSent) >= d.cfg.Keepalive:
+		d.keepalives.Add(1)
+	default:
+		d.skipped.Add(1)
+		return false
+	}
+
+	d.ref = thumb
+	d.lastSent = frame.Timestamp
+	d.sent.Add(1)
+	return true
+}
+
+// Reset forgets the reference frame so the next frame is always sent
+func (d *ChangeDetector) Reset() {
+	d.mu.Lock()
+	d.ref = nil
+	d.mu.Unlock()
+}
+
+// changed returns the fraction of cells that differ from the reference
+func (d *ChangeDetector) changed(thumb []uint8) float64 {
+	n := 0
+	for i, v := range thumb {
+		delta := int(v) - int(d.ref[i])
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta > d.cfg.PixelDelta {
+			n++
+		}
+	}
+	return float64(n) / float64(len(thumb))
+}
+
+// thumbnail decodes a JPEG into a changeGridW×changeGridH grayscale grid of
+// cell averages
+func thumbnail(data []byte) ([]uint8, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	var sums [changeGridW * changeGridH]uint32
+	var counts [changeGridW * changeGridH]uint32
+
+	// Sample every other pixel; plenty for cell averages
+	ycc, isYCC := img.(*image.YCbCr)
+	luma := func(x, y int) uint8 {
+		if isYCC {
+			return ycc.Y[ycc.YOffset(x, y)]
+		}
+		return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+	}
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		cy := (y - b.Min.Y) * changeGridH / b.Dy()
+		for x := b.Min.X; x < b.Max.X; x += 2 {
+			cx := (x - b.Min.X) * changeGridW / b.Dx()
+			i := cy*changeGridW + cx
+			sums[i] += uint32(luma(x, y))
+			counts[i]++
+		}
+	}
+
+	thumb := make([]uint8, len(sums))
+	for i := range sums {
+		if counts[i] > 0 {
+			thumb[i] = uint8(sums[i] / counts[i])
+		}
+	}
+	return thumb, nil
+}
+
+// ChangeStats contains change detection statistics
+type ChangeStats struct {
+	FramesSent    uint64 `json:"frames_sent"`
+	FramesSkipped uint64 `json:"frames_skipped"`
+	Keepalives    uint64 `json:"keepalives"` // Static frames sent because the keepalive was due
+}
+
+// Stats returns change detection statistics
+func (d *ChangeDetector) Stats() ChangeStats {
+	return ChangeStats{
+		FramesSent:    d.sent.Load(),
+		FramesSkipped: d.skipped.Load(),
+		Keepalives:    d.keepalives.Load(),
+	}
+}
+
+// RegisterMetrics registers change detection collectors
+func (d *ChangeDetector) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_frames_skipped_total",
+			Help: "Frames not uploaded because the scene had not changed",
+		}, func() float64 { return float64(d.skipped.Load()) }),
+	)
+}
@@ -0,0 +1,71 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+// testJPEG encodes a gray image with an optional white square
+func testJPEG(t *testing.T, square bool) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 320, 240))
+	for i := range img.Pix {
+		img.Pix[i] = 80
+	}
+	if square {
+		for y := 100; y < 140; y++ {
+			for x := 140; x < 180; x++ {
+				img.SetGray(x, y, color.Gray{Y: 250})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestChangeDetector(t *testing.T) {
+	d := NewChangeDetector(DefaultChangeConfig())
+	static := testJPEG(t, false)
+	moved := testJPEG(t, true)
+	start := time.Now()
+
+	frame := func(data []byte, offset time.Duration) Frame {
+		return Frame{Data: data, Timestamp: start.Add(offset)}
+	}
+
+	if !d.ShouldSend(frame(static, 0)) {
+		t.Error("first frame should be sent")
+	}
+	if d.ShouldSend(frame(static, 100*time.Millisecond)) {
+		t.Error("identical frame should be skipped")
+	}
+	if !d.ShouldSend(frame(moved, 200*time.Millisecond)) {
+		t.Error("changed frame should be sent")
+	}
+	if d.ShouldSend(frame(moved, 300*time.Millisecond)) {
+		t.Error("unchanged frame should be skipped")
+	}
+	if !d.ShouldSend(frame(moved, 5300*time.Millisecond)) {
+		t.Error("keepalive frame should be sent")
+	}
+
+	d.Reset()
+	if !d.ShouldSend(frame(moved, 5400*time.Millisecond)) {
+		t.Error("frame after reset should be sent")
+	}
+	if !d.ShouldSend(frame([]byte("not a jpeg"), 5500*time.Millisecond)) {
+		t.Error("undecodable frame should be sent")
+	}
+
+	stats := d.Stats()
+	if stats.FramesSent != 5 || stats.FramesSkipped != 2 || stats.Keepalives != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
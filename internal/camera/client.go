@@ -4,20 +4,26 @@ package camera
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"net/url"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
 )
 
 // Config holds camera client configuration
 type Config struct {
 	PollenURL string        // Base URL for Pollen API (e.g., "http://localhost:8000")
 	Framerate int           // Target frames per second (for rate limiting callbacks)
-	Width     int           // Desired width (informational only)
-	Height    int           // Desired height (informational only)
+	Width     int           // Maximum frame width (frames are scaled down to fit, keeping aspect)
+	Height    int           // Maximum frame height
 	Quality   int           // JPEG quality (1-100)
 	Timeout   time.Duration // Connection timeout
+
+	// Poll Pollen's snapshot endpoint while WebRTC is down
+	SnapshotFallback bool
 }
 
 // DefaultConfig returns sensible defaults
@@ -29,37 +35,52 @@ func DefaultConfig() Config {
 		Height:    480,
 		Quality:   80,
 		Timeout:   15 * time.Second,
+
+		SnapshotFallback: true,
 	}
 }
 
-// Frame represents a captured video frame
+// Frame represents a captured video frame. Captured frames share a pooled
+// buffer: whoever receives one owns a reference for the duration of the
+// call, and must Retain it to keep Data longer.
 type Frame struct {
 	Data      []byte    // JPEG encoded
 	Width     int       // Actual width
 	Height    int       // Actual height
 	Timestamp time.Time // Capture time
 	FrameID   uint64    // Sequential frame ID
+
+	ref *frameRef // Pooled buffer behind Data (nil = not pooled)
 }
 
+// TopicFrames carries captured frames on the event bus. Subscribers reading
+// the channel directly Release each frame when done; Handle does it for them.
+var TopicFrames = bus.NewTopic[Frame]("camera.frame")
+
 // Client captures frames via WebRTC from Pollen
 type Client struct {
 	cfg    Config
 	logger *slog.Logger
 
-	webrtc    *WebRTCClient
-	robotIP   string
+	webrtc     *WebRTCClient
+	robotIP    string
+	httpClient *http.Client
 
 	mu        sync.RWMutex
 	running   bool
 	cancel    context.CancelFunc
 	lastFrame *Frame
+	annotator *Annotator
 
 	// Callbacks
 	onFrame func(Frame)
+	onAudio func([]byte)
 
 	// Stats
 	framesCaptured atomic.Uint64
 	frameErrors    atomic.Uint64
+	snapshots      atomic.Uint64
+	frameSeq       atomic.Uint64
 }
 
 // NewClient creates a new camera client
@@ -75,19 +96,66 @@ func NewClient(cfg Config, logger *slog.Logger) *Client {
 	}
 
 	return &Client{
-		cfg:     cfg,
-		logger:  logger,
-		robotIP: robotIP,
+		cfg:        cfg,
+		logger:     logger,
+		robotIP:    robotIP,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
 	}
 }
 
-// OnFrame sets the callback for new frames
+// OnFrame sets the callback for new frames. The frame's buffer is recycled
+// once the callback returns unless the callback retains it.
 func (c *Client) OnFrame(callback func(Frame)) {
 	c.mu.Lock()
 	c.onFrame = callback
 	c.mu.Unlock()
 }
 
+// OnAudio receives the robot mic as Opus packets from the WebRTC session's
+// audio track. Set it before Start; without it no audio is negotiated.
+func (c *Client) OnAudio(callback func(packet []byte)) {
+	c.mu.Lock()
+	c.onAudio = callback
+	c.mu.Unlock()
+}
+
+// SetFramerate changes the target frame rate of a running client
+func (c *Client) SetFramerate(fps int) {
+	if fps <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.cfg.Framerate = fps
+	webrtc := c.webrtc
+	c.mu.Unlock()
+
+	if webrtc != nil {
+		webrtc.SetFramerate(fps)
+	}
+	c.logger.Info("camera framerate changed", "framerate", fps)
+}
+
+// SetAnnotator draws the speaker bearing onto every frame before it is
+// published or served (nil = off)
+func (c *Client) SetAnnotator(a *Annotator) {
+	c.mu.Lock()
+	c.annotator = a
+	c.mu.Unlock()
+}
+
+// PublishTo routes captured frames to the event bus, replacing OnFrame
+func (c *Client) PublishTo(b *bus.Bus) {
+	c.OnFrame(bus.Publisher(b, TopicFrames))
+}
+
+// Framerate returns the current target frame rate
+func (c *Client) Framerate() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg.Framerate
+}
+
 // Start begins capturing frames via WebRTC
 func (c *Client) Start(ctx context.Context) error {
 	c.mu.Lock()
@@ -101,29 +169,34 @@ func (c *Client) Start(ctx context.Context) error {
 
 	c.logger.Info("camera client starting (WebRTC)",
 		"robot_ip", c.robotIP,
-		"framerate", c.cfg.Framerate,
+		"framerate", c.Framerate(),
 	)
 
 	// Create WebRTC client
-	c.webrtc = NewWebRTCClient(c.robotIP, c.logger)
+	settings := c.Settings()
+	webrtc := NewWebRTCClient(c.robotIP, c.logger)
+	webrtc.SetFramerate(settings.Framerate)
+	webrtc.SetOutput(settings.Width, settings.Height, settings.Quality)
+	c.mu.Lock()
+	c.webrtc = webrtc
+	if c.onAudio != nil {
+		webrtc.OnAudio(c.onAudio)
+	}
+	c.mu.Unlock()
 
 	// Set up frame callback
 	c.webrtc.OnFrame(func(frame Frame) {
-		c.framesCaptured.Add(1)
-
-		c.mu.Lock()
-		c.lastFrame = &frame
-		callback := c.onFrame
-		c.mu.Unlock()
-
-		if callback != nil {
-			callback(frame)
-		}
+		frame.FrameID = c.frameSeq.Add(1)
+		c.publish(frame)
 	})
 
 	// Connect in background (retries on failure)
 	go c.connectLoop(ctx)
 
+	if c.cfg.SnapshotFallback {
+		go c.snapshotLoop(ctx)
+	}
+
 	c.mu.Lock()
 	c.running = true
 	c.mu.Unlock()
@@ -131,6 +204,43 @@ func (c *Client) Start(ctx context.Context) error {
 	return nil
 }
 
+// publish records a frame and hands it to the callback, taking over the
+// caller's reference
+func (c *Client) publish(frame Frame) {
+	c.framesCaptured.Add(1)
+
+	c.mu.RLock()
+	annotator, quality := c.annotator, c.cfg.Quality
+	c.mu.RUnlock()
+	if annotator != nil {
+		annotated, err := annotator.Annotate(frame, quality)
+		if err != nil {
+			c.logger.Debug("frame annotation failed", "error", err)
+		} else {
+			frame.Release()
+			frame = annotated
+		}
+	}
+
+	// lastFrame keeps the reference; the callback gets its own so a
+	// concurrent publish can't recycle the buffer under it
+	frame.Retain()
+	defer frame.Release()
+
+	c.mu.Lock()
+	old := c.lastFrame
+	c.lastFrame = &frame
+	callback := c.onFrame
+	c.mu.Unlock()
+	if old != nil {
+		old.Release()
+	}
+
+	if callback != nil {
+		callback(frame)
+	}
+}
+
 // connectLoop attempts to connect and reconnects on failure
 func (c *Client) connectLoop(ctx context.Context) {
 	backoff := time.Second
@@ -198,36 +308,61 @@ func (c *Client) Stop() {
 	c.logger.Info("camera client stopped")
 }
 
-// GetLastFrame returns the most recently captured frame
+// GetLastFrame returns the most recently captured frame (nil if none yet),
+// retained for the caller, who must Release it when done with Data
 func (c *Client) GetLastFrame() *Frame {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.lastFrame
+	if c.lastFrame == nil {
+		return nil
+	}
+	frame := *c.lastFrame
+	frame.Retain()
+	return &frame
 }
 
 // Stats returns capture statistics
 func (c *Client) Stats() CameraStats {
 	c.mu.RLock()
-	running := c.running
+	running, annotator := c.running, c.annotator
 	c.mu.RUnlock()
 
 	connected := false
+	var decoder DecoderStats
+	var audioPackets uint64
 	if c.webrtc != nil {
 		connected = c.webrtc.IsConnected()
+		decoder = c.webrtc.DecoderStats()
+		audioPackets = c.webrtc.AudioPackets()
 	}
 
-	return CameraStats{
+	stats := CameraStats{
 		FramesCaptured: c.framesCaptured.Load(),
 		FrameErrors:    c.frameErrors.Load(),
+		Snapshots:      c.snapshots.Load(),
 		Running:        running,
 		Connected:      connected,
+		AudioPackets:   audioPackets,
+		Decoder:        decoder,
+		Buffers:        frameBuffers.GetStats(),
+	}
+	if annotator != nil {
+		annotate := annotator.GetStats()
+		stats.Annotate = &annotate
 	}
+	return stats
 }
 
 // CameraStats contains camera statistics
 type CameraStats struct {
 	FramesCaptured uint64 `json:"frames_captured"`
 	FrameErrors    uint64 `json:"frame_errors"`
+	Snapshots      uint64 `json:"snapshots"` // Frames from the snapshot fallback
 	Running        bool   `json:"running"`
 	Connected      bool   `json:"connected"`
+	AudioPackets   uint64 `json:"audio_packets"` // Opus packets from the mic track since Start
+
+	Decoder  DecoderStats   `json:"decoder"`
+	Buffers  BufferStats    `json:"buffers"`            // Pooled frame buffers
+	Annotate *AnnotateStats `json:"annotate,omitempty"` // DOA overlay, when enabled
 }
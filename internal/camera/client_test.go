@@ -206,3 +206,39 @@ func TestGetLastFrame(t *testing.T) {
 }
 
 
+
+func TestReconfigure(t *testing.T) {
+	client := NewClient(DefaultConfig(), nil)
+
+	applied, err := client.Reconfigure("low", Settings{Framerate: 8})
+	if err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	want := Settings{Framerate: 8, Width: 320, Height: 240, Quality: 60}
+	if applied != want || client.Settings() != want {
+		t.Errorf("applied %+v, want %+v", applied, want)
+	}
+
+	if _, err := client.Reconfigure("", Settings{Quality: 150}); err == nil {
+		t.Error("quality above 100 should fail")
+	}
+	if _, err := client.Reconfigure("ultra", Settings{}); err == nil {
+		t.Error("unknown preset should fail")
+	}
+	if client.Settings() != want {
+		t.Errorf("rejected changes should leave settings alone, got %+v", client.Settings())
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
+
+	b := fitImage(img, 640, 480).Bounds()
+	if b.Dx() != 640 || b.Dy() != 360 {
+		t.Errorf("got %dx%d, want 640x360", b.Dx(), b.Dy())
+	}
+
+	if fitImage(img, 1920, 1080) != image.Image(img) {
+		t.Error("smaller images should not be scaled")
+	}
+}
@@ -0,0 +1,321 @@
+package camera
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DecoderConfig configures the streaming H264 decoder
+type DecoderConfig struct {
+	Command    string   // Decoder binary (default: "ffmpeg")
+	Args       []string // Overrides the default ffmpeg arguments when set
+	Framerate  int      // Output JPEG rate
+	Quality    int      // ffmpeg -q:v (2-31, lower is better)
+	Width      int      // Output bounding box, aspect kept (0 = stream resolution)
+	Height     int
+	MaxBackoff time.Duration // Maximum restart delay after the process exits
+}
+
+// DefaultDecoderConfig returns sensible defaults
+func DefaultDecoderConfig() DecoderConfig {
+	return DecoderConfig{
+		Command:    "ffmpeg",
+		Framerate:  10,
+		Quality:    3,
+		MaxBackoff: 10 * time.Second,
+	}
+}
+
+// args returns the ffmpeg command line for a long-lived H264 → MJPEG pipe
+func (c DecoderConfig) args() []string {
+	if c.Args != nil {
+		return c.Args
+	}
+	return []string{
+		"-hide_banner", "-loglevel", "error",
+		"-fflags", "nobuffer", "-flags", "low_delay",
+		"-f", "h264", "-i", "pipe:0",
+		"-vf", c.filter(),
+		"-f", "image2pipe", "-vcodec", "mjpeg",
+		"-q:v", strconv.Itoa(c.Quality),
+		"pipe:1",
+	}
+}
+
+// filter returns the ffmpeg video filter chain
+func (c DecoderConfig) filter() string {
+	vf := "fps=" + strconv.Itoa(c.Framerate)
+	if c.Width > 0 && c.Height > 0 {
+		vf += ",scale=" + strconv.Itoa(c.Width) + ":" + strconv.Itoa(c.Height) +
+			":force_original_aspect_ratio=decrease"
+	}
+	return vf
+}
+
+// ffmpegQuality maps JPEG quality (1-100) to ffmpeg -q:v (31-2)
+func ffmpegQuality(jpegQuality int) int {
+	q := 31 - (jpegQuality*29)/100
+	if q < 2 {
+		q = 2
+	}
+	if q > 31 {
+		q = 31
+	}
+	return q
+}
+
+// StreamDecoder keeps one decoder process running and feeds it the H264
+// elementary stream, emitting each decoded frame as a JPEG
+type StreamDecoder struct {
+	cfg    DecoderConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	stdin    io.WriteCloser
+	onFrame  func(Frame)
+	reconfig bool // Restart requested by Reconfigure, not a failure
+
+	// Stats
+	bytesIn   atomic.Uint64
+	framesOut atomic.Uint64
+	restarts  atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// NewStreamDecoder creates a streaming decoder
+func NewStreamDecoder(cfg DecoderConfig, logger *slog.Logger) *StreamDecoder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Command == "" {
+		cfg.Command = "ffmpeg"
+	}
+	if cfg.Framerate <= 0 {
+		cfg.Framerate = DefaultDecoderConfig().Framerate
+	}
+	if cfg.Quality <= 0 {
+		cfg.Quality = DefaultDecoderConfig().Quality
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultDecoderConfig().MaxBackoff
+	}
+
+	return &StreamDecoder{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// OnFrame sets the callback for decoded JPEG frames. Only Data is set, in a
+// pooled buffer whose reference passes to the callback.
+func (d *StreamDecoder) OnFrame(callback func(Frame)) {
+	d.mu.Lock()
+	d.onFrame = callback
+	d.mu.Unlock()
+}
+
+// Reconfigure restarts the decoder process with new output settings
+func (d *StreamDecoder) Reconfigure(cfg DecoderConfig) {
+	d.mu.Lock()
+	if cfg.Command == "" {
+		cfg.Command = d.cfg.Command
+	}
+	if cfg.Framerate <= 0 {
+		cfg.Framerate = d.cfg.Framerate
+	}
+	if cfg.Quality <= 0 {
+		cfg.Quality = d.cfg.Quality
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = d.cfg.MaxBackoff
+	}
+	d.cfg = cfg
+	stdin := d.stdin
+	if stdin != nil {
+		d.reconfig = true
+	}
+	d.mu.Unlock()
+
+	// Closing stdin ends the process; Run starts a new one with cfg
+	if stdin != nil {
+		stdin.Close()
+	}
+}
+
+// Run keeps the decoder process alive until ctx is cancelled
+func (d *StreamDecoder) Run(ctx context.Context) {
+	backoff := 100 * time.Millisecond
+
+	for {
+		start := time.Now()
+		err := d.runOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.mu.Lock()
+		reconfig := d.reconfig
+		d.reconfig = false
+		maxBackoff := d.cfg.MaxBackoff
+		d.mu.Unlock()
+		if reconfig {
+			continue
+		}
+
+		// A process that ran for a while gets a fresh backoff
+		if time.Since(start) > maxBackoff {
+			backoff = 100 * time.Millisecond
+		}
+
+		d.restarts.Add(1)
+		d.logger.Warn("h264 decoder exited, restarting", "error", err, "retry_in", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce starts the process and reads frames until it exits
+func (d *StreamDecoder) runOnce(ctx context.Context) error {
+	d.mu.Lock()
+	cfg := d.cfg
+	d.mu.Unlock()
+
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.args()...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start decoder: %w", err)
+	}
+
+	d.mu.Lock()
+	d.stdin = stdin
+	d.mu.Unlock()
+
+	readErr := d.readFrames(stdout)
+
+	d.mu.Lock()
+	d.stdin = nil
+	d.mu.Unlock()
+	stdin.Close()
+
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return readErr
+	}
+	return waitErr
+}
+
+// readFrames splits the MJPEG output into individual JPEGs (SOI … EOI),
+// each assembled straight into a pooled buffer
+func (d *StreamDecoder) readFrames(r io.Reader) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var frame *[]byte // Frame being assembled (nil between frames)
+	defer func() {
+		if frame != nil {
+			frameBuffers.Put(frame)
+		}
+	}()
+	var prev byte
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read decoder output: %w", err)
+		}
+
+		// Entropy-coded data byte-stuffs 0xFF, so markers are unambiguous
+		if frame == nil {
+			if prev == 0xFF && b == 0xD8 {
+				frame = frameBuffers.Get()
+				*frame = append(*frame, 0xFF, 0xD8)
+			}
+			prev = b
+			continue
+		}
+
+		*frame = append(*frame, b)
+		if prev == 0xFF && b == 0xD9 {
+			d.emit(newPooledFrame(frameBuffers, frame))
+			frame = nil
+			b = 0 // Don't let this 0xD9 pair with the next byte
+		}
+		prev = b
+	}
+}
+
+func (d *StreamDecoder) emit(frame Frame) {
+	d.framesOut.Add(1)
+
+	d.mu.Lock()
+	callback := d.onFrame
+	d.mu.Unlock()
+
+	if callback != nil {
+		callback(frame)
+	} else {
+		frame.Release()
+	}
+}
+
+// Write feeds Annex-B H264 data to the decoder.
+// Data is dropped while the decoder is (re)starting.
+func (d *StreamDecoder) Write(data []byte) (int, error) {
+	d.mu.Lock()
+	stdin := d.stdin
+	d.mu.Unlock()
+
+	if stdin == nil {
+		d.dropped.Add(uint64(len(data)))
+		return len(data), nil
+	}
+
+	n, err := stdin.Write(data)
+	d.bytesIn.Add(uint64(n))
+	return n, err
+}
+
+// DecoderStats contains decoder statistics
+type DecoderStats struct {
+	BytesIn      uint64 `json:"bytes_in"`
+	FramesOut    uint64 `json:"frames_out"`
+	Restarts     uint64 `json:"restarts"`
+	BytesDropped uint64 `json:"bytes_dropped"`
+}
+
+// GetStats returns decoder statistics
+func (d *StreamDecoder) GetStats() DecoderStats {
+	return DecoderStats{
+		BytesIn:      d.bytesIn.Load(),
+		FramesOut:    d.framesOut.Load(),
+		Restarts:     d.restarts.Load(),
+		BytesDropped: d.dropped.Load(),
+	}
+}
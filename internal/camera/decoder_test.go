@@ -0,0 +1,154 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamDecoder_SplitsJPEGStream(t *testing.T) {
+	d := NewStreamDecoder(DefaultDecoderConfig(), nil)
+
+	var mu sync.Mutex
+	var frames [][]byte
+	d.OnFrame(func(f Frame) {
+		mu.Lock()
+		frames = append(frames, f.Data)
+		mu.Unlock()
+	})
+
+	// Noise before the first SOI, and a 0xFF 0x00 stuffed byte inside a frame
+	a := []byte{0xFF, 0xD8, 0x01, 0xFF, 0x00, 0x02, 0xFF, 0xD9}
+	b := []byte{0xFF, 0xD8, 0x03, 0xFF, 0xD9}
+	stream := append(append([]byte{0x00, 0xFF, 0x12}, a...), b...)
+
+	if err := d.readFrames(bytes.NewReader(stream)); err != nil {
+		t.Fatalf("readFrames() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	if !bytes.Equal(frames[0], a) || !bytes.Equal(frames[1], b) {
+		t.Errorf("unexpected frames %x", frames)
+	}
+	if d.GetStats().FramesOut != 2 {
+		t.Errorf("FramesOut = %d, want 2", d.GetStats().FramesOut)
+	}
+}
+
+func TestStreamDecoder_PersistentProcess(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	// cat echoes its input, standing in for a decoder that emits JPEGs
+	cfg := DefaultDecoderConfig()
+	cfg.Command = "cat"
+	cfg.Args = []string{}
+	d := NewStreamDecoder(cfg, nil)
+
+	got := make(chan []byte, 4)
+	d.OnFrame(func(f Frame) { got <- f.Data })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// Writes are dropped until the process is up; probe until one comes back
+	probe := []byte{0xFF, 0xD8, 0xAA, 0xFF, 0xD9}
+	deadline := time.Now().Add(2 * time.Second)
+	for ready := false; !ready; {
+		d.Write(probe)
+		select {
+		case <-got:
+			ready = true
+		case <-time.After(20 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("timeout waiting for decoder to start")
+			}
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	for len(got) > 0 {
+		<-got
+	}
+
+	for i := 0; i < 3; i++ {
+		frame := []byte{0xFF, 0xD8, byte(i), 0xFF, 0xD9}
+		d.Write(frame)
+		select {
+		case out := <-got:
+			if !bytes.Equal(out, frame) {
+				t.Fatalf("frame %d = %x, want %x", i, out, frame)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for frame %d", i)
+		}
+	}
+
+	if restarts := d.GetStats().Restarts; restarts != 0 {
+		t.Errorf("decoder restarted %d times, want a single long-lived process", restarts)
+	}
+}
+
+func TestStreamDecoder_Reconfigure(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	cfg := DefaultDecoderConfig()
+	cfg.Command = "cat"
+	cfg.Args = []string{}
+	d := NewStreamDecoder(cfg, nil)
+
+	got := make(chan []byte, 16)
+	d.OnFrame(func(f Frame) { got <- f.Data })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// roundTrip writes until a frame comes back from a running process
+	roundTrip := func() {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			d.Write([]byte{0xFF, 0xD8, 0xAA, 0xFF, 0xD9})
+			select {
+			case <-got:
+				return
+			case <-time.After(20 * time.Millisecond):
+				if time.Now().After(deadline) {
+					t.Fatal("timeout waiting for decoder")
+				}
+			}
+		}
+	}
+
+	roundTrip()
+	cfg.Framerate = 5
+	d.Reconfigure(cfg)
+	roundTrip()
+
+	if restarts := d.GetStats().Restarts; restarts != 0 {
+		t.Errorf("reconfigure counted as %d restarts, want 0", restarts)
+	}
+}
+
+func TestDecoderConfig_Filter(t *testing.T) {
+	cfg := DefaultDecoderConfig()
+	if got := cfg.filter(); got != "fps=10" {
+		t.Errorf("filter() = %q, want fps=10", got)
+	}
+
+	cfg.Width, cfg.Height = 320, 240
+	if got := cfg.filter(); got != "fps=10,scale=320:240:force_original_aspect_ratio=decrease" {
+		t.Errorf("unexpected filter %q", got)
+	}
+}
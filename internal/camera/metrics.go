@@ -0,0 +1,33 @@
+package camera
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers camera client collectors
+func (c *Client) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_frames_total",
+			Help: "Frames captured from the robot camera",
+		}, func() float64 { return float64(c.framesCaptured.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_errors_total",
+			Help: "Camera connection and frame errors",
+		}, func() float64 { return float64(c.frameErrors.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_camera_connected",
+			Help: "WebRTC camera connection state (1=connected, 0=disconnected)",
+		}, func() float64 { return boolToFloat(c.Stats().Connected) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_camera_decoder_restarts_total",
+			Help: "Restarts of the streaming H264 decoder process",
+		}, func() float64 { return float64(c.Stats().Decoder.Restarts) }),
+	)
+}
+
+// boolToFloat converts a flag to 1 or 0 for gauges
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package camera
+
+import (
+	"fmt"
+	"image"
+)
+
+// Settings are the capture settings that can change while running
+type Settings struct {
+	Framerate int `json:"framerate"`
+	Width     int `json:"width"` // Frames are scaled to fit Width×Height, keeping aspect
+	Height    int `json:"height"`
+	Quality   int `json:"quality"` // JPEG quality (1-100)
+}
+
+// Presets are named settings the cloud can request
+var Presets = map[string]Settings{
+	"low":    {Framerate: 5, Width: 320, Height: 240, Quality: 60},
+	"medium": {Framerate: 10, Width: 640, Height: 480, Quality: 80},
+	"high":   {Framerate: 15, Width: 1280, Height: 720, Quality: 90},
+}
+
+// Settings returns the current capture settings
+func (c *Client) Settings() Settings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Settings{
+		Framerate: c.cfg.Framerate,
+		Width:     c.cfg.Width,
+		Height:    c.cfg.Height,
+		Quality:   c.cfg.Quality,
+	}
+}
+
+// Reconfigure applies new settings without restarting capture. The preset
+// (if any) is applied first; non-zero fields of s override it. Returns the
+// settings in effect afterwards.
+func (c *Client) Reconfigure(preset string, s Settings) (Settings, error) {
+	next := c.Settings()
+	if preset != "" {
+		p, ok := Presets[preset]
+		if !ok {
+			return next, fmt.Errorf("unknown camera preset %q", preset)
+		}
+		next = p
+	}
+	if s.Framerate != 0 {
+		next.Framerate = s.Framerate
+	}
+	if s.Width != 0 || s.Height != 0 {
+		next.Width, next.Height = s.Width, s.Height
+	}
+	if s.Quality != 0 {
+		next.Quality = s.Quality
+	}
+
+	if next.Framerate < 1 || next.Framerate > 60 {
+		return c.Settings(), fmt.Errorf("framerate must be between 1 and 60, got %d", next.Framerate)
+	}
+	if next.Width < 16 || next.Height < 16 || next.Width > 1920 || next.Height > 1080 {
+		return c.Settings(), fmt.Errorf("resolution must be between 16x16 and 1920x1080, got %dx%d", next.Width, next.Height)
+	}
+	if next.Quality < 1 || next.Quality > 100 {
+		return c.Settings(), fmt.Errorf("quality must be between 1 and 100, got %d", next.Quality)
+	}
+
+	c.mu.Lock()
+	c.cfg.Framerate = next.Framerate
+	c.cfg.Width = next.Width
+	c.cfg.Height = next.Height
+	c.cfg.Quality = next.Quality
+	webrtc := c.webrtc
+	c.mu.Unlock()
+
+	if webrtc != nil {
+		webrtc.SetFramerate(next.Framerate)
+		webrtc.SetOutput(next.Width, next.Height, next.Quality)
+	}
+	c.logger.Info("camera reconfigured",
+		"framerate", next.Framerate,
+		"resolution", fmt.Sprintf("%dx%d", next.Width, next.Height),
+		"quality", next.Quality,
+	)
+	return next, nil
+}
+
+// fitImage scales img down (nearest neighbor) to fit within width×height,
+// keeping its aspect ratio
+func fitImage(img image.Image, width, height int) image.Image {
+	b := img.Bounds()
+	if width <= 0 || height <= 0 || (b.Dx() <= width && b.Dy() <= height) {
+		return img
+	}
+
+	w, h := width, b.Dy()*width/b.Dx()
+	if h > height {
+		w, h = b.Dx()*height/b.Dy(), height
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,95 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"time"
+)
+
+// captureFrame fetches a single JPEG from Pollen's snapshot endpoint,
+// re-encoded into a pooled buffer the caller must Release
+func (c *Client) captureFrame(ctx context.Context) (*Frame, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.PollenURL+"/api/video/snapshot", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("snapshot returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	settings := c.Settings()
+	img = fitImage(img, settings.Width, settings.Height)
+	frame, err := encodeFrame(img, settings.Quality)
+	if err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	frame.Timestamp = time.Now()
+	frame.FrameID = c.frameSeq.Add(1)
+	return &frame, nil
+}
+
+// reencodeJPEG encodes img as JPEG at the given quality
+func (c *Client) reencodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// snapshotLoop polls the snapshot endpoint at the target frame rate while
+// WebRTC is not delivering frames
+func (c *Client) snapshotLoop(ctx context.Context) {
+	for {
+		fps := c.Framerate()
+		if fps <= 0 {
+			fps = 1
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second / time.Duration(fps)):
+		}
+
+		c.mu.RLock()
+		webrtc := c.webrtc
+		c.mu.RUnlock()
+		if webrtc != nil && webrtc.IsConnected() {
+			continue
+		}
+
+		frame, err := c.captureFrame(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.frameErrors.Add(1)
+				c.logger.Debug("snapshot fallback failed", "error", err)
+			}
+			continue
+		}
+		c.snapshots.Add(1)
+		c.publish(*frame)
+	}
+}
@@ -3,12 +3,14 @@ package camera
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"image/jpeg"
 	"log/slog"
-	"os/exec"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -29,19 +31,25 @@ type WebRTCClient struct {
 	producerID string
 	sessionID  string
 
-	// Latest decoded frame
-	latestFrame []byte
-	frameMutex  sync.RWMutex
-	frameReady  chan struct{}
-	frameID     uint64
+	// Decoded frame sequence
+	frameMutex sync.RWMutex
+	frameReady chan struct{}
+	frameID    uint64
 
-	// Rate limiting for decoding
+	// Streaming H264 decoder (guarded by frameMutex)
+	decoder    *StreamDecoder
+	decoderCfg DecoderConfig
+
+	// Rate limiting for published frames
 	lastDecode  time.Time
 	minInterval time.Duration
 	decodeMutex sync.Mutex
 
 	// Callbacks
 	onFrame func(Frame)
+	onAudio func([]byte) // Opus packets from the robot mic track (nil = don't negotiate audio)
+
+	audioPackets atomic.Uint64
 
 	connected bool
 	closed    bool
@@ -58,18 +66,68 @@ func NewWebRTCClient(robotIP string, logger *slog.Logger) *WebRTCClient {
 		signallingURL: fmt.Sprintf("ws://%s:8443", robotIP),
 		logger:        logger,
 		frameReady:    make(chan struct{}, 1),
+		decoderCfg:    DefaultDecoderConfig(),
 		minInterval:   100 * time.Millisecond, // 10 FPS max decode rate
 		lastDecode:    time.Now(),
 	}
 }
 
-// OnFrame sets the callback for new frames
+// SetFramerate changes the maximum rate frames are published at
+func (c *WebRTCClient) SetFramerate(fps int) {
+	if fps <= 0 {
+		return
+	}
+	c.decodeMutex.Lock()
+	c.minInterval = time.Second / time.Duration(fps)
+	c.decodeMutex.Unlock()
+
+	c.configureDecoder(func(cfg *DecoderConfig) { cfg.Framerate = fps })
+}
+
+// SetOutput changes the decoded frame size (0 = stream resolution) and JPEG
+// quality (1-100)
+func (c *WebRTCClient) SetOutput(width, height, quality int) {
+	c.configureDecoder(func(cfg *DecoderConfig) {
+		cfg.Width, cfg.Height = width, height
+		if quality > 0 {
+			cfg.Quality = ffmpegQuality(quality)
+		}
+	})
+}
+
+// configureDecoder updates the decoder settings, restarting a running
+// decoder only if they changed
+func (c *WebRTCClient) configureDecoder(update func(*DecoderConfig)) {
+	c.frameMutex.Lock()
+	old := c.decoderCfg
+	update(&c.decoderCfg)
+	cfg := c.decoderCfg
+	decoder := c.decoder
+	c.frameMutex.Unlock()
+
+	changed := old.Framerate != cfg.Framerate || old.Quality != cfg.Quality ||
+		old.Width != cfg.Width || old.Height != cfg.Height
+	if decoder != nil && changed {
+		decoder.Reconfigure(cfg)
+	}
+}
+
+// OnFrame sets the callback for new frames, which takes over each frame's
+// buffer reference
 func (c *WebRTCClient) OnFrame(callback func(Frame)) {
 	c.frameMutex.Lock()
 	c.onFrame = callback
 	c.frameMutex.Unlock()
 }
 
+// OnAudio negotiates the producer's audio track on the next Connect and
+// hands each Opus packet to callback
+func (c *WebRTCClient) OnAudio(callback func(packet []byte)) {
+	c.frameMutex.Lock()
+	c.onAudio = callback
+	c.frameMutex.Unlock()
+}
+
 // Connect establishes the WebRTC connection
 func (c *WebRTCClient) Connect() error {
 	c.logger.Info("connecting to WebRTC signalling", "url", c.signallingURL)
@@ -196,11 +254,26 @@ func (c *WebRTCClient) createPeerConnection() error {
 		return err
 	}
 
-	// Handle incoming video tracks
+	// And the mic, if someone listens
+	c.frameMutex.RLock()
+	wantAudio := c.onAudio != nil
+	c.frameMutex.RUnlock()
+	if wantAudio {
+		if _, err = c.pc.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio, webrtc.RTPTransceiverInit{
+			Direction: webrtc.RTPTransceiverDirectionRecvonly,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Handle incoming tracks
 	c.pc.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
 		c.logger.Debug("got track", "kind", track.Kind().String(), "codec", track.Codec().MimeType)
-		if track.Kind() == webrtc.RTPCodecTypeVideo {
+		switch track.Kind() {
+		case webrtc.RTPCodecTypeVideo:
 			go c.handleVideoTrack(track)
+		case webrtc.RTPCodecTypeAudio:
+			go c.handleAudioTrack(track)
 		}
 	})
 
@@ -357,12 +430,37 @@ func (c *WebRTCClient) handleVideoTrack(track *webrtc.TrackRemote) {
 	default:
 	}
 
-	// H264 depacketizer
-	var h264Buffer bytes.Buffer
-	var frameBuffer bytes.Buffer
-	hasKeyframe := false
-	var keyframeBuffer bytes.Buffer
-	frameCount := 0
+	// One long-lived decoder per track; RTP payloads are streamed into it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c.frameMutex.Lock()
+	decoder := NewStreamDecoder(c.decoderCfg, c.logger)
+	c.decoder = decoder
+	c.frameMutex.Unlock()
+
+	decoder.OnFrame(c.handleDecodedFrame)
+	go decoder.Run(ctx)
+
+	// H264 depacketizer (RFC 6184) producing an Annex-B stream
+	startCode := []byte{0x00, 0x00, 0x00, 0x01}
+	var nal bytes.Buffer
+	synced := false // Wait for SPS/IDR so the decoder starts cleanly
+
+	writeNAL := func(unit []byte) {
+		if len(unit) == 0 {
+			return
+		}
+		nalType := unit[0] & 0x1F
+		if !synced && (nalType == 5 || nalType == 7) {
+			synced = true
+		}
+		if !synced {
+			return
+		}
+		decoder.Write(startCode)
+		decoder.Write(unit)
+	}
 
 	for !c.closed {
 		rtpPacket, _, err := track.ReadRTP()
@@ -381,34 +479,22 @@ func (c *WebRTCClient) handleVideoTrack(track *webrtc.TrackRemote) {
 		switch {
 		case nalType >= 1 && nalType <= 23:
 			// Single NAL unit
-			h264Buffer.Write([]byte{0x00, 0x00, 0x00, 0x01})
-			h264Buffer.Write(payload)
-			if nalType == 5 || nalType == 7 || nalType == 8 {
-				hasKeyframe = true
-			}
+			writeNAL(payload)
 
 		case nalType == 28: // FU-A (Fragmentation Unit)
 			fuHeader := payload[1]
 			startBit := (fuHeader & 0x80) != 0
 			endBit := (fuHeader & 0x40) != 0
-			fragNalType := fuHeader & 0x1F
 
 			if startBit {
-				h264Buffer.Write([]byte{0x00, 0x00, 0x00, 0x01})
-				h264Buffer.WriteByte((payload[0] & 0xE0) | fragNalType)
-				if fragNalType == 5 {
-					hasKeyframe = true
-				}
+				nal.Reset()
+				nal.WriteByte((payload[0] & 0xE0) | (fuHeader & 0x1F))
 			}
-			h264Buffer.Write(payload[2:])
-
-			if endBit {
-				frameBuffer.Write(h264Buffer.Bytes())
-				h264Buffer.Reset()
-				if hasKeyframe {
-					keyframeBuffer.Reset()
-					keyframeBuffer.Write(frameBuffer.Bytes())
-				}
+			nal.Write(payload[2:])
+
+			if endBit && nal.Len() > 0 {
+				writeNAL(nal.Bytes())
+				nal.Reset()
 			}
 
 		case nalType == 24: // STAP-A
@@ -419,113 +505,94 @@ func (c *WebRTCClient) handleVideoTrack(track *webrtc.TrackRemote) {
 				if offset+nalSize > len(payload) {
 					break
 				}
-				h264Buffer.Write([]byte{0x00, 0x00, 0x00, 0x01})
-				h264Buffer.Write(payload[offset : offset+nalSize])
-				if nalSize > 0 {
-					aggNalType := payload[offset] & 0x1F
-					if aggNalType == 5 || aggNalType == 7 || aggNalType == 8 {
-						hasKeyframe = true
-					}
-				}
+				writeNAL(payload[offset : offset+nalSize])
 				offset += nalSize
 			}
 		}
-
-		// Decode when we have a keyframe and rate limit allows
-		if hasKeyframe && keyframeBuffer.Len() > 1000 {
-			c.decodeMutex.Lock()
-			if time.Since(c.lastDecode) >= c.minInterval {
-				c.lastDecode = time.Now()
-				c.decodeMutex.Unlock()
-
-				jpegData := c.decodeH264ToJPEG(keyframeBuffer.Bytes())
-				if len(jpegData) > 1000 {
-					c.frameID++
-					frame := Frame{
-						Data:      jpegData,
-						Width:     640, // Will be updated from actual decode
-						Height:    480,
-						Timestamp: time.Now(),
-						FrameID:   c.frameID,
-					}
-
-					c.frameMutex.Lock()
-					c.latestFrame = jpegData
-					callback := c.onFrame
-					c.frameMutex.Unlock()
-
-					if callback != nil {
-						callback(frame)
-					}
-
-					frameCount++
-					if frameCount%100 == 1 {
-						c.logger.Debug("decoded frame", "count", frameCount, "size", len(jpegData))
-					}
-				}
-
-				frameBuffer.Reset()
-				if frameCount%30 == 0 {
-					hasKeyframe = false
-				}
-			} else {
-				c.decodeMutex.Unlock()
-			}
-		}
 	}
 }
 
-func (c *WebRTCClient) decodeH264ToJPEG(h264Data []byte) []byte {
-	if len(h264Data) < 100 {
-		return nil
+// handleAudioTrack passes Opus packets to the audio callback. RTP carries
+// exactly one Opus packet per payload (RFC 7587), so depacketizing is just
+// taking the payload.
+func (c *WebRTCClient) handleAudioTrack(track *webrtc.TrackRemote) {
+	if !strings.EqualFold(track.Codec().MimeType, webrtc.MimeTypeOpus) {
+		c.logger.Warn("ignoring audio track", "codec", track.Codec().MimeType)
+		return
 	}
 
-	// Use ffmpeg pipe-based decoding
-	cmd := exec.Command("ffmpeg",
-		"-f", "h264",
-		"-i", "pipe:0",
-		"-vframes", "1",
-		"-f", "image2pipe",
-		"-vcodec", "mjpeg",
-		"-q:v", "3",
-		"pipe:1",
-	)
+	c.frameMutex.RLock()
+	callback := c.onAudio
+	c.frameMutex.RUnlock()
 
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+	for !c.closed {
+		rtpPacket, _, err := track.ReadRTP()
+		if err != nil {
+			return
+		}
+		if len(rtpPacket.Payload) == 0 || callback == nil {
+			continue
+		}
+		c.audioPackets.Add(1)
+		callback(rtpPacket.Payload)
+	}
+}
 
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return nil
+// AudioPackets returns how many Opus packets the audio track delivered
+func (c *WebRTCClient) AudioPackets() uint64 {
+	return c.audioPackets.Load()
+}
+
+// handleDecodedFrame rate-limits and publishes a JPEG from the stream decoder
+func (c *WebRTCClient) handleDecodedFrame(frame Frame) {
+	c.decodeMutex.Lock()
+	if time.Since(c.lastDecode) < c.minInterval {
+		c.decodeMutex.Unlock()
+		frame.Release()
+		return
 	}
+	c.lastDecode = time.Now()
+	c.decodeMutex.Unlock()
 
-	if err := cmd.Start(); err != nil {
-		return nil
+	if len(frame.Data) <= 1000 || c.isGrayFrame(frame.Data) {
+		frame.Release()
+		return
 	}
 
-	go func() {
-		stdin.Write(h264Data)
-		stdin.Close()
-	}()
+	frame.Width, frame.Height = 640, 480
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(frame.Data)); err == nil {
+		frame.Width, frame.Height = cfg.Width, cfg.Height
+	}
+	frame.Timestamp = time.Now()
+	size := len(frame.Data)
 
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Wait()
-	}()
+	c.frameMutex.Lock()
+	c.frameID++
+	frame.FrameID = c.frameID
+	callback := c.onFrame
+	c.frameMutex.Unlock()
 
-	select {
-	case <-done:
-	case <-time.After(200 * time.Millisecond):
-		cmd.Process.Kill()
-		return nil
+	if callback != nil {
+		callback(frame)
+	} else {
+		frame.Release()
 	}
 
-	jpegData := stdout.Bytes()
-	if len(jpegData) > 1000 && !c.isGrayFrame(jpegData) {
-		return jpegData
+	if frame.FrameID%100 == 1 {
+		c.logger.Debug("decoded frame", "count", frame.FrameID, "size", size)
 	}
+}
 
-	return nil
+// DecoderStats returns statistics for the active stream decoder
+func (c *WebRTCClient) DecoderStats() DecoderStats {
+	c.frameMutex.RLock()
+	decoder := c.decoder
+	c.frameMutex.RUnlock()
+
+	if decoder == nil {
+		return DecoderStats{}
+	}
+	return decoder.GetStats()
 }
 
 func (c *WebRTCClient) isGrayFrame(jpegData []byte) bool {
@@ -570,20 +637,6 @@ func (c *WebRTCClient) isGrayFrame(jpegData []byte) bool {
 	return false
 }
 
-// GetFrame returns the latest video frame as JPEG bytes
-func (c *WebRTCClient) GetFrame() ([]byte, error) {
-	c.frameMutex.RLock()
-	defer c.frameMutex.RUnlock()
-
-	if c.latestFrame == nil {
-		return nil, fmt.Errorf("no frame available")
-	}
-
-	frame := make([]byte, len(c.latestFrame))
-	copy(frame, c.latestFrame)
-	return frame, nil
-}
-
 // IsConnected returns true if WebRTC is connected
 func (c *WebRTCClient) IsConnected() bool {
 	return c.connected && !c.closed
@@ -0,0 +1,396 @@
+// Package choreo plays scripted head, antenna and body motion on the robot.
+//
+// A sequence is a list of keyframes, each a pose to reach after a duration
+// with an easing curve. The player interpolates between them locally at a
+// fixed rate, so the cloud can send a whole nod or dance in one message
+// instead of streaming motor commands over a laggy link.
+package choreo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// MaxKeyframes bounds the size of a sequence
+const MaxKeyframes = 256
+
+// Easing shapes the motion between two keyframes
+type Easing string
+
+const (
+	EaseLinear Easing = "linear"
+	EaseIn     Easing = "ease_in"
+	EaseOut    Easing = "ease_out"
+	EaseInOut  Easing = "ease_in_out"
+	EaseStep   Easing = "step" // Hold the previous pose, then jump at the end
+)
+
+// defaultEasing applies to keyframes that don't name one
+const defaultEasing = EaseInOut
+
+// apply maps linear progress t (0-1) to eased progress
+func (e Easing) apply(t float64) float64 {
+	switch e {
+	case EaseLinear:
+		return t
+	case EaseIn:
+		return t * t
+	case EaseOut:
+		return t * (2 - t)
+	case EaseStep:
+		if t < 1 {
+			return 0
+		}
+		return 1
+	default: // EaseInOut
+		return t * t * (3 - 2*t)
+	}
+}
+
+// Pose is a full-body target (radians; head X/Y/Z in meters)
+type Pose struct {
+	Head     pollen.HeadTarget `json:"head" yaml:"head"`
+	Antennas [2]float64        `json:"antennas" yaml:"antennas"`
+	BodyYaw  float64           `json:"body_yaw" yaml:"body_yaw"`
+}
+
+// lerp returns the pose a fraction t of the way from a to b
+func lerp(a, b Pose, t float64) Pose {
+	mix := func(x, y float64) float64 { return x + (y-x)*t }
+	return Pose{
+		Head: pollen.HeadTarget{
+			X:     mix(a.Head.X, b.Head.X),
+			Y:     mix(a.Head.Y, b.Head.Y),
+			Z:     mix(a.Head.Z, b.Head.Z),
+			Roll:  mix(a.Head.Roll, b.Head.Roll),
+			Pitch: mix(a.Head.Pitch, b.Head.Pitch),
+			Yaw:   mix(a.Head.Yaw, b.Head.Yaw),
+		},
+		Antennas: [2]float64{mix(a.Antennas[0], b.Antennas[0]), mix(a.Antennas[1], b.Antennas[1])},
+		BodyYaw:  mix(a.BodyYaw, b.BodyYaw),
+	}
+}
+
+// Keyframe is a pose reached DurationMs after the previous one
+type Keyframe struct {
+	Pose       `yaml:",inline"`
+	DurationMs int    `json:"duration_ms" yaml:"duration_ms"`
+	Easing     Easing `json:"easing,omitempty" yaml:"easing,omitempty"` // Default ease_in_out
+}
+
+// Sequence is a named list of keyframes, played Loops times (0 = once).
+// The first keyframe is approached from wherever the head was; later loops
+// start from the last keyframe.
+type Sequence struct {
+	Name      string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Loops     int        `json:"loops,omitempty" yaml:"loops,omitempty"`
+	Keyframes []Keyframe `json:"keyframes" yaml:"keyframes"`
+}
+
+// Parse decodes a JSON or YAML sequence and validates it
+func Parse(data []byte) (Sequence, error) {
+	var seq Sequence
+	// YAML is a superset of JSON, so one decoder takes both
+	if err := yaml.Unmarshal(data, &seq); err != nil {
+		return Sequence{}, fmt.Errorf("parse sequence: %w", err)
+	}
+	if err := seq.Validate(); err != nil {
+		return Sequence{}, err
+	}
+	return seq, nil
+}
+
+// Validate checks keyframe count, durations, easings and values
+func (s Sequence) Validate() error {
+	if len(s.Keyframes) == 0 {
+		return errors.New("sequence has no keyframes")
+	}
+	if len(s.Keyframes) > MaxKeyframes {
+		return fmt.Errorf("sequence has %d keyframes, max %d", len(s.Keyframes), MaxKeyframes)
+	}
+	if s.Loops < 0 {
+		return fmt.Errorf("loops must not be negative, got %d", s.Loops)
+	}
+	for i, k := range s.Keyframes {
+		if k.DurationMs < 0 {
+			return fmt.Errorf("keyframe %d: duration_ms must not be negative, got %d", i, k.DurationMs)
+		}
+		switch k.Easing {
+		case "", EaseLinear, EaseIn, EaseOut, EaseInOut, EaseStep:
+		default:
+			return fmt.Errorf("keyframe %d: unknown easing %q", i, k.Easing)
+		}
+		h := k.Head
+		for _, v := range []float64{h.X, h.Y, h.Z, h.Roll, h.Pitch, h.Yaw, k.Antennas[0], k.Antennas[1], k.BodyYaw} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("keyframe %d: targets must be finite numbers", i)
+			}
+		}
+	}
+	if s.Duration() <= 0 {
+		return errors.New("sequence duration must be positive")
+	}
+	return nil
+}
+
+// cycle returns the duration of one pass through the keyframes
+func (s Sequence) cycle() time.Duration {
+	var d time.Duration
+	for _, k := range s.Keyframes {
+		d += time.Duration(k.DurationMs) * time.Millisecond
+	}
+	return d
+}
+
+// Duration returns how long the sequence plays, including loops
+func (s Sequence) Duration() time.Duration {
+	return s.cycle() * time.Duration(max(s.Loops, 1))
+}
+
+// At returns the pose elapsed into the sequence when it starts from start
+func (s Sequence) At(start Pose, elapsed time.Duration) Pose {
+	cycle := s.cycle()
+	last := s.Keyframes[len(s.Keyframes)-1].Pose
+	if cycle <= 0 || elapsed >= s.Duration() {
+		return last
+	}
+
+	from := start
+	if elapsed >= cycle {
+		from = last
+		elapsed %= cycle
+	}
+	for _, k := range s.Keyframes {
+		d := time.Duration(k.DurationMs) * time.Millisecond
+		if elapsed < d {
+			easing := k.Easing
+			if easing == "" {
+				easing = defaultEasing
+			}
+			return lerp(from, k.Pose, easing.apply(float64(elapsed)/float64(d)))
+		}
+		elapsed -= d
+		from = k.Pose
+	}
+	return last
+}
+
+// Mover sends full-body targets (implemented by pollen.Limiter and
+// pollen.Client)
+type Mover interface {
+	SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+}
+
+// MoverFunc adapts a function to Mover
+type MoverFunc func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+
+// SetTarget calls f
+func (f MoverFunc) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	return f(ctx, head, antennas, bodyYaw)
+}
+
+// Config holds player configuration
+type Config struct {
+	Rate        time.Duration // Frame interval
+	MaxDuration time.Duration // Longest sequence accepted, including loops
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Rate:        time.Second / 30,
+		MaxDuration: 2 * time.Minute,
+	}
+}
+
+// Status describes the sequence playing
+type Status struct {
+	Playing    bool   `json:"playing"`
+	Name       string `json:"name,omitempty"`
+	ElapsedMs  int64  `json:"elapsed_ms,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+// Stats contains player statistics
+type Stats struct {
+	Played      uint64 `json:"played"`
+	Completed   uint64 `json:"completed"`
+	Interrupted uint64 `json:"interrupted"` // Replaced, stopped, or cut off by the emergency stop
+	Frames      uint64 `json:"frames"`
+	Errors      uint64 `json:"errors"`
+}
+
+// Player interpolates one sequence at a time and sends each frame to a
+// Mover. A new sequence replaces the one playing, starting from wherever
+// the head got to.
+type Player struct {
+	cfg    Config
+	mover  Mover
+	logger *slog.Logger
+	wake   chan struct{}
+
+	mu      sync.Mutex
+	seq     *Sequence
+	start   Pose // Pose the sequence started from
+	started time.Time
+	last    Pose // Last frame sent
+
+	played      atomic.Uint64
+	completed   atomic.Uint64
+	interrupted atomic.Uint64
+	frames      atomic.Uint64
+	errors      atomic.Uint64
+}
+
+// New creates a player sending frames to mover
+func New(cfg Config, mover Mover, logger *slog.Logger) *Player {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultConfig().Rate
+	}
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = DefaultConfig().MaxDuration
+	}
+	return &Player{
+		cfg:    cfg,
+		mover:  mover,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Play starts seq, replacing any sequence playing
+func (p *Player) Play(seq Sequence) error {
+	if err := seq.Validate(); err != nil {
+		return err
+	}
+	if d := seq.Duration(); d > p.cfg.MaxDuration {
+		return fmt.Errorf("sequence lasts %v, max %v", d, p.cfg.MaxDuration)
+	}
+
+	p.mu.Lock()
+	if p.seq != nil {
+		p.interrupted.Add(1)
+	}
+	p.seq = &seq
+	p.start = p.last
+	p.started = time.Now()
+	p.mu.Unlock()
+
+	p.played.Add(1)
+	p.logger.Info("choreography started", "name", seq.Name, "keyframes", len(seq.Keyframes), "duration", seq.Duration())
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stop ends the sequence playing, leaving the head where it is
+func (p *Player) Stop() {
+	p.mu.Lock()
+	stopped := p.seq != nil
+	p.seq = nil
+	p.mu.Unlock()
+
+	if stopped {
+		p.interrupted.Add(1)
+	}
+}
+
+// Run sends frames until ctx is cancelled
+func (p *Player) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+		p.step(ctx, time.Now())
+	}
+}
+
+// step sends the frame due at now, if a sequence is playing
+func (p *Player) step(ctx context.Context, now time.Time) {
+	p.mu.Lock()
+	seq := p.seq
+	if seq == nil {
+		p.mu.Unlock()
+		return
+	}
+	elapsed := now.Sub(p.started)
+	pose := seq.At(p.start, elapsed)
+	done := elapsed >= seq.Duration()
+	p.last = pose
+	if done {
+		p.seq = nil
+	}
+	p.mu.Unlock()
+
+	err := p.mover.SetTarget(ctx, pose.Head, pose.Antennas, pose.BodyYaw)
+	switch {
+	case errors.Is(err, pollen.ErrStopped):
+		p.mu.Lock()
+		if p.seq == seq {
+			p.seq = nil
+			p.interrupted.Add(1)
+		}
+		p.mu.Unlock()
+		p.logger.Info("choreography cut off by emergency stop", "name", seq.Name)
+		return
+	case err != nil:
+		p.errors.Add(1)
+		p.logger.Debug("choreography frame failed", "name", seq.Name, "error", err)
+	default:
+		p.frames.Add(1)
+	}
+
+	if done {
+		p.completed.Add(1)
+		p.logger.Debug("choreography finished", "name", seq.Name)
+	}
+}
+
+// Status returns the sequence playing
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.seq == nil {
+		return Status{}
+	}
+	return Status{
+		Playing:    true,
+		Name:       p.seq.Name,
+		ElapsedMs:  time.Since(p.started).Milliseconds(),
+		DurationMs: p.seq.Duration().Milliseconds(),
+	}
+}
+
+// GetStats returns player statistics
+func (p *Player) GetStats() Stats {
+	return Stats{
+		Played:      p.played.Load(),
+		Completed:   p.completed.Load(),
+		Interrupted: p.interrupted.Load(),
+		Frames:      p.frames.Load(),
+		Errors:      p.errors.Load(),
+	}
+}
@@ -0,0 +1,162 @@
+package choreo
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+const nodYAML = `
+name: nod
+loops: 2
+keyframes:
+  - duration_ms: 200
+    easing: linear
+    head: {pitch: 0.4}
+    antennas: [0.5, -0.5]
+  - duration_ms: 200
+    head: {pitch: 0}
+`
+
+func TestParse(t *testing.T) {
+	seq, err := Parse([]byte(nodYAML))
+	if err != nil {
+		t.Fatalf("Parse(yaml) error = %v", err)
+	}
+	if seq.Name != "nod" || len(seq.Keyframes) != 2 || seq.Keyframes[0].Head.Pitch != 0.4 || seq.Keyframes[0].Antennas[1] != -0.5 {
+		t.Errorf("Parse(yaml) = %+v", seq)
+	}
+	if d := seq.Duration(); d != 800*time.Millisecond {
+		t.Errorf("Duration() = %v, want 800ms", d)
+	}
+
+	json := `{"keyframes": [{"duration_ms": 300, "easing": "step", "body_yaw": 0.2, "head": {"yaw": -0.3}}]}`
+	seq, err = Parse([]byte(json))
+	if err != nil {
+		t.Fatalf("Parse(json) error = %v", err)
+	}
+	if seq.Keyframes[0].BodyYaw != 0.2 || seq.Keyframes[0].Head.Yaw != -0.3 || seq.Keyframes[0].Easing != EaseStep {
+		t.Errorf("Parse(json) = %+v", seq)
+	}
+
+	for name, bad := range map[string]string{
+		"no keyframes":      `{"keyframes": []}`,
+		"negative duration": `{"keyframes": [{"duration_ms": -1}]}`,
+		"zero length":       `{"keyframes": [{"duration_ms": 0}]}`,
+		"unknown easing":    `{"keyframes": [{"duration_ms": 100, "easing": "bounce"}]}`,
+		"not a sequence":    `[1, 2`,
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("%s: Parse() accepted %s", name, bad)
+		}
+	}
+}
+
+func TestSequence_At(t *testing.T) {
+	seq, _ := Parse([]byte(nodYAML))
+	start := Pose{Head: pollen.HeadTarget{Pitch: -0.2}}
+
+	tests := []struct {
+		elapsed time.Duration
+		pitch   float64
+	}{
+		{0, -0.2},
+		{100 * time.Millisecond, 0.1}, // Linear, halfway from start
+		{200 * time.Millisecond, 0.4},
+		{300 * time.Millisecond, 0.2}, // Ease in-out is symmetric at the midpoint
+		{500 * time.Millisecond, 0.2}, // Second loop starts from the last keyframe
+		{time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := seq.At(start, tt.elapsed).Head.Pitch; math.Abs(got-tt.pitch) > 1e-9 {
+			t.Errorf("At(%v) pitch = %v, want %v", tt.elapsed, got, tt.pitch)
+		}
+	}
+}
+
+// recorder is a Mover that records frames and can refuse them
+type recorder struct {
+	mu      sync.Mutex
+	frames  []Pose
+	stopped bool
+}
+
+func (r *recorder) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return pollen.ErrStopped
+	}
+	r.frames = append(r.frames, Pose{Head: head, Antennas: antennas, BodyYaw: bodyYaw})
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.frames)
+}
+
+func TestPlayer_PlaysToCompletion(t *testing.T) {
+	mover := &recorder{}
+	p := New(Config{Rate: 10 * time.Millisecond}, mover, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	seq, _ := Parse([]byte(nodYAML))
+	if err := p.Play(seq); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if s := p.Status(); !s.Playing || s.Name != "nod" || s.DurationMs != 800 {
+		t.Errorf("Status() = %+v", s)
+	}
+
+	time.Sleep(time.Second)
+	if s := p.Status(); s.Playing {
+		t.Errorf("still playing after the sequence ended: %+v", s)
+	}
+	n := mover.count()
+	if n < 40 {
+		t.Errorf("sent %d frames for 800ms at 100Hz", n)
+	}
+	if last := mover.frames[n-1]; last.Head.Pitch != 0 || last.Antennas != [2]float64{} {
+		t.Errorf("last frame = %+v, want the final keyframe", last)
+	}
+	if st := p.GetStats(); st.Played != 1 || st.Completed != 1 || st.Errors != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	long := Sequence{Loops: 1000, Keyframes: seq.Keyframes}
+	if err := p.Play(long); err == nil {
+		t.Error("Play() accepted a sequence longer than MaxDuration")
+	}
+}
+
+func TestPlayer_EmergencyStopEndsSequence(t *testing.T) {
+	mover := &recorder{}
+	p := New(Config{Rate: 10 * time.Millisecond}, mover, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	seq, _ := Parse([]byte(nodYAML))
+	p.Play(seq)
+	time.Sleep(50 * time.Millisecond)
+
+	mover.mu.Lock()
+	mover.stopped = true
+	mover.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	if p.Status().Playing {
+		t.Error("sequence kept playing through the emergency stop")
+	}
+	if st := p.GetStats(); st.Interrupted != 1 || st.Completed != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
@@ -3,57 +3,191 @@ package cloud
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/bus"
 	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/trace"
 )
 
 // Config holds cloud client configuration
 type Config struct {
 	URL              string        // WebSocket URL (e.g., "ws://cloud.example.com/ws/robot")
+	URLs             []string      // Endpoints in priority order; replaces URL when set
+	FailoverAfter    time.Duration // Move to the next endpoint after failing to connect for this long
+	PrimaryRetry     time.Duration // While on a fallback endpoint, check the primary this often (0 = stay)
 	ReconnectBackoff time.Duration // Initial reconnect delay
 	MaxBackoff       time.Duration // Maximum reconnect delay
 	PingInterval     time.Duration // Ping interval for keepalive
+	ClockSync        time.Duration // Interval between clock sync pings (0 = off)
+	DOABatch         time.Duration // Send DOA readings in batches over this interval if the cloud accepts them (0 = one message per reading)
 	WriteTimeout     time.Duration // Write timeout
+	RequestTimeout   time.Duration // How long Request waits for an ack when ctx has no deadline
+	BinaryFrames     bool          // Offer binary frame transport (used only if the cloud accepts it)
+	RobotID          string        // Identity sent in the hello and stamped on every outgoing message
+	HardwareSerial   string        // Hardware serial sent in the hello
+	SerialSource     string        // Where HardwareSerial came from (xvf3800 or pi)
+	Firmware         string        // XVF3800 firmware sent in the hello ("" if unknown)
+	Capabilities     []string      // protocol.Capability* flags sent in the hello (binary_frames is added when negotiated)
+	Version          string        // Firmware/daemon version sent in the hello message
+	AudioCodecs      []string      // Audio codecs offered in the hello, preferred first (pcm16 is always accepted)
+	OpusBitrate      int           // Opus bitrate for mic audio (bits/s)
+	SendQueueSize    int           // Outgoing messages buffered for the writer (frames are dropped first)
+	SendRate         int           // Upload budget in bytes/s (0 = unlimited)
+	SendBurst        int           // Bytes that may be sent at once before SendRate applies (0 = one second's worth)
+	StrictProtocol   bool          // Reject cloud messages with fields this build doesn't know instead of warning
+	Compression      CompressionConfig
+	Auth             AuthConfig
+}
+
+// AuthConfig holds cloud credentials (all optional)
+type AuthConfig struct {
+	Token      string // Bearer token sent in the Authorization header
+	HMACSecret string // Shared secret used to sign the hello message
+	CertFile   string // Client certificate for mTLS (PEM)
+	KeyFile    string // Client private key for mTLS (PEM)
+	CAFile     string // CA bundle used to verify the cloud (PEM; system roots if empty)
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() Config {
 	return Config{
 		URL:              "ws://localhost:8080/ws/robot",
+		FailoverAfter:    30 * time.Second,
 		ReconnectBackoff: 1 * time.Second,
 		MaxBackoff:       30 * time.Second,
 		PingInterval:     10 * time.Second,
 		WriteTimeout:     5 * time.Second,
+		RequestTimeout:   5 * time.Second,
+		BinaryFrames:     true,
+		AudioCodecs:      audio.SupportedCodecs(),
+		OpusBitrate:      audio.DefaultOpusBitrate,
+		SendQueueSize:    256,
 	}
 }
 
+// ErrBlocked is returned when the send gate drops an outgoing message
+var ErrBlocked = errors.New("blocked by privacy mode")
+
+// ErrRejected is returned by Request when the cloud replies with a nack
+var ErrRejected = errors.New("rejected by cloud")
+
+// SendGate decides whether outgoing messages may leave the robot
+type SendGate interface {
+	Blocks(msgType protocol.MessageType) bool
+}
+
 // Client manages WebSocket connection to go-reachy cloud
 type Client struct {
 	cfg    Config
 	logger *slog.Logger
 
 	mu        sync.Mutex
+	writeMu   sync.Mutex
 	conn      *websocket.Conn
 	connected bool
+	binary    bool // Binary frame transport negotiated for this connection
+	timed     bool // Binary frames carry capture times (go-eva.binary.v2)
+	deflate   bool // permessage-deflate negotiated for this connection
 	cancel    context.CancelFunc
 
+	// Connection state machine (guarded by mu)
+	state       ConnectionState
+	stateSince  time.Time
+	closed      bool
+	connections int // Connections established so far
+	failures    int // Consecutive failed attempts
+	endpoint    int // Index of the active endpoint (0 = primary)
+
+	cloudProtocol int             // Version from the cloud's hello (0 = not announced, guarded by mu)
+	cloudGzip     bool            // The cloud's hello accepts gzip-compressed messages (guarded by mu)
+	cloudDOABatch bool            // The cloud's hello accepts DOA batches (guarded by mu)
+	warnedFields  map[string]bool // Unknown fields already logged, by type and path (guarded by mu)
+
+	clock *clockSync // Estimate of the cloud's clock
+
+	// DOA readings waiting for the next batch
+	doaMu    sync.Mutex
+	doaBatch []protocol.DOAData
+
 	// Callbacks for incoming messages
 	onMotorCommand   func(protocol.MotorCommand)
 	onEmotionCommand func(protocol.EmotionCommand)
+	onChoreoCommand  func(protocol.ChoreoCommand)
 	onSpeakData      func(protocol.SpeakData)
 	onConfigUpdate   func(protocol.ConfigUpdate)
+	onPrivacy        func(protocol.PrivacyCommand)
+	onStopSpeak      func(protocol.StopSpeakCommand)
+
+	// Callbacks for connection state
+	onConnect     func()
+	onReconnect   func()
+	onDisconnect  func(error)
+	onStateChange func(ConnectionEvent)
+
+	pending map[string]chan protocol.AckData // Requests awaiting an ack, by message ID
+
+	gate  SendGate
+	queue *Queue // Buffers messages while disconnected (nil = drop them)
+
+	// Outgoing messages are written by writeLoop so senders never block on
+	// the uplink
+	sendQueue  *sendQueue
+	bucket     *tokenBucket
+	queueDelay atomic.Int64 // Smoothed send queue delay (ns); see Uplink
+	writing    atomic.Int32 // Messages popped but not yet written
+
+	// Mic audio; the codec is negotiated per connection
+	micMu       sync.Mutex
+	micCodec    string
+	micEnc      *audio.PayloadEncoder
+	micRate     int
+	micChannels int
+	micSeq      uint64
 
 	// Stats
 	messagesSent     atomic.Uint64
+	messagesBlocked  atomic.Uint64
+	binaryFrames     atomic.Uint64
 	messagesReceived atomic.Uint64
 	reconnects       atomic.Uint64
+	authFailures     atomic.Uint64
+	micChunks        atomic.Uint64
+	messagesQueued   atomic.Uint64
+	messagesReplayed atomic.Uint64
+	acksSent         atomic.Uint64
+	nacksSent        atomic.Uint64
+	requestTimeouts  atomic.Uint64
+	sendDropped      atomic.Uint64
+	misrouted        atomic.Uint64
+	schemaErrors     atomic.Uint64
+	unknownFields    atomic.Uint64
+	versionRejected  atomic.Uint64
+	gzipMessages     atomic.Uint64
+	gzipSaved        atomic.Uint64
+	doaBatches       atomic.Uint64
+	doaBatched       atomic.Uint64
+	failovers        atomic.Uint64
+	primaryReturns   atomic.Uint64
+
+	sendLatency     *prometheus.HistogramVec
+	sendDroppedType *prometheus.CounterVec
+	stateGauge      *prometheus.GaugeVec
+	transitions     *prometheus.CounterVec
 }
 
 // NewClient creates a new cloud client
@@ -61,11 +195,75 @@ func NewClient(cfg Config, logger *slog.Logger) *Client {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = DefaultConfig().RequestTimeout
+	}
+	if cfg.SendQueueSize <= 0 {
+		cfg.SendQueueSize = DefaultConfig().SendQueueSize
+	}
+	if cfg.FailoverAfter <= 0 {
+		cfg.FailoverAfter = DefaultConfig().FailoverAfter
+	}
 
-	return &Client{
-		cfg:    cfg,
-		logger: logger,
+	c := &Client{
+		cfg:          cfg,
+		logger:       logger,
+		state:        StateClosed,
+		stateSince:   time.Now(),
+		micCodec:     audio.CodecPCM16,
+		pending:      make(map[string]chan protocol.AckData),
+		warnedFields: make(map[string]bool),
+		clock:        newClockSync(),
+		sendQueue:    newSendQueue(cfg.SendQueueSize),
+		bucket:       newTokenBucket(cfg.SendRate, cfg.SendBurst),
+		sendLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "go_eva_cloud_send_duration_seconds",
+			Help: "Time to write a message to the cloud WebSocket",
+		}, []string{"type"}),
+		sendDroppedType: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_cloud_send_queue_dropped_total",
+			Help: "Outgoing messages dropped from a full send queue",
+		}, []string{"type"}),
+		stateGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_connection_state",
+			Help: "Cloud connection state (1 for the current state)",
+		}, []string{"state"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_cloud_connection_transitions_total",
+			Help: "Cloud connection state transitions by the state entered",
+		}, []string{"state"}),
 	}
+	for _, s := range connectionStates {
+		c.stateGauge.WithLabelValues(string(s)).Set(boolToFloat(s == StateClosed))
+	}
+	return c
+}
+
+// Event bus topics for commands received from the cloud
+var (
+	TopicMotorCommands   = bus.NewTopic[protocol.MotorCommand]("cloud.motor")
+	TopicEmotionCommands = bus.NewTopic[protocol.EmotionCommand]("cloud.emotion")
+	TopicChoreoCommands  = bus.NewTopic[protocol.ChoreoCommand]("cloud.choreo")
+	TopicSpeakData       = bus.NewTopic[protocol.SpeakData]("cloud.speak")
+	TopicPrivacyCommands = bus.NewTopic[protocol.PrivacyCommand]("cloud.privacy")
+	TopicConfigUpdates   = bus.NewTopic[protocol.ConfigUpdate]("cloud.config")
+	TopicStopSpeak       = bus.NewTopic[protocol.StopSpeakCommand]("cloud.stop_speak")
+)
+
+// PublishTo routes received motor, emotion, choreo, speak, stop-speak,
+// privacy and config messages and connection state transitions to the event bus,
+// replacing the corresponding OnX callbacks. Commands are sent with bus.Send,
+// so a busy handler slows the read loop rather than losing a command that
+// would then never be acked or audited.
+func (c *Client) PublishTo(b *bus.Bus) {
+	c.OnStateChange(bus.Publisher(b, TopicConnection))
+	c.OnMotorCommand(bus.Sender(b, TopicMotorCommands))
+	c.OnEmotionCommand(bus.Sender(b, TopicEmotionCommands))
+	c.OnChoreoCommand(bus.Sender(b, TopicChoreoCommands))
+	c.OnSpeakData(bus.Sender(b, TopicSpeakData))
+	c.OnPrivacyCommand(bus.Sender(b, TopicPrivacyCommands))
+	c.OnConfigUpdate(bus.Sender(b, TopicConfigUpdates))
+	c.OnStopSpeak(bus.Sender(b, TopicStopSpeak))
 }
 
 // OnMotorCommand sets the callback for motor commands
@@ -82,6 +280,13 @@ func (c *Client) OnEmotionCommand(callback func(protocol.EmotionCommand)) {
 	c.mu.Unlock()
 }
 
+// OnChoreoCommand sets the callback for choreography commands
+func (c *Client) OnChoreoCommand(callback func(protocol.ChoreoCommand)) {
+	c.mu.Lock()
+	c.onChoreoCommand = callback
+	c.mu.Unlock()
+}
+
 // OnSpeakData sets the callback for TTS audio
 func (c *Client) OnSpeakData(callback func(protocol.SpeakData)) {
 	c.mu.Lock()
@@ -89,6 +294,13 @@ func (c *Client) OnSpeakData(callback func(protocol.SpeakData)) {
 	c.mu.Unlock()
 }
 
+// OnStopSpeak sets the callback for stop-speak commands
+func (c *Client) OnStopSpeak(callback func(protocol.StopSpeakCommand)) {
+	c.mu.Lock()
+	c.onStopSpeak = callback
+	c.mu.Unlock()
+}
+
 // OnConfigUpdate sets the callback for config updates
 func (c *Client) OnConfigUpdate(callback func(protocol.ConfigUpdate)) {
 	c.mu.Lock()
@@ -96,18 +308,44 @@ func (c *Client) OnConfigUpdate(callback func(protocol.ConfigUpdate)) {
 	c.mu.Unlock()
 }
 
+// OnPrivacyCommand sets the callback for privacy mode commands
+func (c *Client) OnPrivacyCommand(callback func(protocol.PrivacyCommand)) {
+	c.mu.Lock()
+	c.onPrivacy = callback
+	c.mu.Unlock()
+}
+
+// SetSendGate installs a gate consulted for every outgoing message
+func (c *Client) SetSendGate(gate SendGate) {
+	c.mu.Lock()
+	c.gate = gate
+	c.mu.Unlock()
+}
+
+// SetQueue installs a store-and-forward queue for messages sent while
+// disconnected; they are replayed on reconnect
+func (c *Client) SetQueue(q *Queue) {
+	c.mu.Lock()
+	c.queue = q
+	c.mu.Unlock()
+}
+
 // Connect establishes WebSocket connection to cloud
 func (c *Client) Connect(ctx context.Context) error {
 	ctx, c.cancel = context.WithCancel(ctx)
 
 	go c.connectionLoop(ctx)
+	go c.writeLoop(ctx)
 	return nil
 }
 
 // connectionLoop manages connection with auto-reconnect
 func (c *Client) connectionLoop(ctx context.Context) {
 	backoff := c.cfg.ReconnectBackoff
+	defer c.setState(StateClosed, nil, 0)
 
+	var lost error     // Why the previous connection ended
+	down := time.Now() // When the client was last connected, or started
 	for {
 		select {
 		case <-ctx.Done():
@@ -116,12 +354,19 @@ func (c *Client) connectionLoop(ctx context.Context) {
 		default:
 		}
 
+		c.setState(StateConnecting, lost, 0)
 		err := c.connect(ctx)
 		if err != nil {
+			// Sustained failure: try the next endpoint from a fresh backoff
+			if time.Since(down) >= c.cfg.FailoverAfter && c.failover() {
+				down = time.Now()
+				backoff = c.cfg.ReconnectBackoff
+			}
 			c.logger.Warn("cloud connection failed",
 				"error", err,
 				"retry_in", backoff,
 			)
+			c.setState(StateBackoff, err, backoff)
 
 			select {
 			case <-time.After(backoff):
@@ -140,38 +385,178 @@ func (c *Client) connectionLoop(ctx context.Context) {
 
 		// Reset backoff on successful connection
 		backoff = c.cfg.ReconnectBackoff
+		c.setState(StateConnected, nil, 0)
 
 		// Read messages until error
-		c.readLoop(ctx)
+		lost = c.readLoop(ctx)
+		down = time.Now()
 	}
 }
 
 // connect establishes the WebSocket connection
 func (c *Client) connect(ctx context.Context) error {
-	c.logger.Info("connecting to cloud", "url", c.cfg.URL)
-
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
+	url := c.Endpoint()
+	c.logger.Info("connecting to cloud", "url", url)
 
-	conn, _, err := dialer.DialContext(ctx, c.cfg.URL, nil)
+	conn, resp, err := c.dial(ctx, url)
 	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			c.authFailures.Add(1)
+			return fmt.Errorf("dial: cloud rejected credentials (%s): %w", resp.Status, err)
+		}
 		return fmt.Errorf("dial: %w", err)
 	}
 
+	// The cloud opts in to binary frames by selecting a subprotocol
+	timed := conn.Subprotocol() == protocol.BinarySubprotocolV2
+	binary := timed || conn.Subprotocol() == protocol.BinarySubprotocol
+
+	deflate := c.cfg.Compression.Deflate && deflateNegotiated(resp)
+	if deflate {
+		conn.SetCompressionLevel(c.compressionLevel())
+	}
+
+	// Identify before anything else can be sent on this connection
+	if err := c.sendHello(conn, binary); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// Raw PCM until the cloud selects another codec
+	c.setMicCodec(audio.CodecPCM16)
+
 	c.mu.Lock()
 	c.conn = conn
 	c.connected = true
+	c.binary = binary
+	c.timed = timed
+	c.deflate = deflate
+	c.cloudProtocol = 0 // Until the cloud's hello says otherwise
+	c.cloudGzip = false
+	c.cloudDOABatch = false
+	primary := c.endpoint == 0
 	c.mu.Unlock()
+	c.queueDelay.Store(0)
 
-	c.logger.Info("connected to cloud")
+	c.logger.Info("connected to cloud", "url", url, "binary_frames", binary, "deflate", deflate)
 
 	// Start ping goroutine
 	go c.pingLoop(ctx)
+	if c.cfg.ClockSync > 0 {
+		go c.clockSyncLoop(ctx, conn)
+	}
+	if c.cfg.DOABatch > 0 {
+		go c.doaBatchLoop(ctx, conn)
+	}
+	if !primary && c.cfg.PrimaryRetry > 0 {
+		go c.primaryLoop(ctx, conn)
+	}
+
+	go c.replayQueue(ctx)
+
+	return nil
+}
+
+// dial opens a WebSocket to url with the configured subprotocols, TLS and
+// credentials
+func (c *Client) dial(ctx context.Context, url string) (*websocket.Conn, *http.Response, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  10 * time.Second,
+		EnableCompression: c.cfg.Compression.Deflate,
+	}
+	if c.cfg.BinaryFrames {
+		dialer.Subprotocols = []string{protocol.BinarySubprotocolV2, protocol.BinarySubprotocol}
+	}
+
+	tlsConfig, err := LoadTLSConfig(c.cfg.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialer.TLSClientConfig = tlsConfig
+
+	header := http.Header{}
+	if c.cfg.Auth.Token != "" {
+		header.Set("Authorization", "Bearer "+c.cfg.Auth.Token)
+	}
+	return dialer.DialContext(ctx, url, header)
+}
 
+// sendHello writes the (optionally signed) hello message directly on conn
+func (c *Client) sendHello(conn *websocket.Conn, binary bool) error {
+	capabilities := slices.Clone(c.cfg.Capabilities)
+	if binary {
+		capabilities = append(capabilities, protocol.CapabilityBinaryFrames)
+	}
+	if c.cfg.Compression.GzipThreshold > 0 {
+		capabilities = append(capabilities, protocol.CapabilityGzip)
+	}
+	if c.cfg.ClockSync > 0 {
+		capabilities = append(capabilities, protocol.CapabilityClockSync)
+	}
+	if c.cfg.DOABatch > 0 {
+		capabilities = append(capabilities, protocol.CapabilityDOABatch)
+	}
+
+	hello, err := protocol.NewHello(c.cfg.RobotID, c.cfg.Version, capabilities)
+	if err != nil {
+		return err
+	}
+	hello.HardwareSerial = c.cfg.HardwareSerial
+	hello.SerialSource = c.cfg.SerialSource
+	hello.Firmware = c.cfg.Firmware
+	hello.AudioCodecs = c.audioCodecs()
+	if c.cfg.Auth.HMACSecret != "" {
+		hello.Sign([]byte(c.cfg.Auth.HMACSecret))
+	}
+
+	msg, err := protocol.NewHelloMessage(hello)
+	if err != nil {
+		return err
+	}
+	msg.RobotID = c.cfg.RobotID
+	data, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("marshal hello: %w", err)
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("send hello: %w", err)
+	}
 	return nil
 }
 
+// LoadTLSConfig builds a TLS config from mTLS settings (nil if none are set)
+func LoadTLSConfig(auth AuthConfig) (*tls.Config, error) {
+	if auth.CertFile == "" && auth.KeyFile == "" && auth.CAFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if auth.CertFile != "" || auth.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(auth.CertFile, auth.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if auth.CAFile != "" {
+		pem, err := os.ReadFile(auth.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", auth.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
 // pingLoop sends periodic pings
 func (c *Client) pingLoop(ctx context.Context) {
 	ticker := time.NewTicker(c.cfg.PingInterval)
@@ -198,12 +583,13 @@ func (c *Client) pingLoop(ctx context.Context) {
 	}
 }
 
-// readLoop reads messages from cloud
-func (c *Client) readLoop(ctx context.Context) {
+// readLoop reads messages from cloud until the connection ends, and returns
+// why it ended
+func (c *Client) readLoop(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return ctx.Err()
 		default:
 		}
 
@@ -212,94 +598,391 @@ func (c *Client) readLoop(ctx context.Context) {
 		c.mu.Unlock()
 
 		if conn == nil {
-			return
+			return fmt.Errorf("connection closed")
 		}
 
-		_, data, err := conn.ReadMessage()
+		wsType, data, err := conn.ReadMessage()
 		if err != nil {
 			c.logger.Warn("read error", "error", err)
 			c.closeConnection()
-			return
+			return err
 		}
 
 		c.messagesReceived.Add(1)
+		data, err = decompress(wsType, data)
+		if err != nil {
+			c.logger.Warn("dropping undecodable cloud message", "error", err)
+			continue
+		}
 		c.handleMessage(data)
 	}
 }
 
 // handleMessage processes incoming messages
 func (c *Client) handleMessage(data []byte) {
+	received := time.Now()
 	msg, err := protocol.ParseMessage(data)
 	if err != nil {
 		c.logger.Warn("parse message error", "error", err)
 		return
 	}
+	if msg.RobotID != "" && c.cfg.RobotID != "" && msg.RobotID != c.cfg.RobotID {
+		c.misrouted.Add(1)
+		c.logger.Warn("dropping message for another robot", "type", msg.Type, "robot_id", msg.RobotID)
+		return
+	}
+	if msg.Type.IsCommand() {
+		// Every command gets a trace ID for the robot's logs, even if the
+		// cloud didn't send one
+		if !trace.Valid(msg.TraceID) {
+			msg.TraceID = trace.NewID()
+		}
+		if err := c.checkCloudProtocol(); err != nil {
+			c.versionRejected.Add(1)
+			ctx := trace.WithID(context.Background(), msg.TraceID)
+			c.logger.WarnContext(ctx, "refusing command from incompatible cloud", "type", msg.Type, "error", err)
+			c.AckContext(ctx, msg.ID, err)
+			return
+		}
+	}
 
 	c.mu.Lock()
 	motorCb := c.onMotorCommand
 	emotionCb := c.onEmotionCommand
+	choreoCb := c.onChoreoCommand
 	speakCb := c.onSpeakData
 	configCb := c.onConfigUpdate
+	privacyCb := c.onPrivacy
+	stopSpeakCb := c.onStopSpeak
 	c.mu.Unlock()
 
 	switch msg.Type {
+	case protocol.TypeHello:
+		c.handleCloudHello(msg)
+
 	case protocol.TypeMotor:
 		if motorCb != nil {
-			cmd, err := msg.GetMotorCommand()
-			if err == nil {
-				motorCb(*cmd)
+			var cmd protocol.MotorCommand
+			if err := c.decode(msg, &cmd); err != nil {
+				c.AckContext(trace.WithID(context.Background(), msg.TraceID), msg.ID, fmt.Errorf("invalid motor command: %w", err))
+				return
 			}
+			cmd.ID = msg.ID
+			cmd.TraceID = msg.TraceID
+			motorCb(cmd)
 		}
 
 	case protocol.TypeEmotion:
 		if emotionCb != nil {
-			cmd, err := msg.GetEmotionCommand()
-			if err == nil {
-				emotionCb(*cmd)
+			var cmd protocol.EmotionCommand
+			if err := c.decode(msg, &cmd); err != nil {
+				c.AckContext(trace.WithID(context.Background(), msg.TraceID), msg.ID, fmt.Errorf("invalid emotion command: %w", err))
+				return
 			}
+			cmd.ID = msg.ID
+			cmd.TraceID = msg.TraceID
+			emotionCb(cmd)
+		}
+
+	case protocol.TypeChoreo:
+		if choreoCb != nil {
+			var cmd protocol.ChoreoCommand
+			if err := c.decode(msg, &cmd); err != nil {
+				c.AckContext(trace.WithID(context.Background(), msg.TraceID), msg.ID, fmt.Errorf("invalid choreo command: %w", err))
+				return
+			}
+			cmd.ID = msg.ID
+			cmd.TraceID = msg.TraceID
+			choreoCb(cmd)
 		}
 
 	case protocol.TypeSpeak:
 		if speakCb != nil {
-			data, err := msg.GetSpeakData()
-			if err == nil {
-				speakCb(*data)
+			var data protocol.SpeakData
+			if err := c.decode(msg, &data); err != nil {
+				c.AckContext(trace.WithID(context.Background(), msg.TraceID), msg.ID, fmt.Errorf("invalid speak data: %w", err))
+				return
 			}
+			data.ID = msg.ID
+			data.TraceID = msg.TraceID
+			speakCb(data)
 		}
 
 	case protocol.TypeConfig:
+		var cfg protocol.ConfigUpdate
+		if err := c.decode(msg, &cfg); err != nil {
+			c.Ack(msg.ID, fmt.Errorf("invalid config update: %w", err))
+			return
+		}
+		if cfg.Audio != nil && cfg.Audio.MicCodec != "" {
+			if err := c.setMicCodec(cfg.Audio.MicCodec); err != nil {
+				c.logger.Warn("mic codec rejected", "codec", cfg.Audio.MicCodec, "error", err)
+			} else {
+				c.logger.Info("mic codec selected", "codec", cfg.Audio.MicCodec)
+			}
+		}
 		if configCb != nil {
-			cfg, err := msg.GetConfigUpdate()
-			if err == nil {
-				configCb(*cfg)
+			configCb(cfg)
+		}
+
+	case protocol.TypePrivacy:
+		if privacyCb != nil {
+			var cmd protocol.PrivacyCommand
+			if err := c.decode(msg, &cmd); err != nil {
+				c.Ack(msg.ID, fmt.Errorf("invalid privacy command: %w", err))
+				return
+			}
+			privacyCb(cmd)
+		}
+
+	case protocol.TypeStopSpeak:
+		if stopSpeakCb != nil {
+			var cmd protocol.StopSpeakCommand
+			if err := c.decode(msg, &cmd); err != nil {
+				c.Ack(msg.ID, fmt.Errorf("invalid stop-speak command: %w", err))
+				return
 			}
+			stopSpeakCb(cmd)
+		}
+
+	case protocol.TypeAck:
+		var ack protocol.AckData
+		if err := c.decode(msg, &ack); err != nil {
+			return
 		}
+		c.mu.Lock()
+		ch, ok := c.pending[ack.ID]
+		delete(c.pending, ack.ID)
+		c.mu.Unlock()
+		if !ok {
+			c.logger.Debug("ack for unknown request", "id", ack.ID)
+			return
+		}
+		ch <- ack
 
 	case protocol.TypePing:
-		// Respond with pong
-		pong := &protocol.Message{Type: protocol.TypePong, Timestamp: time.Now().UnixMilli()}
-		c.SendMessage(pong)
+		// Respond with pong, echoing any clock sync data
+		if err := c.writeNow(protocol.NewPong(msg, received)); err != nil {
+			c.logger.Debug("pong failed", "error", err)
+		}
+
+	case protocol.TypePong:
+		if data, ok := msg.GetClockSyncData(); ok {
+			c.handleClockPong(data, received)
+		}
 	}
 }
 
-// SendMessage sends a message to cloud
+// SendMessage queues a message for the cloud without waiting for the write.
+// While disconnected, messages other than video, audio and keepalives go to
+// the offline queue if one is set. Messages are stamped with the robot ID
+// and, once clock sync has an estimate, the time on the cloud's clock.
 func (c *Client) SendMessage(msg *protocol.Message) error {
+	msg = c.stamp(msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	err = c.send(msg.Type, websocket.TextMessage, data, time.UnixMilli(msg.Timestamp))
+	if err == nil || errors.Is(err, ErrBlocked) || errors.Is(err, ErrSendQueueFull) || !queueable(msg.Type) {
+		return err
+	}
+	return c.queueOffline(msg.Type, data, time.UnixMilli(msg.Timestamp), err)
+}
+
+// stamp returns msg with the robot ID and cloud timestamp filled in, copying
+// it if anything changes
+func (c *Client) stamp(msg *protocol.Message) *protocol.Message {
+	robotID := msg.RobotID == "" && c.cfg.RobotID != ""
+	var cloudTS int64
+	if msg.CloudTS == 0 {
+		cloudTS = c.clock.cloudMillis(msg.Timestamp)
+	}
+	if !robotID && cloudTS == 0 {
+		return msg
+	}
+
+	stamped := *msg
+	if robotID {
+		stamped.RobotID = c.cfg.RobotID
+	}
+	if cloudTS != 0 {
+		stamped.CloudTS = cloudTS
+	}
+	return &stamped
+}
+
+// send hands an encoded message to the writer, evicting older, lower
+// priority messages if the send queue is full
+func (c *Client) send(msgType protocol.MessageType, wsType int, data []byte, at time.Time) error {
 	c.mu.Lock()
-	conn := c.conn
 	connected := c.connected
+	gate := c.gate
 	c.mu.Unlock()
 
-	if !connected || conn == nil {
+	if gate != nil && gate.Blocks(msgType) {
+		c.messagesBlocked.Add(1)
+		return ErrBlocked
+	}
+	if !connected {
 		return fmt.Errorf("not connected")
 	}
 
-	data, err := json.Marshal(msg)
+	evicted, err := c.sendQueue.push(outgoing{msgType: msgType, wsType: wsType, data: data, at: at, queued: time.Now()})
 	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
+		c.sendDropped.Add(1)
+		c.sendDroppedType.WithLabelValues(string(msgType)).Inc()
+		return err
+	}
+	if evicted != nil {
+		c.sendDropped.Add(1)
+		c.sendDroppedType.WithLabelValues(string(evicted.msgType)).Inc()
 	}
+	return nil
+}
 
-	conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+// queueOffline keeps a message that couldn't be sent for replay; cause is
+// returned when there is no offline queue
+func (c *Client) queueOffline(msgType protocol.MessageType, data []byte, at time.Time, cause error) error {
+	c.mu.Lock()
+	q := c.queue
+	c.mu.Unlock()
+	if q == nil {
+		return cause
+	}
+
+	if qerr := q.Push(msgType, at, data); qerr != nil {
+		if errors.Is(qerr, ErrQueueFull) {
+			return qerr
+		}
+		c.logger.Debug("offline queue write failed", "error", qerr)
+	}
+	c.messagesQueued.Add(1)
+	return nil
+}
+
+// writeLoop writes queued messages, highest priority first and paced by the
+// token bucket, until ctx is cancelled. Messages whose write fails fall back
+// to the offline queue.
+func (c *Client) writeLoop(ctx context.Context) {
+	for {
+		// Counted before the pop so a drain never sees an empty queue while a
+		// message is on its way out
+		c.writing.Add(1)
+		m, ok := c.sendQueue.pop()
+		if !ok {
+			c.writing.Add(-1)
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.sendQueue.ready:
+			}
+			continue
+		}
+
+		err := c.writeQueued(ctx, m)
+		c.writing.Add(-1)
+		if err != nil {
+			return // ctx ended while pacing
+		}
+	}
+}
+
+// writeQueued paces and writes one queued message, falling back to the
+// offline queue if the write fails. It only fails if ctx ends first.
+func (c *Client) writeQueued(ctx context.Context, m outgoing) error {
+	wsType, data := c.compress(m.wsType, m.data)
+	if err := c.bucket.wait(ctx, len(data)); err != nil {
+		return err
+	}
+	err := c.write(m.msgType, wsType, data)
+	if err == nil {
+		c.observeQueueDelay(time.Since(m.queued))
+	}
+	if err == nil || errors.Is(err, ErrBlocked) || !queueable(m.msgType) {
+		return nil
+	}
+	if err := c.queueOffline(m.msgType, m.data, m.at, err); err != nil {
+		c.logger.Debug("message lost", "type", m.msgType, "error", err)
+	}
+	return nil
+}
+
+// queueable reports whether msgType is kept for replay; video, audio, state
+// snapshots, acks and keepalives are only useful live
+func queueable(msgType protocol.MessageType) bool {
+	switch msgType {
+	case protocol.TypeFrame, protocol.TypeMic, protocol.TypeWakeCandidate, protocol.TypeState, protocol.TypePing, protocol.TypePong, protocol.TypeHello, protocol.TypeAck:
+		return false
+	}
+	return true
+}
+
+// replayQueue sends queued messages, oldest first with their original
+// timestamps, until the queue is empty or the connection drops
+func (c *Client) replayQueue(ctx context.Context) {
+	c.mu.Lock()
+	q := c.queue
+	c.mu.Unlock()
+	if q == nil || q.Len() == 0 {
+		return
+	}
+
+	var sent uint64
+	for ctx.Err() == nil {
+		item, ok := q.Peek()
+		if !ok {
+			break
+		}
+		wsType, data := c.compress(websocket.TextMessage, item.Data)
+		if err := c.bucket.wait(ctx, len(data)); err != nil {
+			break
+		}
+		if err := c.write(item.Type, wsType, data); err != nil {
+			if !errors.Is(err, ErrBlocked) {
+				break // Keep the rest for the next connection
+			}
+		} else {
+			sent++
+		}
+		q.Pop()
+	}
+
+	c.messagesReplayed.Add(sent)
+	c.logger.Info("replayed offline queue", "sent", sent, "remaining", q.Len())
+}
+
+// write sends an encoded message; every send path goes through here
+func (c *Client) write(msgType protocol.MessageType, wsType int, data []byte) error {
+	c.mu.Lock()
+	conn := c.conn
+	connected := c.connected
+	deflate := c.deflate
+	gate := c.gate
+	c.mu.Unlock()
+
+	// Enforced here so no sender can bypass privacy mode
+	if gate != nil && gate.Blocks(msgType) {
+		c.messagesBlocked.Add(1)
+		return ErrBlocked
+	}
+
+	if !connected || conn == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	// gorilla/websocket allows only one concurrent writer
+	c.writeMu.Lock()
+	start := time.Now()
+	conn.SetWriteDeadline(start.Add(c.cfg.WriteTimeout))
+	// Binary messages (JPEG, gzip) are already compressed
+	conn.EnableWriteCompression(deflate && wsType == websocket.TextMessage)
+	err := conn.WriteMessage(wsType, data)
+	c.writeMu.Unlock()
+
+	c.sendLatency.WithLabelValues(string(msgType)).Observe(time.Since(start).Seconds())
+	if err != nil {
 		c.logger.Warn("send error", "error", err)
 		c.closeConnection()
 		return fmt.Errorf("write: %w", err)
@@ -309,9 +992,40 @@ func (c *Client) SendMessage(msg *protocol.Message) error {
 	return nil
 }
 
-// SendFrame sends a video frame to cloud
+// SendFrame sends a video frame captured just now; see SendFrameAt
 func (c *Client) SendFrame(width, height int, jpegData []byte, frameID uint64) error {
-	msg, err := protocol.NewFrameMessage(width, height, jpegData, frameID)
+	return c.SendFrameAt(width, height, jpegData, frameID, time.Now())
+}
+
+// SendFrameAt sends a video frame to cloud, as raw binary if negotiated,
+// stamped with its capture time on the robot's and, once synced, the
+// cloud's clock. Binary frames carry no robot ID; the cloud attributes them
+// to the connection's hello. Version 1 binary frames carry no capture time.
+func (c *Client) SendFrameAt(width, height int, jpegData []byte, frameID uint64, capturedAt time.Time) error {
+	c.mu.Lock()
+	binary, timed := c.binary, c.timed
+	c.mu.Unlock()
+
+	var cloudCapturedAt int64
+	if t, ok := c.clock.cloudTime(capturedAt); ok {
+		cloudCapturedAt = t.UnixMilli()
+	}
+
+	if binary {
+		var data []byte
+		if timed {
+			data = protocol.EncodeTimedBinaryFrame(width, height, jpegData, frameID, capturedAt.UnixMilli(), cloudCapturedAt)
+		} else {
+			data = protocol.EncodeBinaryFrame(width, height, jpegData, frameID)
+		}
+		err := c.send(protocol.TypeFrame, websocket.BinaryMessage, data, capturedAt)
+		if err == nil {
+			c.binaryFrames.Add(1)
+		}
+		return err
+	}
+
+	msg, err := protocol.NewTimedFrameMessage(width, height, jpegData, frameID, capturedAt.UnixMilli(), cloudCapturedAt)
 	if err != nil {
 		return err
 	}
@@ -338,6 +1052,253 @@ func (c *Client) SendEnhancedDOA(angle, smoothedAngle float64, speaking, speakin
 	return c.SendMessage(msg)
 }
 
+// SendDOAData sends a DOA message built by the caller, e.g. with CapturedAt
+// set, which is then also converted to the cloud's clock
+func (c *Client) SendDOAData(data protocol.DOAData) error {
+	if data.CloudCapturedAt == 0 {
+		data.CloudCapturedAt = c.clock.cloudMillis(data.CapturedAt)
+	}
+	if c.batchingDOA() {
+		c.bufferDOA(data)
+		return nil
+	}
+	msg, err := protocol.NewMessage(protocol.TypeDOA, data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendTranscript sends a locally recognized utterance to cloud
+func (c *Client) SendTranscript(data protocol.TranscriptData) error {
+	msg, err := protocol.NewTranscriptMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendUtterance sends an utterance boundary to cloud
+func (c *Client) SendUtterance(data protocol.UtteranceData) error {
+	msg, err := protocol.NewUtteranceMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendBargeIn reports someone talking over the robot's playback
+func (c *Client) SendBargeIn(data protocol.BargeInData) error {
+	msg, err := protocol.NewBargeInMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendWakeCandidate reports a possible wake word onset
+func (c *Client) SendWakeCandidate(data protocol.WakeCandidateData) error {
+	msg, err := protocol.NewWakeCandidateMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendEmotionEvent reports an emotion starting (protocol.TypeEmotionStarted)
+// or finishing (protocol.TypeEmotionFinished)
+func (c *Client) SendEmotionEvent(msgType protocol.MessageType, data protocol.EmotionEventData) error {
+	msg, err := protocol.NewEmotionEventMessage(msgType, data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendHistory uploads a batch of history rows (the remote history backend)
+func (c *Client) SendHistory(data protocol.HistoryData) error {
+	msg, err := protocol.NewHistoryMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendState sends a robot state snapshot to cloud
+func (c *Client) SendState(data protocol.StateData) error {
+	msg, err := protocol.NewStateMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// SendConfigApplied acknowledges a config update
+func (c *Client) SendConfigApplied(data protocol.ConfigAppliedData) error {
+	msg, err := protocol.NewConfigAppliedMessage(data)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(msg)
+}
+
+// Ack reports the outcome of the cloud message with id; a non-nil err sends a
+// nack. Messages without an ID didn't ask for an ack, so nothing is sent.
+func (c *Client) Ack(id string, err error) error {
+	return c.AckContext(context.Background(), id, err)
+}
+
+// AckContext is Ack, echoing the trace ID ctx carries
+func (c *Client) AckContext(ctx context.Context, id string, err error) error {
+	if id == "" {
+		return nil
+	}
+	msg, merr := protocol.NewAckMessage(id, err)
+	if merr != nil {
+		return merr
+	}
+	msg.TraceID = trace.ID(ctx)
+	if err != nil {
+		c.nacksSent.Add(1)
+	} else {
+		c.acksSent.Add(1)
+	}
+	return c.SendMessage(msg)
+}
+
+// Request sends msg with a fresh ID and waits for the cloud's ack. A nack
+// returns ErrRejected; with no deadline on ctx, Config.RequestTimeout applies.
+// The trace ID ctx carries goes with the message.
+func (c *Client) Request(ctx context.Context, msg *protocol.Message) (protocol.AckData, error) {
+	id, err := protocol.NewMessageID()
+	if err != nil {
+		return protocol.AckData{}, err
+	}
+	msg.ID = id
+	if msg.TraceID == "" {
+		msg.TraceID = trace.ID(ctx)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.RequestTimeout)
+		defer cancel()
+	}
+
+	ch := make(chan protocol.AckData, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.SendMessage(msg); err != nil {
+		return protocol.AckData{}, err
+	}
+
+	select {
+	case ack := <-ch:
+		if !ack.OK {
+			return ack, fmt.Errorf("%w: %s", ErrRejected, ack.Error)
+		}
+		return ack, nil
+	case <-ctx.Done():
+		c.requestTimeouts.Add(1)
+		return protocol.AckData{}, fmt.Errorf("await ack for %s %s: %w", msg.Type, id, ctx.Err())
+	}
+}
+
+// audioCodecs returns the configured codecs this build supports, with pcm16
+// always offered as the fallback
+func (c *Client) audioCodecs() []string {
+	var codecs []string
+	hasPCM := false
+	for _, codec := range c.cfg.AudioCodecs {
+		if !audio.CodecSupported(codec) {
+			continue
+		}
+		codecs = append(codecs, codec)
+		hasPCM = hasPCM || codec == audio.CodecPCM16
+	}
+	if !hasPCM {
+		codecs = append(codecs, audio.CodecPCM16)
+	}
+	return codecs
+}
+
+// setMicCodec switches the mic codec; it must be one offered in the hello
+func (c *Client) setMicCodec(codec string) error {
+	offered := false
+	for _, o := range c.audioCodecs() {
+		offered = offered || o == codec
+	}
+	if !offered {
+		return fmt.Errorf("codec %q not offered", codec)
+	}
+
+	c.micMu.Lock()
+	defer c.micMu.Unlock()
+	if c.micCodec == codec {
+		return nil
+	}
+	if c.micEnc != nil {
+		c.micEnc.Close()
+		c.micEnc = nil
+	}
+	c.micCodec = codec
+	return nil
+}
+
+// MicCodec returns the codec currently used for mic audio
+func (c *Client) MicCodec() string {
+	c.micMu.Lock()
+	defer c.micMu.Unlock()
+	return c.micCodec
+}
+
+// SendMic encodes a chunk of captured PCM16 audio with the negotiated codec
+// and sends it to cloud, tagged with the talker direction if beam is set.
+// Opus audio short of a full frame is held back for the next chunk.
+func (c *Client) SendMic(pcm []byte, sampleRate, channels int, beam *protocol.MicBeam) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	c.micMu.Lock()
+	defer c.micMu.Unlock()
+
+	if c.micEnc == nil || c.micRate != sampleRate || c.micChannels != channels {
+		if c.micEnc != nil {
+			c.micEnc.Close()
+		}
+		enc, err := audio.NewPayloadEncoder(c.micCodec, sampleRate, channels, c.cfg.OpusBitrate)
+		if err != nil {
+			c.micEnc = nil
+			return fmt.Errorf("mic encoder: %w", err)
+		}
+		c.micEnc, c.micRate, c.micChannels = enc, sampleRate, channels
+	}
+
+	payload, err := c.micEnc.Encode(pcm)
+	if err != nil || payload == nil {
+		return err
+	}
+
+	c.micSeq++
+	msg, err := protocol.NewMicMessage(c.micEnc.Codec(), sampleRate, channels, payload, c.micSeq, beam)
+	if err != nil {
+		return err
+	}
+	if err := c.SendMessage(msg); err != nil {
+		return err
+	}
+	c.micChunks.Add(1)
+	return nil
+}
+
 // closeConnection closes the WebSocket connection
 func (c *Client) closeConnection() {
 	c.mu.Lock()
@@ -356,6 +1317,57 @@ func (c *Client) Close() error {
 		c.cancel()
 	}
 	c.closeConnection()
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.setState(StateClosed, nil, 0)
+
+	c.micMu.Lock()
+	if c.micEnc != nil {
+		c.micEnc.Close()
+		c.micEnc = nil
+	}
+	c.micMu.Unlock()
+	return nil
+}
+
+// Shutdown gives the writer until ctx ends to send what is already queued
+// (or move it to the offline queue), tells the cloud the robot is going
+// away with a close frame, then closes the client
+func (c *Client) Shutdown(ctx context.Context) error {
+	var err error
+	if c.cancel != nil { // The writer only runs once connected
+		err = c.drainSendQueue(ctx)
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil {
+		msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "robot shutting down")
+		c.writeMu.Lock()
+		if werr := conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.cfg.WriteTimeout)); werr != nil {
+			c.logger.Debug("close frame not sent", "error", werr)
+		}
+		c.writeMu.Unlock()
+	}
+
+	c.Close()
+	return err
+}
+
+// drainSendQueue waits until the send queue is empty and the last write has
+// finished, or ctx ends
+func (c *Client) drainSendQueue(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for c.sendQueue.Len() > 0 || c.writing.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("drain send queue: %d messages left: %w", c.sendQueue.Len(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
 	return nil
 }
 
@@ -368,22 +1380,106 @@ func (c *Client) IsConnected() bool {
 
 // Stats returns client statistics
 type Stats struct {
-	Connected        bool   `json:"connected"`
-	MessagesSent     uint64 `json:"messages_sent"`
-	MessagesReceived uint64 `json:"messages_received"`
-	Reconnects       uint64 `json:"reconnects"`
+	Connected        bool            `json:"connected"`
+	State            ConnectionState `json:"state"`
+	StateSince       time.Time       `json:"state_since"`
+	MessagesSent     uint64          `json:"messages_sent"`
+	MessagesReceived uint64          `json:"messages_received"`
+	MessagesBlocked  uint64          `json:"messages_blocked"`
+	BinaryFrames     uint64          `json:"binary_frames"`
+	Reconnects       uint64          `json:"reconnects"`
+	AuthFailures     uint64          `json:"auth_failures"`
+	MicCodec         string          `json:"mic_codec"`
+	MicChunks        uint64          `json:"mic_chunks"`
+	MessagesQueued   uint64          `json:"messages_queued"`
+	MessagesReplayed uint64          `json:"messages_replayed"`
+	QueueLength      int             `json:"queue_length"`
+	QueueDropped     uint64          `json:"queue_dropped"`
+	QueueExpired     uint64          `json:"queue_expired"`
+	AcksSent         uint64          `json:"acks_sent"`
+	NacksSent        uint64          `json:"nacks_sent"`
+	RequestTimeouts  uint64          `json:"request_timeouts"`
+	SendQueueLength  int             `json:"send_queue_length"`
+	SendDropped      uint64          `json:"send_dropped"`
+	Misrouted        uint64          `json:"misrouted"`           // Incoming messages addressed to another robot
+	SendQueueDelayMs float64         `json:"send_queue_delay_ms"` // Smoothed time messages wait for the writer
+	CloudProtocol    int             `json:"cloud_protocol"`      // Protocol version from the cloud's hello (0 = not announced)
+	SchemaErrors     uint64          `json:"schema_errors"`       // Cloud messages that failed decoding or validation
+	UnknownFields    uint64          `json:"unknown_fields"`      // Fields in cloud messages this build doesn't know
+	VersionRejected  uint64          `json:"version_rejected"`    // Commands refused from an incompatible cloud
+	Deflate          bool            `json:"deflate"`             // permessage-deflate negotiated for this connection
+	GzipMessages     uint64          `json:"gzip_messages"`       // Messages sent gzip-compressed
+	GzipSavedBytes   uint64          `json:"gzip_saved_bytes"`    // Bytes saved by gzip
+	ClockSynced      bool            `json:"clock_synced"`        // Clock sync has an estimate of the cloud's clock
+	ClockOffsetMs    float64         `json:"clock_offset_ms"`     // Cloud clock minus the robot's wall clock
+	ClockDelayMs     float64         `json:"clock_delay_ms"`      // Round trip of the exchange the estimate comes from
+	DOABatch         bool            `json:"doa_batch"`           // DOA batching negotiated for this connection
+	DOABatches       uint64          `json:"doa_batches"`         // DOA batch messages sent
+	DOABatched       uint64          `json:"doa_batched"`         // DOA readings sent in batches
+	Endpoint         string          `json:"endpoint"`            // URL connected to, or tried next
+	Primary          bool            `json:"primary"`             // Endpoint is the first in the list
+	Failovers        uint64          `json:"failovers"`           // Moves to the next endpoint after sustained failure
+	PrimaryReturns   uint64          `json:"primary_returns"`     // Moves back to the primary once it answered again
 }
 
 // GetStats returns client statistics
 func (c *Client) GetStats() Stats {
 	c.mu.Lock()
 	connected := c.connected
+	state, since := c.state, c.stateSince
+	q := c.queue
+	cloudProtocol := c.cloudProtocol
+	deflate := c.deflate
+	doaBatch := c.connected && c.cloudDOABatch
+	endpoint := c.endpoint
 	c.mu.Unlock()
 
-	return Stats{
+	stats := Stats{
 		Connected:        connected,
+		State:            state,
+		StateSince:       since,
 		MessagesSent:     c.messagesSent.Load(),
 		MessagesReceived: c.messagesReceived.Load(),
+		MessagesBlocked:  c.messagesBlocked.Load(),
+		BinaryFrames:     c.binaryFrames.Load(),
 		Reconnects:       c.reconnects.Load(),
+		AuthFailures:     c.authFailures.Load(),
+		MicCodec:         c.MicCodec(),
+		MicChunks:        c.micChunks.Load(),
+		MessagesQueued:   c.messagesQueued.Load(),
+		MessagesReplayed: c.messagesReplayed.Load(),
+		AcksSent:         c.acksSent.Load(),
+		NacksSent:        c.nacksSent.Load(),
+		RequestTimeouts:  c.requestTimeouts.Load(),
+		SendQueueLength:  c.sendQueue.Len(),
+		SendDropped:      c.sendDropped.Load(),
+		SendQueueDelayMs: float64(c.Uplink().QueueDelay) / float64(time.Millisecond),
+		Misrouted:        c.misrouted.Load(),
+		CloudProtocol:    cloudProtocol,
+		SchemaErrors:     c.schemaErrors.Load(),
+		UnknownFields:    c.unknownFields.Load(),
+		VersionRejected:  c.versionRejected.Load(),
+		Deflate:          deflate,
+		GzipMessages:     c.gzipMessages.Load(),
+		GzipSavedBytes:   c.gzipSaved.Load(),
+		DOABatch:         doaBatch,
+		DOABatches:       c.doaBatches.Load(),
+		DOABatched:       c.doaBatched.Load(),
+		Endpoint:         c.endpoints()[endpoint],
+		Primary:          endpoint == 0,
+		Failovers:        c.failovers.Load(),
+		PrimaryReturns:   c.primaryReturns.Load(),
+	}
+	if offset, ok := c.clock.offset(); ok {
+		best, _ := c.clock.best()
+		stats.ClockSynced = true
+		stats.ClockOffsetMs = float64(offset) / float64(time.Millisecond)
+		stats.ClockDelayMs = float64(best.delay) / float64(time.Millisecond)
+	}
+	if q != nil {
+		stats.QueueLength = q.Len()
+		stats.QueueDropped = q.dropped.Load()
+		stats.QueueExpired = q.expired.Load()
 	}
+	return stats
 }
@@ -2,16 +2,30 @@ package cloud
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/privacy"
 	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/trace"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -61,6 +75,42 @@ func TestSendDOANotConnected(t *testing.T) {
 	}
 }
 
+type blockFrames struct{}
+
+func (blockFrames) Blocks(msgType protocol.MessageType) bool {
+	return msgType == protocol.TypeFrame
+}
+
+func TestSendGate(t *testing.T) {
+	client := NewClient(DefaultConfig(), nil)
+	client.SetSendGate(blockFrames{})
+
+	if err := client.SendFrame(640, 480, []byte("test"), 1); !errors.Is(err, ErrBlocked) {
+		t.Errorf("SendFrame error = %v, want ErrBlocked", err)
+	}
+
+	if err := client.SendDOA(0.5, 0.48, true, true, 0.9); err == nil || errors.Is(err, ErrBlocked) {
+		t.Errorf("SendDOA should pass the gate and fail on connection, got %v", err)
+	}
+
+	if client.GetStats().MessagesBlocked != 1 {
+		t.Errorf("expected 1 blocked message, got %d", client.GetStats().MessagesBlocked)
+	}
+}
+
+func TestSendGate_PrivacyBlocksHistory(t *testing.T) {
+	guard := privacy.NewGuard(privacy.DefaultConfig(), nil)
+	guard.Set(true, "api")
+
+	client := NewClient(DefaultConfig(), nil)
+	client.SetSendGate(guard)
+
+	data := protocol.HistoryData{Readings: []protocol.HistoryReading{{}}}
+	if err := client.SendHistory(data); !errors.Is(err, ErrBlocked) {
+		t.Errorf("SendHistory error = %v, want ErrBlocked in privacy mode", err)
+	}
+}
+
 func TestGetStats(t *testing.T) {
 	cfg := DefaultConfig()
 	client := NewClient(cfg, nil)
@@ -162,6 +212,404 @@ func TestConnectAndSend(t *testing.T) {
 	}
 }
 
+func TestSendFrame_BinaryNegotiated(t *testing.T) {
+	frames := make(chan []byte, 1)
+
+	binaryUpgrader := websocket.Upgrader{
+		CheckOrigin:  func(r *http.Request) bool { return true },
+		Subprotocols: []string{protocol.BinarySubprotocol},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := binaryUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType == websocket.BinaryMessage {
+				frames <- data
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := client.SendFrame(640, 480, []byte("jpeg"), 7); err != nil {
+		t.Fatalf("SendFrame() error = %v", err)
+	}
+
+	select {
+	case data := <-frames:
+		frame, err := protocol.DecodeBinaryFrame(data)
+		if err != nil {
+			t.Fatalf("DecodeBinaryFrame() error = %v", err)
+		}
+		if frame.FrameID != 7 || string(frame.Data) != "jpeg" {
+			t.Errorf("unexpected frame %+v", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for binary frame")
+	}
+
+	if client.GetStats().BinaryFrames != 1 {
+		t.Errorf("expected 1 binary frame, got %d", client.GetStats().BinaryFrames)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	type received struct {
+		wsType int
+		data   []byte
+	}
+	messages := make(chan received, 4)
+	helloCaps := make(chan []string, 1)
+
+	deflateUpgrader := websocket.Upgrader{
+		CheckOrigin:       func(r *http.Request) bool { return true },
+		EnableCompression: true,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := deflateUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var hello protocol.HelloData
+		if msg, err := protocol.ParseMessage(data); err == nil {
+			json.Unmarshal(msg.Data, &hello)
+		}
+		helloCaps <- hello.Capabilities
+
+		// Accept gzip, then send a gzipped command
+		reply, _ := protocol.NewHelloMessage(protocol.HelloData{
+			ProtocolVersion: protocol.ProtocolVersion,
+			Capabilities:    []string{protocol.CapabilityGzip},
+		})
+		data, _ = json.Marshal(reply)
+		conn.WriteMessage(websocket.TextMessage, data)
+
+		motor, _ := protocol.NewMessage(protocol.TypeMotor, protocol.MotorCommand{Head: protocol.HeadTarget{X: 0.1}})
+		data, _ = json.Marshal(motor)
+		zipped, _ := protocol.GzipJSON(data, 1)
+		conn.WriteMessage(websocket.BinaryMessage, zipped)
+
+		for {
+			wsType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			messages <- received{wsType, data}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.Compression = CompressionConfig{Deflate: true, GzipThreshold: 512}
+	client := NewClient(cfg, nil)
+	motors := make(chan protocol.MotorCommand, 1)
+	client.OnMotorCommand(func(cmd protocol.MotorCommand) { motors <- cmd })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case caps := <-helloCaps:
+		if !slices.Contains(caps, protocol.CapabilityGzip) {
+			t.Errorf("hello capabilities = %v, want %q", caps, protocol.CapabilityGzip)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for hello")
+	}
+	select {
+	case cmd := <-motors:
+		if cmd.Head.X != 0.1 {
+			t.Errorf("gzipped motor command = %+v", cmd)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for gzipped motor command")
+	}
+
+	// Small messages stay JSON text; large ones are gzipped
+	if err := client.SendTranscript(protocol.TranscriptData{Text: "hi"}); err != nil {
+		t.Fatalf("SendTranscript() error = %v", err)
+	}
+	long := strings.Repeat("hello there ", 100)
+	if err := client.SendTranscript(protocol.TranscriptData{Text: long}); err != nil {
+		t.Fatalf("SendTranscript() error = %v", err)
+	}
+
+	for i, want := range []string{"hi", long} {
+		var m received
+		select {
+		case m = <-messages:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for message %d", i)
+		}
+		data := m.data
+		if i == 1 {
+			if m.wsType != websocket.BinaryMessage || !protocol.IsGzip(data) {
+				t.Fatalf("large message sent as type %d, want gzip binary", m.wsType)
+			}
+			var err error
+			if data, err = protocol.GunzipJSON(data); err != nil {
+				t.Fatalf("GunzipJSON() error = %v", err)
+			}
+		} else if m.wsType != websocket.TextMessage {
+			t.Errorf("small message sent as type %d, want text", m.wsType)
+		}
+		msg, err := protocol.ParseMessage(data)
+		if err != nil {
+			t.Fatalf("ParseMessage() error = %v", err)
+		}
+		var tr protocol.TranscriptData
+		json.Unmarshal(msg.Data, &tr)
+		if tr.Text != want {
+			t.Errorf("message %d text = %q", i, tr.Text)
+		}
+	}
+
+	stats := client.GetStats()
+	if !stats.Deflate {
+		t.Error("Deflate = false, want permessage-deflate negotiated")
+	}
+	if stats.GzipMessages != 1 || stats.GzipSavedBytes == 0 {
+		t.Errorf("gzip stats = %d messages, %d bytes saved, want 1 message", stats.GzipMessages, stats.GzipSavedBytes)
+	}
+}
+
+func TestClockSync(t *testing.T) {
+	const skew = 5 * time.Second // The cloud's clock runs ahead
+	messages := make(chan *protocol.Message, 16)
+	frames := make(chan []byte, 1)
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  func(r *http.Request) bool { return true },
+		Subprotocols: []string{protocol.BinarySubprotocolV2},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			wsType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if wsType == websocket.BinaryMessage {
+				frames <- data
+				continue
+			}
+			msg, err := protocol.ParseMessage(data)
+			if err != nil {
+				continue
+			}
+			sync, ok := msg.GetClockSyncData()
+			if msg.Type != protocol.TypePing || !ok {
+				messages <- msg
+				continue
+			}
+			now := time.Now().Add(skew).UnixMilli()
+			sync.Receive, sync.Transmit = now, now
+			pong, _ := protocol.NewMessage(protocol.TypePong, sync)
+			data, _ = pong.Bytes()
+			conn.WriteMessage(websocket.TextMessage, data)
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.ClockSync = 20 * time.Millisecond
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case msg := <-messages:
+		var hello protocol.HelloData
+		msg.ParseData(&hello)
+		if !slices.Contains(hello.Capabilities, protocol.CapabilityClockSync) {
+			t.Errorf("hello capabilities = %v, want %q", hello.Capabilities, protocol.CapabilityClockSync)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for hello")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.GetStats().ClockSynced && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := client.GetStats()
+	if !stats.ClockSynced {
+		t.Fatal("no clock sync estimate")
+	}
+	near := func(got, want int64) bool { return got-want >= -50 && got-want <= 50 }
+	if !near(int64(stats.ClockOffsetMs), skew.Milliseconds()) {
+		t.Errorf("ClockOffsetMs = %v, want about %d", stats.ClockOffsetMs, skew.Milliseconds())
+	}
+
+	// Messages and capture times are stamped on the cloud's clock too
+	capturedAt := time.Now().Add(-100 * time.Millisecond)
+	if err := client.SendDOAData(protocol.DOAData{CapturedAt: capturedAt.UnixMilli()}); err != nil {
+		t.Fatalf("SendDOAData() error = %v", err)
+	}
+	select {
+	case msg := <-messages:
+		var doa protocol.DOAData
+		msg.ParseData(&doa)
+		if !near(msg.CloudTS-msg.Timestamp, skew.Milliseconds()) {
+			t.Errorf("cloud_ts - ts = %d, want about %d", msg.CloudTS-msg.Timestamp, skew.Milliseconds())
+		}
+		if !near(doa.CloudCapturedAt-doa.CapturedAt, skew.Milliseconds()) {
+			t.Errorf("cloud_captured_at - captured_at = %d, want about %d", doa.CloudCapturedAt-doa.CapturedAt, skew.Milliseconds())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for doa")
+	}
+
+	if err := client.SendFrameAt(640, 480, []byte("jpeg"), 9, capturedAt); err != nil {
+		t.Fatalf("SendFrameAt() error = %v", err)
+	}
+	select {
+	case data := <-frames:
+		frame, err := protocol.DecodeBinaryFrame(data)
+		if err != nil {
+			t.Fatalf("DecodeBinaryFrame() error = %v", err)
+		}
+		if frame.CapturedAt != capturedAt.UnixMilli() || !near(frame.CloudCapturedAt-frame.CapturedAt, skew.Milliseconds()) {
+			t.Errorf("frame captured_at = %d, cloud_captured_at = %d", frame.CapturedAt, frame.CloudCapturedAt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for binary frame")
+	}
+}
+
+func TestDOABatch(t *testing.T) {
+	messages := make(chan *protocol.Message, 16)
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := protocol.ParseMessage(data)
+			if err != nil {
+				continue
+			}
+			if msg.Type == protocol.TypeHello {
+				// Accept batching
+				reply, _ := protocol.NewHelloMessage(protocol.HelloData{
+					ProtocolVersion: protocol.ProtocolVersion,
+					Capabilities:    []string{protocol.CapabilityDOABatch},
+				})
+				data, _ = reply.Bytes()
+				conn.WriteMessage(websocket.TextMessage, data)
+			}
+			messages <- msg
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.DOABatch = 50 * time.Millisecond
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	hello := <-messages
+	var helloData protocol.HelloData
+	hello.ParseData(&helloData)
+	if !slices.Contains(helloData.Capabilities, protocol.CapabilityDOABatch) {
+		t.Errorf("hello capabilities = %v, want %q", helloData.Capabilities, protocol.CapabilityDOABatch)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.GetStats().DOABatch && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !client.GetStats().DOABatch {
+		t.Fatal("DOA batching not negotiated")
+	}
+
+	for i := range 3 {
+		client.SendDOAData(protocol.DOAData{SmoothedAngle: float64(i), CapturedAt: 1000 + int64(i)*50})
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.Type != protocol.TypeDOABatch {
+			t.Fatalf("got %q, want a doa_batch", msg.Type)
+		}
+		batch, err := msg.GetDOABatch()
+		if err != nil {
+			t.Fatalf("GetDOABatch() error = %v", err)
+		}
+		readings := batch.Expand()
+		if len(readings) != 3 || readings[2].SmoothedAngle != 2 || readings[2].CapturedAt != 1100 {
+			t.Errorf("unexpected batch %+v", readings)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the batch")
+	}
+
+	if stats := client.GetStats(); stats.DOABatches != 1 || stats.DOABatched != 3 {
+		t.Errorf("DOABatches = %d, DOABatched = %d, want 1, 3", stats.DOABatches, stats.DOABatched)
+	}
+}
+
 func TestReceiveMotorCommand(t *testing.T) {
 	var motorReceived atomic.Bool
 
@@ -309,3 +757,716 @@ func TestCallbacksNotSet(t *testing.T) {
 	client.Close()
 }
 
+
+func TestConnect_AuthAndHello(t *testing.T) {
+	const secret = "s3cret"
+	hellos := make(chan *protocol.HelloData, 1)
+	var authHeader atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader.Store(r.Header.Get("Authorization"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg, err := protocol.ParseMessage(data)
+		if err != nil || msg.Type != protocol.TypeHello {
+			t.Errorf("first message should be hello, got %v", msg)
+			return
+		}
+		hello, _ := msg.GetHello()
+		hellos <- hello
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.RobotID = "eva-01"
+	cfg.Version = "1.2.3"
+	cfg.Auth.Token = "tok"
+	cfg.Auth.HMACSecret = secret
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case hello := <-hellos:
+		if hello.RobotID != "eva-01" || hello.Version != "1.2.3" {
+			t.Errorf("unexpected hello %+v", hello)
+		}
+		if err := hello.Verify([]byte(secret), time.Minute, time.Now()); err != nil {
+			t.Errorf("hello signature invalid: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for hello")
+	}
+
+	if got := authHeader.Load(); got != "Bearer tok" {
+		t.Errorf("Authorization header = %v, want Bearer tok", got)
+	}
+}
+
+func TestRobotIdentity(t *testing.T) {
+	received := make(chan *protocol.Message, 4)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// One motor command for another robot sharing the cloud, one for us
+		for _, robotID := range []string{"eva-02", "eva-01"} {
+			msg, _ := protocol.NewMessage(protocol.TypeMotor, protocol.MotorCommand{BodyYaw: 0.1})
+			msg.RobotID = robotID
+			data, _ := json.Marshal(msg)
+			conn.WriteMessage(websocket.TextMessage, data)
+		}
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msg, err := protocol.ParseMessage(data); err == nil {
+				received <- msg
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.RobotID = "eva-01"
+	cfg.HardwareSerial = "XVF-1234"
+	cfg.SerialSource = "xvf3800"
+	cfg.Firmware = "2.0.1"
+	cfg.Capabilities = []string{protocol.CapabilityCamera, protocol.CapabilityMotor}
+	client := NewClient(cfg, nil)
+
+	var motorCommands atomic.Int32
+	client.OnMotorCommand(func(protocol.MotorCommand) { motorCommands.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	next := func() *protocol.Message {
+		select {
+		case msg := <-received:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for message")
+			return nil
+		}
+	}
+
+	msg := next()
+	hello, err := msg.GetHello()
+	if err != nil {
+		t.Fatalf("first message should be hello: %v", err)
+	}
+	if msg.RobotID != "eva-01" || hello.HardwareSerial != "XVF-1234" || hello.SerialSource != "xvf3800" || hello.Firmware != "2.0.1" {
+		t.Errorf("hello envelope robot_id = %q, data = %+v", msg.RobotID, hello)
+	}
+	want := []string{protocol.CapabilityCamera, protocol.CapabilityMotor} // binary_frames not negotiated here
+	if !slices.Equal(hello.Capabilities, want) {
+		t.Errorf("Capabilities = %v, want %v", hello.Capabilities, want)
+	}
+
+	if err := client.SendDOA(0.5, 0.5, true, true, 0.9); err != nil {
+		t.Fatalf("SendDOA() error = %v", err)
+	}
+	if msg := next(); msg.Type != protocol.TypeDOA || msg.RobotID != "eva-01" {
+		t.Errorf("outgoing message type = %s, robot_id = %q; want doa from eva-01", msg.Type, msg.RobotID)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := motorCommands.Load(); n != 1 {
+		t.Errorf("motor callback ran %d times, want 1 (command for eva-02 should be dropped)", n)
+	}
+	if stats := client.GetStats(); stats.Misrouted != 1 {
+		t.Errorf("Misrouted = %d, want 1", stats.Misrouted)
+	}
+}
+
+func TestConnect_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	client := NewClient(cfg, nil)
+
+	if err := client.connect(context.Background()); err == nil {
+		t.Fatal("expected error for rejected credentials")
+	}
+	if client.GetStats().AuthFailures != 1 {
+		t.Errorf("expected 1 auth failure, got %d", client.GetStats().AuthFailures)
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	cfg, err := LoadTLSConfig(AuthConfig{})
+	if err != nil || cfg != nil {
+		t.Errorf("no mTLS settings should yield nil config, got %v, %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+
+	cfg, err = LoadTLSConfig(AuthConfig{CertFile: certFile, KeyFile: keyFile, CAFile: certFile})
+	if err != nil {
+		t.Fatalf("LoadTLSConfig() error = %v", err)
+	}
+	if len(cfg.Certificates) != 1 || cfg.RootCAs == nil {
+		t.Error("expected client certificate and CA pool")
+	}
+
+	if _, err := LoadTLSConfig(AuthConfig{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}); err == nil {
+		t.Error("expected error for missing certificate")
+	}
+}
+
+// writeTestCert writes a self-signed certificate and key as PEM files
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "eva-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestMicCodecNegotiation(t *testing.T) {
+	mics := make(chan *protocol.MicData, 4)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg, _ := protocol.ParseMessage(data)
+		hello, _ := msg.GetHello()
+
+		// An unknown codec is ignored, then pick the robot's preferred one
+		for _, codec := range []string{"mp3", hello.AudioCodecs[0]} {
+			cfgMsg, _ := protocol.NewMessage(protocol.TypeConfig, protocol.ConfigUpdate{
+				Audio: &protocol.AudioConfig{MicCodec: codec},
+			})
+			data, _ := cfgMsg.Bytes()
+			conn.WriteMessage(websocket.TextMessage, data)
+		}
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msg, err := protocol.ParseMessage(data); err == nil && msg.Type == protocol.TypeMic {
+				mic, _ := msg.GetMicData()
+				mics <- mic
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	client := NewClient(cfg, nil)
+
+	configs := make(chan protocol.ConfigUpdate, 2)
+	client.OnConfigUpdate(func(u protocol.ConfigUpdate) { configs <- u })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-configs:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for config updates")
+		}
+	}
+
+	want := audio.SupportedCodecs()[0]
+	if got := client.MicCodec(); got != want {
+		t.Fatalf("MicCodec() = %q, want %q", got, want)
+	}
+
+	// 100ms of 16kHz mono is a whole number of Opus frames
+	if err := client.SendMic(make([]byte, 3200), 16000, 1, nil); err != nil {
+		t.Fatalf("SendMic() error = %v", err)
+	}
+
+	select {
+	case mic := <-mics:
+		payload, err := mic.DecodeMicData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcm, err := audio.DecodePayload(mic.Codec, payload, mic.SampleRate, mic.Channels)
+		if err != nil {
+			t.Fatalf("DecodePayload() error = %v", err)
+		}
+		if mic.Codec != want || mic.Seq != 1 || len(pcm) != 3200 {
+			t.Errorf("unexpected mic data codec=%s seq=%d pcm=%d", mic.Codec, mic.Seq, len(pcm))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for mic data")
+	}
+
+	if stats := client.GetStats(); stats.MicChunks != 1 || stats.MicCodec != want {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestOfflineQueueReplay(t *testing.T) {
+	received := make(chan *protocol.Message, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msg, err := protocol.ParseMessage(data); err == nil && msg.Type != protocol.TypeHello {
+				received <- msg
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	client := NewClient(cfg, nil)
+
+	q, err := OpenQueue(QueueConfig{MaxMessages: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetQueue(q)
+
+	// Sent while offline: DOA is queued, frames are not
+	var stamps []int64
+	for i := 0; i < 3; i++ {
+		msg, _ := protocol.NewDOAMessage(float64(i), 0, false, false, 0.5)
+		msg.Timestamp -= int64(10 * (3 - i))
+		stamps = append(stamps, msg.Timestamp)
+		if err := client.SendMessage(msg); err != nil {
+			t.Fatalf("SendMessage() offline error = %v", err)
+		}
+	}
+	if err := client.SendFrame(640, 480, []byte("jpeg"), 1); err == nil {
+		t.Error("frames should not be queued")
+	}
+	if stats := client.GetStats(); stats.QueueLength != 3 || stats.MessagesQueued != 3 {
+		t.Fatalf("stats before connect = %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-received:
+			if msg.Type != protocol.TypeDOA || msg.Timestamp != stamps[i] {
+				t.Errorf("replayed %d: %s at %d, want doa at %d", i, msg.Type, msg.Timestamp, stamps[i])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for replayed message %d", i)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for client.GetStats().MessagesReplayed != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := client.GetStats(); stats.QueueLength != 0 || stats.MessagesReplayed != 3 {
+		t.Errorf("stats after replay = %+v", stats)
+	}
+}
+
+func TestRequestAck(t *testing.T) {
+	acks := make(chan protocol.AckData, 10)
+	ackTraces := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Ask the robot to ack a motor command
+		cmd, _ := protocol.NewMessage(protocol.TypeMotor, protocol.MotorCommand{BodyYaw: 0.1})
+		cmd.ID = "cmd-1"
+		cmd.TraceID = "trace-1"
+		data, _ := json.Marshal(cmd)
+		conn.WriteMessage(websocket.TextMessage, data)
+
+		requests := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := protocol.ParseMessage(data)
+			if err != nil {
+				continue
+			}
+			if msg.Type == protocol.TypeAck {
+				ack, _ := msg.GetAckData()
+				acks <- *ack
+				ackTraces <- msg.TraceID
+				continue
+			}
+			if msg.ID == "" {
+				continue
+			}
+
+			// First request is acked, second rejected, third left unanswered
+			requests++
+			var reply *protocol.Message
+			switch requests {
+			case 1:
+				reply, _ = protocol.NewAckMessage(msg.ID, nil)
+			case 2:
+				reply, _ = protocol.NewAckMessage(msg.ID, errors.New("busy"))
+			default:
+				continue
+			}
+			data, _ = json.Marshal(reply)
+			conn.WriteMessage(websocket.TextMessage, data)
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.RequestTimeout = 200 * time.Millisecond
+	client := NewClient(cfg, nil)
+	client.OnMotorCommand(func(cmd protocol.MotorCommand) {
+		client.AckContext(trace.WithID(context.Background(), cmd.TraceID), cmd.ID, nil)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case ack := <-acks:
+		if ack.ID != "cmd-1" || !ack.OK {
+			t.Errorf("unexpected command ack %+v", ack)
+		}
+		if id := <-ackTraces; id != "trace-1" {
+			t.Errorf("ack trace_id = %q, want trace-1", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for command ack")
+	}
+
+	request := func() (protocol.AckData, error) {
+		msg, _ := protocol.NewDOAMessage(0.5, 0.5, true, true, 0.9)
+		return client.Request(ctx, msg)
+	}
+
+	ack, err := request()
+	if err != nil || !ack.OK || ack.ID == "" {
+		t.Errorf("Request() = %+v, %v; want ok", ack, err)
+	}
+
+	if _, err := request(); !errors.Is(err, ErrRejected) || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("Request() error = %v, want ErrRejected: busy", err)
+	}
+
+	if _, err := request(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Request() error = %v, want deadline exceeded", err)
+	}
+
+	stats := client.GetStats()
+	if stats.AcksSent != 1 || stats.RequestTimeouts != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestConnectionState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// Drop every connection shortly after the hello
+		time.Sleep(30 * time.Millisecond)
+		conn.Close()
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	client := NewClient(cfg, nil)
+	if client.State() != StateClosed {
+		t.Fatalf("initial state = %s, want closed", client.State())
+	}
+
+	var connects, reconnects, disconnects atomic.Int32
+	events := make(chan ConnectionEvent, 64)
+	client.OnConnect(func() { connects.Add(1) })
+	client.OnReconnect(func() { reconnects.Add(1) })
+	client.OnDisconnect(func(error) { disconnects.Add(1) })
+	client.OnStateChange(func(ev ConnectionEvent) { events <- ev })
+
+	client.Connect(context.Background())
+	deadline := time.Now().Add(2 * time.Second)
+	for reconnects.Load() < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	client.Close()
+
+	if client.State() != StateClosed {
+		t.Errorf("state after Close = %s, want closed", client.State())
+	}
+	if connects.Load() < 2 || reconnects.Load() != connects.Load()-1 || disconnects.Load() != connects.Load() {
+		t.Errorf("connects = %d, reconnects = %d, disconnects = %d", connects.Load(), reconnects.Load(), disconnects.Load())
+	}
+
+	// The first transitions are closed → connecting → connected → connecting
+	// (with the reason the connection dropped)
+	var got []ConnectionEvent
+	for len(events) > 0 {
+		got = append(got, <-events)
+	}
+	if len(got) < 3 || got[0].To != StateConnecting || got[1].To != StateConnected ||
+		got[2].From != StateConnected || got[2].Error == "" {
+		t.Errorf("transitions = %+v", got)
+	}
+	if last := got[len(got)-1]; last.To != StateClosed {
+		t.Errorf("last transition = %+v, want closed", last)
+	}
+}
+
+func TestConnectionState_Backoff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.URL = "ws://127.0.0.1:1"
+	cfg.ReconnectBackoff = time.Second
+	client := NewClient(cfg, nil)
+
+	backoff := make(chan ConnectionEvent, 1)
+	client.OnStateChange(func(ev ConnectionEvent) {
+		if ev.To == StateBackoff {
+			backoff <- ev
+		}
+	})
+	client.Connect(context.Background())
+	defer client.Close()
+
+	select {
+	case ev := <-backoff:
+		if ev.Error == "" || ev.RetryInMs != 1000 || ev.Failures != 1 {
+			t.Errorf("backoff event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no backoff after a failed dial")
+	}
+	if stats := client.GetStats(); stats.State != StateBackoff {
+		t.Errorf("stats state = %s, want backoff", stats.State)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	// The primary refuses connections until it comes back
+	var primaryUp atomic.Bool
+	serve := func(up func() bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !up() {
+				http.Error(w, "down", http.StatusServiceUnavailable)
+				return
+			}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}))
+	}
+	primary := serve(primaryUp.Load)
+	defer primary.Close()
+	fallback := serve(func() bool { return true })
+	defer fallback.Close()
+
+	cfg := DefaultConfig()
+	cfg.URLs = []string{"ws" + strings.TrimPrefix(primary.URL, "http"), "ws" + strings.TrimPrefix(fallback.URL, "http")}
+	cfg.ReconnectBackoff = 20 * time.Millisecond
+	cfg.MaxBackoff = 20 * time.Millisecond
+	cfg.FailoverAfter = 50 * time.Millisecond
+	cfg.PrimaryRetry = 50 * time.Millisecond
+	client := NewClient(cfg, nil)
+	client.Connect(context.Background())
+	defer client.Close()
+
+	waitFor := func(what string, cond func(Stats) bool) Stats {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if stats := client.GetStats(); cond(stats) {
+				return stats
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %s: %+v", what, client.GetStats())
+		return Stats{}
+	}
+
+	stats := waitFor("failover", func(s Stats) bool { return s.Connected && !s.Primary })
+	if stats.Endpoint != cfg.URLs[1] || stats.Failovers != 1 {
+		t.Errorf("endpoint = %s after %d failovers, want the fallback after 1", stats.Endpoint, stats.Failovers)
+	}
+
+	primaryUp.Store(true)
+	stats = waitFor("return to the primary", func(s Stats) bool { return s.Connected && s.Primary })
+	if stats.Endpoint != cfg.URLs[0] || stats.PrimaryReturns != 1 {
+		t.Errorf("endpoint = %s after %d returns, want the primary after 1", stats.Endpoint, stats.PrimaryReturns)
+	}
+}
+
+func TestShutdown_DrainsAndSaysGoingAway(t *testing.T) {
+	var doaReceived atomic.Int32
+	closed := make(chan *websocket.CloseError, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				var ce *websocket.CloseError
+				if errors.As(err, &ce) {
+					closed <- ce
+				}
+				return
+			}
+			var msg protocol.Message
+			if json.Unmarshal(data, &msg) == nil && msg.Type == protocol.TypeDOA {
+				doaReceived.Add(1)
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	// Slow enough that messages are still queued when shutdown starts
+	cfg.SendRate = 2000
+	cfg.SendBurst = 100
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.Connect(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := client.SendDOA(0.5, 0.48, true, true, 0.9); err != nil {
+			t.Fatalf("SendDOA() error = %v", err)
+		}
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer shutdownCancel()
+	if err := client.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case ce := <-closed:
+		if ce.Code != websocket.CloseGoingAway || ce.Text == "" {
+			t.Errorf("close = %d %q, want going away with a reason", ce.Code, ce.Text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cloud saw no close frame")
+	}
+	if n := doaReceived.Load(); n != 5 {
+		t.Errorf("cloud received %d DOA messages before the close, want 5", n)
+	}
+	if client.GetStats().State != StateClosed {
+		t.Errorf("state = %s, want closed", client.GetStats().State)
+	}
+}
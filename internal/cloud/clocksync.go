@@ -0,0 +1,191 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// clockSyncSamples is how many recent exchanges the estimate picks from
+const clockSyncSamples = 8
+
+// clockSyncBurst pings are sent right after connecting, clockSyncSpacing
+// apart, so the estimate settles before the regular interval takes over
+const (
+	clockSyncBurst   = 4
+	clockSyncSpacing = 250 * time.Millisecond
+)
+
+// clockSync estimates the cloud's clock from ping/pong exchanges. Each sample
+// ties a cloud time to an instant on the robot's monotonic clock, so the
+// estimate holds when the robot's wall clock is stepped (a Pi without an RTC
+// often is, well after boot). The sample with the shortest round trip wins,
+// since queueing delay is what makes a path asymmetric.
+type clockSync struct {
+	mu      sync.Mutex
+	seq     uint64
+	sent    map[uint64]time.Time // Pings awaiting a pong, by seq
+	samples []clockSample        // Oldest first
+}
+
+// clockSample is one ping/pong exchange
+type clockSample struct {
+	at    time.Time     // Midpoint of the round trip (robot, monotonic)
+	cloud time.Time     // The cloud's clock at that instant
+	delay time.Duration // Round trip less the cloud's turnaround
+}
+
+func newClockSync() *clockSync {
+	return &clockSync{sent: make(map[uint64]time.Time)}
+}
+
+// ping registers a ping about to be sent and returns its sequence number
+func (s *clockSync) ping(now time.Time) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	s.sent[s.seq] = now
+	// Pongs that never came back
+	for seq := range s.sent {
+		if seq+clockSyncSamples < s.seq {
+			delete(s.sent, seq)
+		}
+	}
+	return s.seq
+}
+
+// pong records the exchange a pong completes; false if it answers no ping
+// of ours
+func (s *clockSync) pong(d protocol.ClockSyncData, now time.Time) (clockSample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent, ok := s.sent[d.Seq]
+	if !ok || d.Receive == 0 || d.Transmit < d.Receive {
+		return clockSample{}, false
+	}
+	delete(s.sent, d.Seq)
+
+	rtt := now.Sub(sent)
+	sample := clockSample{
+		at:    sent.Add(rtt / 2),
+		cloud: d.Midpoint(),
+		delay: max(rtt-d.Turnaround(), 0),
+	}
+	s.samples = append(s.samples, sample)
+	if len(s.samples) > clockSyncSamples {
+		s.samples = s.samples[1:]
+	}
+	return sample, true
+}
+
+// best returns the sample with the shortest round trip
+func (s *clockSync) best() (clockSample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) == 0 {
+		return clockSample{}, false
+	}
+	best := s.samples[0]
+	for _, sample := range s.samples[1:] {
+		if sample.delay <= best.delay {
+			best = sample
+		}
+	}
+	return best, true
+}
+
+// cloudTime converts t to the cloud's clock. t should come from time.Now()
+// so the monotonic reading is used; times rebuilt from unix ms fall back to
+// the wall clock.
+func (s *clockSync) cloudTime(t time.Time) (time.Time, bool) {
+	best, ok := s.best()
+	if !ok {
+		return time.Time{}, false
+	}
+	return best.cloud.Add(t.Sub(best.at)), true
+}
+
+// offset is how far the cloud's clock is ahead of the robot's wall clock
+func (s *clockSync) offset() (time.Duration, bool) {
+	now := time.Now()
+	cloud, ok := s.cloudTime(now)
+	if !ok {
+		return 0, false
+	}
+	return cloud.Sub(now.Round(0)), true
+}
+
+// cloudMillis converts a robot wall clock time in unix ms to the cloud's
+// clock (0 if there is no estimate yet)
+func (s *clockSync) cloudMillis(ms int64) int64 {
+	offset, ok := s.offset()
+	if !ok || ms == 0 {
+		return 0
+	}
+	return ms + offset.Milliseconds()
+}
+
+// clockSyncLoop pings with clock sync data, a short burst first and then
+// every Config.ClockSync, until conn is closed or replaced
+func (c *Client) clockSyncLoop(ctx context.Context, conn *websocket.Conn) {
+	wait := time.Duration(0)
+	for sent := 0; ; sent++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		c.mu.Lock()
+		current := c.conn
+		c.mu.Unlock()
+		if current != conn {
+			return
+		}
+
+		now := time.Now()
+		msg, err := protocol.NewClockPing(c.clock.ping(now), now)
+		if err != nil {
+			return
+		}
+		if err := c.writeNow(msg); err != nil {
+			c.logger.Debug("clock sync ping failed", "error", err)
+			return
+		}
+
+		wait = c.cfg.ClockSync
+		if sent < clockSyncBurst-1 {
+			wait = clockSyncSpacing
+		}
+	}
+}
+
+// handleClockPong adds the exchange a pong completes to the estimate
+func (c *Client) handleClockPong(data protocol.ClockSyncData, received time.Time) {
+	sample, ok := c.clock.pong(data, received)
+	if !ok {
+		return
+	}
+	c.logger.Debug("clock sync sample",
+		"offset", sample.cloud.Sub(sample.at.Round(0)),
+		"delay", sample.delay,
+	)
+}
+
+// writeNow writes msg straight to the connection, skipping the send queue,
+// so the times a ping or pong carries aren't skewed by queueing
+func (c *Client) writeNow(msg *protocol.Message) error {
+	data, err := json.Marshal(c.stamp(msg))
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	return c.write(msg.Type, websocket.TextMessage, data)
+}
@@ -0,0 +1,52 @@
+package cloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func TestClockSync_PrefersShortestRoundTrip(t *testing.T) {
+	s := newClockSync()
+	if _, ok := s.cloudTime(time.Now()); ok {
+		t.Fatal("estimate before any exchange")
+	}
+
+	start := time.Now()
+	exchange := func(sent time.Time, rtt time.Duration, cloudMid int64) {
+		seq := s.ping(sent)
+		s.pong(protocol.ClockSyncData{Seq: seq, Origin: sent.UnixMilli(), Receive: cloudMid, Transmit: cloudMid}, sent.Add(rtt))
+	}
+	// A slow exchange whose asymmetry puts the cloud 1s off, then a fast one
+	exchange(start, 2*time.Second, start.Add(2*time.Second).UnixMilli())
+	exchange(start.Add(3*time.Second), 10*time.Millisecond, start.Add(3*time.Second+5*time.Millisecond).UnixMilli())
+
+	got, ok := s.cloudTime(start.Add(10 * time.Second))
+	if !ok {
+		t.Fatal("no estimate")
+	}
+	if d := got.Sub(start.Add(10 * time.Second)); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("cloudTime is %v off, want the fast exchange's estimate", d)
+	}
+
+	// Pongs for unknown pings are ignored
+	if _, ok := s.pong(protocol.ClockSyncData{Seq: 99, Origin: 1, Receive: 1, Transmit: 1}, time.Now()); ok {
+		t.Error("pong for an unknown ping was accepted")
+	}
+}
+
+func TestClockSync_TracksElapsedTime(t *testing.T) {
+	s := newClockSync()
+	sent := time.Now()
+	seq := s.ping(sent)
+	cloudMid := sent.Add(time.Hour) // The robot's wall clock is an hour behind
+	s.pong(protocol.ClockSyncData{Seq: seq, Origin: sent.UnixMilli(), Receive: cloudMid.UnixMilli(), Transmit: cloudMid.UnixMilli()}, sent)
+
+	// Later instants keep the offset from the exchange
+	later := sent.Add(30 * time.Second)
+	got, _ := s.cloudTime(later)
+	if d := got.Sub(cloudMid.Add(30 * time.Second)); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("cloudTime is %v off", d)
+	}
+}
@@ -0,0 +1,73 @@
+package cloud
+
+import (
+	"compress/flate"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// CompressionConfig controls compression on the cloud link. Both kinds are
+// only used when the cloud agrees to them.
+type CompressionConfig struct {
+	Deflate       bool // Offer permessage-deflate for JSON messages
+	Level         int  // Flate level 1-9 for deflate and gzip (0 = 1, fastest)
+	GzipThreshold int  // Gzip JSON messages of at least this many bytes (0 = never)
+}
+
+// deflateNegotiated reports whether the handshake response accepted
+// permessage-deflate
+func deflateNegotiated(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	for _, ext := range resp.Header.Values("Sec-Websocket-Extensions") {
+		if strings.Contains(ext, "permessage-deflate") {
+			return true
+		}
+	}
+	return false
+}
+
+// compressionLevel returns the configured flate level
+func (c *Client) compressionLevel() int {
+	if c.cfg.Compression.Level == 0 {
+		return flate.BestSpeed
+	}
+	return c.cfg.Compression.Level
+}
+
+// compress gzips a JSON message at or above the threshold if the cloud
+// accepts gzip, returning the WebSocket message type and payload to send.
+// Messages that don't shrink are sent as they are.
+func (c *Client) compress(wsType int, data []byte) (int, []byte) {
+	threshold := c.cfg.Compression.GzipThreshold
+	if wsType != websocket.TextMessage || threshold <= 0 || len(data) < threshold {
+		return wsType, data
+	}
+	c.mu.Lock()
+	gzip := c.cloudGzip
+	c.mu.Unlock()
+	if !gzip {
+		return wsType, data
+	}
+
+	zipped, err := protocol.GzipJSON(data, c.compressionLevel())
+	if err != nil || len(zipped) >= len(data) {
+		return wsType, data
+	}
+	c.gzipMessages.Add(1)
+	c.gzipSaved.Add(uint64(len(data) - len(zipped)))
+	return websocket.BinaryMessage, zipped
+}
+
+// decompress inflates a gzip-compressed message from the cloud; anything
+// else is returned unchanged
+func decompress(wsType int, data []byte) ([]byte, error) {
+	if wsType != websocket.BinaryMessage || !protocol.IsGzip(data) {
+		return data, nil
+	}
+	return protocol.GunzipJSON(data)
+}
@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// batchingDOA reports whether DOA readings are held for the next batch: the
+// robot offers batching and the connected cloud's hello accepted it
+func (c *Client) batchingDOA() bool {
+	if c.cfg.DOABatch <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected && c.cloudDOABatch
+}
+
+// bufferDOA holds a reading for the next batch. A reading already buffered
+// (same capture time) is not added again.
+func (c *Client) bufferDOA(data protocol.DOAData) {
+	c.doaMu.Lock()
+	defer c.doaMu.Unlock()
+
+	if n := len(c.doaBatch); n > 0 && data.CapturedAt != 0 && c.doaBatch[n-1].CapturedAt == data.CapturedAt {
+		return
+	}
+	c.doaBatch = append(c.doaBatch, data)
+}
+
+// doaBatchLoop sends the buffered DOA readings every DOABatch interval
+// while conn is the connection, and whatever is left when it ends
+func (c *Client) doaBatchLoop(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(c.cfg.DOABatch)
+	defer ticker.Stop()
+	defer c.flushDOA()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		current := c.conn
+		c.mu.Unlock()
+		if current != conn {
+			return
+		}
+		c.flushDOA()
+	}
+}
+
+// flushDOA sends the buffered DOA readings as one batch. Like single
+// readings, a batch that can't be sent goes to the offline queue.
+func (c *Client) flushDOA() {
+	c.doaMu.Lock()
+	readings := c.doaBatch
+	c.doaBatch = nil
+	c.doaMu.Unlock()
+	if len(readings) == 0 {
+		return
+	}
+
+	msg, err := protocol.NewDOABatchMessage(readings)
+	if err != nil {
+		return
+	}
+	if err := c.SendMessage(msg); err != nil {
+		c.logger.Debug("doa batch send failed", "readings", len(readings), "error", err)
+		return
+	}
+	c.doaBatches.Add(1)
+	c.doaBatched.Add(uint64(len(readings)))
+}
@@ -0,0 +1,93 @@
+package cloud
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// endpoints returns the cloud URLs in priority order
+func (c *Client) endpoints() []string {
+	if len(c.cfg.URLs) > 0 {
+		return c.cfg.URLs
+	}
+	return []string{c.cfg.URL}
+}
+
+// Endpoint returns the URL the client is connected to, or trying next
+func (c *Client) Endpoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoints()[c.endpoint]
+}
+
+// OnPrimary reports whether the active endpoint is the first one
+func (c *Client) OnPrimary() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoint == 0
+}
+
+// failover moves to the next endpoint, wrapping back to the primary after
+// the last. It reports whether there was another endpoint to move to.
+func (c *Client) failover() bool {
+	urls := c.endpoints()
+	if len(urls) < 2 {
+		return false
+	}
+
+	c.mu.Lock()
+	from := urls[c.endpoint]
+	c.endpoint = (c.endpoint + 1) % len(urls)
+	to := urls[c.endpoint]
+	c.mu.Unlock()
+
+	c.failovers.Add(1)
+	c.logger.Warn("cloud endpoint unreachable, failing over", "from", from, "to", to)
+	return true
+}
+
+// primaryLoop checks the primary endpoint every PrimaryRetry while conn is
+// a fallback connection. Once the primary accepts a WebSocket, conn is
+// closed so the connection loop reconnects to the primary.
+func (c *Client) primaryLoop(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(c.cfg.PrimaryRetry)
+	defer ticker.Stop()
+
+	primary := c.endpoints()[0]
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		current := c.conn
+		c.mu.Unlock()
+		if current != conn {
+			return
+		}
+
+		probe, _, err := c.dial(ctx, primary)
+		if err != nil {
+			c.logger.Debug("primary cloud endpoint still unreachable", "url", primary, "error", err)
+			continue
+		}
+		probe.Close()
+
+		c.mu.Lock()
+		if c.conn != conn {
+			c.mu.Unlock()
+			return
+		}
+		c.endpoint = 0
+		c.mu.Unlock()
+
+		c.primaryReturns.Add(1)
+		c.logger.Info("primary cloud endpoint is back, reconnecting", "url", primary)
+		c.closeConnection()
+		return
+	}
+}
@@ -0,0 +1,79 @@
+package cloud
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers cloud client collectors
+func (c *Client) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_connected",
+			Help: "Cloud WebSocket connection state (1=connected, 0=disconnected)",
+		}, func() float64 { return boolToFloat(c.IsConnected()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_messages_sent_total",
+			Help: "Messages sent to cloud",
+		}, func() float64 { return float64(c.messagesSent.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_messages_received_total",
+			Help: "Messages received from cloud",
+		}, func() float64 { return float64(c.messagesReceived.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_messages_blocked_total",
+			Help: "Outgoing messages dropped by privacy mode",
+		}, func() float64 { return float64(c.messagesBlocked.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_reconnects_total",
+			Help: "Cloud reconnect attempts",
+		}, func() float64 { return float64(c.reconnects.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_queue_length",
+			Help: "Messages waiting in the offline queue",
+		}, func() float64 { return float64(c.GetStats().QueueLength) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_queue_dropped_total",
+			Help: "Messages dropped from a full offline queue",
+		}, func() float64 { return float64(c.GetStats().QueueDropped) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_send_queue_length",
+			Help: "Messages waiting for the cloud writer",
+		}, func() float64 { return float64(c.sendQueue.Len()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_send_queue_delay_seconds",
+			Help: "Smoothed time outgoing messages wait for the cloud writer",
+		}, func() float64 { return c.Uplink().QueueDelay.Seconds() }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_gzip_messages_total",
+			Help: "Messages sent gzip-compressed",
+		}, func() float64 { return float64(c.gzipMessages.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_cloud_gzip_saved_bytes_total",
+			Help: "Bytes saved by gzip-compressing messages",
+		}, func() float64 { return float64(c.gzipSaved.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_clock_offset_seconds",
+			Help: "Estimated cloud clock minus the robot's wall clock (0 until synced)",
+		}, func() float64 {
+			offset, _ := c.clock.offset()
+			return offset.Seconds()
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_cloud_clock_delay_seconds",
+			Help: "Round trip of the clock sync exchange the offset comes from",
+		}, func() float64 {
+			best, _ := c.clock.best()
+			return best.delay.Seconds()
+		}),
+		c.sendDroppedType,
+		c.sendLatency,
+		c.stateGauge,
+		c.transitions,
+	)
+}
+
+// boolToFloat converts a flag to 1 or 0 for gauges
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
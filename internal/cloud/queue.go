@@ -0,0 +1,252 @@
+package cloud
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Drop policies for a full queue
+const (
+	DropOldest = "oldest" // Evict the oldest queued message
+	DropNewest = "newest" // Reject the incoming message
+)
+
+// ErrQueueFull is returned when the queue rejects a message under DropNewest
+var ErrQueueFull = errors.New("offline queue full")
+
+// QueueConfig configures the store-and-forward queue used while disconnected
+type QueueConfig struct {
+	Path        string        // Spool file so queued messages survive restarts ("" = memory only)
+	MaxMessages int           // Queue length limit
+	MaxAge      time.Duration // Older messages are discarded instead of replayed (0 = no limit)
+	DropPolicy  string        // DropOldest or DropNewest
+}
+
+// DefaultQueueConfig returns sensible defaults
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		Path:        "/var/lib/go-eva/cloud-queue.jsonl",
+		MaxMessages: 10000,
+		MaxAge:      time.Hour,
+		DropPolicy:  DropOldest,
+	}
+}
+
+// queuedMessage is an encoded message waiting for the connection
+type queuedMessage struct {
+	Type protocol.MessageType
+	Time time.Time // Original message timestamp
+	Data []byte
+}
+
+// Queue is a bounded FIFO of encoded messages, mirrored to an append-only
+// spool file when a path is configured
+type Queue struct {
+	cfg QueueConfig
+
+	mu        sync.Mutex
+	items     []queuedMessage
+	file      *os.File
+	fileLines int // Lines in the spool file, including already removed items
+
+	dropped atomic.Uint64
+	expired atomic.Uint64
+}
+
+// OpenQueue creates a queue, loading any messages left in the spool file
+func OpenQueue(cfg QueueConfig) (*Queue, error) {
+	if cfg.MaxMessages <= 0 {
+		cfg.MaxMessages = DefaultQueueConfig().MaxMessages
+	}
+	if cfg.DropPolicy == "" {
+		cfg.DropPolicy = DropOldest
+	}
+	if cfg.DropPolicy != DropOldest && cfg.DropPolicy != DropNewest {
+		return nil, fmt.Errorf("unknown drop policy %q", cfg.DropPolicy)
+	}
+
+	q := &Queue{cfg: cfg}
+	if cfg.Path == "" {
+		return q, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("create queue dir: %w", err)
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load reads the spool file, keeping the newest MaxMessages entries
+func (q *Queue) load() error {
+	f, err := os.Open(q.cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open queue: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var head struct {
+			Type protocol.MessageType `json:"type"`
+			TS   int64                `json:"ts"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &head); err != nil || head.Type == "" {
+			continue // Torn write at crash time
+		}
+		q.items = append(q.items, queuedMessage{
+			Type: head.Type,
+			Time: time.UnixMilli(head.TS),
+			Data: append([]byte(nil), scanner.Bytes()...),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read queue: %w", err)
+	}
+
+	if over := len(q.items) - q.cfg.MaxMessages; over > 0 {
+		q.items = q.items[over:]
+		q.dropped.Add(uint64(over))
+	}
+	return nil
+}
+
+// compact rewrites the spool file with only the queued messages
+func (q *Queue) compact() error {
+	if q.file != nil {
+		q.file.Close()
+		q.file = nil
+	}
+
+	tmp := q.cfg.Path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create queue: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, item := range q.items {
+		w.Write(item.Data)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("write queue: %w", err)
+	}
+	f.Close()
+	if err := os.Rename(tmp, q.cfg.Path); err != nil {
+		return fmt.Errorf("replace queue: %w", err)
+	}
+
+	q.file, err = os.OpenFile(q.cfg.Path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open queue: %w", err)
+	}
+	q.fileLines = len(q.items)
+	return nil
+}
+
+// Push appends a message, applying the drop policy when full
+func (q *Queue) Push(msgType protocol.MessageType, ts time.Time, data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.cfg.MaxMessages {
+		q.dropped.Add(1)
+		if q.cfg.DropPolicy == DropNewest {
+			return ErrQueueFull
+		}
+		q.items = q.items[1:]
+	}
+	q.items = append(q.items, queuedMessage{Type: msgType, Time: ts, Data: data})
+
+	if q.file == nil {
+		return nil
+	}
+	if q.fileLines >= 2*q.cfg.MaxMessages {
+		return q.compact()
+	}
+	q.fileLines++
+	if _, err := q.file.Write(append(data[:len(data):len(data)], '\n')); err != nil {
+		return fmt.Errorf("write queue: %w", err)
+	}
+	return nil
+}
+
+// Peek returns the oldest message that is still within MaxAge
+func (q *Queue) Peek() (queuedMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) > 0 {
+		item := q.items[0]
+		if q.cfg.MaxAge <= 0 || time.Since(item.Time) <= q.cfg.MaxAge {
+			return item, true
+		}
+		q.items = q.items[1:]
+		q.expired.Add(1)
+	}
+	q.truncate()
+	return queuedMessage{}, false
+}
+
+// Pop removes the oldest message
+func (q *Queue) Pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) > 0 {
+		q.items = q.items[1:]
+	}
+	q.truncate()
+}
+
+// truncate empties the spool file once everything has been sent
+func (q *Queue) truncate() {
+	if len(q.items) > 0 || q.file == nil || q.fileLines == 0 {
+		return
+	}
+	if err := q.file.Truncate(0); err == nil {
+		q.fileLines = 0
+	}
+}
+
+// Len returns the number of queued messages
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Close flushes the remaining messages to the spool file
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return nil
+	}
+	err := q.compact()
+	if q.file != nil {
+		q.file.Close()
+		q.file = nil
+	}
+	return err
+}
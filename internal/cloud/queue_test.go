@@ -0,0 +1,127 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func pushN(t *testing.T, q *Queue, from, to int, ts time.Time) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		data := []byte(fmt.Sprintf(`{"type":"doa","ts":%d,"data":{"angle":%d}}`, ts.UnixMilli(), i))
+		if err := q.Push(protocol.TypeDOA, ts, data); err != nil {
+			t.Fatalf("Push(%d) error = %v", i, err)
+		}
+	}
+}
+
+func drain(q *Queue) []string {
+	var out []string
+	for {
+		item, ok := q.Peek()
+		if !ok {
+			return out
+		}
+		out = append(out, string(item.Data))
+		q.Pop()
+	}
+}
+
+func TestQueue_DropOldest(t *testing.T) {
+	q, err := OpenQueue(QueueConfig{MaxMessages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushN(t, q, 0, 5, time.Now())
+	got := drain(q)
+	if len(got) != 3 {
+		t.Fatalf("drained %v", got)
+	}
+	for i, want := range []string{`"angle":2}`, `"angle":3}`, `"angle":4}`} {
+		if !strings.HasSuffix(got[i], want+"}") {
+			t.Errorf("item %d = %s, want %s", i, got[i], want)
+		}
+	}
+	if q.dropped.Load() != 2 {
+		t.Errorf("dropped = %d, want 2", q.dropped.Load())
+	}
+}
+
+func TestQueue_DropNewest(t *testing.T) {
+	q, err := OpenQueue(QueueConfig{MaxMessages: 2, DropPolicy: DropNewest})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushN(t, q, 0, 2, time.Now())
+	if err := q.Push(protocol.TypeDOA, time.Now(), []byte(`{}`)); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Push() on full queue error = %v, want ErrQueueFull", err)
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", q.Len())
+	}
+
+	if _, err := OpenQueue(QueueConfig{DropPolicy: "random"}); err == nil {
+		t.Error("unknown drop policy should fail")
+	}
+}
+
+func TestQueue_MaxAge(t *testing.T) {
+	q, err := OpenQueue(QueueConfig{MaxMessages: 10, MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushN(t, q, 0, 2, time.Now().Add(-time.Hour))
+	pushN(t, q, 2, 3, time.Now())
+
+	if got := drain(q); len(got) != 1 {
+		t.Errorf("drained %v, want only the fresh message", got)
+	}
+	if q.expired.Load() != 2 {
+		t.Errorf("expired = %d, want 2", q.expired.Load())
+	}
+}
+
+func TestQueue_Persistence(t *testing.T) {
+	cfg := QueueConfig{Path: filepath.Join(t.TempDir(), "spool", "queue.jsonl"), MaxMessages: 4}
+
+	q, err := OpenQueue(cfg)
+	if err != nil {
+		t.Fatalf("OpenQueue() error = %v", err)
+	}
+	ts := time.UnixMilli(time.Now().UnixMilli())
+	pushN(t, q, 0, 6, ts) // Forces a compaction
+	q.Pop()
+	q.Close()
+
+	reopened, err := OpenQueue(cfg)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	item, ok := reopened.Peek()
+	if !ok || reopened.Len() != 3 {
+		t.Fatalf("reopened queue has %d messages", reopened.Len())
+	}
+	if item.Type != protocol.TypeDOA || !item.Time.Equal(ts) {
+		t.Errorf("first item type %s at %v, want doa at %v", item.Type, item.Time, ts)
+	}
+
+	// Draining empties the spool file
+	drain(reopened)
+	reopened.Close()
+	empty, err := OpenQueue(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Len() != 0 {
+		t.Errorf("spool has %d messages after drain", empty.Len())
+	}
+}
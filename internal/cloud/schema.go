@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	"slices"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// handleCloudHello records the protocol version the cloud chose and whether
+// it accepts gzip and DOA batches. A cloud that never says hello predates versioning and is
+// treated as version 1.
+func (c *Client) handleCloudHello(msg *protocol.Message) {
+	var hello protocol.HelloData
+	if err := c.decode(msg, &hello); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.cloudProtocol = hello.ProtocolVersion
+	c.cloudGzip = slices.Contains(hello.Capabilities, protocol.CapabilityGzip)
+	c.cloudDOABatch = slices.Contains(hello.Capabilities, protocol.CapabilityDOABatch)
+	c.mu.Unlock()
+
+	if err := protocol.CheckVersion(hello.ProtocolVersion); err != nil {
+		c.logger.Error("cloud speaks an incompatible protocol, its commands will be refused",
+			"cloud_protocol", hello.ProtocolVersion,
+			"error", err,
+		)
+		return
+	}
+	c.logger.Info("cloud protocol negotiated", "protocol_version", hello.ProtocolVersion, "cloud_version", hello.Version)
+}
+
+// checkCloudProtocol reports whether commands from the connected cloud can
+// be trusted to mean what this build thinks they mean
+func (c *Client) checkCloudProtocol() error {
+	c.mu.Lock()
+	v := c.cloudProtocol
+	c.mu.Unlock()
+	return protocol.CheckVersion(v)
+}
+
+// decode parses msg into v and checks it against the type's schema. Unknown
+// fields are counted and logged once each, or rejected with StrictProtocol.
+func (c *Client) decode(msg *protocol.Message, v interface{}) error {
+	unknown, err := msg.Decode(v, c.cfg.StrictProtocol)
+	if len(unknown) > 0 {
+		c.unknownFields.Add(uint64(len(unknown)))
+		c.warnUnknownFields(msg.Type, unknown)
+	}
+	if err != nil {
+		c.schemaErrors.Add(1)
+		c.logger.Warn("invalid cloud message", "type", msg.Type, "error", err)
+	}
+	return err
+}
+
+// warnUnknownFields logs fields not seen before; the same fields arrive with
+// every message once the cloud's schema changes
+func (c *Client) warnUnknownFields(msgType protocol.MessageType, fields []string) {
+	var fresh []string
+	c.mu.Lock()
+	for _, f := range fields {
+		key := string(msgType) + "." + f
+		if !c.warnedFields[key] {
+			c.warnedFields[key] = true
+			fresh = append(fresh, f)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(fresh) > 0 {
+		c.logger.Warn("cloud message has fields this build doesn't know, the cloud's protocol may have changed",
+			"type", msgType,
+			"fields", fresh,
+			"strict", c.cfg.StrictProtocol,
+		)
+	}
+}
@@ -0,0 +1,83 @@
+package cloud
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// cloudMessage builds raw cloud JSON for handleMessage
+func cloudMessage(t *testing.T, msgType protocol.MessageType, id, data string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(protocol.Message{Type: msgType, ID: id, Data: json.RawMessage(data)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestHandleMessage_Schema(t *testing.T) {
+	client := NewClient(DefaultConfig(), nil)
+	var motors []protocol.MotorCommand
+	client.OnMotorCommand(func(cmd protocol.MotorCommand) { motors = append(motors, cmd) })
+	emotions := 0
+	client.OnEmotionCommand(func(protocol.EmotionCommand) { emotions++ })
+
+	// Unknown fields are tolerated and counted
+	client.handleMessage(cloudMessage(t, protocol.TypeMotor, "m1", `{"head":{"yaw":0.5,"speed":2},"gaze":1}`))
+	client.handleMessage(cloudMessage(t, protocol.TypeMotor, "m2", `{"head":{"yaw":0.5,"speed":2},"gaze":1}`))
+	if len(motors) != 2 || motors[0].Head.Yaw != 0.5 || motors[0].ID != "m1" {
+		t.Fatalf("motors = %+v", motors)
+	}
+
+	// Schema violations are nacked instead of delivered
+	client.handleMessage(cloudMessage(t, protocol.TypeEmotion, "e1", `{"duration":2}`))
+	if emotions != 0 {
+		t.Error("emotion without a name was delivered")
+	}
+
+	stats := client.GetStats()
+	if stats.UnknownFields != 4 || stats.SchemaErrors != 1 || stats.NacksSent != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestHandleMessage_Strict(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.StrictProtocol = true
+	client := NewClient(cfg, nil)
+	motors := 0
+	client.OnMotorCommand(func(protocol.MotorCommand) { motors++ })
+
+	client.handleMessage(cloudMessage(t, protocol.TypeMotor, "m1", `{"head":{"yaw":0.5},"gaze":1}`))
+	client.handleMessage(cloudMessage(t, protocol.TypeMotor, "m2", `{"head":{"yaw":0.5}}`))
+	if motors != 1 {
+		t.Errorf("delivered %d motor commands, want only the one without unknown fields", motors)
+	}
+	if stats := client.GetStats(); stats.SchemaErrors != 1 || stats.NacksSent != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestHandleMessage_ProtocolVersion(t *testing.T) {
+	client := NewClient(DefaultConfig(), nil)
+	motors := 0
+	client.OnMotorCommand(func(protocol.MotorCommand) { motors++ })
+	motor := cloudMessage(t, protocol.TypeMotor, "m", `{"head":{"yaw":0.1}}`)
+
+	// A cloud that never says hello predates versioning and is accepted
+	client.handleMessage(motor)
+
+	client.handleMessage(cloudMessage(t, protocol.TypeHello, "", `{"protocol_version":99}`))
+	client.handleMessage(motor)
+	if stats := client.GetStats(); motors != 1 || stats.CloudProtocol != 99 || stats.VersionRejected != 1 {
+		t.Errorf("incompatible cloud: motors = %d, stats = %+v", motors, stats)
+	}
+
+	client.handleMessage(cloudMessage(t, protocol.TypeHello, "", `{"protocol_version":2}`))
+	client.handleMessage(motor)
+	if motors != 2 {
+		t.Errorf("compatible cloud: motors = %d, want 2", motors)
+	}
+}
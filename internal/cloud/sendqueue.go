@@ -0,0 +1,181 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// ErrSendQueueFull is returned when the send queue holds only messages that
+// outrank the one being sent
+var ErrSendQueueFull = errors.New("send queue full")
+
+// Send priorities; a full send queue evicts the oldest message of the lowest
+// priority first
+const (
+	priorityBulk    = iota // Video frames: the next one supersedes them
+	priorityNormal         // Telemetry, transcripts and audio
+	priorityControl        // Acks and keepalives: never dropped
+	numPriorities
+)
+
+// sendPriority returns the send priority of msgType
+func sendPriority(msgType protocol.MessageType) int {
+	switch msgType {
+	case protocol.TypeFrame:
+		return priorityBulk
+	case protocol.TypeAck, protocol.TypePong, protocol.TypePing, protocol.TypeConfigApplied:
+		return priorityControl
+	}
+	return priorityNormal
+}
+
+// outgoing is an encoded message waiting for the writer
+type outgoing struct {
+	msgType protocol.MessageType
+	wsType  int
+	data    []byte
+	at      time.Time // Message timestamp, kept if it falls back to the offline queue
+	queued  time.Time // When the message entered the send queue
+}
+
+// sendQueue buffers outgoing messages for the writer goroutine: one FIFO per
+// priority, always drained from the highest
+type sendQueue struct {
+	max int
+
+	mu     sync.Mutex
+	levels [numPriorities][]outgoing
+	size   int
+	ready  chan struct{} // Signalled when a message is pushed
+}
+
+func newSendQueue(max int) *sendQueue {
+	return &sendQueue{max: max, ready: make(chan struct{}, 1)}
+}
+
+// push adds m, evicting a lower or equal priority message when full.
+// Control messages are always accepted.
+func (q *sendQueue) push(m outgoing) (evicted *outgoing, err error) {
+	prio := sendPriority(m.msgType)
+
+	q.mu.Lock()
+	if q.size >= q.max && prio != priorityControl {
+		for p := priorityBulk; p <= prio; p++ {
+			if len(q.levels[p]) > 0 {
+				evicted = &q.levels[p][0]
+				q.levels[p] = q.levels[p][1:]
+				q.size--
+				break
+			}
+		}
+		if evicted == nil {
+			q.mu.Unlock()
+			return nil, ErrSendQueueFull
+		}
+	}
+	q.levels[prio] = append(q.levels[prio], m)
+	q.size++
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return evicted, nil
+}
+
+// pop removes the oldest message of the highest priority
+func (q *sendQueue) pop() (outgoing, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for p := numPriorities - 1; p >= 0; p-- {
+		if len(q.levels[p]) > 0 {
+			m := q.levels[p][0]
+			q.levels[p] = q.levels[p][1:]
+			q.size--
+			return m, true
+		}
+	}
+	return outgoing{}, false
+}
+
+// oldest returns when the longest-waiting message was queued, or the zero
+// time when the queue is empty
+func (q *sendQueue) oldest() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var t time.Time
+	for _, level := range q.levels {
+		if len(level) > 0 && (t.IsZero() || level[0].queued.Before(t)) {
+			t = level[0].queued
+		}
+	}
+	return t
+}
+
+// Len returns the number of buffered messages
+func (q *sendQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// tokenBucket paces outbound bytes. A message larger than the tokens left
+// runs the bucket into debt, delaying the next one; a zero rate never waits.
+type tokenBucket struct {
+	rate  float64 // Bytes per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &tokenBucket{rate: float64(rate), burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes n bytes and returns how long to wait before sending them
+func (b *tokenBucket) reserve(n int, now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait blocks until n bytes may be sent or ctx is cancelled
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	delay := b.reserve(n, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
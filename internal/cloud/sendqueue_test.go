@@ -0,0 +1,159 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func TestSendQueue_Priorities(t *testing.T) {
+	q := newSendQueue(3)
+
+	q.push(outgoing{msgType: protocol.TypeFrame, data: []byte("f1")})
+	q.push(outgoing{msgType: protocol.TypeDOA, data: []byte("d1")})
+	q.push(outgoing{msgType: protocol.TypeFrame, data: []byte("f2")})
+
+	// Full: the oldest frame makes room for telemetry
+	evicted, err := q.push(outgoing{msgType: protocol.TypeDOA, data: []byte("d2")})
+	if err != nil || evicted == nil || string(evicted.data) != "f1" {
+		t.Fatalf("push(doa) evicted %v, %v; want f1", evicted, err)
+	}
+
+	// Acks are never refused, even over the limit
+	if _, err := q.push(outgoing{msgType: protocol.TypeAck, data: []byte("a1")}); err != nil {
+		t.Fatalf("push(ack) error = %v", err)
+	}
+
+	var order []string
+	for {
+		m, ok := q.pop()
+		if !ok {
+			break
+		}
+		order = append(order, string(m.data))
+	}
+	if got := strings.Join(order, ","); got != "a1,d1,d2,f2" {
+		t.Errorf("pop order = %s, want a1,d1,d2,f2", got)
+	}
+}
+
+func TestSendQueue_FullOfHigherPriority(t *testing.T) {
+	q := newSendQueue(2)
+	q.push(outgoing{msgType: protocol.TypeAck})
+	q.push(outgoing{msgType: protocol.TypeAck})
+
+	if _, err := q.push(outgoing{msgType: protocol.TypeFrame}); !errors.Is(err, ErrSendQueueFull) {
+		t.Errorf("push(frame) error = %v, want ErrSendQueueFull", err)
+	}
+
+	// Telemetry evicts older telemetry but never acks
+	q = newSendQueue(2)
+	q.push(outgoing{msgType: protocol.TypeAck})
+	q.push(outgoing{msgType: protocol.TypeDOA, data: []byte("old")})
+	evicted, err := q.push(outgoing{msgType: protocol.TypeDOA, data: []byte("new")})
+	if err != nil || evicted == nil || string(evicted.data) != "old" {
+		t.Errorf("push(doa) evicted %v, %v; want old", evicted, err)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(1000, 500)
+	now := time.Now()
+
+	if d := b.reserve(500, now); d != 0 {
+		t.Errorf("burst reserve waited %v", d)
+	}
+	if d := b.reserve(250, now); d != 250*time.Millisecond {
+		t.Errorf("reserve in debt = %v, want 250ms", d)
+	}
+	// One second refills 1000 bytes, capped at the 500 byte burst
+	if d := b.reserve(500, now.Add(time.Second)); d != 0 {
+		t.Errorf("reserve after refill waited %v", d)
+	}
+
+	if d := newTokenBucket(0, 0).reserve(1<<20, now); d != 0 {
+		t.Errorf("unlimited bucket waited %v", d)
+	}
+}
+
+func TestSendDoesNotBlockOnSlowUplink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	cfg.BinaryFrames = false
+	cfg.SendQueueSize = 4
+	cfg.SendRate = 1000 // A frame every second
+	client := NewClient(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.Connect(ctx)
+	defer client.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	frame := make([]byte, 700)
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := client.SendFrame(640, 480, frame, uint64(i)); err != nil {
+			t.Fatalf("SendFrame(%d) error = %v", i, err)
+		}
+	}
+	if err := client.Ack("cmd-1", nil); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("sending took %v; senders should not wait for the uplink", elapsed)
+	}
+
+	stats := client.GetStats()
+	if stats.SendDropped == 0 {
+		t.Error("old frames should be dropped when the uplink falls behind")
+	}
+	if stats.SendQueueLength > cfg.SendQueueSize+1 {
+		t.Errorf("send queue length = %d, want at most %d", stats.SendQueueLength, cfg.SendQueueSize+1)
+	}
+}
+
+func TestClient_Uplink(t *testing.T) {
+	c := NewClient(DefaultConfig(), nil)
+	if u := c.Uplink(); u.QueueDelay != 0 || u.QueueLength != 0 || u.Connected {
+		t.Fatalf("idle uplink = %+v", u)
+	}
+
+	for range 20 {
+		c.observeQueueDelay(100 * time.Millisecond)
+	}
+	if d := c.Uplink().QueueDelay; d < 95*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("smoothed delay = %v, want about 100ms", d)
+	}
+
+	// A message stuck in the queue counts before it is written
+	c.sendQueue.push(outgoing{msgType: protocol.TypeFrame, queued: time.Now().Add(-time.Second)})
+	c.sendQueue.push(outgoing{msgType: protocol.TypeDOA, queued: time.Now()})
+	if u := c.Uplink(); u.QueueLength != 2 || u.QueueDelay < time.Second {
+		t.Errorf("stalled uplink = %+v", u)
+	}
+}
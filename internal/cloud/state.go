@@ -0,0 +1,127 @@
+package cloud
+
+import (
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// ConnectionState is where the client is in its connect/reconnect cycle
+type ConnectionState string
+
+// Connection states
+const (
+	StateConnecting ConnectionState = "connecting" // Dialing and sending hello
+	StateConnected  ConnectionState = "connected"
+	StateBackoff    ConnectionState = "backoff" // Waiting to retry after a failed attempt
+	StateClosed     ConnectionState = "closed"  // Not started, or shut down
+)
+
+// connectionStates lists every state, for metrics
+var connectionStates = []ConnectionState{StateConnecting, StateConnected, StateBackoff, StateClosed}
+
+// ConnectionEvent describes a connection state transition
+type ConnectionEvent struct {
+	From      ConnectionState `json:"from"`
+	To        ConnectionState `json:"to"`
+	Error     string          `json:"error,omitempty"`       // Why the connection failed or dropped
+	RetryInMs int64           `json:"retry_in_ms,omitempty"` // Backoff before the next attempt
+	Failures  int             `json:"failures,omitempty"`    // Consecutive failed attempts
+	At        time.Time       `json:"at"`
+}
+
+// TopicConnection carries connection state transitions on the event bus
+var TopicConnection = bus.NewTopic[ConnectionEvent]("cloud.connection")
+
+// OnConnect sets a callback run every time a connection is established
+func (c *Client) OnConnect(callback func()) {
+	c.mu.Lock()
+	c.onConnect = callback
+	c.mu.Unlock()
+}
+
+// OnReconnect sets a callback run when a connection is established after an
+// earlier one was lost (in addition to OnConnect)
+func (c *Client) OnReconnect(callback func()) {
+	c.mu.Lock()
+	c.onReconnect = callback
+	c.mu.Unlock()
+}
+
+// OnDisconnect sets a callback run when an established connection ends; err
+// is nil when the client was closed
+func (c *Client) OnDisconnect(callback func(err error)) {
+	c.mu.Lock()
+	c.onDisconnect = callback
+	c.mu.Unlock()
+}
+
+// OnStateChange sets a callback for every connection state transition
+func (c *Client) OnStateChange(callback func(ConnectionEvent)) {
+	c.mu.Lock()
+	c.onStateChange = callback
+	c.mu.Unlock()
+}
+
+// State returns the current connection state
+func (c *Client) State() ConnectionState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// setState moves to state and notifies listeners. err explains a failure or
+// disconnect; retryIn is the backoff when entering StateBackoff. Once the
+// client is closed it stays closed.
+func (c *Client) setState(to ConnectionState, err error, retryIn time.Duration) {
+	c.mu.Lock()
+	from := c.state
+	if from == to || (c.closed && to != StateClosed) {
+		c.mu.Unlock()
+		return
+	}
+	c.state = to
+	c.stateSince = time.Now()
+	switch to {
+	case StateConnected:
+		c.connections++
+		c.failures = 0
+	case StateBackoff:
+		c.failures++
+	}
+	ev := ConnectionEvent{
+		From:      from,
+		To:        to,
+		RetryInMs: retryIn.Milliseconds(),
+		Failures:  c.failures,
+		At:        c.stateSince,
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	reconnected := to == StateConnected && c.connections > 1
+	onStateChange, onConnect, onReconnect, onDisconnect := c.onStateChange, c.onConnect, c.onReconnect, c.onDisconnect
+	c.mu.Unlock()
+
+	for _, s := range connectionStates {
+		c.stateGauge.WithLabelValues(string(s)).Set(boolToFloat(s == to))
+	}
+	c.transitions.WithLabelValues(string(to)).Inc()
+
+	c.logger.Debug("cloud connection state", "from", from, "to", to, "error", ev.Error)
+
+	if onStateChange != nil {
+		onStateChange(ev)
+	}
+	if from == StateConnected && onDisconnect != nil {
+		onDisconnect(err)
+	}
+	if to == StateConnected {
+		if onConnect != nil {
+			onConnect()
+		}
+		if reconnected && onReconnect != nil {
+			onReconnect()
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cloud
+
+import "time"
+
+// queueDelaySmoothing is the weight of each new sample in the smoothed
+// send queue delay
+const queueDelaySmoothing = 0.2
+
+// Uplink describes how well the connection to the cloud keeps up
+type Uplink struct {
+	Connected   bool          `json:"connected"`
+	QueueLength int           `json:"queue_length"` // Messages waiting for the writer
+	QueueDelay  time.Duration `json:"queue_delay"`  // Smoothed time from send to written
+}
+
+// Uplink returns the current uplink load. A message stuck at the head of the
+// queue counts as soon as it has waited longer than the smoothed delay, so a
+// stalled link shows up before anything is written.
+func (c *Client) Uplink() Uplink {
+	delay := time.Duration(c.queueDelay.Load())
+	if oldest := c.sendQueue.oldest(); !oldest.IsZero() {
+		delay = max(delay, time.Since(oldest))
+	}
+	return Uplink{
+		Connected:   c.IsConnected(),
+		QueueLength: c.sendQueue.Len(),
+		QueueDelay:  delay,
+	}
+}
+
+// observeQueueDelay folds a written message's queue delay into the smoothed
+// delay; only the write loop calls it
+func (c *Client) observeQueueDelay(d time.Duration) {
+	prev := time.Duration(c.queueDelay.Load())
+	c.queueDelay.Store(int64(prev + time.Duration(queueDelaySmoothing*float64(d-prev))))
+}
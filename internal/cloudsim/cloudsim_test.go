@@ -0,0 +1,236 @@
+package cloudsim
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/cloud"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// connectRobot starts a simulator and connects a real cloud client to it
+func connectRobot(t *testing.T, cfg Config, rec *Recorder) (*Server, *cloud.Client) {
+	t.Helper()
+
+	sim := New(cfg, rec, nil)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+
+	clientCfg := cloud.DefaultConfig()
+	clientCfg.URL = "ws" + strings.TrimPrefix(ts.URL, "http") + cfg.Path
+	clientCfg.RobotID = "eva-test"
+	clientCfg.Version = "test"
+	client := cloud.NewClient(clientCfg, nil)
+	t.Cleanup(func() { client.Close() })
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	waitFor(t, func() bool { return len(sim.Robots()) == 1 })
+	return sim, client
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_RobotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	rec, err := OpenRecorder(path)
+	if err != nil {
+		t.Fatalf("OpenRecorder() error = %v", err)
+	}
+	sim, client := connectRobot(t, DefaultConfig(), rec)
+
+	if r := sim.Robots()[0]; r.ID != "eva-test" || r.Version != "test" || !r.Binary || r.Protocol != protocol.ProtocolVersion {
+		t.Errorf("robot = %+v", r)
+	}
+	waitFor(t, func() bool { return client.GetStats().CloudProtocol == protocol.ProtocolVersion })
+
+	msg, err := ParseCommand("emotion happy 2")
+	if err != nil {
+		t.Fatalf("ParseCommand() error = %v", err)
+	}
+	emotions := make(chan protocol.EmotionCommand, 1)
+	client.OnEmotionCommand(func(cmd protocol.EmotionCommand) {
+		client.Ack(msg.ID, nil)
+		emotions <- cmd
+	})
+	if n, err := sim.Send("", msg); n != 1 || err != nil {
+		t.Fatalf("Send() = %d, %v", n, err)
+	}
+	select {
+	case cmd := <-emotions:
+		if cmd.Name != "happy" || cmd.Duration != 2 {
+			t.Errorf("emotion = %+v", cmd)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("robot did not receive the emotion")
+	}
+
+	if err := client.SendDOA(0.5, 0.4, true, true, 0.9); err != nil {
+		t.Fatalf("SendDOA() error = %v", err)
+	}
+	if err := client.SendFrame(2, 2, []byte("jpeg"), 7); err != nil {
+		t.Fatalf("SendFrame() error = %v", err)
+	}
+	waitFor(t, func() bool {
+		r := sim.Robots()[0]
+		return r.LastDOA != nil && r.LastFrameID == 7 && r.Acks == 1
+	})
+	if r := sim.Robots()[0]; r.LastDOA.SmoothedAngle != 0.4 {
+		t.Errorf("last DOA = %+v", r.LastDOA)
+	}
+	if frame, ok := sim.Frame("eva-test"); !ok || string(frame) != "jpeg" {
+		t.Errorf("Frame() = %q, %v", frame, ok)
+	}
+
+	if _, err := sim.Send("other", msg); !errors.Is(err, ErrNoRobot) {
+		t.Errorf("Send() to unknown robot error = %v, want ErrNoRobot", err)
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dirs := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var m RecordedMessage
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("bad recording line %q: %v", scanner.Text(), err)
+		}
+		dirs[m.Dir+" "+string(m.Type)]++
+	}
+	for _, want := range []string{"in hello", "out emotion", "in doa", "in frame", "in ack"} {
+		if dirs[want] == 0 {
+			t.Errorf("recording has no %q: %v", want, dirs)
+		}
+	}
+}
+
+func TestServer_Follow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Follow = true
+	_, client := connectRobot(t, cfg, nil)
+
+	motors := make(chan protocol.MotorCommand, 4)
+	client.OnMotorCommand(func(cmd protocol.MotorCommand) { motors <- cmd })
+
+	// Not latched: the cloud stays still
+	client.SendDOA(0.5, 0.3, true, false, 0.9)
+	client.SendDOA(0.5, 0.3, true, true, 0.9)
+
+	select {
+	case cmd := <-motors:
+		if cmd.Head.Yaw != 0.3 {
+			t.Errorf("follow yaw = %v, want 0.3", cmd.Head.Yaw)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no follow motor command")
+	}
+}
+
+func TestServer_TokenAndUI(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Token = "secret"
+	sim := New(cfg, nil, nil)
+
+	rec := httptest.NewRecorder()
+	sim.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, cfg.Path, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("robot without token = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	sim.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "cloud-sim") {
+		t.Errorf("index = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	sim.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/send", strings.NewReader(`{"command":"stop"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("send with no robots = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	sim.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/send", strings.NewReader(`{"command":"dance"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown command = %d, want 400", rec.Code)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    protocol.MessageType
+		wantErr bool
+	}{
+		{"motor 30", protocol.TypeMotor, false},
+		{"motor 30 -10 5", protocol.TypeMotor, false},
+		{"motor", "", true},
+		{"motor left", "", true},
+		{"emotion happy", protocol.TypeEmotion, false},
+		{"emotion", "", true},
+		{`choreo {"keyframes": [{"duration_ms": 400, "head": {"pitch": 0.3}}]}`, protocol.TypeChoreo, false},
+		{"choreo nod", "", true},
+		{"speak hello there", protocol.TypeSpeak, false},
+		{"speak", "", true},
+		{"stop keep", protocol.TypeStopSpeak, false},
+		{"privacy on", protocol.TypePrivacy, false},
+		{"privacy maybe", "", true},
+		{`config {"camera": {"framerate": 5}}`, protocol.TypeConfig, false},
+		{"config {", "", true},
+		{`raw custom {"a": 1}`, "custom", false},
+		{"raw custom {", "", true},
+		{"dance", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			msg, err := ParseCommand(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if msg.Type != tt.want || msg.ID == "" {
+				t.Errorf("ParseCommand() = %s %q", msg.Type, msg.ID)
+			}
+		})
+	}
+
+	msg, _ := ParseCommand("motor 90 0 0")
+	var cmd protocol.MotorCommand
+	if err := msg.ParseData(&cmd); err != nil || math.Abs(cmd.Head.Yaw-math.Pi/2) > 1e-9 {
+		t.Errorf("motor 90 = %+v, %v", cmd, err)
+	}
+	msg, _ = ParseCommand("speak  hello   there")
+	var speak protocol.SpeakData
+	if msg.ParseData(&speak); speak.Text != "hello   there" {
+		t.Errorf("speak text = %q", speak.Text)
+	}
+}
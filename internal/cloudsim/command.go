@@ -0,0 +1,145 @@
+package cloudsim
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// CommandHelp describes the commands ParseCommand understands
+const CommandHelp = `Commands (sent to every robot, or "@robot-id command" for one):
+  motor <yaw> [pitch] [roll]   Move the head (degrees)
+  emotion <name> [seconds]     Play an emotion
+  choreo <json>                Play a motion sequence, e.g. {"keyframes": [{"duration_ms": 400, "head": {"pitch": 0.3}}]}
+  speak <text>                 Speak text with the robot's local TTS
+  stop [keep]                  Stop playback (keep: only the current clip)
+  privacy on|off               Toggle privacy mode
+  config <json>                Send a config update, e.g. {"camera": {"framerate": 5}}
+  raw <type> [json]            Send any message type
+  robots                       List connected robots
+  help                         Show this help`
+
+// ParseCommand turns a command line into a message for the robot. Messages
+// carry an ID so the robot acknowledges them.
+func ParseCommand(line string) (*protocol.Message, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	name, args := fields[0], fields[1:]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), name))
+
+	var msgType protocol.MessageType
+	var data any
+	switch name {
+	case "motor":
+		angles, err := parseFloats(args, 1, 3)
+		if err != nil {
+			return nil, fmt.Errorf("motor: %w", err)
+		}
+		head := protocol.HeadTarget{Yaw: radians(angles[0])}
+		if len(angles) > 1 {
+			head.Pitch = radians(angles[1])
+		}
+		if len(angles) > 2 {
+			head.Roll = radians(angles[2])
+		}
+		msgType, data = protocol.TypeMotor, protocol.MotorCommand{Head: head}
+
+	case "emotion":
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("usage: emotion <name> [seconds]")
+		}
+		cmd := protocol.EmotionCommand{Name: args[0]}
+		if len(args) == 2 {
+			d, err := strconv.ParseFloat(args[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("emotion duration: %w", err)
+			}
+			cmd.Duration = d
+		}
+		msgType, data = protocol.TypeEmotion, cmd
+
+	case "choreo":
+		var cmd protocol.ChoreoCommand
+		if err := json.Unmarshal([]byte(rest), &cmd); err != nil {
+			return nil, fmt.Errorf("choreo: %w", err)
+		}
+		msgType, data = protocol.TypeChoreo, cmd
+
+	case "speak":
+		if rest == "" {
+			return nil, fmt.Errorf("usage: speak <text>")
+		}
+		msgType, data = protocol.TypeSpeak, protocol.SpeakData{Text: rest}
+
+	case "stop":
+		msgType, data = protocol.TypeStopSpeak, protocol.StopSpeakCommand{KeepQueue: len(args) > 0 && args[0] == "keep"}
+
+	case "privacy":
+		if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+			return nil, fmt.Errorf("usage: privacy on|off")
+		}
+		msgType, data = protocol.TypePrivacy, protocol.PrivacyCommand{Enabled: args[0] == "on"}
+
+	case "config":
+		var update protocol.ConfigUpdate
+		if err := json.Unmarshal([]byte(rest), &update); err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		msgType, data = protocol.TypeConfig, update
+
+	case "raw":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("usage: raw <type> [json]")
+		}
+		msgType = protocol.MessageType(args[0])
+		if payload := strings.TrimSpace(strings.TrimPrefix(rest, args[0])); payload != "" {
+			if !json.Valid([]byte(payload)) {
+				return nil, fmt.Errorf("raw: invalid JSON")
+			}
+			data = json.RawMessage(payload)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown command %q", name)
+	}
+
+	msg, err := protocol.NewMessage(msgType, data)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ID, err = protocol.NewMessageID(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseFloats parses between lo and hi numeric arguments
+func parseFloats(args []string, lo, hi int) ([]float64, error) {
+	if len(args) < lo || len(args) > hi {
+		return nil, fmt.Errorf("want %d to %d numbers, got %d", lo, hi, len(args))
+	}
+	values := make([]float64, len(args))
+	for i, a := range args {
+		v, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
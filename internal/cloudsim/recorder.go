@@ -0,0 +1,78 @@
+package cloudsim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// RecordedMessage is one line of a recording
+type RecordedMessage struct {
+	Time    time.Time            `json:"time"`
+	RobotID string               `json:"robot_id"`
+	Dir     string               `json:"dir"` // "in" from the robot, "out" to it
+	Type    protocol.MessageType `json:"type"`
+	Bytes   int                  `json:"bytes"`          // Size on the wire
+	Data    json.RawMessage      `json:"data,omitempty"` // Frame and mic payloads are left out
+}
+
+// Recorder appends every message to a JSONL file
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// OpenRecorder creates or appends to the recording at path
+func OpenRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open recording: %w", err)
+	}
+	return &Recorder{file: f, writer: bufio.NewWriter(f)}, nil
+}
+
+// record appends a message; a nil recorder records nothing
+func (r *Recorder) record(robotID, dir string, msgType protocol.MessageType, size int, data json.RawMessage) {
+	if r == nil {
+		return
+	}
+	line, err := json.Marshal(RecordedMessage{
+		Time:    time.Now(),
+		RobotID: robotID,
+		Dir:     dir,
+		Type:    msgType,
+		Bytes:   size,
+		Data:    data,
+	})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writer.Write(append(line, '\n'))
+}
+
+// Flush writes buffered lines to disk
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.writer.Flush()
+}
+
+// Close flushes and closes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("flush recording: %w", err)
+	}
+	return r.file.Close()
+}
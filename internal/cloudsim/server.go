@@ -0,0 +1,517 @@
+// Package cloudsim simulates the go-reachy cloud backend for local
+// development: it accepts robot WebSocket connections, logs and records what
+// robots send, and sends motor, emotion, speak and config messages back.
+package cloudsim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Config holds simulator configuration
+type Config struct {
+	Path         string        // WebSocket path robots connect to
+	Token        string        // Required bearer token ("" = any)
+	HMACSecret   string        // Verify hello signatures with this secret ("" = don't)
+	BinaryFrames bool          // Accept the binary frame subprotocol
+	HelloTimeout time.Duration // Close connections that don't say hello in time
+	Follow       bool          // Turn the head toward speech, like the real cloud
+	FollowRate   time.Duration // Minimum time between follow motor commands
+	Protocol     int           // Protocol version announced to robots (0 = negotiate from the robot's hello)
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Path:         "/ws/robot",
+		BinaryFrames: true,
+		HelloTimeout: 10 * time.Second,
+		FollowRate:   100 * time.Millisecond,
+	}
+}
+
+// ErrNoRobot is returned by Send when no matching robot is connected
+var ErrNoRobot = errors.New("no robot connected")
+
+// Robot describes a connected robot
+type Robot struct {
+	ID           string                         `json:"id"`
+	Version      string                         `json:"version"`
+	Capabilities []string                       `json:"capabilities"`
+	AudioCodecs  []string                       `json:"audio_codecs,omitempty"`
+	Binary       bool                           `json:"binary_frames"`
+	Protocol     int                            `json:"protocol"` // Version announced in the cloud's hello
+	Remote       string                         `json:"remote"`
+	ConnectedAt  time.Time                      `json:"connected_at"`
+	LastSeen     time.Time                      `json:"last_seen"`
+	Received     map[protocol.MessageType]int64 `json:"received"` // Messages by type
+	Sent         int64                          `json:"sent"`
+	Acks         int64                          `json:"acks"`
+	Nacks        int64                          `json:"nacks"`
+	LastDOA      *protocol.DOAData              `json:"last_doa,omitempty"`
+	LastState    *protocol.StateData            `json:"last_state,omitempty"`
+	LastFrameID  uint64                         `json:"last_frame_id,omitempty"`
+	LastError    string                         `json:"last_error,omitempty"` // Latest nack
+}
+
+// session is one robot connection
+type session struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	robot      Robot
+	lastFrame  []byte // Latest JPEG
+	lastFollow time.Time
+}
+
+// write sends a message to the robot
+func (s *session) write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Server is a simulated cloud; it implements http.Handler
+type Server struct {
+	cfg      Config
+	logger   *slog.Logger
+	mux      *http.ServeMux
+	upgrader websocket.Upgrader
+	recorder *Recorder
+
+	mu     sync.Mutex
+	robots map[string]*session
+}
+
+// New creates a simulated cloud; rec may be nil to skip recording
+func New(cfg Config, rec *Recorder, logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Path == "" {
+		cfg.Path = DefaultConfig().Path
+	}
+	if cfg.HelloTimeout <= 0 {
+		cfg.HelloTimeout = DefaultConfig().HelloTimeout
+	}
+
+	s := &Server{
+		cfg:      cfg,
+		logger:   logger,
+		mux:      http.NewServeMux(),
+		recorder: rec,
+		robots:   make(map[string]*session),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+	if cfg.BinaryFrames {
+		s.upgrader.Subprotocols = []string{protocol.BinarySubprotocolV2, protocol.BinarySubprotocol}
+	}
+
+	s.mux.HandleFunc(cfg.Path, s.robotHandler)
+	s.registerUI()
+	return s
+}
+
+// ServeHTTP serves the robot WebSocket and the control UI
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// robotHandler accepts a robot connection and reads until it closes
+func (s *Server) robotHandler(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.cfg.Token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Warn("websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	hello, err := s.readHello(conn)
+	if err == nil && s.cfg.Protocol == 0 {
+		hello.ProtocolVersion, err = protocol.NegotiateVersion(hello.ProtocolVersion)
+	}
+	if err != nil {
+		s.logger.Warn("robot rejected", "remote", r.RemoteAddr, "error", err)
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()), time.Now().Add(time.Second))
+		return
+	}
+	if s.cfg.Protocol != 0 {
+		hello.ProtocolVersion = s.cfg.Protocol
+	}
+
+	now := time.Now()
+	sess := &session{
+		conn: conn,
+		robot: Robot{
+			ID:           hello.RobotID,
+			Version:      hello.Version,
+			Capabilities: hello.Capabilities,
+			AudioCodecs:  hello.AudioCodecs,
+			Binary:       conn.Subprotocol() != "",
+			Protocol:     hello.ProtocolVersion,
+			Remote:       r.RemoteAddr,
+			ConnectedAt:  now,
+			LastSeen:     now,
+			Received:     map[protocol.MessageType]int64{protocol.TypeHello: 1},
+		},
+	}
+
+	s.mu.Lock()
+	old := s.robots[hello.RobotID]
+	s.robots[hello.RobotID] = sess
+	s.mu.Unlock()
+	if old != nil {
+		old.conn.Close() // Replaced by the new connection
+	}
+
+	s.logger.Info("robot connected",
+		"robot_id", hello.RobotID,
+		"version", hello.Version,
+		"capabilities", hello.Capabilities,
+		"binary_frames", sess.robot.Binary,
+		"protocol", hello.ProtocolVersion,
+	)
+
+	// Answer with the protocol version this cloud will speak, accepting DOA
+	// batches from robots that offer them
+	var accepted []string
+	if slices.Contains(hello.Capabilities, protocol.CapabilityDOABatch) {
+		accepted = append(accepted, protocol.CapabilityDOABatch)
+	}
+	reply, err := protocol.NewHelloMessage(protocol.HelloData{
+		Version:         "cloud-sim",
+		ProtocolVersion: hello.ProtocolVersion,
+		Timestamp:       now.UnixMilli(),
+		Capabilities:    accepted,
+	})
+	if err == nil {
+		err = s.sendTo(sess, reply)
+	}
+	if err == nil {
+		err = s.readLoop(sess)
+	}
+
+	s.mu.Lock()
+	if s.robots[hello.RobotID] == sess {
+		delete(s.robots, hello.RobotID)
+	}
+	s.mu.Unlock()
+	s.logger.Info("robot disconnected", "robot_id", hello.RobotID, "error", err)
+}
+
+// readHello waits for the robot's hello and checks its signature
+func (s *Server) readHello(conn *websocket.Conn) (*protocol.HelloData, error) {
+	conn.SetReadDeadline(time.Now().Add(s.cfg.HelloTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf("read hello: %w", err)
+	}
+	msg, err := protocol.ParseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type != protocol.TypeHello {
+		return nil, fmt.Errorf("expected hello, got %q", msg.Type)
+	}
+	hello, err := msg.GetHello()
+	if err != nil {
+		return nil, fmt.Errorf("parse hello: %w", err)
+	}
+	if hello.RobotID == "" {
+		return nil, fmt.Errorf("hello has no robot_id")
+	}
+	if s.cfg.HMACSecret != "" {
+		if err := hello.Verify([]byte(s.cfg.HMACSecret), time.Minute, time.Now()); err != nil {
+			return nil, fmt.Errorf("hello signature: %w", err)
+		}
+	}
+
+	s.recorder.record(hello.RobotID, "in", msg.Type, len(data), msg.Data)
+	return hello, nil
+}
+
+// readLoop handles robot messages until the connection ends
+func (s *Server) readLoop(sess *session) error {
+	for {
+		wsType, data, err := sess.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if wsType == websocket.BinaryMessage {
+			s.handleBinary(sess, data)
+			continue
+		}
+
+		msg, err := protocol.ParseMessage(data)
+		if err != nil {
+			s.logger.Warn("unparseable robot message", "robot_id", sess.robot.ID, "error", err)
+			continue
+		}
+		s.handleMessage(sess, msg, len(data))
+	}
+}
+
+// handleBinary stores a binary frame
+func (s *Server) handleBinary(sess *session, data []byte) {
+	frame, err := protocol.DecodeBinaryFrame(data)
+	if err != nil {
+		s.logger.Warn("bad binary message", "robot_id", sess.robot.ID, "error", err)
+		return
+	}
+
+	sess.mu.Lock()
+	sess.robot.Received[protocol.TypeFrame]++
+	sess.robot.LastSeen = time.Now()
+	sess.robot.LastFrameID = frame.FrameID
+	sess.lastFrame = frame.Data
+	sess.mu.Unlock()
+
+	meta, _ := json.Marshal(map[string]any{
+		"frame_id":          frame.FrameID,
+		"width":             frame.Width,
+		"height":            frame.Height,
+		"binary":            true,
+		"captured_at":       frame.CapturedAt,
+		"cloud_captured_at": frame.CloudCapturedAt,
+	})
+	s.recorder.record(sess.robot.ID, "in", protocol.TypeFrame, len(data), meta)
+}
+
+// handleMessage updates the robot's state from a JSON message
+func (s *Server) handleMessage(sess *session, msg *protocol.Message, size int) {
+	recorded := msg.Data
+	received := time.Now()
+
+	sess.mu.Lock()
+	sess.robot.Received[msg.Type]++
+	sess.robot.LastSeen = received
+	robotID := sess.robot.ID
+	sess.mu.Unlock()
+
+	switch msg.Type {
+	case protocol.TypeDOA:
+		var doa protocol.DOAData
+		if err := msg.ParseData(&doa); err == nil {
+			sess.mu.Lock()
+			sess.robot.LastDOA = &doa
+			sess.mu.Unlock()
+			s.follow(sess, doa)
+		}
+		s.logger.Debug("doa", "robot_id", robotID, "angle", doa.SmoothedAngle, "speaking", doa.Speaking)
+
+	case protocol.TypeDOABatch:
+		batch, err := msg.GetDOABatch()
+		if err != nil {
+			break
+		}
+		readings := batch.Expand()
+		if len(readings) == 0 {
+			break
+		}
+		doa := readings[len(readings)-1]
+		sess.mu.Lock()
+		sess.robot.LastDOA = &doa
+		sess.mu.Unlock()
+		s.follow(sess, doa)
+		s.logger.Debug("doa batch", "robot_id", robotID, "readings", len(readings), "angle", doa.SmoothedAngle)
+
+	case protocol.TypeFrame:
+		var frame protocol.FrameData
+		if err := msg.ParseData(&frame); err == nil {
+			if jpeg, err := decodeBase64(frame.Data); err == nil {
+				sess.mu.Lock()
+				sess.robot.LastFrameID = frame.FrameID
+				sess.lastFrame = jpeg
+				sess.mu.Unlock()
+			}
+			// Frame payloads would swamp the recording
+			frame.Data = ""
+			recorded, _ = json.Marshal(frame)
+		}
+
+	case protocol.TypeMic:
+		recorded = nil
+		s.logger.Debug("mic", "robot_id", robotID, "bytes", size)
+
+	case protocol.TypeWakeCandidate:
+		var wake protocol.WakeCandidateData
+		if err := msg.ParseData(&wake); err == nil {
+			s.logger.Info("wake candidate", "robot_id", robotID, "id", wake.ID, "level_dbfs", wake.LevelDBFS, "pre_roll_ms", wake.PreRollMs)
+		}
+
+	case protocol.TypeState:
+		var state protocol.StateData
+		if err := msg.ParseData(&state); err == nil {
+			sess.mu.Lock()
+			sess.robot.LastState = &state
+			sess.mu.Unlock()
+		}
+		s.logger.Info("state", "robot_id", robotID, "uptime_s", state.UptimeSeconds, "cpu", state.System.CPUPercent)
+
+	case protocol.TypeAck:
+		ack, err := msg.GetAckData()
+		if err != nil {
+			break
+		}
+		sess.mu.Lock()
+		if ack.OK {
+			sess.robot.Acks++
+		} else {
+			sess.robot.Nacks++
+			sess.robot.LastError = ack.Error
+		}
+		sess.mu.Unlock()
+		s.logger.Info("ack", "robot_id", robotID, "id", ack.ID, "ok", ack.OK, "error", ack.Error)
+
+	case protocol.TypePing:
+		s.sendTo(sess, protocol.NewPong(msg, received))
+
+	default:
+		s.logger.Info(string(msg.Type), "robot_id", robotID, "data", string(msg.Data))
+	}
+
+	s.recorder.record(robotID, "in", msg.Type, size, recorded)
+}
+
+// follow turns the head toward speech, echoing the capture time as
+// source_ts so the robot can measure end-to-end latency
+func (s *Server) follow(sess *session, doa protocol.DOAData) {
+	if !s.cfg.Follow || !doa.SpeakingLatched {
+		return
+	}
+
+	sess.mu.Lock()
+	if time.Since(sess.lastFollow) < s.cfg.FollowRate {
+		sess.mu.Unlock()
+		return
+	}
+	sess.lastFollow = time.Now()
+	sess.mu.Unlock()
+
+	msg, err := protocol.NewMessage(protocol.TypeMotor, protocol.MotorCommand{
+		Head:     protocol.HeadTarget{Yaw: doa.SmoothedAngle},
+		SourceTS: doa.CapturedAt,
+	})
+	if err == nil {
+		s.sendTo(sess, msg)
+	}
+}
+
+// Send delivers msg to the robot with robotID, or to every robot when
+// robotID is empty. It returns how many robots it was sent to.
+func (s *Server) Send(robotID string, msg *protocol.Message) (int, error) {
+	s.mu.Lock()
+	var targets []*session
+	for id, sess := range s.robots {
+		if robotID == "" || id == robotID {
+			targets = append(targets, sess)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(targets) == 0 {
+		return 0, ErrNoRobot
+	}
+
+	sent := 0
+	var firstErr error
+	for _, sess := range targets {
+		if err := s.sendTo(sess, msg); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, firstErr
+}
+
+// sendTo addresses msg to the session's robot and writes it
+func (s *Server) sendTo(sess *session, msg *protocol.Message) error {
+	out := *msg
+	out.RobotID = sess.robot.ID
+	if out.Timestamp == 0 {
+		out.Timestamp = time.Now().UnixMilli()
+	}
+	data, err := out.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := sess.write(data); err != nil {
+		return fmt.Errorf("send to %s: %w", sess.robot.ID, err)
+	}
+
+	sess.mu.Lock()
+	sess.robot.Sent++
+	sess.mu.Unlock()
+
+	if out.Type != protocol.TypePong {
+		s.logger.Info("sent", "robot_id", sess.robot.ID, "type", out.Type, "id", out.ID)
+	}
+	s.recorder.record(sess.robot.ID, "out", out.Type, len(data), out.Data)
+	return nil
+}
+
+// Robots returns the connected robots, sorted by ID
+func (s *Server) Robots() []Robot {
+	s.mu.Lock()
+	sessions := make([]*session, 0, len(s.robots))
+	for _, sess := range s.robots {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	robots := make([]Robot, 0, len(sessions))
+	for _, sess := range sessions {
+		sess.mu.Lock()
+		r := sess.robot
+		r.Received = make(map[protocol.MessageType]int64, len(sess.robot.Received))
+		for k, v := range sess.robot.Received {
+			r.Received[k] = v
+		}
+		sess.mu.Unlock()
+		robots = append(robots, r)
+	}
+	sort.Slice(robots, func(i, j int) bool { return robots[i].ID < robots[j].ID })
+	return robots
+}
+
+// Frame returns the latest camera frame from a robot
+func (s *Server) Frame(robotID string) ([]byte, bool) {
+	s.mu.Lock()
+	sess := s.robots[robotID]
+	s.mu.Unlock()
+	if sess == nil {
+		return nil, false
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.lastFrame, sess.lastFrame != nil
+}
@@ -0,0 +1,157 @@
+package cloudsim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// SendRequest is the body of POST /api/send: either a command line (see
+// CommandHelp) or a raw message type and data
+type SendRequest struct {
+	RobotID string               `json:"robot_id,omitempty"` // Empty sends to every robot
+	Command string               `json:"command,omitempty"`
+	Type    protocol.MessageType `json:"type,omitempty"`
+	Data    json.RawMessage      `json:"data,omitempty"`
+}
+
+// registerUI adds the control page and its JSON API
+func (s *Server) registerUI() {
+	s.mux.HandleFunc("GET /{$}", s.indexHandler)
+	s.mux.HandleFunc("GET /api/robots", s.robotsHandler)
+	s.mux.HandleFunc("GET /api/robots/{id}/frame", s.frameHandler)
+	s.mux.HandleFunc("POST /api/send", s.sendHandler)
+}
+
+func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(indexHTML))
+}
+
+func (s *Server) robotsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Robots())
+}
+
+func (s *Server) frameHandler(w http.ResponseWriter, r *http.Request) {
+	frame, ok := s.Frame(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no frame"})
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(frame)
+}
+
+func (s *Server) sendHandler(w http.ResponseWriter, r *http.Request) {
+	var req SendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	var msg *protocol.Message
+	var err error
+	switch {
+	case req.Command != "":
+		msg, err = ParseCommand(req.Command)
+	case req.Type != "":
+		var data any
+		if len(req.Data) > 0 {
+			data = req.Data
+		}
+		msg, err = protocol.NewMessage(req.Type, data)
+		if err == nil {
+			msg.ID, err = protocol.NewMessageID()
+		}
+	default:
+		err = errors.New("command or type is required")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	sent, err := s.Send(req.RobotID, msg)
+	if errors.Is(err, ErrNoRobot) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"error": err.Error(), "sent": sent})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": msg.ID, "type": msg.Type, "sent": sent})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// indexHTML is the control page: connected robots with their latest DOA,
+// state and frame, and a command box
+const indexHTML = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>go-eva cloud-sim</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; }
+.robot { border: 1px solid #ccc; border-radius: 6px; padding: 1em; margin-bottom: 1em; }
+.robot img { max-width: 320px; float: right; }
+pre { background: #f6f6f6; padding: .5em; overflow: auto; max-height: 12em; }
+input[type=text] { width: 32em; }
+</style>
+</head>
+<body>
+<h1>go-eva cloud-sim</h1>
+<form id="send">
+  <input type="text" id="command" placeholder="emotion happy 2" autofocus>
+  <button>Send</button>
+  <span id="result"></span>
+</form>
+<p>
+  <button data-cmd="motor 30">Look left</button>
+  <button data-cmd="motor -30">Look right</button>
+  <button data-cmd="motor 0">Center</button>
+  <button data-cmd="emotion happy">Happy</button>
+  <button data-cmd="emotion curious">Curious</button>
+  <button data-cmd="speak Hello from the cloud simulator">Speak</button>
+  <button data-cmd="stop">Stop speaking</button>
+</p>
+<div id="robots">No robots connected</div>
+<script>
+async function send(command) {
+  const res = await fetch("/api/send", {method: "POST", headers: {"Content-Type": "application/json"}, body: JSON.stringify({command})});
+  const body = await res.json();
+  document.getElementById("result").textContent = res.ok ? "sent " + body.type + " " + body.id : body.error;
+}
+document.getElementById("send").onsubmit = e => { e.preventDefault(); send(document.getElementById("command").value); };
+document.querySelectorAll("[data-cmd]").forEach(b => b.onclick = () => send(b.dataset.cmd));
+
+async function refresh() {
+  const robots = await (await fetch("/api/robots")).json();
+  const el = document.getElementById("robots");
+  if (!robots.length) { el.textContent = "No robots connected"; return; }
+  el.innerHTML = "";
+  for (const r of robots) {
+    const div = document.createElement("div");
+    div.className = "robot";
+    const doa = r.last_doa ? (r.last_doa.smoothed_angle * 180 / Math.PI).toFixed(1) + "°" + (r.last_doa.speaking ? " speaking" : "") : "-";
+    div.innerHTML = (r.last_frame_id ? '<img src="/api/robots/' + encodeURIComponent(r.id) + '/frame?' + r.last_frame_id + '">' : "") +
+      "<h2></h2><p>DOA: " + doa + " · acks " + r.acks + " · nacks " + r.nacks + "</p><pre></pre>";
+    div.querySelector("h2").textContent = r.id + " (" + r.version + ")";
+    div.querySelector("pre").textContent = JSON.stringify({capabilities: r.capabilities, received: r.received, last_error: r.last_error, state: r.last_state}, null, 2);
+    el.appendChild(div);
+  }
+}
+setInterval(refresh, 1000);
+refresh();
+</script>
+</body>
+</html>
+`
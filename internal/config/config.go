@@ -3,29 +3,105 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 // Config is the root configuration structure
 type Config struct {
-	Server  ServerConfig  `mapstructure:"server"`
-	Audio   AudioConfig   `mapstructure:"audio"`
-	Cloud   CloudConfig   `mapstructure:"cloud"`
-	Pollen  PollenConfig  `mapstructure:"pollen"`
-	Camera  CameraConfig  `mapstructure:"camera"`
-	Logging LoggingConfig `mapstructure:"logging"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Audio       AudioConfig       `mapstructure:"audio"`
+	Source      SourceConfig      `mapstructure:"source"`
+	Cloud       CloudConfig       `mapstructure:"cloud"`
+	Pollen      PollenConfig      `mapstructure:"pollen"`
+	Camera      CameraConfig      `mapstructure:"camera"`
+	Vision      VisionConfig      `mapstructure:"vision"`
+	History     HistoryConfig     `mapstructure:"history"`
+	Recorder    RecorderConfig    `mapstructure:"recorder"`
+	Audit       AuditConfig       `mapstructure:"audit"`
+	Calibration CalibrationConfig `mapstructure:"calibration"`
+	Influx      InfluxConfig      `mapstructure:"influx"`
+	Webhooks    WebhooksConfig    `mapstructure:"webhooks"`
+	TTS         TTSConfig         `mapstructure:"tts"`
+	STT         STTConfig         `mapstructure:"stt"`
+	Gesture     GestureConfig     `mapstructure:"gesture"`
+	Emotion     EmotionConfig     `mapstructure:"emotion"`
+	Choreo      ChoreoConfig      `mapstructure:"choreo"`
+	Animation   AnimationConfig   `mapstructure:"animation"`
+	Expression  ExpressionConfig  `mapstructure:"expression"`
+	Playback    PlaybackConfig    `mapstructure:"playback"`
+	Capture     CaptureConfig     `mapstructure:"capture"`
+	Behavior    BehaviorConfig    `mapstructure:"behavior"`
+	Privacy     PrivacyConfig     `mapstructure:"privacy"`
+	XVF3800     XVF3800Config     `mapstructure:"xvf3800"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	State       StateConfig       `mapstructure:"state"`
+	Health      HealthConfig      `mapstructure:"health"`
+	Secrets     SecretsConfig     `mapstructure:"secrets"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
 }
 
 // CloudConfig configures connection to go-reachy cloud
 type CloudConfig struct {
-	Enabled          bool          `mapstructure:"enabled"`
-	URL              string        `mapstructure:"url"`
-	ReconnectBackoff time.Duration `mapstructure:"reconnect_backoff"`
-	MaxBackoff       time.Duration `mapstructure:"max_backoff"`
-	PingInterval     time.Duration `mapstructure:"ping_interval"`
+	Enabled          bool             `mapstructure:"enabled"`
+	URL              string           `mapstructure:"url"`
+	URLs             []string         `mapstructure:"urls"`           // Endpoints in priority order; replaces url when set
+	FailoverAfter    time.Duration    `mapstructure:"failover_after"` // Move to the next endpoint after failing to connect for this long
+	PrimaryRetry     time.Duration    `mapstructure:"primary_retry"`  // While on a fallback, check the primary this often (0 = stay)
+	ReconnectBackoff time.Duration    `mapstructure:"reconnect_backoff"`
+	MaxBackoff       time.Duration    `mapstructure:"max_backoff"`
+	PingInterval     time.Duration    `mapstructure:"ping_interval"`
+	ClockSync        time.Duration    `mapstructure:"clock_sync"`      // Interval between clock sync pings (0 = off)
+	DOABatch         time.Duration    `mapstructure:"doa_batch"`       // Batch DOA readings over this interval if the cloud accepts it (0 = off)
+	BinaryFrames     bool             `mapstructure:"binary_frames"`   // Offer raw JPEG frames over binary WebSocket messages
+	RobotID          string           `mapstructure:"robot_id"`        // Sent in the hello message (default: hostname)
+	StreamMic        bool             `mapstructure:"stream_mic"`      // Send captured mic audio to the cloud
+	AudioCodecs      []string         `mapstructure:"audio_codecs"`    // Offered audio codecs, preferred first (opus needs -tags opus)
+	OpusBitrate      int              `mapstructure:"opus_bitrate"`    // Opus bitrate for mic audio (bits/s)
+	StrictProtocol   bool             `mapstructure:"strict_protocol"` // Reject cloud messages with unknown fields instead of warning
+	Auth             CloudAuth        `mapstructure:"auth"`
+	Queue            CloudQueue       `mapstructure:"queue"`
+	Send             CloudSend        `mapstructure:"send"`
+	Compression      CloudCompression `mapstructure:"compression"`
+}
+
+// CloudCompression configures compression on the cloud link; each kind is
+// used only if the cloud accepts it
+type CloudCompression struct {
+	Deflate       bool `mapstructure:"deflate"`        // Offer permessage-deflate
+	Level         int  `mapstructure:"level"`          // Flate level 1 (fastest) to 9 (smallest)
+	GzipThreshold int  `mapstructure:"gzip_threshold"` // Gzip JSON messages of at least this many bytes (0 = never)
+}
+
+// CloudSend configures the outgoing message queue used while connected
+type CloudSend struct {
+	QueueSize int `mapstructure:"queue_size"` // Buffered messages; old frames are dropped first when full
+	Rate      int `mapstructure:"rate"`       // Upload budget in bytes/s (0 = unlimited)
+	Burst     int `mapstructure:"burst"`      // Bytes sent at once before the rate applies (0 = one second's worth)
+}
+
+// CloudQueue configures store-and-forward buffering while the cloud is unreachable
+type CloudQueue struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Path        string        `mapstructure:"path"`         // Spool file (empty = memory only)
+	MaxMessages int           `mapstructure:"max_messages"` // Queue limit
+	MaxAge      time.Duration `mapstructure:"max_age"`      // Older messages are not replayed (0 = no limit)
+	DropPolicy  string        `mapstructure:"drop_policy"`  // oldest or newest, applied when full
+}
+
+// CloudAuth holds cloud credentials; all fields are optional
+type CloudAuth struct {
+	Token      string `mapstructure:"token"`       // Bearer token
+	HMACSecret string `mapstructure:"hmac_secret"` // Signs the hello message
+	CertFile   string `mapstructure:"cert_file"`   // mTLS client certificate (PEM)
+	KeyFile    string `mapstructure:"key_file"`    // mTLS client key (PEM)
+	CAFile     string `mapstructure:"ca_file"`     // CA bundle for the cloud (PEM)
 }
 
 // PollenConfig configures connection to Pollen daemon
@@ -33,6 +109,39 @@ type PollenConfig struct {
 	BaseURL     string        `mapstructure:"base_url"`
 	Timeout     time.Duration `mapstructure:"timeout"`
 	RateLimitHz int           `mapstructure:"rate_limit_hz"`
+
+	Safety  MotorSafetyConfig   `mapstructure:"safety"`
+	Pose    PoseConfig          `mapstructure:"pose"`
+	Breaker PollenBreakerConfig `mapstructure:"breaker"`
+}
+
+// PollenBreakerConfig configures the circuit breaker that fails commands
+// fast while the daemon is down
+type PollenBreakerConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Failures int           `mapstructure:"failures"` // Consecutive failed requests that open it
+	Cooldown time.Duration `mapstructure:"cooldown"` // Time open before probing the daemon
+}
+
+// PoseConfig configures polling of the measured joint state
+type PoseConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	StaleAfter time.Duration `mapstructure:"stale_after"` // Older poses are reported as stale
+}
+
+// MotorSafetyConfig configures joint limits and motion shaping for head commands
+type MotorSafetyConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`
+	YawMin          float64 `mapstructure:"yaw_min"` // degrees
+	YawMax          float64 `mapstructure:"yaw_max"`
+	PitchMin        float64 `mapstructure:"pitch_min"`
+	PitchMax        float64 `mapstructure:"pitch_max"`
+	RollMin         float64 `mapstructure:"roll_min"`
+	RollMax         float64 `mapstructure:"roll_max"`
+	MaxVelocity     float64 `mapstructure:"max_velocity"`     // degrees/second
+	MaxAcceleration float64 `mapstructure:"max_acceleration"` // degrees/second²
+	RateHz          int     `mapstructure:"rate_hz"`          // Interpolated command rate
 }
 
 // CameraConfig configures camera capture
@@ -42,6 +151,310 @@ type CameraConfig struct {
 	Width     int  `mapstructure:"width"`
 	Height    int  `mapstructure:"height"`
 	Quality   int  `mapstructure:"quality"`
+
+	// Horizontal field of view, for mapping image positions to bearings
+	FOVDeg float64 `mapstructure:"fov_deg"`
+
+	// Poll Pollen's snapshot endpoint while WebRTC is down
+	SnapshotFallback bool `mapstructure:"snapshot_fallback"`
+
+	// Skip cloud uploads of frames that barely changed
+	ChangeDetection ChangeDetectionConfig `mapstructure:"change_detection"`
+
+	// Lower framerate and quality while the cloud uplink is backed up
+	Adaptive CameraAdaptiveConfig `mapstructure:"adaptive"`
+
+	// Draw the DOA bearing onto frames for debugging
+	Annotate CameraAnnotateConfig `mapstructure:"annotate"`
+
+	// Keep periodic and speech-triggered stills on disk
+	Archive CameraArchiveConfig `mapstructure:"archive"`
+}
+
+// CameraArchiveConfig configures the on-disk snapshot archive
+type CameraArchiveConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Dir       string        `mapstructure:"dir"`
+	Interval  time.Duration `mapstructure:"interval"`  // Save a snapshot this often (0 = only on speech)
+	OnSpeech  bool          `mapstructure:"on_speech"` // Also save one when an utterance starts
+	MinGap    time.Duration `mapstructure:"min_gap"`   // Skip speech snapshots this soon after the last one
+	Retention time.Duration `mapstructure:"retention"`
+	MaxBytes  int64         `mapstructure:"max_bytes"`
+}
+
+// CameraAnnotateConfig configures the speaker bearing overlay
+type CameraAnnotateConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ChangeDetectionConfig configures skipping of static camera frames
+type ChangeDetectionConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Threshold  float64       `mapstructure:"threshold"`   // Fraction of the frame that must change (0-1)
+	PixelDelta int           `mapstructure:"pixel_delta"` // Brightness change (0-255) that counts as changed
+	Keepalive  time.Duration `mapstructure:"keepalive"`   // Send a frame at least this often (0 = never)
+}
+
+// CameraAdaptiveConfig configures uplink-driven framerate and quality
+type CameraAdaptiveConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Interval     time.Duration `mapstructure:"interval"`      // How often the uplink is checked
+	HighDelay    time.Duration `mapstructure:"high_delay"`    // Step down above this send queue delay
+	LowDelay     time.Duration `mapstructure:"low_delay"`     // Step up once below this delay...
+	RecoverAfter time.Duration `mapstructure:"recover_after"` // ...for this long
+	MaxQueue     int           `mapstructure:"max_queue"`     // Also step down above this many queued messages (0 = ignore)
+	MinFramerate int           `mapstructure:"min_framerate"`
+	MinQuality   int           `mapstructure:"min_quality"`
+}
+
+// HistoryConfig configures the history store behind /api/history
+type HistoryConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Backend        string        `mapstructure:"backend"` // memory, sqlite or remote (uploaded over the cloud link)
+	Path           string        `mapstructure:"path"`    // SQLite database (sqlite backend)
+	SampleInterval time.Duration `mapstructure:"sample_interval"`
+	FlushInterval  time.Duration `mapstructure:"flush_interval"`
+	Retention      time.Duration `mapstructure:"retention"`
+	MaxRows        int           `mapstructure:"max_rows"` // Rows kept in memory per table (memory and remote backends), or waiting after failed sqlite writes
+}
+
+// RecorderConfig configures the full-rate DOA ring-buffer recorder
+type RecorderConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Dir             string        `mapstructure:"dir"`
+	SegmentDuration time.Duration `mapstructure:"segment_duration"`
+	Retention       time.Duration `mapstructure:"retention"`
+	MaxBytes        int64         `mapstructure:"max_bytes"`
+}
+
+// AuditConfig configures the motor and emotion command audit log
+type AuditConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Dir          string `mapstructure:"dir"`
+	MaxFileBytes int64  `mapstructure:"max_file_bytes"` // Start a new file beyond this size
+	MaxFiles     int    `mapstructure:"max_files"`      // Oldest files deleted beyond this (0 = keep all)
+}
+
+// CalibrationConfig configures speaker distance calibration
+type CalibrationConfig struct {
+	File       string        `mapstructure:"file"`        // Persisted fit ("" = memory only)
+	Duration   time.Duration `mapstructure:"duration"`    // Default sample window per run
+	MinSamples int           `mapstructure:"min_samples"` // Speaking samples required per run
+}
+
+// InfluxConfig configures push-based metrics export (InfluxDB line protocol)
+type InfluxConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`
+	URL      string            `mapstructure:"url"`
+	Token    string            `mapstructure:"token"`
+	Interval time.Duration     `mapstructure:"interval"`
+	Tags     map[string]string `mapstructure:"tags"`
+}
+
+// WebhooksConfig configures HTTP callbacks for speech, zone and cloud events
+type WebhooksConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Hooks          []WebhookSpec `mapstructure:"hooks"`
+	Timeout        time.Duration `mapstructure:"timeout"`         // Per request
+	MaxRetries     int           `mapstructure:"max_retries"`     // For network errors, 429 and 5xx
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // Doubles per retry
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	QueueSize      int           `mapstructure:"queue_size"` // Events buffered per hook
+}
+
+// WebhookSpec is one endpoint and the events it receives
+type WebhookSpec struct {
+	URL     string            `mapstructure:"url"`    // Go template, e.g. http://ha.local:8123/api/webhook/eva-{{.Type}}
+	Events  []string          `mapstructure:"events"` // speech_start, speech_end, zone_change, cloud_disconnect (empty = all)
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// webhookEvents are the event names hooks can filter on
+var webhookEvents = []string{"speech_start", "speech_end", "zone_change", "cloud_disconnect"}
+
+// TTSConfig configures the local text-to-speech engine
+type TTSConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Engine  string        `mapstructure:"engine"` // espeak, piper
+	Command string        `mapstructure:"command"`
+	Voice   string        `mapstructure:"voice"`
+	Model   string        `mapstructure:"model"`
+	Speed   int           `mapstructure:"speed"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// STTConfig configures on-robot speech-to-text
+type STTConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Command      string        `mapstructure:"command"`
+	Args         []string      `mapstructure:"args"` // "{file}" is replaced with the utterance WAV path
+	MinUtterance time.Duration `mapstructure:"min_utterance"`
+	MaxUtterance time.Duration `mapstructure:"max_utterance"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+}
+
+// GestureConfig configures DOA-triggered gesture mapping
+type GestureConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	NewSpeakerAngle float64       `mapstructure:"new_speaker_angle"` // degrees
+	BehindAngle     float64       `mapstructure:"behind_angle"`      // degrees
+	LoudRatio       float64       `mapstructure:"loud_ratio"`
+	Rules           []GestureRule `mapstructure:"rules"` // empty uses built-in rules
+}
+
+// GestureRule maps an audio event to an emotion and/or antenna move
+type GestureRule struct {
+	Event    string        `mapstructure:"event"` // speech_start, speech_end, new_speaker, behind, loud
+	Emotion  string        `mapstructure:"emotion"`
+	Duration float64       `mapstructure:"duration"`
+	Antennas []float64     `mapstructure:"antennas"` // [left, right] radians
+	Lean     bool          `mapstructure:"lean"`     // mirror antennas toward the speaker
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// EmotionConfig configures the local emotion library and scheduler
+type EmotionConfig struct {
+	AllowUnknown    bool           `mapstructure:"allow_unknown"` // play names missing from the library
+	DefaultDuration time.Duration  `mapstructure:"default_duration"`
+	QueueSize       int            `mapstructure:"queue_size"`
+	IdleAfter       time.Duration  `mapstructure:"idle_after"` // 0 disables idle behaviors
+	Overlap         string         `mapstructure:"overlap"`    // queue or reject requests made while one plays
+	Library         []EmotionEntry `mapstructure:"library"`    // empty uses the built-in library
+}
+
+// ChoreoConfig configures on-robot playback of keyframed motion sequences
+type ChoreoConfig struct {
+	RateHz      int           `mapstructure:"rate_hz"`      // Interpolated frames per second
+	MaxDuration time.Duration `mapstructure:"max_duration"` // Longest sequence accepted, including loops
+}
+
+// EmotionEntry describes one emotion in the library
+type EmotionEntry struct {
+	Name      string        `mapstructure:"name"`
+	Duration  time.Duration `mapstructure:"duration"`
+	Conflicts []string      `mapstructure:"conflicts"`
+	Idle      bool          `mapstructure:"idle"`
+}
+
+// AnimationConfig configures procedural antenna animation
+type AnimationConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Rate          time.Duration `mapstructure:"rate"`
+	SwayAmplitude float64       `mapstructure:"sway_amplitude"` // radians
+	TwitchGain    float64       `mapstructure:"twitch_gain"`    // radians at full speech energy
+	TargetHold    time.Duration `mapstructure:"target_hold"`
+}
+
+// VisionConfig configures face detection and audio-visual fusion
+type VisionConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	CascadePath   string        `mapstructure:"cascade_path"`   // pico facefinder cascade
+	Interval      time.Duration `mapstructure:"interval"`       // Minimum time between detections
+	MinFaceSize   int           `mapstructure:"min_face_size"`  // Pixels
+	MinConfidence float64       `mapstructure:"min_confidence"` // 0-1
+	MatchDeg      float64       `mapstructure:"match_deg"`      // Face within this of the DOA angle is the speaker
+	MaxFaceAge    time.Duration `mapstructure:"max_face_age"`
+	UnseenPenalty float64       `mapstructure:"unseen_penalty"` // Confidence removed for speech from an empty view (0-1)
+}
+
+// ExpressionConfig configures listening feedback on the antennas
+type ExpressionConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Rest         []float64     `mapstructure:"rest"`   // [left, right] radians when nobody talks
+	Perk         []float64     `mapstructure:"perk"`   // [left, right] radians when speech starts
+	Listen       []float64     `mapstructure:"listen"` // [left, right] radians while someone talks
+	PerkHold     time.Duration `mapstructure:"perk_hold"`
+	Lean         float64       `mapstructure:"lean"` // radians toward a speaker at the side
+	ReleaseAfter time.Duration `mapstructure:"release_after"`
+	MaxVelocity  float64       `mapstructure:"max_velocity"` // rad/s
+}
+
+// PlaybackConfig configures speaker playback
+type PlaybackConfig struct {
+	DeviceRate int              `mapstructure:"device_rate"` // Hz clips are resampled to (0 = play at the clip's rate)
+	MaxQueue   int              `mapstructure:"max_queue"`
+	MP3Command string           `mapstructure:"mp3_command"` // ffmpeg-compatible decoder for mp3 speak data
+	Cache      SpeakCacheConfig `mapstructure:"cache"`
+}
+
+// SpeakCacheConfig configures the on-disk cache of speak audio the cloud
+// can replay by reference
+type SpeakCacheConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Dir      string `mapstructure:"dir"`
+	MaxBytes int64  `mapstructure:"max_bytes"` // Least recently used clips are evicted beyond this
+}
+
+// CaptureConfig selects where microphone audio comes from
+type CaptureConfig struct {
+	Source string `mapstructure:"source"` // arecord, or webrtc for the camera session's audio track
+}
+
+// BehaviorConfig configures autonomous behaviors
+type BehaviorConfig struct {
+	AutoTrack AutoTrackConfig `mapstructure:"autotrack"`
+}
+
+// AutoTrackConfig configures head auto-tracking of the active speaker
+type AutoTrackConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	DeadBand      float64       `mapstructure:"dead_band"`    // degrees
+	MaxVelocity   float64       `mapstructure:"max_velocity"` // degrees/second
+	MaxYaw        float64       `mapstructure:"max_yaw"`      // degrees, relative to the body
+	MinConfidence float64       `mapstructure:"min_confidence"`
+	ReturnAfter   time.Duration `mapstructure:"return_after"` // 0 keeps the last heading
+	Body          BodyYawConfig `mapstructure:"body"`
+}
+
+// BodyYawConfig configures turning the body toward speakers the head can't
+// comfortably reach
+type BodyYawConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	MaxYaw      float64 `mapstructure:"max_yaw"`      // degrees
+	Threshold   float64 `mapstructure:"threshold"`    // Head yaw the body turns to keep within (degrees)
+	Hysteresis  float64 `mapstructure:"hysteresis"`   // degrees
+	MaxVelocity float64 `mapstructure:"max_velocity"` // degrees/second
+}
+
+// PrivacyConfig configures the global privacy switch
+type PrivacyConfig struct {
+	StartEnabled    bool   `mapstructure:"start_enabled"`
+	ButtonPath      string `mapstructure:"button_path"` // GPIO value file, e.g. /sys/class/gpio/gpio17/value
+	ButtonActiveLow bool   `mapstructure:"button_active_low"`
+	LEDPath         string `mapstructure:"led_path"` // LED brightness file lit while private
+}
+
+// XVF3800Config configures remote access to XVF3800 control parameters
+type XVF3800Config struct {
+	ControlEnabled bool               `mapstructure:"control_enabled"`
+	Params         []XVF3800ParamSpec `mapstructure:"params"` // allowlist; empty uses read-only DOA/AEC params
+
+	Profiles    []XVF3800ProfileSpec `mapstructure:"profiles"`     // Named bundles of writable params
+	Profile     string               `mapstructure:"profile"`      // Applied at startup unless one was applied at runtime ("" = none)
+	ProfileFile string               `mapstructure:"profile_file"` // Remembers the profile applied at runtime ("" = memory only)
+}
+
+// XVF3800ProfileSpec bundles parameter values for one environment, such as
+// "quiet-room" or "noisy-cafe"
+type XVF3800ProfileSpec struct {
+	Name     string               `mapstructure:"name"`
+	Settings []XVF3800SettingSpec `mapstructure:"settings"`
+}
+
+// XVF3800SettingSpec sets one allowlisted, writable parameter
+type XVF3800SettingSpec struct {
+	Param  string    `mapstructure:"param"` // Name in xvf3800.params
+	Values []float64 `mapstructure:"values"`
+}
+
+// XVF3800ParamSpec is one allowlisted control parameter
+type XVF3800ParamSpec struct {
+	Name     string `mapstructure:"name"`
+	ResID    uint16 `mapstructure:"resid"`
+	CmdID    uint8  `mapstructure:"cmdid"`
+	Type     string `mapstructure:"type"` // uint8, int32, uint32, float, radians
+	Count    int    `mapstructure:"count"`
+	Writable bool   `mapstructure:"writable"`
 }
 
 // ServerConfig configures the HTTP server
@@ -50,6 +463,59 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	GracefulTimeout time.Duration `mapstructure:"graceful_timeout"`
+	Auth            AuthConfig    `mapstructure:"auth"`
+	CORS            CORSConfig    `mapstructure:"cors"`
+	TLS             TLSConfig     `mapstructure:"tls"`
+	WebSocket       WSConfig      `mapstructure:"websocket"`
+}
+
+// WSConfig configures delivery to WebSocket clients
+type WSConfig struct {
+	QueueSize    int           `mapstructure:"queue_size"`    // Messages buffered per client; the oldest is dropped when full
+	WriteTimeout time.Duration `mapstructure:"write_timeout"` // Deadline for each write (0 = none)
+	StallTimeout time.Duration `mapstructure:"stall_timeout"` // Disconnect a client whose queue stays full this long (0 = never)
+}
+
+// TLSConfig configures HTTPS for the HTTP server
+type TLSConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CertFile     string `mapstructure:"cert_file"`
+	KeyFile      string `mapstructure:"key_file"`
+	SelfSigned   bool   `mapstructure:"self_signed"`   // Generate cert_file and key_file on first boot if missing
+	RedirectPort int    `mapstructure:"redirect_port"` // Plain HTTP port redirecting to HTTPS (0 = off)
+}
+
+// AuthConfig configures API-key authentication of the HTTP API
+type AuthConfig struct {
+	APIKeys []string `mapstructure:"api_keys"` // Accepted keys (empty = no authentication)
+	Exempt  []string `mapstructure:"exempt"`   // Paths served without a key; a trailing * matches a prefix
+}
+
+// CORSConfig configures which browser origins may call the HTTP API
+type CORSConfig struct {
+	AllowOrigins     []string `mapstructure:"allow_origins"` // e.g. "http://dashboard.local:3000", "*" (empty = same origin only)
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"` // Preflight cache (seconds)
+}
+
+// GRPCConfig configures the gRPC API served next to the HTTP server
+type GRPCConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Bind    string `mapstructure:"bind"` // Listen address ("" or 0.0.0.0 = all interfaces); calls need a server.auth.api_keys key when any are set
+	Port    int    `mapstructure:"port"`
+	Motor   bool   `mapstructure:"motor"` // Expose MotorService (set-target, emotions)
+}
+
+// StateConfig configures robot state snapshots
+type StateConfig struct {
+	Interval time.Duration `mapstructure:"interval"` // Cloud publishing period (0 = only via /api/state)
+}
+
+// HealthConfig configures component health probes for /health
+type HealthConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`  // Per-probe limit
+	Critical []string      `mapstructure:"critical"` // Components whose failure makes /health return 503
 }
 
 // AudioConfig configures DOA tracking
@@ -57,10 +523,171 @@ type AudioConfig struct {
 	PollHz            int           `mapstructure:"poll_hz"`
 	SpeakingLatchMs   int           `mapstructure:"speaking_latch_ms"`
 	EMAAlpha          float64       `mapstructure:"ema_alpha"`
-	HistorySize       int           `mapstructure:"history_size"`
+	HistorySize       int           `mapstructure:"history_size"` // Full-rate results kept in memory for DOA history and SSE resume
 	USBReconnectDelay time.Duration `mapstructure:"usb_reconnect_delay"`
+	Transport         string        `mapstructure:"transport"` // XVF3800 control interface: usb or i2c
+	Smoothing         string        `mapstructure:"smoothing"` // ema, kalman, median
+	MedianWindow      int           `mapstructure:"median_window"`
+
+	UtteranceHangoverMs int `mapstructure:"utterance_hangover_ms"` // Silence that ends an utterance
+	MaxUtteranceMs      int `mapstructure:"max_utterance_ms"`      // Split longer utterances (0 = no limit)
+
+	Zones             []ZoneEntry `mapstructure:"zones"`               // Named DOA sectors (empty = eight 45° sectors)
+	ZoneHysteresisDeg float64     `mapstructure:"zone_hysteresis_deg"` // Margin past a zone's edge before leaving it
+	ZoneDwellMs       int         `mapstructure:"zone_dwell_ms"`       // A new zone must hold this long before a transition
+	ZoneWorld         bool        `mapstructure:"zone_world"`          // Map zones from the world angle, fixed in the room
 
+	Processors []ProcessorEntry `mapstructure:"processors"` // Tracker result post-processing, run in order
+
+	I2C        AudioI2CConfig   `mapstructure:"i2c"`
+	Mounting   MountingConfig   `mapstructure:"mounting"`
+	NoiseFloor NoiseFloorConfig `mapstructure:"noise_floor"`
+	Echo       EchoConfig       `mapstructure:"echo"`
+	Outliers   OutlierConfig    `mapstructure:"outliers"`
+	Idle       IdleConfig       `mapstructure:"idle"`
+	Speakers   SpeakersConfig   `mapstructure:"speakers"`
+	Mics       MicsConfig       `mapstructure:"mics"`
+	Persist    PersistConfig    `mapstructure:"persist"`
 	Confidence ConfidenceConfig `mapstructure:"confidence"`
+	Kalman     KalmanConfig     `mapstructure:"kalman"`
+	Wake       WakeConfig       `mapstructure:"wake"`
+}
+
+// AudioI2CConfig locates the XVF3800 when audio.transport is i2c
+type AudioI2CConfig struct {
+	Bus     string `mapstructure:"bus"`     // I2C bus device
+	Address int    `mapstructure:"address"` // 7-bit device address
+}
+
+// EchoConfig configures speech handling while the robot's speaker plays
+type EchoConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`            // Ignore the speech flag during playback
+	TailMs            int     `mapstructure:"tail_ms"`            // Keep ignoring it this long after playback (reverb)
+	BargeIn           bool    `mapstructure:"barge_in"`           // Detect speech far louder than the echo
+	BargeInRatio      float64 `mapstructure:"barge_in_ratio"`     // Energy over the echo level that counts as barge-in
+	InterruptPlayback bool    `mapstructure:"interrupt_playback"` // Stop playback and clear the queue on barge-in
+}
+
+// OutlierConfig configures rejection of single-poll DOA glitches
+type OutlierConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	MaxJumpDeg float64 `mapstructure:"max_jump_deg"` // Distance from the predicted angle that makes a reading suspect
+	Confirm    int     `mapstructure:"confirm"`      // Consecutive agreeing readings that make a jump real
+}
+
+// IdleConfig slows DOA polling after prolonged silence
+type IdleConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	After   time.Duration `mapstructure:"after"`   // Silence before slowing down
+	PollHz  int           `mapstructure:"poll_hz"` // Rate while idle; speech returns to audio.poll_hz
+}
+
+// WakeConfig configures the energy-onset detector that hands the cloud the
+// audio around a possible wake word
+type WakeConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	PreRoll  time.Duration `mapstructure:"pre_roll"` // Audio from before the onset sent with it
+	Window   time.Duration `mapstructure:"window"`   // Frame the level is measured over
+	SpikeDB  float64       `mapstructure:"spike_db"` // Rise above the background level that counts as an onset
+	MinDBFS  float64       `mapstructure:"min_dbfs"` // Quietest level that can trigger
+	Stream   time.Duration `mapstructure:"stream"`   // Live audio sent after the onset
+	Cooldown time.Duration `mapstructure:"cooldown"` // No new candidate this soon after the last one
+}
+
+// SpeakersConfig configures re-identifying a speaker who talks again from
+// roughly the same spot
+type SpeakersConfig struct {
+	GateDeg     float64       `mapstructure:"gate_deg"`     // Max distance from a speaker's spot for a turn to be theirs
+	Timeout     time.Duration `mapstructure:"timeout"`      // Forget speakers not heard for this long
+	MaxSpeakers int           `mapstructure:"max_speakers"` // Forget the least recently heard beyond this
+}
+
+// MicsConfig configures per-mic diagnostics and dead-mic detection
+type MicsConfig struct {
+	Enabled   bool    `mapstructure:"enabled"`
+	Window    int     `mapstructure:"window"`     // Speaking readings averaged before a mic is judged
+	LowRatio  float64 `mapstructure:"low_ratio"`  // Energy below this fraction of the median mic is low
+	DeadRatio float64 `mapstructure:"dead_ratio"` // Energy below this fraction of the median mic is dead
+}
+
+// PersistConfig configures keeping the tracker's last angle, speakers and
+// calibration constants across restarts
+type PersistConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	File    string        `mapstructure:"file"`    // Written on shutdown, read on startup
+	MaxAge  time.Duration `mapstructure:"max_age"` // Older state is ignored (0 = no limit)
+}
+
+// NoiseFloorConfig configures the learned background energy that speech
+// must clear on top of the XVF3800's speech flag
+type NoiseFloorConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	Alpha        float64 `mapstructure:"alpha"`         // Adaptation rate per non-speech reading (0-1)
+	Margin       float64 `mapstructure:"margin"`        // Threshold = floor + margin standard deviations
+	MinThreshold float64 `mapstructure:"min_threshold"` // Lowest energy threshold
+	Warmup       int     `mapstructure:"warmup"`        // Non-speech readings before gating starts
+}
+
+// MountingConfig describes how the mic array is mounted on the head.
+// A mounting set or measured through /api/audio/mounting takes precedence.
+type MountingConfig struct {
+	OffsetDeg float64 `mapstructure:"offset_deg"` // Array rotation from the head (+ = left)
+	Mirror    bool    `mapstructure:"mirror"`     // Array mounted upside down
+}
+
+// ZoneEntry names a sector of DOA angles (degrees, 0 = front, + = left)
+type ZoneEntry struct {
+	Name      string  `mapstructure:"name"`
+	CenterDeg float64 `mapstructure:"center_deg"`
+	WidthDeg  float64 `mapstructure:"width_deg"`
+}
+
+// ProcessorEntry names a processor registered with doa.RegisterProcessor
+// and its parameters
+type ProcessorEntry struct {
+	Name   string             `mapstructure:"name"`
+	Params map[string]float64 `mapstructure:"params"`
+}
+
+// SourceConfig selects the DOA source. The default, auto, is the backend
+// chain (USB → Python → mock).
+type SourceConfig struct {
+	Backend       string             `mapstructure:"backend"`        // auto, usb, i2c, python, mock, wave or replay:<file>
+	ProbeInterval time.Duration      `mapstructure:"probe_interval"` // Re-probe higher-priority backends
+	FailAfter     int                `mapstructure:"fail_after"`     // Consecutive errors before failover
+	MockFallback  bool               `mapstructure:"mock_fallback"`
+	Python        PythonSourceConfig `mapstructure:"python"`
+	Replay        ReplaySourceConfig `mapstructure:"replay"`
+	Health        SourceHealthConfig `mapstructure:"health"`
+}
+
+// SourceHealthConfig configures probing the XVF3800 independently of DOA
+// polling, so a disconnect is noticed even when nothing reads DOA
+type SourceHealthConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`   // Time between VERSION reads
+	Timeout   time.Duration `mapstructure:"timeout"`    // Per-probe limit
+	FailAfter int           `mapstructure:"fail_after"` // Consecutive failed probes before doa_device is unhealthy
+}
+
+// ReplaySourceConfig configures replay:<file> sources
+type ReplaySourceConfig struct {
+	Speed float64 `mapstructure:"speed"` // Playback rate (1 = original timing, 0 = one reading per poll)
+	Loop  bool    `mapstructure:"loop"`  // Start over at the end instead of shutting down
+}
+
+// PythonSourceConfig configures the Python subprocess DOA reader
+type PythonSourceConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Command      string        `mapstructure:"command"`
+	Args         []string      `mapstructure:"args"`
+	StartTimeout time.Duration `mapstructure:"start_timeout"`
+}
+
+// KalmanConfig tunes the Kalman smoothing mode
+type KalmanConfig struct {
+	ProcessNoise     float64 `mapstructure:"process_noise"`
+	MeasurementNoise float64 `mapstructure:"measurement_noise"`
 }
 
 // ConfidenceConfig configures confidence scoring
@@ -84,30 +711,183 @@ func Default() *Config {
 			ReadTimeout:     10 * time.Second,
 			WriteTimeout:    10 * time.Second,
 			GracefulTimeout: 5 * time.Second,
+			Auth: AuthConfig{
+				Exempt: []string{"/health", "/metrics"},
+			},
+			TLS: TLSConfig{
+				CertFile:   "/var/lib/go-eva/tls/cert.pem",
+				KeyFile:    "/var/lib/go-eva/tls/key.pem",
+				SelfSigned: true,
+			},
+			WebSocket: WSConfig{
+				QueueSize:    64,
+				WriteTimeout: 5 * time.Second,
+				StallTimeout: 10 * time.Second,
+			},
+		},
+		GRPC: GRPCConfig{
+			Bind:  "127.0.0.1",
+			Port:  9001,
+			Motor: true,
+		},
+		State: StateConfig{
+			Interval: 5 * time.Second,
+		},
+		Health: HealthConfig{
+			Interval: 10 * time.Second,
+			Timeout:  2 * time.Second,
+			Critical: []string{"doa_source", "tracker"},
 		},
 		Audio: AudioConfig{
 			PollHz:            20,
 			SpeakingLatchMs:   500,
 			EMAAlpha:          0.3,
-			HistorySize:       100,
+			HistorySize:       1200, // 60s at 20Hz
 			USBReconnectDelay: 1 * time.Second,
+			Transport:         "usb",
+			Smoothing:         "ema",
+			MedianWindow:      5,
+
+			UtteranceHangoverMs: 300,
+			MaxUtteranceMs:      15000,
+			ZoneHysteresisDeg:   5,
+			ZoneDwellMs:         200,
+			I2C: AudioI2CConfig{
+				Bus:     "/dev/i2c-1",
+				Address: 0x2C,
+			},
+			NoiseFloor: NoiseFloorConfig{
+				Enabled: true,
+				Alpha:   0.02,
+				Margin:  3,
+				Warmup:  40,
+			},
+			Echo: EchoConfig{
+				Enabled:           true,
+				TailMs:            300,
+				BargeInRatio:      4,
+				InterruptPlayback: true,
+			},
+			Outliers: OutlierConfig{
+				Enabled:    true,
+				MaxJumpDeg: 60,
+				Confirm:    3,
+			},
+			Idle: IdleConfig{
+				Enabled: true,
+				After:   30 * time.Second,
+				PollHz:  2,
+			},
+			Speakers: SpeakersConfig{
+				GateDeg:     20,
+				Timeout:     5 * time.Minute,
+				MaxSpeakers: 8,
+			},
+			Mics: MicsConfig{
+				Enabled:   true,
+				Window:    200,
+				LowRatio:  0.2,
+				DeadRatio: 0.02,
+			},
+			Persist: PersistConfig{
+				Enabled: true,
+				File:    "/var/lib/go-eva/tracker.json",
+				MaxAge:  10 * time.Minute,
+			},
 			Confidence: ConfidenceConfig{
 				Base:           0.3,
 				SpeakingBonus:  0.4,
 				StabilityBonus: 0.2,
 			},
+			Kalman: KalmanConfig{
+				ProcessNoise:     1.0,
+				MeasurementNoise: 0.02,
+			},
+			Wake: WakeConfig{
+				PreRoll:  time.Second,
+				Window:   20 * time.Millisecond,
+				SpikeDB:  15,
+				MinDBFS:  -50,
+				Stream:   2 * time.Second,
+				Cooldown: 3 * time.Second,
+			},
+		},
+		Source: SourceConfig{
+			Backend:       "auto",
+			ProbeInterval: 30 * time.Second,
+			FailAfter:     10,
+			MockFallback:  true,
+			Python: PythonSourceConfig{
+				Enabled:      true,
+				Command:      "python3",
+				Args:         []string{"-u", "/usr/local/share/go-eva/xvf3800_doa.py"},
+				StartTimeout: 3 * time.Second,
+			},
+			Replay: ReplaySourceConfig{
+				Speed: 1,
+			},
+			Health: SourceHealthConfig{
+				Enabled:   true,
+				Interval:  5 * time.Second,
+				Timeout:   time.Second,
+				FailAfter: 2,
+			},
 		},
 		Cloud: CloudConfig{
 			Enabled:          true, // Enabled by default
 			URL:              "ws://localhost:8888/ws/robot",
+			FailoverAfter:    30 * time.Second,
+			PrimaryRetry:     5 * time.Minute,
 			ReconnectBackoff: 1 * time.Second,
 			MaxBackoff:       30 * time.Second,
 			PingInterval:     10 * time.Second,
+			ClockSync:        30 * time.Second,
+			DOABatch:         200 * time.Millisecond,
+			BinaryFrames:     true,
+			AudioCodecs:      []string{"opus", "pcm16"},
+			OpusBitrate:      24000,
+			Queue: CloudQueue{
+				Enabled:     true,
+				Path:        "/var/lib/go-eva/cloud-queue.jsonl",
+				MaxMessages: 10000,
+				MaxAge:      1 * time.Hour,
+				DropPolicy:  "oldest",
+			},
+			Send: CloudSend{
+				QueueSize: 256,
+			},
+			Compression: CloudCompression{
+				Deflate:       true,
+				Level:         1,
+				GzipThreshold: 4096,
+			},
 		},
 		Pollen: PollenConfig{
 			BaseURL:     "http://localhost:8000",
 			Timeout:     2 * time.Second,
 			RateLimitHz: 30,
+			Safety: MotorSafetyConfig{
+				Enabled:         true,
+				YawMin:          -90,
+				YawMax:          90,
+				PitchMin:        -35,
+				PitchMax:        35,
+				RollMin:         -25,
+				RollMax:         25,
+				MaxVelocity:     180,
+				MaxAcceleration: 720,
+				RateHz:          30,
+			},
+			Pose: PoseConfig{
+				Enabled:    true,
+				Interval:   100 * time.Millisecond,
+				StaleAfter: time.Second,
+			},
+			Breaker: PollenBreakerConfig{
+				Enabled:  true,
+				Failures: 3,
+				Cooldown: 5 * time.Second,
+			},
 		},
 		Camera: CameraConfig{
 			Enabled:   true, // Enabled by default
@@ -115,6 +895,169 @@ func Default() *Config {
 			Width:     640,
 			Height:    480,
 			Quality:   80,
+
+			FOVDeg:           80,
+			SnapshotFallback: true,
+			ChangeDetection: ChangeDetectionConfig{
+				Enabled:    true,
+				Threshold:  0.01,
+				PixelDelta: 12,
+				Keepalive:  5 * time.Second,
+			},
+			Adaptive: CameraAdaptiveConfig{
+				Enabled:      true,
+				Interval:     2 * time.Second,
+				HighDelay:    500 * time.Millisecond,
+				LowDelay:     100 * time.Millisecond,
+				RecoverAfter: 10 * time.Second,
+				MaxQueue:     32,
+				MinFramerate: 2,
+				MinQuality:   40,
+			},
+			Archive: CameraArchiveConfig{
+				Enabled:   false,
+				Dir:       "/var/lib/go-eva/snapshots",
+				Interval:  time.Minute,
+				OnSpeech:  true,
+				MinGap:    5 * time.Second,
+				Retention: 7 * 24 * time.Hour,
+				MaxBytes:  256 << 20,
+			},
+		},
+		History: HistoryConfig{
+			Enabled:        false,
+			Backend:        "sqlite",
+			Path:           "/var/lib/go-eva/history.db",
+			SampleInterval: 1 * time.Second,
+			FlushInterval:  5 * time.Second,
+			Retention:      7 * 24 * time.Hour,
+			MaxRows:        50000,
+		},
+		Recorder: RecorderConfig{
+			Enabled:         false,
+			Dir:             "/var/lib/go-eva/doa",
+			SegmentDuration: 10 * time.Minute,
+			Retention:       48 * time.Hour,
+			MaxBytes:        256 << 20,
+		},
+		Audit: AuditConfig{
+			Enabled:      false,
+			Dir:          "/var/lib/go-eva/audit",
+			MaxFileBytes: 16 << 20,
+			MaxFiles:     32,
+		},
+		Calibration: CalibrationConfig{
+			File:       "/var/lib/go-eva/calibration.json",
+			Duration:   10 * time.Second,
+			MinSamples: 20,
+		},
+		Influx: InfluxConfig{
+			Enabled:  false,
+			Interval: 10 * time.Second,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:        false,
+			Timeout:        5 * time.Second,
+			MaxRetries:     3,
+			InitialBackoff: time.Second,
+			MaxBackoff:     30 * time.Second,
+			QueueSize:      64,
+		},
+		TTS: TTSConfig{
+			Enabled: false,
+			Engine:  "espeak",
+			Voice:   "en-us",
+			Speed:   160,
+			Timeout: 10 * time.Second,
+		},
+		STT: STTConfig{
+			Enabled:      false,
+			Command:      "whisper-cli",
+			Args:         []string{"-m", "/usr/share/whisper/ggml-base.en.bin", "-nt", "-np", "-f", "{file}"},
+			MinUtterance: 300 * time.Millisecond,
+			MaxUtterance: 15 * time.Second,
+			Timeout:      20 * time.Second,
+		},
+		Emotion: EmotionConfig{
+			AllowUnknown:    true,
+			DefaultDuration: 3 * time.Second,
+			QueueSize:       8,
+			Overlap:         "queue",
+		},
+		Choreo: ChoreoConfig{
+			RateHz:      30,
+			MaxDuration: 2 * time.Minute,
+		},
+		Gesture: GestureConfig{
+			Enabled:         false,
+			NewSpeakerAngle: 30,
+			BehindAngle:     120,
+			LoudRatio:       8,
+		},
+		Animation: AnimationConfig{
+			Enabled:       false,
+			Rate:          50 * time.Millisecond,
+			SwayAmplitude: 0.15,
+			TwitchGain:    0.25,
+			TargetHold:    2 * time.Second,
+		},
+		Vision: VisionConfig{
+			CascadePath:   "/etc/go-eva/facefinder",
+			Interval:      200 * time.Millisecond,
+			MinFaceSize:   20,
+			MinConfidence: 0.3,
+			MatchDeg:      25,
+			MaxFaceAge:    time.Second,
+			UnseenPenalty: 0.5,
+		},
+		Expression: ExpressionConfig{
+			Enabled:      false,
+			Rest:         []float64{0, 0},
+			Perk:         []float64{0.6, 0.6},
+			Listen:       []float64{0.3, 0.3},
+			PerkHold:     400 * time.Millisecond,
+			Lean:         0.3,
+			ReleaseAfter: 1500 * time.Millisecond,
+			MaxVelocity:  3,
+		},
+		Playback: PlaybackConfig{
+			DeviceRate: 16000,
+			MaxQueue:   16,
+			MP3Command: "ffmpeg",
+			Cache: SpeakCacheConfig{
+				Enabled:  true,
+				Dir:      "/var/lib/go-eva/speak-cache",
+				MaxBytes: 64 << 20,
+			},
+		},
+		Capture: CaptureConfig{
+			Source: "arecord",
+		},
+		Behavior: BehaviorConfig{
+			AutoTrack: AutoTrackConfig{
+				Enabled:       false,
+				DeadBand:      8,
+				MaxVelocity:   90,
+				MaxYaw:        60,
+				MinConfidence: 0.6,
+				ReturnAfter:   10 * time.Second,
+				Body: BodyYawConfig{
+					Enabled:     false,
+					MaxYaw:      90,
+					Threshold:   50,
+					Hysteresis:  10,
+					MaxVelocity: 45,
+				},
+			},
+		},
+		Privacy: PrivacyConfig{
+			ButtonActiveLow: true,
+		},
+		XVF3800: XVF3800Config{
+			ProfileFile: "/var/lib/go-eva/xvf3800_profile.json",
+		},
+		Secrets: SecretsConfig{
+			AgeIdentity: "/etc/go-eva/age.key",
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -123,7 +1066,9 @@ func Default() *Config {
 	}
 }
 
-// Load loads configuration from file and environment
+// Load loads configuration from file and environment. A file encrypted with
+// sops is decrypted first; then <secret>_file keys are read, ${VAR}
+// references expanded and age-armored values decrypted.
 func Load(path string) (*Config, error) {
 	v := viper.New()
 
@@ -142,6 +1087,11 @@ func Load(path string) (*Config, error) {
 				fmt.Printf("Warning: config file not found at %s, using defaults\n", path)
 			}
 		}
+		if v.InConfig("sops") {
+			if err := readSops(v, path); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Environment variable overrides
@@ -149,8 +1099,20 @@ func Load(path string) (*Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
+	if err := resolveSecretFiles(v); err != nil {
+		return nil, err
+	}
+
+	identity, err := expandEnv(v.GetString("secrets.age_identity"))
+	if err != nil {
+		return nil, fmt.Errorf("secrets.age_identity: %w", err)
+	}
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		secretHook(identity),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -160,33 +1122,160 @@ func Load(path string) (*Config, error) {
 func setDefaults(v *viper.Viper) {
 	// Server defaults
 	v.SetDefault("server.port", 9000)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.bind", "127.0.0.1")
+	v.SetDefault("grpc.port", 9001)
+	v.SetDefault("grpc.motor", true)
+	v.SetDefault("state.interval", "5s")
+	v.SetDefault("health.interval", "10s")
+	v.SetDefault("health.timeout", "2s")
+	v.SetDefault("health.critical", []string{"doa_source", "tracker"})
 	v.SetDefault("server.read_timeout", "10s")
 	v.SetDefault("server.write_timeout", "10s")
 	v.SetDefault("server.graceful_timeout", "5s")
+	v.SetDefault("server.auth.api_keys", []string{})
+	v.SetDefault("server.auth.exempt", []string{"/health", "/metrics"})
+	v.SetDefault("server.cors.allow_origins", []string{})
+	v.SetDefault("server.cors.allow_credentials", false)
+	v.SetDefault("server.cors.max_age", 0)
+	v.SetDefault("server.tls.enabled", false)
+	v.SetDefault("server.tls.cert_file", "/var/lib/go-eva/tls/cert.pem")
+	v.SetDefault("server.tls.key_file", "/var/lib/go-eva/tls/key.pem")
+	v.SetDefault("server.tls.self_signed", true)
+	v.SetDefault("server.tls.redirect_port", 0)
+	v.SetDefault("server.websocket.queue_size", 64)
+	v.SetDefault("server.websocket.write_timeout", "5s")
+	v.SetDefault("server.websocket.stall_timeout", "10s")
 
 	// Audio defaults
 	v.SetDefault("audio.poll_hz", 20)
 	v.SetDefault("audio.speaking_latch_ms", 500)
 	v.SetDefault("audio.ema_alpha", 0.3)
-	v.SetDefault("audio.history_size", 100)
+	v.SetDefault("audio.history_size", 1200)
 	v.SetDefault("audio.usb_reconnect_delay", "1s")
+	v.SetDefault("audio.transport", "usb")
+	v.SetDefault("audio.i2c.bus", "/dev/i2c-1")
+	v.SetDefault("audio.i2c.address", 0x2C)
+	v.SetDefault("audio.smoothing", "ema")
+	v.SetDefault("audio.median_window", 5)
+	v.SetDefault("audio.utterance_hangover_ms", 300)
+	v.SetDefault("audio.max_utterance_ms", 15000)
+	v.SetDefault("audio.zone_hysteresis_deg", 5)
+	v.SetDefault("audio.zone_dwell_ms", 200)
+	v.SetDefault("audio.zone_world", false)
+	v.SetDefault("audio.noise_floor.enabled", true)
+	v.SetDefault("audio.noise_floor.alpha", 0.02)
+	v.SetDefault("audio.noise_floor.margin", 3)
+	v.SetDefault("audio.noise_floor.min_threshold", 0)
+	v.SetDefault("audio.noise_floor.warmup", 40)
+	v.SetDefault("audio.echo.enabled", true)
+	v.SetDefault("audio.echo.tail_ms", 300)
+	v.SetDefault("audio.echo.barge_in", false)
+	v.SetDefault("audio.echo.barge_in_ratio", 4)
+	v.SetDefault("audio.echo.interrupt_playback", true)
+	v.SetDefault("audio.outliers.enabled", true)
+	v.SetDefault("audio.outliers.max_jump_deg", 60)
+	v.SetDefault("audio.outliers.confirm", 3)
+	v.SetDefault("audio.idle.enabled", true)
+	v.SetDefault("audio.idle.after", "30s")
+	v.SetDefault("audio.idle.poll_hz", 2)
+	v.SetDefault("audio.speakers.gate_deg", 20)
+	v.SetDefault("audio.speakers.timeout", "5m")
+	v.SetDefault("audio.speakers.max_speakers", 8)
+	v.SetDefault("audio.mics.enabled", true)
+	v.SetDefault("audio.mics.window", 200)
+	v.SetDefault("audio.mics.low_ratio", 0.2)
+	v.SetDefault("audio.mics.dead_ratio", 0.02)
+	v.SetDefault("audio.persist.enabled", true)
+	v.SetDefault("audio.persist.file", "/var/lib/go-eva/tracker.json")
+	v.SetDefault("audio.persist.max_age", "10m")
+	v.SetDefault("audio.mounting.offset_deg", 0.0)
+	v.SetDefault("audio.mounting.mirror", false)
+	v.SetDefault("audio.kalman.process_noise", 1.0)
+	v.SetDefault("audio.kalman.measurement_noise", 0.02)
+	v.SetDefault("audio.wake.enabled", false)
+	v.SetDefault("audio.wake.pre_roll", "1s")
+	v.SetDefault("audio.wake.window", "20ms")
+	v.SetDefault("audio.wake.spike_db", 15)
+	v.SetDefault("audio.wake.min_dbfs", -50)
+	v.SetDefault("audio.wake.stream", "2s")
+	v.SetDefault("audio.wake.cooldown", "3s")
 
 	// Confidence defaults
 	v.SetDefault("audio.confidence.base", 0.3)
 	v.SetDefault("audio.confidence.speaking_bonus", 0.4)
 	v.SetDefault("audio.confidence.stability_bonus", 0.2)
 
+	// DOA source chain defaults
+	v.SetDefault("source.backend", "auto")
+	v.SetDefault("source.probe_interval", "30s")
+	v.SetDefault("source.fail_after", 10)
+	v.SetDefault("source.mock_fallback", true)
+	v.SetDefault("source.python.enabled", true)
+	v.SetDefault("source.python.command", "python3")
+	v.SetDefault("source.python.args", []string{"-u", "/usr/local/share/go-eva/xvf3800_doa.py"})
+	v.SetDefault("source.python.start_timeout", "3s")
+	v.SetDefault("source.replay.speed", 1.0)
+	v.SetDefault("source.replay.loop", false)
+	v.SetDefault("source.health.enabled", true)
+	v.SetDefault("source.health.interval", "5s")
+	v.SetDefault("source.health.timeout", "1s")
+	v.SetDefault("source.health.fail_after", 2)
+
 	// Cloud defaults
 	v.SetDefault("cloud.enabled", true)
 	v.SetDefault("cloud.url", "ws://localhost:8888/ws/robot")
+	v.SetDefault("cloud.urls", []string{})
+	v.SetDefault("cloud.failover_after", "30s")
+	v.SetDefault("cloud.primary_retry", "5m")
 	v.SetDefault("cloud.reconnect_backoff", "1s")
 	v.SetDefault("cloud.max_backoff", "30s")
 	v.SetDefault("cloud.ping_interval", "10s")
+	v.SetDefault("cloud.clock_sync", "30s")
+	v.SetDefault("cloud.doa_batch", "200ms")
+	v.SetDefault("cloud.binary_frames", true)
+	v.SetDefault("cloud.robot_id", "")
+	v.SetDefault("cloud.stream_mic", false)
+	v.SetDefault("cloud.audio_codecs", []string{"opus", "pcm16"})
+	v.SetDefault("cloud.opus_bitrate", 24000)
+	v.SetDefault("cloud.strict_protocol", false)
+	v.SetDefault("cloud.auth.token", "")
+	v.SetDefault("cloud.auth.hmac_secret", "")
+	v.SetDefault("cloud.auth.cert_file", "")
+	v.SetDefault("cloud.auth.key_file", "")
+	v.SetDefault("cloud.auth.ca_file", "")
+	v.SetDefault("cloud.queue.enabled", true)
+	v.SetDefault("cloud.queue.path", "/var/lib/go-eva/cloud-queue.jsonl")
+	v.SetDefault("cloud.queue.max_messages", 10000)
+	v.SetDefault("cloud.queue.max_age", "1h")
+	v.SetDefault("cloud.queue.drop_policy", "oldest")
+	v.SetDefault("cloud.send.queue_size", 256)
+	v.SetDefault("cloud.send.rate", 0)
+	v.SetDefault("cloud.send.burst", 0)
+	v.SetDefault("cloud.compression.deflate", true)
+	v.SetDefault("cloud.compression.level", 1)
+	v.SetDefault("cloud.compression.gzip_threshold", 4096)
 
 	// Pollen defaults
 	v.SetDefault("pollen.base_url", "http://localhost:8000")
 	v.SetDefault("pollen.timeout", "2s")
 	v.SetDefault("pollen.rate_limit_hz", 30)
+	v.SetDefault("pollen.safety.enabled", true)
+	v.SetDefault("pollen.safety.yaw_min", -90)
+	v.SetDefault("pollen.safety.yaw_max", 90)
+	v.SetDefault("pollen.safety.pitch_min", -35)
+	v.SetDefault("pollen.safety.pitch_max", 35)
+	v.SetDefault("pollen.safety.roll_min", -25)
+	v.SetDefault("pollen.safety.roll_max", 25)
+	v.SetDefault("pollen.safety.max_velocity", 180)
+	v.SetDefault("pollen.safety.max_acceleration", 720)
+	v.SetDefault("pollen.safety.rate_hz", 30)
+	v.SetDefault("pollen.pose.enabled", true)
+	v.SetDefault("pollen.pose.interval", "100ms")
+	v.SetDefault("pollen.pose.stale_after", "1s")
+	v.SetDefault("pollen.breaker.enabled", true)
+	v.SetDefault("pollen.breaker.failures", 3)
+	v.SetDefault("pollen.breaker.cooldown", "5s")
 
 	// Camera defaults
 	v.SetDefault("camera.enabled", true)
@@ -194,6 +1283,164 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("camera.width", 640)
 	v.SetDefault("camera.height", 480)
 	v.SetDefault("camera.quality", 80)
+	v.SetDefault("camera.fov_deg", 80)
+	v.SetDefault("camera.snapshot_fallback", true)
+	v.SetDefault("camera.change_detection.enabled", true)
+	v.SetDefault("camera.change_detection.threshold", 0.01)
+	v.SetDefault("camera.change_detection.pixel_delta", 12)
+	v.SetDefault("camera.change_detection.keepalive", "5s")
+	v.SetDefault("camera.adaptive.enabled", true)
+	v.SetDefault("camera.adaptive.interval", "2s")
+	v.SetDefault("camera.adaptive.high_delay", "500ms")
+	v.SetDefault("camera.adaptive.low_delay", "100ms")
+	v.SetDefault("camera.adaptive.recover_after", "10s")
+	v.SetDefault("camera.adaptive.max_queue", 32)
+	v.SetDefault("camera.adaptive.min_framerate", 2)
+	v.SetDefault("camera.adaptive.min_quality", 40)
+	v.SetDefault("camera.annotate.enabled", false)
+	v.SetDefault("camera.archive.enabled", false)
+	v.SetDefault("camera.archive.dir", "/var/lib/go-eva/snapshots")
+	v.SetDefault("camera.archive.interval", "1m")
+	v.SetDefault("camera.archive.on_speech", true)
+	v.SetDefault("camera.archive.min_gap", "5s")
+	v.SetDefault("camera.archive.retention", "168h")
+	v.SetDefault("camera.archive.max_bytes", 256<<20)
+
+	// History defaults
+	v.SetDefault("history.enabled", false)
+	v.SetDefault("history.backend", "sqlite")
+	v.SetDefault("history.path", "/var/lib/go-eva/history.db")
+	v.SetDefault("history.sample_interval", "1s")
+	v.SetDefault("history.flush_interval", "5s")
+	v.SetDefault("history.retention", "168h")
+	v.SetDefault("history.max_rows", 50000)
+
+	// Recorder defaults
+	v.SetDefault("recorder.enabled", false)
+	v.SetDefault("recorder.dir", "/var/lib/go-eva/doa")
+	v.SetDefault("recorder.segment_duration", "10m")
+	v.SetDefault("recorder.retention", "48h")
+	v.SetDefault("recorder.max_bytes", 256<<20)
+
+	// Audit log defaults
+	v.SetDefault("audit.enabled", false)
+	v.SetDefault("audit.dir", "/var/lib/go-eva/audit")
+	v.SetDefault("audit.max_file_bytes", 16<<20)
+	v.SetDefault("audit.max_files", 32)
+
+	// Calibration defaults
+	v.SetDefault("calibration.file", "/var/lib/go-eva/calibration.json")
+	v.SetDefault("calibration.duration", "10s")
+	v.SetDefault("calibration.min_samples", 20)
+
+	// Influx defaults
+	v.SetDefault("influx.enabled", false)
+	v.SetDefault("influx.url", "")
+	v.SetDefault("influx.token", "")
+	v.SetDefault("influx.interval", "10s")
+
+	// Webhook defaults
+	v.SetDefault("webhooks.enabled", false)
+	v.SetDefault("webhooks.timeout", "5s")
+	v.SetDefault("webhooks.max_retries", 3)
+	v.SetDefault("webhooks.initial_backoff", "1s")
+	v.SetDefault("webhooks.max_backoff", "30s")
+	v.SetDefault("webhooks.queue_size", 64)
+
+	// TTS defaults
+	v.SetDefault("tts.enabled", false)
+	v.SetDefault("tts.engine", "espeak")
+	v.SetDefault("tts.command", "")
+	v.SetDefault("tts.voice", "en-us")
+	v.SetDefault("tts.model", "")
+	v.SetDefault("tts.speed", 160)
+	v.SetDefault("tts.timeout", "10s")
+
+	// STT defaults
+	v.SetDefault("stt.enabled", false)
+	v.SetDefault("stt.command", "whisper-cli")
+	v.SetDefault("stt.args", []string{"-m", "/usr/share/whisper/ggml-base.en.bin", "-nt", "-np", "-f", "{file}"})
+	v.SetDefault("stt.min_utterance", "300ms")
+	v.SetDefault("stt.max_utterance", "15s")
+	v.SetDefault("stt.timeout", "20s")
+
+	// Gesture defaults
+	v.SetDefault("gesture.enabled", false)
+	v.SetDefault("gesture.new_speaker_angle", 30)
+	v.SetDefault("gesture.behind_angle", 120)
+	v.SetDefault("gesture.loud_ratio", 8)
+
+	// Emotion defaults
+	v.SetDefault("emotion.allow_unknown", true)
+	v.SetDefault("emotion.default_duration", "3s")
+	v.SetDefault("emotion.queue_size", 8)
+	v.SetDefault("emotion.idle_after", "0s")
+	v.SetDefault("emotion.overlap", "queue")
+	v.SetDefault("choreo.rate_hz", 30)
+	v.SetDefault("choreo.max_duration", "2m")
+
+	// Animation defaults
+	v.SetDefault("animation.enabled", false)
+	v.SetDefault("animation.rate", "50ms")
+	v.SetDefault("animation.sway_amplitude", 0.15)
+	v.SetDefault("animation.twitch_gain", 0.25)
+	v.SetDefault("animation.target_hold", "2s")
+
+	// Vision defaults
+	v.SetDefault("vision.enabled", false)
+	v.SetDefault("vision.cascade_path", "/etc/go-eva/facefinder")
+	v.SetDefault("vision.interval", "200ms")
+	v.SetDefault("vision.min_face_size", 20)
+	v.SetDefault("vision.min_confidence", 0.3)
+	v.SetDefault("vision.match_deg", 25)
+	v.SetDefault("vision.max_face_age", "1s")
+	v.SetDefault("vision.unseen_penalty", 0.5)
+
+	// Expression defaults
+	v.SetDefault("expression.enabled", false)
+	v.SetDefault("expression.rest", []float64{0, 0})
+	v.SetDefault("expression.perk", []float64{0.6, 0.6})
+	v.SetDefault("expression.listen", []float64{0.3, 0.3})
+	v.SetDefault("expression.perk_hold", "400ms")
+	v.SetDefault("expression.lean", 0.3)
+	v.SetDefault("expression.release_after", "1500ms")
+	v.SetDefault("expression.max_velocity", 3)
+
+	// Playback defaults
+	v.SetDefault("playback.device_rate", 16000)
+	v.SetDefault("playback.max_queue", 16)
+	v.SetDefault("playback.mp3_command", "ffmpeg")
+	v.SetDefault("playback.cache.enabled", true)
+	v.SetDefault("playback.cache.dir", "/var/lib/go-eva/speak-cache")
+	v.SetDefault("playback.cache.max_bytes", 64<<20)
+	v.SetDefault("capture.source", "arecord")
+
+	// Behavior defaults
+	v.SetDefault("behavior.autotrack.enabled", false)
+	v.SetDefault("behavior.autotrack.dead_band", 8)
+	v.SetDefault("behavior.autotrack.max_velocity", 90)
+	v.SetDefault("behavior.autotrack.max_yaw", 60)
+	v.SetDefault("behavior.autotrack.min_confidence", 0.6)
+	v.SetDefault("behavior.autotrack.return_after", "10s")
+	v.SetDefault("behavior.autotrack.body.enabled", false)
+	v.SetDefault("behavior.autotrack.body.max_yaw", 90)
+	v.SetDefault("behavior.autotrack.body.threshold", 50)
+	v.SetDefault("behavior.autotrack.body.hysteresis", 10)
+	v.SetDefault("behavior.autotrack.body.max_velocity", 45)
+
+	// XVF3800 control defaults
+	v.SetDefault("xvf3800.control_enabled", false)
+	v.SetDefault("xvf3800.profile", "")
+	v.SetDefault("xvf3800.profile_file", "/var/lib/go-eva/xvf3800_profile.json")
+
+	// Privacy defaults
+	v.SetDefault("privacy.start_enabled", false)
+	v.SetDefault("privacy.button_path", "")
+	v.SetDefault("privacy.button_active_low", true)
+	v.SetDefault("privacy.led_path", "")
+
+	// Secrets defaults
+	v.SetDefault("secrets.age_identity", "/etc/go-eva/age.key")
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -205,6 +1452,70 @@ func (c *Config) Validate() error {
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
+	for _, key := range c.Server.Auth.APIKeys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("server.auth.api_keys must not contain empty keys")
+		}
+	}
+	for _, path := range c.Server.Auth.Exempt {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("server.auth.exempt path %q must start with /", path)
+		}
+	}
+	for _, origin := range c.Server.CORS.AllowOrigins {
+		if origin == "*" {
+			if c.Server.CORS.AllowCredentials {
+				return fmt.Errorf("server.cors.allow_credentials cannot be used with allow_origins \"*\"")
+			}
+			continue
+		}
+		if !validOrigin(origin) {
+			return fmt.Errorf("server.cors.allow_origins entry %q must be \"*\" or scheme://host[:port]", origin)
+		}
+	}
+	if c.Server.CORS.MaxAge < 0 {
+		return fmt.Errorf("server.cors.max_age must not be negative")
+	}
+	if c.Server.TLS.Enabled {
+		if c.Server.TLS.CertFile == "" || c.Server.TLS.KeyFile == "" {
+			return fmt.Errorf("server.tls.cert_file and key_file are required when tls is enabled")
+		}
+		if c.Server.TLS.RedirectPort < 0 || c.Server.TLS.RedirectPort > 65535 {
+			return fmt.Errorf("server.tls.redirect_port must be between 0 and 65535, got %d", c.Server.TLS.RedirectPort)
+		}
+		if c.Server.TLS.RedirectPort == c.Server.Port {
+			return fmt.Errorf("server.tls.redirect_port must differ from server.port (%d)", c.Server.Port)
+		}
+	}
+	if c.Server.WebSocket.QueueSize < 1 {
+		return fmt.Errorf("server.websocket.queue_size must be positive")
+	}
+	if c.Server.WebSocket.WriteTimeout < 0 || c.Server.WebSocket.StallTimeout < 0 {
+		return fmt.Errorf("server.websocket timeouts must not be negative")
+	}
+
+	if c.GRPC.Enabled {
+		if c.GRPC.Port < 1 || c.GRPC.Port > 65535 {
+			return fmt.Errorf("grpc.port must be between 1 and 65535, got %d", c.GRPC.Port)
+		}
+		if c.GRPC.Bind != "" && net.ParseIP(c.GRPC.Bind) == nil {
+			return fmt.Errorf("grpc.bind must be an IP address, got %q", c.GRPC.Bind)
+		}
+		if c.GRPC.Port == c.Server.Port {
+			return fmt.Errorf("grpc.port must differ from server.port (%d)", c.Server.Port)
+		}
+		if c.Server.TLS.Enabled && c.GRPC.Port == c.Server.TLS.RedirectPort {
+			return fmt.Errorf("grpc.port must differ from server.tls.redirect_port (%d)", c.Server.TLS.RedirectPort)
+		}
+	}
+
+	if c.State.Interval < 0 {
+		return fmt.Errorf("state.interval must not be negative")
+	}
+
+	if c.Health.Interval <= 0 || c.Health.Timeout <= 0 {
+		return fmt.Errorf("health.interval and health.timeout must be positive")
+	}
 
 	if c.Audio.PollHz < 1 || c.Audio.PollHz > 100 {
 		return fmt.Errorf("poll_hz must be between 1 and 100, got %d", c.Audio.PollHz)
@@ -214,13 +1525,477 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("ema_alpha must be between 0 and 1, got %f", c.Audio.EMAAlpha)
 	}
 
-	if c.Cloud.Enabled && c.Cloud.URL == "" {
-		return fmt.Errorf("cloud.url is required when cloud is enabled")
+	if c.Audio.UtteranceHangoverMs < 0 || c.Audio.MaxUtteranceMs < 0 {
+		return fmt.Errorf("audio.utterance_hangover_ms and audio.max_utterance_ms must not be negative")
+	}
+
+	if c.Audio.ZoneHysteresisDeg < 0 || c.Audio.ZoneDwellMs < 0 {
+		return fmt.Errorf("audio.zone_hysteresis_deg and audio.zone_dwell_ms must not be negative")
+	}
+
+	nf := c.Audio.NoiseFloor
+	if nf.Alpha <= 0 || nf.Alpha > 1 {
+		return fmt.Errorf("audio.noise_floor.alpha must be in (0, 1], got %v", nf.Alpha)
+	}
+	if nf.Margin < 0 || nf.MinThreshold < 0 || nf.Warmup < 0 {
+		return fmt.Errorf("audio.noise_floor.margin, min_threshold and warmup must not be negative")
+	}
+
+	if c.Audio.Echo.TailMs < 0 {
+		return fmt.Errorf("audio.echo.tail_ms must not be negative")
+	}
+	if c.Audio.Echo.BargeIn && c.Audio.Echo.BargeInRatio <= 1 {
+		return fmt.Errorf("audio.echo.barge_in_ratio must be greater than 1, got %v", c.Audio.Echo.BargeInRatio)
+	}
+	if i := c.Audio.Idle; i.Enabled && (i.After <= 0 || i.PollHz <= 0 || i.PollHz > c.Audio.PollHz) {
+		return fmt.Errorf("audio.idle.after must be positive and poll_hz between 1 and audio.poll_hz, got %v and %d", i.After, i.PollHz)
+	}
+	if o := c.Audio.Outliers; o.Enabled && (o.MaxJumpDeg <= 0 || o.MaxJumpDeg > 180 || o.Confirm < 1) {
+		return fmt.Errorf("audio.outliers.max_jump_deg must be in (0, 180] and confirm at least 1, got %v and %d", o.MaxJumpDeg, o.Confirm)
+	}
+	if w := c.Audio.Wake; w.Enabled {
+		if w.PreRoll <= 0 || w.Window <= 0 {
+			return fmt.Errorf("audio.wake.pre_roll and window must be positive")
+		}
+		if w.SpikeDB <= 0 || w.MinDBFS > 0 {
+			return fmt.Errorf("audio.wake.spike_db must be positive and min_dbfs at most 0, got %v and %v", w.SpikeDB, w.MinDBFS)
+		}
+		if w.Stream < 0 || w.Cooldown < 0 {
+			return fmt.Errorf("audio.wake.stream and cooldown must not be negative")
+		}
+		if !c.Cloud.Enabled {
+			return fmt.Errorf("audio.wake requires cloud.enabled")
+		}
+	}
+	if sp := c.Audio.Speakers; sp.GateDeg <= 0 || sp.GateDeg > 180 {
+		return fmt.Errorf("audio.speakers.gate_deg must be between 0 and 180, got %v", sp.GateDeg)
+	}
+	if c.Audio.Speakers.Timeout <= 0 || c.Audio.Speakers.MaxSpeakers < 1 {
+		return fmt.Errorf("audio.speakers.timeout must be positive and max_speakers at least 1")
+	}
+	if m := c.Audio.Mics; m.Enabled {
+		if m.Window < 1 {
+			return fmt.Errorf("audio.mics.window must be at least 1, got %d", m.Window)
+		}
+		if m.DeadRatio <= 0 || m.DeadRatio >= m.LowRatio || m.LowRatio >= 1 {
+			return fmt.Errorf("audio.mics ratios must satisfy 0 < dead_ratio < low_ratio < 1, got %v and %v", m.DeadRatio, m.LowRatio)
+		}
+	}
+	if p := c.Audio.Persist; p.Enabled {
+		if p.File == "" {
+			return fmt.Errorf("audio.persist.file is required when persist is enabled")
+		}
+		if p.MaxAge < 0 {
+			return fmt.Errorf("audio.persist.max_age must not be negative")
+		}
+	}
+	if c.Audio.Mounting.OffsetDeg < -180 || c.Audio.Mounting.OffsetDeg > 180 {
+		return fmt.Errorf("audio.mounting.offset_deg must be between -180 and 180, got %f", c.Audio.Mounting.OffsetDeg)
+	}
+	for i, z := range c.Audio.Zones {
+		if z.Name == "" {
+			return fmt.Errorf("audio.zones[%d].name is required", i)
+		}
+		if z.WidthDeg <= 0 || z.WidthDeg > 360 {
+			return fmt.Errorf("audio.zones[%d].width_deg must be between 0 and 360, got %g", i, z.WidthDeg)
+		}
+	}
+	for i, p := range c.Audio.Processors {
+		if p.Name == "" {
+			return fmt.Errorf("audio.processors[%d].name is required", i)
+		}
+	}
+
+	switch c.Audio.Smoothing {
+	case "", "ema", "kalman", "median":
+	default:
+		return fmt.Errorf("audio.smoothing must be ema, kalman or median, got %q", c.Audio.Smoothing)
+	}
+
+	switch c.Audio.Transport {
+	case "", "usb":
+	case "i2c":
+		if c.Audio.I2C.Bus == "" {
+			return fmt.Errorf("audio.i2c.bus is required when audio.transport is i2c")
+		}
+		if c.Audio.I2C.Address < 0x03 || c.Audio.I2C.Address > 0x77 {
+			return fmt.Errorf("audio.i2c.address must be a 7-bit address between 0x03 and 0x77, got 0x%X", c.Audio.I2C.Address)
+		}
+	default:
+		return fmt.Errorf("audio.transport must be usb or i2c, got %q", c.Audio.Transport)
+	}
+
+	if c.Cloud.Enabled && c.Cloud.URL == "" && len(c.Cloud.URLs) == 0 {
+		return fmt.Errorf("cloud.url or cloud.urls is required when cloud is enabled")
+	}
+	for i, u := range c.Cloud.URLs {
+		if u == "" {
+			return fmt.Errorf("cloud.urls[%d] is empty", i)
+		}
+	}
+	if c.Cloud.FailoverAfter <= 0 || c.Cloud.PrimaryRetry < 0 {
+		return fmt.Errorf("cloud.failover_after must be positive and cloud.primary_retry not negative")
+	}
+
+	if (c.Cloud.Auth.CertFile == "") != (c.Cloud.Auth.KeyFile == "") {
+		return fmt.Errorf("cloud.auth.cert_file and cloud.auth.key_file must be set together")
+	}
+
+	for _, codec := range c.Cloud.AudioCodecs {
+		if codec != "pcm16" && codec != "opus" {
+			return fmt.Errorf("cloud.audio_codecs: unknown codec %q (valid: opus, pcm16)", codec)
+		}
+	}
+
+	if c.Cloud.Queue.Enabled {
+		if c.Cloud.Queue.MaxMessages < 1 {
+			return fmt.Errorf("cloud.queue.max_messages must be positive, got %d", c.Cloud.Queue.MaxMessages)
+		}
+		if c.Cloud.Queue.DropPolicy != "oldest" && c.Cloud.Queue.DropPolicy != "newest" {
+			return fmt.Errorf("cloud.queue.drop_policy must be oldest or newest, got %q", c.Cloud.Queue.DropPolicy)
+		}
+	}
+
+	if c.Cloud.Send.QueueSize < 1 {
+		return fmt.Errorf("cloud.send.queue_size must be positive, got %d", c.Cloud.Send.QueueSize)
+	}
+	if c.Cloud.Send.Rate < 0 || c.Cloud.Send.Burst < 0 {
+		return fmt.Errorf("cloud.send.rate and cloud.send.burst must not be negative")
+	}
+	if c.Cloud.ClockSync < 0 {
+		return fmt.Errorf("cloud.clock_sync must not be negative, got %v", c.Cloud.ClockSync)
+	}
+	if c.Cloud.DOABatch < 0 {
+		return fmt.Errorf("cloud.doa_batch must not be negative, got %v", c.Cloud.DOABatch)
+	}
+	if c.Cloud.Compression.Level < 1 || c.Cloud.Compression.Level > 9 {
+		return fmt.Errorf("cloud.compression.level must be between 1 and 9, got %d", c.Cloud.Compression.Level)
+	}
+	if c.Cloud.Compression.GzipThreshold < 0 {
+		return fmt.Errorf("cloud.compression.gzip_threshold must not be negative, got %d", c.Cloud.Compression.GzipThreshold)
+	}
+
+	if c.Cloud.OpusBitrate < 6000 || c.Cloud.OpusBitrate > 510000 {
+		return fmt.Errorf("cloud.opus_bitrate must be between 6000 and 510000, got %d", c.Cloud.OpusBitrate)
 	}
 
 	if c.Camera.Enabled && (c.Camera.Framerate < 1 || c.Camera.Framerate > 60) {
 		return fmt.Errorf("camera.framerate must be between 1 and 60, got %d", c.Camera.Framerate)
 	}
+	if c.Camera.Enabled && (c.Camera.FOVDeg <= 0 || c.Camera.FOVDeg >= 180) {
+		return fmt.Errorf("camera.fov_deg must be between 0 and 180, got %v", c.Camera.FOVDeg)
+	}
+
+	if cd := c.Camera.ChangeDetection; cd.Enabled {
+		if cd.Threshold < 0 || cd.Threshold > 1 {
+			return fmt.Errorf("camera.change_detection.threshold must be between 0 and 1, got %v", cd.Threshold)
+		}
+		if cd.PixelDelta < 0 || cd.PixelDelta > 255 {
+			return fmt.Errorf("camera.change_detection.pixel_delta must be between 0 and 255, got %d", cd.PixelDelta)
+		}
+		if cd.Keepalive < 0 {
+			return fmt.Errorf("camera.change_detection.keepalive must not be negative")
+		}
+	}
+
+	if ad := c.Camera.Adaptive; ad.Enabled {
+		if ad.Interval <= 0 || ad.RecoverAfter <= 0 {
+			return fmt.Errorf("camera.adaptive.interval and recover_after must be positive")
+		}
+		if ad.LowDelay <= 0 || ad.HighDelay <= ad.LowDelay {
+			return fmt.Errorf("camera.adaptive.high_delay (%v) must be above low_delay (%v), both positive", ad.HighDelay, ad.LowDelay)
+		}
+		if ad.MaxQueue < 0 {
+			return fmt.Errorf("camera.adaptive.max_queue must not be negative")
+		}
+		if ad.MinFramerate < 1 || ad.MinFramerate > 60 {
+			return fmt.Errorf("camera.adaptive.min_framerate must be between 1 and 60, got %d", ad.MinFramerate)
+		}
+		if ad.MinQuality < 1 || ad.MinQuality > 100 {
+			return fmt.Errorf("camera.adaptive.min_quality must be between 1 and 100, got %d", ad.MinQuality)
+		}
+	}
+
+	if ar := c.Camera.Archive; ar.Enabled {
+		if !c.Camera.Enabled {
+			return fmt.Errorf("camera.archive needs camera.enabled")
+		}
+		if ar.Dir == "" {
+			return fmt.Errorf("camera.archive.dir is required when the archive is enabled")
+		}
+		if ar.Interval < 0 || ar.MinGap < 0 || ar.Retention < 0 || ar.MaxBytes < 0 {
+			return fmt.Errorf("camera.archive interval, min_gap, retention and max_bytes must not be negative")
+		}
+		if ar.Interval == 0 && !ar.OnSpeech {
+			return fmt.Errorf("camera.archive needs an interval or on_speech")
+		}
+	}
+
+	if c.Influx.Enabled && c.Influx.URL == "" {
+		return fmt.Errorf("influx.url is required when influx export is enabled")
+	}
+
+	if wh := c.Webhooks; wh.Enabled {
+		if len(wh.Hooks) == 0 {
+			return fmt.Errorf("webhooks.hooks must not be empty when webhooks are enabled")
+		}
+		for i, hook := range wh.Hooks {
+			if !strings.HasPrefix(hook.URL, "http://") && !strings.HasPrefix(hook.URL, "https://") {
+				return fmt.Errorf("webhooks.hooks[%d].url must be an http or https URL, got %q", i, hook.URL)
+			}
+			for _, ev := range hook.Events {
+				if !slices.Contains(webhookEvents, ev) {
+					return fmt.Errorf("webhooks.hooks[%d].events: unknown event %q (want one of %s)", i, ev, strings.Join(webhookEvents, ", "))
+				}
+			}
+		}
+		if wh.Timeout <= 0 {
+			return fmt.Errorf("webhooks.timeout must be positive, got %v", wh.Timeout)
+		}
+		if wh.MaxRetries < 0 {
+			return fmt.Errorf("webhooks.max_retries must not be negative, got %d", wh.MaxRetries)
+		}
+		if wh.InitialBackoff <= 0 || wh.MaxBackoff < wh.InitialBackoff {
+			return fmt.Errorf("webhooks.initial_backoff must be positive and at most max_backoff")
+		}
+		if wh.QueueSize < 1 {
+			return fmt.Errorf("webhooks.queue_size must be at least 1, got %d", wh.QueueSize)
+		}
+	}
+
+	if c.TTS.Enabled && c.TTS.Engine != "espeak" && c.TTS.Engine != "piper" {
+		return fmt.Errorf("tts.engine must be espeak or piper, got %q", c.TTS.Engine)
+	}
+
+	if c.STT.Enabled && c.STT.Command == "" {
+		return fmt.Errorf("stt.command is required when stt is enabled")
+	}
+
+	for i, rule := range c.Gesture.Rules {
+		if rule.Antennas != nil && len(rule.Antennas) != 2 {
+			return fmt.Errorf("gesture.rules[%d].antennas must have 2 values, got %d", i, len(rule.Antennas))
+		}
+	}
+
+	if c.Emotion.QueueSize <= 0 {
+		return fmt.Errorf("emotion.queue_size must be positive, got %d", c.Emotion.QueueSize)
+	}
+	if c.Emotion.DefaultDuration <= 0 {
+		return fmt.Errorf("emotion.default_duration must be positive, got %v", c.Emotion.DefaultDuration)
+	}
+	if c.Emotion.IdleAfter < 0 {
+		return fmt.Errorf("emotion.idle_after must not be negative, got %v", c.Emotion.IdleAfter)
+	}
+	if c.Emotion.Overlap != "queue" && c.Emotion.Overlap != "reject" {
+		return fmt.Errorf("emotion.overlap must be queue or reject, got %q", c.Emotion.Overlap)
+	}
+	for i, e := range c.Emotion.Library {
+		if e.Name == "" {
+			return fmt.Errorf("emotion.library[%d].name is required", i)
+		}
+	}
+	if c.Choreo.RateHz < 1 || c.Choreo.RateHz > 100 {
+		return fmt.Errorf("choreo.rate_hz must be between 1 and 100, got %d", c.Choreo.RateHz)
+	}
+	if c.Choreo.MaxDuration <= 0 {
+		return fmt.Errorf("choreo.max_duration must be positive, got %v", c.Choreo.MaxDuration)
+	}
+
+	if c.Animation.Enabled && c.Animation.Rate < 10*time.Millisecond {
+		return fmt.Errorf("animation.rate must be at least 10ms, got %v", c.Animation.Rate)
+	}
+
+	if c.Vision.Enabled {
+		if !c.Camera.Enabled {
+			return fmt.Errorf("vision needs camera.enabled")
+		}
+		if c.Vision.CascadePath == "" {
+			return fmt.Errorf("vision.cascade_path is required when vision is enabled")
+		}
+		if c.Vision.Interval <= 0 || c.Vision.MaxFaceAge <= 0 {
+			return fmt.Errorf("vision.interval and max_face_age must be positive")
+		}
+		if c.Vision.MatchDeg <= 0 || c.Vision.MatchDeg > 90 {
+			return fmt.Errorf("vision.match_deg must be between 0 and 90, got %v", c.Vision.MatchDeg)
+		}
+		if c.Vision.UnseenPenalty < 0 || c.Vision.UnseenPenalty > 1 {
+			return fmt.Errorf("vision.unseen_penalty must be between 0 and 1, got %v", c.Vision.UnseenPenalty)
+		}
+	}
+
+	if c.Expression.Enabled {
+		poses := map[string][]float64{"rest": c.Expression.Rest, "perk": c.Expression.Perk, "listen": c.Expression.Listen}
+		for _, name := range []string{"rest", "perk", "listen"} {
+			if len(poses[name]) != 2 {
+				return fmt.Errorf("expression.%s must have 2 values, got %d", name, len(poses[name]))
+			}
+		}
+		if c.Expression.MaxVelocity <= 0 {
+			return fmt.Errorf("expression.max_velocity must be positive, got %v", c.Expression.MaxVelocity)
+		}
+	}
+
+	if c.Playback.DeviceRate < 0 {
+		return fmt.Errorf("playback.device_rate must not be negative, got %d", c.Playback.DeviceRate)
+	}
+	if c.Playback.MaxQueue <= 0 {
+		return fmt.Errorf("playback.max_queue must be positive, got %d", c.Playback.MaxQueue)
+	}
+	if cache := c.Playback.Cache; cache.Enabled {
+		if cache.Dir == "" {
+			return fmt.Errorf("playback.cache.dir is required when the speak cache is enabled")
+		}
+		if cache.MaxBytes <= 0 {
+			return fmt.Errorf("playback.cache.max_bytes must be positive, got %d", cache.MaxBytes)
+		}
+	}
+	switch c.Capture.Source {
+	case "arecord":
+	case "webrtc":
+		if !c.Camera.Enabled {
+			return fmt.Errorf("capture.source webrtc requires camera.enabled")
+		}
+	default:
+		return fmt.Errorf("capture.source must be arecord or webrtc, got %q", c.Capture.Source)
+	}
+
+	if safety := c.Pollen.Safety; safety.Enabled {
+		if safety.YawMin > safety.YawMax || safety.PitchMin > safety.PitchMax || safety.RollMin > safety.RollMax {
+			return fmt.Errorf("pollen.safety joint limits must have min <= max")
+		}
+		if safety.MaxVelocity <= 0 || safety.MaxAcceleration <= 0 {
+			return fmt.Errorf("pollen.safety.max_velocity and max_acceleration must be positive")
+		}
+	}
+	// The limiter interpolates at rate_hz even with the safety checks off
+	if rate := c.Pollen.Safety.RateHz; rate < 1 || rate > 100 {
+		return fmt.Errorf("pollen.safety.rate_hz must be between 1 and 100, got %d", rate)
+	}
+	if pose := c.Pollen.Pose; pose.Enabled && (pose.Interval < 10*time.Millisecond || pose.StaleAfter < pose.Interval) {
+		return fmt.Errorf("pollen.pose.interval must be at least 10ms and stale_after at least the interval")
+	}
+	if breaker := c.Pollen.Breaker; breaker.Enabled && (breaker.Failures < 1 || breaker.Cooldown <= 0) {
+		return fmt.Errorf("pollen.breaker.failures must be at least 1 and cooldown positive")
+	}
+
+	if c.Behavior.AutoTrack.Enabled && c.Behavior.AutoTrack.MaxVelocity <= 0 {
+		return fmt.Errorf("behavior.autotrack.max_velocity must be positive, got %v", c.Behavior.AutoTrack.MaxVelocity)
+	}
+	if track := c.Behavior.AutoTrack; track.Enabled && track.Body.Enabled {
+		body := track.Body
+		if body.MaxYaw <= 0 || body.MaxVelocity <= 0 {
+			return fmt.Errorf("behavior.autotrack.body.max_yaw and max_velocity must be positive")
+		}
+		if body.Hysteresis < 0 || body.Threshold-body.Hysteresis <= 0 || body.Threshold+body.Hysteresis > track.MaxYaw {
+			return fmt.Errorf("behavior.autotrack.body.threshold ± hysteresis must be between 0 and behavior.autotrack.max_yaw (%v)", track.MaxYaw)
+		}
+	}
+
+	for i, p := range c.XVF3800.Params {
+		switch p.Type {
+		case "uint8", "int32", "uint32", "float", "radians":
+		default:
+			return fmt.Errorf("xvf3800.params[%d].type must be uint8, int32, uint32, float or radians, got %q", i, p.Type)
+		}
+		if p.Count <= 0 {
+			return fmt.Errorf("xvf3800.params[%d].count must be positive, got %d", i, p.Count)
+		}
+	}
+
+	profiles := make(map[string]bool, len(c.XVF3800.Profiles))
+	for i, p := range c.XVF3800.Profiles {
+		if p.Name == "" || profiles[p.Name] {
+			return fmt.Errorf("xvf3800.profiles[%d].name must be set and unique, got %q", i, p.Name)
+		}
+		profiles[p.Name] = true
+		for j, s := range p.Settings {
+			if s.Param == "" || len(s.Values) == 0 {
+				return fmt.Errorf("xvf3800.profiles[%d].settings[%d] needs a param and values", i, j)
+			}
+		}
+	}
+	if c.XVF3800.Profile != "" && !profiles[c.XVF3800.Profile] {
+		return fmt.Errorf("xvf3800.profile %q is not defined in xvf3800.profiles", c.XVF3800.Profile)
+	}
+
+	if c.History.Enabled {
+		switch c.History.Backend {
+		case "memory", "remote":
+			if c.History.MaxRows <= 0 {
+				return fmt.Errorf("history.max_rows must be positive")
+			}
+			if c.History.Backend == "remote" && !c.Cloud.Enabled {
+				return fmt.Errorf("history.backend remote requires cloud.enabled")
+			}
+		case "sqlite":
+			if c.History.Path == "" {
+				return fmt.Errorf("history.path is required when history is enabled")
+			}
+		default:
+			return fmt.Errorf("history.backend must be memory, sqlite or remote, got %q", c.History.Backend)
+		}
+	}
+
+	if c.Recorder.Enabled && c.Recorder.Dir == "" {
+		return fmt.Errorf("recorder.dir is required when recorder is enabled")
+	}
+
+	if c.Audit.Enabled && c.Audit.Dir == "" {
+		return fmt.Errorf("audit.dir is required when audit is enabled")
+	}
+	if c.Audit.MaxFileBytes <= 0 {
+		return fmt.Errorf("audit.max_file_bytes must be positive, got %d", c.Audit.MaxFileBytes)
+	}
+	if c.Audit.MaxFiles < 0 {
+		return fmt.Errorf("audit.max_files must not be negative, got %d", c.Audit.MaxFiles)
+	}
+
+	if c.Source.ProbeInterval <= 0 {
+		return fmt.Errorf("source.probe_interval must be positive, got %v", c.Source.ProbeInterval)
+	}
+
+	if c.Source.FailAfter < 1 {
+		return fmt.Errorf("source.fail_after must be at least 1, got %d", c.Source.FailAfter)
+	}
+
+	if c.Source.Backend == "" {
+		return fmt.Errorf("source.backend is required")
+	}
+
+	if (c.Source.Python.Enabled || c.Source.Backend == "python") && c.Source.Python.Command == "" {
+		return fmt.Errorf("source.python.command is required when the python source is enabled")
+	}
+
+	if h := c.Source.Health; h.Enabled {
+		if h.Interval <= 0 || h.Timeout <= 0 {
+			return fmt.Errorf("source.health.interval and timeout must be positive")
+		}
+		if h.FailAfter < 1 {
+			return fmt.Errorf("source.health.fail_after must be at least 1, got %d", h.FailAfter)
+		}
+	}
+	if c.Source.Replay.Speed < 0 {
+		return fmt.Errorf("source.replay.speed must not be negative, got %v", c.Source.Replay.Speed)
+	}
+
+	if c.Calibration.Duration <= 0 {
+		return fmt.Errorf("calibration.duration must be positive, got %v", c.Calibration.Duration)
+	}
+
+	if c.Calibration.MinSamples < 1 {
+		return fmt.Errorf("calibration.min_samples must be at least 1, got %d", c.Calibration.MinSamples)
+	}
 
 	return nil
 }
+
+// validOrigin reports whether origin is an http(s) origin without a path,
+// optionally with a leading subdomain wildcard ("https://*.example.com")
+func validOrigin(origin string) bool {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return u.Host != "" && !strings.Contains(u.Host, "*") &&
+		(u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
@@ -129,6 +129,97 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "empty api key",
+			modify: func(c *Config) {
+				c.Server.Auth.APIKeys = []string{"secret", " "}
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid cors origins",
+			modify: func(c *Config) {
+				c.Server.Auth.APIKeys = []string{"secret"}
+				c.Server.CORS.AllowOrigins = []string{"http://dashboard.local:3000", "https://*.example.com"}
+				c.Server.CORS.AllowCredentials = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "cors origin with a path",
+			modify: func(c *Config) {
+				c.Server.CORS.AllowOrigins = []string{"http://dashboard.local/app"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "noise floor alpha out of range",
+			modify: func(c *Config) {
+				c.Audio.NoiseFloor.Alpha = 1.5
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative noise floor margin",
+			modify: func(c *Config) {
+				c.Audio.NoiseFloor.Margin = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "barge-in ratio not above echo",
+			modify: func(c *Config) {
+				c.Audio.Echo.BargeIn = true
+				c.Audio.Echo.BargeInRatio = 1
+			},
+			wantErr: true,
+		},
+		{
+			name: "idle poll rate above the full rate",
+			modify: func(c *Config) {
+				c.Audio.Idle.PollHz = 50
+			},
+			wantErr: true,
+		},
+		{
+			name: "outlier filter without confirmation",
+			modify: func(c *Config) {
+				c.Audio.Outliers.Confirm = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "wake without a spike threshold",
+			modify: func(c *Config) {
+				c.Audio.Wake.Enabled = true
+				c.Audio.Wake.SpikeDB = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "tls redirect on the server port",
+			modify: func(c *Config) {
+				c.Server.TLS.Enabled = true
+				c.Server.TLS.RedirectPort = c.Server.Port
+			},
+			wantErr: true,
+		},
+		{
+			name: "tls without a key file",
+			modify: func(c *Config) {
+				c.Server.TLS.Enabled = true
+				c.Server.TLS.KeyFile = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "cors wildcard with credentials",
+			modify: func(c *Config) {
+				c.Server.CORS.AllowOrigins = []string{"*"}
+				c.Server.CORS.AllowCredentials = true
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid poll_hz too low",
 			modify: func(c *Config) {
@@ -143,6 +234,501 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "empty source backend",
+			modify: func(c *Config) {
+				c.Source.Backend = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative replay speed",
+			modify: func(c *Config) {
+				c.Source.Replay.Speed = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "mounting offset out of range",
+			modify: func(c *Config) {
+				c.Audio.Mounting.OffsetDeg = 200
+			},
+			wantErr: true,
+		},
+		{
+			name: "change threshold above 1",
+			modify: func(c *Config) {
+				c.Camera.ChangeDetection.Threshold = 1.5
+			},
+			wantErr: true,
+		},
+		{
+			name: "adaptive high delay below low delay",
+			modify: func(c *Config) {
+				c.Camera.Adaptive.HighDelay = 50 * time.Millisecond
+			},
+			wantErr: true,
+		},
+		{
+			name: "adaptive min quality zero",
+			modify: func(c *Config) {
+				c.Camera.Adaptive.MinQuality = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "archive with nothing to trigger it",
+			modify: func(c *Config) {
+				c.Camera.Archive.Enabled = true
+				c.Camera.Archive.Interval = 0
+				c.Camera.Archive.OnSpeech = false
+			},
+			wantErr: true,
+		},
+		{
+			name: "archive without camera",
+			modify: func(c *Config) {
+				c.Camera.Enabled = false
+				c.Camera.Archive.Enabled = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative state interval",
+			modify: func(c *Config) {
+				c.State.Interval = -time.Second
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero health interval",
+			modify: func(c *Config) {
+				c.Health.Interval = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "grpc on the http port",
+			modify: func(c *Config) {
+				c.GRPC.Enabled = true
+				c.GRPC.Port = c.Server.Port
+			},
+			wantErr: true,
+		},
+		{
+			name: "grpc bind not an address",
+			modify: func(c *Config) {
+				c.GRPC.Enabled = true
+				c.GRPC.Bind = "localhost:9001"
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown smoothing mode",
+			modify: func(c *Config) {
+				c.Audio.Smoothing = "lowpass"
+			},
+			wantErr: true,
+		},
+		{
+			name: "i2c transport",
+			modify: func(c *Config) {
+				c.Audio.Transport = "i2c"
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown transport",
+			modify: func(c *Config) {
+				c.Audio.Transport = "spi"
+			},
+			wantErr: true,
+		},
+		{
+			name: "i2c address out of range",
+			modify: func(c *Config) {
+				c.Audio.Transport = "i2c"
+				c.Audio.I2C.Address = 0x80
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative utterance hangover",
+			modify: func(c *Config) {
+				c.Audio.UtteranceHangoverMs = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "history enabled without path",
+			modify: func(c *Config) {
+				c.History.Enabled = true
+				c.History.Path = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "memory history without path",
+			modify: func(c *Config) {
+				c.History.Enabled = true
+				c.History.Backend = "memory"
+				c.History.Path = ""
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown history backend",
+			modify: func(c *Config) {
+				c.History.Enabled = true
+				c.History.Backend = "postgres"
+			},
+			wantErr: true,
+		},
+		{
+			name: "remote history without cloud",
+			modify: func(c *Config) {
+				c.History.Enabled = true
+				c.History.Backend = "remote"
+				c.Cloud.Enabled = false
+			},
+			wantErr: true,
+		},
+		{
+			name: "gesture rule with bad antennas",
+			modify: func(c *Config) {
+				c.Gesture.Rules = []GestureRule{{Event: "loud", Antennas: []float64{0.5}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "recorder without dir",
+			modify: func(c *Config) {
+				c.Recorder.Enabled = true
+				c.Recorder.Dir = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit without dir",
+			modify: func(c *Config) {
+				c.Audit.Enabled = true
+				c.Audit.Dir = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit max_files negative",
+			modify: func(c *Config) {
+				c.Audit.MaxFiles = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "source health fail_after zero",
+			modify: func(c *Config) {
+				c.Source.Health.FailAfter = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "source fail_after zero",
+			modify: func(c *Config) {
+				c.Source.FailAfter = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "calibration without samples",
+			modify: func(c *Config) {
+				c.Calibration.MinSamples = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "xvf3800 param bad type",
+			modify: func(c *Config) {
+				c.XVF3800.Params = []XVF3800ParamSpec{{Name: "x", Type: "double", Count: 1}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "xvf3800 profile",
+			modify: func(c *Config) {
+				c.XVF3800.Profiles = []XVF3800ProfileSpec{{Name: "quiet-room", Settings: []XVF3800SettingSpec{{Param: "GAIN", Values: []float64{2}}}}}
+				c.XVF3800.Profile = "quiet-room"
+			},
+			wantErr: false,
+		},
+		{
+			name: "xvf3800 startup profile not defined",
+			modify: func(c *Config) {
+				c.XVF3800.Profile = "noisy-cafe"
+			},
+			wantErr: true,
+		},
+		{
+			name: "xvf3800 profile setting without values",
+			modify: func(c *Config) {
+				c.XVF3800.Profiles = []XVF3800ProfileSpec{{Name: "quiet-room", Settings: []XVF3800SettingSpec{{Param: "GAIN"}}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "cloud cert without key",
+			modify: func(c *Config) {
+				c.Cloud.Auth.CertFile = "/etc/go-eva/client.pem"
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown cloud queue drop policy",
+			modify: func(c *Config) {
+				c.Cloud.Queue.DropPolicy = "random"
+			},
+			wantErr: true,
+		},
+		{
+			name: "zone without width",
+			modify: func(c *Config) {
+				c.Audio.Zones = []ZoneEntry{{Name: "front"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "unnamed tracker processor",
+			modify: func(c *Config) {
+				c.Audio.Processors = []ProcessorEntry{{Params: map[string]float64{"offset_deg": 5}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero cloud send queue",
+			modify: func(c *Config) {
+				c.Cloud.Send.QueueSize = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "cloud compression level out of range",
+			modify: func(c *Config) {
+				c.Cloud.Compression.Level = 10
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative gzip threshold",
+			modify: func(c *Config) {
+				c.Cloud.Compression.GzipThreshold = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative doa batch interval",
+			modify: func(c *Config) {
+				c.Cloud.DOABatch = -time.Second
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty cloud endpoint",
+			modify: func(c *Config) {
+				c.Cloud.URLs = []string{"wss://eu.example.com/ws/robot", ""}
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown cloud audio codec",
+			modify: func(c *Config) {
+				c.Cloud.AudioCodecs = []string{"opus", "mp3"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "animation rate too fast",
+			modify: func(c *Config) {
+				c.Animation.Enabled = true
+				c.Animation.Rate = time.Millisecond
+			},
+			wantErr: true,
+		},
+		{
+			name: "expression pose needs two antennas",
+			modify: func(c *Config) {
+				c.Expression.Enabled = true
+				c.Expression.Perk = []float64{0.5}
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown emotion overlap policy",
+			modify: func(c *Config) {
+				c.Emotion.Overlap = "interrupt"
+			},
+			wantErr: true,
+		},
+		{
+			name: "emotion library entry needs a name",
+			modify: func(c *Config) {
+				c.Emotion.Library = []EmotionEntry{{Duration: time.Second}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "playback queue must hold a clip",
+			modify: func(c *Config) {
+				c.Playback.MaxQueue = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "autotrack zero velocity",
+			modify: func(c *Config) {
+				c.Behavior.AutoTrack.Enabled = true
+				c.Behavior.AutoTrack.MaxVelocity = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "motor safety inverted yaw limits",
+			modify: func(c *Config) {
+				c.Pollen.Safety.YawMin = 30
+				c.Pollen.Safety.YawMax = -30
+			},
+			wantErr: true,
+		},
+		{
+			name: "vision without camera",
+			modify: func(c *Config) {
+				c.Vision.Enabled = true
+				c.Camera.Enabled = false
+			},
+			wantErr: true,
+		},
+		{
+			name: "camera fov out of range",
+			modify: func(c *Config) {
+				c.Camera.FOVDeg = 180
+			},
+			wantErr: true,
+		},
+		{
+			name: "pose stale_after below interval",
+			modify: func(c *Config) {
+				c.Pollen.Pose.Interval = time.Second
+				c.Pollen.Pose.StaleAfter = 100 * time.Millisecond
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown capture source",
+			modify: func(c *Config) {
+				c.Capture.Source = "pulseaudio"
+			},
+			wantErr: true,
+		},
+		{
+			name: "webrtc capture without camera",
+			modify: func(c *Config) {
+				c.Capture.Source = "webrtc"
+				c.Camera.Enabled = false
+			},
+			wantErr: true,
+		},
+		{
+			name: "webrtc capture",
+			modify: func(c *Config) {
+				c.Capture.Source = "webrtc"
+			},
+			wantErr: false,
+		},
+		{
+			name: "pollen breaker without failures",
+			modify: func(c *Config) {
+				c.Pollen.Breaker.Failures = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "pollen breaker disabled",
+			modify: func(c *Config) {
+				c.Pollen.Breaker.Enabled = false
+				c.Pollen.Breaker.Failures = 0
+			},
+			wantErr: false,
+		},
+		{
+			name: "speaker gate out of range",
+			modify: func(c *Config) {
+				c.Audio.Speakers.GateDeg = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "speaker timeout zero",
+			modify: func(c *Config) {
+				c.Audio.Speakers.Timeout = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "choreo rate too high",
+			modify: func(c *Config) {
+				c.Choreo.RateHz = 500
+			},
+			wantErr: true,
+		},
+		{
+			name: "safety rate zero with safety disabled",
+			modify: func(c *Config) {
+				c.Pollen.Safety.Enabled = false
+				c.Pollen.Safety.RateHz = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "mic window zero",
+			modify: func(c *Config) {
+				c.Audio.Mics.Window = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "mic dead ratio above low ratio",
+			modify: func(c *Config) {
+				c.Audio.Mics.DeadRatio = 0.5
+			},
+			wantErr: true,
+		},
+		{
+			name: "persist without file",
+			modify: func(c *Config) {
+				c.Audio.Persist.File = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid webhooks",
+			modify: func(c *Config) {
+				c.Webhooks.Enabled = true
+				c.Webhooks.Hooks = []WebhookSpec{{URL: "http://ha.local:8123/api/webhook/eva-{{.Type}}", Events: []string{"speech_start", "zone_change"}}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhooks enabled without hooks",
+			modify: func(c *Config) {
+				c.Webhooks.Enabled = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "webhook unknown event",
+			modify: func(c *Config) {
+				c.Webhooks.Enabled = true
+				c.Webhooks.Hooks = []WebhookSpec{{URL: "http://ha.local/hook", Events: []string{"sneeze"}}}
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
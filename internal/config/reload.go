@@ -0,0 +1,351 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
+)
+
+// hotKeys lists the config keys (or key prefixes ending in ".") that can be
+// applied to running subsystems without a restart
+var hotKeys = []string{
+	"audio.poll_hz",
+	"audio.speaking_latch_ms",
+	"audio.ema_alpha",
+	"audio.smoothing",
+	"audio.median_window",
+	"audio.utterance_hangover_ms",
+	"audio.max_utterance_ms",
+	"audio.zones",
+	"audio.zone_hysteresis_deg",
+	"audio.zone_dwell_ms",
+	"audio.zone_world",
+	"audio.noise_floor.",
+	"audio.echo.enabled",
+	"audio.echo.tail_ms",
+	"audio.echo.barge_in",
+	"audio.echo.barge_in_ratio",
+	"audio.outliers.",
+	"audio.idle.",
+	"audio.confidence.",
+	"audio.kalman.",
+	"camera.framerate",
+	"logging.level",
+}
+
+// IsHotReloadable reports whether a config key can change at runtime
+func IsHotReloadable(key string) bool {
+	for _, hot := range hotKeys {
+		if key == hot || (strings.HasSuffix(hot, ".") && strings.HasPrefix(key, hot)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Change describes a single differing config value
+type Change struct {
+	Key             string      `json:"key"`
+	Old             interface{} `json:"old"`
+	New             interface{} `json:"new"`
+	Applied         bool        `json:"applied"`
+	RestartRequired bool        `json:"restart_required"`
+}
+
+// Diff returns the leaf values that differ between two configs, keyed by
+// their dotted mapstructure path
+func Diff(oldCfg, newCfg *Config) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(*oldCfg), reflect.ValueOf(*newCfg), &changes)
+	return changes
+}
+
+func diffValue(prefix string, a, b reflect.Value, changes *[]Change) {
+	if a.Kind() == reflect.Struct && a.Type() != reflect.TypeOf(time.Time{}) {
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			key := t.Field(i).Tag.Get("mapstructure")
+			if key == "" || key == "-" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			diffValue(key, a.Field(i), b.Field(i), changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	*changes = append(*changes, Change{
+		Key:             prefix,
+		Old:             redact(prefix, a),
+		New:             redact(prefix, b),
+		RestartRequired: !IsHotReloadable(prefix),
+	})
+}
+
+// deepCopy clones slices and maps so a patched copy never aliases base
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// setPath assigns the value at a dotted mapstructure path
+func setPath(dst, src reflect.Value, key string) {
+	for _, part := range strings.Split(key, ".") {
+		t := dst.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("mapstructure") == part {
+				dst = dst.Field(i)
+				src = src.Field(i)
+				break
+			}
+		}
+	}
+	dst.Set(src)
+}
+
+// Merge decodes a partial, nested patch (as decoded from JSON or YAML) on top
+// of a copy of base. Unknown keys are rejected.
+func Merge(base *Config, patch map[string]interface{}) (*Config, error) {
+	merged := deepCopy(reflect.ValueOf(*base)).Interface().(Config)
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &merged,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create decoder: %w", err)
+	}
+	if err := decoder.Decode(patch); err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+
+	return &merged, nil
+}
+
+// Watcher owns the live configuration and applies hot-reloadable changes to
+// subscribers when the config file changes, on SIGHUP, or via Apply
+type Watcher struct {
+	path   string
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []func(oldCfg, newCfg *Config)
+	reloads     int
+	lastError   string
+}
+
+// NewWatcher creates a watcher for the config file at path
+func NewWatcher(path string, initial *Config, logger *slog.Logger) *Watcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Watcher{
+		path:    path,
+		logger:  logger,
+		current: initial,
+	}
+}
+
+// Subscribe registers a callback invoked after hot fields change
+func (w *Watcher) Subscribe(fn func(oldCfg, newCfg *Config)) {
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, fn)
+	w.mu.Unlock()
+}
+
+// Current returns the live configuration
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Apply validates next, applies its hot-reloadable fields and reports every
+// difference. Fields that need a restart are reported but not applied.
+func (w *Watcher) Apply(next *Config) ([]Change, error) {
+	if err := next.Validate(); err != nil {
+		w.mu.Lock()
+		w.lastError = err.Error()
+		w.mu.Unlock()
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	w.mu.Lock()
+	oldCfg := w.current
+	changes := Diff(oldCfg, next)
+
+	applied := *oldCfg
+	hot := false
+	for i := range changes {
+		if changes[i].RestartRequired {
+			continue
+		}
+		setPath(reflect.ValueOf(&applied).Elem(), reflect.ValueOf(next).Elem(), changes[i].Key)
+		changes[i].Applied = true
+		hot = true
+	}
+
+	if hot {
+		w.current = &applied
+		w.reloads++
+	}
+	w.lastError = ""
+	subscribers := append([]func(oldCfg, newCfg *Config){}, w.subscribers...)
+	w.mu.Unlock()
+
+	for _, c := range changes {
+		w.logger.Info("config change",
+			"key", c.Key,
+			"applied", c.Applied,
+			"restart_required", c.RestartRequired,
+		)
+	}
+
+	if hot {
+		for _, fn := range subscribers {
+			fn(oldCfg, &applied)
+		}
+	}
+
+	return changes, nil
+}
+
+// Reload re-reads the config file and applies it
+func (w *Watcher) Reload() ([]Change, error) {
+	next, err := Load(w.path)
+	if err != nil {
+		w.mu.Lock()
+		w.lastError = err.Error()
+		w.mu.Unlock()
+		return nil, err
+	}
+	return w.Apply(next)
+}
+
+// Watch reloads on SIGHUP and when the config file is written (blocking)
+func (w *Watcher) Watch(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Watch the directory so editors that replace the file are still seen
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if w.path != "" {
+		if err := fsw.Add(filepath.Dir(w.path)); err != nil {
+			w.logger.Warn("config file watch unavailable, SIGHUP only", "path", w.path, "error", err)
+		} else {
+			events = fsw.Events
+			errs = fsw.Errors
+		}
+	}
+
+	// Debounce bursts of write events from a single save
+	var debounce <-chan time.Time
+	name := filepath.Clean(w.path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-hup:
+			w.logger.Info("SIGHUP received, reloading config", "path", w.path)
+			w.reload()
+
+		case ev := <-events:
+			if filepath.Clean(ev.Name) != name {
+				continue
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(200 * time.Millisecond)
+			}
+
+		case <-debounce:
+			debounce = nil
+			w.logger.Info("config file changed, reloading", "path", w.path)
+			w.reload()
+
+		case err := <-errs:
+			w.logger.Warn("config watch error", "error", err)
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	if _, err := w.Reload(); err != nil {
+		w.logger.Warn("config reload rejected", "error", err)
+	}
+}
+
+// WatcherStats contains reload statistics
+type WatcherStats struct {
+	Reloads   int    `json:"reloads"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// GetStats returns reload statistics
+func (w *Watcher) GetStats() WatcherStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WatcherStats{
+		Reloads:   w.reloads,
+		LastError: w.lastError,
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsHotReloadable(t *testing.T) {
+	tests := map[string]bool{
+		"audio.ema_alpha":            true,
+		"audio.kalman.process_noise": true,
+		"camera.framerate":           true,
+		"logging.level":              true,
+		"audio.history_size":         false,
+		"logging.format":             false,
+		"server.port":                false,
+	}
+	for key, want := range tests {
+		if got := IsHotReloadable(key); got != want {
+			t.Errorf("IsHotReloadable(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := Default()
+	b := Default()
+	b.Audio.EMAAlpha = 0.6
+	b.Server.ReadTimeout = 3 * time.Second
+	b.Cloud.Auth.Token = "hunter2"
+
+	changes := Diff(a, b)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3: %+v", len(changes), changes)
+	}
+
+	byKey := make(map[string]Change)
+	for _, c := range changes {
+		byKey[c.Key] = c
+	}
+	if c := byKey["audio.ema_alpha"]; c.New != 0.6 || c.RestartRequired {
+		t.Errorf("audio.ema_alpha change = %+v", c)
+	}
+	if c := byKey["server.read_timeout"]; c.New != "3s" || !c.RestartRequired {
+		t.Errorf("server.read_timeout change = %+v", c)
+	}
+	if c := byKey["cloud.auth.token"]; c.New != "***" {
+		t.Errorf("token not redacted: %+v", c)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Default()
+	base.Gesture.Rules = []GestureRule{{Event: "speech_start", Emotion: "nod"}}
+
+	merged, err := Merge(base, map[string]interface{}{
+		"audio":   map[string]interface{}{"poll_hz": 40.0},
+		"server":  map[string]interface{}{"read_timeout": "2s"},
+		"gesture": map[string]interface{}{"rules": []interface{}{map[string]interface{}{"event": "loud", "emotion": "shake"}}},
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if merged.Audio.PollHz != 40 || merged.Server.ReadTimeout != 2*time.Second {
+		t.Errorf("patch not applied: poll_hz=%d read_timeout=%v", merged.Audio.PollHz, merged.Server.ReadTimeout)
+	}
+	if merged.Audio.EMAAlpha != base.Audio.EMAAlpha {
+		t.Error("untouched field changed")
+	}
+	if base.Audio.PollHz != 20 || base.Gesture.Rules[0].Emotion != "nod" {
+		t.Error("Merge modified base")
+	}
+
+	if _, err := Merge(base, map[string]interface{}{"audio": map[string]interface{}{"nope": 1}}); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestWatcher_Apply(t *testing.T) {
+	w := NewWatcher("", Default(), nil)
+
+	var calls int
+	w.Subscribe(func(oldCfg, newCfg *Config) {
+		calls++
+		if oldCfg.Logging.Level != "info" || newCfg.Logging.Level != "debug" {
+			t.Errorf("subscriber got %s → %s", oldCfg.Logging.Level, newCfg.Logging.Level)
+		}
+	})
+
+	next := Default()
+	next.Logging.Level = "debug"
+	next.Server.Port = 9100
+	changes, err := w.Apply(next)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(changes) != 2 || calls != 1 {
+		t.Fatalf("changes=%+v calls=%d", changes, calls)
+	}
+	if w.Current().Logging.Level != "debug" || w.Current().Server.Port != 9000 {
+		t.Errorf("current = level %s port %d, want only the hot field applied",
+			w.Current().Logging.Level, w.Current().Server.Port)
+	}
+
+	// Cold-only changes don't notify subscribers
+	if _, err := w.Apply(next); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("subscriber called %d times, want 1", calls)
+	}
+
+	bad := Default()
+	bad.Audio.PollHz = 0
+	if _, err := w.Apply(bad); err == nil {
+		t.Error("expected validation error")
+	}
+	if w.GetStats().LastError == "" {
+		t.Error("expected LastError to be set")
+	}
+}
+
+func TestWatcher_FileChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("audio:\n  ema_alpha: 0.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	initial, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWatcher(path, initial, nil)
+
+	got := make(chan float64, 1)
+	w.Subscribe(func(_, newCfg *Config) { got <- newCfg.Audio.EMAAlpha })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Watch(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("audio:\n  ema_alpha: 0.7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case alpha := <-got:
+		if alpha != 0.7 {
+			t.Errorf("ema_alpha = %v, want 0.7", alpha)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// SecretsConfig configures decryption of encrypted config values
+type SecretsConfig struct {
+	AgeIdentity string `mapstructure:"age_identity"` // age identity file for values in age armor
+}
+
+// ageHeader starts an ASCII-armored age ciphertext
+const ageHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// envRef matches ${VAR} and ${VAR:-default}
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} references with the environment variable, or the
+// default after :- when it is unset or empty. A reference to an unset
+// variable without a default is an error, so a missing secret fails startup
+// rather than sending an empty credential.
+func expandEnv(s string) (string, error) {
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		if v := os.Getenv(m[1]); v != "" {
+			return v
+		}
+		if m[2] == "" {
+			missing = append(missing, m[1])
+		}
+		return m[3]
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// decryptAge decrypts an armored age value with the identity file
+func decryptAge(value, identity string) (string, error) {
+	cmd := exec.Command("age", "--decrypt", "-i", identity)
+	cmd.Stdin = strings.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("age decrypt with %s: %w: %s", identity, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// readSops replaces the config read from a sops-encrypted file with its
+// decrypted contents
+func readSops(v *viper.Viper, path string) error {
+	cmd := exec.Command("sops", "--decrypt", "--input-type", "yaml", "--output-type", "yaml", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("sops decrypt %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return v.ReadConfig(bytes.NewReader(out))
+}
+
+// secretHook expands ${VAR} references in every string value and decrypts
+// age-armored values, wherever they sit in the config
+func secretHook(identity string) mapstructure.DecodeHookFuncType {
+	return func(from, _ reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String {
+			return data, nil
+		}
+		s, err := expandEnv(data.(string))
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.TrimSpace(s), ageHeader) {
+			return decryptAge(s, identity)
+		}
+		return s, nil
+	}
+}
+
+// resolveSecretFiles reads each <secret>_file key into its secret, so
+// cloud.auth.token_file: /run/secrets/cloud_token sets cloud.auth.token.
+// api_keys_file holds one key per line. Keys inside lists are not resolved.
+func resolveSecretFiles(v *viper.Viper) error {
+	for _, key := range v.AllKeys() {
+		target, ok := strings.CutSuffix(key, "_file")
+		if !ok || !isSecret(target) {
+			continue
+		}
+		path, err := expandEnv(v.GetString(key))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if path == "" {
+			continue
+		}
+		if v.GetString(target) != "" || len(v.GetStringSlice(target)) > 0 {
+			return fmt.Errorf("set %s or %s, not both", target, key)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if strings.HasSuffix(target, "api_keys") {
+			v.Set(target, strings.Fields(string(data)))
+		} else {
+			v.Set(target, strings.TrimSpace(string(data)))
+		}
+	}
+	return nil
+}
+
+// isSecret reports whether a config key (or header name) holds a credential
+func isSecret(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	for _, suffix := range []string{"token", "secret", "password", "api_key", "api_keys", "authorization"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns cfg keyed by its config names with every credential
+// masked, for display
+func Redact(cfg *Config) map[string]interface{} {
+	return redact("", reflect.ValueOf(*cfg)).(map[string]interface{})
+}
+
+// redact renders v for display: durations as strings and credentials masked,
+// including those nested in lists and maps
+func redact(key string, v reflect.Value) interface{} {
+	if key != "" && isSecret(key) {
+		if v.IsZero() {
+			return ""
+		}
+		return "***"
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v.Interface()
+		}
+		out := make(map[string]interface{}, v.NumField())
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Tag.Get("mapstructure")
+			if name == "" || name == "-" {
+				continue
+			}
+			out[name] = redact(joinKey(key, name), v.Field(i))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.Struct && v.Type().Elem().Kind() != reflect.Map {
+			return v.Interface()
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redact(key, v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = redact(joinKey(key, iter.Key().String()), iter.Value())
+		}
+		return out
+	}
+
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return v.Interface()
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
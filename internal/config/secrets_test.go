@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoad_Secrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	tokenPath := filepath.Join(tmpDir, "cloud_token")
+	keysPath := filepath.Join(tmpDir, "api_keys")
+	os.WriteFile(tokenPath, []byte("cloud-secret\n"), 0600)
+	os.WriteFile(keysPath, []byte("key-one\nkey-two\n"), 0600)
+
+	t.Setenv("EVA_INFLUX_TOKEN", "influx-secret")
+	t.Setenv("EVA_SECRETS", tmpDir)
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	os.WriteFile(configPath, []byte(`
+server:
+  auth:
+    api_keys_file: ${EVA_SECRETS}/api_keys
+cloud:
+  url: ws://${EVA_CLOUD_HOST:-cloud.local}:8080/ws
+  auth:
+    token_file: `+tokenPath+`
+influx:
+  token: ${EVA_INFLUX_TOKEN}
+`), 0644)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cloud.Auth.Token != "cloud-secret" {
+		t.Errorf("cloud.auth.token = %q, want the token file's contents", cfg.Cloud.Auth.Token)
+	}
+	if !slices.Equal(cfg.Server.Auth.APIKeys, []string{"key-one", "key-two"}) {
+		t.Errorf("server.auth.api_keys = %v, want one key per line", cfg.Server.Auth.APIKeys)
+	}
+	if cfg.Influx.Token != "influx-secret" {
+		t.Errorf("influx.token = %q, want the environment variable", cfg.Influx.Token)
+	}
+	if cfg.Cloud.URL != "ws://cloud.local:8080/ws" {
+		t.Errorf("cloud.url = %q, want the default host", cfg.Cloud.URL)
+	}
+
+	// A secret set both ways is ambiguous
+	os.WriteFile(configPath, []byte("cloud:\n  auth:\n    token: inline\n    token_file: "+tokenPath+"\n"), 0644)
+	if _, err := Load(configPath); err == nil {
+		t.Error("token and token_file together should fail")
+	}
+
+	// So is a reference to an unset variable
+	os.WriteFile(configPath, []byte("influx:\n  token: ${EVA_UNSET_TOKEN}\n"), 0644)
+	if _, err := Load(configPath); err == nil {
+		t.Error("unset variable without a default should fail")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	cfg := Default()
+	cfg.Cloud.Auth.Token = "cloud-secret"
+	cfg.Server.Auth.APIKeys = []string{"key-one"}
+	cfg.Webhooks.Hooks = []WebhookSpec{{
+		URL:     "http://ha.local/hook",
+		Headers: map[string]string{"Authorization": "Bearer hook-secret", "X-API-Key": "hook-key", "Accept": "application/json"},
+	}}
+
+	out := Redact(cfg)
+	auth := out["cloud"].(map[string]interface{})["auth"].(map[string]interface{})
+	if auth["token"] != "***" || auth["hmac_secret"] != "" {
+		t.Errorf("cloud.auth = %v, want token masked and empty secret empty", auth)
+	}
+	if keys := out["server"].(map[string]interface{})["auth"].(map[string]interface{})["api_keys"]; keys != "***" {
+		t.Errorf("server.auth.api_keys = %v, want masked", keys)
+	}
+	hook := out["webhooks"].(map[string]interface{})["hooks"].([]interface{})[0].(map[string]interface{})
+	headers := hook["headers"].(map[string]interface{})
+	if headers["Authorization"] != "***" || headers["X-API-Key"] != "***" || headers["Accept"] != "application/json" {
+		t.Errorf("webhook headers = %v, want credentials masked", headers)
+	}
+	if out["server"].(map[string]interface{})["read_timeout"] != "10s" {
+		t.Errorf("durations should render as strings")
+	}
+}
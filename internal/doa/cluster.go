@@ -0,0 +1,128 @@
+package doa
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// angleCluster is a spot that sound keeps coming from
+type angleCluster struct {
+	id        int
+	angle     float64 // Radians
+	hits      int     // Angles matched to it
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// angleClusters groups angles by where they come from: an angle belongs to
+// the nearest cluster within gate, and clusters not seen for timeout are
+// forgotten. Source tracks and speakers are both angleClusters, tuned
+// differently. The caller serialises access.
+type angleClusters struct {
+	gate    float64       // Max distance from a cluster for an angle to match it (radians)
+	alpha   float64       // How far each blended angle moves its cluster (0-1)
+	timeout time.Duration // Clusters not seen for this long are forgotten
+	max     int           // The least recently seen cluster is evicted beyond this
+
+	clusters []*angleCluster
+	nextID   int
+}
+
+// newAngleClusters creates an empty set; tune it before use
+func newAngleClusters() *angleClusters {
+	return &angleClusters{nextID: 1}
+}
+
+// tune changes matching and expiry; clusters are kept
+func (c *angleClusters) tune(gate, alpha float64, timeout time.Duration, size int) {
+	c.gate, c.alpha, c.timeout, c.max = gate, alpha, timeout, size
+}
+
+// nearest returns the closest cluster within the gate of angle, or nil
+func (c *angleClusters) nearest(angle float64) *angleCluster {
+	var best *angleCluster
+	bestDist := c.gate
+	for _, cl := range c.clusters {
+		if d := math.Abs(NormalizeAngle(angle - cl.angle)); d <= bestDist {
+			best, bestDist = cl, d
+		}
+	}
+	return best
+}
+
+// find returns the cluster with id, or nil
+func (c *angleClusters) find(id int) *angleCluster {
+	for _, cl := range c.clusters {
+		if cl.id == id {
+			return cl
+		}
+	}
+	return nil
+}
+
+// blend moves a cluster toward angle along the shorter arc
+func (c *angleClusters) blend(cl *angleCluster, angle float64) {
+	cl.angle = NormalizeAngle(cl.angle + c.alpha*NormalizeAngle(angle-cl.angle))
+}
+
+// add starts a cluster at angle, evicting the least recently seen one when
+// full
+func (c *angleClusters) add(angle float64, now time.Time) *angleCluster {
+	if len(c.clusters) >= c.max {
+		stalest := 0
+		for i, cl := range c.clusters {
+			if cl.lastSeen.Before(c.clusters[stalest].lastSeen) {
+				stalest = i
+			}
+		}
+		c.clusters = append(c.clusters[:stalest], c.clusters[stalest+1:]...)
+	}
+
+	cl := &angleCluster{id: c.nextID, angle: angle, firstSeen: now}
+	c.nextID++
+	c.clusters = append(c.clusters, cl)
+	return cl
+}
+
+// prune forgets clusters not seen within the timeout
+func (c *angleClusters) prune(now time.Time) {
+	kept := c.clusters[:0]
+	for _, cl := range c.clusters {
+		if now.Sub(cl.lastSeen) < c.timeout {
+			kept = append(kept, cl)
+		}
+	}
+	c.clusters = kept
+}
+
+// live returns the clusters seen within the timeout, most recently seen
+// first
+func (c *angleClusters) live(now time.Time) []*angleCluster {
+	live := make([]*angleCluster, 0, len(c.clusters))
+	for _, cl := range c.clusters {
+		if now.Sub(cl.lastSeen) < c.timeout {
+			live = append(live, cl)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].lastSeen.After(live[j].lastSeen)
+	})
+	return live
+}
+
+// reset forgets every cluster; IDs keep counting
+func (c *angleClusters) reset() {
+	c.clusters = c.clusters[:0]
+}
+
+// restore adds a saved cluster, keeping its ID, unless it has expired or
+// there is no room
+func (c *angleClusters) restore(cl angleCluster, now time.Time) bool {
+	if now.Sub(cl.lastSeen) >= c.timeout || len(c.clusters) >= c.max {
+		return false
+	}
+	c.clusters = append(c.clusters, &cl)
+	c.nextID = max(c.nextID, cl.id+1)
+	return true
+}
@@ -0,0 +1,45 @@
+package doa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAngleClusters_NearestAcrossWrap(t *testing.T) {
+	c := newAngleClusters()
+	c.tune(deg(20), 0.5, time.Minute, 4)
+	now := time.Now()
+
+	behind := c.add(deg(175), now)
+	c.add(deg(90), now)
+
+	if cl := c.nearest(deg(-170)); cl != behind {
+		t.Fatalf("nearest(-170°) = %+v, want the cluster at 175°", cl)
+	}
+	c.blend(behind, deg(-175))
+	if a := behind.angle; a < deg(179) && a > deg(-179) {
+		t.Errorf("blend across ±180° moved the cluster to %.1f°, want 180°", a/deg(1))
+	}
+	if cl := c.nearest(deg(0)); cl != nil {
+		t.Errorf("nearest(0°) = %+v, want none within the gate", cl)
+	}
+}
+
+func TestAngleClusters_RestoreKeepsIDs(t *testing.T) {
+	c := newAngleClusters()
+	c.tune(deg(20), 0.3, time.Minute, 2)
+	now := time.Now()
+
+	if !c.restore(angleCluster{id: 7, angle: deg(10), lastSeen: now}, now) {
+		t.Fatal("fresh cluster was not restored")
+	}
+	if c.restore(angleCluster{id: 8, angle: deg(50), lastSeen: now.Add(-2 * time.Minute)}, now) {
+		t.Error("expired cluster was restored")
+	}
+	if cl := c.add(deg(90), now); cl.id != 8 {
+		t.Errorf("new cluster after restoring ID 7 got ID %d, want 8", cl.id)
+	}
+	if c.restore(angleCluster{id: 9, angle: deg(-90), lastSeen: now}, now) {
+		t.Error("restored beyond the cluster limit")
+	}
+}
@@ -0,0 +1,142 @@
+package doa
+
+import (
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// EchoConfig configures speech handling while the robot's own speaker is
+// playing. The XVF3800 hears the speaker as speech, so without this the
+// head would turn toward its own voice.
+type EchoConfig struct {
+	Enabled      bool
+	Tail         time.Duration // Echo handling continues this long after playback stops (room reverb)
+	BargeIn      bool          // Let through speech far louder than the echo
+	BargeInRatio float64       // Energy over the learned echo level that counts as barge-in
+}
+
+// DefaultEchoConfig returns sensible defaults
+func DefaultEchoConfig() EchoConfig {
+	return EchoConfig{
+		Enabled:      true,
+		Tail:         300 * time.Millisecond,
+		BargeInRatio: 4,
+	}
+}
+
+// echoAlpha is the adaptation rate of the echo level per reading
+const echoAlpha = 0.1
+
+// minEchoSamples is how many playback readings set the echo level before
+// barge-in can be detected
+const minEchoSamples = 3
+
+// BargeInEvent marks someone talking over the robot's own playback
+type BargeInEvent struct {
+	Angle     float64   `json:"angle"`      // Raw DOA angle (radians)
+	Energy    float64   `json:"energy"`     // Total speech energy of the reading
+	EchoLevel float64   `json:"echo_level"` // Learned energy of the speaker's echo
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TopicBargeIn carries barge-in detections on the event bus
+var TopicBargeIn = bus.NewTopic[BargeInEvent]("doa.barge_in")
+
+// EchoStats describes echo handling
+type EchoStats struct {
+	Enabled    bool    `json:"enabled"`
+	Playback   bool    `json:"playback"`   // Speaker is playing
+	Active     bool    `json:"active"`     // Playing or within the tail
+	EchoLevel  float64 `json:"echo_level"` // Learned echo energy
+	Suppressed uint64  `json:"suppressed"` // Speech flags dropped as echo
+	BargeIns   uint64  `json:"barge_ins"`
+}
+
+// EchoGate suppresses the speech flag during playback and detects barge-in
+type EchoGate struct {
+	cfg EchoConfig
+
+	playing    bool
+	stoppedAt  time.Time
+	level      float64
+	samples    int
+	inBargeIn  bool
+	suppressed uint64
+	bargeIns   uint64
+}
+
+// NewEchoGate creates an echo gate
+func NewEchoGate(cfg EchoConfig) *EchoGate {
+	return &EchoGate{cfg: cfg}
+}
+
+// SetConfig changes the tuning
+func (g *EchoGate) SetConfig(cfg EchoConfig) {
+	g.cfg = cfg
+}
+
+// SetPlayback records the speaker starting or stopping at now. The echo
+// level is relearned for every playback, since volume and content change.
+func (g *EchoGate) SetPlayback(active bool, now time.Time) {
+	if active && !g.playing {
+		g.level, g.samples = 0, 0
+	}
+	if !active && g.playing {
+		g.stoppedAt = now
+	}
+	g.playing = active
+}
+
+// Active reports whether readings at now may contain the speaker's echo
+func (g *EchoGate) Active(now time.Time) bool {
+	if !g.cfg.Enabled {
+		return false
+	}
+	return g.playing || (!g.stoppedAt.IsZero() && now.Sub(g.stoppedAt) < g.cfg.Tail)
+}
+
+// Update gates a reading taken while Active. It returns whether the reading
+// is speech, and whether it starts a barge-in.
+func (g *EchoGate) Update(r Reading) (speaking, bargeIn bool) {
+	if g.cfg.BargeIn && r.Speaking && g.samples >= minEchoSamples &&
+		r.TotalEnergy > g.level*g.cfg.BargeInRatio {
+		bargeIn = !g.inBargeIn
+		if bargeIn {
+			g.bargeIns++
+		}
+		g.inBargeIn = true
+		return true, bargeIn
+	}
+	g.inBargeIn = false
+
+	if r.TotalEnergy > 0 {
+		g.samples++
+		if g.samples == 1 {
+			g.level = r.TotalEnergy
+		} else {
+			g.level += echoAlpha * (r.TotalEnergy - g.level)
+		}
+	}
+	if r.Speaking {
+		g.suppressed++
+	}
+	return false, false
+}
+
+// Level returns the learned echo energy of the current playback
+func (g *EchoGate) Level() float64 {
+	return g.level
+}
+
+// Stats returns echo handling statistics at now
+func (g *EchoGate) Stats(now time.Time) EchoStats {
+	return EchoStats{
+		Enabled:    g.cfg.Enabled,
+		Playback:   g.playing,
+		Active:     g.Active(now),
+		EchoLevel:  g.Level(),
+		Suppressed: g.suppressed,
+		BargeIns:   g.bargeIns,
+	}
+}
@@ -0,0 +1,93 @@
+package doa
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEchoGate(t *testing.T) {
+	g := NewEchoGate(EchoConfig{Enabled: true, Tail: 300 * time.Millisecond, BargeIn: true, BargeInRatio: 4})
+	t0 := time.Now()
+
+	if g.Active(t0) {
+		t.Fatal("gate active before playback")
+	}
+	g.SetPlayback(true, t0)
+	if !g.Active(t0) {
+		t.Fatal("gate inactive during playback")
+	}
+
+	// The speaker's own voice is suppressed and sets the echo level
+	for range 5 {
+		if speaking, _ := g.Update(Reading{Speaking: true, TotalEnergy: 10}); speaking {
+			t.Fatal("echo should be suppressed")
+		}
+	}
+	if level := g.Level(); level != 10 {
+		t.Errorf("echo level = %v, want 10", level)
+	}
+
+	// Someone much louder barges in once, however long they talk
+	if speaking, bargeIn := g.Update(Reading{Speaking: true, TotalEnergy: 100}); !speaking || !bargeIn {
+		t.Errorf("loud speech = %v, %v; want speech and barge-in", speaking, bargeIn)
+	}
+	if speaking, bargeIn := g.Update(Reading{Speaking: true, TotalEnergy: 90}); !speaking || bargeIn {
+		t.Errorf("continued barge-in = %v, %v; want speech only", speaking, bargeIn)
+	}
+
+	// Suppression outlasts playback by the tail
+	g.SetPlayback(false, t0.Add(time.Second))
+	if !g.Active(t0.Add(time.Second + 200*time.Millisecond)) {
+		t.Error("gate should stay active during the tail")
+	}
+	if g.Active(t0.Add(time.Second + 400*time.Millisecond)) {
+		t.Error("gate should end after the tail")
+	}
+
+	stats := g.Stats(t0)
+	if stats.Suppressed != 5 || stats.BargeIns != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestEchoGate_NoBargeIn(t *testing.T) {
+	g := NewEchoGate(EchoConfig{Enabled: true, BargeInRatio: 4})
+	g.SetPlayback(true, time.Now())
+	for range 5 {
+		g.Update(Reading{TotalEnergy: 10})
+	}
+	if speaking, _ := g.Update(Reading{Speaking: true, TotalEnergy: 1000}); speaking {
+		t.Error("speech during playback should be suppressed without barge-in")
+	}
+}
+
+func TestTracker_SetPlayback(t *testing.T) {
+	source := NewMockSource()
+	source.SetSpeaking(true)
+
+	cfg := DefaultTrackerConfig()
+	cfg.SpeakingLatchDur = 0
+	tracker := NewTracker(source, cfg, nil)
+	ctx := context.Background()
+
+	tracker.SetPlayback(true)
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r := tracker.GetLatest(); r.Speaking || r.SpeakingLatched || !r.RawSpeaking || !r.EchoActive {
+		t.Errorf("during playback = %+v, want suppressed speech", r)
+	}
+
+	tracker.SetPlayback(false)
+	time.Sleep(cfg.Echo.Tail + 10*time.Millisecond)
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r := tracker.GetLatest(); !r.Speaking || r.EchoActive {
+		t.Errorf("after playback = %+v, want speech", r)
+	}
+	if stats := tracker.Stats(); stats.Echo.Suppressed != 1 {
+		t.Errorf("suppressed = %d, want 1", stats.Echo.Suppressed)
+	}
+}
@@ -0,0 +1,62 @@
+package doa
+
+import (
+	"math"
+	"time"
+)
+
+// HistoryPoint summarizes the tracker results in one resolution bucket
+type HistoryPoint struct {
+	Time       time.Time `json:"time"`       // Bucket start
+	Angle      float64   `json:"angle"`      // Circular mean of the smoothed angle (radians)
+	Confidence float64   `json:"confidence"` // Mean confidence
+	Energy     float64   `json:"energy"`     // Mean total speech energy
+	Speaking   float64   `json:"speaking"`   // Fraction of readings with speech
+	Samples    int       `json:"samples"`
+}
+
+// Downsample averages results (oldest first) into buckets of resolution
+// aligned to start
+func Downsample(results []Result, start time.Time, resolution time.Duration) []HistoryPoint {
+	var points []HistoryPoint
+	var sin, cos, conf, energy float64
+	var speaking, n int
+
+	flush := func(at time.Time) {
+		if n == 0 {
+			return
+		}
+		points = append(points, HistoryPoint{
+			Time:       at,
+			Angle:      math.Atan2(sin, cos),
+			Confidence: conf / float64(n),
+			Energy:     energy / float64(n),
+			Speaking:   float64(speaking) / float64(n),
+			Samples:    n,
+		})
+		sin, cos, conf, energy, speaking, n = 0, 0, 0, 0, 0, 0
+	}
+
+	var bucket time.Time
+	for _, res := range results {
+		at := res.Timestamp
+		if resolution > 0 {
+			at = start.Add(res.Timestamp.Sub(start).Truncate(resolution))
+		}
+		if n > 0 && !at.Equal(bucket) {
+			flush(bucket)
+		}
+		bucket = at
+
+		sin += math.Sin(res.SmoothedAngle)
+		cos += math.Cos(res.SmoothedAngle)
+		conf += res.Confidence
+		energy += res.TotalEnergy
+		if res.Speaking {
+			speaking++
+		}
+		n++
+	}
+	flush(bucket)
+	return points
+}
@@ -0,0 +1,43 @@
+package doa
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	var results []Result
+	for i := range 10 {
+		results = append(results, Result{
+			Reading: Reading{
+				Timestamp:   start.Add(time.Duration(i) * 50 * time.Millisecond),
+				Speaking:    i%2 == 0,
+				TotalEnergy: float64(i),
+			},
+			// Straddles ±π: the mean must stay at π, not wrap to 0
+			SmoothedAngle: math.Pi - 0.1 + 0.2*float64(i%2),
+			Confidence:    0.5,
+		})
+	}
+
+	points := Downsample(results, start, 100*time.Millisecond)
+	if len(points) != 5 {
+		t.Fatalf("points = %d, want 5", len(points))
+	}
+	p := points[1]
+	if !p.Time.Equal(start.Add(100*time.Millisecond)) || p.Samples != 2 {
+		t.Errorf("bucket = %+v", p)
+	}
+	if math.Abs(math.Abs(p.Angle)-math.Pi) > 1e-9 {
+		t.Errorf("circular mean = %v, want ±π", p.Angle)
+	}
+	if p.Energy != 2.5 || p.Speaking != 0.5 || p.Confidence != 0.5 {
+		t.Errorf("averages = %+v", p)
+	}
+
+	if raw := Downsample(results, start, 0); len(raw) != 10 {
+		t.Errorf("resolution 0 gave %d points, want 10", len(raw))
+	}
+}
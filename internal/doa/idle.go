@@ -0,0 +1,41 @@
+package doa
+
+import "time"
+
+// IdlePollConfig slows polling after prolonged silence. Polling the XVF3800
+// at full rate through an empty night costs CPU and USB bandwidth for
+// nothing; the first speech heard at the idle rate switches straight back
+// to PollInterval.
+type IdlePollConfig struct {
+	Enabled  bool
+	After    time.Duration // Silence before slowing down
+	Interval time.Duration // Poll interval while idle
+}
+
+// DefaultIdlePollConfig returns sensible defaults
+func DefaultIdlePollConfig() IdlePollConfig {
+	return IdlePollConfig{
+		Enabled:  false,
+		After:    30 * time.Second,
+		Interval: 500 * time.Millisecond, // 2Hz
+	}
+}
+
+// pollIntervalLocked returns the interval until the next poll: the idle
+// interval once nothing has spoken for IdlePoll.After, otherwise
+// PollInterval (caller holds mu)
+func (t *Tracker) pollIntervalLocked(now time.Time) time.Duration {
+	idle := t.cfg.IdlePoll
+	t.idle = idle.Enabled && idle.Interval > t.cfg.PollInterval && now.Sub(t.lastSpeech) >= idle.After
+	if t.idle {
+		return idle.Interval
+	}
+	return t.cfg.PollInterval
+}
+
+// Idle reports whether the tracker is polling at the idle rate
+func (t *Tracker) Idle() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.idle
+}
@@ -0,0 +1,50 @@
+package doa
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTracker_IdlePoll(t *testing.T) {
+	source := NewMockSource()
+	cfg := DefaultTrackerConfig()
+	cfg.PollInterval = 5 * time.Millisecond
+	cfg.IdlePoll = IdlePollConfig{Enabled: true, After: 50 * time.Millisecond, Interval: 100 * time.Millisecond}
+	tracker := NewTracker(source, cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	waitFor := func(idle bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for tracker.Idle() != idle {
+			if time.Now().After(deadline) {
+				t.Fatalf("Idle() stayed %v", !idle)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Silence slows polling down
+	waitFor(true)
+	calls := source.GetCalls()
+	time.Sleep(250 * time.Millisecond)
+	if n := source.GetCalls() - calls; n > 4 {
+		t.Errorf("%d polls in 250ms while idle, want about 2", n)
+	}
+
+	// The first speech heard switches straight back
+	source.SetSpeaking(true)
+	waitFor(false)
+	calls = source.GetCalls()
+	time.Sleep(50 * time.Millisecond)
+	if n := source.GetCalls() - calls; n < 5 {
+		t.Errorf("%d polls in 50ms after speech, want about 10", n)
+	}
+	if tracker.Stats().Idle {
+		t.Error("Stats().Idle set while speaking")
+	}
+}
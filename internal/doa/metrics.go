@@ -0,0 +1,54 @@
+package doa
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers tracker collectors
+func (t *Tracker) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_doa_angle_radians",
+			Help: "Current DOA angle in radians",
+		}, func() float64 { return t.Stats().CurrentAngle }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_speaking",
+			Help: "Speaking state (1=speaking, 0=silent)",
+		}, func() float64 { return boolToFloat(t.Stats().SpeakingLatched) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_doa_confidence",
+			Help: "DOA confidence score",
+		}, func() float64 { return t.Stats().CurrentConfidence }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_poll_count",
+			Help: "Total DOA polls",
+		}, func() float64 { return float64(t.Stats().PollCount) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_poll_errors",
+			Help: "Total DOA poll errors",
+		}, func() float64 { return float64(t.Stats().ErrorCount) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_avg_latency_ms",
+			Help: "Average poll latency in milliseconds",
+		}, func() float64 { return t.Stats().AvgLatencyMs }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_source_healthy",
+			Help: "DOA source health (1=healthy, 0=unhealthy)",
+		}, func() float64 { return boolToFloat(t.Stats().SourceHealthy) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_doa_idle",
+			Help: "Polling at the idle rate after prolonged silence (1=idle, 0=full rate)",
+		}, func() float64 { return boolToFloat(t.Idle()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_doa_sources",
+			Help: "Active per-speaker tracks",
+		}, func() float64 { return float64(len(t.GetSources())) }),
+		t.pollLatency,
+	)
+}
+
+// boolToFloat converts a flag to 1 or 0 for gauges
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
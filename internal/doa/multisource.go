@@ -0,0 +1,163 @@
+package doa
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// MultiSourceConfig configures multi-speaker tracking
+type MultiSourceConfig struct {
+	GateAngle  float64       // Max angular distance to associate a reading with a track (radians)
+	Alpha      float64       // Per-track EMA smoothing factor
+	BirthHits  int           // Speaking readings needed before a track is reported
+	DeathAfter time.Duration // Tracks silent for this long are removed
+	MaxSources int           // Maximum simultaneous tracks
+}
+
+// DefaultMultiSourceConfig returns sensible defaults
+func DefaultMultiSourceConfig() MultiSourceConfig {
+	return MultiSourceConfig{
+		GateAngle:  0.35, // ~20°
+		Alpha:      0.3,
+		BirthHits:  3,
+		DeathAfter: 10 * time.Second,
+		MaxSources: 4,
+	}
+}
+
+// TrackedSource is an active sound source (e.g., one speaker)
+type TrackedSource struct {
+	ID         int       `json:"id"`
+	Angle      float64   `json:"angle"`
+	Confidence float64   `json:"confidence"`
+	LastActive time.Time `json:"last_active"`
+	FirstSeen  time.Time `json:"first_seen"`
+	Hits       int       `json:"hits"`
+	Speaking   bool      `json:"speaking"` // Matched the most recent speaking reading
+}
+
+// MultiSourceTracker maintains per-speaker angle tracks with birth/death logic,
+// so alternating speakers don't drag a single smoothed angle back and forth
+type MultiSourceTracker struct {
+	cfg MultiSourceConfig
+
+	mu      sync.RWMutex
+	tracks  *angleClusters
+	current int // ID of the track matched by the latest speaking reading (0 = none)
+}
+
+// NewMultiSourceTracker creates a new multi-source tracker
+func NewMultiSourceTracker(cfg MultiSourceConfig) *MultiSourceTracker {
+	def := DefaultMultiSourceConfig()
+	if cfg.GateAngle <= 0 {
+		cfg.GateAngle = def.GateAngle
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = def.Alpha
+	}
+	if cfg.BirthHits <= 0 {
+		cfg.BirthHits = def.BirthHits
+	}
+	if cfg.DeathAfter <= 0 {
+		cfg.DeathAfter = def.DeathAfter
+	}
+	if cfg.MaxSources <= 0 {
+		cfg.MaxSources = def.MaxSources
+	}
+
+	m := &MultiSourceTracker{cfg: cfg, tracks: newAngleClusters()}
+	m.tracks.tune(cfg.GateAngle, cfg.Alpha, cfg.DeathAfter, cfg.MaxSources)
+	return m
+}
+
+// Update feeds a raw reading; only speaking readings create or refresh tracks
+func (m *MultiSourceTracker) Update(angle float64, speaking bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tracks.prune(now)
+
+	if !speaking {
+		m.current = 0
+		return
+	}
+
+	// Associate with the nearest track inside the gate
+	tr := m.tracks.nearest(angle)
+	if tr == nil {
+		tr = m.tracks.add(angle, now)
+	} else {
+		m.tracks.blend(tr, angle)
+	}
+
+	tr.hits++
+	tr.lastSeen = now
+	m.current = tr.id
+}
+
+// Sources returns confirmed tracks, most recently active first
+func (m *MultiSourceTracker) Sources(now time.Time) []TrackedSource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	live := m.tracks.live(now)
+	sources := make([]TrackedSource, 0, len(live))
+	for _, tr := range live {
+		if tr.hits < m.cfg.BirthHits {
+			continue
+		}
+
+		src := trackedSource(tr)
+		src.Speaking = tr.id == m.current
+
+		// Confidence grows with evidence and fades with silence
+		age := now.Sub(tr.lastSeen)
+		evidence := math.Min(float64(tr.hits)/float64(m.cfg.BirthHits*5), 1)
+		src.Confidence = Clamp(evidence*(1-float64(age)/float64(m.cfg.DeathAfter)), 0, 1)
+
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// snapshot returns a copy of every live track, confirmed or not
+func (m *MultiSourceTracker) snapshot() []TrackedSource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tracks := make([]TrackedSource, len(m.tracks.clusters))
+	for i, tr := range m.tracks.clusters {
+		tracks[i] = trackedSource(tr)
+	}
+	return tracks
+}
+
+// restore replaces the tracks with saved ones that have not yet died
+func (m *MultiSourceTracker) restore(tracks []TrackedSource, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tracks.reset()
+	m.current = 0
+	for _, tr := range tracks {
+		m.tracks.restore(angleCluster{
+			id:        tr.ID,
+			angle:     tr.Angle,
+			hits:      tr.Hits,
+			firstSeen: tr.FirstSeen,
+			lastSeen:  tr.LastActive,
+		}, now)
+	}
+}
+
+// trackedSource reports a track
+func trackedSource(tr *angleCluster) TrackedSource {
+	return TrackedSource{
+		ID:         tr.id,
+		Angle:      tr.angle,
+		LastActive: tr.lastSeen,
+		FirstSeen:  tr.firstSeen,
+		Hits:       tr.hits,
+	}
+}
@@ -0,0 +1,129 @@
+package doa
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMultiSource_AlternatingSpeakers(t *testing.T) {
+	m := NewMultiSourceTracker(DefaultMultiSourceConfig())
+	now := time.Now()
+
+	// Two speakers at +60° and -60° taking turns
+	for i := 0; i < 10; i++ {
+		now = now.Add(50 * time.Millisecond)
+		angle := math.Pi / 3
+		if (i/3)%2 == 1 {
+			angle = -math.Pi / 3
+		}
+		m.Update(angle, true, now)
+	}
+
+	sources := m.Sources(now)
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d: %+v", len(sources), sources)
+	}
+
+	for _, src := range sources {
+		if math.Abs(math.Abs(src.Angle)-math.Pi/3) > 1e-9 {
+			t.Errorf("track angle drifted: %f", src.Angle)
+		}
+	}
+
+	if !sources[0].Speaking || sources[1].Speaking {
+		t.Error("most recent source should be first and marked speaking")
+	}
+}
+
+func TestMultiSource_BirthAndDeath(t *testing.T) {
+	cfg := DefaultMultiSourceConfig()
+	m := NewMultiSourceTracker(cfg)
+	now := time.Now()
+
+	m.Update(0.5, true, now)
+	if len(m.Sources(now)) != 0 {
+		t.Error("track should not be reported before BirthHits")
+	}
+
+	for i := 1; i < cfg.BirthHits; i++ {
+		m.Update(0.5, true, now)
+	}
+	if len(m.Sources(now)) != 1 {
+		t.Fatal("track should be reported after BirthHits")
+	}
+
+	// Silence does not create tracks and the old one dies
+	later := now.Add(cfg.DeathAfter)
+	m.Update(0.5, false, later)
+	if len(m.Sources(later)) != 0 {
+		t.Error("track should die after DeathAfter")
+	}
+}
+
+func TestMultiSource_WrapAround(t *testing.T) {
+	m := NewMultiSourceTracker(DefaultMultiSourceConfig())
+	now := time.Now()
+
+	// Readings straddling ±π belong to the same speaker behind the robot
+	for i := 0; i < 6; i++ {
+		angle := math.Pi - 0.05
+		if i%2 == 1 {
+			angle = -math.Pi + 0.05
+		}
+		m.Update(angle, true, now)
+	}
+
+	if got := len(m.Sources(now)); got != 1 {
+		t.Errorf("expected 1 source across the ±π seam, got %d", got)
+	}
+}
+
+func TestMultiSource_MaxSources(t *testing.T) {
+	cfg := DefaultMultiSourceConfig()
+	cfg.MaxSources = 2
+	cfg.BirthHits = 1
+	m := NewMultiSourceTracker(cfg)
+	now := time.Now()
+
+	m.Update(-2, true, now)
+	m.Update(0, true, now.Add(time.Millisecond))
+	m.Update(2, true, now.Add(2*time.Millisecond))
+
+	sources := m.Sources(now.Add(2 * time.Millisecond))
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(sources))
+	}
+	for _, src := range sources {
+		if src.Angle == -2 {
+			t.Error("stalest track should have been evicted")
+		}
+	}
+}
+
+func TestTracker_GetSources(t *testing.T) {
+	source := NewMockSource()
+	source.SetAngle(math.Pi / 2)
+	source.SetSpeaking(true)
+
+	cfg := DefaultTrackerConfig()
+	cfg.PollInterval = 5 * time.Millisecond
+	tracker := NewTracker(source, cfg, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	tracker.Stop()
+
+	sources := tracker.GetSources()
+	if len(sources) != 1 {
+		t.Fatalf("expected 1 source, got %d", len(sources))
+	}
+	if math.Abs(sources[0].Angle) > 1e-9 {
+		t.Errorf("expected source in front (0 rad), got %f", sources[0].Angle)
+	}
+}
@@ -0,0 +1,113 @@
+package doa
+
+import "math"
+
+// NoiseConfig configures noise floor estimation. The XVF3800's speech flag
+// chatters in noisy rooms, so a reading only counts as speech when its
+// energy also clears a threshold learned from the room's background.
+type NoiseConfig struct {
+	Enabled      bool    // Gate speaking on energy above the floor (the floor is tracked either way)
+	Alpha        float64 // Adaptation rate per non-speech reading (0-1)
+	Margin       float64 // Threshold = floor + Margin standard deviations
+	MinThreshold float64 // Lowest energy threshold
+	Warmup       int     // Non-speech readings before gating starts
+}
+
+// DefaultNoiseConfig returns sensible defaults
+func DefaultNoiseConfig() NoiseConfig {
+	return NoiseConfig{
+		Enabled: true,
+		Alpha:   0.02,
+		Margin:  3,
+		Warmup:  40, // 2s at 20Hz
+	}
+}
+
+// NoiseStats describes the learned noise floor
+type NoiseStats struct {
+	Enabled   bool    `json:"enabled"`
+	Floor     float64 `json:"floor"`     // Mean background energy
+	StdDev    float64 `json:"std_dev"`   // Background energy spread
+	Threshold float64 `json:"threshold"` // Energy a reading needs to count as speech
+	Samples   int     `json:"samples"`   // Non-speech readings seen
+	Ready     bool    `json:"ready"`     // Warmed up and gating
+	Gated     uint64  `json:"gated"`     // Speech flags rejected as noise
+}
+
+// NoiseFloor tracks the TotalEnergy statistics of non-speech readings
+type NoiseFloor struct {
+	cfg NoiseConfig
+
+	mean     float64
+	variance float64
+	samples  int
+	gated    uint64
+}
+
+// NewNoiseFloor creates a noise floor estimator
+func NewNoiseFloor(cfg NoiseConfig) *NoiseFloor {
+	return &NoiseFloor{cfg: cfg}
+}
+
+// SetConfig changes the tuning; the learned floor is kept
+func (n *NoiseFloor) SetConfig(cfg NoiseConfig) {
+	n.cfg = cfg
+}
+
+// Threshold returns the energy a reading needs to count as speech
+func (n *NoiseFloor) Threshold() float64 {
+	return math.Max(n.cfg.MinThreshold, n.mean+n.cfg.Margin*math.Sqrt(n.variance))
+}
+
+// ready reports whether enough background has been seen to gate on
+func (n *NoiseFloor) ready() bool {
+	return n.samples >= n.cfg.Warmup
+}
+
+// Update feeds one reading and returns whether it is speech: the hardware
+// flag, and once warmed up, energy above the threshold. Readings that are
+// not speech update the floor.
+func (n *NoiseFloor) Update(r Reading) bool {
+	speaking := r.Speaking
+	if speaking && n.cfg.Enabled && n.ready() {
+		// A zero threshold means the source reports no energy; trust the flag
+		if th := n.Threshold(); th > 0 && r.TotalEnergy <= th {
+			speaking = false
+			n.gated++
+		}
+	}
+
+	if !speaking && r.TotalEnergy >= 0 && !math.IsInf(r.TotalEnergy, 1) {
+		n.observe(r.TotalEnergy)
+	}
+	return speaking
+}
+
+// observe folds a background sample into an exponentially weighted mean and
+// variance. Warm-up uses the running average so the floor settles quickly.
+func (n *NoiseFloor) observe(energy float64) {
+	n.samples++
+	if n.samples == 1 {
+		n.mean, n.variance = energy, 0
+		return
+	}
+
+	alpha := math.Max(n.cfg.Alpha, 1/float64(n.samples))
+	diff := energy - n.mean
+	incr := alpha * diff
+	n.mean += incr
+	n.variance = (1 - alpha) * (n.variance + diff*incr)
+}
+
+// Stats returns the learned floor
+func (n *NoiseFloor) Stats() NoiseStats {
+	return NoiseStats{
+		Enabled:   n.cfg.Enabled,
+		Floor:     n.mean,
+		StdDev:    math.Sqrt(n.variance),
+		Threshold: n.Threshold(),
+		Samples:   n.samples,
+		Ready:     n.cfg.Enabled && n.ready(),
+		Gated:     n.gated,
+	}
+}
@@ -0,0 +1,73 @@
+package doa
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNoiseFloor(t *testing.T) {
+	n := NewNoiseFloor(NoiseConfig{Enabled: true, Alpha: 0.05, Margin: 3, Warmup: 20})
+
+	// Background chatter: the flag fires on noise before warm-up completes
+	for i := range 40 {
+		energy := 10 + float64(i%3) // 10..12
+		n.Update(Reading{Speaking: i%4 == 0, TotalEnergy: energy})
+	}
+
+	stats := n.Stats()
+	if !stats.Ready || stats.Samples < 20 {
+		t.Fatalf("stats after warm-up = %+v", stats)
+	}
+	if math.Abs(stats.Floor-11) > 1 {
+		t.Errorf("floor = %v, want about 11", stats.Floor)
+	}
+	if stats.Threshold <= 12 || stats.Threshold > 20 {
+		t.Errorf("threshold = %v, want just above the background", stats.Threshold)
+	}
+
+	// A speech flag on background energy is rejected and learned as noise
+	gated := stats.Gated
+	if n.Update(Reading{Speaking: true, TotalEnergy: 11}) {
+		t.Error("speech flag at the noise floor should be gated")
+	}
+	if n.Stats().Gated != gated+1 {
+		t.Errorf("gated = %d, want %d", n.Stats().Gated, gated+1)
+	}
+
+	// Real speech clears the threshold and leaves the floor alone
+	floor := n.Stats().Floor
+	if !n.Update(Reading{Speaking: true, TotalEnergy: 200}) {
+		t.Error("loud speech should pass the gate")
+	}
+	if n.Stats().Floor != floor {
+		t.Error("speech should not move the noise floor")
+	}
+
+	// Energy alone is never speech
+	if n.Update(Reading{Speaking: false, TotalEnergy: 200}) {
+		t.Error("energy without the hardware flag should not be speech")
+	}
+}
+
+func TestNoiseFloor_PassThrough(t *testing.T) {
+	// Disabled: the floor is tracked but the flag is never gated
+	n := NewNoiseFloor(NoiseConfig{Alpha: 0.05, Margin: 3})
+	for range 10 {
+		n.Update(Reading{TotalEnergy: 50})
+	}
+	if !n.Update(Reading{Speaking: true, TotalEnergy: 1}) {
+		t.Error("disabled gate rejected the speech flag")
+	}
+	if stats := n.Stats(); stats.Ready || stats.Floor == 0 {
+		t.Errorf("disabled stats = %+v", stats)
+	}
+
+	// Sources without energy (threshold 0) keep the hardware flag
+	n = NewNoiseFloor(DefaultNoiseConfig())
+	for range 50 {
+		n.Update(Reading{})
+	}
+	if !n.Update(Reading{Speaking: true}) {
+		t.Error("a source without energy should keep its speech flag")
+	}
+}
@@ -0,0 +1,97 @@
+package doa
+
+import (
+	"math"
+	"time"
+)
+
+// OutlierConfig configures rejection of single-poll angle jumps. A glitched
+// reading far from where the smoother expects the speaker is kept out of
+// the smoothed angle unless the following readings confirm the jump.
+type OutlierConfig struct {
+	Enabled bool
+	MaxJump float64 // Radians from the prediction beyond which a reading is suspect
+	Confirm int     // Consecutive agreeing readings that make a jump real
+}
+
+// DefaultOutlierConfig returns sensible defaults
+func DefaultOutlierConfig() OutlierConfig {
+	return OutlierConfig{
+		Enabled: false,
+		MaxJump: 60 * math.Pi / 180,
+		Confirm: 3,
+	}
+}
+
+// OutlierStats describes outlier rejection
+type OutlierStats struct {
+	Enabled   bool   `json:"enabled"`
+	Rejected  uint64 `json:"rejected"`  // Readings kept out of the smoothed angle
+	Confirmed uint64 `json:"confirmed"` // Jumps accepted after Confirm readings
+	Pending   int    `json:"pending"`   // Readings agreeing on a jump so far
+}
+
+// OutlierFilter decides whether a reading is consistent with a predicted
+// angle. Readings beyond MaxJump are rejected until Confirm of them in a row
+// agree with each other.
+type OutlierFilter struct {
+	cfg OutlierConfig
+
+	candidate float64 // Angle of the pending jump
+	run       int     // Readings agreeing with candidate
+	rejected  uint64
+	confirmed uint64
+}
+
+// NewOutlierFilter creates an outlier filter
+func NewOutlierFilter(cfg OutlierConfig) *OutlierFilter {
+	return &OutlierFilter{cfg: cfg}
+}
+
+// SetConfig changes the tuning; a pending jump is dropped
+func (f *OutlierFilter) SetConfig(cfg OutlierConfig) {
+	f.cfg = cfg
+	f.run = 0
+}
+
+// Accept feeds one angle and reports whether to use it given the predicted
+// angle, and whether it confirms a jump, in which case the caller should
+// restart smoothing from it rather than let the prediction lag behind. A
+// disabled filter accepts everything.
+func (f *OutlierFilter) Accept(angle, prediction float64) (accept, jump bool) {
+	if !f.cfg.Enabled || math.Abs(NormalizeAngle(angle-prediction)) <= f.cfg.MaxJump {
+		f.run = 0
+		return true, false
+	}
+
+	if f.run > 0 && math.Abs(NormalizeAngle(angle-f.candidate)) <= f.cfg.MaxJump {
+		f.run++
+	} else {
+		f.candidate, f.run = angle, 1
+	}
+	if f.run >= f.cfg.Confirm {
+		f.run = 0
+		f.confirmed++
+		return true, true
+	}
+	f.rejected++
+	return false, false
+}
+
+// Stats returns rejection counts
+func (f *OutlierFilter) Stats() OutlierStats {
+	return OutlierStats{
+		Enabled:   f.cfg.Enabled,
+		Rejected:  f.rejected,
+		Confirmed: f.confirmed,
+		Pending:   f.run,
+	}
+}
+
+// predictLocked returns the angle the smoother expects at t (caller holds mu)
+func (t *Tracker) predictLocked(at time.Time) float64 {
+	if p, ok := t.smoother.(Predictor); ok {
+		return p.Predict(at)
+	}
+	return t.latest.SmoothedAngle
+}
@@ -0,0 +1,95 @@
+package doa
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestOutlierFilter(t *testing.T) {
+	f := NewOutlierFilter(OutlierConfig{Enabled: true, MaxJump: deg(45), Confirm: 2})
+
+	accept := func(angle float64) bool {
+		ok, _ := f.Accept(angle, 0)
+		return ok
+	}
+
+	if !accept(deg(30)) {
+		t.Error("reading within MaxJump rejected")
+	}
+	if accept(deg(150)) {
+		t.Error("single jump accepted")
+	}
+	// A reading back near the prediction drops the pending jump
+	if !accept(deg(5)) || f.Stats().Pending != 0 {
+		t.Errorf("pending jump kept: %+v", f.Stats())
+	}
+	// Two agreeing readings in a row confirm it
+	if accept(deg(150)) {
+		t.Error("first reading of a jump accepted")
+	}
+	if ok, jump := f.Accept(deg(160), 0); !ok || !jump {
+		t.Errorf("confirming reading: accept = %v, jump = %v, want both", ok, jump)
+	}
+	// Jumps that disagree with each other never confirm
+	if accept(deg(120)) || accept(deg(-120)) {
+		t.Error("scattered jumps accepted")
+	}
+
+	stats := f.Stats()
+	if stats.Rejected != 4 || stats.Confirmed != 1 || stats.Pending != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	f.SetConfig(OutlierConfig{})
+	if ok, jump := f.Accept(math.Pi, 0); !ok || jump {
+		t.Error("disabled filter rejected a reading or reported a jump")
+	}
+}
+
+func TestTracker_Outliers(t *testing.T) {
+	source := NewMockSource()
+	source.SetSpeaking(true)
+	cfg := DefaultTrackerConfig()
+	cfg.Outliers = OutlierConfig{Enabled: true, MaxJump: deg(60), Confirm: 3}
+	tracker := NewTracker(source, cfg, nil)
+	ctx := context.Background()
+
+	poll := func(angle float64) Result {
+		t.Helper()
+		source.SetAngle(math.Pi/2 - angle) // Mock angles are XVF3800 frame
+		if err := tracker.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return tracker.GetLatest()
+	}
+
+	for range 5 {
+		poll(0)
+	}
+
+	// One glitched poll leaves the smoothed angle alone
+	if r := poll(deg(150)); !r.Outlier || math.Abs(r.SmoothedAngle) > 1e-9 {
+		t.Errorf("glitch: outlier = %v, smoothed = %v, want held at 0", r.Outlier, r.SmoothedAngle)
+	}
+	if r := poll(0); r.Outlier {
+		t.Error("reading after the glitch marked as outlier")
+	}
+
+	// A speaker who really moved is followed once three polls agree
+	poll(deg(90))
+	poll(deg(92))
+	r := poll(deg(91))
+	if r.Outlier || math.Abs(r.SmoothedAngle-deg(91)) > 1e-9 {
+		t.Errorf("confirmed move: outlier = %v, smoothed = %v, want restarted at 91°", r.Outlier, r.SmoothedAngle)
+	}
+	// Readings after the jump are judged against the new direction
+	for range 3 {
+		if r := poll(deg(90)); r.Outlier {
+			t.Fatal("reading after a confirmed jump rejected")
+		}
+	}
+	if stats := tracker.Stats().Outliers; stats.Rejected != 3 || stats.Confirmed != 1 {
+		t.Errorf("unexpected outlier stats %+v", stats)
+	}
+}
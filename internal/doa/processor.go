@@ -0,0 +1,82 @@
+package doa
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Processor post-processes tracker results. The tracker runs its
+// processors in order on every result before storing and publishing it, so
+// each sees the previous one's output, and maps the zone from the processed
+// angle afterwards. Process is called with the tracker locked and must not
+// call back into it.
+type Processor interface {
+	Process(Result) Result
+}
+
+// ProcessorFunc adapts a function to Processor
+type ProcessorFunc func(Result) Result
+
+// Process calls f
+func (f ProcessorFunc) Process(r Result) Result {
+	return f(r)
+}
+
+// ErrUnknownProcessor is returned by NewProcessor for a name nothing
+// registered
+var ErrUnknownProcessor = errors.New("unknown DOA processor")
+
+// ProcessorFactory builds a registered processor from its parameters and
+// the config of the tracker it will run in
+type ProcessorFactory func(params map[string]float64, cfg TrackerConfig) (Processor, error)
+
+var processors = struct {
+	sync.RWMutex
+	factories map[string]ProcessorFactory
+}{factories: make(map[string]ProcessorFactory)}
+
+// RegisterProcessor makes a processor available to NewProcessor under name.
+// It panics if the name is taken, so conflicting registrations fail at
+// startup.
+func RegisterProcessor(name string, factory ProcessorFactory) {
+	processors.Lock()
+	defer processors.Unlock()
+
+	if name == "" {
+		panic("doa: empty processor name")
+	}
+	if _, dup := processors.factories[name]; dup {
+		panic(fmt.Sprintf("doa: processor %q registered twice", name))
+	}
+	processors.factories[name] = factory
+}
+
+// Processors returns the registered processor names, sorted
+func Processors() []string {
+	processors.RLock()
+	defer processors.RUnlock()
+
+	names := make([]string, 0, len(processors.factories))
+	for name := range processors.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProcessor builds the processor registered under name
+func NewProcessor(name string, params map[string]float64, cfg TrackerConfig) (Processor, error) {
+	processors.RLock()
+	factory, ok := processors.factories[name]
+	processors.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q (registered: %v)", ErrUnknownProcessor, name, Processors())
+	}
+	p, err := factory(params, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("processor %s: %w", name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,83 @@
+package doa
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// test-rotate adds params["deg"] degrees to a result's angles
+func init() {
+	RegisterProcessor("test-rotate", func(params map[string]float64, _ TrackerConfig) (Processor, error) {
+		offset := params["deg"] * math.Pi / 180
+		return ProcessorFunc(func(r Result) Result {
+			r.Angle = NormalizeAngle(r.Angle + offset)
+			r.SmoothedAngle = NormalizeAngle(r.SmoothedAngle + offset)
+			return r
+		}), nil
+	})
+}
+
+func TestProcessorRegistry(t *testing.T) {
+	if !slices.Contains(Processors(), "test-rotate") {
+		t.Errorf("Processors() = %v, want test-rotate", Processors())
+	}
+
+	if _, err := NewProcessor("nope", nil, DefaultTrackerConfig()); !errors.Is(err, ErrUnknownProcessor) {
+		t.Errorf("NewProcessor(nope) error = %v, want ErrUnknownProcessor", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	RegisterProcessor("test-rotate", nil)
+}
+
+func TestTracker_Processors(t *testing.T) {
+	source := NewMockSource()
+	source.SetSpeaking(true)
+	cfg := DefaultTrackerConfig()
+	cfg.Zones.MinDwell = 0
+
+	rotate, err := NewProcessor("test-rotate", map[string]float64{"deg": 90}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen float64
+	cfg.Processors = []Processor{rotate, ProcessorFunc(func(r Result) Result {
+		seen = r.Angle
+		return r
+	})}
+	tracker := NewTracker(source, cfg, nil)
+	zones := tracker.SubscribeZones()
+	defer tracker.UnsubscribeZones(zones)
+
+	source.SetAngle(math.Pi / 2) // Eva front
+	if err := tracker.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r := tracker.GetLatest()
+	if math.Abs(r.Angle-deg(90)) > 1e-9 || math.Abs(r.SmoothedAngle-deg(90)) > 1e-9 {
+		t.Errorf("angle = %v, smoothed = %v, want rotated to 90°", r.Angle, r.SmoothedAngle)
+	}
+	if seen != r.Angle {
+		t.Errorf("second processor saw %v, want the rotated angle %v", seen, r.Angle)
+	}
+
+	// The zone and its event both follow the processed angle
+	if r.Zone != "left" {
+		t.Errorf("Result.Zone = %q, want left", r.Zone)
+	}
+	select {
+	case ev := <-zones:
+		if ev.To != r.Zone {
+			t.Errorf("zone event = %+v, want → %s", ev, r.Zone)
+		}
+	default:
+		t.Error("no zone event")
+	}
+}
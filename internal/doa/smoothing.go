@@ -0,0 +1,206 @@
+package doa
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Smoothing modes selectable via config
+const (
+	SmoothingEMA    = "ema"
+	SmoothingKalman = "kalman"
+	SmoothingMedian = "median"
+)
+
+// Smoother filters a stream of raw DOA angles
+type Smoother interface {
+	// Update ingests a raw angle measured at t and returns the smoothed angle
+	Update(angle float64, t time.Time) float64
+	// Reset clears filter state
+	Reset()
+}
+
+// Predictor is implemented by smoothers that can extrapolate between polls
+type Predictor interface {
+	Predict(t time.Time) float64
+}
+
+// SmoothingConfig configures angle smoothing
+type SmoothingConfig struct {
+	Mode             string  // ema, kalman, median
+	EMAAlpha         float64 // EMA weight of the new reading
+	MedianWindow     int     // Readings in the median window
+	ProcessNoise     float64 // Kalman angular acceleration noise (rad/s²)²
+	MeasurementNoise float64 // Kalman measurement noise (rad²)
+}
+
+// NewSmoother creates a smoother for the configured mode
+func NewSmoother(cfg SmoothingConfig) (Smoother, error) {
+	switch cfg.Mode {
+	case "", SmoothingEMA:
+		return &EMASmoother{Alpha: cfg.EMAAlpha}, nil
+	case SmoothingKalman:
+		return NewKalmanSmoother(cfg.ProcessNoise, cfg.MeasurementNoise), nil
+	case SmoothingMedian:
+		return NewMedianSmoother(cfg.MedianWindow), nil
+	default:
+		return nil, fmt.Errorf("unknown smoothing mode: %q", cfg.Mode)
+	}
+}
+
+// EMASmoother is an exponential moving average on the circle: each update
+// moves along the shortest arc, so ±π crossings don't swing through 0
+type EMASmoother struct {
+	Alpha float64
+
+	prev    float64
+	started bool
+}
+
+// Update implements Smoother
+func (s *EMASmoother) Update(angle float64, t time.Time) float64 {
+	if !s.started {
+		s.prev = angle
+		s.started = true
+		return angle
+	}
+	s.prev = NormalizeAngle(s.prev + s.Alpha*NormalizeAngle(angle-s.prev))
+	return s.prev
+}
+
+// Reset implements Smoother
+func (s *EMASmoother) Reset() {
+	s.started = false
+}
+
+// MedianSmoother returns the circular median of a sliding window, rejecting outliers
+type MedianSmoother struct {
+	window []float64
+	size   int
+}
+
+// NewMedianSmoother creates a median smoother (window defaults to 5)
+func NewMedianSmoother(size int) *MedianSmoother {
+	if size <= 0 {
+		size = 5
+	}
+	return &MedianSmoother{size: size}
+}
+
+// Update implements Smoother
+func (s *MedianSmoother) Update(angle float64, t time.Time) float64 {
+	s.window = append(s.window, angle)
+	if len(s.window) > s.size {
+		s.window = s.window[1:]
+	}
+
+	// Unwrap relative to the newest reading so the median is valid across ±π
+	offsets := make([]float64, len(s.window))
+	for i, a := range s.window {
+		offsets[i] = NormalizeAngle(a - angle)
+	}
+	sort.Float64s(offsets)
+
+	mid := len(offsets) / 2
+	median := offsets[mid]
+	if len(offsets)%2 == 0 {
+		median = (offsets[mid-1] + offsets[mid]) / 2
+	}
+	return NormalizeAngle(angle + median)
+}
+
+// Reset implements Smoother
+func (s *MedianSmoother) Reset() {
+	s.window = nil
+}
+
+// KalmanSmoother is a constant-velocity Kalman filter over [angle, angular velocity]
+type KalmanSmoother struct {
+	q float64 // Process noise
+	r float64 // Measurement noise
+
+	angle    float64
+	velocity float64
+	p        [2][2]float64 // State covariance
+	last     time.Time
+	started  bool
+}
+
+// NewKalmanSmoother creates a Kalman smoother (zero noise values use defaults)
+func NewKalmanSmoother(processNoise, measurementNoise float64) *KalmanSmoother {
+	if processNoise <= 0 {
+		processNoise = 1.0
+	}
+	if measurementNoise <= 0 {
+		measurementNoise = 0.02
+	}
+	return &KalmanSmoother{q: processNoise, r: measurementNoise}
+}
+
+// Update implements Smoother
+func (k *KalmanSmoother) Update(angle float64, t time.Time) float64 {
+	if !k.started {
+		k.angle = angle
+		k.velocity = 0
+		k.p = [2][2]float64{{k.r, 0}, {0, 1}}
+		k.last = t
+		k.started = true
+		return angle
+	}
+
+	dt := t.Sub(k.last).Seconds()
+	if dt < 0 {
+		dt = 0
+	}
+	k.last = t
+
+	// Predict: x = F x, P = F P Fᵀ + Q (white-noise acceleration model)
+	k.angle = NormalizeAngle(k.angle + k.velocity*dt)
+	p00 := k.p[0][0] + dt*(k.p[1][0]+k.p[0][1]) + dt*dt*k.p[1][1]
+	p01 := k.p[0][1] + dt*k.p[1][1]
+	p10 := k.p[1][0] + dt*k.p[1][1]
+	p11 := k.p[1][1]
+
+	dt2 := dt * dt
+	p00 += k.q * dt2 * dt2 / 4
+	p01 += k.q * dt2 * dt / 2
+	p10 += k.q * dt2 * dt / 2
+	p11 += k.q * dt2
+
+	// Update with the circular innovation so ±π crossings don't jump
+	innovation := NormalizeAngle(angle - k.angle)
+	s := p00 + k.r
+	k0 := p00 / s
+	k1 := p10 / s
+
+	k.angle = NormalizeAngle(k.angle + k0*innovation)
+	k.velocity += k1 * innovation
+
+	k.p = [2][2]float64{
+		{(1 - k0) * p00, (1 - k0) * p01},
+		{p10 - k1*p00, p11 - k1*p01},
+	}
+
+	return k.angle
+}
+
+// Predict extrapolates the filtered angle to time t without updating state
+func (k *KalmanSmoother) Predict(t time.Time) float64 {
+	if !k.started {
+		return 0
+	}
+	dt := math.Max(t.Sub(k.last).Seconds(), 0)
+	return NormalizeAngle(k.angle + k.velocity*dt)
+}
+
+// Velocity returns the estimated angular velocity (rad/s)
+func (k *KalmanSmoother) Velocity() float64 {
+	return k.velocity
+}
+
+// Reset implements Smoother
+func (k *KalmanSmoother) Reset() {
+	k.started = false
+}
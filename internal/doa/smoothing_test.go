@@ -0,0 +1,113 @@
+package doa
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewSmoother(t *testing.T) {
+	for _, mode := range []string{"", SmoothingEMA, SmoothingKalman, SmoothingMedian} {
+		if _, err := NewSmoother(SmoothingConfig{Mode: mode, EMAAlpha: 0.3}); err != nil {
+			t.Errorf("mode %q: unexpected error %v", mode, err)
+		}
+	}
+
+	if _, err := NewSmoother(SmoothingConfig{Mode: "lowpass"}); err == nil {
+		t.Error("unknown mode should fail")
+	}
+}
+
+func TestEMASmoother(t *testing.T) {
+	s := &EMASmoother{Alpha: 0.5}
+	now := time.Now()
+
+	if got := s.Update(1.0, now); got != 1.0 {
+		t.Errorf("first update should pass through, got %f", got)
+	}
+	if got := s.Update(0.0, now); got != 0.5 {
+		t.Errorf("expected 0.5, got %f", got)
+	}
+}
+
+func TestEMASmoother_WrapAround(t *testing.T) {
+	s := &EMASmoother{Alpha: 0.5}
+	now := time.Now()
+
+	// Speaker behind the robot, readings straddling ±π
+	s.Update(3.1, now)
+	got := s.Update(-3.1, now)
+	if math.Abs(got) < 3.1 {
+		t.Errorf("smoothed angle should stay near ±π, got %f", got)
+	}
+}
+
+func TestMedianSmoother_RejectsOutlier(t *testing.T) {
+	s := NewMedianSmoother(5)
+	now := time.Now()
+
+	var got float64
+	for _, a := range []float64{0.5, 0.51, 2.5, 0.49, 0.5} {
+		got = s.Update(a, now)
+	}
+	if math.Abs(got-0.5) > 0.011 {
+		t.Errorf("median should ignore outlier, got %f", got)
+	}
+}
+
+func TestMedianSmoother_WrapAround(t *testing.T) {
+	s := NewMedianSmoother(3)
+	now := time.Now()
+
+	s.Update(math.Pi-0.1, now)
+	s.Update(-math.Pi+0.1, now)
+	got := s.Update(math.Pi-0.05, now)
+
+	if math.Abs(got) < 3 {
+		t.Errorf("median across ±π should stay behind the robot, got %f", got)
+	}
+}
+
+func TestKalmanSmoother_TracksMovingSpeaker(t *testing.T) {
+	k := NewKalmanSmoother(0, 0)
+	ema := &EMASmoother{Alpha: 0.3}
+	start := time.Now()
+
+	// Speaker walking at 0.5 rad/s, polled at 20Hz
+	const velocity = 0.5
+	var ts time.Time
+	var kAngle, eAngle, truth float64
+	for i := 0; i < 60; i++ {
+		ts = start.Add(time.Duration(i) * 50 * time.Millisecond)
+		truth = -1.5 + velocity*float64(i)*0.05
+		kAngle = k.Update(truth, ts)
+		eAngle = ema.Update(truth, ts)
+	}
+
+	if math.Abs(kAngle-truth) >= math.Abs(eAngle-truth) {
+		t.Errorf("kalman should lag less than EMA: kalman err %f, ema err %f",
+			math.Abs(kAngle-truth), math.Abs(eAngle-truth))
+	}
+
+	if math.Abs(k.Velocity()-velocity) > 0.1 {
+		t.Errorf("expected velocity ~%f, got %f", velocity, k.Velocity())
+	}
+
+	// Prediction extrapolates between polls
+	predicted := k.Predict(ts.Add(100 * time.Millisecond))
+	if predicted <= kAngle {
+		t.Errorf("prediction should move ahead of the filtered angle: %f <= %f", predicted, kAngle)
+	}
+}
+
+func TestKalmanSmoother_WrapAround(t *testing.T) {
+	k := NewKalmanSmoother(0, 0)
+	now := time.Now()
+
+	k.Update(math.Pi-0.05, now)
+	got := k.Update(-math.Pi+0.05, now.Add(50*time.Millisecond))
+
+	if math.Abs(got) < 3 {
+		t.Errorf("kalman should cross ±π without swinging through 0, got %f", got)
+	}
+}
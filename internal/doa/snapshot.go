@@ -0,0 +1,106 @@
+package doa
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot is the tracker state kept across restarts, so the robot keeps
+// facing the last talker instead of snapping to front
+type Snapshot struct {
+	SavedAt         time.Time       `json:"saved_at"`
+	SmoothedAngle   float64         `json:"smoothed_angle"`
+	SpeakerID       int             `json:"speaker_id,omitempty"`
+	Speakers        []Speaker       `json:"speakers,omitempty"`
+	Sources         []TrackedSource `json:"sources,omitempty"`
+	ReferenceEnergy float64         `json:"reference_energy"`
+	Mounting        Mounting        `json:"mounting"` // Frame the angles were measured in
+}
+
+// Snapshot captures the latest smoothed angle, speaker tracks and
+// calibration constants
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now()
+	return Snapshot{
+		SavedAt:         now,
+		SmoothedAngle:   t.latest.SmoothedAngle,
+		SpeakerID:       t.speakers.Current(),
+		Speakers:        t.speakers.Speakers(now),
+		Sources:         t.sources.snapshot(),
+		ReferenceEnergy: ReferenceEnergy(),
+		Mounting:        CurrentMounting(),
+	}
+}
+
+// Restore seeds the tracker from a snapshot no older than maxAge (0 = no
+// limit) and reports whether it was used. A snapshot taken with a different
+// mounting is discarded, since its angles are in another frame; its
+// reference energy is only applied when no calibration has been loaded.
+func (t *Tracker) Restore(snap Snapshot, maxAge time.Duration) bool {
+	now := time.Now()
+	if snap.SavedAt.IsZero() || (maxAge > 0 && now.Sub(snap.SavedAt) > maxAge) {
+		return false
+	}
+	if snap.Mounting != CurrentMounting() {
+		return false
+	}
+	if ReferenceEnergy() == DefaultReferenceEnergy {
+		SetReferenceEnergy(snap.ReferenceEnergy)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	angle := NormalizeAngle(snap.SmoothedAngle)
+	t.smoother.Reset()
+	t.smoother.Update(angle, now)
+	t.latest = Result{
+		Reading:       Reading{Angle: angle, Timestamp: snap.SavedAt},
+		SmoothedAngle: angle,
+		Confidence:    t.cfg.Confidence.Base, // Enough for GetTarget, never more than a fresh reading
+		SpeakerID:     snap.SpeakerID,
+	}
+	t.speakers.restore(snap.Speakers, snap.SpeakerID, now)
+	t.sources.restore(snap.Sources, now)
+	return true
+}
+
+// SaveSnapshot writes a snapshot to path atomically
+func SaveSnapshot(path string, snap Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode tracker state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create tracker state dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write tracker state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write tracker state: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot. A missing file
+// returns an error matching os.ErrNotExist.
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snap Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, fmt.Errorf("read tracker state: %w", err)
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("parse tracker state %s: %w", path, err)
+	}
+	return snap, nil
+}
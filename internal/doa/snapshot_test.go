@@ -0,0 +1,79 @@
+package doa
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	cfg := DefaultTrackerConfig()
+	tracker := NewTracker(nil, cfg, nil)
+
+	now := time.Now()
+	tracker.mu.Lock()
+	tracker.latest.SmoothedAngle = deg(40)
+	alice := tracker.speakers.Identify(deg(40), now.Add(-time.Minute))
+	tracker.mu.Unlock()
+
+	path := filepath.Join(t.TempDir(), "state", "tracker.json")
+	if _, err := LoadSnapshot(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadSnapshot on a missing file = %v, want os.ErrNotExist", err)
+	}
+	if err := SaveSnapshot(path, tracker.Snapshot()); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	snap, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	restored := NewTracker(nil, cfg, nil)
+	if !restored.Restore(snap, time.Minute) {
+		t.Fatal("fresh snapshot was not restored")
+	}
+	if got := restored.GetLatest().SmoothedAngle; math.Abs(got-deg(40)) > 1e-9 {
+		t.Errorf("restored angle = %.1f°, want 40°", got/deg(1))
+	}
+	if _, _, ok := restored.GetTarget(); !ok {
+		t.Error("restored angle is not available as a target")
+	}
+	speakers := restored.GetSpeakers()
+	if len(speakers) != 1 || speakers[0].ID != alice {
+		t.Fatalf("restored speakers = %+v, want Alice (%d)", speakers, alice)
+	}
+
+	// New speakers must not reuse a restored ID
+	restored.mu.Lock()
+	bob := restored.speakers.Identify(deg(-90), time.Now())
+	restored.mu.Unlock()
+	if bob == alice {
+		t.Errorf("new speaker reused restored ID %d", alice)
+	}
+}
+
+func TestSnapshot_Staleness(t *testing.T) {
+	snap := Snapshot{
+		SavedAt:       time.Now().Add(-time.Hour),
+		SmoothedAngle: deg(90),
+		Mounting:      CurrentMounting(),
+	}
+
+	tracker := NewTracker(nil, DefaultTrackerConfig(), nil)
+	if tracker.Restore(snap, 10*time.Minute) {
+		t.Error("snapshot older than max age was restored")
+	}
+	if got := tracker.GetLatest().SmoothedAngle; got != 0 {
+		t.Errorf("stale snapshot moved the angle to %.1f°", got/deg(1))
+	}
+
+	// A different mounting means the saved angles are in another frame
+	snap.SavedAt = time.Now()
+	snap.Mounting.Mirror = !snap.Mounting.Mirror
+	if tracker.Restore(snap, 0) {
+		t.Error("snapshot from another mounting was restored")
+	}
+}
@@ -4,9 +4,36 @@ package doa
 import (
 	"context"
 	"math"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultReferenceEnergy is the speech energy at 1 meter from the factory
+// calibration (2026-01-03): multi-distance runs at 0.5m, 1m, 2m and 3m with
+// constant voice volume, using the median k value for robustness
+const DefaultReferenceEnergy = 6267144.0
+
+// referenceEnergy holds the float64 bits of the active calibration
+var referenceEnergy atomic.Uint64
+
+func init() {
+	referenceEnergy.Store(math.Float64bits(DefaultReferenceEnergy))
+}
+
+// SetReferenceEnergy replaces the speech energy at 1 meter used for distance
+// estimation. Non-positive values restore the default.
+func SetReferenceEnergy(energy float64) {
+	if energy <= 0 || math.IsNaN(energy) || math.IsInf(energy, 0) {
+		energy = DefaultReferenceEnergy
+	}
+	referenceEnergy.Store(math.Float64bits(energy))
+}
+
+// ReferenceEnergy returns the speech energy at 1 meter used for distance estimation
+func ReferenceEnergy() float64 {
+	return math.Float64frombits(referenceEnergy.Load())
+}
+
 // Reading represents a single DOA measurement from hardware
 type Reading struct {
 	Angle        float64   `json:"angle"`         // Radians in Eva coordinates (0=front, +left, -right)
@@ -23,18 +50,14 @@ type Reading struct {
 
 // EstimatedDistance returns a rough distance estimate based on speech energy.
 // Higher energy = closer. Returns 0 if no speech detected.
-// Calibrated using real-world measurements with XVF3800.
+// Uses the reference energy from SetReferenceEnergy (see internal/calibration).
 func (r *Reading) EstimatedDistance() float64 {
 	if r.TotalEnergy <= 0 || !r.Speaking {
 		return 0
 	}
-	// Calibrated reference energy at 1 meter (from calibration 2026-01-03)
-	// Multi-distance calibration: 0.5m, 1m, 2m, 3m with constant voice volume
-	// Using median k value for robustness against outliers
-	const referenceEnergy = 6267144.0
 
 	// Inverse square law: distance = sqrt(refEnergy / measuredEnergy)
-	distance := math.Sqrt(referenceEnergy / r.TotalEnergy)
+	distance := math.Sqrt(ReferenceEnergy() / r.TotalEnergy)
 
 	// Clamp to reasonable range (0.3m - 5m)
 	if distance < 0.3 {
@@ -46,6 +69,18 @@ func (r *Reading) EstimatedDistance() float64 {
 	return distance
 }
 
+// DominantMic returns the index of the mic with the most speech energy, or
+// -1 when no mic has any
+func (r *Reading) DominantMic() int {
+	best := -1
+	for i, e := range r.SpeechEnergy {
+		if e > 0 && (best < 0 || e > r.SpeechEnergy[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
 // EstimatedY returns the lateral (left/right) position estimate in meters.
 // Positive = left, negative = right (Eva coordinates)
 func (r *Reading) EstimatedY() float64 {
@@ -80,16 +115,55 @@ type Source interface {
 	Name() string
 }
 
-// ToEvaAngle converts XVF3800 angle to Eva's coordinate system
+// Mounting describes how the mic array sits relative to the head. Bearings
+// are converted to the head's frame, so a rotated or flipped array still
+// reports 0 for a speaker straight ahead of the head.
+type Mounting struct {
+	Offset float64 `json:"offset"` // Array rotation from the head (radians, + = left), added to every bearing
+	Mirror bool    `json:"mirror"` // Array mounted upside down: left and right swapped
+}
+
+// mounting holds the active mounting
+var mounting atomic.Pointer[Mounting]
+
+// SetMounting replaces the mounting applied by ToEvaAngle and FromEvaAngle
+func SetMounting(m Mounting) {
+	if math.IsNaN(m.Offset) || math.IsInf(m.Offset, 0) {
+		m.Offset = 0
+	}
+	m.Offset = NormalizeAngle(m.Offset)
+	mounting.Store(&m)
+}
+
+// CurrentMounting returns the active mounting (zero = array aligned with the head)
+func CurrentMounting() Mounting {
+	if m := mounting.Load(); m != nil {
+		return *m
+	}
+	return Mounting{}
+}
+
+// ToEvaAngle converts XVF3800 angle to Eva's coordinate system, correcting
+// for the mounting
 // XVF3800: 0 = left, π/2 = front, π = right
 // Eva:     0 = front, +π/2 = left, -π/2 = right
 func ToEvaAngle(xvfAngle float64) float64 {
-	return (math.Pi / 2) - xvfAngle
+	m := CurrentMounting()
+	angle := (math.Pi / 2) - xvfAngle
+	if m.Mirror {
+		angle = -angle
+	}
+	return NormalizeAngle(angle + m.Offset)
 }
 
 // FromEvaAngle converts Eva's angle back to XVF3800 coordinates
 func FromEvaAngle(evaAngle float64) float64 {
-	return (math.Pi / 2) - evaAngle
+	m := CurrentMounting()
+	angle := NormalizeAngle(evaAngle - m.Offset)
+	if m.Mirror {
+		angle = -angle
+	}
+	return (math.Pi / 2) - angle
 }
 
 // NormalizeAngle normalizes an angle to [-π, π]
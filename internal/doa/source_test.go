@@ -95,6 +95,35 @@ func TestRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMounting(t *testing.T) {
+	t.Cleanup(func() { SetMounting(Mounting{}) })
+
+	// Array rotated 15° left of the head: a speaker ahead of the head
+	// appears 15° right in the array
+	SetMounting(Mounting{Offset: 15 * math.Pi / 180})
+	if got := ToEvaAngle(math.Pi/2 + 15*math.Pi/180); math.Abs(got) > 0.001 {
+		t.Errorf("ToEvaAngle with offset = %f, want 0", got)
+	}
+
+	// Mirrored: the array's left is the head's right
+	SetMounting(Mounting{Mirror: true})
+	if got := ToEvaAngle(math.Pi / 4); math.Abs(got+math.Pi/4) > 0.001 {
+		t.Errorf("ToEvaAngle mirrored = %f, want %f", got, -math.Pi/4)
+	}
+
+	// Results stay normalized and round trip
+	SetMounting(Mounting{Offset: 3, Mirror: true})
+	for _, angle := range []float64{0, math.Pi / 4, math.Pi / 2, math.Pi} {
+		eva := ToEvaAngle(angle)
+		if eva < -math.Pi || eva > math.Pi {
+			t.Errorf("ToEvaAngle(%f) = %f, not normalized", angle, eva)
+		}
+		if back := NormalizeAngle(FromEvaAngle(eva) - angle); math.Abs(back) > 0.001 {
+			t.Errorf("round trip failed: %f -> %f -> %f", angle, eva, FromEvaAngle(eva))
+		}
+	}
+}
+
 func TestNormalizeAngle(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -193,3 +222,20 @@ func TestClamp(t *testing.T) {
 	}
 }
 
+
+func TestDominantMic(t *testing.T) {
+	tests := []struct {
+		energy [4]float64
+		want   int
+	}{
+		{[4]float64{0, 0, 0, 0}, -1},
+		{[4]float64{0.1, 0.7, 0.3, 0}, 1},
+		{[4]float64{0, 0, 0, 0.2}, 3},
+	}
+	for _, tt := range tests {
+		r := Reading{SpeechEnergy: tt.energy}
+		if got := r.DominantMic(); got != tt.want {
+			t.Errorf("DominantMic(%v) = %d, want %d", tt.energy, got, tt.want)
+		}
+	}
+}
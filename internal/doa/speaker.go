@@ -0,0 +1,134 @@
+package doa
+
+import (
+	"time"
+)
+
+// SpeakerConfig configures speaker re-identification
+type SpeakerConfig struct {
+	GateAngle   float64       // Max distance from a speaker's spot for an utterance to be theirs (radians)
+	Alpha       float64       // How far each utterance moves the speaker's spot toward its angle (0-1)
+	Timeout     time.Duration // Speakers not heard for this long are forgotten
+	MaxSpeakers int           // The least recently heard speaker is forgotten beyond this
+}
+
+// DefaultSpeakerConfig returns sensible defaults
+func DefaultSpeakerConfig() SpeakerConfig {
+	return SpeakerConfig{
+		GateAngle:   0.35, // ~20°
+		Alpha:       0.3,
+		Timeout:     5 * time.Minute,
+		MaxSpeakers: 8,
+	}
+}
+
+// Speaker is someone who has spoken from roughly the same spot
+type Speaker struct {
+	ID        int       `json:"id"`
+	Angle     float64   `json:"angle"` // Where they usually speak from (radians)
+	Turns     int       `json:"turns"` // Utterances attributed to them
+	FirstSeen time.Time `json:"first_seen"`
+	LastHeard time.Time `json:"last_heard"`
+}
+
+// SpeakerRegistry gives utterances from the same spot a stable speaker ID
+// across turns. Unlike the per-reading source tracks, which die after a few
+// seconds of silence, speakers are remembered until Timeout so a
+// conversation can be followed turn by turn.
+type SpeakerRegistry struct {
+	speakers *angleClusters
+	current  int // Speaker of the current or latest utterance (0 = none yet)
+}
+
+// NewSpeakerRegistry creates a speaker registry
+func NewSpeakerRegistry(cfg SpeakerConfig) *SpeakerRegistry {
+	r := &SpeakerRegistry{speakers: newAngleClusters()}
+	r.SetConfig(cfg)
+	return r
+}
+
+// SetConfig changes matching and expiry; known speakers are kept
+func (r *SpeakerRegistry) SetConfig(cfg SpeakerConfig) {
+	def := DefaultSpeakerConfig()
+	if cfg.GateAngle <= 0 {
+		cfg.GateAngle = def.GateAngle
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = def.Alpha
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxSpeakers <= 0 {
+		cfg.MaxSpeakers = def.MaxSpeakers
+	}
+	r.speakers.tune(cfg.GateAngle, cfg.Alpha, cfg.Timeout, cfg.MaxSpeakers)
+}
+
+// Current returns the speaker of the current or latest utterance (0 until
+// someone speaks)
+func (r *SpeakerRegistry) Current() int {
+	return r.current
+}
+
+// Identify attributes an utterance starting at angle to the nearest known
+// speaker within the gate, or to a new speaker, and returns their ID
+func (r *SpeakerRegistry) Identify(angle float64, now time.Time) int {
+	r.speakers.prune(now)
+	if r.speakers.find(r.current) == nil {
+		r.current = 0
+	}
+
+	s := r.speakers.nearest(angle)
+	if s == nil {
+		s = r.speakers.add(angle, now)
+	}
+
+	s.hits++
+	s.lastSeen = now
+	r.current = s.id
+	return s.id
+}
+
+// Observe refines a speaker's spot with a finished utterance's mean angle
+func (r *SpeakerRegistry) Observe(id int, angle float64, now time.Time) {
+	if s := r.speakers.find(id); s != nil {
+		r.speakers.blend(s, angle)
+		s.lastSeen = now
+	}
+}
+
+// Speakers returns the remembered speakers, most recently heard first
+func (r *SpeakerRegistry) Speakers(now time.Time) []Speaker {
+	live := r.speakers.live(now)
+	speakers := make([]Speaker, len(live))
+	for i, s := range live {
+		speakers[i] = Speaker{
+			ID:        s.id,
+			Angle:     s.angle,
+			Turns:     s.hits,
+			FirstSeen: s.firstSeen,
+			LastHeard: s.lastSeen,
+		}
+	}
+	return speakers
+}
+
+// restore replaces the remembered speakers with saved ones still within the
+// timeout, keeping their IDs
+func (r *SpeakerRegistry) restore(speakers []Speaker, current int, now time.Time) {
+	r.speakers.reset()
+	r.current = 0
+	for _, s := range speakers {
+		restored := r.speakers.restore(angleCluster{
+			id:        s.ID,
+			angle:     s.Angle,
+			hits:      s.Turns,
+			firstSeen: s.FirstSeen,
+			lastSeen:  s.LastHeard,
+		}, now)
+		if restored && s.ID == current {
+			r.current = current
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package doa
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSpeakerRegistry_ReidentifiesAcrossTurns(t *testing.T) {
+	r := NewSpeakerRegistry(SpeakerConfig{GateAngle: deg(20), Timeout: time.Minute})
+	now := time.Now()
+
+	alice := r.Identify(deg(30), now)
+	bob := r.Identify(deg(-60), now.Add(5*time.Second))
+	if alice == bob {
+		t.Fatalf("speakers 90° apart share ID %d", alice)
+	}
+
+	// Alice speaks again a little off her first spot, long after any source
+	// track would have expired
+	if id := r.Identify(deg(38), now.Add(30*time.Second)); id != alice {
+		t.Errorf("Alice's next turn got ID %d, want %d", id, alice)
+	}
+	if r.Current() != alice {
+		t.Errorf("Current() = %d, want %d", r.Current(), alice)
+	}
+	r.Observe(alice, deg(38), now.Add(31*time.Second))
+
+	speakers := r.Speakers(now.Add(31 * time.Second))
+	if len(speakers) != 2 || speakers[0].ID != alice || speakers[0].Turns != 2 {
+		t.Fatalf("Speakers() = %+v, want Alice first with 2 turns", speakers)
+	}
+	if a := speakers[0].Angle; a <= deg(30) || a >= deg(38) {
+		t.Errorf("Alice's spot = %.1f°, want between her turns", a/deg(1))
+	}
+
+	// Bob has been quiet past the timeout, so his spot is free again
+	later := now.Add(90 * time.Second)
+	if id := r.Identify(deg(-60), later); id == bob || id == alice {
+		t.Errorf("returning after timeout got ID %d, want a new speaker", id)
+	}
+}
+
+func TestSpeakerRegistry_MaxSpeakers(t *testing.T) {
+	r := NewSpeakerRegistry(SpeakerConfig{GateAngle: deg(10), MaxSpeakers: 2})
+	now := time.Now()
+
+	first := r.Identify(deg(0), now)
+	r.Identify(deg(90), now.Add(time.Second))
+	r.Identify(deg(180), now.Add(2*time.Second))
+
+	speakers := r.Speakers(now.Add(2 * time.Second))
+	if len(speakers) != 2 {
+		t.Fatalf("Speakers() = %+v, want 2", speakers)
+	}
+	for _, s := range speakers {
+		if s.ID == first {
+			t.Errorf("least recently heard speaker %d was kept", first)
+		}
+	}
+}
+
+func TestTracker_UtteranceSpeakerIDs(t *testing.T) {
+	source := NewMockSource()
+
+	cfg := DefaultTrackerConfig()
+	cfg.PollInterval = 10 * time.Millisecond
+	cfg.Utterance.Hangover = 30 * time.Millisecond
+	tracker := NewTracker(source, cfg, nil)
+	ch := tracker.SubscribeUtterances()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	// Two turns from the left with someone on the right in between
+	var ends []UtteranceEvent
+	for _, angle := range []float64{deg(90), deg(-90), deg(95)} {
+		source.SetAngle(angle)
+		source.SetSpeaking(true)
+		time.Sleep(40 * time.Millisecond)
+		source.SetSpeaking(false)
+
+		timeout := time.After(time.Second)
+	wait:
+		for {
+			select {
+			case ev := <-ch:
+				if ev.Type == UtteranceEnd {
+					ends = append(ends, ev)
+					break wait
+				}
+			case <-timeout:
+				t.Fatalf("no end event for turn at %.0f°", angle/deg(1))
+			}
+		}
+	}
+
+	if ends[0].SpeakerID == 0 || ends[0].SpeakerID != ends[2].SpeakerID || ends[1].SpeakerID == ends[0].SpeakerID {
+		t.Errorf("speaker IDs = %d, %d, %d; want the left turns to share one", ends[0].SpeakerID, ends[1].SpeakerID, ends[2].SpeakerID)
+	}
+	if got := tracker.GetLatest().SpeakerID; got != ends[2].SpeakerID {
+		t.Errorf("result speaker_id = %d, want %d", got, ends[2].SpeakerID)
+	}
+	if n := len(tracker.GetSpeakers()); n != 2 {
+		t.Errorf("GetSpeakers() has %d speakers, want 2", n)
+	}
+}
@@ -2,9 +2,15 @@ package doa
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/teslashibe/go-eva/internal/bus"
 )
 
 // TrackerConfig configures the DOA tracker
@@ -12,9 +18,20 @@ type TrackerConfig struct {
 	PollInterval     time.Duration
 	SpeakingLatchDur time.Duration
 	EMAAlpha         float64
-	HistorySize      int
 
-	Confidence ConfidenceConfig
+	Confidence  ConfidenceConfig
+	MultiSource MultiSourceConfig
+	Smoothing   SmoothingConfig // Mode "" uses EMA with EMAAlpha
+	Utterance   UtteranceConfig
+	Zones       ZoneConfig
+	Speakers    SpeakerConfig
+	Noise       NoiseConfig
+	Echo        EchoConfig
+	Outliers    OutlierConfig
+	IdlePoll    IdlePollConfig
+
+	// Run in order on every result before it is stored and published
+	Processors []Processor
 }
 
 // ConfidenceConfig configures confidence scoring
@@ -30,12 +47,19 @@ func DefaultTrackerConfig() TrackerConfig {
 		PollInterval:     50 * time.Millisecond, // 20Hz
 		SpeakingLatchDur: 500 * time.Millisecond,
 		EMAAlpha:         0.3,
-		HistorySize:      100,
 		Confidence: ConfidenceConfig{
 			Base:           0.3,
 			SpeakingBonus:  0.4,
 			StabilityBonus: 0.2,
 		},
+		MultiSource: DefaultMultiSourceConfig(),
+		Utterance:   DefaultUtteranceConfig(),
+		Zones:       DefaultZoneConfig(),
+		Speakers:    DefaultSpeakerConfig(),
+		Noise:       DefaultNoiseConfig(),
+		Echo:        DefaultEchoConfig(),
+		Outliers:    DefaultOutlierConfig(),
+		IdlePoll:    DefaultIdlePollConfig(),
 	}
 }
 
@@ -46,6 +70,18 @@ type Result struct {
 	SmoothedAngle   float64 `json:"smoothed_angle"`
 	Confidence      float64 `json:"confidence"`
 	SpeakingLatched bool    `json:"speaking_latched"`
+	RawSpeaking     bool    `json:"raw_speaking"`          // Hardware speech flag before noise and echo gating
+	EchoActive      bool    `json:"echo_active,omitempty"` // Robot's own playback may be heard
+	Outlier         bool    `json:"outlier,omitempty"`     // Angle rejected as a glitch; the smoothed angles held
+	Zone            string  `json:"zone,omitempty"`        // Zone of the latest speech
+	SpeakerID       int     `json:"speaker_id,omitempty"`  // Speaker of the current or latest utterance
+
+	// World frame: angles relative to where the robot faced at body yaw 0,
+	// so a speaker who stays put keeps their angle while the body turns.
+	// Without a body yaw source WorldAngle is SmoothedAngle.
+	WorldAngle float64 `json:"world_angle"`
+	BodyYaw    float64 `json:"body_yaw"`              // Body rotation compensated for (radians)
+	WorldFrame bool    `json:"world_frame,omitempty"` // BodyYaw was known
 
 	// Estimated position (from energy-based distance + angle)
 	EstX float64 `json:"est_x"` // Forward distance (meters)
@@ -58,25 +94,65 @@ type Tracker struct {
 	cfg    TrackerConfig
 	logger *slog.Logger
 
-	mu      sync.RWMutex
-	latest  Result
-	history []Result
+	mu     sync.RWMutex
+	latest Result
+	recent []float64 // Last stabilityWindow smoothed angles, oldest first
+
+	// Angle smoothing (guarded by mu)
+	smoother Smoother
+
+	// World-frame angle: body yaw source and its own smoother, so the
+	// rotation is removed before smoothing rather than after (guarded by mu)
+	bodyYaw       func() (float64, bool)
+	worldSmoother Smoother
 
 	// Speaking latch state
 	speakingLatchedAt time.Time
 
+	// Adaptive polling: last gated speech and whether polling slowed down
+	// (guarded by mu)
+	lastSpeech time.Time
+	idle       bool
+
+	// Per-speaker tracks
+	sources *MultiSourceTracker
+
+	// Utterance segmentation (guarded by mu)
+	utterances *UtteranceSegmenter
+
+	// Zone mapping (guarded by mu)
+	zones *ZoneMapper
+
+	// Speaker re-identification across utterances (guarded by mu)
+	speakers *SpeakerRegistry
+
+	// Noise floor and speech gating (guarded by mu)
+	noise *NoiseFloor
+
+	// Own-playback echo suppression and barge-in (guarded by mu)
+	echo *EchoGate
+
+	// Single-poll angle glitch rejection (guarded by mu)
+	outliers *OutlierFilter
+
 	// Metrics
 	pollCount      int64
 	pollErrorCount int64
 	totalLatencyMs int64
+	pollLatency    prometheus.Histogram
 
-	// Lifecycle
-	cancel context.CancelFunc
-	done   chan struct{}
+	// Lifecycle (cancel and stopped guarded by mu)
+	cancel   context.CancelFunc
+	stopped  bool
+	done     chan struct{}
+	interval chan time.Duration // Poll interval changes for Run
 
 	// Subscribers for real-time updates
 	subsMu sync.RWMutex
 	subs   map[chan Result]struct{}
+	uSubs  map[chan UtteranceEvent]struct{}
+	zSubs  map[chan ZoneEvent]struct{}
+	bus    atomic.Pointer[bus.Bus]
 }
 
 // NewTracker creates a new DOA tracker
@@ -85,28 +161,70 @@ func NewTracker(source Source, cfg TrackerConfig, logger *slog.Logger) *Tracker
 		logger = slog.Default()
 	}
 
+	if cfg.Smoothing.EMAAlpha == 0 {
+		cfg.Smoothing.EMAAlpha = cfg.EMAAlpha
+	}
+	smoother, err := NewSmoother(cfg.Smoothing)
+	if err != nil {
+		logger.Warn("invalid smoothing mode, using ema", "error", err)
+		smoother = &EMASmoother{Alpha: cfg.Smoothing.EMAAlpha}
+	}
+	worldSmoother, err := NewSmoother(cfg.Smoothing)
+	if err != nil {
+		worldSmoother = &EMASmoother{Alpha: cfg.Smoothing.EMAAlpha}
+	}
+
+	pollLatency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "go_eva_doa_poll_duration_seconds",
+		Help:    "DOA source read latency",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12), // 0.5ms to 1s
+	})
+
 	return &Tracker{
-		source:  source,
-		cfg:     cfg,
-		logger:  logger,
-		history: make([]Result, 0, cfg.HistorySize),
-		done:    make(chan struct{}),
-		subs:    make(map[chan Result]struct{}),
+		source:        source,
+		cfg:           cfg,
+		logger:        logger,
+		smoother:      smoother,
+		worldSmoother: worldSmoother,
+		sources:       NewMultiSourceTracker(cfg.MultiSource),
+		utterances:    NewUtteranceSegmenter(cfg.Utterance),
+		zones:         NewZoneMapper(cfg.Zones),
+		speakers:      NewSpeakerRegistry(cfg.Speakers),
+		noise:         NewNoiseFloor(cfg.Noise),
+		echo:          NewEchoGate(cfg.Echo),
+		outliers:      NewOutlierFilter(cfg.Outliers),
+		done:          make(chan struct{}),
+		interval:      make(chan time.Duration, 1),
+		subs:          make(map[chan Result]struct{}),
+		uSubs:         make(map[chan UtteranceEvent]struct{}),
+		zSubs:         make(map[chan ZoneEvent]struct{}),
+		pollLatency:   pollLatency,
 	}
 }
 
 // Run starts the polling loop (blocking, use goroutine)
 func (t *Tracker) Run(ctx context.Context) error {
-	ctx, t.cancel = context.WithCancel(ctx)
 	defer close(t.done)
 
-	ticker := time.NewTicker(t.cfg.PollInterval)
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return context.Canceled
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	defer t.cancel()
+	cfg := t.cfg
+	t.lastSpeech = time.Now() // Start at the full rate
+	t.mu.Unlock()
+
+	interval := cfg.PollInterval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	t.logger.Info("tracker started",
-		"poll_interval", t.cfg.PollInterval,
-		"ema_alpha", t.cfg.EMAAlpha,
-		"speaking_latch", t.cfg.SpeakingLatchDur,
+		"poll_interval", cfg.PollInterval,
+		"ema_alpha", cfg.EMAAlpha,
+		"speaking_latch", cfg.SpeakingLatchDur,
 		"source", t.source.Name(),
 	)
 
@@ -118,14 +236,73 @@ func (t *Tracker) Run(ctx context.Context) error {
 				"errors", t.pollErrorCount,
 			)
 			return ctx.Err()
+		case <-t.interval:
+			// PollInterval changed; picked up below
 		case <-ticker.C:
 			if err := t.poll(ctx); err != nil {
 				t.logger.Warn("poll failed", "error", err)
 			}
 		}
+
+		// Slow down after prolonged silence, speed up on speech
+		t.mu.Lock()
+		next := t.pollIntervalLocked(time.Now())
+		idle := t.idle
+		t.mu.Unlock()
+		if next != interval {
+			ticker.Reset(next)
+			interval = next
+			t.logger.Debug("tracker poll rate changed", "interval", next, "idle", idle)
+		}
 	}
 }
 
+// UpdateConfig applies tuning changes to a running tracker. Multi-source
+// settings and processors are fixed at construction and are left unchanged.
+func (t *Tracker) UpdateConfig(cfg TrackerConfig) error {
+	if cfg.Smoothing.EMAAlpha == 0 {
+		cfg.Smoothing.EMAAlpha = cfg.EMAAlpha
+	}
+
+	t.mu.Lock()
+	old := t.cfg
+	if cfg.Smoothing != old.Smoothing {
+		smoother, err := NewSmoother(cfg.Smoothing)
+		if err != nil {
+			t.mu.Unlock()
+			return fmt.Errorf("smoothing: %w", err)
+		}
+		t.smoother = smoother
+		t.worldSmoother, _ = NewSmoother(cfg.Smoothing)
+	}
+	cfg.MultiSource = old.MultiSource
+	cfg.Processors = old.Processors
+	t.cfg = cfg
+	t.utterances.SetConfig(cfg.Utterance)
+	t.zones.SetConfig(cfg.Zones)
+	t.speakers.SetConfig(cfg.Speakers)
+	t.noise.SetConfig(cfg.Noise)
+	t.echo.SetConfig(cfg.Echo)
+	t.outliers.SetConfig(cfg.Outliers)
+	t.mu.Unlock()
+
+	if cfg.PollInterval > 0 && (cfg.PollInterval != old.PollInterval || cfg.IdlePoll != old.IdlePoll) {
+		// Replace any interval Run hasn't picked up yet
+		select {
+		case <-t.interval:
+		default:
+		}
+		t.interval <- cfg.PollInterval
+	}
+
+	t.logger.Info("tracker reconfigured",
+		"poll_interval", cfg.PollInterval,
+		"ema_alpha", cfg.EMAAlpha,
+		"smoothing", cfg.Smoothing.Mode,
+	)
+	return nil
+}
+
 func (t *Tracker) poll(ctx context.Context) error {
 	start := time.Now()
 
@@ -137,6 +314,7 @@ func (t *Tracker) poll(ctx context.Context) error {
 		return err
 	}
 
+	t.pollLatency.Observe(time.Since(start).Seconds())
 	latencyMs := time.Since(start).Milliseconds()
 	reading.LatencyMs = latencyMs
 
@@ -146,15 +324,57 @@ func (t *Tracker) poll(ctx context.Context) error {
 	t.pollCount++
 	t.totalLatencyMs += latencyMs
 
+	// Gate the hardware speech flag: while the speaker plays only barge-in
+	// counts, otherwise speech needs energy above the noise floor
+	rawSpeaking := reading.Speaking
+	echoActive := t.echo.Active(time.Now())
+	var bargeIn bool
+	if echoActive {
+		reading.Speaking, bargeIn = t.echo.Update(reading)
+	} else {
+		reading.Speaking = t.noise.Update(reading)
+	}
+
 	// Latch speaking flag
 	speakingLatched := t.updateSpeakingLatch(reading.Speaking)
+	if reading.Speaking {
+		t.lastSpeech = time.Now()
+	}
+
+	// Raw angles feed the per-speaker tracks before any smoothing
+	t.sources.Update(reading.Angle, reading.Speaking, time.Now())
 
-	// Smooth angle with EMA
-	smoothedAngle := reading.Angle
-	if len(t.history) > 0 {
-		prev := t.latest.SmoothedAngle
-		smoothedAngle = t.cfg.EMAAlpha*reading.Angle + (1-t.cfg.EMAAlpha)*prev
+	// Smooth angle (EMA, Kalman or median), holding the smoothed angles
+	// through a glitch the next readings don't confirm
+	measuredAt := reading.Timestamp
+	if measuredAt.IsZero() {
+		measuredAt = time.Now()
 	}
+	var outlier, jump bool
+	if t.pollCount > 1 {
+		var accept bool
+		accept, jump = t.outliers.Accept(reading.Angle, t.predictLocked(measuredAt))
+		outlier = !accept
+	}
+	var (
+		smoothedAngle, worldAngle, bodyYaw float64
+		worldFrame                         bool
+	)
+	if outlier {
+		smoothedAngle, worldAngle = t.latest.SmoothedAngle, t.latest.WorldAngle
+		bodyYaw, worldFrame = t.latest.BodyYaw, t.latest.WorldFrame
+	} else {
+		if jump {
+			// The speaker really moved: start over from where they are now
+			t.smoother.Reset()
+			t.worldSmoother.Reset()
+		}
+		smoothedAngle = t.smoother.Update(reading.Angle, measuredAt)
+		worldAngle, bodyYaw, worldFrame = t.worldAngleLocked(reading.Angle, smoothedAngle, measuredAt)
+	}
+
+	utterances := t.utterances.Update(reading, measuredAt)
+	t.attributeSpeakers(utterances)
 
 	// Calculate confidence
 	confidence := t.calculateConfidence(speakingLatched, smoothedAngle)
@@ -168,15 +388,53 @@ func (t *Tracker) poll(ctx context.Context) error {
 		SmoothedAngle:   smoothedAngle,
 		Confidence:      confidence,
 		SpeakingLatched: speakingLatched,
+		RawSpeaking:     rawSpeaking,
+		EchoActive:      echoActive,
+		Outlier:         outlier,
+		SpeakerID:       t.speakers.Current(),
+		WorldAngle:      worldAngle,
+		BodyYaw:         bodyYaw,
+		WorldFrame:      worldFrame,
 		EstX:            estX,
 		EstY:            estY,
 	}
+	for _, p := range t.cfg.Processors {
+		result = p.Process(result)
+	}
+
+	// Zones follow the processed angle, so the zone and its transitions
+	// agree with what processors made of the reading
+	zoneAngle := result.SmoothedAngle
+	if t.cfg.Zones.World && result.WorldFrame {
+		zoneAngle = result.WorldAngle
+	}
+	zoneEvent := t.zones.Update(zoneAngle, result.SpeakingLatched, measuredAt)
+	result.Zone = t.zones.Current()
 
 	t.latest = result
-	t.appendHistory(result)
+	t.recent = append(t.recent, result.SmoothedAngle)
+	if len(t.recent) > stabilityWindow {
+		t.recent = t.recent[1:]
+	}
 
 	// Notify subscribers (non-blocking)
 	t.notifySubscribers(result)
+	for _, ev := range utterances {
+		t.notifyUtterance(ev)
+	}
+	if zoneEvent != nil {
+		t.notifyZone(*zoneEvent)
+	}
+	if bargeIn {
+		if b := t.bus.Load(); b != nil {
+			bus.Publish(b, TopicBargeIn, BargeInEvent{
+				Angle:     reading.Angle,
+				Energy:    reading.TotalEnergy,
+				EchoLevel: t.echo.Level(),
+				Timestamp: measuredAt,
+			})
+		}
+	}
 
 	if speakingLatched && t.pollCount%10 == 0 {
 		t.logger.Debug("doa poll",
@@ -193,6 +451,37 @@ func (t *Tracker) poll(ctx context.Context) error {
 	return nil
 }
 
+// worldAngleLocked adds the body yaw to the raw angle and smooths the sum,
+// so turning the body doesn't drag the smoothed world angle through the
+// filter's lag. Without a known body yaw it returns the robot-relative
+// smoothed angle. Caller holds mu.
+func (t *Tracker) worldAngleLocked(angle, smoothed float64, at time.Time) (world, bodyYaw float64, ok bool) {
+	if t.bodyYaw != nil {
+		bodyYaw, ok = t.bodyYaw()
+	}
+	if !ok {
+		t.worldSmoother.Reset()
+		return smoothed, 0, false
+	}
+	return t.worldSmoother.Update(NormalizeAngle(angle+bodyYaw), at), bodyYaw, true
+}
+
+// attributeSpeakers tags utterance boundaries with their speaker: a start is
+// matched to a known speaker by its onset angle, and an end refines that
+// speaker's spot with the utterance's mean angle (caller holds mu)
+func (t *Tracker) attributeSpeakers(events []UtteranceEvent) {
+	for i := range events {
+		ev := &events[i]
+		switch ev.Type {
+		case UtteranceStart:
+			ev.SpeakerID = t.speakers.Identify(ev.AvgAngle, ev.Start)
+		case UtteranceEnd:
+			ev.SpeakerID = t.speakers.Current()
+			t.speakers.Observe(ev.SpeakerID, ev.AvgAngle, ev.End)
+		}
+	}
+}
+
 func (t *Tracker) updateSpeakingLatch(rawSpeaking bool) bool {
 	now := time.Now()
 
@@ -209,6 +498,10 @@ func (t *Tracker) updateSpeakingLatch(rawSpeaking bool) bool {
 	return false
 }
 
+// stabilityWindow is how many recent smoothed angles must agree for the
+// confidence stability bonus
+const stabilityWindow = 5
+
 func (t *Tracker) calculateConfidence(speaking bool, angle float64) float64 {
 	conf := t.cfg.Confidence.Base
 
@@ -216,14 +509,14 @@ func (t *Tracker) calculateConfidence(speaking bool, angle float64) float64 {
 		conf += t.cfg.Confidence.SpeakingBonus
 	}
 
-	// Check angle stability over last 5 readings
-	if len(t.history) >= 5 {
+	// Check angle stability over the last readings (shortest-arc deviations)
+	if len(t.recent) >= stabilityWindow {
 		var variance float64
-		for i := len(t.history) - 5; i < len(t.history); i++ {
-			diff := t.history[i].SmoothedAngle - angle
+		for _, prev := range t.recent {
+			diff := NormalizeAngle(prev - angle)
 			variance += diff * diff
 		}
-		variance /= 5
+		variance /= stabilityWindow
 
 		if variance < 0.01 {
 			conf += t.cfg.Confidence.StabilityBonus
@@ -233,17 +526,6 @@ func (t *Tracker) calculateConfidence(speaking bool, angle float64) float64 {
 	return Clamp(conf, 0, 1)
 }
 
-func (t *Tracker) appendHistory(result Result) {
-	t.history = append(t.history, result)
-
-	// Trim history
-	if len(t.history) > t.cfg.HistorySize {
-		// Shift instead of slice to avoid memory leak
-		copy(t.history, t.history[1:])
-		t.history = t.history[:t.cfg.HistorySize]
-	}
-}
-
 func (t *Tracker) notifySubscribers(result Result) {
 	t.subsMu.RLock()
 	defer t.subsMu.RUnlock()
@@ -255,8 +537,53 @@ func (t *Tracker) notifySubscribers(result Result) {
 			// Drop if subscriber is slow
 		}
 	}
+
+	if b := t.bus.Load(); b != nil {
+		bus.Publish(b, TopicResults, result)
+	}
 }
 
+func (t *Tracker) notifyUtterance(ev UtteranceEvent) {
+	t.subsMu.RLock()
+	defer t.subsMu.RUnlock()
+
+	for ch := range t.uSubs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+
+	if b := t.bus.Load(); b != nil {
+		bus.Publish(b, TopicUtterances, ev)
+	}
+}
+
+func (t *Tracker) notifyZone(ev ZoneEvent) {
+	t.subsMu.RLock()
+	defer t.subsMu.RUnlock()
+
+	for ch := range t.zSubs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+
+	if b := t.bus.Load(); b != nil {
+		bus.Publish(b, TopicZones, ev)
+	}
+}
+
+// PublishTo publishes every result, utterance and zone transition on the
+// event bus as well as to subscriber channels
+func (t *Tracker) PublishTo(b *bus.Bus) {
+	t.bus.Store(b)
+}
+
+// TopicResults carries every tracker result on the event bus
+var TopicResults = bus.NewTopic[Result]("doa.result")
+
 // Subscribe returns a channel that receives DOA updates
 func (t *Tracker) Subscribe() chan Result {
 	ch := make(chan Result, 10) // Buffer to avoid blocking
@@ -278,6 +605,65 @@ func (t *Tracker) Unsubscribe(ch chan Result) {
 	t.subsMu.Unlock()
 }
 
+// SubscribeUtterances returns a channel that receives utterance boundaries
+func (t *Tracker) SubscribeUtterances() chan UtteranceEvent {
+	ch := make(chan UtteranceEvent, 10)
+
+	t.subsMu.Lock()
+	t.uSubs[ch] = struct{}{}
+	t.subsMu.Unlock()
+
+	return ch
+}
+
+// UnsubscribeUtterances removes an utterance subscriber
+func (t *Tracker) UnsubscribeUtterances(ch chan UtteranceEvent) {
+	t.subsMu.Lock()
+	if _, exists := t.uSubs[ch]; exists {
+		delete(t.uSubs, ch)
+		close(ch)
+	}
+	t.subsMu.Unlock()
+}
+
+// SubscribeZones returns a channel that receives zone transitions
+func (t *Tracker) SubscribeZones() chan ZoneEvent {
+	ch := make(chan ZoneEvent, 10)
+
+	t.subsMu.Lock()
+	t.zSubs[ch] = struct{}{}
+	t.subsMu.Unlock()
+
+	return ch
+}
+
+// UnsubscribeZones removes a zone subscriber
+func (t *Tracker) UnsubscribeZones(ch chan ZoneEvent) {
+	t.subsMu.Lock()
+	if _, exists := t.zSubs[ch]; exists {
+		delete(t.zSubs, ch)
+		close(ch)
+	}
+	t.subsMu.Unlock()
+}
+
+// SetPlayback tells the tracker whether the robot's speaker is playing, so
+// its own voice is not tracked as a talker
+func (t *Tracker) SetPlayback(active bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.echo.SetPlayback(active, time.Now())
+}
+
+// SetBodyYaw supplies the robot's body yaw (radians, + = left) for the
+// world-frame angle, and whether it is known
+func (t *Tracker) SetBodyYaw(fn func() (float64, bool)) {
+	t.mu.Lock()
+	t.bodyYaw = fn
+	t.worldSmoother.Reset()
+	t.mu.Unlock()
+}
+
 // GetLatest returns the most recent DOA result
 func (t *Tracker) GetLatest() Result {
 	t.mu.RLock()
@@ -285,6 +671,31 @@ func (t *Tracker) GetLatest() Result {
 	return t.latest
 }
 
+// GetSources returns the currently active sound sources, most recent first
+func (t *Tracker) GetSources() []TrackedSource {
+	return t.sources.Sources(time.Now())
+}
+
+// GetSpeakers returns the speakers remembered across utterances, most
+// recently heard first
+func (t *Tracker) GetSpeakers() []Speaker {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.speakers.Speakers(time.Now())
+}
+
+// PredictAngle returns the expected angle at time at. Smoothers that model
+// angular velocity (Kalman) extrapolate; others return the latest smoothed angle.
+func (t *Tracker) PredictAngle(at time.Time) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if p, ok := t.smoother.(Predictor); ok && len(t.recent) > 0 {
+		return p.Predict(at)
+	}
+	return t.latest.SmoothedAngle
+}
+
 // GetTarget returns the current target angle if confidence is high enough
 func (t *Tracker) GetTarget() (angle float64, confidence float64, ok bool) {
 	t.mu.RLock()
@@ -297,6 +708,11 @@ func (t *Tracker) GetTarget() (angle float64, confidence float64, ok bool) {
 	return t.latest.SmoothedAngle, t.latest.Confidence, true
 }
 
+// Source returns the tracker's DOA source
+func (t *Tracker) Source() Source {
+	return t.source
+}
+
 // Stats returns tracker statistics
 func (t *Tracker) Stats() TrackerStats {
 	t.mu.RLock()
@@ -311,32 +727,45 @@ func (t *Tracker) Stats() TrackerStats {
 		PollCount:         t.pollCount,
 		ErrorCount:        t.pollErrorCount,
 		AvgLatencyMs:      avgLatency,
-		HistorySize:       len(t.history),
 		SubscriberCount:   len(t.subs),
 		SourceHealthy:     t.source.Healthy(),
 		SpeakingLatched:   t.latest.SpeakingLatched,
+		InUtterance:       t.utterances.Active(),
 		CurrentAngle:      t.latest.SmoothedAngle,
 		CurrentConfidence: t.latest.Confidence,
+		Noise:             t.noise.Stats(),
+		Echo:              t.echo.Stats(time.Now()),
+		Outliers:          t.outliers.Stats(),
+		Idle:              t.idle,
 	}
 }
 
 // TrackerStats contains tracker statistics
 type TrackerStats struct {
-	PollCount         int64   `json:"poll_count"`
-	ErrorCount        int64   `json:"error_count"`
-	AvgLatencyMs      float64 `json:"avg_latency_ms"`
-	HistorySize       int     `json:"history_size"`
-	SubscriberCount   int     `json:"subscriber_count"`
-	SourceHealthy     bool    `json:"source_healthy"`
-	SpeakingLatched   bool    `json:"speaking_latched"`
-	CurrentAngle      float64 `json:"current_angle"`
-	CurrentConfidence float64 `json:"current_confidence"`
+	PollCount         int64        `json:"poll_count"`
+	ErrorCount        int64        `json:"error_count"`
+	AvgLatencyMs      float64      `json:"avg_latency_ms"`
+	SubscriberCount   int          `json:"subscriber_count"`
+	SourceHealthy     bool         `json:"source_healthy"`
+	SpeakingLatched   bool         `json:"speaking_latched"`
+	InUtterance       bool         `json:"in_utterance"`
+	CurrentAngle      float64      `json:"current_angle"`
+	CurrentConfidence float64      `json:"current_confidence"`
+	Noise             NoiseStats   `json:"noise"`
+	Echo              EchoStats    `json:"echo"`
+	Outliers          OutlierStats `json:"outliers"`
+	Idle              bool         `json:"idle"` // Polling at the idle rate after prolonged silence
 }
 
 // Stop stops the tracker gracefully
 func (t *Tracker) Stop() {
-	if t.cancel != nil {
-		t.cancel()
+	t.mu.Lock()
+	t.stopped = true
+	cancel := t.cancel
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
 		<-t.done
 	}
 
@@ -346,5 +775,13 @@ func (t *Tracker) Stop() {
 		close(ch)
 		delete(t.subs, ch)
 	}
+	for ch := range t.uSubs {
+		close(ch)
+		delete(t.uSubs, ch)
+	}
+	for ch := range t.zSubs {
+		close(ch)
+		delete(t.zSubs, ch)
+	}
 	t.subsMu.Unlock()
 }
@@ -3,6 +3,7 @@ package doa
 import (
 	"context"
 	"log/slog"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -78,7 +79,6 @@ func TestTracker_BasicPolling(t *testing.T) {
 		PollInterval:     10 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.3,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base:           0.3,
 			SpeakingBonus:  0.4,
@@ -119,7 +119,6 @@ func TestTracker_SpeakingLatch(t *testing.T) {
 		PollInterval:     10 * time.Millisecond,
 		SpeakingLatchDur: 50 * time.Millisecond, // Short latch for testing
 		EMAAlpha:         0.5,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base:           0.3,
 			SpeakingBonus:  0.4,
@@ -172,7 +171,6 @@ func TestTracker_EMASmoothing(t *testing.T) {
 		PollInterval:     5 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.5, // 50% new, 50% old
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base:           0.3,
 			SpeakingBonus:  0.4,
@@ -215,7 +213,6 @@ func TestTracker_Confidence(t *testing.T) {
 		PollInterval:     5 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.5,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base:           0.3,
 			SpeakingBonus:  0.4,
@@ -252,6 +249,20 @@ func TestTracker_Confidence(t *testing.T) {
 	tracker.Stop()
 }
 
+func TestTracker_ConfidenceWrapAround(t *testing.T) {
+	cfg := DefaultTrackerConfig()
+	tracker := NewTracker(NewMockSource(), cfg, slog.Default())
+
+	// Stable speaker behind the robot: angles jitter across ±π
+	tracker.recent = []float64{3.13, -3.13, 3.12, -3.14, 3.13}
+
+	got := tracker.calculateConfidence(false, 3.13)
+	want := Clamp(cfg.Confidence.Base+cfg.Confidence.StabilityBonus, 0, 1)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected stability bonus across ±π (confidence %f), got %f", want, got)
+	}
+}
+
 func TestTracker_Subscribe(t *testing.T) {
 	source := NewMockSource()
 	source.SetAngle(1.57)
@@ -260,7 +271,6 @@ func TestTracker_Subscribe(t *testing.T) {
 		PollInterval:     10 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.5,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base: 0.3,
 		},
@@ -299,7 +309,6 @@ func TestTracker_Stats(t *testing.T) {
 		PollInterval:     10 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.5,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base: 0.3,
 		},
@@ -336,7 +345,6 @@ func TestTracker_GetTarget(t *testing.T) {
 		PollInterval:     10 * time.Millisecond,
 		SpeakingLatchDur: 100 * time.Millisecond,
 		EMAAlpha:         0.5,
-		HistorySize:      10,
 		Confidence: ConfidenceConfig{
 			Base:          0.3,
 			SpeakingBonus: 0.4,
@@ -388,3 +396,97 @@ func TestDefaultTrackerConfig(t *testing.T) {
 	}
 }
 
+
+func TestTracker_UpdateConfig(t *testing.T) {
+	source := NewMockSource()
+	source.SetAngle(1.57)
+
+	cfg := DefaultTrackerConfig()
+	cfg.PollInterval = time.Hour // Effectively paused until reconfigured
+	tracker := NewTracker(source, cfg, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+	if calls := source.GetCalls(); calls != 0 {
+		t.Fatalf("expected no polls before reconfigure, got %d", calls)
+	}
+
+	next := cfg
+	next.PollInterval = 5 * time.Millisecond
+	next.Smoothing.Mode = "median"
+	next.Smoothing.MedianWindow = 3
+	next.MultiSource.MaxSources = 1 // Fixed at construction
+	if err := tracker.UpdateConfig(next); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if calls := source.GetCalls(); calls < 3 {
+		t.Errorf("expected polling at the new interval, got %d polls", calls)
+	}
+
+	tracker.mu.RLock()
+	_, isMedian := tracker.smoother.(*MedianSmoother)
+	maxSources := tracker.cfg.MultiSource.MaxSources
+	tracker.mu.RUnlock()
+	if !isMedian {
+		t.Error("expected smoother to switch to median")
+	}
+	if maxSources != cfg.MultiSource.MaxSources {
+		t.Errorf("MultiSource.MaxSources = %d, want unchanged %d", maxSources, cfg.MultiSource.MaxSources)
+	}
+
+	bad := next
+	bad.Smoothing.Mode = "bogus"
+	if err := tracker.UpdateConfig(bad); err == nil {
+		t.Error("expected error for unknown smoothing mode")
+	}
+
+	tracker.Stop()
+}
+
+func TestTracker_WorldAngle(t *testing.T) {
+	source := NewMockSource()
+	source.SetSpeaking(true)
+	tracker := NewTracker(source, DefaultTrackerConfig(), nil)
+	ctx := context.Background()
+
+	// Speaker at 0.5 rad (left of front) before anything knows the body yaw
+	source.SetAngle(math.Pi/2 - 0.5)
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r := tracker.GetLatest(); r.WorldFrame || r.WorldAngle != r.SmoothedAngle {
+		t.Errorf("without a body yaw source world_angle should be smoothed_angle, got %+v", r)
+	}
+
+	var bodyYaw float64
+	tracker.SetBodyYaw(func() (float64, bool) { return bodyYaw, true })
+	for range 5 {
+		if err := tracker.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The body turns 0.3 rad toward the speaker, who stays put: the reading
+	// moves to 0.2 rad and the smoothed angle lags behind it, but the world
+	// angle doesn't move
+	bodyYaw = 0.3
+	source.SetAngle(math.Pi/2 - 0.2)
+	if err := tracker.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	r := tracker.GetLatest()
+	if !r.WorldFrame || r.BodyYaw != 0.3 {
+		t.Errorf("WorldFrame = %v, BodyYaw = %v, want true, 0.3", r.WorldFrame, r.BodyYaw)
+	}
+	if math.Abs(r.WorldAngle-0.5) > 1e-9 {
+		t.Errorf("WorldAngle = %v, want 0.5", r.WorldAngle)
+	}
+	if math.Abs(r.SmoothedAngle-0.2) < 0.05 {
+		t.Errorf("SmoothedAngle = %v, should still lag the robot-relative jump", r.SmoothedAngle)
+	}
+}
@@ -0,0 +1,127 @@
+package doa
+
+import (
+	"math"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// UtteranceConfig configures utterance segmentation
+type UtteranceConfig struct {
+	Hangover    time.Duration // Silence that ends an utterance; bridges gaps between words
+	MaxDuration time.Duration // Longer utterances are split (0 = no limit)
+}
+
+// DefaultUtteranceConfig returns sensible defaults
+func DefaultUtteranceConfig() UtteranceConfig {
+	return UtteranceConfig{
+		Hangover:    300 * time.Millisecond,
+		MaxDuration: 15 * time.Second,
+	}
+}
+
+// UtteranceEventType distinguishes the start and end of an utterance
+type UtteranceEventType string
+
+const (
+	UtteranceStart UtteranceEventType = "start"
+	UtteranceEnd   UtteranceEventType = "end"
+)
+
+// UtteranceEvent marks an utterance boundary. Start events carry the onset
+// reading; end events summarize the whole utterance.
+type UtteranceEvent struct {
+	Type       UtteranceEventType `json:"type"`
+	ID         uint64             `json:"id"`
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`         // Last speech reading (end events only)
+	DurationMs int64              `json:"duration_ms"` // Excludes the hangover
+	AvgAngle   float64            `json:"avg_angle"`   // Circular mean of raw angles while speaking (radians)
+	PeakEnergy float64            `json:"peak_energy"` // Highest total speech energy
+	SpeakerID  int                `json:"speaker_id"`  // Stable across turns from the same spot; see SpeakerRegistry
+}
+
+// TopicUtterances carries utterance boundaries on the event bus
+var TopicUtterances = bus.NewTopic[UtteranceEvent]("doa.utterance")
+
+// UtteranceSegmenter groups raw speaking readings into utterances
+type UtteranceSegmenter struct {
+	cfg UtteranceConfig
+
+	active     bool
+	id         uint64
+	start      time.Time
+	lastSpeech time.Time
+	sumSin     float64
+	sumCos     float64
+	peak       float64
+}
+
+// NewUtteranceSegmenter creates a segmenter
+func NewUtteranceSegmenter(cfg UtteranceConfig) *UtteranceSegmenter {
+	return &UtteranceSegmenter{cfg: cfg}
+}
+
+// SetConfig changes the hangover and duration limit; an open utterance continues
+func (s *UtteranceSegmenter) SetConfig(cfg UtteranceConfig) {
+	s.cfg = cfg
+}
+
+// Active reports whether an utterance is in progress
+func (s *UtteranceSegmenter) Active() bool {
+	return s.active
+}
+
+// Update feeds one reading taken at now and returns any boundaries it crosses
+func (s *UtteranceSegmenter) Update(r Reading, now time.Time) []UtteranceEvent {
+	var events []UtteranceEvent
+
+	if s.active {
+		switch {
+		case !r.Speaking && now.Sub(s.lastSpeech) >= s.cfg.Hangover:
+			events = append(events, s.finish())
+		case r.Speaking && s.cfg.MaxDuration > 0 && now.Sub(s.start) >= s.cfg.MaxDuration:
+			events = append(events, s.finish())
+		}
+	}
+
+	if !r.Speaking {
+		return events
+	}
+
+	if !s.active {
+		s.active = true
+		s.id++
+		s.start = now
+		s.sumSin, s.sumCos, s.peak = 0, 0, 0
+		events = append(events, UtteranceEvent{
+			Type:       UtteranceStart,
+			ID:         s.id,
+			Start:      now,
+			AvgAngle:   r.Angle,
+			PeakEnergy: r.TotalEnergy,
+		})
+	}
+
+	s.lastSpeech = now
+	s.sumSin += math.Sin(r.Angle)
+	s.sumCos += math.Cos(r.Angle)
+	s.peak = math.Max(s.peak, r.TotalEnergy)
+
+	return events
+}
+
+// finish closes the current utterance
+func (s *UtteranceSegmenter) finish() UtteranceEvent {
+	s.active = false
+	return UtteranceEvent{
+		Type:       UtteranceEnd,
+		ID:         s.id,
+		Start:      s.start,
+		End:        s.lastSpeech,
+		DurationMs: s.lastSpeech.Sub(s.start).Milliseconds(),
+		AvgAngle:   math.Atan2(s.sumSin, s.sumCos),
+		PeakEnergy: s.peak,
+	}
+}
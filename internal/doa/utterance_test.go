@@ -0,0 +1,112 @@
+package doa
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUtteranceSegmenter(t *testing.T) {
+	s := NewUtteranceSegmenter(UtteranceConfig{Hangover: 300 * time.Millisecond})
+	t0 := time.Now()
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	var events []UtteranceEvent
+	feed := func(ms int, speaking bool, angle, energy float64) {
+		events = append(events, s.Update(Reading{Speaking: speaking, Angle: angle, TotalEnergy: energy}, at(ms))...)
+	}
+
+	feed(0, false, 0, 0)
+	feed(100, true, 0.2, 1)
+	feed(200, true, 0.4, 5)
+	feed(300, false, 0, 0) // Gap between words is bridged
+	feed(500, true, 0.3, 2)
+	feed(700, false, 0, 0)
+	feed(750, false, 0, 0)
+
+	if len(events) != 1 || events[0].Type != UtteranceStart || events[0].ID != 1 {
+		t.Fatalf("events before hangover = %+v", events)
+	}
+
+	feed(800, false, 0, 0)
+	if len(events) != 2 {
+		t.Fatalf("expected end event, got %+v", events)
+	}
+
+	end := events[1]
+	if end.Type != UtteranceEnd || end.ID != 1 {
+		t.Errorf("end = %+v", end)
+	}
+	if end.DurationMs != 400 || !end.Start.Equal(at(100)) || !end.End.Equal(at(500)) {
+		t.Errorf("duration %dms from %v to %v", end.DurationMs, end.Start, end.End)
+	}
+	if math.Abs(end.AvgAngle-0.3) > 0.01 || end.PeakEnergy != 5 {
+		t.Errorf("avg angle %.3f peak %.1f", end.AvgAngle, end.PeakEnergy)
+	}
+	if s.Active() {
+		t.Error("segmenter still active")
+	}
+}
+
+func TestUtteranceSegmenter_MaxDuration(t *testing.T) {
+	s := NewUtteranceSegmenter(UtteranceConfig{Hangover: time.Second, MaxDuration: time.Second})
+	t0 := time.Now()
+
+	var events []UtteranceEvent
+	for ms := 0; ms <= 1500; ms += 100 {
+		events = append(events, s.Update(Reading{Speaking: true}, t0.Add(time.Duration(ms)*time.Millisecond))...)
+	}
+
+	want := []UtteranceEventType{UtteranceStart, UtteranceEnd, UtteranceStart}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v", events)
+	}
+	for i, ev := range events {
+		if ev.Type != want[i] {
+			t.Errorf("event %d = %s, want %s", i, ev.Type, want[i])
+		}
+	}
+	if events[2].ID != 2 {
+		t.Errorf("split utterance id = %d, want 2", events[2].ID)
+	}
+}
+
+func TestTracker_SubscribeUtterances(t *testing.T) {
+	source := NewMockSource()
+	source.SetAngle(1.57)
+
+	cfg := DefaultTrackerConfig()
+	cfg.PollInterval = 10 * time.Millisecond
+	cfg.Utterance.Hangover = 50 * time.Millisecond
+	tracker := NewTracker(source, cfg, nil)
+
+	ch := tracker.SubscribeUtterances()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	source.SetSpeaking(true)
+	time.Sleep(50 * time.Millisecond)
+	source.SetSpeaking(false)
+
+	var got []UtteranceEvent
+	timeout := time.After(time.Second)
+	for len(got) < 2 {
+		select {
+		case ev := <-ch:
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("utterance events = %+v", got)
+		}
+	}
+	if got[0].Type != UtteranceStart || got[1].Type != UtteranceEnd || got[1].DurationMs <= 0 {
+		t.Errorf("utterance events = %+v", got)
+	}
+
+	tracker.Stop()
+	if _, ok := <-ch; ok {
+		t.Error("utterance channel open after Stop")
+	}
+}
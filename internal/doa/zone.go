@@ -0,0 +1,139 @@
+package doa
+
+import (
+	"math"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// Zone is a named sector around the robot in Eva's frame (0 = front,
+// +π/2 = left)
+type Zone struct {
+	Name   string
+	Center float64 // Radians
+	Width  float64 // Radians
+}
+
+// DefaultZones returns eight 45° sectors starting at the front
+func DefaultZones() []Zone {
+	names := []string{"front", "front-left", "left", "rear-left", "behind", "rear-right", "right", "front-right"}
+	zones := make([]Zone, len(names))
+	for i, name := range names {
+		zones[i] = Zone{Name: name, Center: NormalizeAngle(float64(i) * math.Pi / 4), Width: math.Pi / 4}
+	}
+	return zones
+}
+
+// ZoneConfig configures zone mapping
+type ZoneConfig struct {
+	Zones      []Zone        // nil = DefaultZones
+	Hysteresis float64       // Radians past the current zone's edge before it is left
+	MinDwell   time.Duration // A new zone must hold this long before the transition
+	World      bool          // Map the world angle, for zones fixed in the room rather than around the robot
+}
+
+// DefaultZoneConfig returns sensible defaults
+func DefaultZoneConfig() ZoneConfig {
+	return ZoneConfig{
+		Hysteresis: 5 * math.Pi / 180,
+		MinDwell:   200 * time.Millisecond,
+	}
+}
+
+// ZoneEvent reports the speaker moving into another zone
+type ZoneEvent struct {
+	From      string    `json:"from,omitempty"` // Empty for the first zone
+	To        string    `json:"to"`
+	Angle     float64   `json:"angle"` // Smoothed angle at the transition (radians)
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TopicZones carries zone transitions on the event bus
+var TopicZones = bus.NewTopic[ZoneEvent]("doa.zone")
+
+// ZoneMapper names the zone a speaker is in and reports transitions
+type ZoneMapper struct {
+	cfg ZoneConfig
+
+	current        string
+	candidate      string
+	candidateSince time.Time
+}
+
+// NewZoneMapper creates a zone mapper
+func NewZoneMapper(cfg ZoneConfig) *ZoneMapper {
+	m := &ZoneMapper{}
+	m.SetConfig(cfg)
+	return m
+}
+
+// SetConfig replaces the zones; the current zone is kept if it still exists
+func (m *ZoneMapper) SetConfig(cfg ZoneConfig) {
+	if cfg.Zones == nil {
+		cfg.Zones = DefaultZones()
+	}
+	m.cfg = cfg
+	m.candidate = ""
+	if _, ok := m.zone(m.current); !ok {
+		m.current = ""
+	}
+}
+
+// Current returns the zone of the latest speech ("" until someone speaks)
+func (m *ZoneMapper) Current() string {
+	return m.current
+}
+
+// Lookup returns the zone containing angle, preferring the nearest center
+// where zones overlap ("" if the angle falls in a gap)
+func (m *ZoneMapper) Lookup(angle float64) string {
+	name, best := "", math.Inf(1)
+	for _, z := range m.cfg.Zones {
+		d := math.Abs(NormalizeAngle(angle - z.Center))
+		if d <= z.Width/2 && d < best {
+			name, best = z.Name, d
+		}
+	}
+	return name
+}
+
+// Update feeds one smoothed angle observed at now. Silence never changes the
+// zone. Returns the transition, if any.
+func (m *ZoneMapper) Update(angle float64, speaking bool, now time.Time) *ZoneEvent {
+	if !speaking {
+		m.candidate = ""
+		return nil
+	}
+
+	next := m.Lookup(angle)
+	if next == "" || next == m.current {
+		m.candidate = ""
+		return nil
+	}
+	if z, ok := m.zone(m.current); ok && math.Abs(NormalizeAngle(angle-z.Center)) <= z.Width/2+m.cfg.Hysteresis {
+		m.candidate = ""
+		return nil
+	}
+
+	if next != m.candidate {
+		m.candidate, m.candidateSince = next, now
+	}
+	if now.Sub(m.candidateSince) < m.cfg.MinDwell {
+		return nil
+	}
+
+	ev := &ZoneEvent{From: m.current, To: next, Angle: angle, Timestamp: now}
+	m.current, m.candidate = next, ""
+	return ev
+}
+
+// zone looks up a zone by name
+func (m *ZoneMapper) zone(name string) (Zone, bool) {
+	for _, z := range m.cfg.Zones {
+		if z.Name == name && name != "" {
+			return z, true
+		}
+	}
+	return Zone{}, false
+}
@@ -0,0 +1,151 @@
+package doa
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func deg(d float64) float64 { return d * math.Pi / 180 }
+
+func TestZoneMapper_DefaultZones(t *testing.T) {
+	m := NewZoneMapper(ZoneConfig{})
+
+	tests := []struct {
+		angle float64
+		want  string
+	}{
+		{0, "front"},
+		{deg(20), "front"},
+		{deg(30), "front-left"},
+		{deg(90), "left"},
+		{deg(-90), "right"},
+		{deg(135), "rear-left"},
+		{deg(180), "behind"},
+		{deg(-175), "behind"},
+		{deg(-40), "front-right"},
+	}
+	for _, tt := range tests {
+		if got := m.Lookup(tt.angle); got != tt.want {
+			t.Errorf("Lookup(%.0f°) = %q, want %q", tt.angle*180/math.Pi, got, tt.want)
+		}
+	}
+}
+
+func TestZoneMapper_Transitions(t *testing.T) {
+	m := NewZoneMapper(ZoneConfig{Hysteresis: deg(5), MinDwell: 100 * time.Millisecond})
+	now := time.Now()
+
+	// The first zone still has to hold for MinDwell
+	if ev := m.Update(0, true, now); ev != nil {
+		t.Fatalf("transition before dwell: %+v", ev)
+	}
+	ev := m.Update(0, true, now.Add(100*time.Millisecond))
+	if ev == nil || ev.From != "" || ev.To != "front" {
+		t.Fatalf("first transition = %+v, want → front", ev)
+	}
+
+	// Just past the edge stays within the hysteresis margin
+	now = now.Add(time.Second)
+	if ev := m.Update(deg(25), true, now); ev != nil || m.Current() != "front" {
+		t.Errorf("left front within hysteresis: %+v, zone %q", ev, m.Current())
+	}
+
+	// Silence never moves the zone
+	if ev := m.Update(deg(90), false, now.Add(time.Second)); ev != nil {
+		t.Errorf("transition while silent: %+v", ev)
+	}
+
+	// A brief excursion is ignored, a sustained one is reported
+	m.Update(deg(90), true, now)
+	m.Update(0, true, now.Add(50*time.Millisecond))
+	m.Update(deg(90), true, now.Add(60*time.Millisecond))
+	if ev := m.Update(deg(90), true, now.Add(120*time.Millisecond)); ev != nil {
+		t.Errorf("transition before dwell: %+v", ev)
+	}
+	ev = m.Update(deg(90), true, now.Add(160*time.Millisecond))
+	if ev == nil || ev.From != "front" || ev.To != "left" {
+		t.Errorf("transition = %+v, want front → left", ev)
+	}
+}
+
+func TestZoneMapper_CustomZonesWithGap(t *testing.T) {
+	m := NewZoneMapper(ZoneConfig{Zones: []Zone{
+		{Name: "desk", Center: 0, Width: deg(60)},
+		{Name: "door", Center: deg(180), Width: deg(40)},
+	}})
+
+	if got := m.Lookup(deg(90)); got != "" {
+		t.Errorf("Lookup(90°) = %q, want no zone", got)
+	}
+	m.Update(0, true, time.Now())
+	if ev := m.Update(deg(90), true, time.Now()); ev != nil || m.Current() != "desk" {
+		t.Errorf("gap changed the zone: %+v, zone %q", ev, m.Current())
+	}
+
+	// Reconfiguring drops a zone that no longer exists
+	m.SetConfig(ZoneConfig{})
+	if m.Current() != "" {
+		t.Errorf("Current() = %q after zones changed, want empty", m.Current())
+	}
+}
+
+func TestTracker_Zones(t *testing.T) {
+	source := NewMockSource()
+	source.SetAngle(0) // XVF 0 = Eva left
+	source.SetSpeaking(true)
+
+	cfg := DefaultTrackerConfig()
+	cfg.Zones.MinDwell = 0
+	tracker := NewTracker(source, cfg, nil)
+	ch := tracker.SubscribeZones()
+	defer tracker.UnsubscribeZones(ch)
+
+	if err := tracker.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if zone := tracker.GetLatest().Zone; zone != "left" {
+		t.Errorf("Result.Zone = %q, want left", zone)
+	}
+
+	select {
+	case ev := <-ch:
+		if ev.To != "left" {
+			t.Errorf("zone event = %+v, want → left", ev)
+		}
+	default:
+		t.Error("no zone event")
+	}
+}
+
+func TestTracker_WorldZones(t *testing.T) {
+	source := NewMockSource()
+	source.SetAngle(math.Pi / 2) // Eva front
+	source.SetSpeaking(true)
+
+	cfg := DefaultTrackerConfig()
+	cfg.Zones.MinDwell = 0
+	cfg.Zones.World = true
+	tracker := NewTracker(source, cfg, nil)
+	tracker.SetBodyYaw(func() (float64, bool) { return math.Pi, true })
+
+	if err := tracker.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// In front of a body turned around is behind in the room
+	if zone := tracker.GetLatest().Zone; zone != "behind" {
+		t.Errorf("Result.Zone = %q, want behind", zone)
+	}
+}
+
+func TestTracker_StopClosesZoneSubscribers(t *testing.T) {
+	tracker := NewTracker(NewMockSource(), DefaultTrackerConfig(), nil)
+	ch := tracker.SubscribeZones()
+
+	tracker.Stop()
+	if _, ok := <-ch; ok {
+		t.Error("zone channel still open after Stop")
+	}
+	tracker.UnsubscribeZones(ch) // Already removed; must not close twice
+}
@@ -0,0 +1,438 @@
+// Package emotion keeps a local library of known emotion animations and
+// plays them one at a time, so requests from the cloud, gestures and idle
+// behaviors never overlap on the robot.
+package emotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// Scheduling errors
+var (
+	ErrUnknown   = errors.New("unknown emotion")
+	ErrConflict  = errors.New("emotion conflicts with the one playing")
+	ErrQueueFull = errors.New("emotion queue full")
+	ErrBusy      = errors.New("an emotion is already playing")
+)
+
+// Overlap policies for requests made while an emotion plays
+const (
+	OverlapQueue  = "queue"  // Wait behind the playing emotion
+	OverlapReject = "reject" // Refuse with ErrBusy
+)
+
+// Emotion describes an animation Pollen can play
+type Emotion struct {
+	Name      string
+	Duration  time.Duration // How long the animation occupies the robot
+	Conflicts []string      // Emotions that must not follow while this one plays
+	Idle      bool          // Eligible as an idle behavior
+}
+
+// DefaultLibrary returns the emotions shipped with Pollen's Reachy Mini
+// library
+func DefaultLibrary() []Emotion {
+	return []Emotion{
+		{Name: "happy", Duration: 3 * time.Second, Conflicts: []string{"sad"}},
+		{Name: "sad", Duration: 4 * time.Second, Conflicts: []string{"happy"}},
+		{Name: "curious", Duration: 3 * time.Second, Idle: true},
+		{Name: "surprised", Duration: 2 * time.Second},
+		{Name: "startled", Duration: 2 * time.Second},
+		{Name: "thinking", Duration: 4 * time.Second},
+		{Name: "yes", Duration: 2 * time.Second, Conflicts: []string{"no"}},
+		{Name: "no", Duration: 2 * time.Second, Conflicts: []string{"yes"}},
+		{Name: "sleepy", Duration: 5 * time.Second, Idle: true},
+	}
+}
+
+// Config holds scheduler configuration
+type Config struct {
+	Library         []Emotion     // Known emotions (nil = DefaultLibrary)
+	AllowUnknown    bool          // Play names missing from the library with DefaultDuration
+	DefaultDuration time.Duration // Duration for unknown emotions
+	QueueSize       int           // Requests waiting behind the one playing
+	IdleAfter       time.Duration // Silence before an idle emotion plays (0 = never)
+	Overlap         string        // OverlapQueue ("") or OverlapReject
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		AllowUnknown:    true,
+		DefaultDuration: 3 * time.Second,
+		QueueSize:       8,
+		Overlap:         OverlapQueue,
+	}
+}
+
+// Player plays emotion animations (implemented by pollen.Limiter)
+type Player interface {
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// EventType distinguishes the start and end of an emotion
+type EventType string
+
+const (
+	EventStarted  EventType = "started"
+	EventFinished EventType = "finished"
+)
+
+// Event marks an emotion starting or finishing on the robot, so callers can
+// sequence speech around it
+type Event struct {
+	Type       EventType `json:"type"`
+	ID         string    `json:"id,omitempty"` // Request ID given to PlayRequest
+	Name       string    `json:"name"`
+	DurationMs int64     `json:"duration_ms"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`             // Expected end for started events
+	Idle       bool      `json:"idle,omitempty"`  // Played as an idle behavior
+	Error      string    `json:"error,omitempty"` // Finished without playing
+}
+
+// TopicEvents carries emotion start and finish events on the event bus
+var TopicEvents = bus.NewTopic[Event]("emotion.event")
+
+// request is a queued or playing emotion
+type request struct {
+	Emotion
+	id   string
+	idle bool
+}
+
+// Scheduler serializes emotion requests and fills long silences with idle
+// behaviors
+type Scheduler struct {
+	cfg     Config
+	player  Player
+	logger  *slog.Logger
+	library map[string]Emotion
+	order   []string // Library names in manifest order
+	idle    []string
+
+	mu           sync.Mutex
+	queue        []request
+	playing      *request
+	playingSince time.Time
+	playingUntil time.Time
+	lastActivity time.Time
+	nextIdle     int
+	wake         chan struct{}
+	bus          atomic.Pointer[bus.Bus]
+
+	// Stats
+	played   atomic.Uint64
+	rejected atomic.Uint64
+	idled    atomic.Uint64
+	errors   atomic.Uint64
+}
+
+// New creates a scheduler for player
+func New(cfg Config, player Player, logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Library == nil {
+		cfg.Library = DefaultLibrary()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultConfig().QueueSize
+	}
+	if cfg.DefaultDuration <= 0 {
+		cfg.DefaultDuration = DefaultConfig().DefaultDuration
+	}
+
+	s := &Scheduler{
+		cfg:          cfg,
+		player:       player,
+		logger:       logger,
+		library:      make(map[string]Emotion, len(cfg.Library)),
+		lastActivity: time.Now(),
+		wake:         make(chan struct{}, 1),
+	}
+	for _, e := range cfg.Library {
+		if e.Duration <= 0 {
+			e.Duration = cfg.DefaultDuration
+		}
+		if _, dup := s.library[e.Name]; !dup {
+			s.order = append(s.order, e.Name)
+		}
+		s.library[e.Name] = e
+		if e.Idle && !slices.Contains(s.idle, e.Name) {
+			s.idle = append(s.idle, e.Name)
+		}
+	}
+	return s
+}
+
+// Play queues an emotion; duration overrides the library duration when
+// positive. Queued emotions that conflict with it are replaced.
+func (s *Scheduler) Play(name string, duration time.Duration) error {
+	return s.PlayRequest("", name, duration)
+}
+
+// PlayRequest is Play for a request identified by id, which its start and
+// finish events carry
+func (s *Scheduler) PlayRequest(id, name string, duration time.Duration) error {
+	e, ok := s.library[name]
+	if !ok {
+		if !s.cfg.AllowUnknown {
+			s.rejected.Add(1)
+			return fmt.Errorf("%w: %q", ErrUnknown, name)
+		}
+		e = Emotion{Name: name, Duration: s.cfg.DefaultDuration}
+	}
+	if duration > 0 {
+		e.Duration = duration
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.admitLocked(e, time.Now()); err != nil {
+		s.rejected.Add(1)
+		return err
+	}
+	s.queue = slices.DeleteFunc(s.queue, func(q request) bool { return conflicts(q.Emotion, e) })
+	if len(s.queue) >= s.cfg.QueueSize {
+		s.rejected.Add(1)
+		return ErrQueueFull
+	}
+	s.queue = append(s.queue, request{Emotion: e, id: id})
+	s.lastActivity = time.Now()
+	s.signal()
+	return nil
+}
+
+// PlayEmotion queues an emotion without waiting for it (satisfies Player,
+// so gestures can share the queue)
+func (s *Scheduler) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	return s.Play(name, time.Duration(duration*float64(time.Second)))
+}
+
+// admitLocked checks e against the emotion playing at now and, when
+// overlaps are rejected, the queue (caller holds mu)
+func (s *Scheduler) admitLocked(e Emotion, now time.Time) error {
+	busy := s.playing != nil && now.Before(s.playingUntil)
+	if busy && conflicts(s.playing.Emotion, e) {
+		return fmt.Errorf("%w: %q is playing", ErrConflict, s.playing.Name)
+	}
+	if s.cfg.Overlap == OverlapReject {
+		if busy {
+			return fmt.Errorf("%w: %q", ErrBusy, s.playing.Name)
+		}
+		if len(s.queue) > 0 {
+			return fmt.Errorf("%w: %q", ErrBusy, s.queue[0].Name)
+		}
+	}
+	return nil
+}
+
+// conflicts reports whether either emotion lists the other
+func conflicts(a, b Emotion) bool {
+	return slices.Contains(a.Conflicts, b.Name) || slices.Contains(b.Conflicts, a.Name)
+}
+
+// NoteActivity resets the idle timer (call when someone speaks)
+func (s *Scheduler) NoteActivity() {
+	s.mu.Lock()
+	s.lastActivity = time.Now()
+	s.mu.Unlock()
+	s.signal()
+}
+
+// signal wakes Run without blocking
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run plays queued emotions until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		next, finished, wait := s.next(time.Now())
+		if finished != nil {
+			s.publish(*finished)
+		}
+		if next != nil {
+			start := time.Now()
+			ev := Event{
+				ID:         next.id,
+				Name:       next.Name,
+				DurationMs: next.Duration.Milliseconds(),
+				Start:      start,
+				Idle:       next.idle,
+			}
+			if err := s.player.PlayEmotion(ctx, next.Name, next.Duration.Seconds()); err != nil {
+				s.errors.Add(1)
+				s.logger.Warn("emotion failed", "name", next.Name, "error", err)
+				// Nothing is moving, so the queue need not wait
+				s.mu.Lock()
+				s.playing = nil
+				s.mu.Unlock()
+				ev.Type, ev.End, ev.Error = EventFinished, start, err.Error()
+			} else {
+				s.played.Add(1)
+				s.logger.Debug("emotion playing", "name", next.Name, "duration", next.Duration)
+				ev.Type, ev.End = EventStarted, start.Add(next.Duration)
+			}
+			s.publish(ev)
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// next returns the emotion to start at now, or how long to wait before
+// checking again (0 = until woken), and the finish event of an emotion
+// that has ended since the last call
+func (s *Scheduler) next(now time.Time) (*request, *Event, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var finished *Event
+	if s.playing != nil && !now.Before(s.playingUntil) {
+		finished = &Event{
+			Type:       EventFinished,
+			ID:         s.playing.id,
+			Name:       s.playing.Name,
+			DurationMs: s.playing.Duration.Milliseconds(),
+			Start:      s.playingSince,
+			End:        now,
+			Idle:       s.playing.idle,
+		}
+		s.playing = nil
+	}
+	if s.playing != nil {
+		return nil, nil, s.playingUntil.Sub(now)
+	}
+
+	if len(s.queue) == 0 && s.cfg.IdleAfter > 0 && len(s.idle) > 0 {
+		idleAt := s.lastActivity.Add(s.cfg.IdleAfter)
+		if now.Before(idleAt) {
+			return nil, finished, idleAt.Sub(now)
+		}
+		e := s.library[s.idle[s.nextIdle%len(s.idle)]]
+		s.nextIdle++
+		s.idled.Add(1)
+		s.queue = append(s.queue, request{Emotion: e, idle: true})
+		s.lastActivity = now
+	}
+	if len(s.queue) == 0 {
+		return nil, finished, 0
+	}
+
+	r := s.queue[0]
+	s.queue = s.queue[1:]
+	s.playing = &r
+	s.playingSince = now
+	s.playingUntil = now.Add(r.Duration)
+	return &r, finished, 0
+}
+
+// publish sends ev to the event bus, if attached
+func (s *Scheduler) publish(ev Event) {
+	if b := s.bus.Load(); b != nil {
+		bus.Publish(b, TopicEvents, ev)
+	}
+}
+
+// PublishTo publishes emotion start and finish events on the event bus
+func (s *Scheduler) PublishTo(b *bus.Bus) {
+	s.bus.Store(b)
+}
+
+// Status describes one library emotion
+type Status struct {
+	Name      string   `json:"name"`
+	DurationS float64  `json:"duration_s"`
+	Conflicts []string `json:"conflicts,omitempty"`
+	Idle      bool     `json:"idle"`
+	Available bool     `json:"available"`        // Play would accept it right now
+	Reason    string   `json:"reason,omitempty"` // Why it is unavailable
+}
+
+// List returns the library with the current availability of each emotion
+func (s *Scheduler) List() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	out := make([]Status, 0, len(s.order))
+	for _, name := range s.order {
+		e := s.library[name]
+		st := Status{
+			Name:      e.Name,
+			DurationS: e.Duration.Seconds(),
+			Conflicts: e.Conflicts,
+			Idle:      e.Idle,
+			Available: true,
+		}
+		if err := s.admitLocked(e, now); err != nil {
+			st.Available, st.Reason = false, err.Error()
+		} else if len(slices.DeleteFunc(slices.Clone(s.queue), func(q request) bool { return conflicts(q.Emotion, e) })) >= s.cfg.QueueSize {
+			st.Available, st.Reason = false, ErrQueueFull.Error()
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// Stats contains scheduler statistics
+type Stats struct {
+	Playing       string   `json:"playing,omitempty"`
+	Queued        []string `json:"queued"`
+	Played        uint64   `json:"played"`
+	Rejected      uint64   `json:"rejected"`
+	IdleTriggered uint64   `json:"idle_triggered"`
+	Errors        uint64   `json:"errors"`
+}
+
+// GetStats returns scheduler statistics
+func (s *Scheduler) GetStats() Stats {
+	s.mu.Lock()
+	stats := Stats{Queued: make([]string, 0, len(s.queue))}
+	if s.playing != nil && time.Now().Before(s.playingUntil) {
+		stats.Playing = s.playing.Name
+	}
+	for _, e := range s.queue {
+		stats.Queued = append(stats.Queued, e.Name)
+	}
+	s.mu.Unlock()
+
+	stats.Played = s.played.Load()
+	stats.Rejected = s.rejected.Load()
+	stats.IdleTriggered = s.idled.Load()
+	stats.Errors = s.errors.Load()
+	return stats
+}
@@ -0,0 +1,269 @@
+package emotion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// fakePlayer records emotions as they start
+type fakePlayer struct {
+	mu     sync.Mutex
+	played []string
+	at     []time.Time
+	err    error
+}
+
+func (p *fakePlayer) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.played = append(p.played, name)
+	p.at = append(p.at, time.Now())
+	return p.err
+}
+
+func (p *fakePlayer) snapshot() ([]string, []time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.played...), append([]time.Time(nil), p.at...)
+}
+
+// waitPlayed waits until n emotions have started
+func waitPlayed(t *testing.T, p *fakePlayer, n int) ([]string, []time.Time) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if names, at := p.snapshot(); len(names) >= n {
+			return names, at
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	names, at := p.snapshot()
+	t.Fatalf("played %v, want %d emotions", names, n)
+	return names, at
+}
+
+func testLibrary() []Emotion {
+	return []Emotion{
+		{Name: "happy", Duration: 60 * time.Millisecond, Conflicts: []string{"sad"}},
+		{Name: "sad", Duration: 60 * time.Millisecond},
+		{Name: "curious", Duration: 40 * time.Millisecond, Idle: true},
+		{Name: "sleepy", Duration: 40 * time.Millisecond, Idle: true},
+	}
+}
+
+func TestScheduler_NoOverlap(t *testing.T) {
+	player := &fakePlayer{}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	s := New(cfg, player, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	for _, name := range []string{"happy", "curious"} {
+		if err := s.Play(name, 0); err != nil {
+			t.Fatalf("Play(%s) error = %v", name, err)
+		}
+	}
+
+	names, at := waitPlayed(t, player, 2)
+	if names[0] != "happy" || names[1] != "curious" {
+		t.Errorf("played %v, want [happy curious]", names)
+	}
+	if gap := at[1].Sub(at[0]); gap < 60*time.Millisecond {
+		t.Errorf("curious started %v after happy, want at least its 60ms duration", gap)
+	}
+}
+
+func TestScheduler_Unknown(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	cfg.AllowUnknown = false
+	s := New(cfg, &fakePlayer{}, nil)
+
+	if err := s.Play("dance", 0); !errors.Is(err, ErrUnknown) {
+		t.Errorf("Play(unknown) error = %v, want ErrUnknown", err)
+	}
+
+	cfg.AllowUnknown = true
+	s = New(cfg, &fakePlayer{}, nil)
+	if err := s.Play("dance", 0); err != nil {
+		t.Errorf("Play(unknown) with AllowUnknown error = %v", err)
+	}
+}
+
+func TestScheduler_Conflicts(t *testing.T) {
+	player := &fakePlayer{}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	s := New(cfg, player, nil)
+
+	// A queued emotion is replaced by a conflicting one
+	s.Play("curious", time.Hour)
+	s.Play("happy", 0)
+	s.Play("sad", 0)
+	if queued := s.GetStats().Queued; len(queued) != 2 || queued[1] != "sad" {
+		t.Fatalf("queued = %v, want [curious sad]", queued)
+	}
+
+	// A playing emotion refuses conflicting ones
+	s = New(cfg, player, nil)
+	s.Play("happy", time.Hour)
+	s.next(time.Now())
+	if err := s.Play("sad", 0); !errors.Is(err, ErrConflict) {
+		t.Errorf("Play(sad) during happy error = %v, want ErrConflict", err)
+	}
+
+	for _, st := range s.List() {
+		if st.Name == "sad" && st.Available {
+			t.Error("sad should be unavailable while happy plays")
+		}
+		if st.Name == "curious" && !st.Available {
+			t.Errorf("curious should be available: %s", st.Reason)
+		}
+	}
+}
+
+func TestScheduler_QueueFull(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	cfg.QueueSize = 1
+	s := New(cfg, &fakePlayer{}, nil)
+
+	s.Play("curious", 0)
+	if err := s.Play("sleepy", 0); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Play() error = %v, want ErrQueueFull", err)
+	}
+	if stats := s.GetStats(); stats.Rejected != 1 {
+		t.Errorf("Rejected = %d, want 1", stats.Rejected)
+	}
+}
+
+func TestScheduler_Idle(t *testing.T) {
+	player := &fakePlayer{}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	cfg.IdleAfter = 50 * time.Millisecond
+	s := New(cfg, player, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// Idle emotions rotate through the library
+	names, _ := waitPlayed(t, player, 2)
+	if names[0] != "curious" || names[1] != "sleepy" {
+		t.Errorf("idle emotions = %v, want [curious sleepy]", names)
+	}
+	if s.GetStats().IdleTriggered < 2 {
+		t.Error("IdleTriggered should count idle emotions")
+	}
+}
+
+func TestScheduler_ActivityDelaysIdle(t *testing.T) {
+	player := &fakePlayer{}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	cfg.IdleAfter = 100 * time.Millisecond
+	s := New(cfg, player, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	for i := 0; i < 5; i++ {
+		time.Sleep(40 * time.Millisecond)
+		s.NoteActivity()
+	}
+	if names, _ := player.snapshot(); len(names) != 0 {
+		t.Errorf("idle emotion played during activity: %v", names)
+	}
+}
+
+func TestScheduler_PlayerError(t *testing.T) {
+	player := &fakePlayer{err: errors.New("daemon down")}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	s := New(cfg, player, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	s.Play("happy", time.Hour)
+	s.Play("curious", 0)
+
+	// A failed emotion doesn't hold up the queue
+	waitPlayed(t, player, 2)
+	if s.GetStats().Errors < 1 {
+		t.Error("Errors should count the failed emotion")
+	}
+}
+
+func TestScheduler_Events(t *testing.T) {
+	player := &fakePlayer{}
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	s := New(cfg, player, nil)
+
+	b := bus.New(nil)
+	defer b.Close()
+	sub := bus.Subscribe(b, TopicEvents, 8)
+	s.PublishTo(b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	if err := s.PlayRequest("cmd-1", "happy", 0); err != nil {
+		t.Fatalf("PlayRequest: %v", err)
+	}
+
+	var events []Event
+	timeout := time.After(2 * time.Second)
+	for len(events) < 2 {
+		select {
+		case ev := <-sub.C:
+			events = append(events, ev)
+		case <-timeout:
+			t.Fatalf("got events %+v, want started and finished", events)
+		}
+	}
+
+	started, finished := events[0], events[1]
+	if started.Type != EventStarted || finished.Type != EventFinished {
+		t.Fatalf("event types = %s, %s", started.Type, finished.Type)
+	}
+	for _, ev := range events {
+		if ev.ID != "cmd-1" || ev.Name != "happy" || ev.DurationMs != 60 {
+			t.Errorf("event %+v does not describe the request", ev)
+		}
+	}
+	if finished.End.Sub(started.Start) < 60*time.Millisecond {
+		t.Errorf("finished %v after start, before the emotion's duration", finished.End.Sub(started.Start))
+	}
+}
+
+func TestScheduler_OverlapReject(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Library = testLibrary()
+	cfg.Overlap = OverlapReject
+	s := New(cfg, &fakePlayer{}, nil)
+
+	if err := s.Play("happy", time.Hour); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if err := s.Play("curious", 0); !errors.Is(err, ErrBusy) {
+		t.Errorf("request behind a queued emotion: got %v, want ErrBusy", err)
+	}
+	s.next(time.Now())
+	if err := s.Play("curious", 0); !errors.Is(err, ErrBusy) {
+		t.Errorf("request while playing: got %v, want ErrBusy", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package expression gives visual feedback that the robot is listening:
+// antennas perk up when speech starts, lean toward the speaker while they
+// talk, and relax once it has been quiet for a while.
+package expression
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Gesture names reported in Stats
+const (
+	GestureRest   = "rest"
+	GesturePerk   = "perk"
+	GestureListen = "listen"
+)
+
+// Config holds expression configuration. Poses are [left, right] antenna
+// positions in radians, written for a speaker straight ahead.
+type Config struct {
+	Rate          time.Duration // Frame interval
+	Rest          [2]float64    // Pose when nobody is talking
+	Perk          [2]float64    // Pose held briefly when speech starts
+	Listen        [2]float64    // Pose while someone is talking
+	PerkHold      time.Duration // How long the perk pose is held
+	Lean          float64       // Antenna offset toward a speaker at 90° (radians)
+	MinConfidence float64       // Below this DOA confidence the antennas don't lean
+	ReleaseAfter  time.Duration // Quiet time before returning to rest
+	MaxVelocity   float64       // Antenna speed limit (rad/s)
+}
+
+// DefaultConfig returns subtle defaults
+func DefaultConfig() Config {
+	return Config{
+		Rate:          50 * time.Millisecond,
+		Rest:          [2]float64{0, 0},
+		Perk:          [2]float64{0.6, 0.6},
+		Listen:        [2]float64{0.3, 0.3},
+		PerkHold:      400 * time.Millisecond,
+		Lean:          0.3,
+		MinConfidence: 0.3,
+		ReleaseAfter:  1500 * time.Millisecond,
+		MaxVelocity:   3,
+	}
+}
+
+// Actuator moves the antennas (implemented by pollen.Client)
+type Actuator interface {
+	SetAntennas(ctx context.Context, antennas [2]float64) error
+}
+
+// Expression maps tracker results to antenna poses
+type Expression struct {
+	cfg      Config
+	actuator Actuator
+	logger   *slog.Logger
+
+	mu         sync.Mutex
+	speaking   bool
+	perkUntil  time.Time
+	lastSpeech time.Time
+	lean       float64 // Signed lean toward the current speaker (+ = left)
+	current    [2]float64
+	lastFrame  time.Time
+	gesture    string
+
+	// Stats
+	framesSent atomic.Uint64
+	sendErrors atomic.Uint64
+	perks      atomic.Uint64
+}
+
+// New creates an expression driver
+func New(cfg Config, actuator Actuator, logger *slog.Logger) *Expression {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultConfig().Rate
+	}
+
+	return &Expression{
+		cfg:      cfg,
+		actuator: actuator,
+		logger:   logger,
+		current:  cfg.Rest,
+		gesture:  GestureRest,
+	}
+}
+
+// Feed updates listening state from a tracker result
+func (e *Expression) Feed(result doa.Result) {
+	now := result.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if result.SpeakingLatched && !e.speaking {
+		e.perkUntil = now.Add(e.cfg.PerkHold)
+		e.perks.Add(1)
+	}
+	e.speaking = result.SpeakingLatched
+	if !result.SpeakingLatched {
+		return
+	}
+
+	e.lastSpeech = now
+	if result.Confidence >= e.cfg.MinConfidence {
+		// Full lean for a speaker at the side, none straight ahead or behind
+		e.lean = e.cfg.Lean * math.Sin(result.SmoothedAngle)
+	}
+}
+
+// Target returns the gesture and pose the antennas should move to at time t
+func (e *Expression) Target(t time.Time) (string, [2]float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.target(t)
+}
+
+// target implements Target (caller holds mu)
+func (e *Expression) target(t time.Time) (string, [2]float64) {
+	if e.lastSpeech.IsZero() || (!e.speaking && t.Sub(e.lastSpeech) > e.cfg.ReleaseAfter) {
+		return GestureRest, e.cfg.Rest
+	}
+
+	name, pose := GestureListen, e.cfg.Listen
+	if t.Before(e.perkUntil) {
+		name, pose = GesturePerk, e.cfg.Perk
+	}
+	return name, [2]float64{pose[0] + e.lean, pose[1] - e.lean}
+}
+
+// Step advances the antennas toward the target at time t, limited by
+// MaxVelocity. It reports whether a frame should be sent: once the antennas
+// have settled at rest they are left alone for other animations.
+func (e *Expression) Step(t time.Time) ([2]float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name, target := e.target(t)
+	e.gesture = name
+
+	dt := e.cfg.Rate.Seconds()
+	if !e.lastFrame.IsZero() {
+		dt = t.Sub(e.lastFrame).Seconds()
+	}
+	e.lastFrame = t
+
+	maxStep := math.Inf(1)
+	if e.cfg.MaxVelocity > 0 {
+		maxStep = e.cfg.MaxVelocity * dt
+	}
+
+	moved := false
+	for i := range e.current {
+		delta := target[i] - e.current[i]
+		if delta == 0 {
+			continue
+		}
+		moved = true
+		e.current[i] += math.Max(-maxStep, math.Min(maxStep, delta))
+	}
+
+	return e.current, moved || name != GestureRest
+}
+
+// Current returns the latest antenna pose
+func (e *Expression) Current() [2]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.current
+}
+
+// Run sends frames to the actuator until ctx is cancelled
+func (e *Expression) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			frame, send := e.Step(now)
+			if !send || e.actuator == nil {
+				continue
+			}
+			if err := e.actuator.SetAntennas(ctx, frame); err != nil {
+				e.sendErrors.Add(1)
+				e.logger.Debug("expression frame failed", "error", err)
+				continue
+			}
+			e.framesSent.Add(1)
+		}
+	}
+}
+
+// Stats contains expression statistics
+type Stats struct {
+	Gesture    string `json:"gesture"`
+	FramesSent uint64 `json:"frames_sent"`
+	SendErrors uint64 `json:"send_errors"`
+	Perks      uint64 `json:"perks"`
+}
+
+// GetStats returns expression statistics
+func (e *Expression) GetStats() Stats {
+	e.mu.Lock()
+	gesture := e.gesture
+	e.mu.Unlock()
+
+	return Stats{
+		Gesture:    gesture,
+		FramesSent: e.framesSent.Load(),
+		SendErrors: e.sendErrors.Load(),
+		Perks:      e.perks.Load(),
+	}
+}
@@ -0,0 +1,150 @@
+package expression
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+type fakeActuator struct {
+	mu     sync.Mutex
+	frames [][2]float64
+}
+
+func (f *fakeActuator) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames = append(f.frames, antennas)
+	return nil
+}
+
+func (f *fakeActuator) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.frames)
+}
+
+func result(ts time.Time, angle float64, speaking bool) doa.Result {
+	return doa.Result{
+		Reading:         doa.Reading{Angle: angle, Timestamp: ts},
+		SmoothedAngle:   angle,
+		SpeakingLatched: speaking,
+		Confidence:      0.9,
+	}
+}
+
+func TestTarget_Gestures(t *testing.T) {
+	cfg := DefaultConfig()
+	e := New(cfg, nil, nil)
+	now := time.Now()
+
+	if name, pose := e.Target(now); name != GestureRest || pose != cfg.Rest {
+		t.Errorf("before speech: %s %v, want rest", name, pose)
+	}
+
+	// Speech straight ahead: perk, then listen, no lean
+	e.Feed(result(now, 0, true))
+	if name, pose := e.Target(now); name != GesturePerk || pose != cfg.Perk {
+		t.Errorf("speech start: %s %v, want perk %v", name, pose, cfg.Perk)
+	}
+	later := now.Add(cfg.PerkHold + time.Millisecond)
+	e.Feed(result(later, 0, true))
+	if name, pose := e.Target(later); name != GestureListen || pose != cfg.Listen {
+		t.Errorf("after perk: %s %v, want listen %v", name, pose, cfg.Listen)
+	}
+
+	// Speech ends: keep listening until the release delay has passed
+	e.Feed(result(later, 0, false))
+	if name, _ := e.Target(later.Add(cfg.ReleaseAfter / 2)); name != GestureListen {
+		t.Errorf("just after speech: %s, want listen", name)
+	}
+	if name, _ := e.Target(later.Add(cfg.ReleaseAfter + time.Millisecond)); name != GestureRest {
+		t.Errorf("after release: %s, want rest", name)
+	}
+}
+
+func TestTarget_LeanTowardSpeaker(t *testing.T) {
+	cfg := DefaultConfig()
+	e := New(cfg, nil, nil)
+	now := time.Now()
+
+	// Speaker on the left (positive angle)
+	e.Feed(result(now, math.Pi/2, true))
+	_, pose := e.Target(now.Add(cfg.PerkHold + time.Millisecond))
+	if math.Abs(pose[0]-(cfg.Listen[0]+cfg.Lean)) > 1e-9 || math.Abs(pose[1]-(cfg.Listen[1]-cfg.Lean)) > 1e-9 {
+		t.Errorf("left speaker pose = %v, want lean %v", pose, cfg.Lean)
+	}
+
+	// Low-confidence readings keep the previous lean
+	low := result(now, -math.Pi/2, true)
+	low.Confidence = 0.1
+	e.Feed(low)
+	if _, again := e.Target(now.Add(cfg.PerkHold + time.Millisecond)); again != pose {
+		t.Errorf("low confidence changed the lean: %v, want %v", again, pose)
+	}
+}
+
+func TestStep_VelocityLimitAndSettle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxVelocity = 1 // rad/s
+	e := New(cfg, nil, nil)
+	now := time.Now()
+
+	if _, send := e.Step(now); send {
+		t.Error("settled at rest should not send frames")
+	}
+
+	e.Feed(result(now, 0, true))
+	frame, send := e.Step(now.Add(100 * time.Millisecond))
+	if !send {
+		t.Fatal("speech should send frames")
+	}
+	if math.Abs(frame[0]-0.1) > 1e-9 {
+		t.Errorf("frame[0] = %v, want 0.1 after 100ms at 1 rad/s", frame[0])
+	}
+
+	// Return to rest, then stop sending once settled
+	end := now.Add(200 * time.Millisecond)
+	e.Feed(result(end, 0, false))
+	ts := end.Add(cfg.ReleaseAfter)
+	sent := 0
+	for i := 0; i < 100; i++ {
+		ts = ts.Add(cfg.Rate)
+		if _, send := e.Step(ts); send {
+			sent++
+		}
+	}
+	if e.Current() != cfg.Rest {
+		t.Errorf("Current() = %v, want rest", e.Current())
+	}
+	if sent == 0 || sent == 100 {
+		t.Errorf("sent %d frames on the way to rest, want some then none", sent)
+	}
+}
+
+func TestRun_SendsFrames(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Rate = 5 * time.Millisecond
+	act := &fakeActuator{}
+	e := New(cfg, act, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+
+	e.Feed(result(time.Now(), 0.5, true))
+	deadline := time.Now().Add(time.Second)
+	for act.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if act.count() < 3 {
+		t.Errorf("sent %d frames, want at least 3", act.count())
+	}
+	if stats := e.GetStats(); stats.Perks != 1 || stats.FramesSent == 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
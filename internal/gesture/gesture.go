@@ -0,0 +1,301 @@
+// Package gesture maps audio events from the DOA tracker to expressive
+// reactions (emotions and antenna moves) defined declaratively in config.
+package gesture
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Event names usable in rules
+const (
+	EventSpeechStart = "speech_start" // VAD rising edge
+	EventSpeechEnd   = "speech_end"   // VAD falling edge
+	EventNewSpeaker  = "new_speaker"  // Speech starts far from the previous speaker
+	EventBehind      = "behind"       // Speech starts from behind the robot
+	EventLoud        = "loud"         // Sudden energy spike above the running average
+)
+
+// Rule maps an event to a reaction
+type Rule struct {
+	Event    string        // One of the Event* names
+	Emotion  string        // Pollen emotion to play (optional)
+	Duration float64       // Emotion duration in seconds (0 = emotion default)
+	Antennas []float64     // Antenna target [left, right] (optional)
+	Lean     bool          // Mirror antenna target toward the speaker's side
+	Cooldown time.Duration // Minimum time between firings of this rule
+}
+
+// Config holds gesture mapping configuration
+type Config struct {
+	NewSpeakerAngle float64 // Radians between speakers to count as a new speaker
+	BehindAngle     float64 // |angle| beyond which sound is "behind" (radians)
+	LoudRatio       float64 // Energy / running average ratio that counts as "loud"
+	Rules           []Rule
+}
+
+// DefaultConfig returns a small set of expressive defaults
+func DefaultConfig() Config {
+	return Config{
+		NewSpeakerAngle: 30 * math.Pi / 180,
+		BehindAngle:     120 * math.Pi / 180,
+		LoudRatio:       8,
+		Rules: []Rule{
+			{Event: EventNewSpeaker, Antennas: []float64{0.6, -0.2}, Lean: true, Cooldown: 2 * time.Second},
+			{Event: EventBehind, Emotion: "curious", Cooldown: 10 * time.Second},
+			{Event: EventLoud, Emotion: "startled", Cooldown: 10 * time.Second},
+		},
+	}
+}
+
+// Actuator executes reactions (implemented by pollen.Client)
+type Actuator interface {
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+	SetAntennas(ctx context.Context, antennas [2]float64) error
+}
+
+// EmotionPlayer plays emotions (implemented by emotion.Scheduler)
+type EmotionPlayer interface {
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// AntennaSetter moves the antennas (implemented by animation.Animator)
+type AntennaSetter interface {
+	SetAntennas(ctx context.Context, antennas [2]float64) error
+}
+
+// Event is a detected audio event
+type Event struct {
+	Name  string    `json:"name"`
+	Angle float64   `json:"angle"`
+	Time  time.Time `json:"time"`
+}
+
+// TopicEvents carries detected gesture events on the event bus
+var TopicEvents = bus.NewTopic[Event]("gesture.event")
+
+// Engine detects audio events and fires matching rules
+type Engine struct {
+	cfg      Config
+	actuator Actuator
+	logger   *slog.Logger
+
+	// Detection state (only touched from Run)
+	lastSpeaking   bool
+	lastSpeakerAng float64
+	haveSpeaker    bool
+	energyAvg      float64
+
+	mu        sync.Mutex
+	lastFired map[int]time.Time
+
+	// Callbacks
+	onEvent  func(Event)
+	emotions EmotionPlayer // Overrides actuator for emotions
+	antennas AntennaSetter // Overrides actuator for antenna moves
+
+	// Stats
+	eventsDetected atomic.Uint64
+	rulesFired     atomic.Uint64
+	actionErrors   atomic.Uint64
+}
+
+// NewEngine creates a new gesture engine
+func NewEngine(cfg Config, actuator Actuator, logger *slog.Logger) *Engine {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Engine{
+		cfg:       cfg,
+		actuator:  actuator,
+		logger:    logger,
+		lastFired: make(map[int]time.Time),
+	}
+}
+
+// OnEvent sets a callback for every detected event (fired or not)
+func (e *Engine) OnEvent(callback func(Event)) {
+	e.mu.Lock()
+	e.onEvent = callback
+	e.mu.Unlock()
+}
+
+// SetEmotionPlayer routes rule emotions through p instead of the actuator,
+// e.g. to share a scheduler that prevents overlapping animations
+func (e *Engine) SetEmotionPlayer(p EmotionPlayer) {
+	e.mu.Lock()
+	e.emotions = p
+	e.mu.Unlock()
+}
+
+// SetAntennaSetter routes rule antenna moves through a instead of the
+// actuator, e.g. to blend them into the antenna animation rather than have
+// it overwrite them on its next frame
+func (e *Engine) SetAntennaSetter(a AntennaSetter) {
+	e.mu.Lock()
+	e.antennas = a
+	e.mu.Unlock()
+}
+
+// PublishTo routes gesture events to the event bus, replacing OnEvent
+func (e *Engine) PublishTo(b *bus.Bus) {
+	e.OnEvent(bus.Publisher(b, TopicEvents))
+}
+
+// Run consumes tracker results until ctx is cancelled or the channel closes
+func (e *Engine) Run(ctx context.Context, results <-chan doa.Result) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			for _, ev := range e.Detect(result) {
+				e.handle(ctx, ev)
+			}
+		}
+	}
+}
+
+// Detect returns the events implied by a new tracker result
+func (e *Engine) Detect(result doa.Result) []Event {
+	now := result.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	angle := result.SmoothedAngle
+
+	var events []Event
+
+	if result.SpeakingLatched && !e.lastSpeaking {
+		events = append(events, Event{Name: EventSpeechStart, Angle: angle, Time: now})
+
+		if e.haveSpeaker && math.Abs(doa.NormalizeAngle(angle-e.lastSpeakerAng)) > e.cfg.NewSpeakerAngle {
+			events = append(events, Event{Name: EventNewSpeaker, Angle: angle, Time: now})
+		}
+		if math.Abs(angle) > e.cfg.BehindAngle {
+			events = append(events, Event{Name: EventBehind, Angle: angle, Time: now})
+		}
+
+		e.lastSpeakerAng = angle
+		e.haveSpeaker = true
+	}
+
+	if !result.SpeakingLatched && e.lastSpeaking {
+		events = append(events, Event{Name: EventSpeechEnd, Angle: angle, Time: now})
+	}
+	e.lastSpeaking = result.SpeakingLatched
+
+	// Sudden loud energy relative to a slow running average
+	if e.cfg.LoudRatio > 0 && e.energyAvg > 0 && result.TotalEnergy > e.energyAvg*e.cfg.LoudRatio {
+		events = append(events, Event{Name: EventLoud, Angle: result.Angle, Time: now})
+	}
+	if e.energyAvg == 0 {
+		e.energyAvg = result.TotalEnergy
+	} else {
+		e.energyAvg = 0.05*result.TotalEnergy + 0.95*e.energyAvg
+	}
+
+	if len(events) > 0 {
+		e.eventsDetected.Add(uint64(len(events)))
+	}
+	return events
+}
+
+// handle notifies listeners and fires matching rules
+func (e *Engine) handle(ctx context.Context, ev Event) {
+	e.mu.Lock()
+	callback := e.onEvent
+	e.mu.Unlock()
+
+	if callback != nil {
+		callback(ev)
+	}
+
+	for i, rule := range e.cfg.Rules {
+		if rule.Event != ev.Name || !e.claim(i, rule.Cooldown, ev.Time) {
+			continue
+		}
+
+		e.rulesFired.Add(1)
+		e.logger.Debug("gesture fired", "event", ev.Name, "emotion", rule.Emotion, "angle", ev.Angle)
+		e.execute(ctx, rule, ev)
+	}
+}
+
+// claim checks and records the cooldown for a rule
+func (e *Engine) claim(index int, cooldown time.Duration, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if last, ok := e.lastFired[index]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	e.lastFired[index] = now
+	return true
+}
+
+func (e *Engine) execute(ctx context.Context, rule Rule, ev Event) {
+	if e.actuator == nil {
+		return
+	}
+
+	if len(rule.Antennas) == 2 {
+		antennas := [2]float64{rule.Antennas[0], rule.Antennas[1]}
+		// Rules are written for a speaker on the left; mirror for the right
+		if rule.Lean && ev.Angle < 0 {
+			antennas = [2]float64{rule.Antennas[1], rule.Antennas[0]}
+		}
+		e.mu.Lock()
+		var setter AntennaSetter = e.actuator
+		if e.antennas != nil {
+			setter = e.antennas
+		}
+		e.mu.Unlock()
+
+		if err := setter.SetAntennas(ctx, antennas); err != nil {
+			e.actionErrors.Add(1)
+			e.logger.Warn("gesture antenna move failed", "error", err)
+		}
+	}
+
+	if rule.Emotion != "" {
+		e.mu.Lock()
+		var player EmotionPlayer = e.actuator
+		if e.emotions != nil {
+			player = e.emotions
+		}
+		e.mu.Unlock()
+
+		if err := player.PlayEmotion(ctx, rule.Emotion, rule.Duration); err != nil {
+			e.actionErrors.Add(1)
+			e.logger.Warn("gesture emotion failed", "emotion", rule.Emotion, "error", err)
+		}
+	}
+}
+
+// Stats contains gesture engine statistics
+type Stats struct {
+	EventsDetected uint64 `json:"events_detected"`
+	RulesFired     uint64 `json:"rules_fired"`
+	ActionErrors   uint64 `json:"action_errors"`
+}
+
+// GetStats returns gesture engine statistics
+func (e *Engine) GetStats() Stats {
+	return Stats{
+		EventsDetected: e.eventsDetected.Load(),
+		RulesFired:     e.rulesFired.Load(),
+		ActionErrors:   e.actionErrors.Load(),
+	}
+}
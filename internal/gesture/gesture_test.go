@@ -0,0 +1,169 @@
+package gesture
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+type fakeActuator struct {
+	mu       sync.Mutex
+	emotions []string
+	antennas [][2]float64
+}
+
+func (f *fakeActuator) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.emotions = append(f.emotions, name)
+	return nil
+}
+
+func (f *fakeActuator) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.antennas = append(f.antennas, antennas)
+	return nil
+}
+
+func result(ts time.Time, angle float64, speaking bool, energy float64) doa.Result {
+	return doa.Result{
+		Reading:         doa.Reading{Angle: angle, Timestamp: ts, TotalEnergy: energy},
+		SmoothedAngle:   angle,
+		SpeakingLatched: speaking,
+	}
+}
+
+func names(events []Event) []string {
+	out := make([]string, len(events))
+	for i, ev := range events {
+		out[i] = ev.Name
+	}
+	return out
+}
+
+func TestDetect_SpeechAndNewSpeaker(t *testing.T) {
+	e := NewEngine(DefaultConfig(), nil, nil)
+	now := time.Now()
+
+	if got := names(e.Detect(result(now, 0.1, true, 1))); len(got) != 1 || got[0] != EventSpeechStart {
+		t.Errorf("first speech should only be speech_start, got %v", got)
+	}
+
+	if got := names(e.Detect(result(now, 0.1, false, 1))); len(got) != 1 || got[0] != EventSpeechEnd {
+		t.Errorf("expected speech_end, got %v", got)
+	}
+
+	// Second speaker 90° away
+	got := names(e.Detect(result(now, 1.6, true, 1)))
+	if len(got) != 2 || got[1] != EventNewSpeaker {
+		t.Errorf("expected speech_start + new_speaker, got %v", got)
+	}
+}
+
+func TestDetect_Behind(t *testing.T) {
+	e := NewEngine(DefaultConfig(), nil, nil)
+
+	got := names(e.Detect(result(time.Now(), 3.0, true, 1)))
+	if len(got) != 2 || got[1] != EventBehind {
+		t.Errorf("expected behind event, got %v", got)
+	}
+}
+
+func TestDetect_Loud(t *testing.T) {
+	e := NewEngine(DefaultConfig(), nil, nil)
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		e.Detect(result(now, 0, false, 100))
+	}
+
+	got := names(e.Detect(result(now, 0, false, 10000)))
+	if len(got) != 1 || got[0] != EventLoud {
+		t.Errorf("expected loud event, got %v", got)
+	}
+}
+
+func TestRun_FiresRulesWithCooldown(t *testing.T) {
+	act := &fakeActuator{}
+	e := NewEngine(DefaultConfig(), act, nil)
+
+	results := make(chan doa.Result, 10)
+	now := time.Now()
+	// Two behind-speech onsets within the cooldown: only one emotion
+	results <- result(now, 3.0, true, 1)
+	results <- result(now.Add(100*time.Millisecond), 3.0, false, 1)
+	results <- result(now.Add(200*time.Millisecond), 3.0, true, 1)
+	close(results)
+
+	e.Run(context.Background(), results)
+
+	act.mu.Lock()
+	defer act.mu.Unlock()
+	if len(act.emotions) != 1 || act.emotions[0] != "curious" {
+		t.Errorf("expected a single curious emotion, got %v", act.emotions)
+	}
+}
+
+func TestRun_EmotionPlayerOverridesActuator(t *testing.T) {
+	act := &fakeActuator{}
+	emotions := &fakeActuator{}
+	e := NewEngine(DefaultConfig(), act, nil)
+	e.SetEmotionPlayer(emotions)
+
+	results := make(chan doa.Result, 10)
+	results <- result(time.Now(), 3.0, true, 1)
+	close(results)
+
+	e.Run(context.Background(), results)
+
+	if len(act.emotions) != 0 || len(emotions.emotions) != 1 {
+		t.Errorf("emotions went to actuator %v and player %v, want the player only", act.emotions, emotions.emotions)
+	}
+}
+
+func TestRun_AntennaSetterOverridesActuator(t *testing.T) {
+	act := &fakeActuator{}
+	animator := &fakeActuator{}
+	e := NewEngine(DefaultConfig(), act, nil)
+	e.SetAntennaSetter(animator)
+
+	results := make(chan doa.Result, 10)
+	now := time.Now()
+	results <- result(now, 0.8, true, 1)
+	results <- result(now.Add(100*time.Millisecond), 0.8, false, 1)
+	results <- result(now.Add(200*time.Millisecond), -0.8, true, 1)
+	close(results)
+
+	e.Run(context.Background(), results)
+
+	if len(act.antennas) != 0 || len(animator.antennas) == 0 {
+		t.Errorf("antenna moves went to actuator %v and setter %v, want the setter only", act.antennas, animator.antennas)
+	}
+}
+
+func TestRun_LeanMirrorsForRightSide(t *testing.T) {
+	act := &fakeActuator{}
+	e := NewEngine(DefaultConfig(), act, nil)
+
+	results := make(chan doa.Result, 10)
+	now := time.Now()
+	results <- result(now, 0.8, true, 1)
+	results <- result(now.Add(100*time.Millisecond), 0.8, false, 1)
+	results <- result(now.Add(200*time.Millisecond), -0.8, true, 1)
+	close(results)
+
+	e.Run(context.Background(), results)
+
+	act.mu.Lock()
+	defer act.mu.Unlock()
+	if len(act.antennas) != 1 {
+		t.Fatalf("expected one antenna move, got %v", act.antennas)
+	}
+	if act.antennas[0] != [2]float64{-0.2, 0.6} {
+		t.Errorf("expected mirrored antennas for right side, got %v", act.antennas[0])
+	}
+}
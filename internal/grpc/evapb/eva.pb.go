@@ -0,0 +1,749 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: internal/grpc/evapb/eva.proto
+
+package evapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StreamDOARequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxRateHz     float64                `protobuf:"fixed64,1,opt,name=max_rate_hz,json=maxRateHz,proto3" json:"max_rate_hz,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamDOARequest) Reset() {
+	*x = StreamDOARequest{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamDOARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDOARequest) ProtoMessage() {}
+
+func (x *StreamDOARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDOARequest.ProtoReflect.Descriptor instead.
+func (*StreamDOARequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamDOARequest) GetMaxRateHz() float64 {
+	if x != nil {
+		return x.MaxRateHz
+	}
+	return 0
+}
+
+type GetLatestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestRequest) Reset() {
+	*x = GetLatestRequest{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestRequest) ProtoMessage() {}
+
+func (x *GetLatestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{1}
+}
+
+type DOAResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TimestampMs     int64                  `protobuf:"varint,1,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	Angle           float64                `protobuf:"fixed64,2,opt,name=angle,proto3" json:"angle,omitempty"`
+	SmoothedAngle   float64                `protobuf:"fixed64,3,opt,name=smoothed_angle,json=smoothedAngle,proto3" json:"smoothed_angle,omitempty"`
+	Confidence      float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Speaking        bool                   `protobuf:"varint,5,opt,name=speaking,proto3" json:"speaking,omitempty"`
+	SpeakingLatched bool                   `protobuf:"varint,6,opt,name=speaking_latched,json=speakingLatched,proto3" json:"speaking_latched,omitempty"`
+	TotalEnergy     float64                `protobuf:"fixed64,7,opt,name=total_energy,json=totalEnergy,proto3" json:"total_energy,omitempty"`
+	EstX            float64                `protobuf:"fixed64,8,opt,name=est_x,json=estX,proto3" json:"est_x,omitempty"`
+	EstY            float64                `protobuf:"fixed64,9,opt,name=est_y,json=estY,proto3" json:"est_y,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DOAResult) Reset() {
+	*x = DOAResult{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DOAResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DOAResult) ProtoMessage() {}
+
+func (x *DOAResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DOAResult.ProtoReflect.Descriptor instead.
+func (*DOAResult) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DOAResult) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *DOAResult) GetAngle() float64 {
+	if x != nil {
+		return x.Angle
+	}
+	return 0
+}
+
+func (x *DOAResult) GetSmoothedAngle() float64 {
+	if x != nil {
+		return x.SmoothedAngle
+	}
+	return 0
+}
+
+func (x *DOAResult) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *DOAResult) GetSpeaking() bool {
+	if x != nil {
+		return x.Speaking
+	}
+	return false
+}
+
+func (x *DOAResult) GetSpeakingLatched() bool {
+	if x != nil {
+		return x.SpeakingLatched
+	}
+	return false
+}
+
+func (x *DOAResult) GetTotalEnergy() float64 {
+	if x != nil {
+		return x.TotalEnergy
+	}
+	return 0
+}
+
+func (x *DOAResult) GetEstX() float64 {
+	if x != nil {
+		return x.EstX
+	}
+	return 0
+}
+
+func (x *DOAResult) GetEstY() float64 {
+	if x != nil {
+		return x.EstY
+	}
+	return 0
+}
+
+type GetTrackerStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrackerStatsRequest) Reset() {
+	*x = GetTrackerStatsRequest{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrackerStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrackerStatsRequest) ProtoMessage() {}
+
+func (x *GetTrackerStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrackerStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetTrackerStatsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{3}
+}
+
+type TrackerStats struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PollCount         int64                  `protobuf:"varint,1,opt,name=poll_count,json=pollCount,proto3" json:"poll_count,omitempty"`
+	ErrorCount        int64                  `protobuf:"varint,2,opt,name=error_count,json=errorCount,proto3" json:"error_count,omitempty"`
+	AvgLatencyMs      float64                `protobuf:"fixed64,3,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"`
+	SubscriberCount   int32                  `protobuf:"varint,4,opt,name=subscriber_count,json=subscriberCount,proto3" json:"subscriber_count,omitempty"`
+	SourceHealthy     bool                   `protobuf:"varint,5,opt,name=source_healthy,json=sourceHealthy,proto3" json:"source_healthy,omitempty"`
+	SpeakingLatched   bool                   `protobuf:"varint,6,opt,name=speaking_latched,json=speakingLatched,proto3" json:"speaking_latched,omitempty"`
+	CurrentAngle      float64                `protobuf:"fixed64,7,opt,name=current_angle,json=currentAngle,proto3" json:"current_angle,omitempty"`
+	CurrentConfidence float64                `protobuf:"fixed64,8,opt,name=current_confidence,json=currentConfidence,proto3" json:"current_confidence,omitempty"`
+	InUtterance       bool                   `protobuf:"varint,9,opt,name=in_utterance,json=inUtterance,proto3" json:"in_utterance,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TrackerStats) Reset() {
+	*x = TrackerStats{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackerStats) ProtoMessage() {}
+
+func (x *TrackerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackerStats.ProtoReflect.Descriptor instead.
+func (*TrackerStats) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TrackerStats) GetPollCount() int64 {
+	if x != nil {
+		return x.PollCount
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetErrorCount() int64 {
+	if x != nil {
+		return x.ErrorCount
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetAvgLatencyMs() float64 {
+	if x != nil {
+		return x.AvgLatencyMs
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetSubscriberCount() int32 {
+	if x != nil {
+		return x.SubscriberCount
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetSourceHealthy() bool {
+	if x != nil {
+		return x.SourceHealthy
+	}
+	return false
+}
+
+func (x *TrackerStats) GetSpeakingLatched() bool {
+	if x != nil {
+		return x.SpeakingLatched
+	}
+	return false
+}
+
+func (x *TrackerStats) GetCurrentAngle() float64 {
+	if x != nil {
+		return x.CurrentAngle
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetCurrentConfidence() float64 {
+	if x != nil {
+		return x.CurrentConfidence
+	}
+	return 0
+}
+
+func (x *TrackerStats) GetInUtterance() bool {
+	if x != nil {
+		return x.InUtterance
+	}
+	return false
+}
+
+type HeadTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Z             float64                `protobuf:"fixed64,3,opt,name=z,proto3" json:"z,omitempty"`
+	Roll          float64                `protobuf:"fixed64,4,opt,name=roll,proto3" json:"roll,omitempty"`
+	Pitch         float64                `protobuf:"fixed64,5,opt,name=pitch,proto3" json:"pitch,omitempty"`
+	Yaw           float64                `protobuf:"fixed64,6,opt,name=yaw,proto3" json:"yaw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeadTarget) Reset() {
+	*x = HeadTarget{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeadTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadTarget) ProtoMessage() {}
+
+func (x *HeadTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadTarget.ProtoReflect.Descriptor instead.
+func (*HeadTarget) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeadTarget) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *HeadTarget) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *HeadTarget) GetZ() float64 {
+	if x != nil {
+		return x.Z
+	}
+	return 0
+}
+
+func (x *HeadTarget) GetRoll() float64 {
+	if x != nil {
+		return x.Roll
+	}
+	return 0
+}
+
+func (x *HeadTarget) GetPitch() float64 {
+	if x != nil {
+		return x.Pitch
+	}
+	return 0
+}
+
+func (x *HeadTarget) GetYaw() float64 {
+	if x != nil {
+		return x.Yaw
+	}
+	return 0
+}
+
+type SetTargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Head          *HeadTarget            `protobuf:"bytes,1,opt,name=head,proto3" json:"head,omitempty"`
+	Antennas      []float64              `protobuf:"fixed64,2,rep,packed,name=antennas,proto3" json:"antennas,omitempty"`
+	BodyYaw       float64                `protobuf:"fixed64,3,opt,name=body_yaw,json=bodyYaw,proto3" json:"body_yaw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTargetRequest) Reset() {
+	*x = SetTargetRequest{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTargetRequest) ProtoMessage() {}
+
+func (x *SetTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTargetRequest.ProtoReflect.Descriptor instead.
+func (*SetTargetRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetTargetRequest) GetHead() *HeadTarget {
+	if x != nil {
+		return x.Head
+	}
+	return nil
+}
+
+func (x *SetTargetRequest) GetAntennas() []float64 {
+	if x != nil {
+		return x.Antennas
+	}
+	return nil
+}
+
+func (x *SetTargetRequest) GetBodyYaw() float64 {
+	if x != nil {
+		return x.BodyYaw
+	}
+	return 0
+}
+
+type SetTargetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTargetResponse) Reset() {
+	*x = SetTargetResponse{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTargetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTargetResponse) ProtoMessage() {}
+
+func (x *SetTargetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTargetResponse.ProtoReflect.Descriptor instead.
+func (*SetTargetResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{7}
+}
+
+type PlayEmotionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Duration      float64                `protobuf:"fixed64,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayEmotionRequest) Reset() {
+	*x = PlayEmotionRequest{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayEmotionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayEmotionRequest) ProtoMessage() {}
+
+func (x *PlayEmotionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayEmotionRequest.ProtoReflect.Descriptor instead.
+func (*PlayEmotionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PlayEmotionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PlayEmotionRequest) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type PlayEmotionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayEmotionResponse) Reset() {
+	*x = PlayEmotionResponse{}
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayEmotionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayEmotionResponse) ProtoMessage() {}
+
+func (x *PlayEmotionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_evapb_eva_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayEmotionResponse.ProtoReflect.Descriptor instead.
+func (*PlayEmotionResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_evapb_eva_proto_rawDescGZIP(), []int{9}
+}
+
+var File_internal_grpc_evapb_eva_proto protoreflect.FileDescriptor
+
+const file_internal_grpc_evapb_eva_proto_rawDesc = "" +
+	"\n" +
+	"\x1dinternal/grpc/evapb/eva.proto\x12\x06eva.v1\"2\n" +
+	"\x10StreamDOARequest\x12\x1e\n" +
+	"\vmax_rate_hz\x18\x01 \x01(\x01R\tmaxRateHz\"\x12\n" +
+	"\x10GetLatestRequest\"\x9f\x02\n" +
+	"\tDOAResult\x12!\n" +
+	"\ftimestamp_ms\x18\x01 \x01(\x03R\vtimestampMs\x12\x14\n" +
+	"\x05angle\x18\x02 \x01(\x01R\x05angle\x12%\n" +
+	"\x0esmoothed_angle\x18\x03 \x01(\x01R\rsmoothedAngle\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12\x1a\n" +
+	"\bspeaking\x18\x05 \x01(\bR\bspeaking\x12)\n" +
+	"\x10speaking_latched\x18\x06 \x01(\bR\x0fspeakingLatched\x12!\n" +
+	"\ftotal_energy\x18\a \x01(\x01R\vtotalEnergy\x12\x13\n" +
+	"\x05est_x\x18\b \x01(\x01R\x04estX\x12\x13\n" +
+	"\x05est_y\x18\t \x01(\x01R\x04estY\"\x18\n" +
+	"\x16GetTrackerStatsRequest\"\xe8\x02\n" +
+	"\fTrackerStats\x12\x1d\n" +
+	"\n" +
+	"poll_count\x18\x01 \x01(\x03R\tpollCount\x12\x1f\n" +
+	"\verror_count\x18\x02 \x01(\x03R\n" +
+	"errorCount\x12$\n" +
+	"\x0eavg_latency_ms\x18\x03 \x01(\x01R\favgLatencyMs\x12)\n" +
+	"\x10subscriber_count\x18\x04 \x01(\x05R\x0fsubscriberCount\x12%\n" +
+	"\x0esource_healthy\x18\x05 \x01(\bR\rsourceHealthy\x12)\n" +
+	"\x10speaking_latched\x18\x06 \x01(\bR\x0fspeakingLatched\x12#\n" +
+	"\rcurrent_angle\x18\a \x01(\x01R\fcurrentAngle\x12-\n" +
+	"\x12current_confidence\x18\b \x01(\x01R\x11currentConfidence\x12!\n" +
+	"\fin_utterance\x18\t \x01(\bR\vinUtterance\"r\n" +
+	"\n" +
+	"HeadTarget\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\x12\f\n" +
+	"\x01z\x18\x03 \x01(\x01R\x01z\x12\x12\n" +
+	"\x04roll\x18\x04 \x01(\x01R\x04roll\x12\x14\n" +
+	"\x05pitch\x18\x05 \x01(\x01R\x05pitch\x12\x10\n" +
+	"\x03yaw\x18\x06 \x01(\x01R\x03yaw\"q\n" +
+	"\x10SetTargetRequest\x12&\n" +
+	"\x04head\x18\x01 \x01(\v2\x12.eva.v1.HeadTargetR\x04head\x12\x1a\n" +
+	"\bantennas\x18\x02 \x03(\x01R\bantennas\x12\x19\n" +
+	"\bbody_yaw\x18\x03 \x01(\x01R\abodyYaw\"\x13\n" +
+	"\x11SetTargetResponse\"D\n" +
+	"\x12PlayEmotionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bduration\x18\x02 \x01(\x01R\bduration\"\x15\n" +
+	"\x13PlayEmotionResponse2\xcb\x01\n" +
+	"\n" +
+	"DOAService\x12:\n" +
+	"\tStreamDOA\x12\x18.eva.v1.StreamDOARequest\x1a\x11.eva.v1.DOAResult0\x01\x128\n" +
+	"\tGetLatest\x12\x18.eva.v1.GetLatestRequest\x1a\x11.eva.v1.DOAResult\x12G\n" +
+	"\x0fGetTrackerStats\x12\x1e.eva.v1.GetTrackerStatsRequest\x1a\x14.eva.v1.TrackerStats2\x98\x01\n" +
+	"\fMotorService\x12@\n" +
+	"\tSetTarget\x12\x18.eva.v1.SetTargetRequest\x1a\x19.eva.v1.SetTargetResponse\x12F\n" +
+	"\vPlayEmotion\x12\x1a.eva.v1.PlayEmotionRequest\x1a\x1b.eva.v1.PlayEmotionResponseB2Z0github.com/teslashibe/go-eva/internal/grpc/evapbb\x06proto3"
+
+var (
+	file_internal_grpc_evapb_eva_proto_rawDescOnce sync.Once
+	file_internal_grpc_evapb_eva_proto_rawDescData []byte
+)
+
+func file_internal_grpc_evapb_eva_proto_rawDescGZIP() []byte {
+	file_internal_grpc_evapb_eva_proto_rawDescOnce.Do(func() {
+		file_internal_grpc_evapb_eva_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_grpc_evapb_eva_proto_rawDesc), len(file_internal_grpc_evapb_eva_proto_rawDesc)))
+	})
+	return file_internal_grpc_evapb_eva_proto_rawDescData
+}
+
+var file_internal_grpc_evapb_eva_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_internal_grpc_evapb_eva_proto_goTypes = []any{
+	(*StreamDOARequest)(nil),       // 0: eva.v1.StreamDOARequest
+	(*GetLatestRequest)(nil),       // 1: eva.v1.GetLatestRequest
+	(*DOAResult)(nil),              // 2: eva.v1.DOAResult
+	(*GetTrackerStatsRequest)(nil), // 3: eva.v1.GetTrackerStatsRequest
+	(*TrackerStats)(nil),           // 4: eva.v1.TrackerStats
+	(*HeadTarget)(nil),             // 5: eva.v1.HeadTarget
+	(*SetTargetRequest)(nil),       // 6: eva.v1.SetTargetRequest
+	(*SetTargetResponse)(nil),      // 7: eva.v1.SetTargetResponse
+	(*PlayEmotionRequest)(nil),     // 8: eva.v1.PlayEmotionRequest
+	(*PlayEmotionResponse)(nil),    // 9: eva.v1.PlayEmotionResponse
+}
+var file_internal_grpc_evapb_eva_proto_depIdxs = []int32{
+	5, // 0: eva.v1.SetTargetRequest.head:type_name -> eva.v1.HeadTarget
+	0, // 1: eva.v1.DOAService.StreamDOA:input_type -> eva.v1.StreamDOARequest
+	1, // 2: eva.v1.DOAService.GetLatest:input_type -> eva.v1.GetLatestRequest
+	3, // 3: eva.v1.DOAService.GetTrackerStats:input_type -> eva.v1.GetTrackerStatsRequest
+	6, // 4: eva.v1.MotorService.SetTarget:input_type -> eva.v1.SetTargetRequest
+	8, // 5: eva.v1.MotorService.PlayEmotion:input_type -> eva.v1.PlayEmotionRequest
+	2, // 6: eva.v1.DOAService.StreamDOA:output_type -> eva.v1.DOAResult
+	2, // 7: eva.v1.DOAService.GetLatest:output_type -> eva.v1.DOAResult
+	4, // 8: eva.v1.DOAService.GetTrackerStats:output_type -> eva.v1.TrackerStats
+	7, // 9: eva.v1.MotorService.SetTarget:output_type -> eva.v1.SetTargetResponse
+	9, // 10: eva.v1.MotorService.PlayEmotion:output_type -> eva.v1.PlayEmotionResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_internal_grpc_evapb_eva_proto_init() }
+func file_internal_grpc_evapb_eva_proto_init() {
+	if File_internal_grpc_evapb_eva_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_grpc_evapb_eva_proto_rawDesc), len(file_internal_grpc_evapb_eva_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_internal_grpc_evapb_eva_proto_goTypes,
+		DependencyIndexes: file_internal_grpc_evapb_eva_proto_depIdxs,
+		MessageInfos:      file_internal_grpc_evapb_eva_proto_msgTypes,
+	}.Build()
+	File_internal_grpc_evapb_eva_proto = out.File
+	file_internal_grpc_evapb_eva_proto_goTypes = nil
+	file_internal_grpc_evapb_eva_proto_depIdxs = nil
+}
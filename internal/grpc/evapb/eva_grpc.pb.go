@@ -0,0 +1,341 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: internal/grpc/evapb/eva.proto
+
+package evapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DOAService_StreamDOA_FullMethodName       = "/eva.v1.DOAService/StreamDOA"
+	DOAService_GetLatest_FullMethodName       = "/eva.v1.DOAService/GetLatest"
+	DOAService_GetTrackerStats_FullMethodName = "/eva.v1.DOAService/GetTrackerStats"
+)
+
+// DOAServiceClient is the client API for DOAService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DOAServiceClient interface {
+	StreamDOA(ctx context.Context, in *StreamDOARequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DOAResult], error)
+	GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*DOAResult, error)
+	GetTrackerStats(ctx context.Context, in *GetTrackerStatsRequest, opts ...grpc.CallOption) (*TrackerStats, error)
+}
+
+type dOAServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDOAServiceClient(cc grpc.ClientConnInterface) DOAServiceClient {
+	return &dOAServiceClient{cc}
+}
+
+func (c *dOAServiceClient) StreamDOA(ctx context.Context, in *StreamDOARequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DOAResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DOAService_ServiceDesc.Streams[0], DOAService_StreamDOA_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamDOARequest, DOAResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DOAService_StreamDOAClient = grpc.ServerStreamingClient[DOAResult]
+
+func (c *dOAServiceClient) GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*DOAResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DOAResult)
+	err := c.cc.Invoke(ctx, DOAService_GetLatest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dOAServiceClient) GetTrackerStats(ctx context.Context, in *GetTrackerStatsRequest, opts ...grpc.CallOption) (*TrackerStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrackerStats)
+	err := c.cc.Invoke(ctx, DOAService_GetTrackerStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DOAServiceServer is the server API for DOAService service.
+// All implementations must embed UnimplementedDOAServiceServer
+// for forward compatibility.
+type DOAServiceServer interface {
+	StreamDOA(*StreamDOARequest, grpc.ServerStreamingServer[DOAResult]) error
+	GetLatest(context.Context, *GetLatestRequest) (*DOAResult, error)
+	GetTrackerStats(context.Context, *GetTrackerStatsRequest) (*TrackerStats, error)
+	mustEmbedUnimplementedDOAServiceServer()
+}
+
+// UnimplementedDOAServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDOAServiceServer struct{}
+
+func (UnimplementedDOAServiceServer) StreamDOA(*StreamDOARequest, grpc.ServerStreamingServer[DOAResult]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDOA not implemented")
+}
+func (UnimplementedDOAServiceServer) GetLatest(context.Context, *GetLatestRequest) (*DOAResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatest not implemented")
+}
+func (UnimplementedDOAServiceServer) GetTrackerStats(context.Context, *GetTrackerStatsRequest) (*TrackerStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrackerStats not implemented")
+}
+func (UnimplementedDOAServiceServer) mustEmbedUnimplementedDOAServiceServer() {}
+func (UnimplementedDOAServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeDOAServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DOAServiceServer will
+// result in compilation errors.
+type UnsafeDOAServiceServer interface {
+	mustEmbedUnimplementedDOAServiceServer()
+}
+
+func RegisterDOAServiceServer(s grpc.ServiceRegistrar, srv DOAServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDOAServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DOAService_ServiceDesc, srv)
+}
+
+func _DOAService_StreamDOA_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDOARequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DOAServiceServer).StreamDOA(m, &grpc.GenericServerStream[StreamDOARequest, DOAResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DOAService_StreamDOAServer = grpc.ServerStreamingServer[DOAResult]
+
+func _DOAService_GetLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DOAServiceServer).GetLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DOAService_GetLatest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DOAServiceServer).GetLatest(ctx, req.(*GetLatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DOAService_GetTrackerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrackerStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DOAServiceServer).GetTrackerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DOAService_GetTrackerStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DOAServiceServer).GetTrackerStats(ctx, req.(*GetTrackerStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DOAService_ServiceDesc is the grpc.ServiceDesc for DOAService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DOAService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eva.v1.DOAService",
+	HandlerType: (*DOAServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLatest",
+			Handler:    _DOAService_GetLatest_Handler,
+		},
+		{
+			MethodName: "GetTrackerStats",
+			Handler:    _DOAService_GetTrackerStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDOA",
+			Handler:       _DOAService_StreamDOA_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/grpc/evapb/eva.proto",
+}
+
+const (
+	MotorService_SetTarget_FullMethodName   = "/eva.v1.MotorService/SetTarget"
+	MotorService_PlayEmotion_FullMethodName = "/eva.v1.MotorService/PlayEmotion"
+)
+
+// MotorServiceClient is the client API for MotorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MotorServiceClient interface {
+	SetTarget(ctx context.Context, in *SetTargetRequest, opts ...grpc.CallOption) (*SetTargetResponse, error)
+	PlayEmotion(ctx context.Context, in *PlayEmotionRequest, opts ...grpc.CallOption) (*PlayEmotionResponse, error)
+}
+
+type motorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMotorServiceClient(cc grpc.ClientConnInterface) MotorServiceClient {
+	return &motorServiceClient{cc}
+}
+
+func (c *motorServiceClient) SetTarget(ctx context.Context, in *SetTargetRequest, opts ...grpc.CallOption) (*SetTargetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetTargetResponse)
+	err := c.cc.Invoke(ctx, MotorService_SetTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motorServiceClient) PlayEmotion(ctx context.Context, in *PlayEmotionRequest, opts ...grpc.CallOption) (*PlayEmotionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlayEmotionResponse)
+	err := c.cc.Invoke(ctx, MotorService_PlayEmotion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MotorServiceServer is the server API for MotorService service.
+// All implementations must embed UnimplementedMotorServiceServer
+// for forward compatibility.
+type MotorServiceServer interface {
+	SetTarget(context.Context, *SetTargetRequest) (*SetTargetResponse, error)
+	PlayEmotion(context.Context, *PlayEmotionRequest) (*PlayEmotionResponse, error)
+	mustEmbedUnimplementedMotorServiceServer()
+}
+
+// UnimplementedMotorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMotorServiceServer struct{}
+
+func (UnimplementedMotorServiceServer) SetTarget(context.Context, *SetTargetRequest) (*SetTargetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTarget not implemented")
+}
+func (UnimplementedMotorServiceServer) PlayEmotion(context.Context, *PlayEmotionRequest) (*PlayEmotionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlayEmotion not implemented")
+}
+func (UnimplementedMotorServiceServer) mustEmbedUnimplementedMotorServiceServer() {}
+func (UnimplementedMotorServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeMotorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MotorServiceServer will
+// result in compilation errors.
+type UnsafeMotorServiceServer interface {
+	mustEmbedUnimplementedMotorServiceServer()
+}
+
+func RegisterMotorServiceServer(s grpc.ServiceRegistrar, srv MotorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMotorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MotorService_ServiceDesc, srv)
+}
+
+func _MotorService_SetTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotorServiceServer).SetTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotorService_SetTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotorServiceServer).SetTarget(ctx, req.(*SetTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotorService_PlayEmotion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayEmotionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotorServiceServer).PlayEmotion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotorService_PlayEmotion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotorServiceServer).PlayEmotion(ctx, req.(*PlayEmotionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MotorService_ServiceDesc is the grpc.ServiceDesc for MotorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MotorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eva.v1.MotorService",
+	HandlerType: (*MotorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetTarget",
+			Handler:    _MotorService_SetTarget_Handler,
+		},
+		{
+			MethodName: "PlayEmotion",
+			Handler:    _MotorService_PlayEmotion_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/grpc/evapb/eva.proto",
+}
@@ -0,0 +1,350 @@
+// Package grpc serves the typed gRPC API for other on-robot services,
+// alongside the HTTP server. The API is defined in evapb/eva.proto.
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/grpc/evapb"
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// Config holds gRPC server configuration
+type Config struct {
+	Bind    string   // Listen address ("" = all interfaces)
+	Port    int      // Listen port (separate from the HTTP server)
+	APIKeys []string // Accepted keys (empty = no authentication)
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Bind: "127.0.0.1",
+		Port: 9001,
+	}
+}
+
+// Tracker is the part of the DOA tracker the API needs
+type Tracker interface {
+	Subscribe() chan doa.Result
+	Unsubscribe(ch chan doa.Result)
+	GetLatest() doa.Result
+	Stats() doa.TrackerStats
+}
+
+// Motor moves the head; normally the safety limiter
+type Motor interface {
+	SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// EmotionPlayer queues emotions; normally the emotion scheduler
+type EmotionPlayer interface {
+	PlayEmotion(ctx context.Context, name string, duration float64) error
+}
+
+// MoveFunc moves the head
+type MoveFunc func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error
+
+// Server hosts the DOA and motor services
+type Server struct {
+	evapb.UnimplementedDOAServiceServer
+	evapb.UnimplementedMotorServiceServer
+
+	cfg      Config
+	logger   *slog.Logger
+	tracker  Tracker
+	motor    Motor
+	move     MoveFunc      // Overrides motor for SetTarget
+	emotions EmotionPlayer // Overrides motor for PlayEmotion
+	srv      *grpclib.Server
+
+	// Stats
+	streams  atomic.Int64
+	requests atomic.Uint64
+}
+
+// NewServer creates a gRPC server; motor may be nil to leave MotorService
+// unimplemented
+func NewServer(cfg Config, tracker Tracker, motor Motor, logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Server{
+		cfg:     cfg,
+		logger:  logger,
+		tracker: tracker,
+		motor:   motor,
+	}
+	s.srv = grpclib.NewServer(
+		grpclib.ChainUnaryInterceptor(s.authUnary, s.countUnary),
+		grpclib.StreamInterceptor(s.authStream),
+	)
+	evapb.RegisterDOAServiceServer(s.srv, s)
+	if motor != nil {
+		evapb.RegisterMotorServiceServer(s.srv, s)
+	}
+	return s
+}
+
+// SetMove routes SetTarget through fn instead of the motor, so gRPC moves
+// take the same path as other local commands. Call before Start.
+func (s *Server) SetMove(fn MoveFunc) {
+	s.move = fn
+}
+
+// SetEmotions routes PlayEmotion through p instead of the motor, so gRPC
+// emotions queue behind cloud and gesture ones and publish the same start
+// and finish events. Call before Start.
+func (s *Server) SetEmotions(p EmotionPlayer) {
+	s.emotions = p
+}
+
+// Start listens on the configured address and serves until Stop (blocking)
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", net.JoinHostPort(s.cfg.Bind, strconv.Itoa(s.cfg.Port)))
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve handles connections on lis until Stop (blocking)
+func (s *Server) Serve(lis net.Listener) error {
+	s.logger.Info("grpc server listening", "addr", lis.Addr().String())
+	if err := s.srv.Serve(lis); err != nil && !errors.Is(err, grpclib.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Stop ends open streams and waits for in-flight calls until ctx expires
+func (s *Server) Stop(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.srv.Stop()
+	}
+}
+
+// authorize requires one of the configured keys in the x-api-key or
+// "authorization: Bearer" metadata. No keys disables authentication.
+func (s *Server) authorize(ctx context.Context) error {
+	if len(s.cfg.APIKeys) == 0 {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var key string
+	if v := md.Get("x-api-key"); len(v) > 0 {
+		key = v[0]
+	} else if v := md.Get("authorization"); len(v) > 0 {
+		if bearer, ok := strings.CutPrefix(v[0], "Bearer "); ok {
+			key = strings.TrimSpace(bearer)
+		}
+	}
+
+	if key == "" {
+		return status.Error(codes.Unauthenticated, "API key required")
+	}
+	for _, k := range s.cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid API key")
+}
+
+// authUnary rejects unauthenticated unary calls
+func (s *Server) authUnary(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStream rejects unauthenticated streams
+func (s *Server) authStream(srv interface{}, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	if err := s.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// countUnary counts unary calls
+func (s *Server) countUnary(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	s.requests.Add(1)
+	return handler(ctx, req)
+}
+
+// StreamDOA sends tracker results until the client goes away
+func (s *Server) StreamDOA(req *evapb.StreamDOARequest, stream evapb.DOAService_StreamDOAServer) error {
+	if req.GetMaxRateHz() < 0 {
+		return status.Error(codes.InvalidArgument, "max_rate_hz must not be negative")
+	}
+	var minGap time.Duration
+	if req.GetMaxRateHz() > 0 {
+		minGap = time.Duration(float64(time.Second) / req.GetMaxRateHz())
+	}
+
+	ch := s.tracker.Subscribe()
+	defer s.tracker.Unsubscribe(ch)
+
+	s.streams.Add(1)
+	defer s.streams.Add(-1)
+
+	var lastSent time.Time
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case result, ok := <-ch:
+			if !ok {
+				return status.Error(codes.Unavailable, "tracker stopped")
+			}
+			if minGap > 0 && time.Since(lastSent) < minGap {
+				continue
+			}
+			if err := stream.Send(toDOAResult(result)); err != nil {
+				return err
+			}
+			lastSent = time.Now()
+		}
+	}
+}
+
+// GetLatest returns the most recent tracker result
+func (s *Server) GetLatest(ctx context.Context, req *evapb.GetLatestRequest) (*evapb.DOAResult, error) {
+	return toDOAResult(s.tracker.GetLatest()), nil
+}
+
+// GetTrackerStats returns tracker statistics
+func (s *Server) GetTrackerStats(ctx context.Context, req *evapb.GetTrackerStatsRequest) (*evapb.TrackerStats, error) {
+	st := s.tracker.Stats()
+	return &evapb.TrackerStats{
+		PollCount:         st.PollCount,
+		ErrorCount:        st.ErrorCount,
+		AvgLatencyMs:      st.AvgLatencyMs,
+		SubscriberCount:   int32(st.SubscriberCount),
+		SourceHealthy:     st.SourceHealthy,
+		SpeakingLatched:   st.SpeakingLatched,
+		CurrentAngle:      st.CurrentAngle,
+		CurrentConfidence: st.CurrentConfidence,
+		InUtterance:       st.InUtterance,
+	}, nil
+}
+
+// SetTarget moves the head through the motor, or the SetMove path
+func (s *Server) SetTarget(ctx context.Context, req *evapb.SetTargetRequest) (*evapb.SetTargetResponse, error) {
+	var antennas [2]float64
+	switch len(req.GetAntennas()) {
+	case 0:
+	case 2:
+		copy(antennas[:], req.GetAntennas())
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "antennas needs 2 values, got %d", len(req.GetAntennas()))
+	}
+
+	h := req.GetHead()
+	head := pollen.HeadTarget{
+		X:     h.GetX(),
+		Y:     h.GetY(),
+		Z:     h.GetZ(),
+		Roll:  h.GetRoll(),
+		Pitch: h.GetPitch(),
+		Yaw:   h.GetYaw(),
+	}
+	move := s.motor.SetTarget
+	if s.move != nil {
+		move = s.move
+	}
+	if err := move(ctx, head, antennas, req.GetBodyYaw()); err != nil {
+		return nil, motorError(err)
+	}
+	return &evapb.SetTargetResponse{}, nil
+}
+
+// PlayEmotion plays an emotion animation through the motor, or queues it
+// with the SetEmotions player
+func (s *Server) PlayEmotion(ctx context.Context, req *evapb.PlayEmotionRequest) (*evapb.PlayEmotionResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	play := s.motor.PlayEmotion
+	if s.emotions != nil {
+		play = s.emotions.PlayEmotion
+	}
+	if err := play(ctx, req.GetName(), req.GetDuration()); err != nil {
+		return nil, motorError(err)
+	}
+	return &evapb.PlayEmotionResponse{}, nil
+}
+
+// motorError maps motor failures to gRPC status codes
+func motorError(err error) error {
+	switch {
+	case errors.Is(err, pollen.ErrStopped), errors.Is(err, emotion.ErrConflict), errors.Is(err, emotion.ErrBusy):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, emotion.ErrUnknown):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, emotion.ErrQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// toDOAResult converts a tracker result to its wire form
+func toDOAResult(r doa.Result) *evapb.DOAResult {
+	return &evapb.DOAResult{
+		TimestampMs:     r.Timestamp.UnixMilli(),
+		Angle:           r.Angle,
+		SmoothedAngle:   r.SmoothedAngle,
+		Confidence:      r.Confidence,
+		Speaking:        r.Speaking,
+		SpeakingLatched: r.SpeakingLatched,
+		TotalEnergy:     r.TotalEnergy,
+		EstX:            r.EstX,
+		EstY:            r.EstY,
+	}
+}
+
+// Stats contains gRPC server statistics
+type Stats struct {
+	Port         int    `json:"port"`
+	DOAStreams   int64  `json:"doa_streams"`
+	UnaryCalls   uint64 `json:"unary_calls"`
+	MotorEnabled bool   `json:"motor_enabled"`
+}
+
+// GetStats returns server statistics
+func (s *Server) GetStats() Stats {
+	return Stats{
+		Port:         s.cfg.Port,
+		DOAStreams:   s.streams.Load(),
+		UnaryCalls:   s.requests.Load(),
+		MotorEnabled: s.motor != nil,
+	}
+}
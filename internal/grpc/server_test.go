@@ -0,0 +1,254 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/grpc/evapb"
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+type fakeTracker struct {
+	mu   sync.Mutex
+	subs []chan doa.Result
+}
+
+func (f *fakeTracker) Subscribe() chan doa.Result {
+	ch := make(chan doa.Result, 10)
+	f.mu.Lock()
+	f.subs = append(f.subs, ch)
+	f.mu.Unlock()
+	return ch
+}
+
+func (f *fakeTracker) Unsubscribe(ch chan doa.Result) {}
+
+func (f *fakeTracker) publish(r doa.Result) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subs {
+		ch <- r
+	}
+	return len(f.subs)
+}
+
+func (f *fakeTracker) GetLatest() doa.Result {
+	return doa.Result{SmoothedAngle: 0.4, Confidence: 0.8}
+}
+
+func (f *fakeTracker) Stats() doa.TrackerStats {
+	return doa.TrackerStats{PollCount: 42, SourceHealthy: true}
+}
+
+type fakeMotor struct {
+	stopped bool
+	head    pollen.HeadTarget
+	emotion string
+}
+
+func (m *fakeMotor) SetTarget(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	if m.stopped {
+		return pollen.ErrStopped
+	}
+	m.head = head
+	return nil
+}
+
+func (m *fakeMotor) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	m.emotion = name
+	return nil
+}
+
+func startTestServer(t *testing.T, tracker Tracker, motor Motor) *grpclib.ClientConn {
+	t.Helper()
+	return serveTest(t, NewServer(DefaultConfig(), tracker, motor, nil))
+}
+
+func serveTest(t *testing.T, s *Server) *grpclib.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.Stop(ctx)
+	})
+
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDOAService(t *testing.T) {
+	tracker := &fakeTracker{}
+	client := evapb.NewDOAServiceClient(startTestServer(t, tracker, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	latest, err := client.GetLatest(ctx, &evapb.GetLatestRequest{})
+	if err != nil || latest.SmoothedAngle != 0.4 {
+		t.Errorf("GetLatest() = %v, %v", latest, err)
+	}
+
+	stats, err := client.GetTrackerStats(ctx, &evapb.GetTrackerStatsRequest{})
+	if err != nil || stats.PollCount != 42 || !stats.SourceHealthy {
+		t.Errorf("GetTrackerStats() = %v, %v", stats, err)
+	}
+
+	stream, err := client.StreamDOA(ctx, &evapb.StreamDOARequest{})
+	if err != nil {
+		t.Fatalf("StreamDOA() error = %v", err)
+	}
+
+	// Publish once the server has subscribed
+	for tracker.publish(doa.Result{SmoothedAngle: 1.2, SpeakingLatched: true}) == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	got, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	if got.SmoothedAngle != 1.2 || !got.SpeakingLatched {
+		t.Errorf("streamed %v", got)
+	}
+}
+
+func TestMotorService(t *testing.T) {
+	motor := &fakeMotor{}
+	client := evapb.NewMotorServiceClient(startTestServer(t, &fakeTracker{}, motor))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := client.SetTarget(ctx, &evapb.SetTargetRequest{
+		Head:     &evapb.HeadTarget{Yaw: 0.3},
+		Antennas: []float64{0.1, -0.1},
+	})
+	if err != nil || motor.head.Yaw != 0.3 {
+		t.Errorf("SetTarget() error = %v, head %+v", err, motor.head)
+	}
+
+	_, err = client.SetTarget(ctx, &evapb.SetTargetRequest{Antennas: []float64{1}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("one antenna: code %v, want InvalidArgument", status.Code(err))
+	}
+
+	motor.stopped = true
+	_, err = client.SetTarget(ctx, &evapb.SetTargetRequest{})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("stopped motor: code %v, want FailedPrecondition", status.Code(err))
+	}
+
+	if _, err := client.PlayEmotion(ctx, &evapb.PlayEmotionRequest{Name: "happy"}); err != nil || motor.emotion != "happy" {
+		t.Errorf("PlayEmotion() error = %v, emotion %q", err, motor.emotion)
+	}
+	if _, err := client.PlayEmotion(ctx, &evapb.PlayEmotionRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty emotion: code %v", status.Code(err))
+	}
+}
+
+func TestMotorService_SetMove(t *testing.T) {
+	motor := &fakeMotor{}
+	s := NewServer(DefaultConfig(), &fakeTracker{}, motor, nil)
+	var moved pollen.HeadTarget
+	s.SetMove(func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error {
+		moved = head
+		return nil
+	})
+	client := evapb.NewMotorServiceClient(serveTest(t, s))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := client.SetTarget(ctx, &evapb.SetTargetRequest{Head: &evapb.HeadTarget{Yaw: 0.2}}); err != nil {
+		t.Fatalf("SetTarget() error = %v", err)
+	}
+	if moved.Yaw != 0.2 || motor.head.Yaw != 0 {
+		t.Errorf("move got %+v, motor got %+v, want the move path only", moved, motor.head)
+	}
+}
+
+func TestMotorService_SetEmotions(t *testing.T) {
+	motor := &fakeMotor{}
+	s := NewServer(DefaultConfig(), &fakeTracker{}, motor, nil)
+	cfg := emotion.DefaultConfig()
+	cfg.AllowUnknown = false
+	scheduler := emotion.New(cfg, motor, nil)
+	s.SetEmotions(scheduler)
+	client := evapb.NewMotorServiceClient(serveTest(t, s))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := client.PlayEmotion(ctx, &evapb.PlayEmotionRequest{Name: "happy", Duration: 2}); err != nil {
+		t.Fatalf("PlayEmotion() error = %v", err)
+	}
+	if motor.emotion != "" || len(scheduler.GetStats().Queued) != 1 {
+		t.Errorf("motor played %q, scheduler stats %+v, want it queued", motor.emotion, scheduler.GetStats())
+	}
+	_, err := client.PlayEmotion(ctx, &evapb.PlayEmotionRequest{Name: "no-such-emotion"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown emotion: code %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKeys = []string{"k1"}
+	conn := serveTest(t, NewServer(cfg, &fakeTracker{}, &fakeMotor{}, nil))
+	doaClient := evapb.NewDOAServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := doaClient.GetLatest(ctx, &evapb.GetLatestRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no key: code %v, want Unauthenticated", status.Code(err))
+	}
+	wrong := metadata.AppendToOutgoingContext(ctx, "x-api-key", "nope")
+	if _, err := doaClient.GetLatest(wrong, &evapb.GetLatestRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong key: code %v, want Unauthenticated", status.Code(err))
+	}
+	bearer := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer k1")
+	if _, err := doaClient.GetLatest(bearer, &evapb.GetLatestRequest{}); err != nil {
+		t.Errorf("bearer key: error = %v", err)
+	}
+
+	stream, err := doaClient.StreamDOA(ctx, &evapb.StreamDOARequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("stream without key: code %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+func TestMotorServiceDisabled(t *testing.T) {
+	client := evapb.NewMotorServiceClient(startTestServer(t, &fakeTracker{}, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := client.SetTarget(ctx, &evapb.SetTargetRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("code %v, want Unimplemented", status.Code(err))
+	}
+	if errors.Is(err, pollen.ErrStopped) {
+		t.Error("unexpected motor error")
+	}
+}
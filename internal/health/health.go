@@ -2,31 +2,60 @@
 package health
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
 )
 
+// Overall status values
+const (
+	StatusOK        = "ok"
+	StatusDegraded  = "degraded"  // A non-critical component is down
+	StatusUnhealthy = "unhealthy" // A critical component is down
+)
+
 // Status represents overall system health
 type Status struct {
-	Status        string            `json:"status"` // ok, degraded, unhealthy
-	Version       string            `json:"version"`
-	UptimeSeconds int64             `json:"uptime_seconds"`
-	Components    map[string]Check  `json:"components"`
+	Status        string           `json:"status"` // ok, degraded, unhealthy
+	Version       string           `json:"version"`
+	UptimeSeconds int64            `json:"uptime_seconds"`
+	Components    map[string]Check `json:"components"`
 }
 
 // Check represents a component health check
 type Check struct {
 	Healthy   bool      `json:"healthy"`
+	Critical  bool      `json:"critical"`
 	Message   string    `json:"message,omitempty"`
 	LastCheck time.Time `json:"last_check"`
 }
 
+// Probe checks one component; a non-nil error marks it unhealthy, except a
+// Note
+type Probe func(ctx context.Context) error
+
+// Note is returned by a probe to report a healthy component with a message
+type Note string
+
+// Error returns the message
+func (n Note) Error() string { return string(n) }
+
+// probe is a registered component probe
+type probe struct {
+	name     string
+	critical bool
+	fn       Probe
+}
+
 // Checker tracks health of system components
 type Checker struct {
 	mu         sync.RWMutex
 	version    string
 	startTime  time.Time
 	components map[string]Check
+	probes     []probe
+	lastRun    time.Time
 }
 
 // NewChecker creates a new health checker
@@ -38,6 +67,72 @@ func NewChecker(version string) *Checker {
 	}
 }
 
+// Register adds a periodic probe for a component; a failing critical
+// component makes the overall status unhealthy
+func (c *Checker) Register(name string, critical bool, fn Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probes = append(c.probes, probe{name: name, critical: critical, fn: fn})
+	c.components[name] = Check{Healthy: true, Critical: critical, Message: "not checked yet"}
+}
+
+// CheckNow runs every registered probe once, each bounded by timeout
+func (c *Checker) CheckNow(ctx context.Context, timeout time.Duration) {
+	c.mu.RLock()
+	probes := append([]probe(nil), c.probes...)
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p probe) {
+			defer wg.Done()
+
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			var note Note
+			if err := p.fn(pctx); errors.As(err, &note) {
+				c.SetComponent(p.name, true, string(note))
+			} else if err != nil {
+				c.SetComponent(p.name, false, err.Error())
+			} else {
+				c.SetComponent(p.name, true, "")
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	c.lastRun = time.Now()
+	c.mu.Unlock()
+}
+
+// LastRun returns when CheckNow last completed (zero before the first run)
+func (c *Checker) LastRun() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastRun
+}
+
+// Run probes all components every interval until ctx is cancelled
+func (c *Checker) Run(ctx context.Context, interval, timeout time.Duration) {
+	c.CheckNow(ctx, timeout)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckNow(ctx, timeout)
+		}
+	}
+}
+
 // SetComponent updates a component's health status
 func (c *Checker) SetComponent(name string, healthy bool, message string) {
 	c.mu.Lock()
@@ -45,6 +140,7 @@ func (c *Checker) SetComponent(name string, healthy bool, message string) {
 
 	c.components[name] = Check{
 		Healthy:   healthy,
+		Critical:  c.components[name].Critical,
 		Message:   message,
 		LastCheck: time.Now(),
 	}
@@ -55,12 +151,16 @@ func (c *Checker) GetStatus() Status {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	status := "ok"
+	status := StatusOK
 	for _, check := range c.components {
-		if !check.Healthy {
-			status = "degraded"
+		if check.Healthy {
+			continue
+		}
+		if check.Critical {
+			status = StatusUnhealthy
 			break
 		}
+		status = StatusDegraded
 	}
 
 	// Copy components map
@@ -89,4 +189,3 @@ func (c *Checker) IsHealthy() bool {
 	}
 	return true
 }
-
@@ -1,7 +1,10 @@
 package health
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestChecker_Basic(t *testing.T) {
@@ -105,3 +108,62 @@ func TestChecker_MultipleComponents(t *testing.T) {
 	}
 }
 
+
+func TestChecker_Probes(t *testing.T) {
+	checker := NewChecker("1.0.0")
+
+	var cloudErr, usbErr error
+	checker.Register("cloud", false, func(ctx context.Context) error { return cloudErr })
+	checker.Register("doa_source", true, func(ctx context.Context) error { return usbErr })
+
+	if !checker.LastRun().IsZero() {
+		t.Error("expected no LastRun before the first check")
+	}
+	checker.CheckNow(context.Background(), time.Second)
+	if status := checker.GetStatus(); status.Status != StatusOK {
+		t.Errorf("expected status 'ok', got %s", status.Status)
+	}
+	if time.Since(checker.LastRun()) > time.Second {
+		t.Errorf("expected LastRun to be set, got %v", checker.LastRun())
+	}
+
+	cloudErr = errors.New("disconnected")
+	checker.CheckNow(context.Background(), time.Second)
+	status := checker.GetStatus()
+	if status.Status != StatusDegraded {
+		t.Errorf("expected status 'degraded', got %s", status.Status)
+	}
+	if msg := status.Components["cloud"].Message; msg != "disconnected" {
+		t.Errorf("expected message 'disconnected', got %q", msg)
+	}
+
+	cloudErr = Note("connected to fallback")
+	checker.CheckNow(context.Background(), time.Second)
+	status = checker.GetStatus()
+	if cloud := status.Components["cloud"]; !cloud.Healthy || cloud.Message != "connected to fallback" {
+		t.Errorf("expected a healthy cloud with a note, got %+v", cloud)
+	}
+
+	usbErr = errors.New("no device")
+	checker.CheckNow(context.Background(), time.Second)
+	status = checker.GetStatus()
+	if status.Status != StatusUnhealthy {
+		t.Errorf("expected status 'unhealthy', got %s", status.Status)
+	}
+	if !status.Components["doa_source"].Critical {
+		t.Error("expected doa_source to stay critical")
+	}
+}
+
+func TestChecker_ProbeTimeout(t *testing.T) {
+	checker := NewChecker("1.0.0")
+	checker.Register("pollen", false, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	checker.CheckNow(context.Background(), 10*time.Millisecond)
+	if checker.GetStatus().Components["pollen"].Healthy {
+		t.Error("hung probe should be reported unhealthy")
+	}
+}
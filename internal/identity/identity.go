@@ -0,0 +1,67 @@
+// Package identity works out which robot this is, so several robots can
+// share one cloud
+package identity
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// Serial sources
+const (
+	SourceXVF3800 = "xvf3800" // USB serial of the mic array
+	SourcePi      = "pi"      // Raspberry Pi board serial
+)
+
+// Paths the Pi serial is read from, in order
+var (
+	devicetreeSerialPath = "/sys/firmware/devicetree/base/serial-number"
+	cpuinfoPath          = "/proc/cpuinfo"
+)
+
+// HardwareSerial returns the robot's hardware serial and where it came from.
+// The XVF3800 serial is preferred since the mic array is part of the robot
+// itself; the Pi serial changes if the compute board is swapped. Returns ""
+// when neither is available (development machines).
+func HardwareSerial(xvfSerial string) (serial, source string) {
+	if s := strings.TrimSpace(xvfSerial); s != "" {
+		return s, SourceXVF3800
+	}
+	if s := PiSerial(); s != "" {
+		return s, SourcePi
+	}
+	return "", ""
+}
+
+// PiSerial returns the Raspberry Pi board serial, or "" off a Pi
+func PiSerial() string {
+	if data, err := os.ReadFile(devicetreeSerialPath); err == nil {
+		if s := strings.TrimSpace(strings.TrimRight(string(data), "\x00")); s != "" {
+			return s
+		}
+	}
+	f, err := os.Open(cpuinfoPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return parseCPUInfoSerial(f)
+}
+
+// parseCPUInfoSerial extracts the "Serial" line of /proc/cpuinfo
+func parseCPUInfoSerial(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "Serial" {
+			continue
+		}
+		// Non-Pi ARM boards report all zeros
+		if value = strings.TrimSpace(value); strings.Trim(value, "0") != "" {
+			return value
+		}
+	}
+	return ""
+}
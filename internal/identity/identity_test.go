@@ -0,0 +1,63 @@
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cpuinfo = `processor	: 0
+BogoMIPS	: 108.00
+Features	: fp asimd evtstrm crc32 cpuid
+
+Hardware	: BCM2835
+Revision	: d04170
+Serial		: 10000000abcdef01
+Model		: Raspberry Pi 5 Model B Rev 1.0
+`
+
+func TestParseCPUInfoSerial(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"pi", cpuinfo, "10000000abcdef01"},
+		{"zeros", "Serial\t\t: 0000000000000000\n", ""},
+		{"missing", "processor\t: 0\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCPUInfoSerial(strings.NewReader(tt.input)); got != tt.want {
+				t.Errorf("parseCPUInfoSerial() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHardwareSerial(t *testing.T) {
+	dir := t.TempDir()
+	oldDT, oldCPU := devicetreeSerialPath, cpuinfoPath
+	t.Cleanup(func() { devicetreeSerialPath, cpuinfoPath = oldDT, oldCPU })
+	devicetreeSerialPath = filepath.Join(dir, "serial-number")
+	cpuinfoPath = filepath.Join(dir, "cpuinfo")
+
+	if serial, source := HardwareSerial(" XVF-42 "); serial != "XVF-42" || source != SourceXVF3800 {
+		t.Errorf("HardwareSerial(xvf) = %q, %q", serial, source)
+	}
+	if serial, source := HardwareSerial(""); serial != "" || source != "" {
+		t.Errorf("HardwareSerial() with no serials = %q, %q", serial, source)
+	}
+
+	os.WriteFile(cpuinfoPath, []byte(cpuinfo), 0o644)
+	if serial, source := HardwareSerial(""); serial != "10000000abcdef01" || source != SourcePi {
+		t.Errorf("HardwareSerial() from cpuinfo = %q, %q", serial, source)
+	}
+
+	// The device tree wins over cpuinfo and is NUL terminated
+	os.WriteFile(devicetreeSerialPath, []byte("abcdef0123456789\x00"), 0o644)
+	if serial := PiSerial(); serial != "abcdef0123456789" {
+		t.Errorf("PiSerial() = %q", serial)
+	}
+}
@@ -0,0 +1,322 @@
+// Package influx pushes go-eva metrics to InfluxDB (or any endpoint that
+// accepts the line protocol) on a fixed interval.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds exporter configuration
+type Config struct {
+	URL      string            // Full write URL (e.g., "http://influx:8086/api/v2/write?org=eva&bucket=robots&precision=ns")
+	Token    string            // Optional auth token, sent as "Authorization: Token <token>"
+	Interval time.Duration     // Push interval
+	Prefix   string            // Measurement name prefix (default: "go_eva_")
+	Tags     map[string]string // Extra tags added to every point (host is added automatically)
+	Timeout  time.Duration     // HTTP request timeout
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Interval: 10 * time.Second,
+		Prefix:   "go_eva_",
+		Timeout:  5 * time.Second,
+	}
+}
+
+// Gatherer returns the current fields for one measurement
+type Gatherer func() map[string]interface{}
+
+// Exporter periodically gathers metrics and writes them as line protocol
+type Exporter struct {
+	cfg        Config
+	logger     *slog.Logger
+	httpClient *http.Client
+	tags       string // pre-rendered tag set
+
+	mu      sync.RWMutex
+	sources map[string]Gatherer
+
+	// Stats
+	pushes     atomic.Uint64
+	pushErrors atomic.Uint64
+	points     atomic.Uint64
+}
+
+// NewExporter creates a new line-protocol exporter
+func NewExporter(cfg Config, logger *slog.Logger) *Exporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+
+	tags := make(map[string]string, len(cfg.Tags)+1)
+	if host, err := os.Hostname(); err == nil {
+		tags["host"] = host
+	}
+	for k, v := range cfg.Tags {
+		tags[k] = v
+	}
+
+	e := &Exporter{
+		cfg:        cfg,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		tags:       renderTags(tags),
+		sources:    make(map[string]Gatherer),
+	}
+
+	// System metrics are always exported
+	e.AddSource("system", systemFields)
+
+	return e
+}
+
+// AddSource registers a measurement gatherer under the given name
+func (e *Exporter) AddSource(name string, gather Gatherer) {
+	e.mu.Lock()
+	e.sources[name] = gather
+	e.mu.Unlock()
+}
+
+// Run pushes metrics every interval until ctx is cancelled (blocking, use goroutine)
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	e.logger.Info("influx exporter started",
+		"url", e.cfg.URL,
+		"interval", e.cfg.Interval,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("influx exporter stopped")
+			return
+		case <-ticker.C:
+			if err := e.Push(ctx); err != nil {
+				e.logger.Warn("influx push failed", "error", err)
+			}
+		}
+	}
+}
+
+// Push gathers all sources and writes a single batch
+func (e *Exporter) Push(ctx context.Context) error {
+	body, count := e.Render(time.Now())
+	if count == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.cfg.Token)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		e.pushErrors.Add(1)
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		e.pushErrors.Add(1)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(msg))
+	}
+
+	e.pushes.Add(1)
+	e.points.Add(uint64(count))
+	return nil
+}
+
+// Render gathers all sources into a line protocol batch
+func (e *Exporter) Render(ts time.Time) ([]byte, int) {
+	e.mu.RLock()
+	names := make([]string, 0, len(e.sources))
+	for name := range e.sources {
+		names = append(names, name)
+	}
+	sources := make(map[string]Gatherer, len(e.sources))
+	for k, v := range e.sources {
+		sources[k] = v
+	}
+	e.mu.RUnlock()
+
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	count := 0
+	for _, name := range names {
+		fields := renderFields(sources[name]())
+		if fields == "" {
+			continue
+		}
+
+		buf.WriteString(escapeKey(e.cfg.Prefix + name))
+		buf.WriteString(e.tags)
+		buf.WriteByte(' ')
+		buf.WriteString(fields)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+		buf.WriteByte('\n')
+		count++
+	}
+
+	return buf.Bytes(), count
+}
+
+// StructFields converts a stats struct into fields using its JSON tags.
+// Nested objects are flattened with "_"; strings and timestamps are skipped.
+func StructFields(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	fields := make(map[string]interface{})
+	flatten("", raw, fields)
+	return fields
+}
+
+func flatten(prefix string, raw map[string]interface{}, out map[string]interface{}) {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "_" + k
+		}
+
+		switch val := v.(type) {
+		case float64, bool:
+			out[key] = val
+		case map[string]interface{}:
+			flatten(key, val, out)
+		}
+	}
+}
+
+// systemFields reports Go runtime metrics
+func systemFields() map[string]interface{} {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return map[string]interface{}{
+		"goroutines":     runtime.NumGoroutine(),
+		"heap_alloc":     mem.HeapAlloc,
+		"heap_sys":       mem.HeapSys,
+		"gc_cycles":      mem.NumGC,
+		"gc_pause_total": mem.PauseTotalNs,
+	}
+}
+
+func renderTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		if tags[k] == "" {
+			continue
+		}
+		b.WriteByte(',')
+		b.WriteString(escapeKey(k))
+		b.WriteByte('=')
+		b.WriteString(escapeKey(tags[k]))
+	}
+	return b.String()
+}
+
+func renderFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value, ok := formatValue(fields[k])
+		if !ok {
+			continue
+		}
+		parts = append(parts, escapeKey(k)+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatValue(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), true
+	case int:
+		return strconv.FormatInt(int64(val), 10) + "i", true
+	case int64:
+		return strconv.FormatInt(val, 10) + "i", true
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10) + "i", true
+	case uint64:
+		return strconv.FormatUint(val, 10) + "i", true
+	case bool:
+		return strconv.FormatBool(val), true
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val) + `"`, true
+	}
+	return "", false
+}
+
+// escapeKey escapes measurement names, tag keys/values, and field keys
+func escapeKey(s string) string {
+	return strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace(s)
+}
+
+// Stats contains exporter statistics
+type Stats struct {
+	Pushes     uint64 `json:"pushes"`
+	PushErrors uint64 `json:"push_errors"`
+	Points     uint64 `json:"points"`
+}
+
+// GetStats returns exporter statistics
+func (e *Exporter) GetStats() Stats {
+	return Stats{
+		Pushes:     e.pushes.Load(),
+		PushErrors: e.pushErrors.Load(),
+		Points:     e.points.Load(),
+	}
+}
@@ -0,0 +1,130 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.Interval <= 0 {
+		t.Error("Interval should be positive")
+	}
+	if cfg.Prefix == "" {
+		t.Error("Prefix should not be empty")
+	}
+}
+
+func TestRender(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tags = map[string]string{"robot": "eva 1"}
+
+	e := NewExporter(cfg, nil)
+	e.AddSource("tracker", func() map[string]interface{} {
+		return map[string]interface{}{
+			"poll_count":     int64(42),
+			"avg_latency_ms": 1.5,
+			"healthy":        true,
+		}
+	})
+
+	body, count := e.Render(time.Unix(0, 1000))
+	if count != 2 {
+		t.Fatalf("expected 2 points (system + tracker), got %d", count)
+	}
+
+	var trackerLine string
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.HasPrefix(line, "go_eva_tracker,") {
+			trackerLine = line
+		}
+	}
+
+	if trackerLine == "" {
+		t.Fatalf("tracker line missing from:\n%s", body)
+	}
+	if !strings.Contains(trackerLine, `robot=eva\ 1`) {
+		t.Errorf("expected escaped robot tag in %q", trackerLine)
+	}
+	if !strings.Contains(trackerLine, " avg_latency_ms=1.5,healthy=true,poll_count=42i 1000") {
+		t.Errorf("unexpected field set in %q", trackerLine)
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	stats := struct {
+		Count   int64  `json:"count"`
+		Healthy bool   `json:"healthy"`
+		Name    string `json:"name"`
+		Inner   struct {
+			Depth int `json:"depth"`
+		} `json:"inner"`
+	}{Count: 3, Healthy: true, Name: "usb"}
+	stats.Inner.Depth = 7
+
+	fields := StructFields(stats)
+
+	if fields["count"] != 3.0 {
+		t.Errorf("expected count 3, got %v", fields["count"])
+	}
+	if fields["inner_depth"] != 7.0 {
+		t.Errorf("expected inner_depth 7, got %v", fields["inner_depth"])
+	}
+	if _, ok := fields["name"]; ok {
+		t.Error("string fields should be skipped")
+	}
+}
+
+func TestPush(t *testing.T) {
+	var gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+	cfg.Token = "secret"
+
+	e := NewExporter(cfg, nil)
+	if err := e.Push(context.Background()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if gotAuth != "Token secret" {
+		t.Errorf("expected token auth header, got %q", gotAuth)
+	}
+	if !strings.HasPrefix(gotBody, "go_eva_system") {
+		t.Errorf("expected system measurement, got %q", gotBody)
+	}
+	if e.GetStats().Pushes != 1 {
+		t.Errorf("expected 1 push, got %d", e.GetStats().Pushes)
+	}
+}
+
+func TestPushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+
+	e := NewExporter(cfg, nil)
+	if err := e.Push(context.Background()); err == nil {
+		t.Error("Push() should fail on 401")
+	}
+	if e.GetStats().PushErrors != 1 {
+		t.Errorf("expected 1 push error, got %d", e.GetStats().PushErrors)
+	}
+}
@@ -0,0 +1,215 @@
+// Package latency measures how long the robot takes to react to sound,
+// stage by stage: from the XVF3800 capturing a reading, through the
+// tracker and the cloud, to Pollen accepting the resulting head move.
+//
+// Every stage is timed from a timestamp carried along with the data
+// (doa.Reading.Timestamp, DOAData.CapturedAt echoed back as
+// MotorCommand.SourceTS), so a breakdown needs no extra clocks.
+package latency
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Pipeline stages
+const (
+	StageTrack    = "track"      // Sound captured → tracker result delivered
+	StageUplink   = "uplink"     // Sound captured → DOA message queued for the cloud
+	StageCloud    = "cloud"      // Sound captured → cloud motor command received
+	StageMotor    = "motor"      // Motor command → Pollen accepted it
+	StageEndToEnd = "end_to_end" // Sound captured → Pollen accepted the head move it caused
+)
+
+// stageOrder is the order stages are reported in
+var stageOrder = []string{StageTrack, StageUplink, StageCloud, StageMotor, StageEndToEnd}
+
+// Buckets are latency buckets in seconds spanning local stages and cloud
+// round trips
+var Buckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// maxPlausible bounds samples; anything longer is a stale or skewed
+// timestamp, not a reaction
+const maxPlausible = time.Minute
+
+// Config configures the recorder
+type Config struct {
+	Window int // Recent samples per stage used for percentiles
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Window: 256,
+	}
+}
+
+// stage holds the samples for one stage
+type stage struct {
+	recent  []float64 // Ring of the last Window samples (ms)
+	next    int
+	count   uint64
+	dropped uint64
+	sumMs   float64
+	maxMs   float64
+	last    float64
+	lastAt  time.Time
+}
+
+// Recorder collects stage latencies
+type Recorder struct {
+	cfg Config
+
+	mu     sync.Mutex
+	stages map[string]*stage
+
+	histogram *prometheus.HistogramVec
+}
+
+// NewRecorder creates a latency recorder
+func NewRecorder(cfg Config) *Recorder {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultConfig().Window
+	}
+
+	return &Recorder{
+		cfg:    cfg,
+		stages: make(map[string]*stage),
+		histogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_eva_latency_seconds",
+			Help:    "Reaction latency per pipeline stage, from sound capture to head move",
+			Buckets: Buckets,
+		}, []string{"stage"}),
+	}
+}
+
+// Observe records one sample for name. Negative or implausibly long
+// durations (clock skew, stale timestamps) are counted but not recorded.
+func (r *Recorder) Observe(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stages[name]
+	if s == nil {
+		s = &stage{recent: make([]float64, 0, r.cfg.Window)}
+		r.stages[name] = s
+	}
+	if d < 0 || d > maxPlausible {
+		s.dropped++
+		return
+	}
+
+	ms := float64(d) / float64(time.Millisecond)
+	if len(s.recent) < r.cfg.Window {
+		s.recent = append(s.recent, ms)
+	} else {
+		s.recent[s.next] = ms
+		s.next = (s.next + 1) % r.cfg.Window
+	}
+	s.count++
+	s.sumMs += ms
+	s.maxMs = math.Max(s.maxMs, ms)
+	s.last = ms
+	s.lastAt = time.Now()
+
+	r.histogram.WithLabelValues(name).Observe(d.Seconds())
+}
+
+// Since records the time elapsed since start for name. A zero start (no
+// timestamp to measure from) is ignored.
+func (r *Recorder) Since(name string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	r.Observe(name, time.Since(start))
+}
+
+// StageStats summarizes one stage. Percentiles cover the recent window;
+// the average and maximum cover every sample.
+type StageStats struct {
+	Stage   string    `json:"stage"`
+	Count   uint64    `json:"count"`
+	Dropped uint64    `json:"dropped"` // Negative or implausible samples
+	LastMs  float64   `json:"last_ms"`
+	AvgMs   float64   `json:"avg_ms"`
+	P50Ms   float64   `json:"p50_ms"`
+	P95Ms   float64   `json:"p95_ms"`
+	P99Ms   float64   `json:"p99_ms"`
+	MaxMs   float64   `json:"max_ms"`
+	LastAt  time.Time `json:"last_at,omitempty"`
+}
+
+// Breakdown is the latency of every stage seen so far, in pipeline order
+type Breakdown struct {
+	Window int          `json:"window"`
+	Stages []StageStats `json:"stages"`
+}
+
+// Breakdown returns per-stage statistics
+func (r *Recorder) Breakdown() Breakdown {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.stages))
+	for name := range r.stages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		oi, oj := order(names[i]), order(names[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return names[i] < names[j]
+	})
+
+	b := Breakdown{Window: r.cfg.Window, Stages: make([]StageStats, 0, len(names))}
+	for _, name := range names {
+		s := r.stages[name]
+		stats := StageStats{
+			Stage:   name,
+			Count:   s.count,
+			Dropped: s.dropped,
+			LastMs:  s.last,
+			MaxMs:   s.maxMs,
+			LastAt:  s.lastAt,
+		}
+		if s.count > 0 {
+			stats.AvgMs = s.sumMs / float64(s.count)
+			sorted := append([]float64(nil), s.recent...)
+			sort.Float64s(sorted)
+			stats.P50Ms = percentile(sorted, 0.50)
+			stats.P95Ms = percentile(sorted, 0.95)
+			stats.P99Ms = percentile(sorted, 0.99)
+		}
+		b.Stages = append(b.Stages, stats)
+	}
+	return b
+}
+
+// order returns a stage's position in the pipeline (unknown stages last)
+func order(name string) int {
+	for i, s := range stageOrder {
+		if s == name {
+			return i
+		}
+	}
+	return len(stageOrder)
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// RegisterMetrics registers the per-stage histogram
+func (r *Recorder) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(r.histogram)
+}
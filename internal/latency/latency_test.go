@@ -0,0 +1,63 @@
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRecorder_Breakdown(t *testing.T) {
+	r := NewRecorder(Config{Window: 10})
+
+	for i := 1; i <= 20; i++ {
+		r.Observe(StageMotor, time.Duration(i)*time.Millisecond)
+	}
+	r.Observe(StageTrack, 2*time.Millisecond)
+	r.Observe("custom", time.Millisecond)
+	r.Observe(StageTrack, -time.Millisecond) // Clock skew
+	r.Since(StageEndToEnd, time.Time{})      // No timestamp
+
+	b := r.Breakdown()
+	if len(b.Stages) != 3 {
+		t.Fatalf("stages = %+v, want track, motor, custom", b.Stages)
+	}
+	if b.Stages[0].Stage != StageTrack || b.Stages[1].Stage != StageMotor || b.Stages[2].Stage != "custom" {
+		t.Errorf("stages not in pipeline order: %+v", b.Stages)
+	}
+
+	track := b.Stages[0]
+	if track.Count != 1 || track.Dropped != 1 || track.LastMs != 2 {
+		t.Errorf("track = %+v", track)
+	}
+
+	// Percentiles cover the last 10 samples (11-20ms), the mean all 20
+	motor := b.Stages[1]
+	if motor.Count != 20 || motor.AvgMs != 10.5 || motor.MaxMs != 20 || motor.LastMs != 20 {
+		t.Errorf("motor = %+v", motor)
+	}
+	if motor.P50Ms != 15 || motor.P95Ms != 20 || motor.P99Ms != 20 {
+		t.Errorf("motor percentiles = %v/%v/%v, want 15/20/20", motor.P50Ms, motor.P95Ms, motor.P99Ms)
+	}
+}
+
+func TestRecorder_Metrics(t *testing.T) {
+	r := NewRecorder(DefaultConfig())
+	reg := prometheus.NewRegistry()
+	r.RegisterMetrics(reg)
+
+	r.Observe(StageCloud, 120*time.Millisecond)
+	r.Observe(StageCloud, 2*time.Minute) // Stale timestamp, not recorded
+
+	fams, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fams) != 1 || len(fams[0].GetMetric()) != 1 {
+		t.Fatalf("gathered %v, want one cloud histogram", fams)
+	}
+	m := fams[0].GetMetric()[0]
+	if m.GetLabel()[0].GetValue() != StageCloud || m.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("histogram = %v, want 1 cloud sample", m)
+	}
+}
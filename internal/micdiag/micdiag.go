@@ -0,0 +1,289 @@
+// Package micdiag watches the XVF3800's four mic channels for failures.
+//
+// A dead or badly degraded mic doesn't stop DOA from working; the array
+// just gets quietly worse at locating speech. The monitor compares each
+// channel's speech energy with the others over many speaking readings: a
+// channel carrying a small fraction of the median channel's energy is low,
+// and one carrying almost none is dead. Judgements need a full window of
+// speech, so a single odd utterance never flags a mic.
+package micdiag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// NumMics is the number of channels on the XVF3800 array
+const NumMics = 4
+
+// Config holds mic diagnostics configuration
+type Config struct {
+	Window    int     // Speaking readings averaged per channel; no channel is judged before this many
+	LowRatio  float64 // Channel energy below this fraction of the median channel is low
+	DeadRatio float64 // Channel energy below this fraction of the median channel is dead
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Window:    200, // ~10s of speech at 20Hz
+		LowRatio:  0.2,
+		DeadRatio: 0.02,
+	}
+}
+
+// Status is a channel's diagnosis
+type Status string
+
+const (
+	StatusUnknown Status = "unknown" // Not enough speech heard yet
+	StatusOK      Status = "ok"
+	StatusLow     Status = "low"
+	StatusDead    Status = "dead"
+)
+
+// Channel is the diagnosis and statistics of one mic
+type Channel struct {
+	Mic          int       `json:"mic"`
+	Status       Status    `json:"status"`
+	Since        time.Time `json:"since"`         // When the status last changed
+	Energy       float64   `json:"energy"`        // Mean speech energy over the window
+	Ratio        float64   `json:"ratio"`         // Energy relative to the median channel
+	LastEnergy   float64   `json:"last_energy"`   // Latest speaking reading
+	Azimuth      float64   `json:"azimuth"`       // Latest azimuth (radians)
+	AzimuthStill int       `json:"azimuth_still"` // Speaking readings since the azimuth last changed (a frozen one hints at a bad channel)
+	ZeroReadings int64     `json:"zero_readings"` // Speaking readings where this channel had no energy
+}
+
+// Report is the state of the whole array
+type Report struct {
+	Healthy  bool      `json:"healthy"`
+	Samples  int64     `json:"samples"` // Speaking readings seen
+	Channels []Channel `json:"channels"`
+}
+
+// Monitor tracks per-channel energy and flags failing mics
+type Monitor struct {
+	cfg    Config
+	logger *slog.Logger
+	alpha  float64
+
+	mu       sync.RWMutex
+	samples  int64
+	channels [NumMics]Channel
+
+	energyGauge *prometheus.GaugeVec
+	ratioGauge  *prometheus.GaugeVec
+	faultGauge  *prometheus.GaugeVec
+}
+
+// New creates a mic monitor
+func New(cfg Config, logger *slog.Logger) *Monitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	def := DefaultConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.LowRatio <= 0 {
+		cfg.LowRatio = def.LowRatio
+	}
+	if cfg.DeadRatio <= 0 {
+		cfg.DeadRatio = def.DeadRatio
+	}
+
+	m := &Monitor{
+		cfg:    cfg,
+		logger: logger,
+		alpha:  2 / float64(cfg.Window+1),
+		energyGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_eva_mic_energy",
+			Help: "Mean speech energy per mic over the diagnostics window",
+		}, []string{"mic"}),
+		ratioGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_eva_mic_energy_ratio",
+			Help: "Mic speech energy relative to the median mic",
+		}, []string{"mic"}),
+		faultGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_eva_mic_fault",
+			Help: "1 while the mic is diagnosed low or dead",
+		}, []string{"mic", "status"}),
+	}
+	now := time.Now()
+	for i := range m.channels {
+		m.channels[i] = Channel{Mic: i, Status: StatusUnknown, Since: now}
+	}
+	return m
+}
+
+// Run feeds tracker results to the monitor until ctx is cancelled or
+// results is closed
+func (m *Monitor) Run(ctx context.Context, results <-chan doa.Result) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			m.Feed(result)
+		}
+	}
+}
+
+// Feed adds one tracker result. Only speaking readings with energy count; silence
+// says nothing about a mic.
+func (m *Monitor) Feed(r doa.Result) {
+	if !r.Speaking || r.TotalEnergy <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.samples++
+	for i := range m.channels {
+		ch := &m.channels[i]
+		e := r.SpeechEnergy[i]
+		if m.samples == 1 {
+			ch.Energy = e
+		} else {
+			ch.Energy += m.alpha * (e - ch.Energy)
+		}
+		ch.LastEnergy = e
+		if e <= 0 {
+			ch.ZeroReadings++
+		}
+		if m.samples == 1 || r.MicAzimuths[i] != ch.Azimuth {
+			ch.AzimuthStill = 0
+		} else {
+			ch.AzimuthStill++
+		}
+		ch.Azimuth = r.MicAzimuths[i]
+	}
+
+	m.diagnoseLocked(r.Timestamp)
+}
+
+// diagnoseLocked updates every channel's ratio and status
+func (m *Monitor) diagnoseLocked(now time.Time) {
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	energies := make([]float64, NumMics)
+	for i, ch := range m.channels {
+		energies[i] = ch.Energy
+	}
+	sort.Float64s(energies)
+	ref := (energies[NumMics/2-1] + energies[NumMics/2]) / 2
+	if ref <= 0 {
+		ref = energies[NumMics-1]
+	}
+
+	for i := range m.channels {
+		ch := &m.channels[i]
+		ch.Ratio = 0
+		if ref > 0 {
+			ch.Ratio = ch.Energy / ref
+		}
+
+		status := StatusOK
+		switch {
+		case m.samples < int64(m.cfg.Window):
+			status = StatusUnknown
+		case ch.Ratio < m.cfg.DeadRatio:
+			status = StatusDead
+		case ch.Ratio < m.cfg.LowRatio:
+			status = StatusLow
+		}
+
+		if status != ch.Status {
+			switch {
+			case isFault(status):
+				m.logger.Warn("mic fault", "mic", i, "status", status, "ratio", ch.Ratio)
+			case isFault(ch.Status):
+				m.logger.Info("mic recovered", "mic", i, "was", ch.Status, "ratio", ch.Ratio)
+			}
+			if isFault(ch.Status) {
+				m.faultGauge.WithLabelValues(strconv.Itoa(i), string(ch.Status)).Set(0)
+			}
+			if isFault(status) {
+				m.faultGauge.WithLabelValues(strconv.Itoa(i), string(status)).Set(1)
+			}
+			ch.Status = status
+			ch.Since = now
+		}
+
+		m.energyGauge.WithLabelValues(strconv.Itoa(i)).Set(ch.Energy)
+		m.ratioGauge.WithLabelValues(strconv.Itoa(i)).Set(ch.Ratio)
+	}
+}
+
+// isFault reports whether a status means the mic needs attention
+func isFault(s Status) bool {
+	return s == StatusLow || s == StatusDead
+}
+
+// Report returns the diagnosis of every channel
+func (m *Monitor) Report() Report {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rep := Report{
+		Healthy:  true,
+		Samples:  m.samples,
+		Channels: make([]Channel, NumMics),
+	}
+	for i, ch := range m.channels {
+		rep.Channels[i] = ch
+		if isFault(ch.Status) {
+			rep.Healthy = false
+		}
+	}
+	return rep
+}
+
+// Check returns an error naming every faulty mic, for /health
+func (m *Monitor) Check() error {
+	var faults []string
+	for _, ch := range m.Report().Channels {
+		if isFault(ch.Status) {
+			faults = append(faults, fmt.Sprintf("mic %d %s", ch.Mic, ch.Status))
+		}
+	}
+	if len(faults) > 0 {
+		return errors.New(strings.Join(faults, ", "))
+	}
+	return nil
+}
+
+// RegisterMetrics adds the per-mic collectors to reg
+func (m *Monitor) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		m.energyGauge,
+		m.ratioGauge,
+		m.faultGauge,
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_mic_samples_total",
+			Help: "Speaking readings used for mic diagnostics",
+		}, func() float64 {
+			m.mu.RLock()
+			defer m.mu.RUnlock()
+			return float64(m.samples)
+		}),
+	)
+}
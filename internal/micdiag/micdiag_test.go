@@ -0,0 +1,89 @@
+package micdiag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// speech returns a speaking result with the given per-mic energies
+func speech(at time.Time, energy [NumMics]float64) doa.Result {
+	total := 0.0
+	for _, e := range energy {
+		total += e
+	}
+	return doa.Result{Reading: doa.Reading{
+		Speaking:     true,
+		Timestamp:    at,
+		SpeechEnergy: energy,
+		TotalEnergy:  total,
+	}}
+}
+
+func TestMonitor_FlagsDeadAndLowMics(t *testing.T) {
+	m := New(Config{Window: 20}, nil)
+	now := time.Now()
+
+	// Speech from all around: the loudest mic changes every reading
+	feed := func(n int, gain [NumMics]float64) {
+		for i := 0; i < n; i++ {
+			var e [NumMics]float64
+			for mic := range e {
+				e[mic] = gain[mic] * float64(1000+300*((i+mic)%NumMics))
+			}
+			now = now.Add(50 * time.Millisecond)
+			m.Feed(speech(now, e))
+		}
+	}
+
+	feed(10, [NumMics]float64{1, 1, 0, 0.1})
+	m.Feed(doa.Result{}) // Silence is ignored
+	if rep := m.Report(); rep.Samples != 10 || rep.Channels[2].Status != StatusUnknown || !rep.Healthy {
+		t.Fatalf("before a full window: %+v, want unknown and healthy", rep)
+	}
+
+	feed(20, [NumMics]float64{1, 1, 0, 0.1})
+	rep := m.Report()
+	want := []Status{StatusOK, StatusOK, StatusDead, StatusLow}
+	for i, ch := range rep.Channels {
+		if ch.Status != want[i] {
+			t.Errorf("mic %d status = %s (ratio %.3f), want %s", i, ch.Status, ch.Ratio, want[i])
+		}
+	}
+	if rep.Healthy || rep.Channels[2].ZeroReadings != 30 {
+		t.Errorf("report = %+v, want unhealthy with 30 zero readings on mic 2", rep)
+	}
+	if err := m.Check(); err == nil || err.Error() != "mic 2 dead, mic 3 low" {
+		t.Errorf("Check() = %v, want mic 2 dead, mic 3 low", err)
+	}
+
+	m.RegisterMetrics(prometheus.NewRegistry())
+	if v := testutil.ToFloat64(m.faultGauge.WithLabelValues("2", string(StatusDead))); v != 1 {
+		t.Errorf("go_eva_mic_fault{mic=\"2\",status=\"dead\"} = %v, want 1", v)
+	}
+
+	// The mic is replaced: it recovers once its average catches up
+	feed(100, [NumMics]float64{1, 1, 1, 1})
+	if err := m.Check(); err != nil {
+		t.Errorf("Check() after repair = %v", err)
+	}
+	if v := testutil.ToFloat64(m.faultGauge.WithLabelValues("2", string(StatusDead))); v != 0 {
+		t.Errorf("dead gauge not cleared: %v", v)
+	}
+}
+
+func TestMonitor_NoEnergyStaysUnknown(t *testing.T) {
+	// Sources without per-mic energy never get mics flagged
+	m := New(DefaultConfig(), nil)
+	for i := 0; i < 500; i++ {
+		m.Feed(doa.Result{Reading: doa.Reading{Speaking: true}})
+	}
+	rep := m.Report()
+	if rep.Samples != 0 || !rep.Healthy || rep.Channels[0].Status != StatusUnknown {
+		t.Errorf("report = %+v, want no samples", rep)
+	}
+}
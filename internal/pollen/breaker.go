@@ -0,0 +1,152 @@
+package pollen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the daemon while the
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("pollen circuit open")
+
+// BreakerState is the state of the client's circuit breaker
+type BreakerState int32
+
+const (
+	BreakerClosed   BreakerState = iota // Commands go through
+	BreakerHalfOpen                     // One status probe decides whether to close
+	BreakerOpen                         // Commands fail fast with ErrCircuitOpen
+)
+
+// String returns the state name used in stats and health messages
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker stops commands from waiting out the HTTP timeout while the daemon
+// is down. It opens after failures consecutive failed requests; once
+// cooldown has passed, the next command probes the daemon status first and
+// either closes the breaker or keeps it open for another cooldown.
+type breaker struct {
+	failures int
+	cooldown time.Duration
+
+	mu          sync.Mutex
+	state       BreakerState
+	consecutive int
+	openedAt    time.Time
+	opens       uint64
+}
+
+// allow reports whether a command may be sent now, and whether the caller
+// must probe the daemon first (the breaker is then half-open until the
+// probe's result is recorded)
+func (b *breaker) allow(now time.Time) (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true, false
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false, false
+		}
+		b.state = BreakerHalfOpen
+		return false, true
+	default:
+		// Another caller's probe is in flight
+		return false, false
+	}
+}
+
+// record updates the breaker with the outcome of a request, returning the
+// state it moved to and whether that is a change
+func (b *breaker) record(failed bool, now time.Time) (BreakerState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.state
+	if !failed {
+		b.consecutive = 0
+		b.state = BreakerClosed
+		return b.state, b.state != prev
+	}
+
+	b.consecutive++
+	if prev == BreakerHalfOpen || (prev == BreakerClosed && b.consecutive >= b.failures) {
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.opens++
+	}
+	return b.state, b.state != prev
+}
+
+// snapshot returns the state, consecutive failures and times opened
+func (b *breaker) snapshot() (BreakerState, int, uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.consecutive, b.opens
+}
+
+// allow checks the breaker before a command, probing the daemon with
+// GetStatus when the cooldown has passed. Refused commands are counted as
+// dropped.
+func (c *Client) allow(ctx context.Context) error {
+	if c.breaker == nil {
+		return nil
+	}
+
+	ok, probe := c.breaker.allow(time.Now())
+	if probe {
+		_, err := c.GetStatus(ctx)
+		ok = err == nil
+		if !ok && c.BreakerState() == BreakerHalfOpen {
+			// The probe was cancelled; wait out another cooldown
+			c.breaker.record(true, time.Now())
+		}
+	}
+	if !ok {
+		c.commandsDropped.Add(1)
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// observe records a request outcome on the breaker. Requests the caller
+// cancelled say nothing about the daemon and are ignored.
+func (c *Client) observe(ctx context.Context, failed bool) {
+	if c.breaker == nil || (failed && ctx.Err() != nil) {
+		return
+	}
+
+	state, changed := c.breaker.record(failed, time.Now())
+	if !changed {
+		return
+	}
+	switch state {
+	case BreakerOpen:
+		c.logger.Warn("pollen circuit opened, dropping commands", "cooldown", c.breaker.cooldown)
+	case BreakerClosed:
+		c.logger.Info("pollen circuit closed")
+	}
+}
+
+// BreakerState returns the circuit breaker state (always closed when the
+// breaker is disabled)
+func (c *Client) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	state, _, _ := c.breaker.snapshot()
+	return state
+}
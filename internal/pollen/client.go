@@ -12,13 +12,20 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/teslashibe/go-eva/internal/trace"
 )
 
 // Config holds Pollen client configuration
 type Config struct {
 	BaseURL     string        // Base URL for Pollen API (e.g., "http://localhost:8000")
 	Timeout     time.Duration // HTTP request timeout
-	RateLimitHz int           // Max commands per second (0 = unlimited)
+	RateLimitHz int           // Max commands per second (0 = unlimited); faster targets are coalesced
+
+	BreakerFailures int           // Consecutive failed requests that open the circuit breaker (0 = disabled)
+	BreakerCooldown time.Duration // How long the breaker stays open before probing the daemon
 }
 
 // DefaultConfig returns sensible defaults
@@ -27,6 +34,8 @@ func DefaultConfig() Config {
 		BaseURL:     "http://localhost:8000",
 		Timeout:     2 * time.Second,
 		RateLimitHz: 30, // 30 Hz max
+
+		BreakerCooldown: 5 * time.Second,
 	}
 }
 
@@ -59,16 +68,27 @@ type Client struct {
 	logger     *slog.Logger
 	httpClient *http.Client
 
-	// Rate limiting
+	// Rate limiting: targets arriving faster than minInterval wait in
+	// pending, newer ones replacing older, and the flush timer sends the
+	// latest when the interval has passed
 	mu            sync.Mutex
 	lastCommandAt time.Time
 	minInterval   time.Duration
+	pending       interface{} // FullBodyTarget or AntennaTarget
+	flushTimer    *time.Timer
+	closed        bool
+
+	breaker *breaker // nil when disabled
 
 	// Stats
-	commandsSent  atomic.Uint64
-	commandErrors atomic.Uint64
-	emotionsSent  atomic.Uint64
-	emotionErrors atomic.Uint64
+	commandsSent      atomic.Uint64
+	commandErrors     atomic.Uint64
+	commandsCoalesced atomic.Uint64
+	emotionsSent      atomic.Uint64
+	emotionErrors     atomic.Uint64
+	commandsDropped   atomic.Uint64
+
+	requestLatency *prometheus.HistogramVec
 }
 
 // NewClient creates a new Pollen client
@@ -82,40 +102,130 @@ func NewClient(cfg Config, logger *slog.Logger) *Client {
 		minInterval = time.Second / time.Duration(cfg.RateLimitHz)
 	}
 
-	return &Client{
+	c := &Client{
 		cfg:    cfg,
 		logger: logger,
 		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:   cfg.Timeout,
+			Transport: &trace.Transport{}, // Requests made for a traced command carry its ID
 		},
 		minInterval: minInterval,
+		requestLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "go_eva_pollen_request_duration_seconds",
+			Help: "Pollen API request latency",
+		}, []string{"endpoint"}),
+	}
+	if cfg.BreakerFailures > 0 {
+		c.breaker = &breaker{failures: cfg.BreakerFailures, cooldown: cfg.BreakerCooldown}
 	}
+	return c
 }
 
-// SetTarget sends a movement command to the robot
+// AntennaTarget moves only the antennas, leaving head and body untouched
+type AntennaTarget struct {
+	TargetAntennas [2]float64 `json:"target_antennas"`
+}
+
+// SetTarget sends a movement command. Within the rate limit it is sent
+// right away; otherwise it is held and sent on the next allowed tick unless
+// a newer target replaces it first, so the last commanded pose always lands.
 func (c *Client) SetTarget(ctx context.Context, head HeadTarget, antennas [2]float64, bodyYaw float64) error {
-	// Rate limiting
-	if c.minInterval > 0 {
-		c.mu.Lock()
-		elapsed := time.Since(c.lastCommandAt)
-		if elapsed < c.minInterval {
-			c.mu.Unlock()
-			return nil // Skip this command to maintain rate limit
-		}
+	return c.submit(ctx, FullBodyTarget{
+		TargetHeadPose: head,
+		TargetAntennas: antennas,
+		TargetBodyYaw:  bodyYaw,
+	})
+}
+
+// SetAntennas sends an antenna-only movement command (rate limited like
+// SetTarget)
+func (c *Client) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	return c.submit(ctx, AntennaTarget{TargetAntennas: antennas})
+}
+
+// submit sends target now if the rate limit allows, or coalesces it into
+// the pending target
+func (c *Client) submit(ctx context.Context, target interface{}) error {
+	if c.minInterval <= 0 {
+		return c.sendTarget(ctx, target)
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("pollen client closed")
+	}
+	wait := c.minInterval - time.Since(c.lastCommandAt)
+	if c.pending == nil && wait <= 0 {
 		c.lastCommandAt = time.Now()
 		c.mu.Unlock()
+		return c.sendTarget(ctx, target)
 	}
 
-	target := FullBodyTarget{
-		TargetHeadPose: head,
-		TargetAntennas: antennas,
-		TargetBodyYaw:  bodyYaw,
+	if c.pending != nil {
+		c.commandsCoalesced.Add(1)
+	}
+	c.pending = mergeTargets(c.pending, target)
+	if c.flushTimer == nil {
+		c.flushTimer = time.AfterFunc(max(wait, 0), c.flush)
 	}
+	c.mu.Unlock()
+	return nil
+}
+
+// mergeTargets combines a pending target with a newer one. An antenna-only
+// update keeps the head and body of a pending full target.
+func mergeTargets(pending, next interface{}) interface{} {
+	full, ok := pending.(FullBodyTarget)
+	antennas, isAntennas := next.(AntennaTarget)
+	if ok && isAntennas {
+		full.TargetAntennas = antennas.TargetAntennas
+		return full
+	}
+	return next
+}
+
+// flush sends the pending target from the timer goroutine
+func (c *Client) flush() {
+	c.mu.Lock()
+	target := c.pending
+	c.pending = nil
+	c.flushTimer = nil
+	if target == nil || c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.lastCommandAt = time.Now()
+	c.mu.Unlock()
+
+	if err := c.sendTarget(context.Background(), target); err != nil {
+		c.logger.Debug("coalesced target failed", "error", err)
+	}
+}
+
+// Close drops any pending target and stops the flush timer
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
+	c.closed = true
+	c.pending = nil
+	if c.flushTimer != nil {
+		c.flushTimer.Stop()
+		c.flushTimer = nil
+	}
+	return nil
+}
+
+// sendTarget posts a target payload to the Pollen move API
+func (c *Client) sendTarget(ctx context.Context, target interface{}) error {
 	data, err := json.Marshal(target)
 	if err != nil {
 		return fmt.Errorf("marshal target: %w", err)
 	}
+	if err := c.allow(ctx); err != nil {
+		return err
+	}
 
 	url := c.cfg.BaseURL + "/api/move/set_target"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
@@ -124,13 +234,17 @@ func (c *Client) SetTarget(ctx context.Context, head HeadTarget, antennas [2]flo
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.requestLatency.WithLabelValues("set_target").Observe(time.Since(start).Seconds())
 	if err != nil {
 		c.commandErrors.Add(1)
+		c.observe(ctx, true)
 		return fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	c.observe(ctx, resp.StatusCode >= http.StatusInternalServerError)
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		c.commandErrors.Add(1)
@@ -152,6 +266,9 @@ func (c *Client) PlayEmotion(ctx context.Context, name string, duration float64)
 	if err != nil {
 		return fmt.Errorf("marshal emotion: %w", err)
 	}
+	if err := c.allow(ctx); err != nil {
+		return err
+	}
 
 	url := c.cfg.BaseURL + "/api/emotion/play"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
@@ -160,13 +277,18 @@ func (c *Client) PlayEmotion(ctx context.Context, name string, duration float64)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.requestLatency.WithLabelValues("emotion_play").Observe(time.Since(start).Seconds())
 	if err != nil {
 		c.emotionErrors.Add(1)
+		c.observe(ctx, true)
 		return fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	c.observe(ctx, resp.StatusCode >= http.StatusInternalServerError)
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
 		c.emotionErrors.Add(1)
@@ -174,11 +296,12 @@ func (c *Client) PlayEmotion(ctx context.Context, name string, duration float64)
 	}
 
 	c.emotionsSent.Add(1)
-	c.logger.Debug("emotion played", "name", name)
+	c.logger.DebugContext(ctx, "emotion played", "name", name)
 	return nil
 }
 
-// GetStatus fetches the current robot status
+// GetStatus fetches the current robot status. It bypasses the circuit
+// breaker but reports to it, so a successful status check closes it.
 func (c *Client) GetStatus(ctx context.Context) (map[string]interface{}, error) {
 	url := c.cfg.BaseURL + "/api/daemon/status"
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -188,14 +311,17 @@ func (c *Client) GetStatus(ctx context.Context) (map[string]interface{}, error)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.observe(ctx, true)
 		return nil, fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		c.observe(ctx, true)
 		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
 	}
+	c.observe(ctx, false)
 
 	var status map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
@@ -234,16 +360,33 @@ type Stats struct {
 	CommandErrors uint64 `json:"command_errors"`
 	EmotionsSent  uint64 `json:"emotions_sent"`
 	EmotionErrors uint64 `json:"emotion_errors"`
+
+	CommandsCoalesced uint64 `json:"commands_coalesced"` // Replaced by a newer target before being sent
+
+	Breaker             string `json:"breaker,omitempty"`    // closed, half_open or open (empty when disabled)
+	ConsecutiveFailures int    `json:"consecutive_failures"` // Failed requests since the last success
+	BreakerOpens        uint64 `json:"breaker_opens"`
+	CommandsDropped     uint64 `json:"commands_dropped"` // Refused while the breaker was open
 }
 
 // GetStats returns client statistics
 func (c *Client) GetStats() Stats {
-	return Stats{
+	stats := Stats{
 		CommandsSent:  c.commandsSent.Load(),
 		CommandErrors: c.commandErrors.Load(),
 		EmotionsSent:  c.emotionsSent.Load(),
 		EmotionErrors: c.emotionErrors.Load(),
+
+		CommandsCoalesced: c.commandsCoalesced.Load(),
+		CommandsDropped:   c.commandsDropped.Load(),
+	}
+	if c.breaker != nil {
+		state, consecutive, opens := c.breaker.snapshot()
+		stats.Breaker = state.String()
+		stats.ConsecutiveFailures = consecutive
+		stats.BreakerOpens = opens
 	}
+	return stats
 }
 
 // IsHealthy checks if Pollen daemon is reachable
@@ -3,11 +3,14 @@ package pollen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/teslashibe/go-eva/internal/trace"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -86,9 +89,15 @@ func TestSetTarget(t *testing.T) {
 }
 
 func TestSetTargetRateLimit(t *testing.T) {
-	var requestCount atomic.Int32
+	var (
+		requestCount atomic.Int32
+		lastYaw      atomic.Value
+	)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var target FullBodyTarget
+		json.NewDecoder(r.Body).Decode(&target)
+		lastYaw.Store(target.TargetHeadPose.Yaw)
 		requestCount.Add(1)
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -99,19 +108,69 @@ func TestSetTargetRateLimit(t *testing.T) {
 	cfg.RateLimitHz = 10 // 10 Hz = 100ms between commands
 
 	client := NewClient(cfg, nil)
+	defer client.Close()
 
-	head := HeadTarget{}
 	antennas := [2]float64{0, 0}
 
 	// Send 5 commands rapidly
 	for i := 0; i < 5; i++ {
-		client.SetTarget(context.Background(), head, antennas, 0)
+		client.SetTarget(context.Background(), HeadTarget{Yaw: float64(i)}, antennas, 0)
 	}
 
-	// Only 1 should have gone through due to rate limiting
+	// Only the first goes out right away due to rate limiting
 	if requestCount.Load() != 1 {
 		t.Errorf("Expected 1 request due to rate limiting, got %d", requestCount.Load())
 	}
+
+	// The rest coalesce into the latest target, sent on the next tick
+	deadline := time.Now().Add(time.Second)
+	for requestCount.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if got := requestCount.Load(); got != 2 {
+		t.Fatalf("Expected 2 requests after the flush, got %d", got)
+	}
+	if yaw := lastYaw.Load(); yaw != 4.0 {
+		t.Errorf("last yaw sent = %v, want 4", yaw)
+	}
+	if stats := client.GetStats(); stats.CommandsCoalesced != 3 {
+		t.Errorf("CommandsCoalesced = %d, want 3", stats.CommandsCoalesced)
+	}
+}
+
+func TestSetAntennasCoalescesIntoTarget(t *testing.T) {
+	bodies := make(chan FullBodyTarget, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body FullBodyTarget
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 20
+
+	client := NewClient(cfg, nil)
+	defer client.Close()
+
+	ctx := context.Background()
+	client.SetAntennas(ctx, [2]float64{0, 0})
+	client.SetTarget(ctx, HeadTarget{Yaw: 0.5}, [2]float64{0, 0}, 0)
+	client.SetAntennas(ctx, [2]float64{0.3, -0.3})
+
+	<-bodies
+	select {
+	case target := <-bodies:
+		if target.TargetHeadPose.Yaw != 0.5 || target.TargetAntennas != [2]float64{0.3, -0.3} {
+			t.Errorf("flushed target = %+v, want yaw 0.5 with the newer antennas", target)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending target was never sent")
+	}
 }
 
 func TestSetTargetError(t *testing.T) {
@@ -138,6 +197,33 @@ func TestSetTargetError(t *testing.T) {
 	}
 }
 
+func TestSetAntennas(t *testing.T) {
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 0
+
+	client := NewClient(cfg, nil)
+
+	if err := client.SetAntennas(context.Background(), [2]float64{0.4, -0.4}); err != nil {
+		t.Fatalf("SetAntennas() error = %v", err)
+	}
+
+	if _, ok := received["target_head_pose"]; ok {
+		t.Error("antenna-only target should not include head pose")
+	}
+	if _, ok := received["target_antennas"]; !ok {
+		t.Error("expected target_antennas in payload")
+	}
+}
+
 func TestPlayEmotion(t *testing.T) {
 	var receivedEmotion EmotionRequest
 
@@ -265,3 +351,122 @@ func TestIsHealthyFalse(t *testing.T) {
 	}
 }
 
+
+func TestCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	var moves, probes atomic.Int32
+	down.Store(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/move/set_target":
+			moves.Add(1)
+			w.WriteHeader(http.StatusOK)
+		case "/api/daemon/status":
+			probes.Add(1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"state": "running"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 0
+	cfg.BreakerFailures = 3
+	cfg.BreakerCooldown = 50 * time.Millisecond
+
+	client := NewClient(cfg, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d: expected a daemon error, got %v", i, err)
+		}
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Fatalf("state after 3 failures = %s, want open", state)
+	}
+
+	// Open: fail fast without a request
+	down.Store(false)
+	if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if err := client.PlayEmotion(ctx, "happy", 0); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen for emotion, got %v", err)
+	}
+	if moves.Load() != 0 || client.GetStats().CommandsDropped != 2 {
+		t.Errorf("moves = %d, dropped = %d; want 0 and 2", moves.Load(), client.GetStats().CommandsDropped)
+	}
+
+	// After the cooldown a status probe closes the breaker and the command goes through
+	time.Sleep(60 * time.Millisecond)
+	if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); err != nil {
+		t.Fatalf("SetTarget() after cooldown error = %v", err)
+	}
+	if probes.Load() != 1 || moves.Load() != 1 {
+		t.Errorf("probes = %d, moves = %d; want 1 and 1", probes.Load(), moves.Load())
+	}
+	stats := client.GetStats()
+	if stats.Breaker != "closed" || stats.BreakerOpens != 1 || stats.ConsecutiveFailures != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCircuitBreakerFailedProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 0
+	cfg.BreakerFailures = 1
+	cfg.BreakerCooldown = 20 * time.Millisecond
+
+	client := NewClient(cfg, nil)
+	ctx := context.Background()
+
+	client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0)
+	time.Sleep(30 * time.Millisecond)
+
+	// The probe fails, so the breaker stays open for another cooldown
+	if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Errorf("state = %s, want open", state)
+	}
+	if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen within the new cooldown, got %v", err)
+	}
+}
+
+func TestTraceHeader(t *testing.T) {
+	var got atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.Header.Get(trace.Header))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 0
+	client := NewClient(cfg, nil)
+
+	ctx := trace.WithID(context.Background(), "abc123")
+	if err := client.SetTarget(ctx, HeadTarget{}, [2]float64{}, 0); err != nil {
+		t.Fatalf("SetTarget() error = %v", err)
+	}
+	if id, _ := got.Load().(string); id != "abc123" {
+		t.Errorf("%s = %q, want abc123", trace.Header, id)
+	}
+}
@@ -0,0 +1,38 @@
+package pollen
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers Pollen client collectors
+func (c *Client) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_commands_total",
+			Help: "Movement commands sent to Pollen",
+		}, func() float64 { return float64(c.commandsSent.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_command_errors_total",
+			Help: "Failed movement commands",
+		}, func() float64 { return float64(c.commandErrors.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_commands_coalesced_total",
+			Help: "Movement commands replaced by a newer target within the rate limit",
+		}, func() float64 { return float64(c.commandsCoalesced.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_emotions_total",
+			Help: "Emotions played via Pollen",
+		}, func() float64 { return float64(c.emotionsSent.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_emotion_errors_total",
+			Help: "Failed emotion requests",
+		}, func() float64 { return float64(c.emotionErrors.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_pollen_commands_dropped_total",
+			Help: "Commands refused without a request while the circuit breaker was open",
+		}, func() float64 { return float64(c.commandsDropped.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_pollen_circuit_state",
+			Help: "Pollen circuit breaker state (0 closed, 1 half-open, 2 open)",
+		}, func() float64 { return float64(c.BreakerState()) }),
+		c.requestLatency,
+	)
+}
@@ -0,0 +1,287 @@
+package pollen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLimitsUnsupported is returned by GetLimits when the daemon does not
+// publish its joint limits
+var ErrLimitsUnsupported = errors.New("pollen does not report joint limits")
+
+// errNotFound marks a 404 from the daemon
+var errNotFound = errors.New("not found")
+
+// JointState is the robot's measured pose
+type JointState struct {
+	HeadPose   HeadTarget `json:"head_pose"`
+	HeadJoints []float64  `json:"head_joints,omitempty"` // Stewart platform motor angles (radians)
+	Antennas   [2]float64 `json:"antennas"`
+	BodyYaw    float64    `json:"body_yaw"`
+	Timestamp  time.Time  `json:"timestamp"` // When go-eva read it
+}
+
+// fullState is the response of Pollen's /api/state/full
+type fullState struct {
+	HeadPose   *HeadTarget `json:"head_pose"`
+	HeadJoints []float64   `json:"head_joints"`
+	BodyYaw    *float64    `json:"body_yaw"`
+	Antennas   []float64   `json:"antennas_position"`
+}
+
+// Limits are the head joint ranges (radians)
+type Limits struct {
+	Yaw    JointLimit `json:"yaw"`
+	Pitch  JointLimit `json:"pitch"`
+	Roll   JointLimit `json:"roll"`
+	Source string     `json:"source"` // "pollen", or "safety" for the configured limiter range
+}
+
+// SafetyLimits returns the limiter's configured range as Limits
+func (c SafetyConfig) SafetyLimits() Limits {
+	return Limits{Yaw: c.Yaw, Pitch: c.Pitch, Roll: c.Roll, Source: "safety"}
+}
+
+// GetJointState reads the current head pose, antenna and body positions
+func (c *Client) GetJointState(ctx context.Context) (JointState, error) {
+	url := c.cfg.BaseURL + "/api/state/full?with_head_pose=true&use_pose_matrix=false" +
+		"&with_head_joints=true&with_body_yaw=true&with_antenna_positions=true"
+
+	var full fullState
+	if err := c.getJSON(ctx, "state_full", url, &full); err != nil {
+		return JointState{}, err
+	}
+	if full.HeadPose == nil {
+		return JointState{}, fmt.Errorf("state has no head pose")
+	}
+
+	state := JointState{
+		HeadPose:   *full.HeadPose,
+		HeadJoints: full.HeadJoints,
+		Timestamp:  time.Now(),
+	}
+	if full.BodyYaw != nil {
+		state.BodyYaw = *full.BodyYaw
+	}
+	copy(state.Antennas[:], full.Antennas)
+	return state, nil
+}
+
+// GetLimits reads the head joint limits from the daemon's kinematics API.
+// Daemons without the endpoint return ErrLimitsUnsupported.
+func (c *Client) GetLimits(ctx context.Context) (Limits, error) {
+	var limits Limits
+	err := c.getJSON(ctx, "limits", c.cfg.BaseURL+"/api/kinematics/limits", &limits)
+	if errors.Is(err, errNotFound) {
+		return Limits{}, ErrLimitsUnsupported
+	}
+	if err != nil {
+		return Limits{}, err
+	}
+	limits.Source = "pollen"
+	return limits, nil
+}
+
+// getJSON fetches url and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, endpoint, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.requestLatency.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", req.URL.Path, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// PoseConfig configures the pose poller
+type PoseConfig struct {
+	Interval   time.Duration // Joint state polling period
+	StaleAfter time.Duration // A pose older than this is not reported as current
+	Fallback   Limits        // Used when the daemon does not report limits
+}
+
+// DefaultPoseConfig returns sensible defaults
+func DefaultPoseConfig() PoseConfig {
+	return PoseConfig{
+		Interval:   100 * time.Millisecond,
+		StaleAfter: time.Second,
+		Fallback:   DefaultSafetyConfig().SafetyLimits(),
+	}
+}
+
+// PosePoller polls the joint state in the background so behaviors can read
+// the current pose without a round trip to Pollen
+type PosePoller struct {
+	client *Client
+	cfg    PoseConfig
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	state   JointState
+	limits  *Limits // nil until read from Pollen (or given up on)
+	lastErr error
+
+	// Stats
+	polls  atomic.Uint64
+	errors atomic.Uint64
+}
+
+// NewPosePoller creates a pose poller
+func NewPosePoller(client *Client, cfg PoseConfig, logger *slog.Logger) *PosePoller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPoseConfig().Interval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 10 * cfg.Interval
+	}
+
+	return &PosePoller{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run polls until ctx is cancelled
+func (p *PosePoller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the joint state, and the limits until they are known
+func (p *PosePoller) poll(ctx context.Context) {
+	if p.limitsUnknown() {
+		p.readLimits(ctx)
+	}
+
+	state, err := p.client.GetJointState(ctx)
+	p.polls.Add(1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		if p.lastErr == nil {
+			p.logger.Warn("joint state poll failed", "error", err)
+		}
+		p.errors.Add(1)
+		p.lastErr = err
+		return
+	}
+	if p.lastErr != nil {
+		p.logger.Info("joint state poll recovered")
+	}
+	p.state, p.lastErr = state, nil
+}
+
+func (p *PosePoller) limitsUnknown() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limits == nil
+}
+
+// readLimits fetches the limits, settling on the fallback if the daemon
+// does not have them
+func (p *PosePoller) readLimits(ctx context.Context) {
+	limits, err := p.client.GetLimits(ctx)
+	if errors.Is(err, ErrLimitsUnsupported) {
+		p.logger.Info("pollen does not report joint limits, using safety limits")
+		limits = p.cfg.Fallback
+	} else if err != nil {
+		return // Retried on the next poll
+	}
+
+	p.mu.Lock()
+	p.limits = &limits
+	p.mu.Unlock()
+}
+
+// Latest returns the cached joint state and whether it is fresh
+func (p *PosePoller) Latest() (JointState, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.state.Timestamp.IsZero() {
+		return JointState{}, false
+	}
+	return p.state, time.Since(p.state.Timestamp) <= p.cfg.StaleAfter
+}
+
+// Limits returns the joint limits, or the fallback while they are unknown
+func (p *PosePoller) Limits() Limits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.limits == nil {
+		return p.cfg.Fallback
+	}
+	return *p.limits
+}
+
+// PoseStats contains pose poller statistics
+type PoseStats struct {
+	Polls     uint64  `json:"polls"`
+	Errors    uint64  `json:"errors"`
+	AgeMs     int64   `json:"age_ms"` // Age of the cached pose (-1 = none yet)
+	LastError string  `json:"last_error,omitempty"`
+	Interval  float64 `json:"interval_seconds"`
+}
+
+// GetStats returns pose poller statistics
+func (p *PosePoller) GetStats() PoseStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := PoseStats{
+		Polls:    p.polls.Load(),
+		Errors:   p.errors.Load(),
+		AgeMs:    -1,
+		Interval: p.cfg.Interval.Seconds(),
+	}
+	if !p.state.Timestamp.IsZero() {
+		stats.AgeMs = time.Since(p.state.Timestamp).Milliseconds()
+	}
+	if p.lastErr != nil {
+		stats.LastError = p.lastErr.Error()
+	}
+	return stats
+}
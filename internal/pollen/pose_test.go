@@ -0,0 +1,119 @@
+package pollen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const fullStateJSON = `{
+	"control_mode": "enabled",
+	"head_pose": {"x": 0.01, "y": 0, "z": 0.02, "roll": 0.1, "pitch": -0.2, "yaw": 0.3},
+	"head_joints": [0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7],
+	"body_yaw": 0.5,
+	"antennas_position": [0.4, -0.4]
+}`
+
+func TestGetJointState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/state/full" || r.URL.Query().Get("with_head_pose") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(fullStateJSON))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	client := NewClient(cfg, nil)
+
+	state, err := client.GetJointState(context.Background())
+	if err != nil {
+		t.Fatalf("GetJointState() error = %v", err)
+	}
+	if state.HeadPose.Yaw != 0.3 || state.HeadPose.Pitch != -0.2 || state.HeadPose.Z != 0.02 {
+		t.Errorf("HeadPose = %+v", state.HeadPose)
+	}
+	if state.BodyYaw != 0.5 || state.Antennas != [2]float64{0.4, -0.4} || len(state.HeadJoints) != 7 {
+		t.Errorf("state = %+v", state)
+	}
+	if state.Timestamp.IsZero() {
+		t.Error("Timestamp should be set")
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	const limitsJSON = `{"yaw": {"min": -1, "max": 1}, "pitch": {"min": -0.5, "max": 0.5}, "roll": {"min": -0.3, "max": 0.3}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/kinematics/limits" {
+			w.Write([]byte(limitsJSON))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	limits, err := NewClient(cfg, nil).GetLimits(context.Background())
+	if err != nil {
+		t.Fatalf("GetLimits() error = %v", err)
+	}
+	if limits.Yaw != (JointLimit{Min: -1, Max: 1}) || limits.Roll.Max != 0.3 || limits.Source != "pollen" {
+		t.Errorf("limits = %+v", limits)
+	}
+
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := NewClient(cfg, nil).GetLimits(context.Background()); !errors.Is(err, ErrLimitsUnsupported) {
+		t.Errorf("GetLimits() on 404 error = %v, want ErrLimitsUnsupported", err)
+	}
+}
+
+func TestPosePoller(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/state/full" {
+			w.Write([]byte(fullStateJSON))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound) // No limits endpoint
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	poseCfg := DefaultPoseConfig()
+	poseCfg.StaleAfter = 50 * time.Millisecond
+	poller := NewPosePoller(NewClient(cfg, nil), poseCfg, nil)
+
+	if _, fresh := poller.Latest(); fresh {
+		t.Error("Latest() should not be fresh before the first poll")
+	}
+
+	poller.poll(context.Background())
+	state, fresh := poller.Latest()
+	if !fresh || state.HeadPose.Yaw != 0.3 {
+		t.Errorf("Latest() = %+v, %v; want fresh pose", state, fresh)
+	}
+	if limits := poller.Limits(); limits.Source != "safety" || limits.Yaw != DefaultSafetyConfig().Yaw {
+		t.Errorf("Limits() = %+v, want safety fallback", limits)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, fresh := poller.Latest(); fresh {
+		t.Error("Latest() should be stale after StaleAfter")
+	}
+
+	// A failed poll keeps the last pose
+	server.Close()
+	poller.poll(context.Background())
+	if state, _ := poller.Latest(); state.HeadPose.Yaw != 0.3 {
+		t.Errorf("pose lost after failed poll: %+v", state)
+	}
+	if stats := poller.GetStats(); stats.Polls != 2 || stats.Errors != 1 || stats.LastError == "" {
+		t.Errorf("stats = %+v", stats)
+	}
+}
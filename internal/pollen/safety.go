@@ -0,0 +1,342 @@
+package pollen
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStopped is returned for motor commands while the emergency stop is engaged
+var ErrStopped = errors.New("motors stopped")
+
+// JointLimit is the allowed range of one head axis (radians)
+type JointLimit struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// clamp limits v to the range, reporting whether it changed
+func (l JointLimit) clamp(v float64) (float64, bool) {
+	switch {
+	case v < l.Min:
+		return l.Min, true
+	case v > l.Max:
+		return l.Max, true
+	}
+	return v, false
+}
+
+// SafetyConfig configures the motor safety limiter
+type SafetyConfig struct {
+	Enabled         bool          // Clamp and shape head targets (the stop works either way)
+	Yaw             JointLimit    // Head yaw range (radians)
+	Pitch           JointLimit    // Head pitch range (radians)
+	Roll            JointLimit    // Head roll range (radians)
+	MaxVelocity     float64       // Max angular velocity per axis (radians/second)
+	MaxAcceleration float64       // Max angular acceleration per axis (radians/second²)
+	Rate            time.Duration // Interpolation step
+}
+
+// DefaultSafetyConfig returns conservative defaults
+func DefaultSafetyConfig() SafetyConfig {
+	return SafetyConfig{
+		Enabled:         true,
+		Yaw:             JointLimit{Min: -90 * math.Pi / 180, Max: 90 * math.Pi / 180},
+		Pitch:           JointLimit{Min: -35 * math.Pi / 180, Max: 35 * math.Pi / 180},
+		Roll:            JointLimit{Min: -25 * math.Pi / 180, Max: 25 * math.Pi / 180},
+		MaxVelocity:     180 * math.Pi / 180,
+		MaxAcceleration: 720 * math.Pi / 180,
+		Rate:            time.Second / 30,
+	}
+}
+
+// axis is the motion state of one shaped joint
+type axis struct {
+	pos  float64
+	vel  float64
+	goal float64
+}
+
+// step moves the axis toward its goal under the velocity and acceleration
+// limits, braking early enough to stop on the goal
+func (a *axis) step(dt, maxVel, maxAcc float64) {
+	diff := a.goal - a.pos
+	if math.Abs(diff) < 1e-4 && math.Abs(a.vel) < maxAcc*dt {
+		a.pos, a.vel = a.goal, 0
+		return
+	}
+
+	// Fastest speed from which we can still stop at the goal
+	want := math.Copysign(math.Min(maxVel, math.Sqrt(2*maxAcc*math.Abs(diff))), diff)
+	dv := want - a.vel
+	if limit := maxAcc * dt; math.Abs(dv) > limit {
+		dv = math.Copysign(limit, dv)
+	}
+	a.vel += dv
+
+	next := a.pos + a.vel*dt
+	if (a.goal-a.pos)*(a.goal-next) <= 0 {
+		// Would overshoot: land on the goal
+		next, a.vel = a.goal, 0
+	}
+	a.pos = next
+}
+
+// moving returns true until the axis has settled on its goal
+func (a *axis) moving() bool {
+	return a.pos != a.goal || a.vel != 0
+}
+
+// SafetyStatus describes the limiter for /api/motor
+type SafetyStatus struct {
+	Enabled  bool       `json:"enabled"`
+	Stopped  bool       `json:"stopped"`
+	Current  HeadTarget `json:"current"`
+	Goal     HeadTarget `json:"goal"`
+	Clamped  uint64     `json:"clamped"`  // Targets clamped to joint limits
+	Rejected uint64     `json:"rejected"` // Commands refused while stopped
+	Stops    uint64     `json:"stops"`
+}
+
+// Limiter sits between motor command producers and the Pollen client. It
+// clamps head yaw/pitch/roll to joint limits, replaces target jumps with
+// velocity- and acceleration-limited motion, and provides an emergency stop.
+// It has the same SetTarget/SetAntennas/PlayEmotion methods as Client.
+type Limiter struct {
+	client *Client
+	cfg    SafetyConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	yaw      axis
+	pitch    axis
+	roll     axis
+	goal     HeadTarget // Latest (clamped) target, for X/Y/Z and status
+	antennas [2]float64
+	bodyYaw  float64
+	pending  bool // A target arrived that hasn't been sent yet
+	stopped  bool
+
+	clamped  atomic.Uint64
+	rejected atomic.Uint64
+	stops    atomic.Uint64
+}
+
+// NewLimiter wraps client with the safety limiter. The head is assumed to
+// start at the neutral pose.
+func NewLimiter(client *Client, cfg SafetyConfig, logger *slog.Logger) *Limiter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	defaults := DefaultSafetyConfig()
+	if cfg.Rate <= 0 {
+		cfg.Rate = defaults.Rate
+	}
+	if cfg.MaxVelocity <= 0 {
+		cfg.MaxVelocity = defaults.MaxVelocity
+	}
+	if cfg.MaxAcceleration <= 0 {
+		cfg.MaxAcceleration = defaults.MaxAcceleration
+	}
+
+	return &Limiter{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// SetTarget records a head target; Run moves toward it within the limits.
+// With the limiter disabled the target is sent straight through.
+func (l *Limiter) SetTarget(ctx context.Context, head HeadTarget, antennas [2]float64, bodyYaw float64) error {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return ErrStopped
+	}
+
+	if !l.cfg.Enabled {
+		l.goal = head
+		l.yaw.pos, l.pitch.pos, l.roll.pos = head.Yaw, head.Pitch, head.Roll
+		l.yaw.goal, l.pitch.goal, l.roll.goal = head.Yaw, head.Pitch, head.Roll
+		l.bodyYaw = bodyYaw
+		l.mu.Unlock()
+		return l.client.SetTarget(ctx, head, antennas, bodyYaw)
+	}
+
+	var c1, c2, c3 bool
+	head.Yaw, c1 = l.cfg.Yaw.clamp(head.Yaw)
+	head.Pitch, c2 = l.cfg.Pitch.clamp(head.Pitch)
+	head.Roll, c3 = l.cfg.Roll.clamp(head.Roll)
+	if c1 || c2 || c3 {
+		l.clamped.Add(1)
+	}
+
+	l.goal = head
+	l.yaw.goal, l.pitch.goal, l.roll.goal = head.Yaw, head.Pitch, head.Roll
+	l.antennas = antennas
+	l.bodyYaw = bodyYaw
+	l.pending = true
+	l.mu.Unlock()
+	return nil
+}
+
+// SetAntennas passes antenna-only commands through while the head is at rest;
+// during head motion they ride along with the next interpolated target
+func (l *Limiter) SetAntennas(ctx context.Context, antennas [2]float64) error {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return ErrStopped
+	}
+	l.antennas = antennas
+	busy := l.cfg.Enabled && (l.pending || l.yaw.moving() || l.pitch.moving() || l.roll.moving())
+	l.mu.Unlock()
+
+	if busy {
+		return nil
+	}
+	return l.client.SetAntennas(ctx, antennas)
+}
+
+// PlayEmotion forwards emotion animations unless the emergency stop is engaged
+func (l *Limiter) PlayEmotion(ctx context.Context, name string, duration float64) error {
+	if l.Stopped() {
+		l.rejected.Add(1)
+		return ErrStopped
+	}
+	return l.client.PlayEmotion(ctx, name, duration)
+}
+
+// Stop engages the emergency stop: the head holds its current pose and all
+// commands are refused until Resume
+func (l *Limiter) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	l.stopped = true
+	l.pending = false
+	for _, a := range []*axis{&l.yaw, &l.pitch, &l.roll} {
+		a.goal, a.vel = a.pos, 0
+	}
+	hold := l.currentLocked()
+	l.goal = hold
+	antennas, bodyYaw := l.antennas, l.bodyYaw
+	l.mu.Unlock()
+
+	l.stops.Add(1)
+	l.logger.Warn("motor emergency stop engaged")
+
+	// Bypass the client's rate limit: the hold must go out
+	return l.client.sendTarget(ctx, FullBodyTarget{
+		TargetHeadPose: hold,
+		TargetAntennas: antennas,
+		TargetBodyYaw:  bodyYaw,
+	})
+}
+
+// Resume releases the emergency stop
+func (l *Limiter) Resume() {
+	l.mu.Lock()
+	wasStopped := l.stopped
+	l.stopped = false
+	l.mu.Unlock()
+
+	if wasStopped {
+		l.logger.Info("motor emergency stop released")
+	}
+}
+
+// Stopped returns true while the emergency stop is engaged
+func (l *Limiter) Stopped() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stopped
+}
+
+// currentLocked returns the shaped head pose. Caller holds mu.
+func (l *Limiter) currentLocked() HeadTarget {
+	head := l.goal
+	head.Yaw, head.Pitch, head.Roll = l.yaw.pos, l.pitch.pos, l.roll.pos
+	return head
+}
+
+// Step advances the shaped pose by dt and returns the target to send, if any
+func (l *Limiter) Step(dt time.Duration) (FullBodyTarget, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped || !l.cfg.Enabled {
+		return FullBodyTarget{}, false
+	}
+	if !l.pending && !l.yaw.moving() && !l.pitch.moving() && !l.roll.moving() {
+		return FullBodyTarget{}, false
+	}
+	l.pending = false
+
+	seconds := dt.Seconds()
+	for _, a := range []*axis{&l.yaw, &l.pitch, &l.roll} {
+		a.step(seconds, l.cfg.MaxVelocity, l.cfg.MaxAcceleration)
+	}
+
+	return FullBodyTarget{
+		TargetHeadPose: l.currentLocked(),
+		TargetAntennas: l.antennas,
+		TargetBodyYaw:  l.bodyYaw,
+	}, true
+}
+
+// Run sends interpolated targets until ctx is cancelled
+func (l *Limiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.cfg.Rate)
+	defer ticker.Stop()
+
+	l.logger.Info("motor safety limiter started",
+		"enabled", l.cfg.Enabled,
+		"max_velocity", l.cfg.MaxVelocity,
+		"max_acceleration", l.cfg.MaxAcceleration,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			target, ok := l.Step(l.cfg.Rate)
+			if !ok {
+				continue
+			}
+			// The limiter paces commands itself, so skip the client's rate limit
+			if err := l.client.sendTarget(ctx, target); err != nil {
+				l.logger.Debug("shaped motor command failed", "error", err)
+			}
+		}
+	}
+}
+
+// BodyYaw returns the last commanded body yaw
+func (l *Limiter) BodyYaw() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bodyYaw
+}
+
+// Status returns the limiter state
+func (l *Limiter) Status() SafetyStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return SafetyStatus{
+		Enabled:  l.cfg.Enabled,
+		Stopped:  l.stopped,
+		Current:  l.currentLocked(),
+		Goal:     l.goal,
+		Clamped:  l.clamped.Load(),
+		Rejected: l.rejected.Load(),
+		Stops:    l.stops.Load(),
+	}
+}
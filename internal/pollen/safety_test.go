@@ -0,0 +1,166 @@
+package pollen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer captures set_target payloads
+func recordingServer(t *testing.T) (*httptest.Server, func() []FullBodyTarget) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var targets []FullBodyTarget
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var target FullBodyTarget
+		json.NewDecoder(r.Body).Decode(&target)
+		mu.Lock()
+		targets = append(targets, target)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []FullBodyTarget {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]FullBodyTarget(nil), targets...)
+	}
+}
+
+func TestLimiter_ClampsToJointLimits(t *testing.T) {
+	cfg := DefaultSafetyConfig()
+	limiter := NewLimiter(NewClient(DefaultConfig(), nil), cfg, nil)
+
+	limiter.SetTarget(context.Background(), HeadTarget{Yaw: 3, Pitch: -2, Roll: 0.1}, [2]float64{}, 0)
+
+	status := limiter.Status()
+	if status.Goal.Yaw != cfg.Yaw.Max {
+		t.Errorf("goal yaw = %v, want %v", status.Goal.Yaw, cfg.Yaw.Max)
+	}
+	if status.Goal.Pitch != cfg.Pitch.Min {
+		t.Errorf("goal pitch = %v, want %v", status.Goal.Pitch, cfg.Pitch.Min)
+	}
+	if status.Goal.Roll != 0.1 {
+		t.Errorf("goal roll = %v, want 0.1", status.Goal.Roll)
+	}
+	if status.Clamped != 1 {
+		t.Errorf("clamped = %d, want 1", status.Clamped)
+	}
+}
+
+func TestLimiter_ShapesMotion(t *testing.T) {
+	cfg := DefaultSafetyConfig()
+	cfg.MaxVelocity = 2
+	cfg.MaxAcceleration = 10
+	limiter := NewLimiter(NewClient(DefaultConfig(), nil), cfg, nil)
+
+	goal := 1.0
+	limiter.SetTarget(context.Background(), HeadTarget{Yaw: goal}, [2]float64{}, 0)
+
+	dt := 10 * time.Millisecond
+	var prevPos, prevVel float64
+	steps := 0
+	for {
+		target, ok := limiter.Step(dt)
+		if !ok {
+			break
+		}
+		steps++
+		if steps > 1000 {
+			t.Fatal("never reached the goal")
+		}
+
+		pos := target.TargetHeadPose.Yaw
+		vel := (pos - prevPos) / dt.Seconds()
+		if vel > cfg.MaxVelocity+1e-9 {
+			t.Fatalf("step %d: velocity %v exceeds %v", steps, vel, cfg.MaxVelocity)
+		}
+		if acc := math.Abs(vel-prevVel) / dt.Seconds(); pos != goal && acc > cfg.MaxAcceleration+1e-6 {
+			t.Fatalf("step %d: acceleration %v exceeds %v", steps, acc, cfg.MaxAcceleration)
+		}
+		if pos > goal {
+			t.Fatalf("step %d: overshot to %v", steps, pos)
+		}
+		prevPos, prevVel = pos, vel
+	}
+
+	if prevPos != goal {
+		t.Errorf("final yaw = %v, want %v", prevPos, goal)
+	}
+	// 1 rad at 2 rad/s with 10 rad/s² ramps takes ~0.7s
+	if steps < 60 {
+		t.Errorf("reached goal in %d steps, expected shaped motion", steps)
+	}
+}
+
+func TestLimiter_EmergencyStop(t *testing.T) {
+	server, targets := recordingServer(t)
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	limiter := NewLimiter(NewClient(cfg, nil), DefaultSafetyConfig(), nil)
+
+	ctx := context.Background()
+	limiter.SetTarget(ctx, HeadTarget{Yaw: 1}, [2]float64{}, 0)
+	for i := 0; i < 10; i++ {
+		limiter.Step(10 * time.Millisecond)
+	}
+	held := limiter.Status().Current.Yaw
+	if held <= 0 || held >= 1 {
+		t.Fatalf("yaw mid-motion = %v", held)
+	}
+
+	if err := limiter.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	sent := targets()
+	if len(sent) != 1 || sent[0].TargetHeadPose.Yaw != held {
+		t.Errorf("hold targets = %+v, want yaw %v", sent, held)
+	}
+
+	if err := limiter.SetTarget(ctx, HeadTarget{Yaw: 0.5}, [2]float64{}, 0); !errors.Is(err, ErrStopped) {
+		t.Errorf("SetTarget while stopped error = %v, want ErrStopped", err)
+	}
+	if err := limiter.SetAntennas(ctx, [2]float64{1, 1}); !errors.Is(err, ErrStopped) {
+		t.Errorf("SetAntennas while stopped error = %v, want ErrStopped", err)
+	}
+	if _, ok := limiter.Step(10 * time.Millisecond); ok {
+		t.Error("Step moved the head while stopped")
+	}
+
+	limiter.Resume()
+	if err := limiter.SetTarget(ctx, HeadTarget{Yaw: 0.5}, [2]float64{}, 0); err != nil {
+		t.Errorf("SetTarget after Resume error = %v", err)
+	}
+	status := limiter.Status()
+	if status.Stopped || status.Stops != 1 || status.Rejected != 2 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	server, targets := recordingServer(t)
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.RateLimitHz = 0
+	safety := DefaultSafetyConfig()
+	safety.Enabled = false
+	limiter := NewLimiter(NewClient(cfg, nil), safety, nil)
+
+	if err := limiter.SetTarget(context.Background(), HeadTarget{Yaw: 3}, [2]float64{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	sent := targets()
+	if len(sent) != 1 || sent[0].TargetHeadPose.Yaw != 3 {
+		t.Errorf("targets = %+v, want unclamped passthrough", sent)
+	}
+}
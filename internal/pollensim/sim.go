@@ -0,0 +1,333 @@
+// Package pollensim simulates the Pollen robot daemon's HTTP API, so motor,
+// emotion and camera pipelines can be exercised on a laptop without a robot.
+//
+// It implements the endpoints go-eva uses: set_target, emotion play, daemon
+// status/start, full joint state, kinematic limits and camera snapshots.
+package pollensim
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+// Config holds simulator configuration
+type Config struct {
+	Width, Height   int           // Snapshot size
+	Latency         time.Duration // Added to every response
+	MaxSpeed        float64       // Head joint speed toward the target (rad/s, 0 = instant)
+	EmotionDuration time.Duration // Length of emotions played without a duration
+	Limits          pollen.Limits // Reported by /api/kinematics/limits
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	deg := math.Pi / 180
+	return Config{
+		Width:           640,
+		Height:          480,
+		MaxSpeed:        3,
+		EmotionDuration: 3 * time.Second,
+		Limits: pollen.Limits{
+			Yaw:   pollen.JointLimit{Min: -160 * deg, Max: 160 * deg},
+			Pitch: pollen.JointLimit{Min: -40 * deg, Max: 40 * deg},
+			Roll:  pollen.JointLimit{Min: -40 * deg, Max: 40 * deg},
+		},
+	}
+}
+
+// State is the simulated robot state
+type State struct {
+	Head      pollen.HeadTarget `json:"head"`   // Current pose
+	Target    pollen.HeadTarget `json:"target"` // Commanded pose
+	Antennas  [2]float64        `json:"antennas"`
+	BodyYaw   float64           `json:"body_yaw"`
+	Emotion   string            `json:"emotion,omitempty"` // Playing emotion
+	Running   bool              `json:"running"`           // Daemon started
+	Targets   uint64            `json:"targets"`           // set_target calls
+	Emotions  uint64            `json:"emotions"`          // Emotions played
+	Snapshots uint64            `json:"snapshots"`
+}
+
+// Sim is a simulated Pollen daemon; it implements http.Handler
+type Sim struct {
+	cfg    Config
+	logger *slog.Logger
+	mux    *http.ServeMux
+
+	mu           sync.Mutex
+	head         pollen.HeadTarget
+	target       pollen.HeadTarget
+	antennas     [2]float64
+	bodyYaw      float64
+	emotion      string
+	emotionUntil time.Time
+	running      bool
+	updated      time.Time
+
+	// Stats
+	targets   atomic.Uint64
+	emotions  atomic.Uint64
+	snapshots atomic.Uint64
+}
+
+// New creates a simulator
+func New(cfg Config, logger *slog.Logger) *Sim {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		cfg.Width, cfg.Height = DefaultConfig().Width, DefaultConfig().Height
+	}
+	if cfg.EmotionDuration <= 0 {
+		cfg.EmotionDuration = DefaultConfig().EmotionDuration
+	}
+
+	s := &Sim{
+		cfg:     cfg,
+		logger:  logger,
+		mux:     http.NewServeMux(),
+		running: true,
+		updated: time.Now(),
+	}
+	s.mux.HandleFunc("POST /api/move/set_target", s.setTargetHandler)
+	s.mux.HandleFunc("POST /api/emotion/play", s.emotionHandler)
+	s.mux.HandleFunc("GET /api/daemon/status", s.statusHandler)
+	s.mux.HandleFunc("POST /api/daemon/start", s.startHandler)
+	s.mux.HandleFunc("GET /api/state/full", s.stateHandler)
+	s.mux.HandleFunc("GET /api/kinematics/limits", s.limitsHandler)
+	s.mux.HandleFunc("GET /api/video/snapshot", s.snapshotHandler)
+	return s
+}
+
+// ServeHTTP serves the Pollen API
+func (s *Sim) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Latency > 0 {
+		select {
+		case <-time.After(s.cfg.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	s.logger.Debug("pollen-sim request", "method", r.Method, "path", r.URL.Path)
+	s.mux.ServeHTTP(w, r)
+}
+
+// State returns the simulated robot state
+func (s *Sim) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.advanceLocked(now)
+
+	return State{
+		Head:      s.head,
+		Target:    s.target,
+		Antennas:  s.antennas,
+		BodyYaw:   s.bodyYaw,
+		Emotion:   s.playingLocked(now),
+		Running:   s.running,
+		Targets:   s.targets.Load(),
+		Emotions:  s.emotions.Load(),
+		Snapshots: s.snapshots.Load(),
+	}
+}
+
+// SetRunning starts or stops the simulated daemon; a stopped daemon refuses
+// set_target until /api/daemon/start
+func (s *Sim) SetRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+}
+
+// advanceLocked moves the head toward its target at MaxSpeed
+func (s *Sim) advanceLocked(now time.Time) {
+	dt := now.Sub(s.updated).Seconds()
+	s.updated = now
+	if s.cfg.MaxSpeed <= 0 {
+		s.head = s.target
+		return
+	}
+
+	step := s.cfg.MaxSpeed * dt
+	approach := func(cur, goal float64) float64 {
+		if math.Abs(goal-cur) <= step {
+			return goal
+		}
+		if goal > cur {
+			return cur + step
+		}
+		return cur - step
+	}
+	s.head = pollen.HeadTarget{
+		X:     s.target.X,
+		Y:     s.target.Y,
+		Z:     s.target.Z,
+		Roll:  approach(s.head.Roll, s.target.Roll),
+		Pitch: approach(s.head.Pitch, s.target.Pitch),
+		Yaw:   approach(s.head.Yaw, s.target.Yaw),
+	}
+}
+
+// playingLocked returns the emotion playing at now
+func (s *Sim) playingLocked(now time.Time) string {
+	if now.Before(s.emotionUntil) {
+		return s.emotion
+	}
+	return ""
+}
+
+// setTarget is a set_target body; absent fields leave that part unchanged
+type setTarget struct {
+	TargetHeadPose *pollen.HeadTarget `json:"target_head_pose"`
+	TargetAntennas *[2]float64        `json:"target_antennas"`
+	TargetBodyYaw  *float64           `json:"target_body_yaw"`
+}
+
+func (s *Sim) setTargetHandler(w http.ResponseWriter, r *http.Request) {
+	var req setTarget
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid target: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "daemon not running")
+		return
+	}
+	s.advanceLocked(time.Now())
+	if req.TargetHeadPose != nil {
+		s.target = *req.TargetHeadPose
+	}
+	if req.TargetAntennas != nil {
+		s.antennas = *req.TargetAntennas
+	}
+	if req.TargetBodyYaw != nil {
+		s.bodyYaw = *req.TargetBodyYaw
+	}
+	s.mu.Unlock()
+
+	s.targets.Add(1)
+	writeJSON(w, map[string]string{"status": "ok"})
+}
+
+func (s *Sim) emotionHandler(w http.ResponseWriter, r *http.Request) {
+	var req pollen.EmotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid emotion: "+err.Error())
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	duration := s.cfg.EmotionDuration
+	if req.Duration > 0 {
+		duration = time.Duration(req.Duration * float64(time.Second))
+	}
+
+	s.mu.Lock()
+	s.emotion = req.Name
+	s.emotionUntil = time.Now().Add(duration)
+	s.mu.Unlock()
+
+	s.emotions.Add(1)
+	s.logger.Info("pollen-sim emotion", "name", req.Name, "duration", duration)
+	writeJSON(w, map[string]string{"status": "ok"})
+}
+
+func (s *Sim) statusHandler(w http.ResponseWriter, r *http.Request) {
+	state := s.State()
+	status := "stopped"
+	if state.Running {
+		status = "running"
+	}
+	writeJSON(w, map[string]any{
+		"state":     status,
+		"simulated": true,
+		"emotion":   state.Emotion,
+		"targets":   state.Targets,
+	})
+}
+
+func (s *Sim) startHandler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Sim) stateHandler(w http.ResponseWriter, r *http.Request) {
+	state := s.State()
+	writeJSON(w, map[string]any{
+		"head_pose":         state.Head,
+		"body_yaw":          state.BodyYaw,
+		"antennas_position": state.Antennas[:],
+	})
+}
+
+func (s *Sim) limitsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.cfg.Limits)
+}
+
+// snapshotHandler returns a synthetic JPEG: a gradient with a square that
+// sweeps across the frame, offset by the head yaw, so change detection and
+// vision see movement
+func (s *Sim) snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	frame := s.snapshots.Add(1)
+	yaw := s.State().Head.Yaw
+
+	width, height := s.cfg.Width, s.cfg.Height
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 96, A: 255})
+		}
+	}
+
+	size := height / 4
+	span := max(width-size, 1)
+	x0 := (int(frame)*8 + int(yaw/math.Pi*float64(span))) % span
+	if x0 < 0 {
+		x0 += span
+	}
+	y0 := (height - size) / 2
+	for y := y0; y < y0+size; y++ {
+		for x := x0; x < x0+size; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Write(buf.Bytes())
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,129 @@
+package pollensim
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+)
+
+func startSim(t *testing.T, cfg Config) (*Sim, *pollen.Client) {
+	t.Helper()
+
+	sim := New(cfg, nil)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+
+	client := pollen.NewClient(pollen.Config{BaseURL: ts.URL, Timeout: time.Second}, nil)
+	t.Cleanup(func() { client.Close() })
+	return sim, client
+}
+
+func TestSim_MotorAndEmotion(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxSpeed = 0
+	sim, client := startSim(t, cfg)
+	ctx := context.Background()
+
+	head := pollen.HeadTarget{Yaw: 0.4, Pitch: -0.1}
+	if err := client.SetTarget(ctx, head, [2]float64{0.3, -0.3}, 0.2); err != nil {
+		t.Fatalf("SetTarget() error = %v", err)
+	}
+	state, err := client.GetJointState(ctx)
+	if err != nil {
+		t.Fatalf("GetJointState() error = %v", err)
+	}
+	if state.HeadPose != head || state.Antennas != [2]float64{0.3, -0.3} || state.BodyYaw != 0.2 {
+		t.Errorf("joint state = %+v", state)
+	}
+
+	// Antenna-only targets leave the head alone
+	if err := client.SetAntennas(ctx, [2]float64{0.1, 0.1}); err != nil {
+		t.Fatalf("SetAntennas() error = %v", err)
+	}
+	if s := sim.State(); s.Head != head || s.Antennas != [2]float64{0.1, 0.1} {
+		t.Errorf("after antennas = %+v", s)
+	}
+
+	if err := client.PlayEmotion(ctx, "happy", 1); err != nil {
+		t.Fatalf("PlayEmotion() error = %v", err)
+	}
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status["state"] != "running" || status["emotion"] != "happy" {
+		t.Errorf("status = %v", status)
+	}
+
+	limits, err := client.GetLimits(ctx)
+	if err != nil || limits.Yaw != cfg.Limits.Yaw {
+		t.Errorf("GetLimits() = %+v, %v", limits, err)
+	}
+}
+
+func TestSim_HeadMovesAtMaxSpeed(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxSpeed = 1
+	sim, client := startSim(t, cfg)
+
+	if err := client.SetTarget(context.Background(), pollen.HeadTarget{Yaw: 10}, [2]float64{}, 0); err != nil {
+		t.Fatalf("SetTarget() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if yaw := sim.State().Head.Yaw; yaw <= 0 || yaw > 1 {
+		t.Errorf("yaw after 100ms at 1 rad/s = %v", yaw)
+	}
+}
+
+func TestSim_DaemonStopped(t *testing.T) {
+	sim, client := startSim(t, DefaultConfig())
+	ctx := context.Background()
+
+	sim.SetRunning(false)
+	if err := client.SetTarget(ctx, pollen.HeadTarget{}, [2]float64{}, 0); err == nil {
+		t.Error("SetTarget() should fail while the daemon is stopped")
+	}
+	if err := client.StartDaemon(ctx); err != nil {
+		t.Fatalf("StartDaemon() error = %v", err)
+	}
+	if err := client.SetTarget(ctx, pollen.HeadTarget{}, [2]float64{}, 0); err != nil {
+		t.Errorf("SetTarget() after start error = %v", err)
+	}
+}
+
+func TestSim_Snapshot(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Width, cfg.Height = 160, 120
+	sim := New(cfg, nil)
+
+	var frames [][]byte
+	for range 2 {
+		rec := httptest.NewRecorder()
+		sim.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/video/snapshot", nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/jpeg" {
+			t.Fatalf("snapshot = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		img, err := jpeg.Decode(bytes.NewReader(rec.Body.Bytes()))
+		if err != nil {
+			t.Fatalf("decode snapshot: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 160 || b.Dy() != 120 {
+			t.Errorf("snapshot size = %v", b)
+		}
+		frames = append(frames, rec.Body.Bytes())
+	}
+
+	if bytes.Equal(frames[0], frames[1]) {
+		t.Error("consecutive snapshots should differ")
+	}
+	if sim.State().Snapshots != 2 {
+		t.Errorf("snapshots = %d, want 2", sim.State().Snapshots)
+	}
+}
@@ -0,0 +1,221 @@
+// Package privacy provides a global privacy switch that suspends sensor
+// capture and blocks sensor data from leaving the robot.
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Config holds privacy switch configuration
+type Config struct {
+	ButtonPath      string        // GPIO value file for a hardware toggle button (optional)
+	ButtonActiveLow bool          // Button reads "0" when pressed
+	PollInterval    time.Duration // Button poll interval
+	LEDPath         string        // LED brightness/value file lit while private (optional)
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		ButtonActiveLow: true,
+		PollInterval:    50 * time.Millisecond,
+	}
+}
+
+// blockedTypes are outgoing message types that carry sensor data
+var blockedTypes = map[protocol.MessageType]bool{
+	protocol.TypeFrame:      true,
+	protocol.TypeMic:        true,
+	protocol.TypeDOA:        true,
+	protocol.TypeDOABatch:   true,
+	protocol.TypeTranscript: true,
+	protocol.TypeUtterance:  true,
+	protocol.TypeBargeIn:    true,
+	protocol.TypeHistory:    true,
+
+	protocol.TypeWakeCandidate: true,
+}
+
+// Guard is the global privacy switch
+type Guard struct {
+	cfg    Config
+	logger *slog.Logger
+
+	enabled atomic.Bool
+
+	mu       sync.Mutex
+	source   string
+	changed  time.Time
+	onChange func(enabled bool, source string)
+
+	// Stats
+	toggles atomic.Uint64
+	blocked atomic.Uint64
+}
+
+// NewGuard creates a new privacy guard (initially off)
+func NewGuard(cfg Config, logger *slog.Logger) *Guard {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig().PollInterval
+	}
+
+	return &Guard{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// TopicChanges carries the privacy status after each change on the event bus
+var TopicChanges = bus.NewTopic[Status]("privacy.change")
+
+// OnChange sets the callback invoked when privacy mode changes
+func (g *Guard) OnChange(callback func(enabled bool, source string)) {
+	g.mu.Lock()
+	g.onChange = callback
+	g.mu.Unlock()
+}
+
+// PublishTo routes privacy changes to the event bus as Status, replacing OnChange
+func (g *Guard) PublishTo(b *bus.Bus) {
+	g.OnChange(func(bool, string) {
+		bus.Publish(b, TopicChanges, g.GetStatus())
+	})
+}
+
+// Enabled reports whether privacy mode is on
+func (g *Guard) Enabled() bool {
+	return g.enabled.Load()
+}
+
+// Set turns privacy mode on or off; source identifies the requester (api, cloud, button)
+func (g *Guard) Set(enabled bool, source string) {
+	if g.enabled.Swap(enabled) == enabled {
+		return
+	}
+
+	g.toggles.Add(1)
+
+	g.mu.Lock()
+	g.source = source
+	g.changed = time.Now()
+	callback := g.onChange
+	g.mu.Unlock()
+
+	g.logger.Info("privacy mode changed", "enabled", enabled, "source", source)
+
+	if err := g.setLED(enabled); err != nil {
+		g.logger.Warn("privacy LED update failed", "error", err)
+	}
+
+	if callback != nil {
+		callback(enabled, source)
+	}
+}
+
+// Toggle flips privacy mode
+func (g *Guard) Toggle(source string) {
+	g.Set(!g.Enabled(), source)
+}
+
+// Blocks reports whether an outgoing message type must be dropped.
+// Counts each blocked message so enforcement is observable.
+func (g *Guard) Blocks(msgType protocol.MessageType) bool {
+	if !g.enabled.Load() || !blockedTypes[msgType] {
+		return false
+	}
+	g.blocked.Add(1)
+	return true
+}
+
+func (g *Guard) setLED(on bool) error {
+	if g.cfg.LEDPath == "" {
+		return nil
+	}
+
+	value := "0"
+	if on {
+		value = "1"
+	}
+	if err := os.WriteFile(g.cfg.LEDPath, []byte(value), 0644); err != nil {
+		return fmt.Errorf("write led: %w", err)
+	}
+	return nil
+}
+
+// WatchButton polls the GPIO button and toggles privacy on each press
+func (g *Guard) WatchButton(ctx context.Context) {
+	if g.cfg.ButtonPath == "" {
+		return
+	}
+
+	ticker := time.NewTicker(g.cfg.PollInterval)
+	defer ticker.Stop()
+
+	wasPressed := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pressed, err := g.readButton()
+			if err != nil {
+				g.logger.Debug("privacy button read failed", "error", err)
+				continue
+			}
+			if pressed && !wasPressed {
+				g.Toggle("button")
+			}
+			wasPressed = pressed
+		}
+	}
+}
+
+func (g *Guard) readButton() (bool, error) {
+	data, err := os.ReadFile(g.cfg.ButtonPath)
+	if err != nil {
+		return false, err
+	}
+
+	high := strings.TrimSpace(string(data)) == "1"
+	if g.cfg.ButtonActiveLow {
+		return !high, nil
+	}
+	return high, nil
+}
+
+// Status describes the current privacy state
+type Status struct {
+	Enabled   bool      `json:"enabled"`
+	Source    string    `json:"source,omitempty"`
+	ChangedAt time.Time `json:"changed_at,omitempty"`
+	Toggles   uint64    `json:"toggles"`
+	Blocked   uint64    `json:"blocked_messages"`
+}
+
+// GetStatus returns the current privacy state and statistics
+func (g *Guard) GetStatus() Status {
+	g.mu.Lock()
+	source, changed := g.source, g.changed
+	g.mu.Unlock()
+
+	return Status{
+		Enabled:   g.enabled.Load(),
+		Source:    source,
+		ChangedAt: changed,
+		Toggles:   g.toggles.Load(),
+		Blocked:   g.blocked.Load(),
+	}
+}
@@ -0,0 +1,92 @@
+package privacy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func TestGuard_Blocks(t *testing.T) {
+	g := NewGuard(DefaultConfig(), nil)
+
+	if g.Blocks(protocol.TypeFrame) {
+		t.Error("frames should pass when privacy is off")
+	}
+
+	g.Set(true, "api")
+
+	for _, typ := range []protocol.MessageType{protocol.TypeFrame, protocol.TypeMic, protocol.TypeDOA, protocol.TypeTranscript, protocol.TypeUtterance, protocol.TypeHistory} {
+		if !g.Blocks(typ) {
+			t.Errorf("%s should be blocked in privacy mode", typ)
+		}
+	}
+	if g.Blocks(protocol.TypePong) {
+		t.Error("pong should never be blocked")
+	}
+
+	if got := g.GetStatus(); got.Blocked != 6 || got.Source != "api" {
+		t.Errorf("unexpected status %+v", got)
+	}
+}
+
+func TestGuard_OnChangeAndLED(t *testing.T) {
+	led := filepath.Join(t.TempDir(), "brightness")
+
+	cfg := DefaultConfig()
+	cfg.LEDPath = led
+	g := NewGuard(cfg, nil)
+
+	var changes []bool
+	g.OnChange(func(enabled bool, source string) { changes = append(changes, enabled) })
+
+	g.Set(true, "cloud")
+	g.Set(true, "cloud") // no-op
+	g.Toggle("api")
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	data, err := os.ReadFile(led)
+	if err != nil {
+		t.Fatalf("read led: %v", err)
+	}
+	if string(data) != "0" {
+		t.Errorf("expected LED off, got %q", data)
+	}
+}
+
+func TestGuard_WatchButton(t *testing.T) {
+	button := filepath.Join(t.TempDir(), "value")
+	os.WriteFile(button, []byte("1\n"), 0644) // released (active low)
+
+	cfg := DefaultConfig()
+	cfg.ButtonPath = button
+	cfg.PollInterval = 2 * time.Millisecond
+	g := NewGuard(cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go g.WatchButton(ctx)
+
+	time.Sleep(10 * time.Millisecond)
+	os.WriteFile(button, []byte("0\n"), 0644) // pressed
+
+	deadline := time.Now().Add(time.Second)
+	for !g.Enabled() && time.Now().Before(deadline) {
+		time.Sleep(2 * time.Millisecond)
+	}
+	if !g.Enabled() {
+		t.Fatal("button press should enable privacy mode")
+	}
+
+	// Holding the button must not toggle again
+	time.Sleep(20 * time.Millisecond)
+	if g.GetStatus().Toggles != 1 {
+		t.Errorf("expected 1 toggle, got %d", g.GetStatus().Toggles)
+	}
+}
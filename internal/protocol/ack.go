@@ -0,0 +1,44 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// TypeAck reports the outcome of a message that carried an ID (Bidirectional)
+const TypeAck MessageType = "ack"
+
+// AckData acknowledges (OK) or rejects (Error set) the message with ID
+type AckData struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewMessageID returns a random ID for correlating a request with its ack
+func NewMessageID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewAckMessage creates an ack for id; a non-nil err makes it a nack
+func NewAckMessage(id string, err error) (*Message, error) {
+	data := AckData{ID: id, OK: err == nil}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	return NewMessage(TypeAck, data)
+}
+
+// GetAckData extracts ack data from a message
+func (m *Message) GetAckData() (*AckData, error) {
+	var data AckData
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,115 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BinarySubprotocol is the WebSocket subprotocol offered at connect time.
+// When the cloud selects it, frames are sent as binary messages instead of base64 JSON.
+const BinarySubprotocol = "go-eva.binary.v1"
+
+// BinarySubprotocolV2 is offered alongside BinarySubprotocol; selecting it
+// gets binary frames that also carry their capture time
+const BinarySubprotocolV2 = "go-eva.binary.v2"
+
+// Binary message layout (big endian):
+//
+//	[0]      version (1 or 2)
+//	[1]      type (BinaryTypeFrame)
+//	[2:10]   frame_id
+//	[10:12]  width
+//	[12:14]  height
+//	[14]     format (BinaryFormatJPEG)
+//	[15]     reserved
+//	[16:]    payload (version 1)
+//
+// Version 2 inserts the capture time before the payload:
+//
+//	[16:24]  captured_at (unix ms, robot clock)
+//	[24:32]  cloud_captured_at (unix ms, cloud clock; 0 = not synced)
+//	[32:]    payload
+const (
+	binaryVersion      = 1
+	binaryVersionTimed = 2
+	BinaryHeaderSize   = 16
+	BinaryHeaderSizeV2 = 32
+
+	BinaryTypeFrame  byte = 1
+	BinaryFormatJPEG byte = 1
+)
+
+// BinaryFrame is a decoded binary frame message
+type BinaryFrame struct {
+	FrameID         uint64
+	Width           int
+	Height          int
+	CapturedAt      int64  // Unix ms on the robot's clock (0 in version 1)
+	CloudCapturedAt int64  // Unix ms on the cloud's clock (0 = not synced or version 1)
+	Data            []byte // Raw JPEG
+}
+
+// EncodeBinaryFrame builds a binary frame message from raw JPEG data
+func EncodeBinaryFrame(width, height int, jpegData []byte, frameID uint64) []byte {
+	buf := make([]byte, BinaryHeaderSize+len(jpegData))
+	putBinaryHeader(buf, binaryVersion, width, height, frameID)
+	copy(buf[BinaryHeaderSize:], jpegData)
+	return buf
+}
+
+// EncodeTimedBinaryFrame builds a version 2 binary frame message, which
+// carries the capture time on the robot's and the cloud's clock
+func EncodeTimedBinaryFrame(width, height int, jpegData []byte, frameID uint64, capturedAt, cloudCapturedAt int64) []byte {
+	buf := make([]byte, BinaryHeaderSizeV2+len(jpegData))
+	putBinaryHeader(buf, binaryVersionTimed, width, height, frameID)
+	binary.BigEndian.PutUint64(buf[16:24], uint64(capturedAt))
+	binary.BigEndian.PutUint64(buf[24:32], uint64(cloudCapturedAt))
+	copy(buf[BinaryHeaderSizeV2:], jpegData)
+	return buf
+}
+
+// putBinaryHeader writes the header fields both versions share
+func putBinaryHeader(buf []byte, version byte, width, height int, frameID uint64) {
+	buf[0] = version
+	buf[1] = BinaryTypeFrame
+	binary.BigEndian.PutUint64(buf[2:10], frameID)
+	binary.BigEndian.PutUint16(buf[10:12], uint16(width))
+	binary.BigEndian.PutUint16(buf[12:14], uint16(height))
+	buf[14] = BinaryFormatJPEG
+}
+
+// DecodeBinaryFrame parses a binary frame message of either version
+func DecodeBinaryFrame(data []byte) (*BinaryFrame, error) {
+	if len(data) < BinaryHeaderSize {
+		return nil, fmt.Errorf("binary message too short: %d bytes", len(data))
+	}
+	headerSize := BinaryHeaderSize
+	switch data[0] {
+	case binaryVersion:
+	case binaryVersionTimed:
+		headerSize = BinaryHeaderSizeV2
+		if len(data) < headerSize {
+			return nil, fmt.Errorf("binary message too short: %d bytes", len(data))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported binary version %d", data[0])
+	}
+	if data[1] != BinaryTypeFrame {
+		return nil, fmt.Errorf("unexpected binary message type %d", data[1])
+	}
+	if data[14] != BinaryFormatJPEG {
+		return nil, fmt.Errorf("unsupported frame format %d", data[14])
+	}
+
+	frame := &BinaryFrame{
+		FrameID: binary.BigEndian.Uint64(data[2:10]),
+		Width:   int(binary.BigEndian.Uint16(data[10:12])),
+		Height:  int(binary.BigEndian.Uint16(data[12:14])),
+		Data:    data[headerSize:],
+	}
+	if data[0] == binaryVersionTimed {
+		frame.CapturedAt = int64(binary.BigEndian.Uint64(data[16:24]))
+		frame.CloudCapturedAt = int64(binary.BigEndian.Uint64(data[24:32]))
+	}
+	return frame, nil
+}
@@ -0,0 +1,63 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBinaryFrameRoundTrip(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 1, 2, 3}
+
+	data := EncodeBinaryFrame(640, 480, jpeg, 1234567890123)
+	if len(data) != BinaryHeaderSize+len(jpeg) {
+		t.Fatalf("expected %d bytes, got %d", BinaryHeaderSize+len(jpeg), len(data))
+	}
+
+	frame, err := DecodeBinaryFrame(data)
+	if err != nil {
+		t.Fatalf("DecodeBinaryFrame() error = %v", err)
+	}
+
+	if frame.Width != 640 || frame.Height != 480 || frame.FrameID != 1234567890123 {
+		t.Errorf("unexpected header %+v", frame)
+	}
+	if !bytes.Equal(frame.Data, jpeg) {
+		t.Error("payload mismatch")
+	}
+}
+
+func TestDecodeBinaryFrame_Invalid(t *testing.T) {
+	if _, err := DecodeBinaryFrame([]byte{1, 1, 0}); err == nil {
+		t.Error("short message should fail")
+	}
+
+	data := EncodeBinaryFrame(1, 1, nil, 1)
+	data[0] = 9
+	if _, err := DecodeBinaryFrame(data); err == nil {
+		t.Error("unknown version should fail")
+	}
+}
+
+func TestTimedBinaryFrameRoundTrip(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 1, 2, 3}
+
+	data := EncodeTimedBinaryFrame(640, 480, jpeg, 7, 1700000000123, 1700000000456)
+	if len(data) != BinaryHeaderSizeV2+len(jpeg) {
+		t.Fatalf("expected %d bytes, got %d", BinaryHeaderSizeV2+len(jpeg), len(data))
+	}
+
+	frame, err := DecodeBinaryFrame(data)
+	if err != nil {
+		t.Fatalf("DecodeBinaryFrame() error = %v", err)
+	}
+	if frame.FrameID != 7 || frame.CapturedAt != 1700000000123 || frame.CloudCapturedAt != 1700000000456 {
+		t.Errorf("unexpected header %+v", frame)
+	}
+	if !bytes.Equal(frame.Data, jpeg) {
+		t.Error("payload mismatch")
+	}
+
+	if _, err := DecodeBinaryFrame(data[:BinaryHeaderSize+4]); err == nil {
+		t.Error("truncated version 2 header should fail")
+	}
+}
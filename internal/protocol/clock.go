@@ -0,0 +1,62 @@
+package protocol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ClockSyncData rides on ping and pong messages so either side can estimate
+// the other's clock, NTP style. The pinging side sets Seq and Origin; the
+// pong echoes them and adds when the ping arrived and when the pong left,
+// on the responder's clock. Times are unix ms.
+type ClockSyncData struct {
+	Seq      uint64 `json:"seq"`
+	Origin   int64  `json:"origin"`             // Ping sent (pinger's clock)
+	Receive  int64  `json:"receive,omitempty"`  // Ping received (responder's clock)
+	Transmit int64  `json:"transmit,omitempty"` // Pong sent (responder's clock)
+}
+
+// NewClockPing creates a ping carrying clock sync data
+func NewClockPing(seq uint64, sent time.Time) (*Message, error) {
+	return NewMessage(TypePing, ClockSyncData{Seq: seq, Origin: sent.UnixMilli()})
+}
+
+// NewPong answers ping, which arrived at received. A ping with clock sync
+// data gets it echoed with the receive and transmit times filled in; any
+// other ping gets a bare pong.
+func NewPong(ping *Message, received time.Time) *Message {
+	now := time.Now()
+	pong := &Message{Type: TypePong, Timestamp: now.UnixMilli()}
+
+	sync, ok := ping.GetClockSyncData()
+	if !ok {
+		return pong
+	}
+	sync.Receive = received.UnixMilli()
+	sync.Transmit = now.UnixMilli()
+	if data, err := json.Marshal(sync); err == nil {
+		pong.Data = data
+	}
+	return pong
+}
+
+// GetClockSyncData extracts clock sync data from a ping or pong; ok is false
+// if the message carries none
+func (m *Message) GetClockSyncData() (data ClockSyncData, ok bool) {
+	if m.ParseData(&data) != nil || data.Origin == 0 {
+		return ClockSyncData{}, false
+	}
+	return data, true
+}
+
+// Turnaround is how long the responder held the ping before answering
+func (d ClockSyncData) Turnaround() time.Duration {
+	return time.Duration(d.Transmit-d.Receive) * time.Millisecond
+}
+
+// Midpoint is the responder's clock halfway through its turnaround, which
+// lines up with the midpoint of the round trip on the pinger's side when
+// the network delay is symmetric
+func (d ClockSyncData) Midpoint() time.Time {
+	return time.UnixMilli(d.Receive + (d.Transmit-d.Receive)/2)
+}
@@ -0,0 +1,50 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPong_ClockSync(t *testing.T) {
+	sent := time.UnixMilli(1700000000000)
+	ping, err := NewClockPing(3, sent)
+	if err != nil {
+		t.Fatalf("NewClockPing() error = %v", err)
+	}
+
+	received := time.Now()
+	pong := NewPong(ping, received)
+	if pong.Type != TypePong {
+		t.Fatalf("Type = %v, want %v", pong.Type, TypePong)
+	}
+	sync, ok := pong.GetClockSyncData()
+	if !ok {
+		t.Fatal("pong carries no clock sync data")
+	}
+	if sync.Seq != 3 || sync.Origin != sent.UnixMilli() {
+		t.Errorf("seq/origin = %d/%d, want 3/%d", sync.Seq, sync.Origin, sent.UnixMilli())
+	}
+	if sync.Receive != received.UnixMilli() || sync.Transmit < sync.Receive {
+		t.Errorf("receive/transmit = %d/%d, want %d and no earlier", sync.Receive, sync.Transmit, received.UnixMilli())
+	}
+}
+
+func TestNewPong_BarePing(t *testing.T) {
+	pong := NewPong(&Message{Type: TypePing}, time.Now())
+	if pong.Data != nil {
+		t.Errorf("bare ping got pong data %s", pong.Data)
+	}
+	if _, ok := pong.GetClockSyncData(); ok {
+		t.Error("bare pong reports clock sync data")
+	}
+}
+
+func TestClockSyncData_Midpoint(t *testing.T) {
+	d := ClockSyncData{Origin: 1, Receive: 1000, Transmit: 1010}
+	if got := d.Midpoint().UnixMilli(); got != 1005 {
+		t.Errorf("Midpoint() = %d, want 1005", got)
+	}
+	if got := d.Turnaround(); got != 10*time.Millisecond {
+		t.Errorf("Turnaround() = %v, want 10ms", got)
+	}
+}
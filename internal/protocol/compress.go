@@ -0,0 +1,54 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Gzip-compressed JSON messages are sent as binary WebSocket messages, only
+// to a peer whose hello lists CapabilityGzip. They start with the gzip magic
+// (1f 8b), which no binary frame does (frames start with their version).
+
+// MaxGunzipSize limits how large a compressed message may inflate
+const MaxGunzipSize = 16 << 20
+
+// GzipJSON compresses an encoded message at the given flate level
+func GzipJSON(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// IsGzip reports whether a binary message is gzip-compressed JSON
+func IsGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// GunzipJSON decompresses a message compressed by GzipJSON
+func GunzipJSON(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, MaxGunzipSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	if len(out) > MaxGunzipSize {
+		return nil, fmt.Errorf("gzip: message inflates beyond %d bytes", MaxGunzipSize)
+	}
+	return out, nil
+}
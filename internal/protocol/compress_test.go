@@ -0,0 +1,44 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+)
+
+func TestGzipJSONRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"type":"doa","data":{"angle":0.5}}`), 100)
+
+	zipped, err := GzipJSON(data, flate.BestSpeed)
+	if err != nil {
+		t.Fatalf("GzipJSON() error = %v", err)
+	}
+	if !IsGzip(zipped) {
+		t.Error("IsGzip() = false for a compressed message")
+	}
+	if len(zipped) >= len(data) {
+		t.Errorf("compressed %d bytes to %d", len(data), len(zipped))
+	}
+
+	out, err := GunzipJSON(zipped)
+	if err != nil {
+		t.Fatalf("GunzipJSON() error = %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("round trip mismatch")
+	}
+
+	if IsGzip(EncodeBinaryFrame(1, 1, []byte{0x1f, 0x8b}, 1)) {
+		t.Error("IsGzip() = true for a binary frame")
+	}
+}
+
+func TestGunzipJSON_TooLarge(t *testing.T) {
+	zipped, err := GzipJSON(make([]byte, MaxGunzipSize+1), flate.BestCompression)
+	if err != nil {
+		t.Fatalf("GzipJSON() error = %v", err)
+	}
+	if _, err := GunzipJSON(zipped); err == nil {
+		t.Error("GunzipJSON() should refuse a message inflating past the limit")
+	}
+}
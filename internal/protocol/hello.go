@@ -0,0 +1,113 @@
+package protocol
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TypeHello is the first message a robot sends after connecting (Robot →
+// Cloud). The cloud may answer with its own hello to settle the protocol
+// version.
+const TypeHello MessageType = "hello"
+
+// HelloData identifies the robot to the cloud.
+// When a shared secret is configured, Signature is hex(HMAC-SHA256) over
+// robot_id, version, ts and nonce joined by newlines.
+type HelloData struct {
+	RobotID         string   `json:"robot_id"`
+	Version         string   `json:"version"`
+	ProtocolVersion int      `json:"protocol_version,omitempty"` // Highest version the robot speaks, or the version the cloud chose
+	Timestamp       int64    `json:"ts"`                         // Unix ms
+	Nonce           string   `json:"nonce"`
+	HardwareSerial  string   `json:"hardware_serial,omitempty"`
+	SerialSource    string   `json:"serial_source,omitempty"` // xvf3800 or pi
+	Firmware        string   `json:"firmware,omitempty"`      // XVF3800 firmware version and build
+	Capabilities    []string `json:"capabilities,omitempty"`  // Capability* flags
+	AudioCodecs     []string `json:"audio_codecs,omitempty"`  // Supported audio codecs, preferred first
+	Signature       string   `json:"signature,omitempty"`
+}
+
+// Capability flags advertised in the hello
+const (
+	CapabilityBinaryFrames = "binary_frames" // Binary frame transport negotiated
+	CapabilityCamera       = "camera"        // Sends video frames
+	CapabilityMic          = "mic"           // Streams mic audio
+	CapabilitySpeaker      = "speaker"       // Plays speak audio
+	CapabilityTTS          = "tts"           // Speaks text commands locally
+	CapabilitySTT          = "stt"           // Sends local transcripts
+	CapabilityMotor        = "motor"         // Accepts motor and emotion commands
+	CapabilityVision       = "vision"        // Fuses face detection with DOA
+	CapabilityGzip         = "gzip"          // Accepts gzip-compressed JSON messages (see GzipJSON)
+	CapabilityClockSync    = "clock_sync"    // Pings with ClockSyncData and stamps messages with cloud_ts
+	CapabilitySpeakCache   = "speak_cache"   // Plays speak audio by SpeakData.CacheKey
+	CapabilityDOABatch     = "doa_batch"     // Sends DOA as TypeDOABatch; the cloud opts in by listing it in its hello
+	CapabilityWake         = "wake"          // Sends wake candidates followed by their pre-roll audio
+)
+
+// NewHello creates hello data with a fresh timestamp and random nonce
+func NewHello(robotID, version string, capabilities []string) (HelloData, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return HelloData{}, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return HelloData{
+		RobotID:         robotID,
+		Version:         version,
+		ProtocolVersion: ProtocolVersion,
+		Timestamp:       time.Now().UnixMilli(),
+		Nonce:           hex.EncodeToString(nonce),
+		Capabilities:    capabilities,
+	}, nil
+}
+
+// signingPayload is the canonical byte string covered by the signature
+func (h *HelloData) signingPayload() []byte {
+	return []byte(h.RobotID + "\n" + h.Version + "\n" + strconv.FormatInt(h.Timestamp, 10) + "\n" + h.Nonce)
+}
+
+// Sign sets Signature using the shared secret
+func (h *HelloData) Sign(secret []byte) {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(h.signingPayload())
+	h.Signature = hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and that the timestamp is within maxSkew of now
+func (h *HelloData) Verify(secret []byte, maxSkew time.Duration, now time.Time) error {
+	sig, err := hex.DecodeString(h.Signature)
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("missing or malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(h.signingPayload())
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	skew := now.Sub(time.UnixMilli(h.Timestamp))
+	if skew < -maxSkew || skew > maxSkew {
+		return fmt.Errorf("timestamp skew %v exceeds %v", skew, maxSkew)
+	}
+	return nil
+}
+
+// NewHelloMessage creates a hello message
+func NewHelloMessage(data HelloData) (*Message, error) {
+	return NewMessage(TypeHello, data)
+}
+
+// GetHello extracts hello data from a message
+func (m *Message) GetHello() (*HelloData, error) {
+	var data HelloData
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,54 @@
+package protocol
+
+import "encoding/json"
+
+// TypeHistory carries a batch of history rows for long-horizon analytics
+// (Robot → Cloud), sent when history.backend is remote
+const TypeHistory MessageType = "history"
+
+// HistoryData is a batch of downsampled readings, speech segments and
+// events, oldest first within each list
+type HistoryData struct {
+	Readings []HistoryReading `json:"readings,omitempty"`
+	Segments []HistorySegment `json:"segments,omitempty"`
+	Events   []HistoryEvent   `json:"events,omitempty"`
+}
+
+// Len returns the number of rows in the batch
+func (d HistoryData) Len() int {
+	return len(d.Readings) + len(d.Segments) + len(d.Events)
+}
+
+// HistoryReading is a downsampled tracker result
+type HistoryReading struct {
+	Timestamp       int64   `json:"ts"` // Unix ms
+	Angle           float64 `json:"angle"`
+	SmoothedAngle   float64 `json:"smoothed_angle"`
+	Confidence      float64 `json:"confidence"`
+	Speaking        bool    `json:"speaking"`
+	SpeakingLatched bool    `json:"speaking_latched"`
+	TotalEnergy     float64 `json:"total_energy"`
+	EstX            float64 `json:"est_x"`
+	EstY            float64 `json:"est_y"`
+}
+
+// HistorySegment is a stretch of latched speech
+type HistorySegment struct {
+	Start      int64   `json:"start_ts"` // Unix ms
+	End        int64   `json:"end_ts"`   // Unix ms
+	DurationMs int64   `json:"duration_ms"`
+	AvgAngle   float64 `json:"avg_angle"` // Radians
+	PeakEnergy float64 `json:"peak_energy"`
+}
+
+// HistoryEvent is a notable event with a JSON payload
+type HistoryEvent struct {
+	Timestamp int64           `json:"ts"` // Unix ms
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// NewHistoryMessage creates a history message
+func NewHistoryMessage(data HistoryData) (*Message, error) {
+	return NewMessage(TypeHistory, data)
+}
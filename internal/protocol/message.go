@@ -5,7 +5,9 @@ package protocol
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -14,17 +16,34 @@ type MessageType string
 
 const (
 	// Robot → Cloud messages
-	TypeFrame MessageType = "frame" // Video frame
-	TypeDOA   MessageType = "doa"   // Direction of arrival
-	TypeMic   MessageType = "mic"   // Microphone audio
-	TypeState MessageType = "state" // Robot state
+	TypeFrame    MessageType = "frame"     // Video frame
+	TypeDOA      MessageType = "doa"       // Direction of arrival
+	TypeDOABatch MessageType = "doa_batch" // Several DOA readings, to clouds with CapabilityDOABatch
+	TypeMic      MessageType = "mic"       // Microphone audio
+	TypeState    MessageType = "state"     // Robot state
+
+	TypeTranscript MessageType = "transcript" // On-robot speech-to-text result
+	TypeUtterance  MessageType = "utterance"  // Utterance start/end from the DOA tracker
+	TypeBargeIn    MessageType = "barge_in"   // Someone talked over the robot's playback
+
+	TypeWakeCandidate MessageType = "wake_candidate" // Energy onset that may be the wake word
+
+	TypeEmotionStarted  MessageType = "emotion_started"  // An emotion animation began playing
+	TypeEmotionFinished MessageType = "emotion_finished" // An emotion animation ended or failed
+
+	TypeConfigApplied MessageType = "config_applied" // Acknowledges a config update with the settings in effect
 
 	// Cloud → Robot messages
 	TypeMotor   MessageType = "motor"   // Motor command
 	TypeSpeak   MessageType = "speak"   // TTS audio playback
 	TypeEmotion MessageType = "emotion" // Play emotion animation
+	TypeChoreo  MessageType = "choreo"  // Play a keyframed motion sequence
 	TypeConfig  MessageType = "config"  // Configuration update
 
+	TypePrivacy MessageType = "privacy" // Toggle global privacy mode
+
+	TypeStopSpeak MessageType = "stop_speak" // Stop speaker playback
+
 	// Bidirectional
 	TypePing MessageType = "ping"
 	TypePong MessageType = "pong"
@@ -33,7 +52,11 @@ const (
 // Message is the base wrapper for all WebSocket messages
 type Message struct {
 	Type      MessageType     `json:"type"`
+	ID        string          `json:"id,omitempty"`       // Set when the sender wants an ack
+	RobotID   string          `json:"robot_id,omitempty"` // Sending robot, or the robot a cloud message is for
 	Timestamp int64           `json:"ts,omitempty"`
+	CloudTS   int64           `json:"cloud_ts,omitempty"` // Timestamp on the cloud's clock, once clock sync has an estimate (robot messages only)
+	TraceID   string          `json:"trace_id,omitempty"` // Correlates a command with its ack and the robot's logs
 	Data      json.RawMessage `json:"data,omitempty"`
 }
 
@@ -84,17 +107,50 @@ type FrameData struct {
 	Format  string `json:"format"`
 	Data    string `json:"data"`
 	FrameID uint64 `json:"frame_id,omitempty"`
+
+	CapturedAt      int64 `json:"captured_at,omitempty"`       // When the frame was captured (unix ms)
+	CloudCapturedAt int64 `json:"cloud_captured_at,omitempty"` // CapturedAt on the cloud's clock (0 = not synced)
 }
 
 // NewFrameMessage creates a frame message from raw JPEG data
 func NewFrameMessage(width, height int, jpegData []byte, frameID uint64) (*Message, error) {
-	return NewMessage(TypeFrame, FrameData{
-		Width:   width,
-		Height:  height,
-		Format:  "jpeg",
-		Data:    base64.StdEncoding.EncodeToString(jpegData),
-		FrameID: frameID,
-	})
+	return NewTimedFrameMessage(width, height, jpegData, frameID, 0, 0)
+}
+
+// NewTimedFrameMessage creates a frame message stamped with its capture time
+// on the robot's clock and, if known, the cloud's (unix ms, 0 = unknown)
+//
+// The FrameData JSON is written directly into one buffer sized for the
+// base64 payload, rather than encoding to a string and marshaling a copy of
+// it, since this runs for every frame sent to a JSON-only cloud.
+func NewTimedFrameMessage(width, height int, jpegData []byte, frameID uint64, capturedAt, cloudCapturedAt int64) (*Message, error) {
+	data := make([]byte, 0, base64.StdEncoding.EncodedLen(len(jpegData))+128)
+	data = append(data, `{"width":`...)
+	data = strconv.AppendInt(data, int64(width), 10)
+	data = append(data, `,"height":`...)
+	data = strconv.AppendInt(data, int64(height), 10)
+	data = append(data, `,"format":"jpeg","data":"`...)
+	data = base64.StdEncoding.AppendEncode(data, jpegData)
+	data = append(data, '"')
+	if frameID != 0 {
+		data = append(data, `,"frame_id":`...)
+		data = strconv.AppendUint(data, frameID, 10)
+	}
+	if capturedAt != 0 {
+		data = append(data, `,"captured_at":`...)
+		data = strconv.AppendInt(data, capturedAt, 10)
+	}
+	if cloudCapturedAt != 0 {
+		data = append(data, `,"cloud_captured_at":`...)
+		data = strconv.AppendInt(data, cloudCapturedAt, 10)
+	}
+	data = append(data, '}')
+
+	return &Message{
+		Type:      TypeFrame,
+		Timestamp: time.Now().UnixMilli(),
+		Data:      data,
+	}, nil
 }
 
 // DOAData contains direction of arrival information
@@ -105,11 +161,18 @@ type DOAData struct {
 	SpeakingLatched bool    `json:"speaking_latched"`
 	Confidence      float64 `json:"confidence"`
 
+	// Smoothed angle corrected for the robot's body yaw (smoothed_angle when the yaw is unknown)
+	WorldAngle float64 `json:"world_angle"`
+	BodyYaw    float64 `json:"body_yaw,omitempty"`
+
 	// Enhanced 3D positioning data (from XVF3800 speech energy)
 	EstX        float64    `json:"est_x,omitempty"`        // Estimated forward distance (meters)
 	EstY        float64    `json:"est_y,omitempty"`        // Estimated lateral position (meters, + = left)
 	TotalEnergy float64    `json:"total_energy,omitempty"` // Total speech energy (higher = closer)
 	MicEnergy   [4]float64 `json:"mic_energy,omitempty"`   // Per-mic speech energy
+
+	CapturedAt      int64 `json:"captured_at,omitempty"`       // When the reading was captured (unix ms); echo it as a motor command's source_ts
+	CloudCapturedAt int64 `json:"cloud_captured_at,omitempty"` // CapturedAt on the cloud's clock (0 = not synced)
 }
 
 // NewDOAMessage creates a DOA message (legacy, for backwards compatibility)
@@ -123,6 +186,69 @@ func NewDOAMessage(angle, smoothedAngle float64, speaking, speakingLatched bool,
 	})
 }
 
+// DOABatchData carries the DOA readings of one batch interval. Capture times
+// are delta-encoded: CapturedAt is the first reading's, and Deltas[i] is
+// reading i's capture time minus reading i-1's (Deltas[0] is 0). The
+// readings leave captured_at and cloud_captured_at out.
+type DOABatchData struct {
+	CapturedAt      int64     `json:"captured_at"`                 // Unix ms
+	CloudCapturedAt int64     `json:"cloud_captured_at,omitempty"` // CapturedAt on the cloud's clock (0 = not synced)
+	Deltas          []int64   `json:"dt"`                          // Milliseconds since the previous reading
+	Readings        []DOAData `json:"readings"`
+}
+
+// NewDOABatch delta-encodes readings, which must be in capture order
+func NewDOABatch(readings []DOAData) DOABatchData {
+	batch := DOABatchData{
+		Deltas:   make([]int64, len(readings)),
+		Readings: make([]DOAData, len(readings)),
+	}
+	if len(readings) == 0 {
+		return batch
+	}
+	batch.CapturedAt = readings[0].CapturedAt
+	batch.CloudCapturedAt = readings[0].CloudCapturedAt
+	for i, r := range readings {
+		if i > 0 {
+			batch.Deltas[i] = r.CapturedAt - readings[i-1].CapturedAt
+		}
+		r.CapturedAt, r.CloudCapturedAt = 0, 0
+		batch.Readings[i] = r
+	}
+	return batch
+}
+
+// NewDOABatchMessage creates a DOA batch message
+func NewDOABatchMessage(readings []DOAData) (*Message, error) {
+	return NewMessage(TypeDOABatch, NewDOABatch(readings))
+}
+
+// Expand returns the readings with their capture times restored
+func (b DOABatchData) Expand() []DOAData {
+	readings := make([]DOAData, len(b.Readings))
+	at := b.CapturedAt
+	for i, r := range b.Readings {
+		if i < len(b.Deltas) {
+			at += b.Deltas[i]
+		}
+		r.CapturedAt = at
+		if b.CloudCapturedAt != 0 {
+			r.CloudCapturedAt = b.CloudCapturedAt + (at - b.CapturedAt)
+		}
+		readings[i] = r
+	}
+	return readings
+}
+
+// GetDOABatch extracts DOA batch data from a message
+func (m *Message) GetDOABatch() (*DOABatchData, error) {
+	var data DOABatchData
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
 // NewEnhancedDOAMessage creates a DOA message with enhanced 3D positioning data
 func NewEnhancedDOAMessage(angle, smoothedAngle float64, speaking, speakingLatched bool, confidence float64,
 	estX, estY, totalEnergy float64, micEnergy [4]float64) (*Message, error) {
@@ -139,11 +265,128 @@ func NewEnhancedDOAMessage(angle, smoothedAngle float64, speaking, speakingLatch
 	})
 }
 
+// TranscriptData contains a locally recognized utterance
+type TranscriptData struct {
+	Text       string `json:"text"`
+	StartMs    int64  `json:"start_ms"`    // Utterance start (unix ms)
+	EndMs      int64  `json:"end_ms"`      // Utterance end (unix ms)
+	DurationMs int64  `json:"duration_ms"` // Utterance length
+	LatencyMs  int64  `json:"latency_ms"`  // Recognition time after utterance end
+}
+
+// NewTranscriptMessage creates a transcript message
+func NewTranscriptMessage(data TranscriptData) (*Message, error) {
+	return NewMessage(TypeTranscript, data)
+}
+
+// UtteranceData marks the start or end of a detected utterance
+type UtteranceData struct {
+	Event      string  `json:"event"` // "start" or "end"
+	ID         uint64  `json:"id"`
+	StartMs    int64   `json:"start_ms"`         // Utterance start (unix ms)
+	EndMs      int64   `json:"end_ms,omitempty"` // Last speech (unix ms, end events only)
+	DurationMs int64   `json:"duration_ms"`
+	AvgAngle   float64 `json:"avg_angle"` // Radians
+	PeakEnergy float64 `json:"peak_energy"`
+	SpeakerID  int     `json:"speaker_id,omitempty"` // Same ID for turns from the same spot
+}
+
+// NewUtteranceMessage creates an utterance message
+func NewUtteranceMessage(data UtteranceData) (*Message, error) {
+	return NewMessage(TypeUtterance, data)
+}
+
+// BargeInData reports someone talking over the robot's own playback
+type BargeInData struct {
+	Angle       float64 `json:"angle"`       // Radians
+	Energy      float64 `json:"energy"`      // Speech energy of the barge-in
+	EchoLevel   float64 `json:"echo_level"`  // Learned energy of the playback echo
+	Timestamp   int64   `json:"timestamp"`   // Unix ms
+	Interrupted bool    `json:"interrupted"` // Playback was stopped locally
+	Flushed     int     `json:"flushed"`     // Queued clips dropped
+}
+
+// NewBargeInMessage creates a barge-in message
+func NewBargeInMessage(data BargeInData) (*Message, error) {
+	return NewMessage(TypeBargeIn, data)
+}
+
+// WakeCandidateData reports a sudden rise in mic energy that may be the
+// start of the wake word. Unless the robot streams its mic anyway,
+// PreRollMs of audio from before the onset and StreamMs after it follow
+// as mic messages.
+type WakeCandidateData struct {
+	ID         uint64   `json:"id"`
+	CapturedAt int64    `json:"captured_at"` // Onset (unix ms)
+	LevelDBFS  float64  `json:"level_dbfs"`
+	FloorDBFS  float64  `json:"floor_dbfs"`  // Background level before the onset
+	PreRollMs  int64    `json:"pre_roll_ms"` // 0 when the mic is streamed anyway
+	StreamMs   int64    `json:"stream_ms"`   // 0 when the mic is streamed anyway
+	Beam       *MicBeam `json:"beam,omitempty"`
+}
+
+// NewWakeCandidateMessage creates a wake candidate message
+func NewWakeCandidateMessage(data WakeCandidateData) (*Message, error) {
+	return NewMessage(TypeWakeCandidate, data)
+}
+
+// EmotionEventData reports an emotion animation starting or finishing, so
+// the cloud can time speech around it
+type EmotionEventData struct {
+	ID         string `json:"id,omitempty"` // Envelope ID of the emotion command that queued it
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	StartMs    int64  `json:"start_ms"`        // Unix ms
+	EndMs      int64  `json:"end_ms"`          // Unix ms; expected end for emotion_started
+	Idle       bool   `json:"idle,omitempty"`  // Played as an idle behavior
+	Error      string `json:"error,omitempty"` // emotion_finished only: it never played
+}
+
+// NewEmotionEventMessage creates an emotion_started or emotion_finished message
+func NewEmotionEventMessage(msgType MessageType, data EmotionEventData) (*Message, error) {
+	return NewMessage(msgType, data)
+}
+
+// StateData is a periodic snapshot of robot health and statistics
+type StateData struct {
+	Version       string                 `json:"version"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	System        SystemState            `json:"system"`
+	Components    map[string]interface{} `json:"components"` // Per-subsystem stats (tracker, pollen, usb, camera, cloud, ...)
+	Pose          *PoseState             `json:"pose,omitempty"`
+}
+
+// PoseState is the measured robot pose
+type PoseState struct {
+	Head     HeadTarget `json:"head"`
+	Antennas [2]float64 `json:"antennas"`
+	BodyYaw  float64    `json:"body_yaw"`
+	AgeMs    int64      `json:"age_ms"` // Time since the pose was read from Pollen
+}
+
+// SystemState describes host resource usage
+type SystemState struct {
+	CPUPercent        float64 `json:"cpu_percent"` // Host CPU busy since the previous snapshot
+	MemTotalBytes     uint64  `json:"mem_total_bytes"`
+	MemAvailableBytes uint64  `json:"mem_available_bytes"`
+	HeapAllocBytes    uint64  `json:"heap_alloc_bytes"` // go-eva Go heap
+	Goroutines        int     `json:"goroutines"`
+}
+
+// NewStateMessage creates a robot state message
+func NewStateMessage(data StateData) (*Message, error) {
+	return NewMessage(TypeState, data)
+}
+
 // MotorCommand contains motor movement instructions
 type MotorCommand struct {
 	Head     HeadTarget `json:"head"`
 	Antennas [2]float64 `json:"antennas"`
 	BodyYaw  float64    `json:"body_yaw"`
+	SourceTS int64      `json:"source_ts,omitempty"` // captured_at of the DOA data the command reacts to (unix ms), for latency measurement
+
+	ID      string `json:"-"` // Envelope ID to acknowledge (empty = no ack requested)
+	TraceID string `json:"-"` // Envelope trace ID
 }
 
 // HeadTarget specifies head position
@@ -162,6 +405,8 @@ func (m *Message) GetMotorCommand() (*MotorCommand, error) {
 	if err := m.ParseData(&data); err != nil {
 		return nil, err
 	}
+	data.ID = m.ID
+	data.TraceID = m.TraceID
 	return &data, nil
 }
 
@@ -169,6 +414,9 @@ func (m *Message) GetMotorCommand() (*MotorCommand, error) {
 type EmotionCommand struct {
 	Name     string  `json:"name"`
 	Duration float64 `json:"duration,omitempty"`
+
+	ID      string `json:"-"` // Envelope ID to acknowledge (empty = no ack requested)
+	TraceID string `json:"-"` // Envelope trace ID
 }
 
 // GetEmotionCommand extracts emotion command from a message
@@ -177,15 +425,102 @@ func (m *Message) GetEmotionCommand() (*EmotionCommand, error) {
 	if err := m.ParseData(&data); err != nil {
 		return nil, err
 	}
+	data.ID = m.ID
+	data.TraceID = m.TraceID
 	return &data, nil
 }
 
-// SpeakData contains TTS audio to play
+// ChoreoCommand plays a keyframed head, antenna and body sequence,
+// interpolated on the robot (see internal/choreo)
+type ChoreoCommand struct {
+	Name      string           `json:"name,omitempty"`
+	Loops     int              `json:"loops,omitempty"` // Times to play (0 = once)
+	Keyframes []ChoreoKeyframe `json:"keyframes"`
+
+	ID      string `json:"-"` // Envelope ID to acknowledge (empty = no ack requested)
+	TraceID string `json:"-"` // Envelope trace ID
+}
+
+// ChoreoKeyframe is a pose reached DurationMs after the previous keyframe
+type ChoreoKeyframe struct {
+	DurationMs int        `json:"duration_ms"`
+	Easing     string     `json:"easing,omitempty"` // linear, ease_in, ease_out, ease_in_out (default) or step
+	Head       HeadTarget `json:"head"`
+	Antennas   [2]float64 `json:"antennas"`
+	BodyYaw    float64    `json:"body_yaw"`
+}
+
+// GetChoreoCommand extracts a choreography command from a message
+func (m *Message) GetChoreoCommand() (*ChoreoCommand, error) {
+	var data ChoreoCommand
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	data.ID = m.ID
+	data.TraceID = m.TraceID
+	return &data, nil
+}
+
+// PrivacyCommand turns global privacy mode on or off
+type PrivacyCommand struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetPrivacyCommand extracts privacy command from a message
+func (m *Message) GetPrivacyCommand() (*PrivacyCommand, error) {
+	var data PrivacyCommand
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// StopSpeakCommand stops the clip playing and, unless KeepQueue is set,
+// drops everything queued behind it
+type StopSpeakCommand struct {
+	KeepQueue bool `json:"keep_queue,omitempty"`
+}
+
+// GetStopSpeakCommand extracts a stop-speak command from a message
+func (m *Message) GetStopSpeakCommand() (*StopSpeakCommand, error) {
+	var data StopSpeakCommand
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SpeakData contains TTS audio to play.
+// If Text is set and Data is empty, the robot synthesizes the speech locally.
+// Robots with CapabilitySpeakCache keep Data sent with Cache set, and play it
+// again for a message carrying only its CacheKey (the other fields are sent
+// as before).
 type SpeakData struct {
-	Format     string `json:"format"`
-	SampleRate int    `json:"sample_rate"`
+	ID         string `json:"-"`           // Envelope ID to acknowledge (empty = no ack requested)
+	TraceID    string `json:"-"`           // Envelope trace ID
+	Format     string `json:"format"`      // "pcm16" (default), "wav" or "mp3"; empty sniffs the data
+	SampleRate int    `json:"sample_rate"` // Raw pcm16 only; containers carry their own
 	Channels   int    `json:"channels"`
 	Data       string `json:"data"`
+	Text       string `json:"text,omitempty"`
+	Codec      string `json:"codec,omitempty"`     // "pcm16" (default) or "opus"
+	Priority   string `json:"priority,omitempty"`  // "alert", "tts" (default) or "ambient"
+	Cache      bool   `json:"cache,omitempty"`     // Keep Data in the speak cache
+	CacheKey   string `json:"cache_key,omitempty"` // Hex SHA-256 of decoded Data, to play it from the cache
+}
+
+// ErrSpeakNotCached is the ack error for a CacheKey the robot doesn't have;
+// the cloud should resend the data
+var ErrSpeakNotCached = errors.New("speak audio not cached")
+
+// IsText returns true if the robot should synthesize the speech itself
+func (s *SpeakData) IsText() bool {
+	return s.Text != "" && s.Data == "" && s.CacheKey == ""
+}
+
+// IsCacheRef returns true if the audio should come from the speak cache
+func (s *SpeakData) IsCacheRef() bool {
+	return s.CacheKey != "" && s.Data == ""
 }
 
 // GetSpeakData extracts speak data from a message
@@ -202,9 +537,62 @@ func (s *SpeakData) DecodeSpeakData() ([]byte, error) {
 	return base64.StdEncoding.DecodeString(s.Data)
 }
 
+// MicData contains a chunk of captured microphone audio
+type MicData struct {
+	Codec      string `json:"codec"` // "pcm16" or "opus"
+	SampleRate int    `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+	Data       string `json:"data"` // Base64 payload
+	Seq        uint64 `json:"seq"`
+
+	Beam *MicBeam `json:"beam,omitempty"` // Talker direction while captured
+}
+
+// MicBeam tells the cloud where speech came from while a mic chunk was
+// captured, so ASR can skip chunks nobody is speaking in
+type MicBeam struct {
+	Angle       float64 `json:"angle"` // Radians (0=front, +left, -right)
+	Confidence  float64 `json:"confidence"`
+	DominantMic int     `json:"dominant_mic"` // Mic with the most speech energy (-1 = none)
+	Speaking    bool    `json:"speaking"`     // Voice activity
+}
+
+// NewMicMessage creates a mic message from an encoded payload; beam may be nil
+func NewMicMessage(codec string, sampleRate, channels int, payload []byte, seq uint64, beam *MicBeam) (*Message, error) {
+	return NewMessage(TypeMic, MicData{
+		Codec:      codec,
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Data:       base64.StdEncoding.EncodeToString(payload),
+		Seq:        seq,
+		Beam:       beam,
+	})
+}
+
+// GetMicData extracts mic data from a message
+func (m *Message) GetMicData() (*MicData, error) {
+	var data MicData
+	if err := m.ParseData(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DecodeMicData decodes the base64 audio payload
+func (d *MicData) DecodeMicData() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(d.Data)
+}
+
 // ConfigUpdate contains configuration changes
 type ConfigUpdate struct {
 	Camera *CameraConfig `json:"camera,omitempty"`
+	Audio  *AudioConfig  `json:"audio,omitempty"`
+}
+
+// AudioConfig selects the codec for mic audio sent to the cloud; it must be
+// one of the codecs advertised in the hello
+type AudioConfig struct {
+	MicCodec string `json:"mic_codec,omitempty"`
 }
 
 // CameraConfig contains camera settings
@@ -216,6 +604,19 @@ type CameraConfig struct {
 	Preset    string `json:"preset,omitempty"`
 }
 
+// ConfigAppliedData acknowledges a config update. Each section echoes the
+// settings now in effect; Error explains anything that was rejected.
+type ConfigAppliedData struct {
+	Camera *CameraConfig `json:"camera,omitempty"`
+	Audio  *AudioConfig  `json:"audio,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// NewConfigAppliedMessage creates a config acknowledgement message
+func NewConfigAppliedMessage(data ConfigAppliedData) (*Message, error) {
+	return NewMessage(TypeConfigApplied, data)
+}
+
 // GetConfigUpdate extracts config update from a message
 func (m *Message) GetConfigUpdate() (*ConfigUpdate, error) {
 	var data ConfigUpdate
@@ -1,8 +1,13 @@
 package protocol
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewMessage(t *testing.T) {
@@ -82,6 +87,28 @@ func TestNewFrameMessage(t *testing.T) {
 	}
 }
 
+func TestNewTimedFrameMessage_MatchesMarshal(t *testing.T) {
+	jpegData := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 0xFF, 0xD9}
+
+	for _, tt := range []FrameData{
+		{Width: 640, Height: 480},
+		{Width: 640, Height: 480, FrameID: 7, CapturedAt: 1700000000000},
+		{Width: 1920, Height: 1080, FrameID: 42, CapturedAt: 1700000000000, CloudCapturedAt: 1700000000123},
+	} {
+		msg, err := NewTimedFrameMessage(tt.Width, tt.Height, jpegData, tt.FrameID, tt.CapturedAt, tt.CloudCapturedAt)
+		if err != nil {
+			t.Fatalf("NewTimedFrameMessage() error = %v", err)
+		}
+
+		tt.Format = "jpeg"
+		tt.Data = base64.StdEncoding.EncodeToString(jpegData)
+		want, _ := json.Marshal(tt)
+		if string(msg.Data) != string(want) {
+			t.Errorf("Data = %s, want %s", msg.Data, want)
+		}
+	}
+}
+
 func TestNewDOAMessage(t *testing.T) {
 	msg, err := NewDOAMessage(0.5, 0.48, true, true, 0.95)
 	if err != nil {
@@ -93,6 +120,32 @@ func TestNewDOAMessage(t *testing.T) {
 	}
 }
 
+func TestDOABatch(t *testing.T) {
+	readings := []DOAData{
+		{SmoothedAngle: 0.1, CapturedAt: 1000, CloudCapturedAt: 5000},
+		{SmoothedAngle: 0.2, CapturedAt: 1050, CloudCapturedAt: 5050},
+		{SmoothedAngle: 0.3, CapturedAt: 1120, CloudCapturedAt: 5120},
+	}
+
+	msg, err := NewDOABatchMessage(readings)
+	if err != nil {
+		t.Fatalf("NewDOABatchMessage() error = %v", err)
+	}
+	batch, err := msg.GetDOABatch()
+	if err != nil {
+		t.Fatalf("GetDOABatch() error = %v", err)
+	}
+	if batch.Readings[1].CapturedAt != 0 || batch.Readings[1].CloudCapturedAt != 0 {
+		t.Errorf("batched readings should leave their capture times out, got %+v", batch.Readings[1])
+	}
+	if batch.CapturedAt != 1000 || !slices.Equal(batch.Deltas, []int64{0, 50, 70}) {
+		t.Errorf("captured_at = %d, dt = %v, want 1000, [0 50 70]", batch.CapturedAt, batch.Deltas)
+	}
+	if got := batch.Expand(); !slices.Equal(got, readings) {
+		t.Errorf("Expand() = %+v, want %+v", got, readings)
+	}
+}
+
 func TestParseInvalidMessage(t *testing.T) {
 	_, err := ParseMessage([]byte("not json"))
 	if err == nil {
@@ -112,6 +165,240 @@ func TestMessageJSONFormat(t *testing.T) {
 	if parsed["type"] != "ping" {
 		t.Errorf("type = %v, want ping", parsed["type"])
 	}
+	if _, ok := parsed["robot_id"]; ok {
+		t.Error("robot_id should be omitted when empty")
+	}
+
+	msg.RobotID = "eva-01"
+	bytes, _ = msg.Bytes()
+	roundTrip, err := ParseMessage(bytes)
+	if err != nil || roundTrip.RobotID != "eva-01" {
+		t.Errorf("robot_id round trip = %q, %v", roundTrip.RobotID, err)
+	}
+}
+
+
+
+func TestSpeakDataText(t *testing.T) {
+	msg, _ := NewMessage(TypeSpeak, SpeakData{Text: "hello"})
+
+	data, err := msg.GetSpeakData()
+	if err != nil {
+		t.Fatalf("GetSpeakData() error = %v", err)
+	}
+
+	if !data.IsText() {
+		t.Error("speak data with only text should be a text-speak command")
+	}
+
+	data.Data = "AAAA"
+	if data.IsText() {
+		t.Error("speak data with audio payload should not be a text-speak command")
+	}
+}
+
+func TestSpeakDataCacheRef(t *testing.T) {
+	data := SpeakData{Text: "I didn't catch that", CacheKey: "ab12"}
+	if !data.IsCacheRef() || data.IsText() {
+		t.Error("speak data with a cache key and no payload should play from the cache")
+	}
+
+	data.Data = "AAAA"
+	if data.IsCacheRef() {
+		t.Error("speak data with audio payload should not be a cache reference")
+	}
+}
+
+func TestNewTranscriptMessage(t *testing.T) {
+	msg, err := NewTranscriptMessage(TranscriptData{Text: "hello", DurationMs: 800})
+	if err != nil {
+		t.Fatalf("NewTranscriptMessage() error = %v", err)
+	}
+
+	if msg.Type != TypeTranscript {
+		t.Errorf("Type = %v, want %v", msg.Type, TypeTranscript)
+	}
+
+	var data TranscriptData
+	if err := msg.ParseData(&data); err != nil {
+		t.Fatalf("ParseData() error = %v", err)
+	}
+	if data.Text != "hello" || data.DurationMs != 800 {
+		t.Errorf("unexpected transcript data %+v", data)
+	}
+}
+
+func TestNewWakeCandidateMessage(t *testing.T) {
+	msg, err := NewWakeCandidateMessage(WakeCandidateData{ID: 3, CapturedAt: 1700000000000, LevelDBFS: -20, FloorDBFS: -55, PreRollMs: 1000, StreamMs: 2000})
+	if err != nil {
+		t.Fatalf("NewWakeCandidateMessage() error = %v", err)
+	}
+	if msg.Type != TypeWakeCandidate {
+		t.Errorf("Type = %v, want %v", msg.Type, TypeWakeCandidate)
+	}
+
+	var data WakeCandidateData
+	if err := msg.ParseData(&data); err != nil {
+		t.Fatalf("ParseData() error = %v", err)
+	}
+	if data.ID != 3 || data.PreRollMs != 1000 || data.StreamMs != 2000 || data.Beam != nil {
+		t.Errorf("unexpected wake candidate data %+v", data)
+	}
+}
+
+func TestNewMicMessage(t *testing.T) {
+	msg, err := NewMicMessage("opus", 16000, 1, []byte{0, 2, 7, 9}, 3, &MicBeam{Angle: 0.5, DominantMic: 2, Speaking: true})
+	if err != nil {
+		t.Fatalf("NewMicMessage() error = %v", err)
+	}
+	if msg.Type != TypeMic {
+		t.Errorf("Type = %v, want %v", msg.Type, TypeMic)
+	}
+
+	data, err := msg.GetMicData()
+	if err != nil {
+		t.Fatalf("GetMicData() error = %v", err)
+	}
+	payload, err := data.DecodeMicData()
+	if err != nil {
+		t.Fatalf("DecodeMicData() error = %v", err)
+	}
+	if data.Codec != "opus" || data.SampleRate != 16000 || data.Seq != 3 || len(payload) != 4 {
+		t.Errorf("unexpected mic data %+v (payload %v)", data, payload)
+	}
+	if data.Beam == nil || data.Beam.Angle != 0.5 || data.Beam.DominantMic != 2 || !data.Beam.Speaking {
+		t.Errorf("unexpected mic beam %+v", data.Beam)
+	}
+
+	// Without a beam the field is omitted
+	msg, _ = NewMicMessage("pcm16", 16000, 1, []byte{0, 0}, 4, nil)
+	if strings.Contains(string(msg.Data), "beam") {
+		t.Errorf("beam should be omitted: %s", msg.Data)
+	}
+}
+
+func TestGetPrivacyCommand(t *testing.T) {
+	msg, err := NewMessage(TypePrivacy, PrivacyCommand{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+
+	cmd, err := msg.GetPrivacyCommand()
+	if err != nil {
+		t.Fatalf("GetPrivacyCommand() error = %v", err)
+	}
+	if !cmd.Enabled {
+		t.Error("expected privacy enabled")
+	}
+}
+
+func TestHelloSignVerify(t *testing.T) {
+	secret := []byte("shared-secret")
+
+	hello, err := NewHello("eva-01", "1.2.3", []string{"binary_frames"})
+	if err != nil {
+		t.Fatalf("NewHello() error = %v", err)
+	}
+	if hello.Nonce == "" || hello.Timestamp == 0 {
+		t.Fatal("nonce and timestamp should be set")
+	}
+	hello.Sign(secret)
+
+	now := time.UnixMilli(hello.Timestamp)
+	if err := hello.Verify(secret, time.Minute, now); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	if err := hello.Verify([]byte("wrong"), time.Minute, now); err == nil {
+		t.Error("wrong secret should fail")
+	}
+
+	tampered := hello
+	tampered.RobotID = "eva-02"
+	if err := tampered.Verify(secret, time.Minute, now); err == nil {
+		t.Error("tampered robot_id should fail")
+	}
+
+	if err := hello.Verify(secret, time.Minute, now.Add(2*time.Minute)); err == nil {
+		t.Error("stale timestamp should fail")
+	}
+}
+
+func TestCommandID(t *testing.T) {
+	data := []byte(`{"type":"motor","id":"abc123","trace_id":"t-1","data":{"head":{"yaw":0.5}}}`)
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+
+	cmd, err := msg.GetMotorCommand()
+	if err != nil {
+		t.Fatalf("GetMotorCommand() error = %v", err)
+	}
+	if cmd.ID != "abc123" || cmd.TraceID != "t-1" || cmd.Head.Yaw != 0.5 {
+		t.Errorf("unexpected command %+v", cmd)
+	}
+
+	// Commands without an ID don't ask for an ack
+	msg, _ = NewMessage(TypeEmotion, EmotionCommand{Name: "happy"})
+	emotion, err := msg.GetEmotionCommand()
+	if err != nil {
+		t.Fatalf("GetEmotionCommand() error = %v", err)
+	}
+	if emotion.ID != "" {
+		t.Errorf("ID = %q, want empty", emotion.ID)
+	}
+}
+
+func TestNewAckMessage(t *testing.T) {
+	msg, err := NewAckMessage("abc123", nil)
+	if err != nil {
+		t.Fatalf("NewAckMessage() error = %v", err)
+	}
+	if msg.Type != TypeAck {
+		t.Errorf("Type = %v, want %v", msg.Type, TypeAck)
+	}
+	ack, err := msg.GetAckData()
+	if err != nil {
+		t.Fatalf("GetAckData() error = %v", err)
+	}
+	if ack.ID != "abc123" || !ack.OK || ack.Error != "" {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+
+	msg, _ = NewAckMessage("abc123", errors.New("motor stopped"))
+	ack, _ = msg.GetAckData()
+	if ack.OK || ack.Error != "motor stopped" {
+		t.Errorf("unexpected nack %+v", ack)
+	}
+}
+
+func TestNewMessageID(t *testing.T) {
+	a, err := NewMessageID()
+	if err != nil {
+		t.Fatalf("NewMessageID() error = %v", err)
+	}
+	b, _ := NewMessageID()
+	if a == "" || a == b {
+		t.Errorf("IDs should be unique and non-empty, got %q and %q", a, b)
+	}
 }
 
+func TestGetStopSpeakCommand(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"type":"stop_speak"}`))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	cmd, err := msg.GetStopSpeakCommand()
+	if err != nil {
+		t.Fatalf("GetStopSpeakCommand() error = %v", err)
+	}
+	if msg.Type != TypeStopSpeak || cmd.KeepQueue {
+		t.Errorf("bare stop_speak should flush the queue, got %s %+v", msg.Type, cmd)
+	}
 
+	msg, _ = NewMessage(TypeStopSpeak, StopSpeakCommand{KeepQueue: true})
+	if cmd, _ := msg.GetStopSpeakCommand(); !cmd.KeepQueue {
+		t.Error("expected keep_queue")
+	}
+}
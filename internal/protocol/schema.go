@@ -0,0 +1,251 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownFields is returned by strict decoding when the data carries
+// fields the receiving type doesn't define
+var ErrUnknownFields = errors.New("unknown fields")
+
+// Validator is message data that checks its own schema
+type Validator interface {
+	Validate() error
+}
+
+// Decode unmarshals the message data into v and validates it if v is a
+// Validator. It returns the fields v doesn't define (dotted paths, sorted),
+// which usually means the sender's schema changed; in strict mode they are
+// an error.
+func (m *Message) Decode(v interface{}, strict bool) ([]string, error) {
+	if err := m.ParseData(v); err != nil {
+		return nil, err
+	}
+
+	unknown := UnknownFields(m.Data, v)
+	if strict && len(unknown) > 0 {
+		return unknown, fmt.Errorf("%w: %s", ErrUnknownFields, strings.Join(unknown, ", "))
+	}
+	if val, ok := v.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return unknown, err
+		}
+	}
+	return unknown, nil
+}
+
+// UnknownFields lists the JSON object keys in data that v's type doesn't
+// define, descending into nested structs and slices of structs
+func UnknownFields(data json.RawMessage, v interface{}) []string {
+	var unknown []string
+	collectUnknown(data, reflect.TypeOf(v), "", &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+func collectUnknown(data json.RawMessage, t reflect.Type, prefix string, unknown *[]string) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return
+		}
+		known := jsonFields(t)
+		for key, value := range fields {
+			field, ok := lookupField(known, key)
+			if !ok {
+				*unknown = append(*unknown, prefix+key)
+				continue
+			}
+			collectUnknown(value, field.Type, prefix+key+".", unknown)
+		}
+
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return
+		}
+		base := strings.TrimSuffix(prefix, ".")
+		for i, item := range items {
+			collectUnknown(item, t.Elem(), base+"["+strconv.Itoa(i)+"].", unknown)
+		}
+	}
+}
+
+// jsonFields maps a struct's JSON names to its fields, flattening embedded
+// structs the way encoding/json does
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					fields[k] = v
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// lookupField matches a key like encoding/json: exactly, then ignoring case
+func lookupField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if f, ok := fields[key]; ok {
+		return f, true
+	}
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// finite reports whether every value is a real number
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that every target is a finite number
+func (c *MotorCommand) Validate() error {
+	h := c.Head
+	if !finite(h.X, h.Y, h.Z, h.Roll, h.Pitch, h.Yaw, c.Antennas[0], c.Antennas[1], c.BodyYaw) {
+		return errors.New("motor targets must be finite numbers")
+	}
+	if c.SourceTS < 0 {
+		return fmt.Errorf("source_ts must not be negative, got %d", c.SourceTS)
+	}
+	return nil
+}
+
+// Validate checks that an emotion is named and its duration is sane
+func (c *EmotionCommand) Validate() error {
+	if c.Name == "" {
+		return errors.New("emotion name is required")
+	}
+	if !finite(c.Duration) || c.Duration < 0 {
+		return fmt.Errorf("emotion duration must not be negative, got %v", c.Duration)
+	}
+	return nil
+}
+
+// Validate checks that a sequence has keyframes with sane durations and
+// finite targets; easings are checked by the player
+func (c *ChoreoCommand) Validate() error {
+	if len(c.Keyframes) == 0 {
+		return errors.New("choreo needs at least one keyframe")
+	}
+	if c.Loops < 0 {
+		return fmt.Errorf("choreo loops must not be negative, got %d", c.Loops)
+	}
+	for i, k := range c.Keyframes {
+		h := k.Head
+		if !finite(h.X, h.Y, h.Z, h.Roll, h.Pitch, h.Yaw, k.Antennas[0], k.Antennas[1], k.BodyYaw) {
+			return fmt.Errorf("choreo keyframe %d: targets must be finite numbers", i)
+		}
+		if k.DurationMs < 0 {
+			return fmt.Errorf("choreo keyframe %d: duration_ms must not be negative, got %d", i, k.DurationMs)
+		}
+	}
+	return nil
+}
+
+// Validate checks the speak format, codec and priority
+func (s *SpeakData) Validate() error {
+	if s.Text == "" && s.Data == "" && s.CacheKey == "" {
+		return errors.New("speak needs text, data or cache_key")
+	}
+	if s.CacheKey != "" && !isSHA256Hex(s.CacheKey) {
+		return fmt.Errorf("speak cache_key must be a lowercase hex SHA-256, got %q", s.CacheKey)
+	}
+	switch s.Format {
+	case "", "pcm16", "wav", "mp3":
+	default:
+		return fmt.Errorf("unknown speak format %q", s.Format)
+	}
+	switch s.Codec {
+	case "", "pcm16", "opus":
+	default:
+		return fmt.Errorf("unknown speak codec %q", s.Codec)
+	}
+	switch s.Priority {
+	case "", "alert", "tts", "ambient":
+	default:
+		return fmt.Errorf("unknown speak priority %q", s.Priority)
+	}
+	if s.SampleRate < 0 || s.Channels < 0 {
+		return errors.New("speak sample_rate and channels must not be negative")
+	}
+	return nil
+}
+
+// isSHA256Hex reports whether s is a lowercase hex SHA-256 digest
+func isSHA256Hex(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that camera settings are in range
+func (u *ConfigUpdate) Validate() error {
+	if cam := u.Camera; cam != nil {
+		if cam.Width < 0 || cam.Height < 0 || cam.Framerate < 0 {
+			return errors.New("camera width, height and framerate must not be negative")
+		}
+		if cam.Quality < 0 || cam.Quality > 100 {
+			return fmt.Errorf("camera quality must be between 0 and 100, got %d", cam.Quality)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the ack names the message it answers
+func (a *AckData) Validate() error {
+	if a.ID == "" {
+		return errors.New("ack id is required")
+	}
+	return nil
+}
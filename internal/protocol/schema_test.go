@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	data := []byte(`{"head":{"yaw":1,"speed":2},"antennas":[0,0],"GAZE":1,"Body_Yaw":0.5}`)
+	got := UnknownFields(data, &MotorCommand{})
+	if want := []string{"GAZE", "head.speed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownFields() = %v, want %v", got, want)
+	}
+
+	// Slices of structs and optional pointers are checked too
+	cfg := []byte(`{"camera":{"framerate":5,"zoom":2},"audio":{"mic_codec":"opus"}}`)
+	if got := UnknownFields(cfg, &ConfigUpdate{}); !reflect.DeepEqual(got, []string{"camera.zoom"}) {
+		t.Errorf("config UnknownFields() = %v", got)
+	}
+	type list struct {
+		Items []AckData `json:"items"`
+	}
+	if got := UnknownFields([]byte(`{"items":[{"id":"a"},{"id":"b","extra":1}]}`), &list{}); !reflect.DeepEqual(got, []string{"items[1].extra"}) {
+		t.Errorf("slice UnknownFields() = %v", got)
+	}
+}
+
+func TestMessageDecode(t *testing.T) {
+	msg := &Message{Type: TypeEmotion, Data: []byte(`{"name":"happy","intensity":3}`)}
+
+	var cmd EmotionCommand
+	unknown, err := msg.Decode(&cmd, false)
+	if err != nil || cmd.Name != "happy" || len(unknown) != 1 {
+		t.Errorf("Decode() = %v, %v, %+v", unknown, err, cmd)
+	}
+	if _, err := msg.Decode(&cmd, true); !errors.Is(err, ErrUnknownFields) {
+		t.Errorf("strict Decode() error = %v, want ErrUnknownFields", err)
+	}
+
+	msg.Data = []byte(`{"name":""}`)
+	if _, err := msg.Decode(&cmd, false); err == nil {
+		t.Error("Decode() accepted an emotion without a name")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Validator
+		ok   bool
+	}{
+		{"motor", &MotorCommand{Head: HeadTarget{Yaw: 0.3}}, true},
+		{"motor nan", &MotorCommand{Head: HeadTarget{Yaw: math.NaN()}}, false},
+		{"emotion negative duration", &EmotionCommand{Name: "sad", Duration: -1}, false},
+		{"choreo", &ChoreoCommand{Keyframes: []ChoreoKeyframe{{DurationMs: 300, Head: HeadTarget{Pitch: 0.2}}}}, true},
+		{"choreo empty", &ChoreoCommand{Name: "nod"}, false},
+		{"choreo inf", &ChoreoCommand{Keyframes: []ChoreoKeyframe{{DurationMs: 300, BodyYaw: math.Inf(1)}}}, false},
+		{"speak text", &SpeakData{Text: "hi"}, true},
+		{"speak empty", &SpeakData{}, false},
+		{"speak format", &SpeakData{Data: "AAAA", Format: "flac"}, false},
+		{"speak priority", &SpeakData{Text: "hi", Priority: "urgent"}, false},
+		{"speak cache ref", &SpeakData{CacheKey: strings.Repeat("ab", 32)}, true},
+		{"speak bad cache key", &SpeakData{CacheKey: "../clip"}, false},
+		{"config quality", &ConfigUpdate{Camera: &CameraConfig{Quality: 101}}, false},
+		{"config", &ConfigUpdate{Camera: &CameraConfig{Framerate: 5}}, true},
+		{"ack without id", &AckData{OK: true}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.v.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestVersions(t *testing.T) {
+	for v, ok := range map[int]bool{0: true, 1: true, ProtocolVersion: true, ProtocolVersion + 1: false, -1: false} {
+		if err := CheckVersion(v); (err == nil) != ok {
+			t.Errorf("CheckVersion(%d) = %v", v, err)
+		}
+	}
+	if v, err := NegotiateVersion(ProtocolVersion + 5); err != nil || v != ProtocolVersion {
+		t.Errorf("NegotiateVersion(newer) = %d, %v", v, err)
+	}
+	if v, err := NegotiateVersion(0); err != nil || v != 1 {
+		t.Errorf("NegotiateVersion(0) = %d, %v", v, err)
+	}
+	if !TypeMotor.IsCommand() || TypeDOA.IsCommand() {
+		t.Error("IsCommand() misclassifies motor or doa")
+	}
+}
@@ -0,0 +1,54 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Protocol versions. Peers announce the highest version they speak in the
+// hello; the cloud answers with its own hello carrying the version both
+// sides use. Bump ProtocolVersion when a field changes meaning, and raise
+// MinProtocolVersion when the robot can no longer understand an old one.
+const (
+	ProtocolVersion    = 2 // Version this build speaks
+	MinProtocolVersion = 1 // Oldest version this build still understands
+)
+
+// ErrIncompatibleVersion is returned for peers speaking a protocol version
+// this build can't understand
+var ErrIncompatibleVersion = errors.New("incompatible protocol version")
+
+// CheckVersion reports whether a peer speaking version v is understood;
+// 0 is a peer from before versioning, which speaks version 1
+func CheckVersion(v int) error {
+	if v == 0 {
+		v = 1
+	}
+	if v < MinProtocolVersion || v > ProtocolVersion {
+		return fmt.Errorf("%w: peer speaks %d, this build speaks %d-%d", ErrIncompatibleVersion, v, MinProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// NegotiateVersion returns the version to use with a peer that speaks up
+// to peer (0 = before versioning)
+func NegotiateVersion(peer int) (int, error) {
+	if peer == 0 {
+		peer = 1
+	}
+	v := min(peer, ProtocolVersion)
+	if err := CheckVersion(v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// IsCommand reports whether a message type is a cloud command that changes
+// what the robot does, and so is refused from an incompatible cloud
+func (t MessageType) IsCommand() bool {
+	switch t {
+	case TypeMotor, TypeEmotion, TypeChoreo, TypeSpeak, TypeStopSpeak, TypeConfig, TypePrivacy:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+// Package recorder persists every tracker result to a rolling on-disk ring
+// buffer of JSONL segments, for replaying what the robot heard after the fact.
+//
+// Unlike the history store, which downsamples into SQLite for long-term
+// trends, the recorder keeps full-rate results for a bounded window.
+package recorder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Config holds recorder configuration
+type Config struct {
+	Dir             string        // Directory holding segment files
+	SegmentDuration time.Duration // Start a new segment after this long
+	Retention       time.Duration // Delete segments older than this (0 = no age limit)
+	MaxBytes        int64         // Delete oldest segments beyond this total size (0 = no size limit)
+	FlushInterval   time.Duration // How often buffered lines are written
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Dir:             "/var/lib/go-eva/doa",
+		SegmentDuration: 10 * time.Minute,
+		Retention:       48 * time.Hour,
+		MaxBytes:        256 << 20,
+		FlushInterval:   time.Second,
+	}
+}
+
+const (
+	segmentPrefix = "doa-"
+	segmentSuffix = ".jsonl"
+)
+
+// segment is one file in the ring
+type segment struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+// Recorder writes tracker results to rotating JSONL segments
+type Recorder struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	segments []segment // Oldest first; the last one is open for writing
+	file     *os.File
+	writer   *bufio.Writer
+
+	// Stats
+	recorded      atomic.Uint64
+	writeErrors   atomic.Uint64
+	rotations     atomic.Uint64
+	prunedSegment atomic.Uint64
+}
+
+// Open creates the segment directory and indexes existing segments
+func Open(cfg Config, logger *slog.Logger) (*Recorder, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = DefaultConfig().SegmentDuration
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultConfig().FlushInterval
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recorder dir: %w", err)
+	}
+
+	r := &Recorder{
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	segments, err := r.scan()
+	if err != nil {
+		return nil, err
+	}
+	r.segments = segments
+
+	logger.Info("doa recorder opened",
+		"dir", cfg.Dir,
+		"segments", len(segments),
+		"retention", cfg.Retention,
+	)
+
+	return r, nil
+}
+
+// scan lists existing segment files sorted by start time
+func (r *Recorder) scan() ([]segment, error) {
+	entries, err := os.ReadDir(r.cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read recorder dir: %w", err)
+	}
+
+	var segments []segment
+	for _, e := range entries {
+		start, ok := parseSegmentName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{
+			path:  filepath.Join(r.cfg.Dir, e.Name()),
+			start: start,
+			size:  info.Size(),
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start.Before(segments[j].start) })
+	return segments, nil
+}
+
+func segmentName(start time.Time) string {
+	return segmentPrefix + strconv.FormatInt(start.UnixMilli(), 10) + segmentSuffix
+}
+
+func parseSegmentName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+// Run records tracker results until ctx is cancelled or the channel closes
+func (r *Recorder) Run(ctx context.Context, results <-chan doa.Result) {
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			if err := r.Record(result); err != nil {
+				r.logger.Warn("doa record failed", "error", err)
+			}
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				r.logger.Warn("doa recorder flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// Record appends a result, rotating segments as needed
+func (r *Recorder) Record(result doa.Result) error {
+	if result.Timestamp.IsZero() {
+		result.Timestamp = time.Now()
+	}
+
+	line, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal result: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil || result.Timestamp.Sub(r.segments[len(r.segments)-1].start) >= r.cfg.SegmentDuration {
+		if err := r.rotateLocked(result.Timestamp); err != nil {
+			r.writeErrors.Add(1)
+			return err
+		}
+	}
+
+	if _, err := r.writer.Write(line); err != nil {
+		r.writeErrors.Add(1)
+		return fmt.Errorf("write segment: %w", err)
+	}
+	r.segments[len(r.segments)-1].size += int64(len(line))
+	r.recorded.Add(1)
+	return nil
+}
+
+// rotateLocked closes the current segment, opens a new one, and prunes the ring
+func (r *Recorder) rotateLocked(start time.Time) error {
+	if err := r.closeLocked(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.cfg.Dir, segmentName(start))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+
+	r.file = f
+	r.writer = bufio.NewWriter(f)
+	r.segments = append(r.segments, segment{path: path, start: start})
+	r.rotations.Add(1)
+
+	r.pruneLocked(start)
+	return nil
+}
+
+// pruneLocked deletes closed segments past the retention window or size budget
+func (r *Recorder) pruneLocked(now time.Time) {
+	var total int64
+	for _, s := range r.segments {
+		total += s.size
+	}
+
+	// Never delete the open segment (always last)
+	for len(r.segments) > 1 {
+		oldest := r.segments[0]
+		// A segment is expired once the next one started before the cutoff
+		expired := r.cfg.Retention > 0 && r.segments[1].start.Before(now.Add(-r.cfg.Retention))
+		oversize := r.cfg.MaxBytes > 0 && total > r.cfg.MaxBytes
+		if !expired && !oversize {
+			break
+		}
+
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			r.logger.Warn("doa segment prune failed", "path", oldest.path, "error", err)
+			break
+		}
+		total -= oldest.size
+		r.segments = r.segments[1:]
+		r.prunedSegment.Add(1)
+	}
+}
+
+// Flush writes buffered lines to disk
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flushLocked()
+}
+
+func (r *Recorder) flushLocked() error {
+	if r.writer == nil {
+		return nil
+	}
+	if err := r.writer.Flush(); err != nil {
+		r.writeErrors.Add(1)
+		return fmt.Errorf("flush segment: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) closeLocked() error {
+	if r.file == nil {
+		return nil
+	}
+	flushErr := r.flushLocked()
+	closeErr := r.file.Close()
+	r.file = nil
+	r.writer = nil
+	if flushErr != nil {
+		return flushErr
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close segment: %w", closeErr)
+	}
+	return nil
+}
+
+// Query returns recorded results with from <= timestamp <= to, oldest first.
+// limit <= 0 returns everything in range.
+func (r *Recorder) Query(from, to time.Time, limit int) ([]doa.Result, error) {
+	results := []doa.Result{}
+	err := r.Export(from, to, func(res doa.Result) bool {
+		results = append(results, res)
+		return limit <= 0 || len(results) < limit
+	})
+	return results, err
+}
+
+// Export streams recorded results in [from, to] to fn until it returns false
+func (r *Recorder) Export(from, to time.Time, fn func(doa.Result) bool) error {
+	r.mu.Lock()
+	if err := r.flushLocked(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	segments := make([]segment, len(r.segments))
+	copy(segments, r.segments)
+	r.mu.Unlock()
+
+	for i, seg := range segments {
+		if seg.start.After(to) {
+			break
+		}
+		// Segments end where the next one starts
+		if i+1 < len(segments) && segments[i+1].start.Before(from) {
+			continue
+		}
+
+		more, err := readSegment(seg.path, from, to, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+func readSegment(path string, from, to time.Time, fn func(doa.Result) bool) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil // Pruned while we were reading
+		}
+		return false, fmt.Errorf("open segment: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var res doa.Result
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			continue // Tolerate a torn final line after a crash
+		}
+		if res.Timestamp.Before(from) || res.Timestamp.After(to) {
+			continue
+		}
+		if !fn(res) {
+			return false, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read segment: %w", err)
+	}
+	return true, nil
+}
+
+// Stats contains recorder statistics
+type Stats struct {
+	Recorded       uint64    `json:"recorded"`
+	WriteErrors    uint64    `json:"write_errors"`
+	Rotations      uint64    `json:"rotations"`
+	PrunedSegments uint64    `json:"pruned_segments"`
+	Segments       int       `json:"segments"`
+	Bytes          int64     `json:"bytes"`
+	Oldest         time.Time `json:"oldest,omitempty"`
+	Dir            string    `json:"dir"`
+}
+
+// GetStats returns recorder statistics
+func (r *Recorder) GetStats() Stats {
+	r.mu.Lock()
+	var total int64
+	for _, s := range r.segments {
+		total += s.size
+	}
+	var oldest time.Time
+	if len(r.segments) > 0 {
+		oldest = r.segments[0].start
+	}
+	count := len(r.segments)
+	r.mu.Unlock()
+
+	return Stats{
+		Recorded:       r.recorded.Load(),
+		WriteErrors:    r.writeErrors.Load(),
+		Rotations:      r.rotations.Load(),
+		PrunedSegments: r.prunedSegment.Load(),
+		Segments:       count,
+		Bytes:          total,
+		Oldest:         oldest,
+		Dir:            r.cfg.Dir,
+	}
+}
+
+// Close flushes and closes the open segment
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeLocked()
+}
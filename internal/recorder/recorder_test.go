@@ -0,0 +1,151 @@
+package recorder
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func openTestRecorder(t *testing.T, cfg Config) *Recorder {
+	t.Helper()
+
+	cfg.Dir = t.TempDir()
+	r, err := Open(cfg, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func result(ts time.Time, angle float64) doa.Result {
+	return doa.Result{
+		Reading:       doa.Reading{Angle: angle, Timestamp: ts},
+		SmoothedAngle: angle,
+	}
+}
+
+func TestRecorder_RecordAndQuery(t *testing.T) {
+	r := openTestRecorder(t, DefaultConfig())
+
+	base := time.UnixMilli(1_700_000_000_000)
+	for i := 0; i < 20; i++ {
+		if err := r.Record(result(base.Add(time.Duration(i)*100*time.Millisecond), float64(i))); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	got, err := r.Query(base.Add(500*time.Millisecond), base.Add(900*time.Millisecond), 0)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("Query() returned %d results, want 5", len(got))
+	}
+	if got[0].Angle != 5 || got[4].Angle != 9 {
+		t.Errorf("unexpected range: first=%v last=%v", got[0].Angle, got[4].Angle)
+	}
+
+	limited, _ := r.Query(base, base.Add(time.Hour), 3)
+	if len(limited) != 3 {
+		t.Errorf("limit not applied: got %d", len(limited))
+	}
+}
+
+func TestRecorder_RotatesAndQueriesAcrossSegments(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SegmentDuration = time.Second
+	r := openTestRecorder(t, cfg)
+
+	base := time.UnixMilli(1_700_000_000_000)
+	for i := 0; i < 30; i++ {
+		r.Record(result(base.Add(time.Duration(i)*200*time.Millisecond), float64(i)))
+	}
+
+	stats := r.GetStats()
+	if stats.Segments != 6 {
+		t.Errorf("segments = %d, want 6", stats.Segments)
+	}
+
+	got, err := r.Query(base.Add(900*time.Millisecond), base.Add(2100*time.Millisecond), 0)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(got) != 6 {
+		t.Errorf("cross-segment query returned %d results, want 6", len(got))
+	}
+}
+
+func TestRecorder_RetentionPrunesOldSegments(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SegmentDuration = time.Minute
+	cfg.Retention = 5 * time.Minute
+	r := openTestRecorder(t, cfg)
+
+	base := time.UnixMilli(1_700_000_000_000)
+	for i := 0; i < 20; i++ {
+		r.Record(result(base.Add(time.Duration(i)*time.Minute), float64(i)))
+	}
+
+	// The segment straddling the cutoff is kept
+	stats := r.GetStats()
+	if stats.Segments > 7 {
+		t.Errorf("retention not enforced: %d segments", stats.Segments)
+	}
+	if stats.PrunedSegments == 0 {
+		t.Error("expected pruned segments")
+	}
+	if old := stats.Oldest; old.Before(base.Add(13 * time.Minute)) {
+		t.Errorf("oldest segment %v outside retention", old)
+	}
+}
+
+func TestRecorder_MaxBytes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SegmentDuration = time.Second
+	cfg.Retention = 0
+	cfg.MaxBytes = 2048
+	r := openTestRecorder(t, cfg)
+
+	base := time.UnixMilli(1_700_000_000_000)
+	for i := 0; i < 200; i++ {
+		r.Record(result(base.Add(time.Duration(i)*250*time.Millisecond), float64(i)))
+	}
+
+	// The open segment may push slightly past the budget until the next rotation
+	stats := r.GetStats()
+	if stats.Bytes > cfg.MaxBytes*2 {
+		t.Errorf("size budget not enforced: %d bytes", stats.Bytes)
+	}
+}
+
+func TestRecorder_ReopenKeepsSegments(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dir = t.TempDir()
+
+	r, err := Open(cfg, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	base := time.UnixMilli(1_700_000_000_000)
+	r.Record(result(base, 1))
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Stray files are ignored
+	os.WriteFile(cfg.Dir+"/notes.txt", []byte("x"), 0o644)
+
+	r2, err := Open(cfg, nil)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer r2.Close()
+
+	got, _ := r2.Query(base.Add(-time.Second), base.Add(time.Second), 0)
+	if len(got) != 1 {
+		t.Errorf("expected recorded result after reopen, got %d", len(got))
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audit"
+)
+
+// SetAudit attaches the command audit log for /api/audit; local emergency
+// stops and resumes are recorded to it
+func (s *Server) SetAudit(log *audit.Log) {
+	s.audit = log
+}
+
+// auditHandler returns audited commands
+// Query params: since (RFC3339 or unix ms, default 24h ago), limit
+func (s *Server) auditHandler(c *fiber.Ctx) error {
+	if s.audit == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "audit log not enabled",
+		})
+	}
+
+	since, err := parseTimeParam(c.Query("since"), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid since: " + err.Error()})
+	}
+
+	entries, err := s.audit.Query(since, c.QueryInt("limit", 1000))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"since":   since,
+		"count":   len(entries),
+		"entries": entries,
+	})
+}
+
+// auditVerifyHandler checks the audit log's hash chain
+func (s *Server) auditVerifyHandler(c *fiber.Ctx) error {
+	if s.audit == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "audit log not enabled",
+		})
+	}
+
+	res, err := s.audit.Verify()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+// recordAudit records a local motor command, and its payload if cmd is not
+// nil, when the audit log is attached
+func (s *Server) recordAudit(kind audit.Kind, cmd any, cmdErr error) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Record(audit.SourceLocal, kind, "", cmd, cmdErr); err != nil {
+		s.logger.Warn("audit record failed", "kind", kind, "error", err)
+	}
+}
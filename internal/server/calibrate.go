@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/calibration"
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// SetCalibrator attaches the distance calibrator for /api/audio/calibrate
+func (s *Server) SetCalibrator(cal *calibration.Calibrator) {
+	s.calibrator = cal
+}
+
+// calibrateStatusHandler returns the current calibration run and fit
+func (s *Server) calibrateStatusHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	return c.JSON(s.calibrator.Status())
+}
+
+// calibrateStartHandler starts collecting samples at a known distance
+// Body: {"distance_m": 1.0, "duration_s": 10}
+func (s *Server) calibrateStartHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	var req struct {
+		Distance float64 `json:"distance_m"`
+		Duration float64 `json:"duration_s"`
+	}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	duration := time.Duration(req.Duration * float64(time.Second))
+	if err := s.calibrator.Start(req.Distance, duration); err != nil {
+		if errors.Is(err, calibration.ErrBusy) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(202).JSON(s.calibrator.Status())
+}
+
+// calibrateResetHandler discards the calibration and restores the default
+func (s *Server) calibrateResetHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	if err := s.calibrator.Reset(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(s.calibrator.Status())
+}
+
+// mountingResponse describes the active mic array mounting in degrees
+func (s *Server) mountingResponse() fiber.Map {
+	status := s.calibrator.Status()
+	resp := fiber.Map{
+		"offset_deg": status.Mounting.Offset * 180 / math.Pi,
+		"mirror":     status.Mounting.Mirror,
+		"source":     "config",
+		"state":      status.State,
+	}
+	if status.MountingFit != nil {
+		resp["source"] = "set"
+		if status.MountingFit.Samples > 0 {
+			resp["source"] = "measured"
+			resp["bearing_deg"] = status.MountingFit.Bearing * 180 / math.Pi
+			resp["samples"] = status.MountingFit.Samples
+		}
+		resp["updated_at"] = status.MountingFit.At
+	}
+	if status.Kind == calibration.KindMounting {
+		resp["error"] = status.Error
+		resp["remaining_ms"] = status.RemainingMs
+	}
+	return resp
+}
+
+// mountingHandler returns the active mic array mounting
+func (s *Server) mountingHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	return c.JSON(s.mountingResponse())
+}
+
+// mountingSetHandler sets the mounting offset directly
+// Body: {"offset_deg": 15, "mirror": false}
+func (s *Server) mountingSetHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	var req struct {
+		OffsetDeg float64 `json:"offset_deg"`
+		Mirror    bool    `json:"mirror"`
+	}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.OffsetDeg < -180 || req.OffsetDeg > 180 {
+		return c.Status(400).JSON(fiber.Map{"error": "offset_deg must be between -180 and 180"})
+	}
+
+	m := doa.Mounting{Offset: req.OffsetDeg * math.Pi / 180, Mirror: req.Mirror}
+	if err := s.calibrator.SetMounting(m); err != nil {
+		if errors.Is(err, calibration.ErrBusy) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(s.mountingResponse())
+}
+
+// mountingMeasureHandler measures the offset while someone speaks at a
+// known bearing from the head
+// Body: {"bearing_deg": 0, "duration_s": 10}
+func (s *Server) mountingMeasureHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	var req struct {
+		BearingDeg float64 `json:"bearing_deg"`
+		Duration   float64 `json:"duration_s"`
+	}
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	duration := time.Duration(req.Duration * float64(time.Second))
+	if err := s.calibrator.StartMounting(req.BearingDeg*math.Pi/180, duration); err != nil {
+		if errors.Is(err, calibration.ErrBusy) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(202).JSON(s.mountingResponse())
+}
+
+// mountingResetHandler restores the configured mounting
+func (s *Server) mountingResetHandler(c *fiber.Ctx) error {
+	if s.calibrator == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "calibration not available",
+		})
+	}
+
+	if err := s.calibrator.ResetMounting(); err != nil {
+		if errors.Is(err, calibration.ErrBusy) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(s.mountingResponse())
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/camera"
+)
+
+// mjpegBoundary separates parts of the MJPEG stream
+const mjpegBoundary = "frame"
+
+// streamPoll is how often the MJPEG stream checks for a new frame
+const streamPoll = 20 * time.Millisecond
+
+// FrameSource provides the latest camera frame, retained for the caller to
+// Release
+type FrameSource interface {
+	GetLastFrame() *camera.Frame
+}
+
+// SetCamera attaches the camera for /api/camera preview endpoints
+func (s *Server) SetCamera(cam FrameSource) {
+	s.camera = cam
+}
+
+// cameraSnapshotHandler returns the latest frame as a single JPEG
+func (s *Server) cameraSnapshotHandler(c *fiber.Ctx) error {
+	if s.camera == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "camera not enabled",
+		})
+	}
+
+	frame := s.camera.GetLastFrame()
+	if frame == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "no camera frame available yet",
+		})
+	}
+
+	c.Set("Content-Type", "image/jpeg")
+	c.Set("Cache-Control", "no-store")
+	c.Set("X-Frame-Id", fmt.Sprintf("%d", frame.FrameID))
+	// SetBody copies, so the buffer can go back to the pool right away
+	c.Response().SetBody(frame.Data)
+	frame.Release()
+	return nil
+}
+
+// cameraStreamHandler serves the camera as multipart MJPEG, writing each new
+// frame once until the client disconnects or the server shuts down
+func (s *Server) cameraStreamHandler(c *fiber.Ctx) error {
+	if s.camera == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "camera not enabled",
+		})
+	}
+
+	c.Set("Content-Type", "multipart/x-mixed-replace; boundary="+mjpegBoundary)
+	c.Set("Cache-Control", "no-store")
+	c.Set("Connection", "close")
+
+	// The server's write timeout applies to the whole response, so extend
+	// the deadline per frame to keep long-lived streams open
+	cam, done, conn, writeTimeout := s.camera, s.done, c.Context().Conn(), s.cfg.WriteTimeout
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ticker := time.NewTicker(streamPoll)
+		defer ticker.Stop()
+
+		var lastID uint64
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			frame := cam.GetLastFrame()
+			if frame == nil {
+				continue
+			}
+			if frame.FrameID == lastID {
+				frame.Release()
+				continue
+			}
+			lastID = frame.FrameID
+
+			if writeTimeout > 0 {
+				conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			}
+			fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", mjpegBoundary, len(frame.Data))
+			w.Write(frame.Data)
+			frame.Release()
+			w.WriteString("\r\n")
+			if err := w.Flush(); err != nil {
+				return // Client went away
+			}
+		}
+	})
+	return nil
+}
+
+// SetArchive attaches the snapshot archive for /api/camera/archive
+func (s *Server) SetArchive(archive *camera.Archive) {
+	s.archive = archive
+}
+
+// cameraArchiveHandler lists archived snapshots, newest first
+// Query params: from, to (RFC3339 or unix ms, default the last 24h), limit
+func (s *Server) cameraArchiveHandler(c *fiber.Ctx) error {
+	if s.archive == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "camera archive not enabled",
+		})
+	}
+
+	from, err := parseTimeParam(c.Query("from"), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid from: " + err.Error()})
+	}
+	to, err := parseTimeParam(c.Query("to"), time.Now())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid to: " + err.Error()})
+	}
+	if to.Before(from) {
+		return c.Status(400).JSON(fiber.Map{"error": "to must not be before from"})
+	}
+
+	snapshots := s.archive.List(from, to, c.QueryInt("limit", 100))
+	return c.JSON(fiber.Map{
+		"from":      from,
+		"to":        to,
+		"count":     len(snapshots),
+		"snapshots": snapshots,
+		"stats":     s.archive.GetStats(),
+	})
+}
+
+// cameraArchiveFileHandler returns one archived snapshot as JPEG
+func (s *Server) cameraArchiveFileHandler(c *fiber.Ctx) error {
+	if s.archive == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "camera archive not enabled",
+		})
+	}
+
+	data, err := s.archive.Read(c.Params("name"))
+	if errors.Is(err, camera.ErrSnapshotNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set("Content-Type", "image/jpeg")
+	c.Set("Cache-Control", "private, max-age=86400") // Snapshots never change
+	return c.Send(data)
+}
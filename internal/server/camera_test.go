@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/camera"
+)
+
+// fakeCamera serves a settable last frame
+type fakeCamera struct {
+	mu    sync.Mutex
+	frame *camera.Frame
+}
+
+func (f *fakeCamera) GetLastFrame() *camera.Frame {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.frame
+}
+
+func (f *fakeCamera) set(id uint64, data string) {
+	f.mu.Lock()
+	f.frame = &camera.Frame{Data: []byte(data), FrameID: id, Timestamp: time.Now()}
+	f.mu.Unlock()
+}
+
+func TestServer_CameraSnapshot(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/camera/snapshot", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 503 {
+		t.Errorf("without camera: status = %d, want 503", resp.StatusCode)
+	}
+
+	cam := &fakeCamera{}
+	server.SetCamera(cam)
+
+	resp, _ = server.app.Test(httptest.NewRequest("GET", "/api/camera/snapshot", nil), -1)
+	if resp.StatusCode != 503 {
+		t.Errorf("before first frame: status = %d, want 503", resp.StatusCode)
+	}
+
+	cam.set(7, "jpeg-bytes")
+	resp, _ = server.app.Test(httptest.NewRequest("GET", "/api/camera/snapshot", nil), -1)
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "jpeg-bytes" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestServer_CameraStream(t *testing.T) {
+	server, _ := setupTestServer(t)
+	cam := &fakeCamera{}
+	cam.set(1, "first")
+	server.SetCamera(cam)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.app.Listener(ln)
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/api/camera/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/x-mixed-replace" {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	// Parts are read by Content-Length; a multipart.Reader would block on
+	// the boundary that only follows the next frame
+	reader := textproto.NewReader(bufio.NewReader(resp.Body))
+	readPart := func() string {
+		if line, err := reader.ReadLine(); err != nil || line != "--"+params["boundary"] {
+			t.Fatalf("boundary = %q, %v", line, err)
+		}
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			t.Fatalf("part header: %v", err)
+		}
+		if ct := header.Get("Content-Type"); ct != "image/jpeg" {
+			t.Errorf("part Content-Type = %q", ct)
+		}
+		n, _ := strconv.Atoi(header.Get("Content-Length"))
+		data := make([]byte, n)
+		if _, err := io.ReadFull(reader.R, data); err != nil {
+			t.Fatalf("part body: %v", err)
+		}
+		reader.ReadLine()
+		return string(data)
+	}
+
+	if got := readPart(); got != "first" {
+		t.Errorf("first part = %q", got)
+	}
+	cam.set(2, "second")
+	if got := readPart(); got != "second" {
+		t.Errorf("second part = %q", got)
+	}
+
+	// Shutdown ends the stream instead of waiting on it
+	done := make(chan error, 1)
+	go func() { done <- server.Shutdown(t.Context()) }()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown blocked on open stream")
+	}
+}
+
+func TestServer_CameraArchive(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	resp, _ := server.app.Test(httptest.NewRequest("GET", "/api/camera/archive", nil), -1)
+	if resp.StatusCode != 503 {
+		t.Errorf("without archive: status = %d, want 503", resp.StatusCode)
+	}
+
+	cfg := camera.DefaultArchiveConfig()
+	cfg.Dir = t.TempDir()
+	archive, err := camera.OpenArchive(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Save(camera.Frame{Data: []byte("jpeg-bytes"), Timestamp: time.Now()}, "speech"); err != nil {
+		t.Fatal(err)
+	}
+	server.SetArchive(archive)
+
+	resp, _ = server.app.Test(httptest.NewRequest("GET", "/api/camera/archive", nil), -1)
+	var list struct {
+		Count     int                   `json:"count"`
+		Snapshots []camera.ArchiveEntry `json:"snapshots"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if list.Count != 1 || list.Snapshots[0].Reason != "speech" {
+		t.Fatalf("list = %+v, want the speech snapshot", list)
+	}
+
+	resp, _ = server.app.Test(httptest.NewRequest("GET", "/api/camera/archive/"+list.Snapshots[0].Name, nil), -1)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "jpeg-bytes" {
+		t.Errorf("snapshot = %d %q, want the JPEG", resp.StatusCode, body)
+	}
+
+	resp, _ = server.app.Test(httptest.NewRequest("GET", "/api/camera/archive/snap-1-missing.jpg", nil), -1)
+	if resp.StatusCode != 404 {
+		t.Errorf("unknown snapshot: status = %d, want 404", resp.StatusCode)
+	}
+}
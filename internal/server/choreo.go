@@ -0,0 +1,63 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/choreo"
+)
+
+// SetChoreo attaches the choreography player for /api/choreo
+func (s *Server) SetChoreo(player *choreo.Player) {
+	s.choreo = player
+}
+
+// choreoStatusHandler returns the sequence playing and player statistics
+func (s *Server) choreoStatusHandler(c *fiber.Ctx) error {
+	if s.choreo == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "choreography not available",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": s.choreo.Status(),
+		"stats":  s.choreo.GetStats(),
+	})
+}
+
+// choreoPlayHandler starts a keyframed sequence, replacing the one playing.
+// The body is JSON or YAML:
+// {"name": "nod", "loops": 2, "keyframes": [{"duration_ms": 300, "easing": "ease_in_out", "head": {"pitch": 0.3}}]}
+func (s *Server) choreoPlayHandler(c *fiber.Ctx) error {
+	if s.choreo == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "choreography not available",
+		})
+	}
+
+	seq, err := choreo.Parse(c.Body())
+	if err == nil {
+		err = s.choreo.Play(seq)
+	}
+	s.recordAudit(audit.KindChoreo, nil, err)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(202).JSON(s.choreo.Status())
+}
+
+// choreoStopHandler ends the sequence playing, leaving the head where it is
+func (s *Server) choreoStopHandler(c *fiber.Ctx) error {
+	if s.choreo == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "choreography not available",
+		})
+	}
+
+	s.choreo.Stop()
+	return c.JSON(s.choreo.Status())
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/config"
+)
+
+// SetConfigWatcher attaches the live config for GET and PUT /api/config
+func (s *Server) SetConfigWatcher(w *config.Watcher) {
+	s.configWatcher = w
+}
+
+// configUpdateHandler merges a partial config into the live one, validates
+// it and applies the hot-reloadable fields
+// Body: nested config keys, e.g. {"audio": {"ema_alpha": 0.4}}
+func (s *Server) configUpdateHandler(c *fiber.Ctx) error {
+	if s.configWatcher == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "config reload not enabled",
+		})
+	}
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	next, err := config.Merge(s.configWatcher.Current(), patch)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	changes, err := s.configWatcher.Apply(next)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	restartRequired := false
+	for _, ch := range changes {
+		if ch.RestartRequired {
+			restartRequired = true
+		}
+	}
+	if changes == nil {
+		changes = []config.Change{}
+	}
+
+	return c.JSON(fiber.Map{
+		"changes":          changes,
+		"restart_required": restartRequired,
+	})
+}
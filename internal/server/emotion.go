@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/emotion"
+)
+
+// SetEmotions attaches the emotion scheduler for /api/emotions
+func (s *Server) SetEmotions(scheduler *emotion.Scheduler) {
+	s.emotions = scheduler
+}
+
+// emotionsHandler lists the emotion library with current availability
+func (s *Server) emotionsHandler(c *fiber.Ctx) error {
+	if s.emotions == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "emotion scheduler not available",
+		})
+	}
+
+	stats := s.emotions.GetStats()
+	return c.JSON(fiber.Map{
+		"playing":  stats.Playing,
+		"queued":   stats.Queued,
+		"emotions": s.emotions.List(),
+	})
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/cloud"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/gesture"
+	"github.com/teslashibe/go-eva/internal/privacy"
+	"github.com/teslashibe/go-eva/internal/stt"
+)
+
+// ForwardEvents relays bus events to WebSocket clients on the events topic
+// until ctx ends
+func (s *Server) ForwardEvents(ctx context.Context, b *bus.Bus) {
+	forwardEvent(ctx, s.wsHub, b, stt.TopicTranscripts, "transcript")
+	forwardEvent(ctx, s.wsHub, b, gesture.TopicEvents, "gesture_event")
+	forwardEvent(ctx, s.wsHub, b, privacy.TopicChanges, "privacy")
+	forwardEvent(ctx, s.wsHub, b, doa.TopicUtterances, "utterance")
+	forwardEvent(ctx, s.wsHub, b, doa.TopicZones, "zone")
+	forwardEvent(ctx, s.wsHub, b, cloud.TopicConnection, "cloud_connection")
+	bus.Handle(ctx, b, emotion.TopicEvents, 0, func(ev emotion.Event) {
+		s.wsHub.Publish("emotion_"+string(ev.Type), ev)
+	})
+}
+
+// forwardEvent publishes each event on topic to the hub as msgType
+func forwardEvent[T any](ctx context.Context, hub *WSHub, b *bus.Bus, topic bus.Topic[T], msgType string) {
+	bus.Handle(ctx, b, topic, 0, func(v T) { hub.Publish(msgType, v) })
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/store"
+)
+
+// historyQueryHandler returns stored readings, segments, or events
+// Query params: table (readings|segments|events), from, to (RFC3339 or unix ms), limit
+func (s *Server) historyQueryHandler(c *fiber.Ctx) error {
+	if s.history == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "history store not enabled",
+		})
+	}
+
+	q := store.Query{
+		Table: c.Query("table", store.TableReadings),
+		Limit: c.QueryInt("limit", 1000),
+	}
+
+	var err error
+	if q.From, err = parseTimeParam(c.Query("from"), time.Now().Add(-time.Hour)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid from: " + err.Error()})
+	}
+	if q.To, err = parseTimeParam(c.Query("to"), time.Now()); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid to: " + err.Error()})
+	}
+
+	rows, err := s.history.Query(c.Context(), q)
+	if errors.Is(err, store.ErrNotStored) {
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"table": q.Table,
+		"from":  q.From,
+		"to":    q.To,
+		"count": len(rows),
+		"rows":  rows,
+	})
+}
+
+// parseTimeParam accepts RFC3339 timestamps or unix milliseconds
+func parseTimeParam(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/latency"
+)
+
+// SetLatency attaches the latency recorder for /api/latency
+func (s *Server) SetLatency(rec *latency.Recorder) {
+	s.latency = rec
+}
+
+// latencyHandler returns the per-stage reaction latency breakdown
+func (s *Server) latencyHandler(c *fiber.Ctx) error {
+	if s.latency == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "latency measurement not available",
+		})
+	}
+
+	return c.JSON(s.latency.Breakdown())
+}
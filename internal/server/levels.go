@@ -0,0 +1,37 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+)
+
+// SetLevels attaches the mic level meter for /api/audio/levels and the
+// levels WebSocket topic
+func (s *Server) SetLevels(meter *audio.LevelMeter) {
+	s.levels = meter
+	s.wsHub.SetProvider(TopicLevels, func() interface{} {
+		levels, ok := meter.Levels()
+		if !ok {
+			return nil
+		}
+		return levels
+	})
+}
+
+// levelsHandler returns the latest RMS and peak level of each mic channel
+func (s *Server) levelsHandler(c *fiber.Ctx) error {
+	if s.levels == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "audio levels not available",
+		})
+	}
+
+	levels, ok := s.levels.Levels()
+	if !ok {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "mic capture not running",
+		})
+	}
+	return c.JSON(levels)
+}
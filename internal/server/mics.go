@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/micdiag"
+)
+
+// SetMics attaches the mic diagnostics monitor for /api/audio/mics
+func (s *Server) SetMics(m *micdiag.Monitor) {
+	s.mics = m
+}
+
+// micsHandler returns per-channel mic statistics and diagnoses
+func (s *Server) micsHandler(c *fiber.Ctx) error {
+	if s.mics == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "mic diagnostics not available",
+		})
+	}
+
+	return c.JSON(s.mics.Report())
+}
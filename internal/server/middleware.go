@@ -1,12 +1,34 @@
 package server
 
 import (
+	"crypto/subtle"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/teslashibe/go-eva/internal/trace"
 )
 
+// TraceMiddleware gives every request a trace ID: the client's X-Trace-ID
+// or W3C traceparent if it sent a valid one, otherwise a new one. The ID is
+// echoed in the X-Trace-ID response header and carried in the request's
+// user context, so logs and Pollen calls made for the request share it.
+func TraceMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := trace.FromRequest(func(key string) string { return c.Get(key) })
+		if id == "" {
+			id = trace.NewID()
+		}
+		c.Set(trace.Header, id)
+		c.SetUserContext(trace.WithID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
 // LoggingMiddleware logs HTTP requests
 func LoggingMiddleware(logger *slog.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -21,7 +43,7 @@ func LoggingMiddleware(logger *slog.Logger) fiber.Handler {
 			return err
 		}
 
-		logger.Info("http request",
+		logger.InfoContext(c.UserContext(), "http request",
 			"method", c.Method(),
 			"path", path,
 			"status", c.Response().StatusCode(),
@@ -33,3 +55,72 @@ func LoggingMiddleware(logger *slog.Logger) fiber.Handler {
 	}
 }
 
+// MetricsMiddleware counts requests and records latency per route
+func MetricsMiddleware(requests *prometheus.CounterVec, duration *prometheus.HistogramVec) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		// Use the route pattern, not the raw path, to keep label cardinality bounded
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fe, ok := err.(*fiber.Error); ok {
+				status = fe.Code
+			}
+		}
+
+		requests.WithLabelValues(route, c.Method(), strconv.Itoa(status)).Inc()
+		duration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// AuthMiddleware requires one of keys on every request except the exempt
+// paths. The key is read from the X-API-Key header, an "Authorization:
+// Bearer" header or, for browser WebSockets and EventSources that cannot
+// set headers, the api_key query parameter. No keys disables authentication.
+func AuthMiddleware(keys, exempt []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(keys) == 0 || isExempt(c.Path(), exempt) {
+			return c.Next()
+		}
+
+		key := c.Get("X-API-Key")
+		if key == "" {
+			if bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+				key = strings.TrimSpace(bearer)
+			}
+		}
+		if key == "" {
+			key = c.Query("api_key")
+		}
+
+		if key == "" {
+			return c.Status(401).JSON(fiber.Map{"error": "API key required"})
+		}
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+				return c.Next()
+			}
+		}
+		return c.Status(401).JSON(fiber.Map{"error": "invalid API key"})
+	}
+}
+
+// isExempt reports whether path matches an exemption; a trailing * matches
+// any path with that prefix
+func isExempt(path string, exempt []string) bool {
+	for _, e := range exempt {
+		if prefix, ok := strings.CutSuffix(e, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == e {
+			return true
+		}
+	}
+	return false
+}
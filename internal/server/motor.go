@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// MotorCommandFunc carries out a motor command on the same path as cloud
+// motor commands, through the safety limiter's clamps and Pollen's rate limit
+type MotorCommandFunc func(ctx context.Context, cmd protocol.MotorCommand) error
+
+// SetMotor attaches the motor safety limiter for /api/motor
+func (s *Server) SetMotor(limiter *pollen.Limiter) {
+	s.motor = limiter
+}
+
+// SetMotorCommands attaches the local motor command path for
+// POST /api/motor/target and the WebSocket "motor" command. Local commands
+// are audited like emergency stops.
+func (s *Server) SetMotorCommands(fn MotorCommandFunc) {
+	s.motorCmd = func(ctx context.Context, cmd protocol.MotorCommand) error {
+		err := fn(ctx, cmd)
+		s.recordAudit(audit.KindMotor, cmd, err)
+		return err
+	}
+	s.wsHub.SetMotorCommands(s.motorCmd)
+}
+
+// SetPose attaches the joint state poller for /api/motor/state
+func (s *Server) SetPose(poller *pollen.PosePoller) {
+	s.pose = poller
+}
+
+// motorStateHandler returns the measured pose and joint limits
+func (s *Server) motorStateHandler(c *fiber.Ctx) error {
+	if s.pose == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "pose polling not available",
+		})
+	}
+
+	state, fresh := s.pose.Latest()
+	if state.Timestamp.IsZero() {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "no pose read from pollen yet",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"state":  state,
+		"fresh":  fresh,
+		"age_ms": time.Since(state.Timestamp).Milliseconds(),
+		"limits": s.pose.Limits(),
+	})
+}
+
+// motorStatusHandler returns the limiter state and current head pose
+func (s *Server) motorStatusHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "motor control not available",
+		})
+	}
+
+	return c.JSON(s.motor.Status())
+}
+
+// motorStopHandler engages the emergency stop
+func (s *Server) motorStopHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "motor control not available",
+		})
+	}
+
+	err := s.motor.Stop(c.UserContext())
+	s.recordAudit(audit.KindStop, nil, err)
+	if err != nil {
+		// The latch is set either way; only the hold command failed
+		s.logger.ErrorContext(c.UserContext(), "motor stop hold command failed", "error", err)
+		return c.Status(502).JSON(fiber.Map{
+			"error":   "stop engaged but hold command failed: " + err.Error(),
+			"stopped": true,
+		})
+	}
+
+	return c.JSON(s.motor.Status())
+}
+
+// motorResumeHandler releases the emergency stop
+func (s *Server) motorResumeHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "motor control not available",
+		})
+	}
+
+	s.motor.Resume()
+	s.recordAudit(audit.KindResume, nil, nil)
+	return c.JSON(s.motor.Status())
+}
+
+// motorTargetHandler moves the head to a target, as a cloud motor command
+// would. Body: {"head": {"yaw": 0.3, "pitch": -0.1}, "antennas": [0, 0], "body_yaw": 0}
+// (radians; x/y/z in meters). The limiter clamps the target to the joint
+// limits and moves there at its velocity limits.
+func (s *Server) motorTargetHandler(c *fiber.Ctx) error {
+	if s.motorCmd == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "motor control not available",
+		})
+	}
+
+	var cmd protocol.MotorCommand
+	if err := c.BodyParser(&cmd); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "invalid request body: " + err.Error(),
+		})
+	}
+	if err := cmd.Validate(); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := s.motorCmd(c.UserContext(), cmd); err != nil {
+		status := 502
+		if errors.Is(err, pollen.ErrStopped) {
+			status = 409 // Emergency stop engaged
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if s.motor == nil {
+		return c.SendStatus(202)
+	}
+	return c.Status(202).JSON(s.motor.Status())
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// apiRoute describes a versioned endpoint; the same table registers the
+// handler and generates its OpenAPI operation
+type apiRoute struct {
+	Method   string
+	Path     string // Relative to the version prefix
+	Summary  string
+	Tag      string
+	Query    []apiParam
+	Request  any // Zero value of the JSON body type (nil = no body)
+	Response any // Zero value of the success response type
+	Status   int // Success status (0 = 200)
+	Handler  fiber.Handler
+}
+
+// apiParam describes a query parameter
+type apiParam struct {
+	Name        string
+	Type        string // OpenAPI scalar type
+	Description string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPIDoc builds an OpenAPI 3 document for routes mounted under prefix
+func openAPIDoc(version, prefix string, routes []apiRoute) map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]map[string]any{}
+	errorRef := schemaFor(reflect.TypeOf(ErrorResponse{}), schemas)
+
+	for _, r := range routes {
+		status := r.Status
+		if status == 0 {
+			status = fiber.StatusOK
+		}
+
+		op := map[string]any{
+			"summary":     r.Summary,
+			"operationId": operationID(r.Method, r.Path),
+			"responses": map[string]any{
+				strconv.Itoa(status): map[string]any{
+					"description": "Success",
+					"content":     jsonContent(schemaFor(reflect.TypeOf(r.Response), schemas)),
+				},
+				"default": map[string]any{
+					"description": "Error",
+					"content":     jsonContent(errorRef),
+				},
+			},
+		}
+		if r.Tag != "" {
+			op["tags"] = []string{r.Tag}
+		}
+		if len(r.Query) > 0 {
+			params := make([]any, 0, len(r.Query))
+			for _, p := range r.Query {
+				params = append(params, map[string]any{
+					"name":        p.Name,
+					"in":          "query",
+					"description": p.Description,
+					"schema":      map[string]any{"type": p.Type},
+				})
+			}
+			op["parameters"] = params
+		}
+		if r.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaFor(reflect.TypeOf(r.Request), schemas)),
+			}
+		}
+
+		path := prefix + r.Path
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "go-eva API",
+			"version": version,
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+// schemaFor returns the JSON schema for t; named structs are added to
+// schemas and referenced
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Array:
+		return map[string]any{
+			"type":     "array",
+			"items":    schemaFor(t.Elem(), schemas),
+			"minItems": t.Len(),
+			"maxItems": t.Len(),
+		}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]any{} // Placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// structSchema builds an object schema from a struct's JSON fields
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaFor(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// jsonContent wraps a schema as an application/json media type
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// operationID derives an operation ID such as postAudioStop from a route
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
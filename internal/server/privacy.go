@@ -0,0 +1,45 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/privacy"
+)
+
+// SetPrivacy attaches the global privacy switch for /api/privacy
+func (s *Server) SetPrivacy(guard *privacy.Guard) {
+	s.privacy = guard
+}
+
+// privacyStatusHandler returns the current privacy state
+func (s *Server) privacyStatusHandler(c *fiber.Ctx) error {
+	if s.privacy == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "privacy switch not available",
+		})
+	}
+
+	return c.JSON(s.privacy.GetStatus())
+}
+
+// privacySetHandler turns privacy mode on or off
+func (s *Server) privacySetHandler(c *fiber.Ctx) error {
+	if s.privacy == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "privacy switch not available",
+		})
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "request body must be {\"enabled\": true|false}",
+		})
+	}
+
+	s.privacy.Set(*req.Enabled, "api")
+
+	return c.JSON(s.privacy.GetStatus())
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/store"
+)
+
+// Tracker history window limits
+const (
+	defaultHistoryWindow     = 60 * time.Second
+	maxHistoryWindow         = time.Hour
+	defaultHistoryResolution = 100 * time.Millisecond
+)
+
+// doaHistoryHandler returns full-rate tracker results in a time range from
+// the history: the recording when one is attached, else the in-memory window
+// Query params: from, to (RFC3339 or unix ms), limit, format (json|jsonl).
+// With seconds or resolution it returns the results downsampled instead; see
+// trackerHistoryHandler.
+func (s *Server) doaHistoryHandler(c *fiber.Ctx) error {
+	if c.Query("seconds") != "" || c.Query("resolution") != "" {
+		return s.trackerHistoryHandler(c)
+	}
+	if s.history == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "history store not enabled",
+		})
+	}
+
+	from, err := parseTimeParam(c.Query("from"), time.Now().Add(-10*time.Minute))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid from: " + err.Error()})
+	}
+	to, err := parseTimeParam(c.Query("to"), time.Now())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid to: " + err.Error()})
+	}
+	if to.Before(from) {
+		return c.Status(400).JSON(fiber.Map{"error": "to must not be before from"})
+	}
+
+	// JSONL export streams the raw results as a download
+	if c.Query("format") == "jsonl" {
+		history := s.history
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="doa-`+from.UTC().Format("20060102T150405Z")+`.jsonl"`)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			enc := json.NewEncoder(w)
+			err := store.Export(history, from, to, func(res doa.Result) bool {
+				return enc.Encode(res) == nil
+			})
+			if err != nil {
+				s.logger.Warn("doa history export failed", "error", err)
+			}
+			w.Flush()
+		})
+		return nil
+	}
+
+	results, err := s.history.Results(from, to, c.QueryInt("limit", 10000))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"from":    from,
+		"to":      to,
+		"count":   len(results),
+		"results": results,
+	})
+}
+
+// trackerHistoryHandler returns the last seconds of tracker results averaged
+// into resolution buckets, for dashboard trend lines
+// Query params: seconds (default 60), resolution (duration, default 100ms; 0 = every result)
+func (s *Server) trackerHistoryHandler(c *fiber.Ctx) error {
+	if s.history == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "history store not enabled"})
+	}
+
+	window, resolution, err := parseHistoryWindow(c.Query("seconds"), c.Query("resolution"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	history, err := trackerHistory(s.history, window, resolution)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(history)
+}
+
+// trackerHistory builds the history response shared by HTTP and WebSocket
+func trackerHistory(history store.History, window, resolution time.Duration) (fiber.Map, error) {
+	from := time.Now().Add(-window)
+	results, err := history.Results(from, time.Now(), 0)
+	if err != nil {
+		return nil, err
+	}
+	points := doa.Downsample(results, from, resolution)
+	return fiber.Map{
+		"from":          from,
+		"seconds":       window.Seconds(),
+		"resolution_ms": resolution.Milliseconds(),
+		"count":         len(points),
+		"points":        points,
+	}, nil
+}
+
+// parseHistoryWindow parses the seconds and resolution of a tracker history
+// request; empty values take the defaults
+func parseHistoryWindow(seconds, resolution string) (time.Duration, time.Duration, error) {
+	window := defaultHistoryWindow
+	if seconds != "" {
+		v, err := strconv.ParseFloat(seconds, 64)
+		if err != nil || v <= 0 || v > maxHistoryWindow.Seconds() {
+			return 0, 0, fmt.Errorf("seconds must be between 0 and %g", maxHistoryWindow.Seconds())
+		}
+		window = time.Duration(v * float64(time.Second))
+	}
+
+	res := defaultHistoryResolution
+	if resolution != "" {
+		var err error
+		if res, err = time.ParseDuration(resolution); err != nil || res < 0 {
+			return 0, 0, fmt.Errorf("resolution must be a non-negative duration like 100ms")
+		}
+	}
+	return window, res, nil
+}
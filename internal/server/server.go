@@ -3,27 +3,83 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/calibration"
+	"github.com/teslashibe/go-eva/internal/camera"
+	"github.com/teslashibe/go-eva/internal/choreo"
 	"github.com/teslashibe/go-eva/internal/config"
 	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/health"
+	"github.com/teslashibe/go-eva/internal/latency"
+	"github.com/teslashibe/go-eva/internal/micdiag"
+	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/privacy"
+	"github.com/teslashibe/go-eva/internal/state"
+	"github.com/teslashibe/go-eva/internal/store"
+	"github.com/teslashibe/go-eva/internal/trace"
+	"github.com/teslashibe/go-eva/internal/tts"
+	"github.com/teslashibe/go-eva/internal/xvf3800"
 )
 
 // Server is the HTTP server for go-eva
 type Server struct {
-	app       *fiber.App
-	cfg       config.ServerConfig
-	tracker   *doa.Tracker
-	logger    *slog.Logger
-	wsHub     *WSHub
-	startTime time.Time
-	version   string
+	app           *fiber.App
+	cfg           config.ServerConfig
+	tracker       *doa.Tracker
+	logger        *slog.Logger
+	wsHub         *WSHub
+	history       store.History
+	speaker       *tts.Speaker
+	playback      *audio.Bridge
+	privacy       *privacy.Guard
+	xvf           xvf3800.ParamController
+	xvfAllow      *xvf3800.Allowlist
+	xvfProfiles   *xvf3800.Profiles
+	configWatcher *config.Watcher
+	calibrator    *calibration.Calibrator
+	mics          *micdiag.Monitor
+	levels        *audio.LevelMeter
+	camera        FrameSource
+	archive       *camera.Archive
+	motor         *pollen.Limiter
+	motorCmd      MotorCommandFunc
+	pose          *pollen.PosePoller
+	latency       *latency.Recorder
+	audit         *audit.Log
+	emotions      *emotion.Scheduler
+	choreo        *choreo.Player
+	state         *state.Aggregator
+	health        *health.Checker
+	metrics       *prometheus.Registry
+	startTime     time.Time
+	version       string
+	done          chan struct{}
+	closeOnce     sync.Once
+
+	mu       sync.Mutex
+	redirect *http.Server // HTTP to HTTPS redirect, when enabled
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
 }
 
 // New creates a new HTTP server
@@ -39,19 +95,49 @@ func New(cfg config.ServerConfig, tracker *doa.Tracker, logger *slog.Logger, ver
 		WriteTimeout:          cfg.WriteTimeout,
 	})
 
-	// Middleware
-	app.Use(recover.New())
-	app.Use(cors.New())
-	app.Use(LoggingMiddleware(logger))
-
 	s := &Server{
 		app:       app,
 		cfg:       cfg,
 		tracker:   tracker,
 		logger:    logger,
 		wsHub:     NewWSHub(tracker, logger),
+		metrics:   prometheus.NewRegistry(),
 		startTime: time.Now(),
 		version:   version,
+		done:      make(chan struct{}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_http_requests_total",
+			Help: "HTTP requests by route, method and status",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "go_eva_http_request_duration_seconds",
+			Help: "HTTP request latency by route",
+		}, []string{"route"}),
+	}
+
+	// Middleware
+	app.Use(recover.New())
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		// Without CORS headers browsers only allow same-origin requests
+		app.Use(cors.New(cors.Config{
+			AllowOrigins:     strings.Join(cfg.CORS.AllowOrigins, ","),
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+			ExposeHeaders:    trace.Header,
+		}))
+	}
+	app.Use(TraceMiddleware())
+	app.Use(LoggingMiddleware(logger))
+	app.Use(MetricsMiddleware(s.httpRequests, s.httpDuration))
+	app.Use(AuthMiddleware(cfg.Auth.APIKeys, cfg.Auth.Exempt))
+
+	s.wsHub.SetConfig(cfg.WebSocket)
+	s.registerMetrics()
+
+	// Periodic WebSocket topics
+	s.wsHub.SetProvider(TopicHealth, func() interface{} { return s.healthStatus() })
+	if tracker != nil {
+		s.wsHub.SetProvider(TopicStats, func() interface{} { return tracker.Stats() })
 	}
 
 	// Register routes
@@ -66,7 +152,7 @@ func (s *Server) registerRoutes() {
 	s.app.Get("/health", s.healthHandler)
 
 	// Metrics endpoint
-	s.app.Get("/metrics", s.metricsHandler)
+	s.app.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{})))
 
 	// Audio API
 	api := s.app.Group("/api")
@@ -74,37 +160,158 @@ func (s *Server) registerRoutes() {
 	audio := api.Group("/audio")
 	audio.Get("/doa", s.doaHandler)
 	audio.Get("/doa/stream", s.wsHub.UpgradeHandler())
+	audio.Get("/doa/sse", s.doaSSEHandler)
+	audio.Get("/doa/history", s.doaHistoryHandler)
+	audio.Get("/sources", s.sourcesHandler)
+	audio.Get("/speakers", s.speakersHandler)
+	audio.Get("/mics", s.micsHandler)
+	audio.Get("/levels", s.levelsHandler)
+	audio.Get("/calibrate", s.calibrateStatusHandler)
+	audio.Post("/calibrate/start", s.calibrateStartHandler)
+	audio.Delete("/calibrate", s.calibrateResetHandler)
+	audio.Get("/mounting", s.mountingHandler)
+	audio.Put("/mounting", s.mountingSetHandler)
+	audio.Post("/mounting/measure", s.mountingMeasureHandler)
+	audio.Delete("/mounting", s.mountingResetHandler)
+	audio.Post("/stop", s.audioStopHandler)
+
+	// Versioned API with stable response types, described by /api/openapi.json
+	s.registerV1(api)
 
 	// Config endpoint
 	api.Get("/config", s.configHandler)
+	api.Put("/config", s.configUpdateHandler)
 
 	// Stats endpoint
 	api.Get("/stats", s.statsHandler)
+
+	// Robot state snapshot
+	api.Get("/state", s.stateHandler)
+
+	// Reaction latency breakdown
+	api.Get("/latency", s.latencyHandler)
+
+	// History API
+	api.Get("/history/query", s.historyQueryHandler)
+
+	// Local TTS
+	api.Post("/speak", s.speakHandler)
+
+	// Privacy mode
+	api.Get("/privacy", s.privacyStatusHandler)
+	api.Post("/privacy", s.privacySetHandler)
+
+	// XVF3800 parameter control
+	api.Get("/xvf3800/params", s.xvfParamsHandler)
+	api.Post("/xvf3800/param", s.xvfParamHandler)
+	api.Get("/xvf3800/profiles", s.xvfProfilesHandler)
+	api.Post("/xvf3800/profile/:name", s.xvfProfileHandler)
+
+	// Motor safety
+	api.Get("/motor", s.motorStatusHandler)
+	api.Get("/motor/state", s.motorStateHandler)
+	api.Post("/motor/target", s.motorTargetHandler)
+	api.Post("/motor/stop", s.motorStopHandler)
+	api.Post("/motor/resume", s.motorResumeHandler)
+
+	// Command audit log
+	api.Get("/audit", s.auditHandler)
+	api.Get("/audit/verify", s.auditVerifyHandler)
+
+	// Emotion library
+	api.Get("/emotions", s.emotionsHandler)
+
+	// Keyframed motion sequences
+	api.Get("/choreo", s.choreoStatusHandler)
+	api.Post("/choreo/play", s.choreoPlayHandler)
+	api.Post("/choreo/stop", s.choreoStopHandler)
+
+	// Camera preview
+	api.Get("/camera/snapshot", s.cameraSnapshotHandler)
+	api.Get("/camera/stream", s.cameraStreamHandler)
+	api.Get("/camera/archive", s.cameraArchiveHandler)
+	api.Get("/camera/archive/:name", s.cameraArchiveFileHandler)
 }
 
-// healthHandler returns service health
+// SetHistory attaches the history store for /api/history endpoints, and
+// the full-rate results behind DOA history and stream resumption
+func (s *Server) SetHistory(history store.History) {
+	s.history = history
+	s.wsHub.SetHistory(history)
+}
+
+// OnListen sets a callback run once the HTTP server accepts connections
+func (s *Server) OnListen(callback func()) {
+	s.app.Hooks().OnListen(func(fiber.ListenData) error {
+		callback()
+		return nil
+	})
+}
+
+// SetHealth attaches the component health checker for /health
+func (s *Server) SetHealth(checker *health.Checker) {
+	s.health = checker
+}
+
+// healthHandler returns service health, with 503 when a critical component is down
 func (s *Server) healthHandler(c *fiber.Ctx) error {
-	uptime := time.Since(s.startTime)
+	status := s.healthStatus()
+	if status["status"] == health.StatusUnhealthy {
+		return c.Status(503).JSON(status)
+	}
+	return c.JSON(status)
+}
 
-	sourceHealthy := false
-	sourceName := "unknown"
+// healthStatus summarizes service health for /health and the health topic
+func (s *Server) healthStatus() fiber.Map {
+	h := s.healthResponse()
+
+	resp := fiber.Map{
+		"status":         h.Status,
+		"version":        h.Version,
+		"uptime_seconds": h.UptimeSeconds,
+		"doa_source":     h.DOASource,
+		"source_healthy": h.SourceHealthy,
+		"privacy_mode":   h.PrivacyMode,
+	}
 	if s.tracker != nil {
-		stats := s.tracker.Stats()
-		sourceHealthy = stats.SourceHealthy
+		if composite, ok := s.tracker.Source().(*xvf3800.CompositeSource); ok {
+			resp["doa_backends"] = composite.Status()
+		}
+	}
+	if s.health != nil {
+		resp["components"] = s.health.GetStatus().Components
 	}
+	return resp
+}
 
-	status := "ok"
-	if !sourceHealthy {
-		status = "degraded"
+// healthResponse computes service health
+func (s *Server) healthResponse() HealthResponse {
+	resp := HealthResponse{
+		Status:        health.StatusOK,
+		Version:       s.version,
+		UptimeSeconds: int64(time.Since(s.startTime).Seconds()),
+		DOASource:     "unknown",
+		PrivacyMode:   s.privacy != nil && s.privacy.Enabled(),
 	}
 
-	return c.JSON(fiber.Map{
-		"status":         status,
-		"version":        s.version,
-		"uptime_seconds": int64(uptime.Seconds()),
-		"doa_source":     sourceName,
-		"source_healthy": sourceHealthy,
-	})
+	if s.tracker != nil {
+		resp.SourceHealthy = s.tracker.Stats().SourceHealthy
+		resp.DOASource = s.tracker.Source().Name()
+	}
+	if !resp.SourceHealthy {
+		resp.Status = health.StatusDegraded
+	}
+
+	if s.health != nil {
+		checked := s.health.GetStatus()
+		resp.Status = checked.Status
+		resp.Components = make(map[string]ComponentHealth, len(checked.Components))
+		for name, check := range checked.Components {
+			resp.Components[name] = ComponentHealth(check)
+		}
+	}
+	return resp
 }
 
 // doaHandler returns the current DOA reading
@@ -120,8 +327,43 @@ func (s *Server) doaHandler(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
-// configHandler returns current configuration
+// sourcesHandler returns the active per-speaker tracks
+func (s *Server) sourcesHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "DOA tracker not available",
+		})
+	}
+
+	sources := s.tracker.GetSources()
+	return c.JSON(fiber.Map{
+		"count":   len(sources),
+		"sources": sources,
+	})
+}
+
+// speakersHandler returns the speakers remembered across utterances
+func (s *Server) speakersHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "DOA tracker not available",
+		})
+	}
+
+	speakers := s.tracker.GetSpeakers()
+	return c.JSON(fiber.Map{
+		"count":    len(speakers),
+		"speakers": speakers,
+	})
+}
+
+// configHandler returns current configuration: the live config with
+// credentials masked when a config watcher is attached, otherwise the
+// server settings
 func (s *Server) configHandler(c *fiber.Ctx) error {
+	if s.configWatcher != nil {
+		return c.JSON(config.Redact(s.configWatcher.Current()))
+	}
 	return c.JSON(fiber.Map{
 		"server": fiber.Map{
 			"port":             s.cfg.Port,
@@ -142,79 +384,67 @@ func (s *Server) statsHandler(c *fiber.Ctx) error {
 	return c.JSON(s.tracker.Stats())
 }
 
-// metricsHandler returns Prometheus-format metrics
-func (s *Server) metricsHandler(c *fiber.Ctx) error {
-	if s.tracker == nil {
-		return c.Status(503).SendString("# no tracker available\n")
-	}
-
-	stats := s.tracker.Stats()
-
-	metrics := fmt.Sprintf(`# HELP go_eva_doa_angle_radians Current DOA angle in radians
-# TYPE go_eva_doa_angle_radians gauge
-go_eva_doa_angle_radians %f
-
-# HELP go_eva_speaking Speaking state (1=speaking, 0=silent)
-# TYPE go_eva_speaking gauge
-go_eva_speaking %d
-
-# HELP go_eva_doa_confidence DOA confidence score
-# TYPE go_eva_doa_confidence gauge
-go_eva_doa_confidence %f
-
-# HELP go_eva_poll_count Total DOA polls
-# TYPE go_eva_poll_count counter
-go_eva_poll_count %d
-
-# HELP go_eva_poll_errors Total DOA poll errors
-# TYPE go_eva_poll_errors counter
-go_eva_poll_errors %d
-
-# HELP go_eva_avg_latency_ms Average poll latency in milliseconds
-# TYPE go_eva_avg_latency_ms gauge
-go_eva_avg_latency_ms %f
-
-# HELP go_eva_source_healthy DOA source health (1=healthy, 0=unhealthy)
-# TYPE go_eva_source_healthy gauge
-go_eva_source_healthy %d
-
-# HELP go_eva_uptime_seconds Server uptime in seconds
-# TYPE go_eva_uptime_seconds gauge
-go_eva_uptime_seconds %d
-
-# HELP go_eva_websocket_clients Current WebSocket client count
-# TYPE go_eva_websocket_clients gauge
-go_eva_websocket_clients %d
-`,
-		stats.CurrentAngle,
-		boolToInt(stats.SpeakingLatched),
-		stats.CurrentConfidence,
-		stats.PollCount,
-		stats.ErrorCount,
-		stats.AvgLatencyMs,
-		boolToInt(stats.SourceHealthy),
-		int64(time.Since(s.startTime).Seconds()),
-		s.wsHub.ClientCount(),
-	)
-
-	c.Set("Content-Type", "text/plain; charset=utf-8")
-	return c.SendString(metrics)
+// Metrics returns the registry subsystems register their collectors with
+func (s *Server) Metrics() prometheus.Registerer {
+	return s.metrics
 }
 
-func boolToInt(b bool) int {
-	if b {
-		return 1
+// registerMetrics registers server-level collectors
+func (s *Server) registerMetrics() {
+	if s.tracker != nil {
+		s.tracker.RegisterMetrics(s.metrics)
 	}
-	return 0
+
+	s.metrics.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_uptime_seconds",
+			Help: "Server uptime in seconds",
+		}, func() float64 { return float64(int64(time.Since(s.startTime).Seconds())) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_eva_websocket_clients",
+			Help: "Current WebSocket client count",
+		}, func() float64 { return float64(s.wsHub.ClientCount()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_websocket_dropped_messages_total",
+			Help: "WebSocket messages dropped, oldest first, because a client's send queue was full",
+		}, func() float64 { return float64(s.wsHub.GetStats().Dropped) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "go_eva_websocket_evicted_clients_total",
+			Help: "WebSocket clients disconnected for falling behind",
+		}, func() float64 { return float64(s.wsHub.GetStats().Evicted) }),
+		s.httpRequests,
+		s.httpDuration,
+	)
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	s.logger.Info("starting HTTP server",
+	addr := fmt.Sprintf(":%d", s.cfg.Port)
+	if !s.cfg.TLS.Enabled {
+		s.logger.Info("starting HTTP server",
+			"port", s.cfg.Port,
+		)
+		return s.app.Listen(addr)
+	}
+
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	if s.cfg.TLS.RedirectPort > 0 {
+		s.startRedirect(s.cfg.TLS.RedirectPort)
+	}
+
+	s.logger.Info("starting HTTPS server",
 		"port", s.cfg.Port,
 	)
-
-	return s.app.Listen(fmt.Sprintf(":%d", s.cfg.Port))
+	return s.app.Listener(tls.NewListener(ln, tlsCfg))
 }
 
 // WSHub returns the WebSocket hub for external control
@@ -226,20 +456,14 @@ func (s *Server) WSHub() *WSHub {
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down HTTP server")
 
-	// Close WebSocket hub
-	s.wsHub.Close()
-
-	// Shutdown Fiber with timeout from context
-	done := make(chan error, 1)
-	go func() {
-		done <- s.app.Shutdown()
-	}()
-
-	select {
-	case err := <-done:
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
+	// End MJPEG streams, then let WebSocket clients drain their queues and
+	// see a going-away close frame instead of a reset
+	s.closeOnce.Do(func() { close(s.done) })
+	s.wsHub.Shutdown(ctx)
+	if err := s.stopRedirect(ctx); err != nil {
+		s.logger.Warn("https redirect shutdown error", "error", err)
 	}
-}
 
+	// Stop accepting connections and wait for in-flight requests until ctx ends
+	return s.app.ShutdownWithContext(ctx)
+}
@@ -1,15 +1,35 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
+	"math"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/calibration"
+	"github.com/teslashibe/go-eva/internal/choreo"
 	"github.com/teslashibe/go-eva/internal/config"
 	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/health"
+	"github.com/teslashibe/go-eva/internal/latency"
+	"github.com/teslashibe/go-eva/internal/micdiag"
+	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/privacy"
+	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/recorder"
+	"github.com/teslashibe/go-eva/internal/state"
+	"github.com/teslashibe/go-eva/internal/store"
+	"github.com/teslashibe/go-eva/internal/trace"
 	"github.com/teslashibe/go-eva/internal/xvf3800"
 )
 
@@ -70,6 +90,41 @@ func TestServer_Health(t *testing.T) {
 	}
 }
 
+func TestServer_Health_Components(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	checker := health.NewChecker("test")
+	checker.Register("cloud", false, func(ctx context.Context) error { return nil })
+	checker.Register("doa_source", true, func(ctx context.Context) error { return errors.New("no device") })
+	checker.CheckNow(context.Background(), time.Second)
+	server.SetHealth(checker)
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Status     string                  `json:"status"`
+		Components map[string]health.Check `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Status != health.StatusUnhealthy {
+		t.Errorf("expected status unhealthy, got %q", result.Status)
+	}
+	if len(result.Components) != 2 || result.Components["doa_source"].Message != "no device" {
+		t.Errorf("unexpected components %+v", result.Components)
+	}
+}
+
 func TestServer_DOA(t *testing.T) {
 	server, tracker := setupTestServer(t)
 
@@ -108,6 +163,36 @@ func TestServer_DOA(t *testing.T) {
 	tracker.Stop()
 }
 
+func TestServer_Sources(t *testing.T) {
+	server, tracker := setupTestServer(t)
+
+	go func() {
+		tracker.Run(t.Context())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	tracker.Stop()
+
+	req := httptest.NewRequest("GET", "/api/audio/sources", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Count   int                 `json:"count"`
+		Sources []doa.TrackedSource `json:"sources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+
+	// The mock source is speaking at a fixed angle
+	if body.Count != 1 || len(body.Sources) != 1 {
+		t.Errorf("expected 1 source, got %+v", body)
+	}
+}
+
 func TestServer_Stats(t *testing.T) {
 	server, tracker := setupTestServer(t)
 
@@ -179,6 +264,8 @@ func TestServer_Metrics(t *testing.T) {
 		"go_eva_doa_confidence",
 		"go_eva_poll_count",
 		"go_eva_source_healthy",
+		"go_eva_doa_poll_duration_seconds_bucket",
+		"go_eva_http_request_duration_seconds",
 	}
 
 	for _, metric := range expectedMetrics {
@@ -190,6 +277,56 @@ func TestServer_Metrics(t *testing.T) {
 	tracker.Stop()
 }
 
+func TestServer_Metrics_HTTPRequests(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	for i := 0; i < 2; i++ {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/api/config", nil), -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/metrics", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	want := `go_eva_http_requests_total{method="GET",route="/api/config",status="200"} 2`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("expected %q in metrics:\n%s", want, body)
+	}
+}
+
+func TestServer_TraceID(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	// A valid client ID is kept
+	req := httptest.NewRequest("GET", "/api/config", nil)
+	req.Header.Set(trace.Header, "req-42")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if id := resp.Header.Get(trace.Header); id != "req-42" {
+		t.Errorf("%s = %q, want req-42", trace.Header, id)
+	}
+
+	// Otherwise a new one is generated
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/config", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if id := resp.Header.Get(trace.Header); !trace.Valid(id) {
+		t.Errorf("%s = %q, want a generated ID", trace.Header, id)
+	}
+}
+
 func TestServer_Config(t *testing.T) {
 	server, _ := setupTestServer(t)
 
@@ -220,6 +357,163 @@ func TestServer_Config(t *testing.T) {
 	}
 }
 
+func TestServer_ConfigUpdate(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	watcher := config.NewWatcher("", config.Default(), slog.Default())
+	var applied *config.Config
+	watcher.Subscribe(func(_, newCfg *config.Config) { applied = newCfg })
+	server.SetConfigWatcher(watcher)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"hot field", `{"audio": {"ema_alpha": 0.5}, "camera": {"framerate": 5}}`, 200},
+		{"cold field", `{"server": {"port": 9100}}`, 200},
+		{"unknown key", `{"audio": {"bogus": 1}}`, 400},
+		{"invalid value", `{"audio": {"ema_alpha": 2}}`, 422},
+		{"malformed", `{`, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/config", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	if applied == nil || applied.Audio.EMAAlpha != 0.5 || applied.Camera.Framerate != 5 {
+		t.Fatalf("hot fields not applied: %+v", applied)
+	}
+	if watcher.Current().Server.Port != 9000 {
+		t.Errorf("cold field applied: port = %d", watcher.Current().Server.Port)
+	}
+}
+
+func TestServer_ConfigRedacted(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	cfg := config.Default()
+	cfg.Cloud.Auth.Token = "cloud-secret"
+	server.SetConfigWatcher(config.NewWatcher("", cfg, slog.Default()))
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/config", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(body), "cloud-secret") || !strings.Contains(string(body), `"token":"***"`) {
+		t.Errorf("token not masked: %s", body)
+	}
+}
+
+func TestServer_ConfigUpdate_NotEnabled(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("PUT", "/api/config", strings.NewReader(`{}`))
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/latency", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without a recorder, got %d", resp.StatusCode)
+	}
+
+	rec := latency.NewRecorder(latency.DefaultConfig())
+	rec.Observe(latency.StageTrack, 5*time.Millisecond)
+	server.SetLatency(rec)
+
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/latency", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var breakdown latency.Breakdown
+	if err := json.NewDecoder(resp.Body).Decode(&breakdown); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(breakdown.Stages) != 1 || breakdown.Stages[0].Stage != latency.StageTrack || breakdown.Stages[0].LastMs != 5 {
+		t.Errorf("breakdown = %+v", breakdown)
+	}
+}
+
+func TestServer_Calibrate(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	cal, err := calibration.New(calibration.Config{}, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetCalibrator(cal)
+	t.Cleanup(func() { doa.SetMounting(doa.Mounting{}) })
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"status", "GET", "/api/audio/calibrate", "", 200},
+		{"out of range", "POST", "/api/audio/calibrate/start", `{"distance_m": 12}`, 400},
+		{"start", "POST", "/api/audio/calibrate/start", `{"distance_m": 1, "duration_s": 5}`, 202},
+		{"busy", "POST", "/api/audio/calibrate/start", `{"distance_m": 2}`, 409},
+		{"reset", "DELETE", "/api/audio/calibrate", "", 200},
+		{"mounting", "GET", "/api/audio/mounting", "", 200},
+		{"set mounting", "PUT", "/api/audio/mounting", `{"offset_deg": 15}`, 200},
+		{"bad offset", "PUT", "/api/audio/mounting", `{"offset_deg": 270}`, 400},
+		{"measure", "POST", "/api/audio/mounting/measure", `{"bearing_deg": 0, "duration_s": 5}`, 202},
+		{"measure busy", "POST", "/api/audio/mounting/measure", `{"bearing_deg": 30}`, 409},
+		{"set while measuring", "PUT", "/api/audio/mounting", `{"offset_deg": 0}`, 409},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
 func TestServer_DOAStream_UpgradeRequired(t *testing.T) {
 	server, _ := setupTestServer(t)
 
@@ -236,6 +530,556 @@ func TestServer_DOAStream_UpgradeRequired(t *testing.T) {
 	}
 }
 
+func TestServer_DOASSE_BadLastEventID(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/audio/doa/sse", nil)
+	req.Header.Set("Last-Event-ID", "yesterday")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_HistoryQuery_NotEnabled(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/history/query?table=readings", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_DOAHistory(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	rcfg := recorder.DefaultConfig()
+	rcfg.Dir = t.TempDir()
+	rec, err := recorder.Open(rcfg, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to open recorder: %v", err)
+	}
+	history := store.WithRecording(store.NewWindow(store.Config{}), rec, slog.Default())
+	defer history.Close()
+	server.SetHistory(history)
+
+	base := time.UnixMilli(1_700_000_000_000)
+	for i := 0; i < 5; i++ {
+		history.Record(doa.Result{Reading: doa.Reading{Angle: float64(i), Timestamp: base.Add(time.Duration(i) * time.Second)}})
+	}
+
+	req := httptest.NewRequest("GET", "/api/audio/doa/history?from=1700000001000&to=1700000003000", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Count   int          `json:"count"`
+		Results []doa.Result `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Count != 3 || len(body.Results) != 3 {
+		t.Errorf("expected 3 results, got %d", body.Count)
+	}
+}
+
+func TestServer_Audit(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/audit", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without audit log, got %d", resp.StatusCode)
+	}
+
+	acfg := audit.DefaultConfig()
+	acfg.Dir = t.TempDir()
+	log, err := audit.Open(acfg, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer log.Close()
+	server.SetAudit(log)
+
+	pollenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer pollenServer.Close()
+	pollenCfg := pollen.DefaultConfig()
+	pollenCfg.BaseURL = pollenServer.URL
+	server.SetMotor(pollen.NewLimiter(pollen.NewClient(pollenCfg, nil), pollen.DefaultSafetyConfig(), nil))
+
+	log.Record(audit.SourceCloud, audit.KindEmotion, "cmd-1", audit.EmotionCommand{Name: "happy"}, nil)
+	for _, path := range []string{"/api/motor/stop", "/api/v1/motor/resume"} {
+		resp, err := server.app.Test(httptest.NewRequest("POST", path, nil), -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	req = httptest.NewRequest("GET", "/api/audit?since=0", nil)
+	resp, err = server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Count   int           `json:"count"`
+		Entries []audit.Entry `json:"entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Count != 3 {
+		t.Fatalf("expected 3 entries, got %d", body.Count)
+	}
+	if e := body.Entries[1]; e.Source != audit.SourceLocal || e.Kind != audit.KindStop {
+		t.Errorf("expected local stop, got %s/%s", e.Source, e.Kind)
+	}
+	if e := body.Entries[2]; e.Kind != audit.KindResume {
+		t.Errorf("expected resume, got %s", e.Kind)
+	}
+
+	req = httptest.NewRequest("GET", "/api/audit/verify", nil)
+	resp2, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp2.Body.Close()
+	var verify audit.VerifyResult
+	if err := json.NewDecoder(resp2.Body).Decode(&verify); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !verify.OK || verify.Entries != 3 {
+		t.Errorf("expected an intact chain of 3, got %+v", verify)
+	}
+}
+
+func TestServer_DOAHistory_Tracker(t *testing.T) {
+	server, tracker := setupTestServer(t)
+	history := store.NewWindow(store.Config{Window: 1200})
+	server.SetHistory(history)
+	go store.Run(t.Context(), history, store.DefaultConfig(), tracker.Subscribe(), slog.Default())
+	go tracker.Run(t.Context())
+	time.Sleep(100 * time.Millisecond)
+	defer tracker.Stop()
+
+	req := httptest.NewRequest("GET", "/api/audio/doa/history?seconds=60&resolution=50ms", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		ResolutionMs int64              `json:"resolution_ms"`
+		Count        int                `json:"count"`
+		Points       []doa.HistoryPoint `json:"points"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.ResolutionMs != 50 || body.Count == 0 || len(body.Points) != body.Count {
+		t.Errorf("unexpected history: %+v", body)
+	}
+	for _, p := range body.Points {
+		if p.Samples < 1 || p.Samples > 6 {
+			t.Errorf("bucket of 50ms at 10ms polls has %d samples", p.Samples)
+		}
+	}
+
+	for _, query := range []string{"seconds=0", "seconds=abc", "resolution=-1s", "resolution=fast"} {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/api/audio/doa/history?"+query, nil), -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 400 {
+			t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_DOAHistory_NotEnabled(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/audio/doa/history", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_XVFParam(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	allow, err := xvf3800.NewAllowlist([]xvf3800.Param{
+		{Name: "GAIN", ResID: 35, CmdID: 0, Type: xvf3800.ParamFloat, Count: 1, Writable: true},
+		{Name: "DOA", ResID: 20, CmdID: 19, Type: xvf3800.ParamRadians, Count: 2},
+	})
+	if err != nil {
+		t.Fatalf("failed to build allowlist: %v", err)
+	}
+	server.SetXVFControl(xvf3800.NewMockSource(), allow)
+
+	post := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/xvf3800/param", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post(`{"resid":35,"cmdid":0,"type":"float","values":[6.5]}`); code != 200 {
+		t.Errorf("write: expected status 200, got %d", code)
+	}
+	if code := post(`{"resid":20,"cmdid":19,"values":[1,1]}`); code != 403 {
+		t.Errorf("read-only write: expected status 403, got %d", code)
+	}
+	if code := post(`{"resid":1,"cmdid":1}`); code != 403 {
+		t.Errorf("unlisted: expected status 403, got %d", code)
+	}
+	if code := post(`{"resid":35,"cmdid":0,"type":"uint8"}`); code != 400 {
+		t.Errorf("type mismatch: expected status 400, got %d", code)
+	}
+
+	req := httptest.NewRequest("POST", "/api/xvf3800/param", strings.NewReader(`{"resid":35,"cmdid":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Values []float64 `json:"values"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Values) != 1 || body.Values[0] != 6.5 {
+		t.Errorf("expected read-back [6.5], got %v", body.Values)
+	}
+}
+
+func TestServer_XVFProfile(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	gain := xvf3800.Param{Name: "GAIN", ResID: 35, CmdID: 0, Type: xvf3800.ParamFloat, Count: 1, Writable: true}
+	mock := xvf3800.NewMockSource()
+	profiles, err := xvf3800.NewProfiles(mock, []xvf3800.Profile{
+		{Name: "quiet-room", Settings: []xvf3800.ProfileSetting{{Param: gain, Values: []float64{2.5}}}},
+	}, "", nil)
+	if err != nil {
+		t.Fatalf("failed to build profiles: %v", err)
+	}
+
+	post := func(name string) int {
+		resp, err := server.app.Test(httptest.NewRequest("POST", "/api/xvf3800/profile/"+name, nil), -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("quiet-room"); code != 503 {
+		t.Errorf("without profiles: expected status 503, got %d", code)
+	}
+	server.SetXVFProfiles(profiles)
+	if code := post("stadium"); code != 404 {
+		t.Errorf("unknown profile: expected status 404, got %d", code)
+	}
+	if code := post("quiet-room"); code != 200 {
+		t.Errorf("apply: expected status 200, got %d", code)
+	}
+	if values, _ := mock.ReadParam(context.Background(), gain); values[0] != 2.5 {
+		t.Errorf("gain = %v, want 2.5", values)
+	}
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/xvf3800/profiles", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Active   string            `json:"active"`
+		Profiles []xvf3800.Profile `json:"profiles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Active != "quiet-room" || len(body.Profiles) != 1 {
+		t.Errorf("profiles = %+v", body)
+	}
+}
+
+func TestServer_State(t *testing.T) {
+	server, tracker := setupTestServer(t)
+
+	agg := state.NewAggregator(state.DefaultConfig(), "test", nil)
+	agg.AddSource("tracker", func(ctx context.Context) interface{} { return tracker.Stats() })
+	server.SetState(agg)
+
+	req := httptest.NewRequest("GET", "/api/state", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var snap protocol.StateData
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if snap.Version != "test" {
+		t.Errorf("expected version test, got %q", snap.Version)
+	}
+	if _, ok := snap.Components["tracker"]; !ok {
+		t.Error("expected tracker component")
+	}
+}
+
+func TestServer_AudioStop(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/audio/stop", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without playback, got %d", resp.StatusCode)
+	}
+
+	server.SetPlayback(audio.NewBridge(audio.DefaultConfig(), nil))
+	resp, err = server.app.Test(httptest.NewRequest("POST", "/api/audio/stop", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Interrupted bool `json:"interrupted"`
+		Flushed     int  `json:"flushed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != 200 || body.Interrupted || body.Flushed != 0 {
+		t.Errorf("idle stop = %d %+v", resp.StatusCode, body)
+	}
+}
+
+func TestServer_Speak_NotEnabled(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/speak", strings.NewReader(`{"text":"hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Privacy(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.SetPrivacy(privacy.NewGuard(privacy.DefaultConfig(), slog.Default()))
+
+	req := httptest.NewRequest("POST", "/api/privacy", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/health", nil)
+	resp, err = server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var health map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if health["privacy_mode"] != true {
+		t.Errorf("expected privacy_mode true in health, got %v", health["privacy_mode"])
+	}
+}
+
+func TestServer_Privacy_BadRequest(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.SetPrivacy(privacy.NewGuard(privacy.DefaultConfig(), slog.Default()))
+
+	req := httptest.NewRequest("POST", "/api/privacy", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_MotorStop(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/motor/stop", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without limiter, got %d", resp.StatusCode)
+	}
+
+	pollenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer pollenServer.Close()
+
+	pollenCfg := pollen.DefaultConfig()
+	pollenCfg.BaseURL = pollenServer.URL
+	limiter := pollen.NewLimiter(pollen.NewClient(pollenCfg, nil), pollen.DefaultSafetyConfig(), nil)
+	server.SetMotor(limiter)
+
+	req = httptest.NewRequest("POST", "/api/motor/stop", nil)
+	resp, err = server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var status pollen.SafetyStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !status.Stopped {
+		t.Error("expected stopped after POST /api/motor/stop")
+	}
+
+	req = httptest.NewRequest("POST", "/api/motor/resume", nil)
+	resp2, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp2.Body.Close()
+	if limiter.Stopped() {
+		t.Error("expected resume to release the stop")
+	}
+}
+
+func TestServer_MotorTarget(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	body := `{"head": {"yaw": 0.3}, "antennas": [0.1, -0.1]}`
+	resp, _ := server.app.Test(httptest.NewRequest("POST", "/api/motor/target", strings.NewReader(body)), -1)
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without motor commands, got %d", resp.StatusCode)
+	}
+
+	var got []protocol.MotorCommand
+	stopped := false
+	server.SetMotorCommands(func(ctx context.Context, cmd protocol.MotorCommand) error {
+		if stopped {
+			return pollen.ErrStopped
+		}
+		got = append(got, cmd)
+		return nil
+	})
+
+	post := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/motor/target", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post(body); status != 202 {
+		t.Fatalf("expected status 202, got %d", status)
+	}
+	if len(got) != 1 || got[0].Head.Yaw != 0.3 || got[0].Antennas[1] != -0.1 {
+		t.Errorf("expected the target passed through, got %+v", got)
+	}
+
+	if status := post(`{"head": {"yaw": 0.3}, "source_ts": -1}`); status != 400 {
+		t.Errorf("expected status 400 for an invalid command, got %d", status)
+	}
+
+	stopped = true
+	if status := post(body); status != 409 {
+		t.Errorf("expected status 409 while stopped, got %d", status)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -249,3 +1093,250 @@ func containsHelper(s, substr string) bool {
 	return false
 }
 
+
+func TestServer_Emotions(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/emotions", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503 without a scheduler, got %d", resp.StatusCode)
+	}
+
+	scheduler := emotion.New(emotion.DefaultConfig(), nil, nil)
+	scheduler.Play("happy", time.Hour)
+	server.SetEmotions(scheduler)
+
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/emotions", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Queued   []string         `json:"queued"`
+		Emotions []emotion.Status `json:"emotions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Queued) != 1 || body.Queued[0] != "happy" {
+		t.Errorf("queued = %v, want [happy]", body.Queued)
+	}
+	if len(body.Emotions) != len(emotion.DefaultLibrary()) {
+		t.Errorf("listed %d emotions, want %d", len(body.Emotions), len(emotion.DefaultLibrary()))
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	cfg := config.ServerConfig{Port: 9000}
+	cfg.Auth.APIKeys = []string{"k1", "k2"}
+	cfg.Auth.Exempt = []string{"/health", "/api/audio/*"}
+	server := New(cfg, nil, nil, "test")
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		value  string
+		allow  bool
+	}{
+		{"exempt path", "/health", "", "", true},
+		{"exempt prefix", "/api/audio/mounting", "", "", true},
+		{"missing key", "/api/privacy", "", "", false},
+		{"wrong key", "/api/privacy", "X-API-Key", "nope", false},
+		{"header key", "/api/privacy", "X-API-Key", "k2", true},
+		{"bearer key", "/api/privacy", "Authorization", "Bearer k1", true},
+		{"query key", "/api/privacy?api_key=k1", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			resp.Body.Close()
+			// Handlers without their subsystem answer 503; only auth answers 401
+			if allowed := resp.StatusCode != 401; allowed != tt.allow {
+				t.Errorf("status = %d, want allowed = %v", resp.StatusCode, tt.allow)
+			}
+		})
+	}
+}
+
+func TestServer_CORS(t *testing.T) {
+	cfg := config.ServerConfig{Port: 9000}
+	cfg.CORS.AllowOrigins = []string{"http://dashboard.local:3000"}
+	server := New(cfg, nil, nil, "test")
+
+	for origin, want := range map[string]string{
+		"http://dashboard.local:3000": "http://dashboard.local:3000",
+		"http://evil.example":         "",
+	} {
+		req := httptest.NewRequest("GET", "/health", nil)
+		req.Header.Set("Origin", origin)
+		resp, err := server.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("origin %s: Access-Control-Allow-Origin = %q, want %q", origin, got, want)
+		}
+	}
+
+	// No allowlist means no CORS headers at all
+	server = New(config.ServerConfig{Port: 9000}, nil, nil, "test")
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Header.Set("Origin", "http://dashboard.local:3000")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin without an allowlist = %q", got)
+	}
+}
+
+func TestServer_Mics(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/audio/mics", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("without a monitor: status = %d, want 503", resp.StatusCode)
+	}
+
+	server.SetMics(micdiag.New(micdiag.DefaultConfig(), nil))
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/audio/mics", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var rep micdiag.Report
+	if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(rep.Channels) != micdiag.NumMics || !rep.Healthy || rep.Channels[0].Status != micdiag.StatusUnknown {
+		t.Errorf("report = %+v, want %d unknown channels", rep, micdiag.NumMics)
+	}
+}
+
+func TestServer_Levels(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	meter := audio.NewLevelMeter(audio.DefaultLevelWindow)
+	server.SetLevels(meter)
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/audio/levels", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("before capture: status = %d, want 503", resp.StatusCode)
+	}
+
+	// 100ms of mono PCM at a quarter of full scale
+	pcm := make([]byte, 3200)
+	for i := 0; i < len(pcm); i += 2 {
+		pcm[i], pcm[i+1] = 0x00, 0x20
+	}
+	meter.Feed(audio.AudioChunk{Data: pcm, SampleRate: 16000, Channels: 1, Timestamp: time.Now()})
+
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/audio/levels", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var levels audio.Levels
+	if err := json.NewDecoder(resp.Body).Decode(&levels); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(levels.Channels) != 1 || math.Abs(levels.Channels[0].RMS-20*math.Log10(0.25)) > 0.01 {
+		t.Errorf("levels = %+v, want one channel at -12 dBFS", levels)
+	}
+}
+
+func TestServer_Choreo(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.SetChoreo(choreo.New(choreo.DefaultConfig(), choreo.MoverFunc(
+		func(ctx context.Context, head pollen.HeadTarget, antennas [2]float64, bodyYaw float64) error { return nil },
+	), nil))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"play yaml", "POST", "/api/choreo/play", "name: nod\nkeyframes:\n  - duration_ms: 5000\n    head: {pitch: 0.3}\n", 202},
+		{"status", "GET", "/api/choreo", "", 200},
+		{"no keyframes", "POST", "/api/choreo/play", `{"keyframes": []}`, 400},
+		{"bad easing", "POST", "/api/choreo/play", `{"keyframes": [{"duration_ms": 100, "easing": "wobble"}]}`, 400},
+		{"stop", "POST", "/api/choreo/stop", "", 200},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		resp, err := server.app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+	}
+
+	if st := server.choreo.GetStats(); st.Played != 1 || st.Interrupted != 1 {
+		t.Errorf("stats = %+v, want one sequence played and stopped", st)
+	}
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := dir + "/tls/cert.pem"
+	keyFile := dir + "/tls/key.pem"
+
+	created, err := EnsureSelfSigned(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("EnsureSelfSigned: %v", err)
+	}
+	if !created {
+		t.Error("expected a certificate to be generated")
+	}
+	if created, _ := EnsureSelfSigned(certFile, keyFile); created {
+		t.Error("expected the existing certificate to be kept")
+	}
+
+	server, _ := setupTestServer(t)
+	server.cfg.TLS = config.TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile}
+	if _, err := server.tlsConfig(); err != nil {
+		t.Fatalf("tlsConfig: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "http://eva.local:8080/api/doa?x=1", nil)
+	rec := httptest.NewRecorder()
+	redirectHandler(9443).ServeHTTP(rec, req)
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Errorf("expected status 308, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Location"); got != "https://eva.local:9443/api/doa?x=1" {
+		t.Errorf("unexpected redirect location %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/tts"
+)
+
+// SetSpeaker attaches the local TTS speaker for POST /api/speak
+func (s *Server) SetSpeaker(speaker *tts.Speaker) {
+	s.speaker = speaker
+}
+
+// SetPlayback attaches the audio bridge for POST /api/audio/stop
+func (s *Server) SetPlayback(bridge *audio.Bridge) {
+	s.playback = bridge
+}
+
+// audioStopHandler stops speaker playback; ?keep_queue=true only skips the
+// clip that is playing
+func (s *Server) audioStopHandler(c *fiber.Ctx) error {
+	if s.playback == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "audio playback not available",
+		})
+	}
+
+	interrupted, flushed := s.playback.StopPlayback(!c.QueryBool("keep_queue"))
+	return c.JSON(fiber.Map{
+		"interrupted": interrupted,
+		"flushed":     flushed,
+	})
+}
+
+// speakHandler synthesizes text on-robot and plays it in the background
+func (s *Server) speakHandler(c *fiber.Ctx) error {
+	if s.speaker == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "local TTS not enabled",
+		})
+	}
+
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Text == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "request body must be {\"text\": \"...\"}",
+		})
+	}
+
+	s.speaker.SpeakAsync(req.Text)
+
+	return c.Status(202).JSON(fiber.Map{
+		"status": "queued",
+		"chars":  len(req.Text),
+	})
+}
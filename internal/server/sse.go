@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// sseRetry is the reconnect delay suggested to SSE clients
+const sseRetry = time.Second
+
+// sseKeepalive is how often an idle SSE stream sends a comment, so proxies
+// don't time it out
+const sseKeepalive = 15 * time.Second
+
+// doaSSEHandler streams tracker results as Server-Sent Events, for clients
+// that can't open a WebSocket. Each event's id is the result's capture time
+// in unix microseconds; a client reconnecting with Last-Event-ID (or
+// ?last_event_id=) first gets the results it missed from the history.
+func (s *Server) doaSSEHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "DOA tracker not available"})
+	}
+
+	var resume time.Time
+	lastID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	if lastID != "" {
+		us, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid Last-Event-ID: " + lastID})
+		}
+		resume = time.UnixMicro(us)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("X-Accel-Buffering", "no") // Keep nginx from buffering events
+
+	// The server's write timeout applies to the whole response, so extend
+	// the deadline per event as the MJPEG stream does
+	tracker, done, conn, writeTimeout := s.tracker, s.done, c.Context().Conn(), s.cfg.WriteTimeout
+	history, logger := s.history, s.logger
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// Subscribe before reading the history so nothing falls in between
+		results := tracker.Subscribe()
+		defer tracker.Unsubscribe(results)
+
+		var last time.Time
+		send := func(res doa.Result) error {
+			if !res.Timestamp.After(last) {
+				return nil // Already sent from the history
+			}
+			last = res.Timestamp
+			data, err := json.Marshal(res)
+			if err != nil {
+				return nil
+			}
+			if writeTimeout > 0 {
+				conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", res.Timestamp.UnixMicro(), TopicDOA, data)
+			return w.Flush()
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+		if !resume.IsZero() && history != nil {
+			last = resume
+			missed, err := history.Results(resume, time.Now(), 0)
+			if err != nil {
+				logger.Warn("sse resume failed", "error", err)
+			}
+			for _, res := range missed {
+				if send(res) != nil {
+					return
+				}
+			}
+		}
+		if w.Flush() != nil {
+			return
+		}
+
+		keepalive := time.NewTicker(sseKeepalive)
+		defer keepalive.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case res, ok := <-results:
+				if !ok {
+					return // Tracker stopped
+				}
+				if send(res) != nil {
+					return // Client went away
+				}
+			case <-keepalive.C:
+				if writeTimeout > 0 {
+					conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+				}
+				w.WriteString(": keepalive\n\n")
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
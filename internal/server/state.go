@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/state"
+)
+
+// SetState attaches the state aggregator for /api/state
+func (s *Server) SetState(agg *state.Aggregator) {
+	s.state = agg
+}
+
+// stateHandler returns a fresh robot state snapshot
+func (s *Server) stateHandler(c *fiber.Ctx) error {
+	if s.state == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "robot state not available",
+		})
+	}
+
+	return c.JSON(s.state.Snapshot(c.UserContext()))
+}
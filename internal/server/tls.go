@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selfSignedValidity is how long a generated certificate lasts
+const selfSignedValidity = 10 * 365 * 24 * time.Hour
+
+// tlsConfig loads the server certificate, generating a self-signed one
+// first if configured to and none exists
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	cfg := s.cfg.TLS
+	if cfg.SelfSigned {
+		created, err := EnsureSelfSigned(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			s.logger.Info("generated self-signed TLS certificate", "cert", cfg.CertFile)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load tls certificate: %w", err)
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
+
+// EnsureSelfSigned writes a self-signed certificate and key unless
+// certFile already exists. It reports whether it created them.
+func EnsureSelfSigned(certFile, keyFile string) (bool, error) {
+	if _, err := os.Stat(certFile); err == nil {
+		return false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("stat tls certificate: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return false, fmt.Errorf("generate tls key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return false, fmt.Errorf("generate tls serial: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hostname, Organization: []string{"go-eva"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	tmpl.DNSNames, tmpl.IPAddresses = localNames(hostname)
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return false, fmt.Errorf("create tls certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return false, fmt.Errorf("marshal tls key: %w", err)
+	}
+
+	// Key first, so a crash in between never leaves a certificate without one
+	if err := writePEM(keyFile, "EC PRIVATE KEY", keyDER, 0o600); err != nil {
+		return false, err
+	}
+	if err := writePEM(certFile, "CERTIFICATE", der, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// localNames returns the names and addresses the robot is reachable at
+func localNames(hostname string) ([]string, []net.IP) {
+	dns := []string{"localhost"}
+	if hostname != "" && hostname != "localhost" {
+		dns = append(dns, hostname, hostname+".local")
+	}
+
+	ips := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	addrs, _ := net.InterfaceAddrs()
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && !ipnet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return dns, ips
+}
+
+// writePEM writes one PEM block to path, creating its directory
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create tls dir: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// redirectHandler sends plain HTTP requests to the same path over HTTPS
+func redirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(httpsPort))
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// startRedirect serves the HTTP to HTTPS redirect on port
+func (s *Server) startRedirect(port int) {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           redirectHandler(s.cfg.Port),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	s.mu.Lock()
+	s.redirect = srv
+	s.mu.Unlock()
+
+	s.logger.Info("redirecting HTTP to HTTPS", "port", port)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("https redirect server error", "error", err)
+		}
+	}()
+}
+
+// stopRedirect shuts the redirect server down, if running
+func (s *Server) stopRedirect(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.redirect
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,406 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/audit"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/health"
+	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/privacy"
+)
+
+// APIv1Prefix is where the versioned API is mounted. Response shapes under it
+// only change in backward-compatible ways; internal types may change freely.
+const APIv1Prefix = "/api/v1"
+
+// ErrorResponse is returned with every 4xx/5xx status
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// HealthResponse describes service health
+type HealthResponse struct {
+	Status        string                     `json:"status"` // ok, degraded, unhealthy
+	Version       string                     `json:"version"`
+	UptimeSeconds int64                      `json:"uptime_seconds"`
+	DOASource     string                     `json:"doa_source"`
+	SourceHealthy bool                       `json:"source_healthy"`
+	PrivacyMode   bool                       `json:"privacy_mode"`
+	Components    map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth is the latest health check of one component
+type ComponentHealth struct {
+	Healthy   bool      `json:"healthy"`
+	Critical  bool      `json:"critical"`
+	Message   string    `json:"message,omitempty"`
+	LastCheck time.Time `json:"last_check"`
+}
+
+// DOAResponse is the latest direction-of-arrival estimate. Angles are radians
+// (0 = front, + = left).
+type DOAResponse struct {
+	Angle           float64    `json:"angle"`
+	SmoothedAngle   float64    `json:"smoothed_angle"`
+	Confidence      float64    `json:"confidence"`
+	Speaking        bool       `json:"speaking"`
+	SpeakingLatched bool       `json:"speaking_latched"`
+	Zone            string     `json:"zone,omitempty"` // Named sector of the latest speech
+	SpeechEnergy    [4]float64 `json:"speech_energy"`
+	TotalEnergy     float64    `json:"total_energy"`
+	EstX            float64    `json:"est_x"` // Meters forward
+	EstY            float64    `json:"est_y"` // Meters left
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// SourcesResponse lists the speakers being tracked
+type SourcesResponse struct {
+	Count   int             `json:"count"`
+	Sources []SpeakerSource `json:"sources"`
+}
+
+// SpeakerSource is one tracked speaker
+type SpeakerSource struct {
+	ID         int       `json:"id"`
+	Angle      float64   `json:"angle"`
+	Confidence float64   `json:"confidence"`
+	Speaking   bool      `json:"speaking"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastActive time.Time `json:"last_active"`
+}
+
+// StatsResponse summarizes the DOA tracker
+type StatsResponse struct {
+	PollCount     int64   `json:"poll_count"`
+	ErrorCount    int64   `json:"error_count"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`
+	SourceHealthy bool    `json:"source_healthy"`
+	Speaking      bool    `json:"speaking"`
+	InUtterance   bool    `json:"in_utterance"`
+	Angle         float64 `json:"angle"`
+	Confidence    float64 `json:"confidence"`
+}
+
+// PrivacyRequest turns privacy mode on or off
+type PrivacyRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PrivacyResponse is the privacy mode state
+type PrivacyResponse struct {
+	Enabled   bool       `json:"enabled"`
+	Source    string     `json:"source,omitempty"` // What last changed it (api, cloud, button)
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+}
+
+// SpeakRequest asks for text to be spoken with local TTS
+type SpeakRequest struct {
+	Text string `json:"text"`
+}
+
+// SpeakResponse acknowledges queued speech
+type SpeakResponse struct {
+	Status string `json:"status"`
+	Chars  int    `json:"chars"`
+}
+
+// AudioStopResponse reports what stopping playback did
+type AudioStopResponse struct {
+	Interrupted bool `json:"interrupted"`
+	Flushed     int  `json:"flushed"`
+}
+
+// MotorResponse is the motor safety state
+type MotorResponse struct {
+	Enabled bool     `json:"enabled"`
+	Stopped bool     `json:"stopped"`
+	Current HeadPose `json:"current"`
+	Goal    HeadPose `json:"goal"`
+}
+
+// MotorStateResponse is the measured robot pose. Angles are radians.
+type MotorStateResponse struct {
+	Fresh     bool        `json:"fresh"` // False once the pose is older than pollen.pose.stale_after
+	AgeMs     int64       `json:"age_ms"`
+	Head      HeadPose    `json:"head"`
+	Antennas  [2]float64  `json:"antennas"`
+	BodyYaw   float64     `json:"body_yaw"`
+	Limits    JointLimits `json:"limits"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// JointLimits are the head joint ranges (radians)
+type JointLimits struct {
+	Yaw    [2]float64 `json:"yaw"` // [min, max]
+	Pitch  [2]float64 `json:"pitch"`
+	Roll   [2]float64 `json:"roll"`
+	Source string     `json:"source"` // pollen or safety
+}
+
+// HeadPose is a head position (meters) and orientation (radians)
+type HeadPose struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
+	Roll  float64 `json:"roll"`
+	Pitch float64 `json:"pitch"`
+	Yaw   float64 `json:"yaw"`
+}
+
+// v1Routes lists the versioned endpoints
+func (s *Server) v1Routes() []apiRoute {
+	return []apiRoute{
+		{Method: fiber.MethodGet, Path: "/health", Tag: "system", Summary: "Service health",
+			Response: HealthResponse{}, Handler: s.v1HealthHandler},
+		{Method: fiber.MethodGet, Path: "/audio/doa", Tag: "audio", Summary: "Latest direction of arrival",
+			Response: DOAResponse{}, Handler: s.v1DOAHandler},
+		{Method: fiber.MethodGet, Path: "/audio/sources", Tag: "audio", Summary: "Tracked speakers",
+			Response: SourcesResponse{}, Handler: s.v1SourcesHandler},
+		{Method: fiber.MethodPost, Path: "/audio/stop", Tag: "audio", Summary: "Stop speaker playback",
+			Query:    []apiParam{{Name: "keep_queue", Type: "boolean", Description: "Only skip the clip that is playing"}},
+			Response: AudioStopResponse{}, Handler: s.v1AudioStopHandler},
+		{Method: fiber.MethodGet, Path: "/stats", Tag: "system", Summary: "DOA tracker statistics",
+			Response: StatsResponse{}, Handler: s.v1StatsHandler},
+		{Method: fiber.MethodPost, Path: "/speak", Tag: "audio", Summary: "Speak text with local TTS",
+			Request: SpeakRequest{}, Response: SpeakResponse{}, Status: fiber.StatusAccepted, Handler: s.v1SpeakHandler},
+		{Method: fiber.MethodGet, Path: "/privacy", Tag: "privacy", Summary: "Privacy mode state",
+			Response: PrivacyResponse{}, Handler: s.v1PrivacyHandler},
+		{Method: fiber.MethodPost, Path: "/privacy", Tag: "privacy", Summary: "Turn privacy mode on or off",
+			Request: PrivacyRequest{}, Response: PrivacyResponse{}, Handler: s.v1PrivacySetHandler},
+		{Method: fiber.MethodGet, Path: "/motor", Tag: "motor", Summary: "Motor safety state",
+			Response: MotorResponse{}, Handler: s.v1MotorHandler},
+		{Method: fiber.MethodGet, Path: "/motor/state", Tag: "motor", Summary: "Measured head pose and joint limits",
+			Response: MotorStateResponse{}, Handler: s.v1MotorStateHandler},
+		{Method: fiber.MethodPost, Path: "/motor/stop", Tag: "motor", Summary: "Engage the emergency stop",
+			Response: MotorResponse{}, Handler: s.v1MotorStopHandler},
+		{Method: fiber.MethodPost, Path: "/motor/resume", Tag: "motor", Summary: "Release the emergency stop",
+			Response: MotorResponse{}, Handler: s.v1MotorResumeHandler},
+	}
+}
+
+// registerV1 mounts the versioned API and its OpenAPI document
+func (s *Server) registerV1(api fiber.Router) {
+	routes := s.v1Routes()
+	v1 := s.app.Group(APIv1Prefix)
+	for _, r := range routes {
+		v1.Add(r.Method, r.Path, r.Handler)
+	}
+
+	doc := openAPIDoc(s.version, APIv1Prefix, routes)
+	api.Get("/openapi.json", func(c *fiber.Ctx) error {
+		return c.JSON(doc)
+	})
+}
+
+// v1Error sends an ErrorResponse
+func v1Error(c *fiber.Ctx, status int, msg string) error {
+	return c.Status(status).JSON(ErrorResponse{Error: msg})
+}
+
+// v1HealthHandler returns service health, with 503 when a critical component is down
+func (s *Server) v1HealthHandler(c *fiber.Ctx) error {
+	h := s.healthResponse()
+	if h.Status == health.StatusUnhealthy {
+		return c.Status(503).JSON(h)
+	}
+	return c.JSON(h)
+}
+
+// v1DOAHandler returns the latest DOA estimate
+func (s *Server) v1DOAHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return v1Error(c, 503, "DOA tracker not available")
+	}
+	return c.JSON(newDOAResponse(s.tracker.GetLatest()))
+}
+
+// v1SourcesHandler returns the tracked speakers
+func (s *Server) v1SourcesHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return v1Error(c, 503, "DOA tracker not available")
+	}
+
+	tracked := s.tracker.GetSources()
+	resp := SourcesResponse{Count: len(tracked), Sources: make([]SpeakerSource, 0, len(tracked))}
+	for _, src := range tracked {
+		resp.Sources = append(resp.Sources, SpeakerSource{
+			ID:         src.ID,
+			Angle:      src.Angle,
+			Confidence: src.Confidence,
+			Speaking:   src.Speaking,
+			FirstSeen:  src.FirstSeen,
+			LastActive: src.LastActive,
+		})
+	}
+	return c.JSON(resp)
+}
+
+// v1AudioStopHandler stops speaker playback
+func (s *Server) v1AudioStopHandler(c *fiber.Ctx) error {
+	if s.playback == nil {
+		return v1Error(c, 503, "audio playback not available")
+	}
+	interrupted, flushed := s.playback.StopPlayback(!c.QueryBool("keep_queue"))
+	return c.JSON(AudioStopResponse{Interrupted: interrupted, Flushed: flushed})
+}
+
+// v1StatsHandler returns tracker statistics
+func (s *Server) v1StatsHandler(c *fiber.Ctx) error {
+	if s.tracker == nil {
+		return v1Error(c, 503, "tracker not available")
+	}
+
+	stats := s.tracker.Stats()
+	return c.JSON(StatsResponse{
+		PollCount:     stats.PollCount,
+		ErrorCount:    stats.ErrorCount,
+		AvgLatencyMs:  stats.AvgLatencyMs,
+		SourceHealthy: stats.SourceHealthy,
+		Speaking:      stats.SpeakingLatched,
+		InUtterance:   stats.InUtterance,
+		Angle:         stats.CurrentAngle,
+		Confidence:    stats.CurrentConfidence,
+	})
+}
+
+// v1SpeakHandler queues text for local TTS
+func (s *Server) v1SpeakHandler(c *fiber.Ctx) error {
+	if s.speaker == nil {
+		return v1Error(c, 503, "local TTS not enabled")
+	}
+
+	var req SpeakRequest
+	if err := c.BodyParser(&req); err != nil || req.Text == "" {
+		return v1Error(c, 400, `request body must be {"text": "..."}`)
+	}
+
+	s.speaker.SpeakAsync(req.Text)
+	return c.Status(202).JSON(SpeakResponse{Status: "queued", Chars: len(req.Text)})
+}
+
+// v1PrivacyHandler returns the privacy mode state
+func (s *Server) v1PrivacyHandler(c *fiber.Ctx) error {
+	if s.privacy == nil {
+		return v1Error(c, 503, "privacy switch not available")
+	}
+	return c.JSON(newPrivacyResponse(s.privacy.GetStatus()))
+}
+
+// v1PrivacySetHandler turns privacy mode on or off
+func (s *Server) v1PrivacySetHandler(c *fiber.Ctx) error {
+	if s.privacy == nil {
+		return v1Error(c, 503, "privacy switch not available")
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return v1Error(c, 400, `request body must be {"enabled": true|false}`)
+	}
+
+	s.privacy.Set(*req.Enabled, "api")
+	return c.JSON(newPrivacyResponse(s.privacy.GetStatus()))
+}
+
+// v1MotorHandler returns the motor safety state
+func (s *Server) v1MotorHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return v1Error(c, 503, "motor control not available")
+	}
+	return c.JSON(newMotorResponse(s.motor.Status()))
+}
+
+// v1MotorStateHandler returns the measured pose
+func (s *Server) v1MotorStateHandler(c *fiber.Ctx) error {
+	if s.pose == nil {
+		return v1Error(c, 503, "pose polling not available")
+	}
+	state, fresh := s.pose.Latest()
+	if state.Timestamp.IsZero() {
+		return v1Error(c, 503, "no pose read from pollen yet")
+	}
+	return c.JSON(newMotorStateResponse(state, fresh, s.pose.Limits()))
+}
+
+// v1MotorStopHandler engages the emergency stop
+func (s *Server) v1MotorStopHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return v1Error(c, 503, "motor control not available")
+	}
+	err := s.motor.Stop(c.UserContext())
+	s.recordAudit(audit.KindStop, nil, err)
+	if err != nil {
+		// The latch is set either way; only the hold command failed
+		s.logger.ErrorContext(c.UserContext(), "motor stop hold command failed", "error", err)
+		return v1Error(c, 502, "stop engaged but hold command failed: "+err.Error())
+	}
+	return c.JSON(newMotorResponse(s.motor.Status()))
+}
+
+// v1MotorResumeHandler releases the emergency stop
+func (s *Server) v1MotorResumeHandler(c *fiber.Ctx) error {
+	if s.motor == nil {
+		return v1Error(c, 503, "motor control not available")
+	}
+	s.motor.Resume()
+	s.recordAudit(audit.KindResume, nil, nil)
+	return c.JSON(newMotorResponse(s.motor.Status()))
+}
+
+// newDOAResponse converts a tracker result
+func newDOAResponse(r doa.Result) DOAResponse {
+	return DOAResponse{
+		Angle:           r.Angle,
+		SmoothedAngle:   r.SmoothedAngle,
+		Confidence:      r.Confidence,
+		Speaking:        r.Speaking,
+		SpeakingLatched: r.SpeakingLatched,
+		Zone:            r.Zone,
+		SpeechEnergy:    r.SpeechEnergy,
+		TotalEnergy:     r.TotalEnergy,
+		EstX:            r.EstX,
+		EstY:            r.EstY,
+		Timestamp:       r.Timestamp,
+	}
+}
+
+// newPrivacyResponse converts a privacy status
+func newPrivacyResponse(st privacy.Status) PrivacyResponse {
+	resp := PrivacyResponse{Enabled: st.Enabled, Source: st.Source}
+	if !st.ChangedAt.IsZero() {
+		resp.ChangedAt = &st.ChangedAt
+	}
+	return resp
+}
+
+// newMotorStateResponse converts a joint state and limits
+func newMotorStateResponse(st pollen.JointState, fresh bool, limits pollen.Limits) MotorStateResponse {
+	return MotorStateResponse{
+		Fresh:    fresh,
+		AgeMs:    time.Since(st.Timestamp).Milliseconds(),
+		Head:     HeadPose(st.HeadPose),
+		Antennas: st.Antennas,
+		BodyYaw:  st.BodyYaw,
+		Limits: JointLimits{
+			Yaw:    [2]float64{limits.Yaw.Min, limits.Yaw.Max},
+			Pitch:  [2]float64{limits.Pitch.Min, limits.Pitch.Max},
+			Roll:   [2]float64{limits.Roll.Min, limits.Roll.Max},
+			Source: limits.Source,
+		},
+		Timestamp: st.Timestamp,
+	}
+}
+
+// newMotorResponse converts a motor safety status
+func newMotorResponse(st pollen.SafetyStatus) MotorResponse {
+	return MotorResponse{
+		Enabled: st.Enabled,
+		Stopped: st.Stopped,
+		Current: HeadPose(st.Current),
+		Goal:    HeadPose(st.Goal),
+	}
+}
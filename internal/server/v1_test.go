@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/pollen"
+	"github.com/teslashibe/go-eva/internal/privacy"
+)
+
+func TestServer_OpenAPI(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/openapi.json", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Info       struct{ Version string }              `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") || doc.Info.Version != "test" {
+		t.Errorf("openapi = %q, version = %q", doc.OpenAPI, doc.Info.Version)
+	}
+	// Every registered v1 route is documented
+	for _, r := range server.v1Routes() {
+		if _, ok := doc.Paths[APIv1Prefix+r.Path][strings.ToLower(r.Method)]; !ok {
+			t.Errorf("%s %s missing from document", r.Method, APIv1Prefix+r.Path)
+		}
+	}
+
+	doaSchema, ok := doc.Components.Schemas["DOAResponse"]
+	if !ok {
+		t.Fatal("DOAResponse schema missing")
+	}
+	if energy := doaSchema.Properties["speech_energy"]; energy["type"] != "array" || energy["maxItems"] != float64(4) {
+		t.Errorf("speech_energy schema = %v", energy)
+	}
+	if ts := doaSchema.Properties["timestamp"]; ts["format"] != "date-time" {
+		t.Errorf("timestamp schema = %v", ts)
+	}
+	if _, ok := doc.Components.Schemas["ErrorResponse"]; !ok {
+		t.Error("ErrorResponse schema missing")
+	}
+	for _, name := range doc.Components.Schemas["PrivacyResponse"].Required {
+		if name == "changed_at" || name == "source" {
+			t.Errorf("optional field %s marked required", name)
+		}
+	}
+}
+
+func TestServer_V1DOA(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/v1/audio/doa", nil), -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, field := range []string{"angle", "smoothed_angle", "confidence", "speaking_latched", "timestamp"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("response missing %s", field)
+		}
+	}
+	// Internal-only fields stay out of the stable API
+	if _, ok := body["raw_angle"]; ok {
+		t.Error("response should not expose raw_angle")
+	}
+}
+
+func TestServer_V1Errors(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/v1/speak", strings.NewReader(`{"text":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Fatalf("expected status 503, got %d", resp.StatusCode)
+	}
+
+	var body ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		t.Errorf("expected an ErrorResponse, got %+v (%v)", body, err)
+	}
+}
+
+func TestServer_V1Privacy(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.SetPrivacy(privacy.NewGuard(privacy.DefaultConfig(), slog.Default()))
+
+	req := httptest.NewRequest("POST", "/api/v1/privacy", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body PrivacyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !body.Enabled || body.Source != "api" || body.ChangedAt == nil {
+		t.Errorf("unexpected privacy response %+v", body)
+	}
+}
+
+func TestServer_V1MotorState(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	pollenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/state/full" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"head_pose":{"x":0,"y":0,"z":0,"roll":0,"pitch":0.1,"yaw":0.4},"body_yaw":0.2,"antennas_position":[0.5,-0.5]}`))
+	}))
+	defer pollenServer.Close()
+
+	pollenCfg := pollen.DefaultConfig()
+	pollenCfg.BaseURL = pollenServer.URL
+	poller := pollen.NewPosePoller(pollen.NewClient(pollenCfg, nil), pollen.DefaultPoseConfig(), nil)
+	server.SetPose(poller)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poller.Run(ctx)
+
+	var body MotorStateResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/api/v1/motor/state", nil), -1)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if resp.StatusCode == 200 {
+			json.NewDecoder(resp.Body).Decode(&body)
+			resp.Body.Close()
+			break
+		}
+		resp.Body.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !body.Fresh || body.Head.Yaw != 0.4 || body.BodyYaw != 0.2 || body.Antennas != [2]float64{0.5, -0.5} {
+		t.Errorf("unexpected motor state %+v", body)
+	}
+	if body.Limits.Source != "safety" || body.Limits.Yaw[1] <= 0 {
+		t.Errorf("limits = %+v, want safety fallback", body.Limits)
+	}
+}
@@ -3,50 +3,221 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/teslashibe/go-eva/internal/config"
 	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/store"
 )
 
-// WSHub manages WebSocket connections and broadcasts DOA updates
+// WebSocket topics clients can subscribe to
+const (
+	TopicDOA     = "doa"     // Tracker results
+	TopicSources = "sources" // Per-speaker tracks
+	TopicVAD     = "vad"     // Speaking state changes (sent on change)
+	TopicStats   = "stats"   // Tracker statistics
+	TopicCamera  = "camera"  // Camera statistics
+	TopicHealth  = "health"  // Service health
+	TopicLevels  = "levels"  // Mic levels (dBFS per channel)
+	TopicEvents  = "events"  // Published events (transcripts, gestures, privacy, utterances)
+)
+
+// defaultRates are the per-topic send rates (Hz) for periodic topics
+var defaultRates = map[string]float64{
+	TopicDOA:     10,
+	TopicSources: 10,
+	TopicStats:   1,
+	TopicCamera:  1,
+	TopicHealth:  1,
+	TopicLevels:  20,
+}
+
+// defaultTopics preserves the stream a client gets before it subscribes
+var defaultTopics = []string{TopicDOA, TopicSources, TopicVAD, TopicEvents}
+
+const (
+	maxTopicRate    = 50.0                  // Hz; also the hub tick rate for provider topics
+	minTopicRate    = 0.1                   // Hz
+	clientQueueSize = 64                    // Default messages buffered per client
+	hubTick         = 20 * time.Millisecond // 1 / maxTopicRate; also the rate limit's jitter allowance
+	closeTimeout    = time.Second           // Write deadline for close frames
+)
+
+// Close frame reasons
+const (
+	shutdownReason = "server shutting down"
+	stalledReason  = "client too slow"
+)
+
+// WSHub manages WebSocket connections and fans out topic updates, each client
+// at its own rate and through its own send queue. Tracker topics follow the
+// tracker's own cadence: every result is offered to each client once, as it
+// arrives, and a client's rate only decides which ones it skips.
+//
+// A client that falls behind loses its oldest queued messages first, so it
+// always catches up to the present. One that stays behind for
+// config.WSConfig.StallTimeout is disconnected.
 type WSHub struct {
 	tracker *doa.Tracker
 	logger  *slog.Logger
+	cfg     config.WSConfig
+
+	mu        sync.RWMutex
+	clients   map[*wsClient]struct{}
+	providers map[string]func() interface{}
+	motorCmd  MotorCommandFunc // nil = "motor" commands are refused
+	history   store.History    // nil = "history" commands are ignored
+
+	cancel  context.CancelFunc
+	done    chan struct{}
+	closing atomic.Bool // Shutdown started; new clients are turned away
+
+	// Stats
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	evicted atomic.Uint64
+}
+
+// wsClient is one connection with its subscriptions and send queue
+type wsClient struct {
+	conn    *websocket.Conn
+	send    chan []byte
+	quit    chan struct{}
+	goAway  chan struct{} // Closed on shutdown: flush the queue, then say goodbye
+	stalled chan struct{} // Closed when the client is evicted for falling behind
+	done    chan struct{} // Closed when the write loop exits
+
+	stalledSince atomic.Int64 // Unix ns of the first drop since the queue last emptied (0 = keeping up)
+	evictOnce    sync.Once
+
+	mu      sync.Mutex
+	topics  map[string]bool
+	rates   map[string]float64
+	lastAt  map[string]time.Time
+	sources int // Track count last sent, to announce when all expire
+
+	dropped atomic.Uint64
+}
 
-	mu      sync.RWMutex
-	clients map[*websocket.Conn]struct{}
+func newWSClient(conn *websocket.Conn, queueSize int) *wsClient {
+	if queueSize <= 0 {
+		queueSize = clientQueueSize
+	}
+	c := &wsClient{
+		conn:    conn,
+		send:    make(chan []byte, queueSize),
+		quit:    make(chan struct{}),
+		goAway:  make(chan struct{}),
+		stalled: make(chan struct{}),
+		done:    make(chan struct{}),
+		topics:  make(map[string]bool),
+		rates:   make(map[string]float64),
+		lastAt:  make(map[string]time.Time),
+	}
+	for _, topic := range defaultTopics {
+		c.topics[topic] = true
+	}
+	for topic, rate := range defaultRates {
+		c.rates[topic] = rate
+	}
+	return c
+}
 
-	cancel context.CancelFunc
-	done   chan struct{}
+// subscribed reports whether the client wants topic
+func (c *wsClient) subscribed(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.topics[topic]
+}
+
+// due reports whether a periodic topic should be sent now, and marks it sent
+func (c *wsClient) due(topic string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.topics[topic] {
+		return false
+	}
+	interval := time.Duration(float64(time.Second) / c.rates[topic])
+	// Allow half a tick of jitter so a 50Hz client isn't held to 25Hz by
+	// results arriving a little early
+	if now.Sub(c.lastAt[topic]) < interval-hubTick/2 {
+		return false
+	}
+	c.lastAt[topic] = now
+	return true
 }
 
 // NewWSHub creates a new WebSocket hub
 func NewWSHub(tracker *doa.Tracker, logger *slog.Logger) *WSHub {
 	return &WSHub{
-		tracker: tracker,
-		logger:  logger,
-		clients: make(map[*websocket.Conn]struct{}),
-		done:    make(chan struct{}),
+		tracker:   tracker,
+		logger:    logger,
+		cfg:       config.WSConfig{QueueSize: clientQueueSize},
+		clients:   make(map[*wsClient]struct{}),
+		providers: make(map[string]func() interface{}),
+		done:      make(chan struct{}),
 	}
 }
 
+// SetConfig sets the client queue size, write deadline and stall timeout;
+// it applies to clients that connect afterwards
+func (h *WSHub) SetConfig(cfg config.WSConfig) {
+	h.mu.Lock()
+	h.cfg = cfg
+	h.mu.Unlock()
+}
+
 // Message represents a WebSocket message
 type Message struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
 }
 
-// Run starts the broadcast loop
+// SetProvider registers the data source for a periodic topic (stats, camera,
+// health, levels); fn returns nil to skip a tick
+func (h *WSHub) SetProvider(topic string, fn func() interface{}) {
+	h.mu.Lock()
+	h.providers[topic] = fn
+	h.mu.Unlock()
+}
+
+// SetHistory answers "history" commands from history
+func (h *WSHub) SetHistory(history store.History) {
+	h.mu.Lock()
+	h.history = history
+	h.mu.Unlock()
+}
+
+// SetMotorCommands lets clients move the head with "motor" commands
+func (h *WSHub) SetMotorCommands(fn MotorCommandFunc) {
+	h.mu.Lock()
+	h.motorCmd = fn
+	h.mu.Unlock()
+}
+
+// Run starts the broadcast loop: tracker results are fanned out as the
+// tracker publishes them, provider topics on a fixed tick
 func (h *WSHub) Run(ctx context.Context) {
 	ctx, h.cancel = context.WithCancel(ctx)
 	defer close(h.done)
 
-	ticker := time.NewTicker(100 * time.Millisecond) // 10Hz
+	var results chan doa.Result
+	if h.tracker != nil {
+		results = h.tracker.Subscribe()
+		defer h.tracker.Unsubscribe(results)
+	}
+
+	ticker := time.NewTicker(hubTick)
 	defer ticker.Stop()
 
 	var lastSpeaking bool
@@ -58,57 +229,252 @@ func (h *WSHub) Run(ctx context.Context) {
 		case <-ctx.Done():
 			h.logger.Info("websocket hub stopped")
 			return
-		case <-ticker.C:
-			if h.tracker == nil {
+		case result, ok := <-results:
+			if !ok {
+				results = nil // Tracker stopped; keep serving provider topics
 				continue
 			}
+			h.broadcast(result, time.Now(), &lastSpeaking)
+		case now := <-ticker.C:
+			h.tick(now)
+		}
+	}
+}
+
+// snapshot returns the connected clients
+func (h *WSHub) snapshot() []*wsClient {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := make([]*wsClient, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// broadcast delivers one tracker result to each client whose doa and
+// sources rates allow it, and announces speaking changes. Payloads are built
+// and marshaled at most once per result.
+func (h *WSHub) broadcast(result doa.Result, now time.Time, lastSpeaking *bool) {
+	clients := h.snapshot()
+
+	var doaMsg []byte
+	var sources []doa.TrackedSource
+	var sourcesMsg []byte
+	for _, c := range clients {
+		if c.due(TopicDOA, now) {
+			if doaMsg == nil {
+				doaMsg = h.marshal(Message{Type: TopicDOA, Data: result})
+			}
+			h.enqueue(c, doaMsg)
+		}
+
+		if c.due(TopicSources, now) {
+			if sourcesMsg == nil {
+				sources = h.tracker.GetSources()
+				sourcesMsg = h.marshal(Message{Type: TopicSources, Data: sources})
+			}
+			// Sent while any exist, plus once when they all expire
+			c.mu.Lock()
+			send := len(sources) > 0 || c.sources > 0
+			c.sources = len(sources)
+			c.mu.Unlock()
+			if send {
+				h.enqueue(c, sourcesMsg)
+			}
+		}
+	}
+
+	// Immediate VAD change notification
+	if result.SpeakingLatched != *lastSpeaking {
+		h.publish(clients, TopicVAD, Message{
+			Type: TopicVAD,
+			Data: map[string]interface{}{
+				"speaking": result.SpeakingLatched,
+				"angle":    result.SmoothedAngle,
+			},
+		})
+		*lastSpeaking = result.SpeakingLatched
+
+		h.logger.Debug("vad state change",
+			"speaking", result.SpeakingLatched,
+			"angle", result.SmoothedAngle,
+		)
+	}
+}
+
+// tick delivers every provider topic that is due to each subscribed client.
+// Payloads are built and marshaled at most once per tick.
+func (h *WSHub) tick(now time.Time) {
+	clients := h.snapshot()
 
-			result := h.tracker.GetLatest()
-
-			// Broadcast to all clients
-			h.broadcast(Message{
-				Type: "doa",
-				Data: result,
-			})
-
-			// Immediate VAD change notification
-			if result.SpeakingLatched != lastSpeaking {
-				h.broadcast(Message{
-					Type: "vad",
-					Data: map[string]interface{}{
-						"speaking": result.SpeakingLatched,
-						"angle":    result.SmoothedAngle,
-					},
-				})
-				lastSpeaking = result.SpeakingLatched
-
-				h.logger.Debug("vad state change",
-					"speaking", result.SpeakingLatched,
-					"angle", result.SmoothedAngle,
-				)
+	h.mu.RLock()
+	providers := make(map[string]func() interface{}, len(h.providers))
+	for topic, fn := range h.providers {
+		providers[topic] = fn
+	}
+	h.mu.RUnlock()
+
+	for _, topic := range []string{TopicStats, TopicCamera, TopicHealth, TopicLevels} {
+		fn := providers[topic]
+		if fn == nil {
+			continue
+		}
+		var msg []byte
+		for _, c := range clients {
+			if !c.due(topic, now) {
+				continue
+			}
+			if msg == nil {
+				data := fn()
+				if data == nil {
+					break // Nothing to report this tick
+				}
+				msg = h.marshal(Message{Type: topic, Data: data})
 			}
+			h.enqueue(c, msg)
 		}
 	}
 }
 
-func (h *WSHub) broadcast(msg Message) {
+// Publish sends an event message to clients subscribed to events
+func (h *WSHub) Publish(msgType string, data interface{}) {
+	h.publish(h.snapshot(), TopicEvents, Message{Type: msgType, Data: data})
+}
+
+func (h *WSHub) publish(clients []*wsClient, topic string, msg Message) {
+	var data []byte
+	for _, c := range clients {
+		if !c.subscribed(topic) {
+			continue
+		}
+		if data == nil {
+			data = h.marshal(msg)
+		}
+		h.enqueue(c, data)
+	}
+}
+
+func (h *WSHub) marshal(msg Message) []byte {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		h.logger.Warn("websocket marshal error", "error", err)
+		return []byte{}
+	}
+	return data
+}
+
+// enqueue queues data for a client. If the client is behind, its oldest
+// queued message makes room, and a client that has been behind for longer
+// than the stall timeout is evicted.
+func (h *WSHub) enqueue(c *wsClient, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	for {
+		select {
+		case c.send <- data:
+			return
+		default:
+		}
+
+		select {
+		case <-c.send:
+		default:
+			// The write loop just made room
+			continue
+		}
+		c.dropped.Add(1)
+		h.dropped.Add(1)
+		h.checkStalled(c, time.Now())
+	}
+}
+
+// checkStalled evicts c once it has been dropping messages, without its
+// queue emptying, for longer than the stall timeout
+func (h *WSHub) checkStalled(c *wsClient, now time.Time) {
+	since := c.stalledSince.Load()
+	if since == 0 {
+		c.stalledSince.CompareAndSwap(0, now.UnixNano())
 		return
 	}
 
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	timeout := h.cfg.StallTimeout
+	h.mu.RUnlock()
+	if timeout <= 0 || now.Sub(time.Unix(0, since)) < timeout {
+		return
+	}
+
+	c.evictOnce.Do(func() {
+		h.evicted.Add(1)
+		remote := ""
+		if c.conn != nil {
+			remote = c.conn.RemoteAddr().String()
+		}
+		h.logger.Warn("evicting stalled websocket client",
+			"remote_addr", remote,
+			"stalled_for", now.Sub(time.Unix(0, since)).Round(time.Millisecond),
+			"dropped", c.dropped.Load(),
+		)
+		close(c.stalled)
+	})
+}
 
-	for conn := range h.clients {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			// Will be cleaned up when connection closes
-			h.logger.Debug("websocket write error", "error", err)
+// writeLoop drains a client's queue so a slow reader only stalls itself.
+// It owns the connection's writes, including the close frame on shutdown.
+func (h *WSHub) writeLoop(c *wsClient) {
+	defer close(c.done)
+	goAway := c.goAway
+	for {
+		select {
+		case <-c.quit:
+			return
+		case data := <-c.send:
+			if !h.write(c, data) {
+				return
+			}
+			if len(c.send) == 0 {
+				c.stalledSince.Store(0)
+			}
+		case <-c.stalled:
+			// The read loop sees the closed connection and cleans up
+			msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, stalledReason)
+			c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout))
+			c.conn.Close()
+			return
+		case <-goAway:
+			for len(c.send) > 0 {
+				if !h.write(c, <-c.send) {
+					return
+				}
+			}
+			goingAway(c.conn)
+			goAway = nil
 		}
 	}
 }
 
+// write sends one message within the write deadline, closing the
+// connection if it fails
+func (h *WSHub) write(c *wsClient, data []byte) bool {
+	h.mu.RLock()
+	timeout := h.cfg.WriteTimeout
+	h.mu.RUnlock()
+	if timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		// The read loop sees the closed connection and cleans up
+		h.logger.Debug("websocket write error", "error", err)
+		c.conn.Close()
+		return false
+	}
+	h.sent.Add(1)
+	return true
+}
+
 // UpgradeHandler returns the WebSocket upgrade handler
 func (h *WSHub) UpgradeHandler() fiber.Handler {
 	// Middleware to check if request is a WebSocket upgrade
@@ -125,14 +491,21 @@ func (h *WSHub) UpgradeHandler() fiber.Handler {
 	}
 }
 
-func (h *WSHub) handleConnection(c *websocket.Conn) {
+func (h *WSHub) handleConnection(conn *websocket.Conn) {
+	if h.closing.Load() {
+		goingAway(conn)
+		return
+	}
 	h.mu.Lock()
+	c := newWSClient(conn, h.cfg.QueueSize)
 	h.clients[c] = struct{}{}
 	clientCount := len(h.clients)
 	h.mu.Unlock()
 
+	go h.writeLoop(c)
+
 	h.logger.Info("websocket client connected",
-		"remote_addr", c.RemoteAddr().String(),
+		"remote_addr", conn.RemoteAddr().String(),
 		"clients", clientCount,
 	)
 
@@ -141,43 +514,189 @@ func (h *WSHub) handleConnection(c *websocket.Conn) {
 		delete(h.clients, c)
 		clientCount := len(h.clients)
 		h.mu.Unlock()
+		close(c.quit)
+		// The connection is recycled once this handler returns
+		<-c.done
 
 		h.logger.Info("websocket client disconnected",
-			"remote_addr", c.RemoteAddr().String(),
+			"remote_addr", conn.RemoteAddr().String(),
 			"clients", clientCount,
+			"dropped", c.dropped.Load(),
 		)
 	}()
 
 	// Keep connection alive, read for close or commands
 	for {
-		_, msg, err := c.ReadMessage()
+		_, msg, err := conn.ReadMessage()
 		if err != nil {
 			// Connection closed
 			break
 		}
 
-		// Handle incoming commands (e.g., config changes)
+		// Handle incoming commands (e.g., subscriptions)
 		h.handleCommand(c, msg)
 	}
 }
 
-func (h *WSHub) handleCommand(c *websocket.Conn, msg []byte) {
-	var cmd struct {
-		Type string `json:"type"`
-	}
+// wsCommand is a client → hub message
+type wsCommand struct {
+	Type   string             `json:"type"`
+	Topics []string           `json:"topics,omitempty"`
+	Rate   float64            `json:"rate,omitempty"`  // Hz for all requested periodic topics
+	Rates  map[string]float64 `json:"rates,omitempty"` // Hz per topic, overrides rate
 
+	// history: window and bucket size, as in /api/audio/doa/history
+	Seconds    float64 `json:"seconds,omitempty"`
+	Resolution string  `json:"resolution,omitempty"`
+}
+
+func (h *WSHub) handleCommand(c *wsClient, msg []byte) {
+	var cmd wsCommand
 	if err := json.Unmarshal(msg, &cmd); err != nil {
 		return
 	}
 
 	switch cmd.Type {
 	case "ping":
-		c.WriteJSON(Message{Type: "pong", Data: time.Now().Unix()})
+		h.enqueue(c, h.marshal(Message{Type: "pong", Data: time.Now().Unix()}))
 	case "get_stats":
 		if h.tracker != nil {
-			c.WriteJSON(Message{Type: "stats", Data: h.tracker.Stats()})
+			h.enqueue(c, h.marshal(Message{Type: "stats", Data: h.tracker.Stats()}))
+		}
+	case "history":
+		h.mu.RLock()
+		history := h.history
+		h.mu.RUnlock()
+		if history == nil {
+			return
+		}
+		seconds := ""
+		if cmd.Seconds != 0 {
+			seconds = strconv.FormatFloat(cmd.Seconds, 'f', -1, 64)
+		}
+		window, resolution, err := parseHistoryWindow(seconds, cmd.Resolution)
+		if err != nil {
+			h.enqueue(c, h.marshal(Message{Type: "error", Data: err.Error()}))
+			return
+		}
+		data, err := trackerHistory(history, window, resolution)
+		if err != nil {
+			h.enqueue(c, h.marshal(Message{Type: "error", Data: err.Error()}))
+			return
+		}
+		h.enqueue(c, h.marshal(Message{Type: "history", Data: data}))
+	case "subscribe", "unsubscribe":
+		if err := c.apply(cmd); err != nil {
+			h.enqueue(c, h.marshal(Message{Type: "error", Data: err.Error()}))
+			return
+		}
+		h.enqueue(c, h.marshal(Message{Type: "subscribed", Data: c.subscription()}))
+	case "motor":
+		h.mu.RLock()
+		motorCmd := h.motorCmd
+		h.mu.RUnlock()
+		if motorCmd == nil {
+			h.enqueue(c, h.marshal(Message{Type: "error", Data: "motor control not available"}))
+			return
+		}
+
+		// The command's fields sit beside "type", as in POST /api/motor/target
+		var cmd protocol.MotorCommand
+		err := json.Unmarshal(msg, &cmd)
+		if err == nil {
+			err = cmd.Validate()
+		}
+		if err == nil {
+			err = motorCmd(context.Background(), cmd)
+		}
+		if err != nil {
+			h.enqueue(c, h.marshal(Message{Type: "error", Data: "motor: " + err.Error()}))
+			return
+		}
+		h.enqueue(c, h.marshal(Message{Type: "motor_ack", Data: cmd}))
+	}
+}
+
+// apply updates the client's topics and rates. Subscribe replaces the
+// topic set when topics are given; unsubscribe removes them.
+func (c *wsClient) apply(cmd wsCommand) error {
+	for _, topic := range cmd.Topics {
+		if !knownTopic(topic) {
+			return fmt.Errorf("unknown topic %q", topic)
+		}
+	}
+	for topic, rate := range cmd.Rates {
+		if _, ok := defaultRates[topic]; !ok {
+			return fmt.Errorf("topic %q has no rate", topic)
+		}
+		if rate < minTopicRate || rate > maxTopicRate {
+			return fmt.Errorf("rate for %q must be between %g and %g Hz", topic, minTopicRate, maxTopicRate)
+		}
+	}
+	if cmd.Rate != 0 && (cmd.Rate < minTopicRate || cmd.Rate > maxTopicRate) {
+		return fmt.Errorf("rate must be between %g and %g Hz", minTopicRate, maxTopicRate)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cmd.Type == "unsubscribe" {
+		for _, topic := range cmd.Topics {
+			delete(c.topics, topic)
+		}
+		return nil
+	}
+
+	if len(cmd.Topics) > 0 {
+		c.topics = make(map[string]bool, len(cmd.Topics))
+		for _, topic := range cmd.Topics {
+			c.topics[topic] = true
+		}
+	}
+	if cmd.Rate != 0 {
+		for topic := range defaultRates {
+			if len(cmd.Topics) == 0 || c.topics[topic] {
+				c.rates[topic] = cmd.Rate
+			}
+		}
+	}
+	for topic, rate := range cmd.Rates {
+		c.rates[topic] = rate
+	}
+	return nil
+}
+
+// subscription describes the client's current topics and rates
+func (c *wsClient) subscription() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topics := make([]string, 0, len(c.topics))
+	rates := make(map[string]float64)
+	for _, topic := range allTopics {
+		if !c.topics[topic] {
+			continue
+		}
+		topics = append(topics, topic)
+		if rate, ok := c.rates[topic]; ok {
+			rates[topic] = rate
+		}
+	}
+	return map[string]interface{}{
+		"topics": topics,
+		"rates":  rates,
+	}
+}
+
+var allTopics = []string{TopicDOA, TopicSources, TopicVAD, TopicStats, TopicCamera, TopicHealth, TopicLevels, TopicEvents}
+
+func knownTopic(topic string) bool {
+	for _, t := range allTopics {
+		if t == topic {
+			return true
 		}
 	}
+	return false
 }
 
 // ClientCount returns the number of connected WebSocket clients
@@ -187,7 +706,60 @@ func (h *WSHub) ClientCount() int {
 	return len(h.clients)
 }
 
-// Close shuts down the WebSocket hub
+// WSHubStats contains hub statistics
+type WSHubStats struct {
+	Clients int    `json:"clients"`
+	Sent    uint64 `json:"sent"`
+	Dropped uint64 `json:"dropped"`
+	Evicted uint64 `json:"evicted"` // Clients disconnected for falling behind
+}
+
+// GetStats returns hub statistics
+func (h *WSHub) GetStats() WSHubStats {
+	return WSHubStats{
+		Clients: h.ClientCount(),
+		Sent:    h.sent.Load(),
+		Dropped: h.dropped.Load(),
+		Evicted: h.evicted.Load(),
+	}
+}
+
+// Shutdown stops broadcasting, gives each client until ctx ends to receive
+// what is already queued followed by a going-away close frame, and waits
+// for clients to answer before disconnecting everyone
+func (h *WSHub) Shutdown(ctx context.Context) {
+	if h.closing.Swap(true) {
+		return
+	}
+	if h.cancel != nil {
+		h.cancel()
+		<-h.done
+	}
+
+	clients := h.snapshot()
+	for _, c := range clients {
+		close(c.goAway)
+	}
+	// The read loop ends once the client echoes the close frame
+	for _, c := range clients {
+		select {
+		case <-c.quit:
+		case <-ctx.Done():
+		}
+	}
+
+	h.Close()
+}
+
+// goingAway sends a close frame telling the client the server is shutting
+// down
+func goingAway(conn *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, shutdownReason)
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout))
+}
+
+// Close shuts down the WebSocket hub, dropping connections without a close
+// frame
 func (h *WSHub) Close() {
 	if h.cancel != nil {
 		h.cancel()
@@ -196,10 +768,9 @@ func (h *WSHub) Close() {
 
 	// Close all client connections
 	h.mu.Lock()
-	for conn := range h.clients {
-		conn.Close()
+	for c := range h.clients {
+		c.conn.Close()
 	}
-	h.clients = make(map[*websocket.Conn]struct{})
+	h.clients = make(map[*wsClient]struct{})
 	h.mu.Unlock()
 }
-
@@ -0,0 +1,400 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/teslashibe/go-eva/internal/config"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+	"github.com/teslashibe/go-eva/internal/store"
+	"github.com/teslashibe/go-eva/internal/xvf3800"
+)
+
+// drain counts queued messages by type
+func drain(c *wsClient) map[string]int {
+	counts := make(map[string]int)
+	for {
+		select {
+		case data := <-c.send:
+			var msg Message
+			json.Unmarshal(data, &msg)
+			counts[msg.Type]++
+		default:
+			return counts
+		}
+	}
+}
+
+func TestWSHub_PerClientRates(t *testing.T) {
+	_, tracker := setupTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	hub := NewWSHub(tracker, slog.Default())
+	hub.SetProvider(TopicStats, func() interface{} { return tracker.Stats() })
+
+	fast := newWSClient(nil, clientQueueSize)
+	if err := fast.apply(wsCommand{Type: "subscribe", Topics: []string{TopicDOA}, Rate: 50}); err != nil {
+		t.Fatal(err)
+	}
+	normal := newWSClient(nil, clientQueueSize)
+	statsOnly := newWSClient(nil, clientQueueSize)
+	if err := statsOnly.apply(wsCommand{Type: "subscribe", Topics: []string{TopicStats}, Rates: map[string]float64{TopicStats: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*wsClient{fast, normal, statsOnly} {
+		hub.clients[c] = struct{}{}
+	}
+
+	hubCtx, stopHub := context.WithCancel(ctx)
+	go hub.Run(hubCtx)
+	time.Sleep(25 * hubTick)
+	stopHub()
+	<-hub.done
+
+	fastCounts, normalCounts, statsCounts := drain(fast), drain(normal), drain(statsOnly)
+	if fastCounts[TopicDOA] < 2*normalCounts[TopicDOA] {
+		t.Errorf("fast client got %d doa messages, normal got %d", fastCounts[TopicDOA], normalCounts[TopicDOA])
+	}
+	if normalCounts[TopicDOA] == 0 {
+		t.Error("default client got no doa messages")
+	}
+	if fastCounts[TopicStats] != 0 || normalCounts[TopicStats] != 0 {
+		t.Error("stats sent to clients that didn't subscribe")
+	}
+	if statsCounts[TopicStats] == 0 || statsCounts[TopicDOA] != 0 {
+		t.Errorf("stats-only client got %v", statsCounts)
+	}
+}
+
+func TestWSHub_DeliversEachResultOnce(t *testing.T) {
+	source := xvf3800.NewMockSource()
+	source.SetSpeaking(true)
+	cfg := doa.DefaultTrackerConfig()
+	cfg.PollInterval = 30 * time.Millisecond // Slower than the client's rate
+	tracker := doa.NewTracker(source, cfg, slog.Default())
+
+	hub := NewWSHub(tracker, slog.Default())
+	c := newWSClient(nil, clientQueueSize)
+	if err := c.apply(wsCommand{Type: "subscribe", Topics: []string{TopicDOA}, Rate: 50}); err != nil {
+		t.Fatal(err)
+	}
+	hub.clients[c] = struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+	time.Sleep(10 * time.Millisecond) // Let the hub subscribe
+	trackerCtx, stopTracker := context.WithCancel(ctx)
+	trackerDone := make(chan struct{})
+	go func() {
+		tracker.Run(trackerCtx)
+		close(trackerDone)
+	}()
+	time.Sleep(300 * time.Millisecond)
+	stopTracker()
+	<-trackerDone
+	time.Sleep(10 * time.Millisecond) // Let the hub drain the subscription
+	cancel()
+	<-hub.done
+	polls := tracker.Stats().PollCount
+
+	seen := make(map[time.Time]bool)
+	for len(c.send) > 0 {
+		var msg struct {
+			Type string     `json:"type"`
+			Data doa.Result `json:"data"`
+		}
+		json.Unmarshal(<-c.send, &msg)
+		if seen[msg.Data.Timestamp] {
+			t.Errorf("result at %v delivered twice", msg.Data.Timestamp)
+		}
+		seen[msg.Data.Timestamp] = true
+	}
+	if n := int64(len(seen)); n != polls {
+		t.Errorf("delivered %d results for %d polls", n, polls)
+	}
+}
+
+func TestWSHub_SlowClientDoesNotStarveOthers(t *testing.T) {
+	hub := NewWSHub(nil, slog.Default())
+
+	slow := newWSClient(nil, clientQueueSize) // Never drained
+	fast := newWSClient(nil, clientQueueSize)
+	hub.clients[slow] = struct{}{}
+	hub.clients[fast] = struct{}{}
+
+	received := make(chan int)
+	go func() {
+		n := 0
+		timeout := time.After(time.Second)
+		for n < 100 {
+			select {
+			case <-fast.send:
+				n++
+			case <-timeout:
+				received <- n
+				return
+			}
+		}
+		received <- n
+	}()
+
+	for i := 0; i < 100; i++ {
+		hub.Publish("transcript", i)
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := <-received; n != 100 {
+		t.Errorf("fast client received %d/100 events", n)
+	}
+	if dropped := slow.dropped.Load(); dropped != 100-clientQueueSize {
+		t.Errorf("slow client dropped %d, want %d", dropped, 100-clientQueueSize)
+	}
+	if hub.GetStats().Dropped != slow.dropped.Load() {
+		t.Errorf("hub dropped = %d, want %d", hub.GetStats().Dropped, slow.dropped.Load())
+	}
+
+	// The oldest events were dropped, so the slow client is left with the newest
+	var first struct {
+		Data int `json:"data"`
+	}
+	json.Unmarshal(<-slow.send, &first)
+	if first.Data != 100-clientQueueSize {
+		t.Errorf("slow client's oldest queued event = %d, want %d", first.Data, 100-clientQueueSize)
+	}
+}
+
+func TestWSHub_EvictsStalledClient(t *testing.T) {
+	hub := NewWSHub(nil, slog.Default())
+	hub.SetConfig(config.WSConfig{QueueSize: 4, StallTimeout: 50 * time.Millisecond})
+
+	stalled := newWSClient(nil, 4) // Never drained
+	behind := newWSClient(nil, 4)  // Catches up before the timeout
+	hub.clients[stalled] = struct{}{}
+	hub.clients[behind] = struct{}{}
+
+	for i := 0; i < 8; i++ {
+		hub.Publish("transcript", i)
+	}
+	drain(behind)
+	behind.stalledSince.Store(0) // What the write loop does once the queue empties
+
+	time.Sleep(60 * time.Millisecond)
+	hub.Publish("transcript", 8)
+
+	select {
+	case <-stalled.stalled:
+	default:
+		t.Error("stalled client was not evicted")
+	}
+	select {
+	case <-behind.stalled:
+		t.Error("client that caught up was evicted")
+	default:
+	}
+	if n := hub.GetStats().Evicted; n != 1 {
+		t.Errorf("evicted = %d, want 1", n)
+	}
+}
+
+func TestWSClient_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     wsCommand
+		wantErr bool
+	}{
+		{"valid", wsCommand{Type: "subscribe", Topics: []string{TopicDOA, TopicVAD}, Rate: 20}, false},
+		{"unknown topic", wsCommand{Type: "subscribe", Topics: []string{"video"}}, true},
+		{"rate too high", wsCommand{Type: "subscribe", Rate: 500}, true},
+		{"rate for event topic", wsCommand{Type: "subscribe", Rates: map[string]float64{TopicVAD: 5}}, true},
+		{"unsubscribe", wsCommand{Type: "unsubscribe", Topics: []string{TopicEvents}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newWSClient(nil, clientQueueSize)
+			err := c.apply(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	c := newWSClient(nil, clientQueueSize)
+	c.apply(wsCommand{Type: "subscribe", Topics: []string{TopicDOA, TopicStats}, Rate: 20})
+	if c.subscribed(TopicVAD) || !c.subscribed(TopicStats) {
+		t.Error("subscribe did not replace the topic set")
+	}
+	if c.rates[TopicDOA] != 20 || c.rates[TopicStats] != 20 || c.rates[TopicHealth] != defaultRates[TopicHealth] {
+		t.Errorf("rates = %v", c.rates)
+	}
+}
+
+func TestServer_WebSocketSubscribe(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.app.Listener(ln)
+	defer server.app.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.WSHub().Run(ctx)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/api/audio/doa/stream", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	sub := `{"type": "subscribe", "topics": ["health"], "rates": {"health": 50}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	seen := make(map[string]int)
+	for seen[TopicHealth] < 3 {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v (seen %v)", err, seen)
+		}
+		seen[msg.Type]++
+		if msg.Type == TopicDOA && seen["subscribed"] > 0 {
+			t.Fatal("received doa after unsubscribing from it")
+		}
+	}
+	if seen["subscribed"] != 1 {
+		t.Errorf("expected a subscribed ack, got %v", seen)
+	}
+}
+
+func TestWSHub_HistoryCommand(t *testing.T) {
+	_, tracker := setupTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	history := store.NewWindow(store.Config{Window: 1200})
+	go store.Run(ctx, history, store.DefaultConfig(), tracker.Subscribe(), slog.Default())
+	go tracker.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	hub := NewWSHub(tracker, slog.Default())
+	hub.SetHistory(history)
+	c := newWSClient(nil, clientQueueSize)
+
+	hub.handleCommand(c, []byte(`{"type": "history", "seconds": 10, "resolution": "0s"}`))
+	var msg struct {
+		Type string `json:"type"`
+		Data struct {
+			Seconds float64            `json:"seconds"`
+			Points  []doa.HistoryPoint `json:"points"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(<-c.send, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "history" || msg.Data.Seconds != 10 || len(msg.Data.Points) == 0 {
+		t.Errorf("history reply = %+v", msg)
+	}
+
+	hub.handleCommand(c, []byte(`{"type": "history", "resolution": "soon"}`))
+	if counts := drain(c); counts["error"] != 1 {
+		t.Errorf("bad resolution reply = %v", counts)
+	}
+}
+
+func TestWSHub_MotorCommand(t *testing.T) {
+	hub := NewWSHub(nil, slog.Default())
+	c := newWSClient(nil, clientQueueSize)
+
+	hub.handleCommand(c, []byte(`{"type": "motor", "head": {"yaw": 0.5}}`))
+	if counts := drain(c); counts["error"] != 1 {
+		t.Errorf("motor without a command path: reply = %v", counts)
+	}
+
+	var got []protocol.MotorCommand
+	hub.SetMotorCommands(func(ctx context.Context, cmd protocol.MotorCommand) error {
+		got = append(got, cmd)
+		return nil
+	})
+
+	hub.handleCommand(c, []byte(`{"type": "motor", "head": {"yaw": 0.5, "pitch": -0.2}, "body_yaw": 0.1}`))
+	if counts := drain(c); counts["motor_ack"] != 1 {
+		t.Errorf("motor reply = %v", counts)
+	}
+	if len(got) != 1 || got[0].Head.Yaw != 0.5 || got[0].Head.Pitch != -0.2 || got[0].BodyYaw != 0.1 {
+		t.Errorf("expected the target passed through, got %+v", got)
+	}
+
+	hub.handleCommand(c, []byte(`{"type": "motor", "source_ts": -1}`))
+	if counts := drain(c); counts["error"] != 1 || len(got) != 1 {
+		t.Errorf("invalid motor command: reply = %v, commands = %d", counts, len(got))
+	}
+}
+
+func TestServer_ShutdownClosesWebSockets(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.app.Listener(ln)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.WSHub().Run(ctx)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/api/audio/doa/stream", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.WSHub().ClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	server.WSHub().Publish("transcript", "last words")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer shutdownCancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- server.Shutdown(shutdownCtx) }()
+
+	// Queued events arrive before the close frame, which gorilla answers
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var gotEvent bool
+	for {
+		var msg Message
+		err := conn.ReadJSON(&msg)
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+				t.Fatalf("read error = %v, want a going-away close", err)
+			}
+			break
+		}
+		gotEvent = gotEvent || msg.Type == "transcript"
+	}
+	if !gotEvent {
+		t.Error("queued event was not delivered before the close")
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if n := server.WSHub().ClientCount(); n != 0 {
+		t.Errorf("%d clients left after shutdown", n)
+	}
+}
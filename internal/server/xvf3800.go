@@ -0,0 +1,168 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/teslashibe/go-eva/internal/xvf3800"
+)
+
+// SetXVFControl attaches the XVF3800 parameter interface for /api/xvf3800
+func (s *Server) SetXVFControl(ctrl xvf3800.ParamController, allow *xvf3800.Allowlist) {
+	s.xvf = ctrl
+	s.xvfAllow = allow
+}
+
+// SetXVFProfiles attaches the XVF3800 profiles for /api/xvf3800/profile
+func (s *Server) SetXVFProfiles(profiles *xvf3800.Profiles) {
+	s.xvfProfiles = profiles
+}
+
+// XVFParamRequest reads (no values) or writes (values set) one parameter
+type XVFParamRequest struct {
+	ResID  uint16            `json:"resid"`
+	CmdID  uint8             `json:"cmdid"`
+	Type   xvf3800.ParamType `json:"type,omitempty"` // must match the allowlist if set
+	Values []float64         `json:"values,omitempty"`
+}
+
+// xvfParamsHandler lists the allowlisted parameters
+func (s *Server) xvfParamsHandler(c *fiber.Ctx) error {
+	if s.xvf == nil || s.xvfAllow == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "XVF3800 control not available",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"params": s.xvfAllow.Params(),
+	})
+}
+
+// xvfParamHandler reads or writes an allowlisted parameter
+func (s *Server) xvfParamHandler(c *fiber.Ctx) error {
+	if s.xvf == nil || s.xvfAllow == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "XVF3800 control not available",
+		})
+	}
+
+	var req XVFParamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	param, ok := s.xvfAllow.Lookup(req.ResID, req.CmdID)
+	if !ok {
+		return c.Status(403).JSON(fiber.Map{"error": "parameter not in allowlist"})
+	}
+	if req.Type != "" && req.Type != param.Type {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "type mismatch: parameter is " + string(param.Type),
+		})
+	}
+
+	if req.Values == nil {
+		values, err := s.xvf.ReadParam(c.Context(), param)
+		if err != nil {
+			return c.Status(502).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"param":  param,
+			"values": values,
+		})
+	}
+
+	if !param.Writable {
+		return c.Status(403).JSON(fiber.Map{"error": "parameter is read-only"})
+	}
+
+	// Audit every write attempt, successful or not
+	err := s.xvf.WriteParam(c.Context(), param, req.Values)
+	s.logger.InfoContext(c.UserContext(), "xvf3800 param write",
+		"audit", true,
+		"param", param.Name,
+		"resid", param.ResID,
+		"cmdid", param.CmdID,
+		"values", req.Values,
+		"remote", c.IP(),
+		"ok", err == nil,
+	)
+	if s.history != nil {
+		s.history.RecordEvent("xvf3800_param_write", fiber.Map{
+			"param":  param.Name,
+			"resid":  param.ResID,
+			"cmdid":  param.CmdID,
+			"values": req.Values,
+			"remote": c.IP(),
+			"ok":     err == nil,
+		})
+	}
+	if err != nil {
+		return c.Status(502).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "written",
+		"param":  param,
+		"values": req.Values,
+	})
+}
+
+// xvfProfilesHandler lists the profiles and the one last applied
+func (s *Server) xvfProfilesHandler(c *fiber.Ctx) error {
+	if s.xvfProfiles == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "XVF3800 profiles not available",
+		})
+	}
+
+	active, appliedAt := s.xvfProfiles.Active()
+	resp := fiber.Map{
+		"profiles": s.xvfProfiles.List(),
+		"active":   active,
+	}
+	if active != "" {
+		resp["applied_at"] = appliedAt
+	}
+	return c.JSON(resp)
+}
+
+// xvfProfileHandler applies a profile's settings to the device
+func (s *Server) xvfProfileHandler(c *fiber.Ctx) error {
+	if s.xvfProfiles == nil {
+		return c.Status(503).JSON(fiber.Map{
+			"error": "XVF3800 profiles not available",
+		})
+	}
+
+	name := c.Params("name")
+	err := s.xvfProfiles.Apply(c.Context(), name)
+	if errors.Is(err, xvf3800.ErrUnknownProfile) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Audit every apply attempt, like single parameter writes
+	s.logger.InfoContext(c.UserContext(), "xvf3800 profile apply",
+		"audit", true,
+		"profile", name,
+		"remote", c.IP(),
+		"ok", err == nil,
+	)
+	if s.history != nil {
+		s.history.RecordEvent("xvf3800_profile_apply", fiber.Map{
+			"profile": name,
+			"remote":  c.IP(),
+			"ok":      err == nil,
+		})
+	}
+	if err != nil {
+		return c.Status(502).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "applied",
+		"profile": name,
+	})
+}
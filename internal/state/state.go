@@ -0,0 +1,213 @@
+// Package state aggregates subsystem statistics into periodic robot state
+// snapshots for the cloud and /api/state
+package state
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Config holds aggregator configuration
+type Config struct {
+	Interval      time.Duration // Snapshot publishing period (0 = on demand only)
+	SourceTimeout time.Duration // Limit for gatherers that make network calls
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Interval:      5 * time.Second,
+		SourceTimeout: time.Second,
+	}
+}
+
+// Gatherer returns the current state of one subsystem
+type Gatherer func(ctx context.Context) interface{}
+
+// TopicSnapshots carries periodic state snapshots on the event bus
+var TopicSnapshots = bus.NewTopic[protocol.StateData]("state.snapshot")
+
+// Aggregator collects subsystem state into snapshots
+type Aggregator struct {
+	cfg       Config
+	logger    *slog.Logger
+	version   string
+	startTime time.Time
+
+	mu      sync.Mutex
+	sources map[string]Gatherer
+	onState func(protocol.StateData)
+	pose    func() *protocol.PoseState
+	cpu     cpuSampler
+
+	// Stats
+	published atomic.Uint64
+}
+
+// NewAggregator creates a state aggregator
+func NewAggregator(cfg Config, version string, logger *slog.Logger) *Aggregator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.SourceTimeout <= 0 {
+		cfg.SourceTimeout = DefaultConfig().SourceTimeout
+	}
+
+	return &Aggregator{
+		cfg:       cfg,
+		logger:    logger,
+		version:   version,
+		startTime: time.Now(),
+		sources:   make(map[string]Gatherer),
+	}
+}
+
+// AddSource registers a named subsystem; a nil result omits it from snapshots
+func (a *Aggregator) AddSource(name string, gather Gatherer) {
+	a.mu.Lock()
+	a.sources[name] = gather
+	a.mu.Unlock()
+}
+
+// SetPose sets where snapshots read the robot pose; a nil pose is omitted
+func (a *Aggregator) SetPose(pose func() *protocol.PoseState) {
+	a.mu.Lock()
+	a.pose = pose
+	a.mu.Unlock()
+}
+
+// OnState sets the callback for periodic snapshots
+func (a *Aggregator) OnState(callback func(protocol.StateData)) {
+	a.mu.Lock()
+	a.onState = callback
+	a.mu.Unlock()
+}
+
+// PublishTo routes periodic snapshots to the event bus, replacing OnState
+func (a *Aggregator) PublishTo(b *bus.Bus) {
+	a.OnState(bus.Publisher(b, TopicSnapshots))
+}
+
+// Snapshot gathers the current state of every source
+func (a *Aggregator) Snapshot(ctx context.Context) protocol.StateData {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.SourceTimeout)
+	defer cancel()
+
+	a.mu.Lock()
+	names := make([]string, 0, len(a.sources))
+	for name := range a.sources {
+		names = append(names, name)
+	}
+	sources := make(map[string]Gatherer, len(a.sources))
+	for name, gather := range a.sources {
+		sources[name] = gather
+	}
+	pose := a.pose
+	a.mu.Unlock()
+	sort.Strings(names)
+
+	// Gather concurrently so one slow source doesn't hold up the rest
+	results := make([]interface{}, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, gather Gatherer) {
+			defer wg.Done()
+			results[i] = gather(ctx)
+		}(i, sources[name])
+	}
+	wg.Wait()
+
+	components := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		if results[i] != nil {
+			components[name] = results[i]
+		}
+	}
+
+	snapshot := protocol.StateData{
+		Version:       a.version,
+		UptimeSeconds: int64(time.Since(a.startTime).Seconds()),
+		System:        a.system(),
+		Components:    components,
+	}
+	if pose != nil {
+		snapshot.Pose = pose()
+	}
+	return snapshot
+}
+
+// system reports host and process resource usage
+func (a *Aggregator) system() protocol.SystemState {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	sys := protocol.SystemState{
+		HeapAllocBytes: mem.HeapAlloc,
+		Goroutines:     runtime.NumGoroutine(),
+	}
+
+	a.mu.Lock()
+	sys.CPUPercent = a.cpu.sample()
+	a.mu.Unlock()
+
+	if total, available, err := readMemInfo(); err == nil {
+		sys.MemTotalBytes = total
+		sys.MemAvailableBytes = available
+	}
+	return sys
+}
+
+// Run publishes a snapshot every interval until ctx is cancelled
+func (a *Aggregator) Run(ctx context.Context) {
+	if a.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot := a.Snapshot(ctx)
+
+			a.mu.Lock()
+			callback := a.onState
+			a.mu.Unlock()
+
+			if callback != nil {
+				callback(snapshot)
+			}
+			a.published.Add(1)
+		}
+	}
+}
+
+// Stats contains aggregator statistics
+type Stats struct {
+	Published uint64 `json:"published"`
+	Sources   int    `json:"sources"`
+}
+
+// GetStats returns aggregator statistics
+func (a *Aggregator) GetStats() Stats {
+	a.mu.Lock()
+	sources := len(a.sources)
+	a.mu.Unlock()
+
+	return Stats{
+		Published: a.published.Load(),
+		Sources:   sources,
+	}
+}
@@ -0,0 +1,102 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func TestSnapshot(t *testing.T) {
+	a := NewAggregator(DefaultConfig(), "1.2.3", nil)
+	a.AddSource("tracker", func(ctx context.Context) interface{} {
+		return map[string]int{"poll_count": 7}
+	})
+	a.AddSource("camera", func(ctx context.Context) interface{} { return nil })
+	a.AddSource("pollen", func(ctx context.Context) interface{} {
+		<-ctx.Done() // Unreachable daemon: bounded by SourceTimeout
+		return map[string]bool{"reachable": false}
+	})
+
+	start := time.Now()
+	snap := a.Snapshot(context.Background())
+	if time.Since(start) > 3*time.Second {
+		t.Error("slow source should be bounded by SourceTimeout")
+	}
+
+	if snap.Version != "1.2.3" {
+		t.Errorf("Version = %q, want 1.2.3", snap.Version)
+	}
+	if _, ok := snap.Components["tracker"]; !ok {
+		t.Error("expected tracker component")
+	}
+	if _, ok := snap.Components["pollen"]; !ok {
+		t.Error("expected pollen component")
+	}
+	if _, ok := snap.Components["camera"]; ok {
+		t.Error("nil source should be omitted")
+	}
+	if snap.System.Goroutines == 0 || snap.System.HeapAllocBytes == 0 {
+		t.Errorf("expected runtime stats, got %+v", snap.System)
+	}
+}
+
+func TestSnapshotPose(t *testing.T) {
+	a := NewAggregator(DefaultConfig(), "dev", nil)
+	if snap := a.Snapshot(context.Background()); snap.Pose != nil {
+		t.Errorf("Pose = %+v without a pose source, want nil", snap.Pose)
+	}
+
+	a.SetPose(func() *protocol.PoseState {
+		return &protocol.PoseState{Head: protocol.HeadTarget{Yaw: 0.3}, AgeMs: 40}
+	})
+	if snap := a.Snapshot(context.Background()); snap.Pose == nil || snap.Pose.Head.Yaw != 0.3 {
+		t.Errorf("Pose = %+v, want yaw 0.3", snap.Pose)
+	}
+}
+
+func TestRunPublishes(t *testing.T) {
+	a := NewAggregator(Config{Interval: 10 * time.Millisecond}, "dev", nil)
+	got := make(chan protocol.StateData, 1)
+	a.OnState(func(s protocol.StateData) {
+		select {
+		case got <- s:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.Run(ctx)
+
+	select {
+	case s := <-got:
+		if s.Version != "dev" {
+			t.Errorf("Version = %q, want dev", s.Version)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no snapshot published")
+	}
+}
+
+func TestParseProc(t *testing.T) {
+	idle, total, err := parseCPUTimes(strings.NewReader(
+		"cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 50 0 25 400 25 0 0 0 0 0\n"))
+	if err != nil {
+		t.Fatalf("parseCPUTimes() error = %v", err)
+	}
+	if idle != 850 || total != 1000 {
+		t.Errorf("idle, total = %d, %d; want 850, 1000", idle, total)
+	}
+
+	memTotal, avail, err := parseMemInfo(strings.NewReader(
+		"MemTotal:        3884376 kB\nMemFree:          123456 kB\nMemAvailable:    2000000 kB\n"))
+	if err != nil {
+		t.Fatalf("parseMemInfo() error = %v", err)
+	}
+	if memTotal != 3884376*1024 || avail != 2000000*1024 {
+		t.Errorf("total, available = %d, %d", memTotal, avail)
+	}
+}
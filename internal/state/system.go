@@ -0,0 +1,115 @@
+package state
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Paths read for host resource usage (Linux)
+const (
+	procStat    = "/proc/stat"
+	procMemInfo = "/proc/meminfo"
+)
+
+// cpuSampler computes CPU busy percentage between successive samples
+type cpuSampler struct {
+	idle  uint64
+	total uint64
+}
+
+// sample returns the busy percentage since the previous sample (0 on the
+// first call or where /proc is unavailable)
+func (c *cpuSampler) sample() float64 {
+	f, err := os.Open(procStat)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	idle, total, err := parseCPUTimes(f)
+	if err != nil {
+		return 0
+	}
+
+	prevIdle, prevTotal := c.idle, c.total
+	c.idle, c.total = idle, total
+	if prevTotal == 0 || total <= prevTotal {
+		return 0
+	}
+
+	busy := float64((total-prevTotal)-(idle-prevIdle)) / float64(total-prevTotal)
+	return busy * 100
+}
+
+// parseCPUTimes reads the aggregate "cpu" line of /proc/stat, counting
+// iowait as idle
+func parseCPUTimes(r io.Reader) (idle, total uint64, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("parse cpu field %q: %w", field, err)
+			}
+			total += v
+			if i == 3 || i == 4 { // idle, iowait
+				idle += v
+			}
+		}
+		return idle, total, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	return 0, 0, fmt.Errorf("no cpu line")
+}
+
+// readMemInfo returns total and available host memory in bytes
+func readMemInfo() (total, available uint64, err error) {
+	f, err := os.Open(procMemInfo)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	return parseMemInfo(f)
+}
+
+// parseMemInfo reads MemTotal and MemAvailable (kB) from /proc/meminfo
+func parseMemInfo(r io.Reader) (total, available uint64, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var dst *uint64
+		switch fields[0] {
+		case "MemTotal:":
+			dst = &total
+		case "MemAvailable:":
+			dst = &available
+		default:
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse %s: %w", fields[0], err)
+		}
+		*dst = kb * 1024
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if total == 0 {
+		return 0, 0, fmt.Errorf("no MemTotal")
+	}
+	return total, available, nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/emotion"
+	"github.com/teslashibe/go-eva/internal/privacy"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// History keeps tracker results, speech segments and events: the newest
+// Config.Window results at full rate for trend lines and stream resumption,
+// and rows downsampled to Config.SampleInterval for /api/history and
+// long-horizon analytics. Backends differ in where rows end up: Memory keeps
+// them in RAM, Store in SQLite, and Remote uploads them to the cloud while
+// keeping a local copy for queries. Window keeps no rows, and
+// WithRecording extends the full-rate results to a disk recording.
+type History interface {
+	// Record adds a tracker result
+	Record(result doa.Result)
+	// Results returns full-rate results with from <= timestamp <= to,
+	// oldest first (limit <= 0 = all in range)
+	Results(from, to time.Time, limit int) ([]doa.Result, error)
+	// RecordEvent adds an event with a JSON payload
+	RecordEvent(eventType string, data interface{}) error
+	// Query returns rows from one table as JSON objects, oldest first
+	Query(ctx context.Context, q Query) ([]map[string]interface{}, error)
+	// Flush writes or uploads buffered rows and applies retention
+	Flush(ctx context.Context) error
+	GetStats() Stats
+	// Close flushes buffered rows
+	Close() error
+}
+
+// History backends
+const (
+	BackendMemory = "memory"
+	BackendSQLite = "sqlite"
+	BackendRemote = "remote"
+	BackendWindow = "window" // Full-rate window only; see NewWindow
+)
+
+// Uploader sends a batch of history rows to the cloud
+type Uploader func(protocol.HistoryData) error
+
+// New opens the backend named by cfg.Backend (sqlite if empty). upload is
+// only used, and required, by the remote backend.
+func New(cfg Config, upload Uploader, logger *slog.Logger) (History, error) {
+	switch cfg.Backend {
+	case "", BackendSQLite:
+		s, err := Open(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendMemory:
+		return NewMemory(cfg), nil
+	case BackendRemote:
+		if upload == nil {
+			return nil, fmt.Errorf("remote history needs the cloud link")
+		}
+		return NewRemote(cfg, upload, logger), nil
+	}
+	return nil, fmt.Errorf("unknown history backend %q", cfg.Backend)
+}
+
+// Run records tracker results into h until ctx is cancelled or the channel
+// closes, flushing every cfg.FlushInterval and once more on the way out
+func Run(ctx context.Context, h History, cfg Config, results <-chan doa.Result, logger *slog.Logger) {
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = DefaultConfig().FlushInterval
+	}
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.Flush(context.Background())
+			return
+		case result, ok := <-results:
+			if !ok {
+				h.Flush(context.Background())
+				return
+			}
+			h.Record(result)
+		case <-ticker.C:
+			if err := h.Flush(ctx); err != nil {
+				logger.Warn("history flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// RecordEvents stores the bus events worth reviewing later in h's events
+// table until ctx ends: utterance boundaries (utterance_start,
+// utterance_end), zone changes (zone_change), barge-ins (barge_in), privacy
+// toggles (privacy_change) and emotions (emotion_started,
+// emotion_finished). Each row's data is the event as published.
+func RecordEvents(ctx context.Context, h History, b *bus.Bus, logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	record := func(eventType string, data interface{}) {
+		if err := h.RecordEvent(eventType, data); err != nil {
+			logger.Warn("history event not recorded", "type", eventType, "error", err)
+		}
+	}
+
+	bus.Handle(ctx, b, doa.TopicUtterances, 0, func(ev doa.UtteranceEvent) {
+		record("utterance_"+string(ev.Type), ev)
+	})
+	bus.Handle(ctx, b, doa.TopicZones, 0, func(ev doa.ZoneEvent) {
+		record("zone_change", ev)
+	})
+	bus.Handle(ctx, b, doa.TopicBargeIn, 0, func(ev doa.BargeInEvent) {
+		record("barge_in", ev)
+	})
+	bus.Handle(ctx, b, privacy.TopicChanges, 0, func(status privacy.Status) {
+		record("privacy_change", status)
+	})
+	bus.Handle(ctx, b, emotion.TopicEvents, 0, func(ev emotion.Event) {
+		record("emotion_"+string(ev.Type), ev)
+	})
+}
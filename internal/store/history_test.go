@@ -0,0 +1,222 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/privacy"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.SampleInterval = 100 * time.Millisecond
+	return cfg
+}
+
+func recordReadings(h History, start time.Time, n int) {
+	for i := 0; i < n; i++ {
+		h.Record(doa.Result{
+			Reading: doa.Reading{
+				Angle:     0.5,
+				Timestamp: start.Add(time.Duration(i) * 50 * time.Millisecond),
+			},
+			SmoothedAngle: 0.5,
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cfg := testConfig()
+
+	cfg.Backend = BackendMemory
+	if h, err := New(cfg, nil, nil); err != nil {
+		t.Errorf("New(memory) error = %v", err)
+	} else if _, ok := h.(*Memory); !ok {
+		t.Errorf("New(memory) = %T, want *Memory", h)
+	}
+
+	cfg.Backend = BackendRemote
+	if _, err := New(cfg, nil, nil); err == nil {
+		t.Error("expected error for remote backend without an uploader")
+	}
+
+	cfg.Backend = "postgres"
+	if _, err := New(cfg, nil, nil); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestMemory_RecordAndQuery(t *testing.T) {
+	m := NewMemory(testConfig())
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Second)
+	recordReadings(m, start, 10)
+
+	rows, err := m.Query(ctx, Query{Table: TableReadings, From: start.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	// Same shape and downsampling as the SQLite backend
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 downsampled rows, got %d", len(rows))
+	}
+	if rows[0]["smoothed_angle"].(float64) != 0.5 || rows[0]["speaking"].(float64) != 0 {
+		t.Errorf("unexpected row: %v", rows[0])
+	}
+
+	rows, err = m.Query(ctx, Query{Table: TableReadings, From: start.Add(-time.Second), Limit: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("expected limit of 2 rows, got %d", len(rows))
+	}
+}
+
+func TestMemory_MaxRows(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxRows = 3
+	m := NewMemory(cfg)
+
+	start := time.Now().Add(-time.Second)
+	recordReadings(m, start, 10)
+
+	rows, err := m.Query(context.Background(), Query{Table: TableReadings, From: start.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	// The newest rows are kept
+	if got := int64(rows[0]["ts"].(float64)); got != start.Add(200*time.Millisecond).UnixMilli() {
+		t.Errorf("oldest kept row at %d, want the 3rd newest", got)
+	}
+}
+
+func TestMemory_Retention(t *testing.T) {
+	cfg := testConfig()
+	cfg.Retention = time.Minute
+	m := NewMemory(cfg)
+	ctx := context.Background()
+
+	recordReadings(m, time.Now().Add(-2*time.Minute), 1)
+	recordReadings(m, time.Now(), 1)
+
+	if err := m.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if stats := m.GetStats(); stats.Rows != 1 || stats.Backend != BackendMemory {
+		t.Errorf("unexpected stats after prune: %+v", stats)
+	}
+}
+
+func TestSampler_SegmentAcrossWrap(t *testing.T) {
+	var s sampler
+	start := time.Now()
+	for i, angle := range []float64{math.Pi - 0.1, -math.Pi + 0.1, math.Pi - 0.05, -math.Pi + 0.05} {
+		s.add(doa.Result{
+			Reading:         doa.Reading{Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond)},
+			SmoothedAngle:   angle,
+			SpeakingLatched: true,
+		})
+	}
+	_, segment := s.add(doa.Result{Reading: doa.Reading{Timestamp: start.Add(time.Second)}})
+	if segment == nil {
+		t.Fatal("no segment")
+	}
+	if math.Abs(segment.AvgAngle) < math.Pi-0.01 {
+		t.Errorf("avg angle = %.3f, want ±π behind the robot, not the front", segment.AvgAngle)
+	}
+}
+
+func TestRecordEvents(t *testing.T) {
+	m := NewMemory(testConfig())
+	b := bus.New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	RecordEvents(ctx, m, b, nil)
+
+	bus.Publish(b, doa.TopicUtterances, doa.UtteranceEvent{Type: doa.UtteranceStart, ID: 3})
+	bus.Publish(b, doa.TopicZones, doa.ZoneEvent{From: "left", To: "front"})
+	bus.Publish(b, privacy.TopicChanges, privacy.Status{Enabled: true, Source: "button"})
+
+	var rows []map[string]interface{}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rows) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		rows, _ = m.Query(ctx, Query{Table: TableEvents, From: time.Now().Add(-time.Minute)})
+	}
+	types := map[interface{}]bool{}
+	for _, row := range rows {
+		types[row["type"]] = true
+	}
+	for _, want := range []string{"utterance_start", "zone_change", "privacy_change"} {
+		if !types[want] {
+			t.Errorf("no %s event in %v", want, rows)
+		}
+	}
+}
+
+func TestRemote_Upload(t *testing.T) {
+	var uploads []protocol.HistoryData
+	fail := true
+	upload := func(data protocol.HistoryData) error {
+		if fail {
+			return errors.New("offline")
+		}
+		uploads = append(uploads, data)
+		return nil
+	}
+
+	r := NewRemote(testConfig(), upload, nil)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Second)
+	recordReadings(r, start, 4)
+	if err := r.RecordEvent("cloud_disconnect", map[string]string{"reason": "test"}); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+
+	// A failed upload keeps the rows for the next flush
+	if err := r.Flush(ctx); err == nil {
+		t.Fatal("expected upload error")
+	}
+	if stats := r.GetStats(); stats.PendingRows != 3 || stats.WriteErrors != 1 {
+		t.Errorf("unexpected stats after failed upload: %+v", stats)
+	}
+
+	fail = false
+	recordReadings(r, start.Add(time.Second), 1)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(uploads) != 1 {
+		t.Fatalf("expected 1 upload, got %d", len(uploads))
+	}
+	if len(uploads[0].Readings) != 3 || len(uploads[0].Events) != 1 {
+		t.Errorf("unexpected upload: %+v", uploads[0])
+	}
+	if stats := r.GetStats(); stats.PendingRows != 0 || stats.RowsWritten != 4 {
+		t.Errorf("unexpected stats after upload: %+v", stats)
+	}
+
+	// Queries are answered locally
+	rows, err := r.Query(ctx, Query{Table: TableEvents, From: start.Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 1 || rows[0]["type"] != "cloud_disconnect" {
+		t.Errorf("unexpected events: %v", rows)
+	}
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Memory is the in-memory history backend. Nothing survives a restart; each
+// table keeps its newest Config.MaxRows rows, and Flush drops rows past
+// Config.Retention.
+type Memory struct {
+	cfg    Config
+	window *resultWindow
+
+	mu       sync.Mutex
+	sampler  sampler
+	readings []protocol.HistoryReading
+	segments []protocol.HistorySegment
+	events   []protocol.HistoryEvent
+
+	rowsWritten atomic.Uint64
+	pruneRuns   atomic.Uint64
+}
+
+// NewMemory creates an empty in-memory history
+func NewMemory(cfg Config) *Memory {
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultConfig().MaxRows
+	}
+	return &Memory{
+		cfg:     cfg,
+		window:  newResultWindow(cfg.Window),
+		sampler: sampler{interval: cfg.SampleInterval},
+	}
+}
+
+// Record adds a tracker result, downsampling readings and tracking speech segments
+func (m *Memory) Record(result doa.Result) {
+	m.window.push(result)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.insertLocked(m.sampler.rows(result))
+}
+
+// Results returns results from the in-memory window
+func (m *Memory) Results(from, to time.Time, limit int) ([]doa.Result, error) {
+	return m.window.results(from, to, limit), nil
+}
+
+// RecordEvent adds an event with a JSON payload
+func (m *Memory) RecordEvent(eventType string, data interface{}) error {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+	m.insert(protocol.HistoryData{Events: []protocol.HistoryEvent{event}})
+	return nil
+}
+
+// insert adds rows that were sampled elsewhere
+func (m *Memory) insert(batch protocol.HistoryData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.insertLocked(batch)
+}
+
+func (m *Memory) insertLocked(batch protocol.HistoryData) {
+	m.readings = capped(m.readings, m.cfg.MaxRows, batch.Readings...)
+	m.segments = capped(m.segments, m.cfg.MaxRows, batch.Segments...)
+	m.events = capped(m.events, m.cfg.MaxRows, batch.Events...)
+	m.rowsWritten.Add(uint64(batch.Len()))
+}
+
+// Flush drops rows past the retention window; rows are stored as they are
+// recorded, so there is nothing to write
+func (m *Memory) Flush(ctx context.Context) error {
+	if m.cfg.Retention <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-m.cfg.Retention).UnixMilli()
+
+	m.mu.Lock()
+	m.readings = dropBefore(m.readings, func(r protocol.HistoryReading) bool { return r.Timestamp >= cutoff })
+	m.segments = dropBefore(m.segments, func(s protocol.HistorySegment) bool { return s.End >= cutoff })
+	m.events = dropBefore(m.events, func(e protocol.HistoryEvent) bool { return e.Timestamp >= cutoff })
+	m.mu.Unlock()
+
+	m.pruneRuns.Add(1)
+	return nil
+}
+
+// Query returns rows from the requested table as JSON objects, in the same
+// shape as the SQLite backend's
+func (m *Memory) Query(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	from, to := q.From.UnixMilli(), q.To.UnixMilli()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := []map[string]interface{}{}
+	add := func(ts int64, row func() map[string]interface{}) bool {
+		if ts >= from && ts <= to {
+			rows = append(rows, row())
+		}
+		return len(rows) < q.Limit
+	}
+	switch q.Table {
+	case TableReadings:
+		for _, r := range m.readings {
+			if !add(r.Timestamp, func() map[string]interface{} { return readingRow(r) }) {
+				break
+			}
+		}
+	case TableSegments:
+		for _, s := range m.segments {
+			if !add(s.Start, func() map[string]interface{} { return segmentRow(s) }) {
+				break
+			}
+		}
+	case TableEvents:
+		for _, e := range m.events {
+			if !add(e.Timestamp, func() map[string]interface{} { return eventRow(e) }) {
+				break
+			}
+		}
+	}
+	return rows, nil
+}
+
+// len returns the rows held across all tables
+func (m *Memory) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.readings) + len(m.segments) + len(m.events)
+}
+
+// GetStats returns store statistics
+func (m *Memory) GetStats() Stats {
+	return Stats{
+		Backend:     BackendMemory,
+		RowsWritten: m.rowsWritten.Load(),
+		PruneRuns:   m.pruneRuns.Load(),
+		Rows:        m.len(),
+		Results:     m.window.len(),
+	}
+}
+
+// Close is a no-op; the rows go with the process
+func (m *Memory) Close() error {
+	return nil
+}
+
+// capped appends add to rows, dropping the oldest rows beyond max
+func capped[T any](rows []T, max int, add ...T) []T {
+	rows = append(rows, add...)
+	if over := len(rows) - max; over > 0 {
+		rows = slices.Delete(rows, 0, over)
+	}
+	return rows
+}
+
+// dropBefore drops the leading rows that keep rejects; rows are in time order
+func dropBefore[T any](rows []T, keep func(T) bool) []T {
+	i := slices.IndexFunc(rows, keep)
+	if i < 0 {
+		return rows[:0]
+	}
+	return slices.Delete(rows, 0, i)
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// Remote is the cloud history backend. Each Flush uploads the rows recorded
+// since the last one as a single history message; while the cloud is
+// unreachable the cloud link's offline queue holds them. The newest rows are
+// also kept in memory, as with Memory, so /api/history still answers locally.
+type Remote struct {
+	cfg    Config
+	upload Uploader
+	logger *slog.Logger
+	local  *Memory
+
+	mu      sync.Mutex
+	sampler sampler
+	pending protocol.HistoryData // Rows not uploaded yet
+
+	rowsUploaded atomic.Uint64
+	uploadErrors atomic.Uint64
+}
+
+// NewRemote creates a history that uploads through upload
+func NewRemote(cfg Config, upload Uploader, logger *slog.Logger) *Remote {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	local := NewMemory(cfg)
+	return &Remote{
+		cfg:     local.cfg,
+		upload:  upload,
+		logger:  logger,
+		local:   local,
+		sampler: sampler{interval: cfg.SampleInterval},
+	}
+}
+
+// Record adds a tracker result, downsampling readings and tracking speech segments
+func (r *Remote) Record(result doa.Result) {
+	r.local.window.push(result)
+
+	r.mu.Lock()
+	batch := r.sampler.rows(result)
+	r.queueLocked(batch)
+	r.mu.Unlock()
+	r.local.insert(batch)
+}
+
+// Results returns results from the in-memory window
+func (r *Remote) Results(from, to time.Time, limit int) ([]doa.Result, error) {
+	return r.local.Results(from, to, limit)
+}
+
+// RecordEvent adds an event with a JSON payload
+func (r *Remote) RecordEvent(eventType string, data interface{}) error {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+	batch := protocol.HistoryData{Events: []protocol.HistoryEvent{event}}
+
+	r.mu.Lock()
+	r.queueLocked(batch)
+	r.mu.Unlock()
+	r.local.insert(batch)
+	return nil
+}
+
+// queueLocked adds rows to the next upload, dropping the oldest beyond
+// Config.MaxRows per table if uploads keep failing
+func (r *Remote) queueLocked(batch protocol.HistoryData) {
+	r.pending.Readings = capped(r.pending.Readings, r.cfg.MaxRows, batch.Readings...)
+	r.pending.Segments = capped(r.pending.Segments, r.cfg.MaxRows, batch.Segments...)
+	r.pending.Events = capped(r.pending.Events, r.cfg.MaxRows, batch.Events...)
+}
+
+// Flush uploads the pending rows and applies retention to the local copy.
+// Rows that fail to upload are kept for the next Flush.
+func (r *Remote) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = protocol.HistoryData{}
+	r.mu.Unlock()
+
+	r.local.Flush(ctx)
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := r.upload(batch); err != nil {
+		r.uploadErrors.Add(1)
+		r.mu.Lock()
+		later := r.pending
+		r.pending = protocol.HistoryData{}
+		r.queueLocked(batch)
+		r.queueLocked(later)
+		r.mu.Unlock()
+		return fmt.Errorf("upload history: %w", err)
+	}
+	r.rowsUploaded.Add(uint64(batch.Len()))
+	return nil
+}
+
+// Query answers from the rows kept in memory
+func (r *Remote) Query(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	return r.local.Query(ctx, q)
+}
+
+// GetStats returns store statistics; RowsWritten counts uploaded rows
+func (r *Remote) GetStats() Stats {
+	r.mu.Lock()
+	pending := r.pending.Len()
+	r.mu.Unlock()
+
+	return Stats{
+		Backend:     BackendRemote,
+		RowsWritten: r.rowsUploaded.Load(),
+		WriteErrors: r.uploadErrors.Load(),
+		PendingRows: pending,
+		PruneRuns:   r.local.pruneRuns.Load(),
+		Rows:        r.local.len(),
+		Results:     r.local.window.len(),
+	}
+}
+
+// Close uploads any pending rows
+func (r *Remote) Close() error {
+	return r.Flush(context.Background())
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+// sampler downsamples tracker results into readings and turns
+// SpeakingLatched transitions into speech segments; every backend records
+// through one so they store the same rows
+type sampler struct {
+	interval time.Duration
+	lastAt   time.Time
+
+	inSegment     bool
+	segStart      time.Time
+	segSin        float64 // Angles are summed as unit vectors for a circular mean
+	segCos        float64
+	segSamples    int
+	segPeakEnergy float64
+}
+
+// add returns the reading to store for result, if one is due, and the
+// segment it closes, if any
+func (s *sampler) add(result doa.Result) (*protocol.HistoryReading, *protocol.HistorySegment) {
+	ts := result.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	segment := s.segment(result, ts)
+
+	if !s.lastAt.IsZero() && ts.Sub(s.lastAt) < s.interval {
+		return nil, segment
+	}
+	s.lastAt = ts
+
+	return &protocol.HistoryReading{
+		Timestamp:       ts.UnixMilli(),
+		Angle:           result.Angle,
+		SmoothedAngle:   result.SmoothedAngle,
+		Confidence:      result.Confidence,
+		Speaking:        result.Speaking,
+		SpeakingLatched: result.SpeakingLatched,
+		TotalEnergy:     result.TotalEnergy,
+		EstX:            result.EstX,
+		EstY:            result.EstY,
+	}, segment
+}
+
+// rows returns what add stores for result as a batch
+func (s *sampler) rows(result doa.Result) protocol.HistoryData {
+	var batch protocol.HistoryData
+	reading, segment := s.add(result)
+	if reading != nil {
+		batch.Readings = append(batch.Readings, *reading)
+	}
+	if segment != nil {
+		batch.Segments = append(batch.Segments, *segment)
+	}
+	return batch
+}
+
+// segment tracks latched speech, returning the segment result ends
+func (s *sampler) segment(result doa.Result, ts time.Time) *protocol.HistorySegment {
+	if result.SpeakingLatched {
+		if !s.inSegment {
+			s.inSegment = true
+			s.segStart = ts
+			s.segSin, s.segCos = 0, 0
+			s.segSamples = 0
+			s.segPeakEnergy = 0
+		}
+		s.segSin += math.Sin(result.SmoothedAngle)
+		s.segCos += math.Cos(result.SmoothedAngle)
+		s.segSamples++
+		if result.TotalEnergy > s.segPeakEnergy {
+			s.segPeakEnergy = result.TotalEnergy
+		}
+		return nil
+	}
+
+	if !s.inSegment {
+		return nil
+	}
+	s.inSegment = false
+
+	// Circular mean, so angles either side of ±π average to π, not 0
+	avgAngle := 0.0
+	if s.segSamples > 0 {
+		avgAngle = math.Atan2(s.segSin, s.segCos)
+	}
+	return &protocol.HistorySegment{
+		Start:      s.segStart.UnixMilli(),
+		End:        ts.UnixMilli(),
+		DurationMs: ts.Sub(s.segStart).Milliseconds(),
+		AvgAngle:   avgAngle,
+		PeakEnergy: s.segPeakEnergy,
+	}
+}
+
+// newEvent builds an event row stamped now
+func newEvent(eventType string, data interface{}) (protocol.HistoryEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return protocol.HistoryEvent{}, fmt.Errorf("marshal event: %w", err)
+	}
+	return protocol.HistoryEvent{
+		Timestamp: time.Now().UnixMilli(),
+		Type:      eventType,
+		Data:      payload,
+	}, nil
+}
+
+// Rows returned by Query have the same shape for every backend: SQLite's
+// columns, with numbers as float64, booleans as 0/1 and event data as a
+// JSON string
+
+func readingRow(r protocol.HistoryReading) map[string]interface{} {
+	return map[string]interface{}{
+		"ts":               float64(r.Timestamp),
+		"angle":            r.Angle,
+		"smoothed_angle":   r.SmoothedAngle,
+		"confidence":       r.Confidence,
+		"speaking":         float64(boolToInt(r.Speaking)),
+		"speaking_latched": float64(boolToInt(r.SpeakingLatched)),
+		"total_energy":     r.TotalEnergy,
+		"est_x":            r.EstX,
+		"est_y":            r.EstY,
+	}
+}
+
+func segmentRow(s protocol.HistorySegment) map[string]interface{} {
+	return map[string]interface{}{
+		"start_ts":    float64(s.Start),
+		"end_ts":      float64(s.End),
+		"duration_ms": float64(s.DurationMs),
+		"avg_angle":   s.AvgAngle,
+		"peak_energy": s.PeakEnergy,
+	}
+}
+
+func eventRow(e protocol.HistoryEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"ts":   float64(e.Timestamp),
+		"type": e.Type,
+		"data": string(e.Data),
+	}
+}
@@ -0,0 +1,395 @@
+// Package store keeps a history of DOA readings, speech segments, and
+// events, in memory, in a local SQLite database, or uploaded to the cloud
+// (see History).
+//
+// The SQLite backend drives the sqlite3 command-line tool rather than
+// linking a database driver, which keeps the daemon pure Go (apart from
+// libusb) and matches how the audio bridge uses arecord/aplay.
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Config holds store configuration
+type Config struct {
+	Backend        string        // memory, sqlite (default) or remote
+	Path           string        // SQLite database file
+	SQLiteCmd      string        // sqlite3 binary (default: "sqlite3")
+	SampleInterval time.Duration // Minimum spacing between stored readings
+	FlushInterval  time.Duration // How often buffered rows are written
+	Retention      time.Duration // Rows older than this are deleted (0 = keep forever)
+	MaxRows        int           // Rows kept per table in memory (memory and remote backends), or waiting after failed SQLite writes
+	Window         int           // Newest results kept at full rate in memory
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Backend:        BackendSQLite,
+		Path:           "/var/lib/go-eva/history.db",
+		SQLiteCmd:      "sqlite3",
+		SampleInterval: 1 * time.Second,
+		FlushInterval:  5 * time.Second,
+		Retention:      7 * 24 * time.Hour,
+		MaxRows:        50000,
+		Window:         1200, // 60s at 20Hz
+	}
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS readings (
+	ts INTEGER NOT NULL,
+	angle REAL,
+	smoothed_angle REAL,
+	confidence REAL,
+	speaking INTEGER,
+	speaking_latched INTEGER,
+	total_energy REAL,
+	est_x REAL,
+	est_y REAL
+);
+CREATE INDEX IF NOT EXISTS readings_ts ON readings(ts);
+CREATE TABLE IF NOT EXISTS segments (
+	start_ts INTEGER NOT NULL,
+	end_ts INTEGER NOT NULL,
+	duration_ms INTEGER,
+	avg_angle REAL,
+	peak_energy REAL
+);
+CREATE INDEX IF NOT EXISTS segments_ts ON segments(start_ts);
+CREATE TABLE IF NOT EXISTS events (
+	ts INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	data TEXT
+);
+CREATE INDEX IF NOT EXISTS events_ts ON events(ts);
+`
+
+// Table names accepted by Query
+const (
+	TableReadings = "readings"
+	TableSegments = "segments"
+	TableEvents   = "events"
+)
+
+// Query selects rows from one table within a time range
+type Query struct {
+	Table string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// normalize checks the table and fills in the default end and limit
+func (q *Query) normalize() error {
+	switch q.Table {
+	case TableReadings, TableSegments, TableEvents:
+	default:
+		return fmt.Errorf("unknown table %q", q.Table)
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.Limit <= 0 || q.Limit > 10000 {
+		q.Limit = 1000
+	}
+	return nil
+}
+
+// Store is the SQLite history backend
+type Store struct {
+	cfg    Config
+	logger *slog.Logger
+
+	window *resultWindow
+
+	mu      sync.Mutex
+	pending []string // buffered INSERT statements
+	sampler sampler
+
+	// Stats
+	rowsWritten  atomic.Uint64
+	writeErrors  atomic.Uint64
+	pruneRuns    atomic.Uint64
+	lastPruneRun time.Time // Guarded by mu
+}
+
+// Open creates the database file (if needed) and initializes the schema
+func Open(cfg Config, logger *slog.Logger) (*Store, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.SQLiteCmd == "" {
+		cfg.SQLiteCmd = "sqlite3"
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultConfig().MaxRows
+	}
+
+	if _, err := exec.LookPath(cfg.SQLiteCmd); err != nil {
+		return nil, fmt.Errorf("sqlite3 not available: %w", err)
+	}
+
+	if dir := filepath.Dir(cfg.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create store dir: %w", err)
+		}
+	}
+
+	s := &Store{
+		cfg:     cfg,
+		logger:  logger,
+		window:  newResultWindow(cfg.Window),
+		sampler: sampler{interval: cfg.SampleInterval},
+	}
+
+	if err := s.exec(context.Background(), schema); err != nil {
+		return nil, fmt.Errorf("init schema: %w", err)
+	}
+
+	logger.Info("history store opened",
+		"path", cfg.Path,
+		"sample_interval", cfg.SampleInterval,
+		"retention", cfg.Retention,
+	)
+
+	return s, nil
+}
+
+// Record buffers a tracker result, downsampling readings and tracking speech segments
+func (s *Store) Record(result doa.Result) {
+	s.window.push(result)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reading, segment := s.sampler.add(result)
+	if segment != nil {
+		s.pending = append(s.pending, fmt.Sprintf(
+			"INSERT INTO segments VALUES (%d, %d, %d, %s, %s);",
+			segment.Start,
+			segment.End,
+			segment.DurationMs,
+			sqlReal(segment.AvgAngle),
+			sqlReal(segment.PeakEnergy),
+		))
+	}
+	if reading != nil {
+		s.pending = append(s.pending, fmt.Sprintf(
+			"INSERT INTO readings VALUES (%d, %s, %s, %s, %d, %d, %s, %s, %s);",
+			reading.Timestamp,
+			sqlReal(reading.Angle),
+			sqlReal(reading.SmoothedAngle),
+			sqlReal(reading.Confidence),
+			boolToInt(reading.Speaking),
+			boolToInt(reading.SpeakingLatched),
+			sqlReal(reading.TotalEnergy),
+			sqlReal(reading.EstX),
+			sqlReal(reading.EstY),
+		))
+	}
+}
+
+// Results returns results from the in-memory window
+func (s *Store) Results(from, to time.Time, limit int) ([]doa.Result, error) {
+	return s.window.results(from, to, limit), nil
+}
+
+// RecordEvent buffers an arbitrary event with a JSON payload
+func (s *Store) RecordEvent(eventType string, data interface{}) error {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, fmt.Sprintf(
+		"INSERT INTO events VALUES (%d, %s, %s);",
+		event.Timestamp,
+		quote(event.Type),
+		quote(string(event.Data)),
+	))
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Flush writes all buffered rows in a single transaction, then deletes rows
+// past the retention window. Rows that fail to write are kept for the next
+// Flush, up to Config.MaxRows.
+func (s *Store) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		s.prune(ctx)
+		return nil
+	}
+
+	var sql strings.Builder
+	sql.WriteString("BEGIN;\n")
+	for _, stmt := range pending {
+		sql.WriteString(stmt)
+		sql.WriteByte('\n')
+	}
+	sql.WriteString("COMMIT;\n")
+
+	if err := s.exec(ctx, sql.String()); err != nil {
+		s.writeErrors.Add(1)
+		s.mu.Lock()
+		s.pending = capped(pending, s.cfg.MaxRows, s.pending...)
+		s.mu.Unlock()
+		return err
+	}
+
+	s.rowsWritten.Add(uint64(len(pending)))
+	s.prune(ctx)
+	return nil
+}
+
+// prune deletes rows past the retention window (at most once a minute)
+func (s *Store) prune(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+	s.mu.Lock()
+	due := time.Since(s.lastPruneRun) >= time.Minute
+	if due {
+		s.lastPruneRun = time.Now()
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	cutoff := time.Now().Add(-s.cfg.Retention).UnixMilli()
+	sql := fmt.Sprintf(`DELETE FROM readings WHERE ts < %d;
+DELETE FROM segments WHERE end_ts < %d;
+DELETE FROM events WHERE ts < %d;`, cutoff, cutoff, cutoff)
+
+	if err := s.exec(ctx, sql); err != nil {
+		s.logger.Warn("history prune failed", "error", err)
+		return
+	}
+	s.pruneRuns.Add(1)
+}
+
+// Query returns rows from the requested table as JSON objects
+func (s *Store) Query(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	tsColumn := "ts"
+	if q.Table == TableSegments {
+		tsColumn = "start_ts"
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE %s >= %d AND %s <= %d ORDER BY %s LIMIT %d;",
+		q.Table, tsColumn, q.From.UnixMilli(), tsColumn, q.To.UnixMilli(), tsColumn, q.Limit)
+
+	cmd := exec.CommandContext(ctx, s.cfg.SQLiteCmd, "-json", s.cfg.Path, sql)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("query failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	rows := []map[string]interface{}{}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return rows, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &rows); err != nil {
+		return nil, fmt.Errorf("decode query result: %w", err)
+	}
+
+	return rows, nil
+}
+
+// exec runs SQL statements through the sqlite3 CLI
+func (s *Store) exec(ctx context.Context, sql string) error {
+	cmd := exec.CommandContext(ctx, s.cfg.SQLiteCmd, "-batch", "-bail", s.cfg.Path)
+	cmd.Stdin = strings.NewReader(sql)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sqlite3: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Stats contains store statistics
+type Stats struct {
+	Backend      string `json:"backend"`
+	RowsWritten  uint64 `json:"rows_written"` // Rows written to SQLite or memory, or uploaded
+	WriteErrors  uint64 `json:"write_errors"` // Failed writes or uploads
+	PendingRows  int    `json:"pending_rows"`
+	PruneRuns    uint64 `json:"prune_runs"`
+	DatabasePath string `json:"database_path,omitempty"`
+	Rows         int    `json:"rows,omitempty"` // Rows held in memory (memory and remote backends)
+	Results      int    `json:"results"`        // Full-rate results held in the window
+}
+
+// GetStats returns store statistics
+func (s *Store) GetStats() Stats {
+	s.mu.Lock()
+	pending := len(s.pending)
+	s.mu.Unlock()
+
+	return Stats{
+		Backend:      BackendSQLite,
+		RowsWritten:  s.rowsWritten.Load(),
+		WriteErrors:  s.writeErrors.Load(),
+		PendingRows:  pending,
+		PruneRuns:    s.pruneRuns.Load(),
+		DatabasePath: s.cfg.Path,
+		Results:      s.window.len(),
+	}
+}
+
+// Close flushes any buffered rows
+func (s *Store) Close() error {
+	return s.Flush(context.Background())
+}
+
+// quote returns a SQL string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlReal returns a SQL REAL literal, or NULL for NaN and ±Inf, which SQLite
+// has no literal for
+func sqlReal(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "NULL"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Path = filepath.Join(t.TempDir(), "history.db")
+	cfg.SampleInterval = 100 * time.Millisecond
+
+	s, err := Open(cfg, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return s
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.SampleInterval <= 0 {
+		t.Error("SampleInterval should be positive")
+	}
+	if cfg.FlushInterval <= 0 {
+		t.Error("FlushInterval should be positive")
+	}
+	if cfg.SQLiteCmd == "" {
+		t.Error("SQLiteCmd should not be empty")
+	}
+}
+
+func TestStore_RecordAndQuery(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Second)
+	for i := 0; i < 10; i++ {
+		s.Record(doa.Result{
+			Reading: doa.Reading{
+				Angle:     0.5,
+				Timestamp: start.Add(time.Duration(i) * 50 * time.Millisecond),
+			},
+			SmoothedAngle: 0.5,
+		})
+	}
+
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rows, err := s.Query(ctx, Query{Table: TableReadings, From: start.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	// 10 readings 50ms apart with a 100ms sample interval
+	if len(rows) != 5 {
+		t.Errorf("expected 5 downsampled rows, got %d", len(rows))
+	}
+}
+
+func TestStore_Segments(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Second)
+	speaking := []bool{false, true, true, true, false}
+	for i, sp := range speaking {
+		s.Record(doa.Result{
+			Reading:         doa.Reading{Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond), TotalEnergy: float64(i)},
+			SmoothedAngle:   0.2,
+			SpeakingLatched: sp,
+		})
+	}
+
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rows, err := s.Query(ctx, Query{Table: TableSegments, From: start.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 segment, got %d", len(rows))
+	}
+
+	if rows[0]["duration_ms"].(float64) != 300 {
+		t.Errorf("expected duration 300ms, got %v", rows[0]["duration_ms"])
+	}
+	if rows[0]["peak_energy"].(float64) != 3 {
+		t.Errorf("expected peak energy 3, got %v", rows[0]["peak_energy"])
+	}
+}
+
+func TestStore_Events(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if err := s.RecordEvent("cloud_disconnect", map[string]string{"reason": "it's gone"}); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows, err := s.Query(ctx, Query{Table: TableEvents, From: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	if len(rows) != 1 || rows[0]["type"] != "cloud_disconnect" {
+		t.Errorf("unexpected events: %v", rows)
+	}
+}
+
+func TestStore_NonFiniteValues(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Second)
+	s.Record(doa.Result{
+		Reading:       doa.Reading{Angle: math.NaN(), TotalEnergy: math.Inf(1), Timestamp: start},
+		SmoothedAngle: 0.5,
+	})
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rows, err := s.Query(ctx, Query{Table: TableReadings, From: start.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	if rows[0]["angle"] != nil || rows[0]["total_energy"] != nil || rows[0]["smoothed_angle"] != 0.5 {
+		t.Errorf("row = %v, want NULL angle and energy", rows[0])
+	}
+}
+
+func TestStore_FlushFailureKeepsRows(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if err := s.RecordEvent("first", nil); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+	sqlite := s.cfg.SQLiteCmd
+	s.cfg.SQLiteCmd = "false"
+	if err := s.Flush(ctx); err == nil {
+		t.Fatal("Flush() succeeded with a failing sqlite3")
+	}
+	if err := s.RecordEvent("second", nil); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+	if stats := s.GetStats(); stats.PendingRows != 2 || stats.WriteErrors != 1 {
+		t.Errorf("stats = %+v, want 2 pending rows and 1 write error", stats)
+	}
+
+	s.cfg.SQLiteCmd = sqlite
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	rows, err := s.Query(ctx, Query{Table: TableEvents, From: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0]["type"] != "first" || rows[1]["type"] != "second" {
+		t.Errorf("events = %v, want first then second", rows)
+	}
+}
+
+func TestStore_QueryUnknownTable(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.Query(context.Background(), Query{Table: "users; DROP TABLE readings"}); err == nil {
+		t.Error("expected error for unknown table")
+	}
+}
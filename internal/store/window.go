@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// ErrNotStored is returned by Query on a history that keeps no rows
+var ErrNotStored = errors.New("history store not enabled")
+
+// resultWindow keeps the newest tracker results at full rate in a
+// fixed-size ring, for trend lines and stream resumption
+type resultWindow struct {
+	mu   sync.Mutex
+	buf  []doa.Result
+	next int // Where the next result is written
+	n    int
+}
+
+func newResultWindow(size int) *resultWindow {
+	return &resultWindow{buf: make([]doa.Result, max(size, 0))}
+}
+
+func (w *resultWindow) push(result doa.Result) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return
+	}
+	w.buf[w.next] = result
+	w.next = (w.next + 1) % len(w.buf)
+	w.n = min(w.n+1, len(w.buf))
+}
+
+// results returns the held results with from <= timestamp <= to, oldest
+// first. limit <= 0 returns everything in range.
+func (w *resultWindow) results(from, to time.Time, limit int) []doa.Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	results := []doa.Result{}
+	for i := w.n - 1; i >= 0; i-- {
+		res := w.buf[(w.next-1-i+2*len(w.buf))%len(w.buf)]
+		if res.Timestamp.Before(from) || res.Timestamp.After(to) {
+			continue
+		}
+		results = append(results, res)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results
+}
+
+func (w *resultWindow) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+// Window is the history used when the store is off: it keeps only the
+// full-rate window of recent results, so trend lines and stream resumption
+// work, and stores no rows
+type Window struct {
+	window *resultWindow
+}
+
+// NewWindow creates a history holding the last cfg.Window results
+func NewWindow(cfg Config) *Window {
+	return &Window{window: newResultWindow(cfg.Window)}
+}
+
+// Record adds a tracker result to the window
+func (w *Window) Record(result doa.Result) {
+	w.window.push(result)
+}
+
+// RecordEvent drops the event; there is nowhere to keep it
+func (w *Window) RecordEvent(eventType string, data interface{}) error {
+	return nil
+}
+
+// Results returns results from the window
+func (w *Window) Results(from, to time.Time, limit int) ([]doa.Result, error) {
+	return w.window.results(from, to, limit), nil
+}
+
+// Query returns ErrNotStored
+func (w *Window) Query(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	return nil, ErrNotStored
+}
+
+// Flush is a no-op
+func (w *Window) Flush(ctx context.Context) error {
+	return nil
+}
+
+// GetStats returns store statistics
+func (w *Window) GetStats() Stats {
+	return Stats{Backend: BackendWindow, Results: w.window.len()}
+}
+
+// Close is a no-op
+func (w *Window) Close() error {
+	return nil
+}
+
+// Recording keeps every tracker result on disk (implemented by
+// recorder.Recorder)
+type Recording interface {
+	Record(result doa.Result) error
+	Export(from, to time.Time, fn func(doa.Result) bool) error
+	Flush() error
+	Close() error
+}
+
+// recorded is a History whose full-rate results come from a Recording
+// rather than the in-memory window, so they reach back as far as the
+// recording does
+type recorded struct {
+	History
+	rec    Recording
+	logger *slog.Logger
+}
+
+// WithRecording returns h with every result also written to rec, and
+// Results and Export answered from rec
+func WithRecording(h History, rec Recording, logger *slog.Logger) History {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &recorded{History: h, rec: rec, logger: logger}
+}
+
+// Record adds a tracker result to both the history and the recording
+func (r *recorded) Record(result doa.Result) {
+	r.History.Record(result)
+	if err := r.rec.Record(result); err != nil {
+		r.logger.Warn("doa record failed", "error", err)
+	}
+}
+
+// Results returns recorded results
+func (r *recorded) Results(from, to time.Time, limit int) ([]doa.Result, error) {
+	results := []doa.Result{}
+	err := r.rec.Export(from, to, func(res doa.Result) bool {
+		results = append(results, res)
+		return limit <= 0 || len(results) < limit
+	})
+	return results, err
+}
+
+// Export streams recorded results in [from, to] to fn until it returns false
+func (r *recorded) Export(from, to time.Time, fn func(doa.Result) bool) error {
+	return r.rec.Export(from, to, fn)
+}
+
+// Flush writes buffered results and rows
+func (r *recorded) Flush(ctx context.Context) error {
+	if err := r.rec.Flush(); err != nil {
+		r.logger.Warn("doa recorder flush failed", "error", err)
+	}
+	return r.History.Flush(ctx)
+}
+
+// Close closes the recording and the history
+func (r *recorded) Close() error {
+	return errors.Join(r.rec.Close(), r.History.Close())
+}
+
+// Export streams h's full-rate results in [from, to] to fn until it returns
+// false, without holding them all in memory when h is backed by a recording
+func Export(h History, from, to time.Time, fn func(doa.Result) bool) error {
+	if r, ok := h.(*recorded); ok {
+		return r.Export(from, to, fn)
+	}
+	results, err := h.Results(from, to, 0)
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		if !fn(res) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+	"github.com/teslashibe/go-eva/internal/protocol"
+)
+
+func TestResults_Window(t *testing.T) {
+	cfg := testConfig()
+	cfg.Window = 4
+
+	now := time.Now()
+	for _, h := range []History{NewMemory(cfg), NewRemote(cfg, func(protocol.HistoryData) error { return nil }, nil), NewWindow(cfg)} {
+		for i := range 6 {
+			h.Record(doa.Result{Reading: doa.Reading{Timestamp: now.Add(time.Duration(i-6) * time.Second)}})
+		}
+
+		// Every result is kept, not just the downsampled ones
+		all, err := h.Results(now.Add(-time.Minute), now, 0)
+		if err != nil || len(all) != 4 {
+			t.Errorf("%T: Results() = %d results, %v, want the 4 kept", h, len(all), err)
+			continue
+		}
+		if !all[0].Timestamp.Before(all[3].Timestamp) {
+			t.Errorf("%T: Results() not oldest first", h)
+		}
+		if got, _ := h.Results(now.Add(-2500*time.Millisecond), now, 0); len(got) != 2 {
+			t.Errorf("%T: recent results = %d, want 2", h, len(got))
+		}
+		if got, _ := h.Results(now.Add(-time.Minute), now, 1); len(got) != 1 || !got[0].Timestamp.Equal(all[0].Timestamp) {
+			t.Errorf("%T: limited results = %+v, want the oldest", h, got)
+		}
+		if got := h.GetStats().Results; got != 4 {
+			t.Errorf("%T: stats results = %d, want 4", h, got)
+		}
+	}
+}
+
+func TestWindow_NoRows(t *testing.T) {
+	w := NewWindow(testConfig())
+	if _, err := w.Query(context.Background(), Query{Table: TableReadings}); !errors.Is(err, ErrNotStored) {
+		t.Errorf("Query() error = %v, want ErrNotStored", err)
+	}
+}
+
+type fakeRecording struct {
+	results []doa.Result
+	flushes int
+	closed  bool
+}
+
+func (f *fakeRecording) Record(result doa.Result) error {
+	f.results = append(f.results, result)
+	return nil
+}
+
+func (f *fakeRecording) Export(from, to time.Time, fn func(doa.Result) bool) error {
+	for _, res := range f.results {
+		if !res.Timestamp.Before(from) && !res.Timestamp.After(to) && !fn(res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *fakeRecording) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func (f *fakeRecording) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWithRecording(t *testing.T) {
+	cfg := testConfig()
+	cfg.Window = 2
+	rec := &fakeRecording{}
+	h := WithRecording(NewMemory(cfg), rec, nil)
+
+	start := time.Now().Add(-time.Second)
+	recordReadings(h, start, 10)
+
+	// Results reach past the in-memory window
+	results, err := h.Results(start, time.Now(), 0)
+	if err != nil || len(results) != 10 {
+		t.Errorf("Results() = %d results, %v, want all 10 recorded", len(results), err)
+	}
+	var exported int
+	Export(h, start, time.Now(), func(doa.Result) bool {
+		exported++
+		return exported < 3
+	})
+	if exported != 3 {
+		t.Errorf("Export() stopped after %d results, want 3", exported)
+	}
+
+	// Rows still go to the history
+	rows, err := h.Query(context.Background(), Query{Table: TableReadings, From: start.Add(-time.Second)})
+	if err != nil || len(rows) != 5 {
+		t.Errorf("Query() = %d rows, %v, want 5", len(rows), err)
+	}
+
+	if err := h.Flush(context.Background()); err != nil || rec.flushes != 1 {
+		t.Errorf("Flush() = %v, recording flushed %d times, want once", err, rec.flushes)
+	}
+	if err := h.Close(); err != nil || !rec.closed {
+		t.Errorf("Close() = %v, recording closed %v", err, rec.closed)
+	}
+}
@@ -0,0 +1,306 @@
+// Package stt provides on-robot speech-to-text using an external recognizer
+// process (e.g., whisper.cpp), fed by mic capture and gated by VAD.
+package stt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+	"github.com/teslashibe/go-eva/internal/bus"
+)
+
+// Config holds STT pipeline configuration
+type Config struct {
+	Command        string        // Recognizer binary (e.g., "whisper-cli")
+	Args           []string      // Arguments; "{file}" is replaced with the utterance WAV path
+	MinUtterance   time.Duration // Utterances shorter than this are discarded
+	MaxUtterance   time.Duration // Utterances are cut and transcribed at this length
+	PreRoll        time.Duration // Audio kept from before VAD onset
+	Timeout        time.Duration // Max recognizer runtime per utterance
+	MaxConcurrency int           // Max recognizer processes running at once
+}
+
+// DefaultConfig returns sensible defaults for whisper.cpp
+func DefaultConfig() Config {
+	return Config{
+		Command:        "whisper-cli",
+		Args:           []string{"-m", "/usr/share/whisper/ggml-base.en.bin", "-nt", "-np", "-f", "{file}"},
+		MinUtterance:   300 * time.Millisecond,
+		MaxUtterance:   15 * time.Second,
+		PreRoll:        300 * time.Millisecond,
+		Timeout:        20 * time.Second,
+		MaxConcurrency: 1,
+	}
+}
+
+// Transcript is a recognized utterance
+type Transcript struct {
+	Text       string    `json:"text"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	DurationMs int64     `json:"duration_ms"`
+	LatencyMs  int64     `json:"latency_ms"` // Recognition time after utterance end
+}
+
+// TopicTranscripts carries recognized utterances on the event bus
+var TopicTranscripts = bus.NewTopic[Transcript]("stt.transcript")
+
+// Transcriber converts PCM16 mono audio to text
+type Transcriber interface {
+	Transcribe(ctx context.Context, pcm []byte, sampleRate int) (string, error)
+}
+
+// CommandTranscriber runs an external recognizer on a temporary WAV file
+type CommandTranscriber struct {
+	Command string
+	Args    []string
+}
+
+// Transcribe writes the audio to a WAV file and returns the recognizer's stdout
+func (c *CommandTranscriber) Transcribe(ctx context.Context, pcm []byte, sampleRate int) (string, error) {
+	f, err := os.CreateTemp("", "go-eva-utt-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("create temp wav: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(audio.EncodeWAV(pcm, sampleRate)); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write temp wav: %w", err)
+	}
+	f.Close()
+
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = strings.ReplaceAll(arg, "{file}", f.Name())
+	}
+
+	cmd := exec.CommandContext(ctx, c.Command, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("stt command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.Join(strings.Fields(stdout.String()), " "), nil
+}
+
+// Pipeline segments captured audio by VAD state and transcribes each utterance
+type Pipeline struct {
+	cfg         Config
+	transcriber Transcriber
+	logger      *slog.Logger
+
+	mu         sync.Mutex
+	speaking   bool
+	recording  bool
+	uttStart   time.Time
+	buffer     bytes.Buffer
+	preRoll    []audio.AudioChunk
+	sampleRate int
+
+	sem chan struct{}
+
+	// Callbacks
+	onTranscript func(Transcript)
+
+	// Stats
+	utterances   atomic.Uint64
+	transcripts  atomic.Uint64
+	sttErrors    atomic.Uint64
+	discardedUtt atomic.Uint64
+}
+
+// NewPipeline creates a new STT pipeline
+func NewPipeline(cfg Config, transcriber Transcriber, logger *slog.Logger) *Pipeline {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+
+	return &Pipeline{
+		cfg:         cfg,
+		transcriber: transcriber,
+		logger:      logger,
+		sem:         make(chan struct{}, cfg.MaxConcurrency),
+	}
+}
+
+// OnTranscript sets the callback for recognized utterances
+func (p *Pipeline) OnTranscript(callback func(Transcript)) {
+	p.mu.Lock()
+	p.onTranscript = callback
+	p.mu.Unlock()
+}
+
+// PublishTo routes transcripts to the event bus, replacing OnTranscript
+func (p *Pipeline) PublishTo(b *bus.Bus) {
+	p.OnTranscript(bus.Publisher(b, TopicTranscripts))
+}
+
+// SetSpeaking updates the VAD gate; a falling edge ends the current utterance
+func (p *Pipeline) SetSpeaking(speaking bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if speaking == p.speaking {
+		return
+	}
+	p.speaking = speaking
+
+	if speaking {
+		p.startUtterance(time.Now())
+		return
+	}
+
+	if p.recording {
+		p.endUtterance(time.Now())
+	}
+}
+
+// Feed adds a captured audio chunk to the pipeline
+func (p *Pipeline) Feed(chunk audio.AudioChunk) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sampleRate = chunk.SampleRate
+
+	if !p.recording {
+		p.appendPreRoll(chunk)
+		return
+	}
+
+	p.buffer.Write(chunk.Data)
+
+	if p.cfg.MaxUtterance > 0 && chunk.Timestamp.Sub(p.uttStart) >= p.cfg.MaxUtterance {
+		p.endUtterance(chunk.Timestamp)
+		if p.speaking {
+			p.startUtterance(chunk.Timestamp)
+		}
+	}
+}
+
+// appendPreRoll keeps the most recent chunks covering PreRoll
+func (p *Pipeline) appendPreRoll(chunk audio.AudioChunk) {
+	if p.cfg.PreRoll <= 0 {
+		return
+	}
+
+	p.preRoll = append(p.preRoll, chunk)
+	cutoff := chunk.Timestamp.Add(-p.cfg.PreRoll)
+	drop := 0
+	for drop < len(p.preRoll) && p.preRoll[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	p.preRoll = p.preRoll[drop:]
+}
+
+func (p *Pipeline) startUtterance(now time.Time) {
+	p.recording = true
+	p.uttStart = now
+	p.buffer.Reset()
+
+	for _, chunk := range p.preRoll {
+		p.buffer.Write(chunk.Data)
+	}
+	if len(p.preRoll) > 0 {
+		p.uttStart = p.preRoll[0].Timestamp
+	}
+	p.preRoll = nil
+}
+
+// endUtterance hands the buffered audio to the recognizer (caller holds mu)
+func (p *Pipeline) endUtterance(now time.Time) {
+	p.recording = false
+	p.utterances.Add(1)
+
+	duration := now.Sub(p.uttStart)
+	if duration < p.cfg.MinUtterance || p.buffer.Len() == 0 || p.sampleRate == 0 {
+		p.discardedUtt.Add(1)
+		p.buffer.Reset()
+		return
+	}
+
+	pcm := make([]byte, p.buffer.Len())
+	copy(pcm, p.buffer.Bytes())
+	p.buffer.Reset()
+
+	start := p.uttStart
+	sampleRate := p.sampleRate
+	callback := p.onTranscript
+
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		p.discardedUtt.Add(1)
+		p.logger.Warn("stt busy, dropping utterance", "duration", duration)
+		return
+	}
+
+	go func() {
+		defer func() { <-p.sem }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+		defer cancel()
+
+		text, err := p.transcriber.Transcribe(ctx, pcm, sampleRate)
+		if err != nil {
+			p.sttErrors.Add(1)
+			p.logger.Warn("stt failed", "error", err)
+			return
+		}
+		if text == "" {
+			return
+		}
+
+		p.transcripts.Add(1)
+		transcript := Transcript{
+			Text:       text,
+			Start:      start,
+			End:        now,
+			DurationMs: now.Sub(start).Milliseconds(),
+			LatencyMs:  time.Since(now).Milliseconds(),
+		}
+
+		// What people say stays out of the logs unless debugging
+		p.logger.Debug("transcript", "text", text, "latency_ms", transcript.LatencyMs)
+
+		if callback != nil {
+			callback(transcript)
+		}
+	}()
+}
+
+// Stats contains pipeline statistics
+type Stats struct {
+	Utterances  uint64 `json:"utterances"`
+	Transcripts uint64 `json:"transcripts"`
+	Errors      uint64 `json:"errors"`
+	Discarded   uint64 `json:"discarded"`
+}
+
+// GetStats returns pipeline statistics
+func (p *Pipeline) GetStats() Stats {
+	return Stats{
+		Utterances:  p.utterances.Load(),
+		Transcripts: p.transcripts.Load(),
+		Errors:      p.sttErrors.Load(),
+		Discarded:   p.discardedUtt.Load(),
+	}
+}
@@ -0,0 +1,101 @@
+package stt
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+)
+
+type fakeTranscriber struct {
+	got chan []byte
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, pcm []byte, sampleRate int) (string, error) {
+	f.got <- pcm
+	return "hello eva", nil
+}
+
+func chunk(ts time.Time, b byte) audio.AudioChunk {
+	return audio.AudioChunk{
+		Data:       []byte{b, b},
+		SampleRate: 16000,
+		Channels:   1,
+		Timestamp:  ts,
+	}
+}
+
+func TestPipeline_Utterance(t *testing.T) {
+	fake := &fakeTranscriber{got: make(chan []byte, 1)}
+
+	cfg := DefaultConfig()
+	cfg.MinUtterance = 0
+	cfg.PreRoll = 150 * time.Millisecond
+
+	p := NewPipeline(cfg, fake, nil)
+
+	transcripts := make(chan Transcript, 1)
+	p.OnTranscript(func(tr Transcript) { transcripts <- tr })
+
+	base := time.Now()
+	// Silence: only the last chunks within the pre-roll window are kept
+	p.Feed(chunk(base.Add(-300*time.Millisecond), 1))
+	p.Feed(chunk(base.Add(-100*time.Millisecond), 2))
+
+	p.SetSpeaking(true)
+	p.Feed(chunk(base, 3))
+	p.Feed(chunk(base.Add(100*time.Millisecond), 4))
+	p.SetSpeaking(false)
+
+	select {
+	case pcm := <-fake.got:
+		if string(pcm) != string([]byte{2, 2, 3, 3, 4, 4}) {
+			t.Errorf("unexpected utterance audio %v", pcm)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for transcription")
+	}
+
+	select {
+	case tr := <-transcripts:
+		if tr.Text != "hello eva" {
+			t.Errorf("unexpected transcript %q", tr.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for transcript callback")
+	}
+}
+
+func TestPipeline_DiscardShort(t *testing.T) {
+	fake := &fakeTranscriber{got: make(chan []byte, 1)}
+
+	cfg := DefaultConfig()
+	cfg.MinUtterance = time.Hour
+
+	p := NewPipeline(cfg, fake, nil)
+	p.SetSpeaking(true)
+	p.Feed(chunk(time.Now(), 1))
+	p.SetSpeaking(false)
+
+	if p.GetStats().Discarded != 1 {
+		t.Errorf("expected 1 discarded utterance, got %d", p.GetStats().Discarded)
+	}
+}
+
+func TestCommandTranscriber(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+
+	tr := &CommandTranscriber{Command: "echo", Args: []string{"  turn", " left  "}}
+	text, err := tr.Transcribe(context.Background(), []byte{0, 0}, 16000)
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	if text != "turn left" {
+		t.Errorf("expected normalized text, got %q", text)
+	}
+}
@@ -0,0 +1,164 @@
+// Package systemd implements the sd_notify protocol, so a Type=notify unit
+// knows when go-eva is ready and restarts it when the watchdog goes quiet
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Notification states
+const (
+	StateReady    = "READY=1"
+	StateStopping = "STOPPING=1"
+	StateWatchdog = "WATCHDOG=1"
+)
+
+// Notifier sends state changes to the service manager. Without
+// $NOTIFY_SOCKET (not started by systemd) every call is a no-op.
+type Notifier struct {
+	socket   string
+	watchdog time.Duration
+	logger   *slog.Logger
+
+	// Stats
+	pings   atomic.Uint64
+	skipped atomic.Uint64
+}
+
+// NewNotifier reads the notify socket and watchdog timeout from the
+// environment systemd passes to the service
+func NewNotifier(logger *slog.Logger) *Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	n := &Notifier{socket: os.Getenv("NOTIFY_SOCKET"), logger: logger}
+
+	interval, err := watchdogInterval(os.Getenv("WATCHDOG_USEC"), os.Getenv("WATCHDOG_PID"), os.Getpid())
+	if err != nil {
+		logger.Warn("ignoring systemd watchdog settings", "error", err)
+	}
+	n.watchdog = interval
+	return n
+}
+
+// watchdogInterval parses the watchdog timeout; it applies only when
+// WATCHDOG_PID is unset or names this process
+func watchdogInterval(usec, pid string, self int) (time.Duration, error) {
+	if usec == "" {
+		return 0, nil
+	}
+	if pid != "" {
+		p, err := strconv.Atoi(pid)
+		if err != nil {
+			return 0, fmt.Errorf("WATCHDOG_PID: %w", err)
+		}
+		if p != self {
+			return 0, nil
+		}
+	}
+	us, err := strconv.ParseUint(usec, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("WATCHDOG_USEC: %w", err)
+	}
+	return time.Duration(us) * time.Microsecond, nil
+}
+
+// Enabled reports whether the process was started with a notify socket
+func (n *Notifier) Enabled() bool {
+	return n.socket != ""
+}
+
+// WatchdogInterval returns the watchdog timeout (0 = disabled)
+func (n *Notifier) WatchdogInterval() time.Duration {
+	return n.watchdog
+}
+
+// Notify sends one or more newline-separated state assignments
+func (n *Notifier) Notify(state string) error {
+	if n.socket == "" {
+		return nil
+	}
+
+	addr := &net.UnixAddr{Name: n.socket, Net: "unixgram"}
+	if addr.Name[0] == '@' {
+		addr.Name = "\x00" + addr.Name[1:] // Abstract namespace
+	}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return fmt.Errorf("dial notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("notify %q: %w", state, err)
+	}
+	return nil
+}
+
+// Ready tells systemd startup finished, with a human-readable status
+func (n *Notifier) Ready(status string) error {
+	return n.Notify(StateReady + "\nSTATUS=" + status)
+}
+
+// Stopping tells systemd shutdown has begun
+func (n *Notifier) Stopping() error {
+	return n.Notify(StateStopping)
+}
+
+// RunWatchdog pings the watchdog at half its timeout while check passes,
+// until ctx is cancelled. A failing check withholds the ping, so systemd
+// restarts a wedged daemon once the timeout elapses.
+func (n *Notifier) RunWatchdog(ctx context.Context, check func() error) {
+	if n.socket == "" || n.watchdog <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(n.watchdog / 2)
+	defer ticker.Stop()
+
+	n.logger.Info("systemd watchdog enabled", "timeout", n.watchdog)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := check(); err != nil {
+			n.skipped.Add(1)
+			n.logger.Warn("withholding watchdog ping", "error", err)
+			n.Notify("STATUS=unhealthy: " + err.Error())
+			continue
+		}
+		if err := n.Notify(StateWatchdog); err != nil {
+			n.logger.Warn("watchdog ping failed", "error", err)
+			continue
+		}
+		n.pings.Add(1)
+	}
+}
+
+// Stats contains notifier statistics
+type Stats struct {
+	Enabled         bool    `json:"enabled"`
+	WatchdogSeconds float64 `json:"watchdog_seconds"`
+	Pings           uint64  `json:"pings"`
+	Skipped         uint64  `json:"skipped"` // Pings withheld by a failing check
+}
+
+// GetStats returns notifier statistics
+func (n *Notifier) GetStats() Stats {
+	return Stats{
+		Enabled:         n.Enabled(),
+		WatchdogSeconds: n.watchdog.Seconds(),
+		Pings:           n.pings.Load(),
+		Skipped:         n.skipped.Load(),
+	}
+}
@@ -0,0 +1,127 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// listen opens a notify socket and returns a notifier pointed at it
+func listen(t *testing.T) (*Notifier, *net.UnixConn) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &Notifier{socket: path, logger: slog.Default()}, conn
+}
+
+// receive reads one notification
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("no notification: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotifier_ReadyAndStopping(t *testing.T) {
+	n, conn := listen(t)
+
+	if err := n.Ready("serving"); err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+	if got := receive(t, conn); got != "READY=1\nSTATUS=serving" {
+		t.Errorf("Ready sent %q", got)
+	}
+
+	if err := n.Stopping(); err != nil {
+		t.Fatalf("Stopping() error = %v", err)
+	}
+	if got := receive(t, conn); got != StateStopping {
+		t.Errorf("Stopping sent %q", got)
+	}
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	n := &Notifier{}
+	if n.Enabled() {
+		t.Error("notifier without a socket should be disabled")
+	}
+	if err := n.Ready("ok"); err != nil {
+		t.Errorf("Ready() without systemd error = %v", err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		usec, pid string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{"", "", 0, false},
+		{"30000000", "", 30 * time.Second, false},
+		{"30000000", "42", 30 * time.Second, false},
+		{"30000000", "7", 0, false}, // Meant for another process
+		{"soon", "", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := watchdogInterval(tt.usec, tt.pid, 42)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("watchdogInterval(%q, %q) = %v, %v; want %v", tt.usec, tt.pid, got, err, tt.want)
+		}
+	}
+}
+
+func TestNotifier_RunWatchdog(t *testing.T) {
+	n, conn := listen(t)
+	n.watchdog = 40 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing atomicErr
+	go n.RunWatchdog(ctx, failing.Load)
+
+	if got := receive(t, conn); got != StateWatchdog {
+		t.Errorf("healthy ping = %q, want %q", got, StateWatchdog)
+	}
+
+	// A failing check withholds pings and reports why
+	failing.Store(errors.New("tracker stalled"))
+	for i := 0; i < 5; i++ {
+		if got := receive(t, conn); strings.HasPrefix(got, "STATUS=unhealthy") {
+			if !strings.Contains(got, "tracker stalled") {
+				t.Errorf("status = %q, want the check error", got)
+			}
+			if n.GetStats().Skipped == 0 {
+				t.Error("Skipped should count withheld pings")
+			}
+			return
+		}
+	}
+	t.Error("watchdog kept pinging while the check failed")
+}
+
+// atomicErr is an error shared with the watchdog goroutine
+type atomicErr struct{ v atomic.Pointer[error] }
+
+func (a *atomicErr) Store(err error) { a.v.Store(&err) }
+
+func (a *atomicErr) Load() error {
+	if p := a.v.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
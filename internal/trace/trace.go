@@ -0,0 +1,139 @@
+// Package trace carries a correlation ID through one command's path: the
+// HTTP request or cloud message that started it, the log lines it causes
+// and the Pollen requests made on its behalf.
+//
+// IDs travel in a context.Context. They are 32 hex digits, the size of a
+// W3C trace-id, so an ID taken from a traceparent header keeps its value.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Header carries the trace ID on HTTP requests and responses
+const Header = "X-Trace-ID"
+
+// HeaderTraceparent is the W3C Trace Context header
+const HeaderTraceparent = "traceparent"
+
+// maxIDLen bounds IDs accepted from outside
+const maxIDLen = 64
+
+type contextKey struct{}
+
+// NewID returns a random trace ID
+func NewID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether id is acceptable from a client: 1-64 characters of
+// letters, digits, '-', '_' and '.', so it is safe to log and echo
+func Valid(id string) bool {
+	if id == "" || len(id) > maxIDLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FromTraceparent returns the trace-id field of a W3C traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>"), or "" if it doesn't parse
+func FromTraceparent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || strings.Trim(parts[1], "0") == "" {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// FromRequest returns the trace ID a client sent in Header or traceparent,
+// or "" if neither holds a valid one
+func FromRequest(header func(string) string) string {
+	if id := header(Header); Valid(id) {
+		return id
+	}
+	return FromTraceparent(header(HeaderTraceparent))
+}
+
+// WithID returns ctx carrying id; an empty id leaves ctx unchanged
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// ID returns the trace ID ctx carries, or ""
+func ID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Handler adds a trace_id attribute to records logged with a context that
+// carries one (logger.InfoContext and friends)
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps h
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+// Handle adds the trace ID, if any, and passes the record on
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := ID(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("trace_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper on derived handlers
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper on derived handlers
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Transport sets Header on outgoing requests whose context carries a trace
+// ID. A nil Base uses http.DefaultTransport.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if id := ID(req.Context()); id != "" && req.Header.Get(Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(Header, id)
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,79 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewID(t *testing.T) {
+	a, b := NewID(), NewID()
+	if len(a) != 32 || a == b || !Valid(a) {
+		t.Errorf("IDs should be unique 32-digit hex, got %q and %q", a, b)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"header", map[string]string{Header: "req-42"}, "req-42"},
+		{"traceparent", map[string]string{HeaderTraceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"header wins", map[string]string{Header: "req-42", HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "req-42"},
+		{"invalid header", map[string]string{Header: "bad id\n"}, ""},
+		{"zero traceparent", map[string]string{HeaderTraceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+		{"none", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromRequest(func(key string) string { return tt.headers[key] })
+			if got != tt.want {
+				t.Errorf("FromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(WithID(context.Background(), "abc123"), "traced")
+	logger.Info("untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "trace_id=abc123") || !strings.Contains(lines[0], "component=test") {
+		t.Errorf("traced line = %s", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("untraced line = %s", lines[1])
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	req, _ := http.NewRequestWithContext(WithID(context.Background(), "abc123"), "GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if got != "abc123" {
+		t.Errorf("%s = %q, want abc123", Header, got)
+	}
+}
@@ -0,0 +1,235 @@
+// Package tts provides on-robot text-to-speech using piper or espeak-ng
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/audio"
+)
+
+// Engine names
+const (
+	EngineEspeak = "espeak"
+	EnginePiper  = "piper"
+)
+
+// Config holds TTS configuration
+type Config struct {
+	Engine     string        // "espeak" or "piper"
+	Command    string        // Binary path (default: "espeak-ng" or "piper")
+	Voice      string        // espeak-ng voice (e.g., "en-us")
+	Model      string        // piper .onnx model path
+	Speed      int           // espeak-ng words per minute (0 = engine default)
+	SampleRate int           // piper model output rate (default: 22050)
+	Timeout    time.Duration // Max synthesis time per phrase
+}
+
+// DefaultConfig returns sensible defaults for Raspberry Pi
+func DefaultConfig() Config {
+	return Config{
+		Engine:     EngineEspeak,
+		Command:    "espeak-ng",
+		Voice:      "en-us",
+		Speed:      160,
+		SampleRate: 22050,
+		Timeout:    10 * time.Second,
+	}
+}
+
+// Audio is synthesized PCM16 mono audio
+type Audio struct {
+	Data       []byte
+	SampleRate int
+}
+
+// Engine synthesizes speech from text
+type Engine interface {
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+	Name() string
+}
+
+// NewEngine creates the configured engine
+func NewEngine(cfg Config) (Engine, error) {
+	switch cfg.Engine {
+	case EngineEspeak, "espeak-ng", "":
+		if cfg.Command == "" {
+			cfg.Command = "espeak-ng"
+		}
+		return &espeakEngine{cfg: cfg}, nil
+	case EnginePiper:
+		if cfg.Command == "" {
+			cfg.Command = "piper"
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("piper requires a model path")
+		}
+		if cfg.SampleRate <= 0 {
+			cfg.SampleRate = DefaultConfig().SampleRate
+		}
+		return &piperEngine{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown tts engine %q", cfg.Engine)
+	}
+}
+
+// espeakEngine runs espeak-ng and parses its WAV output
+type espeakEngine struct {
+	cfg Config
+}
+
+func (e *espeakEngine) Name() string { return EngineEspeak }
+
+func (e *espeakEngine) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	args := []string{"--stdout"}
+	if e.cfg.Voice != "" {
+		args = append(args, "-v", e.cfg.Voice)
+	}
+	if e.cfg.Speed > 0 {
+		args = append(args, "-s", fmt.Sprintf("%d", e.cfg.Speed))
+	}
+	args = append(args, "--", text)
+
+	out, err := run(ctx, exec.CommandContext(ctx, e.cfg.Command, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	pcm, sampleRate, err := audio.ParseWAV(out)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: pcm, SampleRate: sampleRate}, nil
+}
+
+// piperEngine runs piper with raw PCM output
+type piperEngine struct {
+	cfg Config
+}
+
+func (p *piperEngine) Name() string { return EnginePiper }
+
+func (p *piperEngine) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	cmd := exec.CommandContext(ctx, p.cfg.Command, "--model", p.cfg.Model, "--output_raw")
+	cmd.Stdin = strings.NewReader(text)
+
+	out, err := run(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Audio{Data: out, SampleRate: p.cfg.SampleRate}, nil
+}
+
+func run(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tts command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("tts command produced no audio")
+	}
+	return stdout.Bytes(), nil
+}
+
+// Player plays PCM audio (implemented by audio.Bridge)
+type Player interface {
+	PlayAudio(ctx context.Context, data []byte, format string, sampleRate int) error
+}
+
+// Speaker synthesizes text and routes it to the audio bridge
+type Speaker struct {
+	engine  Engine
+	player  Player
+	timeout time.Duration
+	logger  *slog.Logger
+
+	// Stats
+	phrasesSpoken atomic.Uint64
+	synthErrors   atomic.Uint64
+}
+
+// NewSpeaker creates a new speaker
+func NewSpeaker(engine Engine, player Player, timeout time.Duration, logger *slog.Logger) *Speaker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if timeout <= 0 {
+		timeout = DefaultConfig().Timeout
+	}
+
+	return &Speaker{
+		engine:  engine,
+		player:  player,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Speak synthesizes text and plays it, blocking until playback completes
+func (s *Speaker) Speak(ctx context.Context, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("empty text")
+	}
+
+	synthCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	audio, err := s.engine.Synthesize(synthCtx, text)
+	if err != nil {
+		s.synthErrors.Add(1)
+		return fmt.Errorf("synthesize: %w", err)
+	}
+
+	s.logger.Debug("tts synthesized",
+		"engine", s.engine.Name(),
+		"chars", len(text),
+		"bytes", len(audio.Data),
+		"synth_ms", time.Since(start).Milliseconds(),
+	)
+
+	if err := s.player.PlayAudio(ctx, audio.Data, "pcm16", audio.SampleRate); err != nil {
+		return fmt.Errorf("playback: %w", err)
+	}
+
+	s.phrasesSpoken.Add(1)
+	return nil
+}
+
+// SpeakAsync speaks in the background
+func (s *Speaker) SpeakAsync(text string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		if err := s.Speak(ctx, text); err != nil {
+			s.logger.Warn("tts speak failed", "error", err)
+		}
+	}()
+}
+
+// Stats contains speaker statistics
+type Stats struct {
+	Engine        string `json:"engine"`
+	PhrasesSpoken uint64 `json:"phrases_spoken"`
+	SynthErrors   uint64 `json:"synth_errors"`
+}
+
+// GetStats returns speaker statistics
+func (s *Speaker) GetStats() Stats {
+	return Stats{
+		Engine:        s.engine.Name(),
+		PhrasesSpoken: s.phrasesSpoken.Load(),
+		SynthErrors:   s.synthErrors.Load(),
+	}
+}
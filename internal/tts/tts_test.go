@@ -0,0 +1,79 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewEngine(t *testing.T) {
+	if _, err := NewEngine(DefaultConfig()); err != nil {
+		t.Errorf("default engine error = %v", err)
+	}
+
+	if _, err := NewEngine(Config{Engine: EnginePiper}); err == nil {
+		t.Error("piper without model should fail")
+	}
+
+	if _, err := NewEngine(Config{Engine: "festival"}); err == nil {
+		t.Error("unknown engine should fail")
+	}
+}
+
+type fakeEngine struct {
+	err error
+}
+
+func (f *fakeEngine) Name() string { return "fake" }
+
+func (f *fakeEngine) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Audio{Data: []byte(text), SampleRate: 16000}, nil
+}
+
+type fakePlayer struct {
+	data       []byte
+	sampleRate int
+}
+
+func (f *fakePlayer) PlayAudio(ctx context.Context, data []byte, format string, sampleRate int) error {
+	f.data = data
+	f.sampleRate = sampleRate
+	return nil
+}
+
+func TestSpeaker_Speak(t *testing.T) {
+	player := &fakePlayer{}
+	speaker := NewSpeaker(&fakeEngine{}, player, 0, nil)
+
+	if err := speaker.Speak(context.Background(), "  hello  "); err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+
+	if string(player.data) != "hello" {
+		t.Errorf("expected trimmed text to be synthesized, got %q", player.data)
+	}
+	if player.sampleRate != 16000 {
+		t.Errorf("expected sample rate 16000, got %d", player.sampleRate)
+	}
+	if speaker.GetStats().PhrasesSpoken != 1 {
+		t.Error("expected 1 phrase spoken")
+	}
+}
+
+func TestSpeaker_Errors(t *testing.T) {
+	speaker := NewSpeaker(&fakeEngine{err: errors.New("boom")}, &fakePlayer{}, 0, nil)
+
+	if err := speaker.Speak(context.Background(), ""); err == nil {
+		t.Error("empty text should fail")
+	}
+
+	if err := speaker.Speak(context.Background(), "hi"); err == nil {
+		t.Error("synthesis error should propagate")
+	}
+	if speaker.GetStats().SynthErrors != 1 {
+		t.Error("expected 1 synth error")
+	}
+}
@@ -0,0 +1,192 @@
+package vision
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Target sources
+const (
+	SourceFused = "fused" // Speech from a direction with a face
+	SourceAudio = "audio" // Speech with no face to confirm it
+	SourceFace  = "face"  // Silence; the most confident face in view
+)
+
+// FusionConfig configures audio-visual fusion. The camera and mic array
+// both sit in the head, so their bearings share a frame.
+type FusionConfig struct {
+	FOV           float64       // Horizontal camera field of view (radians)
+	MatchAngle    float64       // A face this close to the DOA angle is taken as the speaker (radians)
+	MaxFaceAge    time.Duration // Older detections are ignored
+	UnseenPenalty float64       // Confidence removed (0-1) when speech comes from inside the view but no face is there
+}
+
+// DefaultFusionConfig returns sensible defaults
+func DefaultFusionConfig() FusionConfig {
+	return FusionConfig{
+		FOV:           80 * math.Pi / 180,
+		MatchAngle:    25 * math.Pi / 180,
+		MaxFaceAge:    time.Second,
+		UnseenPenalty: 0.5,
+	}
+}
+
+// Target is the fused estimate of where to look
+type Target struct {
+	Angle      float64   `json:"angle"` // Radians (0 = front, + = left)
+	Confidence float64   `json:"confidence"`
+	Source     string    `json:"source"`
+	Face       *Face     `json:"face,omitempty"` // Face the target is based on
+	AudioAngle float64   `json:"audio_angle"`    // DOA angle it was fused from
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// TopicTargets carries fused targets on the event bus
+var TopicTargets = bus.NewTopic[Target]("vision.target")
+
+// Fuser combines DOA results with face detections
+type Fuser struct {
+	cfg FusionConfig
+
+	mu       sync.RWMutex
+	latest   Target
+	onTarget func(Target)
+
+	// Stats
+	fused     atomic.Uint64
+	audioOnly atomic.Uint64
+	unseen    atomic.Uint64
+	faceOnly  atomic.Uint64
+}
+
+// NewFuser creates a fuser
+func NewFuser(cfg FusionConfig) *Fuser {
+	if cfg.FOV <= 0 || cfg.FOV >= math.Pi {
+		cfg.FOV = DefaultFusionConfig().FOV
+	}
+	return &Fuser{cfg: cfg}
+}
+
+// OnTarget sets the callback for fused targets
+func (f *Fuser) OnTarget(callback func(Target)) {
+	f.mu.Lock()
+	f.onTarget = callback
+	f.mu.Unlock()
+}
+
+// PublishTo routes fused targets to the event bus, replacing OnTarget
+func (f *Fuser) PublishTo(b *bus.Bus) {
+	f.OnTarget(bus.Publisher(b, TopicTargets))
+}
+
+// Update fuses a DOA result with the latest detection. Returns false when
+// there is neither speech nor a face to look at.
+func (f *Fuser) Update(r doa.Result, det Detection) (Target, bool) {
+	now := r.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var faces []Face
+	if !det.Timestamp.IsZero() && now.Sub(det.Timestamp) <= f.cfg.MaxFaceAge {
+		faces = det.Faces
+	}
+
+	target, ok := f.fuse(r, faces, now)
+	if !ok {
+		return Target{}, false
+	}
+
+	f.mu.Lock()
+	f.latest = target
+	callback := f.onTarget
+	f.mu.Unlock()
+
+	if callback != nil {
+		callback(target)
+	}
+	return target, true
+}
+
+// fuse picks the target for one DOA result and the faces in view
+func (f *Fuser) fuse(r doa.Result, faces []Face, now time.Time) (Target, bool) {
+	audio := r.SmoothedAngle
+	target := Target{AudioAngle: audio, Timestamp: now}
+
+	if !r.Speaking && !r.SpeakingLatched {
+		best := -1
+		for i, face := range faces {
+			if best < 0 || face.Confidence > faces[best].Confidence {
+				best = i
+			}
+		}
+		if best < 0 {
+			return Target{}, false
+		}
+		face := faces[best]
+		target.Angle, target.Confidence, target.Source, target.Face = face.Bearing, face.Confidence/2, SourceFace, &face
+		f.faceOnly.Add(1)
+		return target, true
+	}
+
+	// The face nearest the DOA angle, if close enough to be the speaker
+	match, matchDist := -1, f.cfg.MatchAngle
+	for i, face := range faces {
+		if d := math.Abs(doa.NormalizeAngle(face.Bearing - audio)); d <= matchDist {
+			match, matchDist = i, d
+		}
+	}
+
+	if match >= 0 {
+		// Faces are far more precise than DOA: pull the angle toward the
+		// face by their relative confidence, and combine the confidences
+		// as independent evidence
+		face := faces[match]
+		wFace, wAudio := 2*face.Confidence, r.Confidence
+		target.Angle = doa.NormalizeAngle(audio + doa.NormalizeAngle(face.Bearing-audio)*wFace/(wFace+wAudio+1e-9))
+		target.Confidence = 1 - (1-r.Confidence)*(1-face.Confidence)
+		target.Source, target.Face = SourceFused, &face
+		f.fused.Add(1)
+		return target, true
+	}
+
+	target.Angle, target.Confidence, target.Source = audio, r.Confidence, SourceAudio
+	if faces != nil && math.Abs(audio) < f.cfg.FOV/2 {
+		// The camera is looking where the sound comes from and sees nobody
+		target.Confidence *= 1 - f.cfg.UnseenPenalty
+		f.unseen.Add(1)
+	}
+	f.audioOnly.Add(1)
+	return target, true
+}
+
+// Latest returns the most recent target
+func (f *Fuser) Latest() Target {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.latest
+}
+
+// FusionStats contains fuser statistics
+type FusionStats struct {
+	Fused     uint64 `json:"fused"`
+	AudioOnly uint64 `json:"audio_only"`
+	Unseen    uint64 `json:"unseen"` // Audio-only targets penalized for having no face in view
+	FaceOnly  uint64 `json:"face_only"`
+	Latest    Target `json:"latest"`
+}
+
+// GetStats returns fuser statistics
+func (f *Fuser) GetStats() FusionStats {
+	return FusionStats{
+		Fused:     f.fused.Load(),
+		AudioOnly: f.audioOnly.Load(),
+		Unseen:    f.unseen.Load(),
+		FaceOnly:  f.faceOnly.Load(),
+		Latest:    f.Latest(),
+	}
+}
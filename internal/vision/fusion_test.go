@@ -0,0 +1,71 @@
+package vision
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func deg(d float64) float64 { return d * math.Pi / 180 }
+
+func speech(angle, confidence float64, at time.Time) doa.Result {
+	return doa.Result{
+		Reading:       doa.Reading{Speaking: true, Timestamp: at},
+		SmoothedAngle: angle,
+		Confidence:    confidence,
+	}
+}
+
+func TestFuser(t *testing.T) {
+	f := NewFuser(DefaultFusionConfig())
+	now := time.Now()
+	det := Detection{Timestamp: now, Faces: []Face{
+		{Bearing: deg(12), Confidence: 0.8},
+		{Bearing: deg(-30), Confidence: 0.9},
+	}}
+
+	// Speech near a face is pulled onto it with more confidence than either
+	target, ok := f.Update(speech(deg(20), 0.5, now), det)
+	if !ok || target.Source != SourceFused || target.Face == nil || target.Face.Bearing != deg(12) {
+		t.Fatalf("target = %+v, want fused with the face at 12°", target)
+	}
+	if target.Angle <= deg(12) || target.Angle >= deg(16) {
+		t.Errorf("fused angle = %.1f°, want between the face and DOA, close to the face", target.Angle*180/math.Pi)
+	}
+	if target.Confidence <= 0.8 {
+		t.Errorf("fused confidence = %.2f, want above both inputs", target.Confidence)
+	}
+
+	// Speech from inside the view with nobody there (a TV) is trusted less
+	target, _ = f.Update(speech(deg(35), 0.6, now), Detection{Timestamp: now, Faces: []Face{}})
+	if target.Source != SourceAudio || math.Abs(target.Confidence-0.3) > 1e-9 {
+		t.Errorf("unseen target = %+v, want audio at confidence 0.3", target)
+	}
+
+	// Outside the view the camera can't tell: DOA confidence is kept
+	target, _ = f.Update(speech(deg(150), 0.6, now), det)
+	if target.Source != SourceAudio || target.Confidence != 0.6 || target.Angle != deg(150) {
+		t.Errorf("behind target = %+v, want audio at 150° with confidence 0.6", target)
+	}
+
+	// Stale detections are ignored
+	target, _ = f.Update(speech(deg(20), 0.5, now.Add(2*time.Second)), det)
+	if target.Source != SourceAudio || target.Confidence != 0.5 {
+		t.Errorf("stale target = %+v, want audio without penalty", target)
+	}
+
+	// In silence the most confident face is the target
+	target, ok = f.Update(doa.Result{Reading: doa.Reading{Timestamp: now}}, det)
+	if !ok || target.Source != SourceFace || target.Angle != deg(-30) {
+		t.Errorf("silent target = %+v, want the face at -30°", target)
+	}
+	if _, ok := f.Update(doa.Result{Reading: doa.Reading{Timestamp: now}}, Detection{}); ok {
+		t.Error("silence with no faces should produce no target")
+	}
+
+	if stats := f.GetStats(); stats.Fused != 1 || stats.AudioOnly != 3 || stats.Unseen != 1 || stats.FaceOnly != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
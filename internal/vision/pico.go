@@ -0,0 +1,199 @@
+package vision
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"os"
+)
+
+// picoQualityScale maps the cascade's unbounded detection quality to 0-1: a
+// quality of picoQualityScale gives confidence 0.5
+const picoQualityScale = 10
+
+// cascade is a pico object detection cascade (Markuš et al., "Object
+// Detection with Pixel Intensity Comparisons Organized in Decision Trees"):
+// a chain of binary trees, each node comparing two pixels at offsets scaled
+// to the search window. This is the format of pico's and pigo's facefinder
+// file, evaluated here so the detector stays pure Go with no extra module.
+type cascade struct {
+	depth      int       // Tree depth; each tree has 2^depth leaves
+	trees      int       // Number of trees
+	codes      []int8    // 4 offsets (row1, col1, row2, col2) per node, node 0 unused
+	preds      []float32 // Leaf outputs, 2^depth per tree
+	thresholds []float32 // Running-sum rejection threshold per tree
+}
+
+// parseCascade unpacks a cascade file: an 8-byte header, then the tree depth
+// and count as little-endian int32, then per tree its node codes, leaf
+// predictions and threshold
+func parseCascade(data []byte) (*cascade, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("cascade too short (%d bytes)", len(data))
+	}
+	depth := int(int32(binary.LittleEndian.Uint32(data[8:])))
+	trees := int(int32(binary.LittleEndian.Uint32(data[12:])))
+	if depth < 1 || depth > 16 || trees < 1 {
+		return nil, fmt.Errorf("invalid cascade: depth %d, %d trees", depth, trees)
+	}
+
+	leaves := 1 << depth
+	treeSize := 4*(leaves-1) + 4*leaves + 4
+	if want := 16 + trees*treeSize; len(data) < want {
+		return nil, fmt.Errorf("cascade truncated: %d bytes, want %d", len(data), want)
+	}
+
+	c := &cascade{depth: depth, trees: trees}
+	pos := 16
+	for t := 0; t < trees; t++ {
+		c.codes = append(c.codes, 0, 0, 0, 0)
+		for _, b := range data[pos : pos+4*(leaves-1)] {
+			c.codes = append(c.codes, int8(b))
+		}
+		pos += 4 * (leaves - 1)
+		for i := 0; i < leaves; i++ {
+			c.preds = append(c.preds, math.Float32frombits(binary.LittleEndian.Uint32(data[pos:])))
+			pos += 4
+		}
+		c.thresholds = append(c.thresholds, math.Float32frombits(binary.LittleEndian.Uint32(data[pos:])))
+		pos += 4
+	}
+	return c, nil
+}
+
+// classify scores the square window of size s centered on (r, col) in a
+// grayscale image with row stride dim. A result <= 0 means no object.
+func (c *cascade) classify(r, col, s int, pixels []uint8, dim int) float32 {
+	r, col = r*256, col*256
+	leaves := 1 << c.depth
+
+	var out float32
+	root := 0
+	for i := 0; i < c.trees; i++ {
+		idx := 1
+		for j := 0; j < c.depth; j++ {
+			code := c.codes[root+4*idx:]
+			p1 := ((r+int(code[0])*s)>>8)*dim + (col+int(code[1])*s)>>8
+			p2 := ((r+int(code[2])*s)>>8)*dim + (col+int(code[3])*s)>>8
+			idx *= 2
+			if pixels[p1] <= pixels[p2] {
+				idx++
+			}
+		}
+		out += c.preds[leaves*i+idx-leaves]
+		if out <= c.thresholds[i] {
+			return -1
+		}
+		root += 4 * leaves
+	}
+	return out - c.thresholds[c.trees-1]
+}
+
+// detection is one window the cascade accepted
+type detection struct {
+	row, col, size int
+	quality        float32
+}
+
+// run slides windows from minSize up to the image size, growing by 10% and
+// stepping 10% of the window
+func (c *cascade) run(pixels []uint8, rows, cols, minSize int) []detection {
+	var dets []detection
+	for size := minSize; size <= max(rows, cols); size = max(size+1, size*11/10) {
+		step := max(size/10, 1)
+		offset := size/2 + 1
+		for r := offset; r <= rows-offset; r += step {
+			for col := offset; col <= cols-offset; col += step {
+				if q := c.classify(r, col, size, pixels, cols); q > 0 {
+					dets = append(dets, detection{row: r, col: col, size: size, quality: q})
+				}
+			}
+		}
+	}
+	return dets
+}
+
+// cluster merges overlapping detections (intersection over union above
+// iou) into one at their mean position and size, summing their quality
+func cluster(dets []detection, iou float64) []detection {
+	var merged []detection
+	taken := make([]bool, len(dets))
+	for i := range dets {
+		if taken[i] {
+			continue
+		}
+		var r, col, size, n int
+		var q float32
+		for j := i; j < len(dets); j++ {
+			if !taken[j] && overlap(dets[i], dets[j]) > iou {
+				taken[j] = true
+				r, col, size, q, n = r+dets[j].row, col+dets[j].col, size+dets[j].size, q+dets[j].quality, n+1
+			}
+		}
+		merged = append(merged, detection{row: r / n, col: col / n, size: size / n, quality: q})
+	}
+	return merged
+}
+
+// overlap returns the intersection over union of two square windows
+func overlap(a, b detection) float64 {
+	rows := max(0, min(a.row+a.size/2, b.row+b.size/2)-max(a.row-a.size/2, b.row-b.size/2))
+	cols := max(0, min(a.col+a.size/2, b.col+b.size/2)-max(a.col-a.size/2, b.col-b.size/2))
+	inter := float64(rows * cols)
+	return inter / (float64(a.size*a.size+b.size*b.size) - inter)
+}
+
+// grayscale converts img to 8-bit luma, one byte per pixel, row by row
+func grayscale(img image.Image) []uint8 {
+	bounds := img.Bounds()
+	pixels := make([]uint8, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			pixels = append(pixels, uint8((299*r+587*g+114*b)/1000>>8))
+		}
+	}
+	return pixels
+}
+
+// picoDetector finds faces with a pico cascade
+type picoDetector struct {
+	cascade *cascade
+	cfg     DetectorConfig
+}
+
+// NewDetector loads the facefinder cascade at cfg.CascadePath
+func NewDetector(cfg DetectorConfig) (Detector, error) {
+	data, err := os.ReadFile(cfg.CascadePath)
+	if err != nil {
+		return nil, fmt.Errorf("read cascade: %w", err)
+	}
+	c, err := parseCascade(data)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 20
+	}
+	return &picoDetector{cascade: c, cfg: cfg}, nil
+}
+
+// Detect implements Detector
+func (d *picoDetector) Detect(img image.Image) ([]Face, error) {
+	bounds := img.Bounds()
+	cols, rows := bounds.Dx(), bounds.Dy()
+
+	dets := cluster(d.cascade.run(grayscale(img), rows, cols, d.cfg.MinSize), 0.2)
+	faces := make([]Face, 0, len(dets))
+	for _, det := range dets {
+		q := float64(det.quality)
+		faces = append(faces, Face{
+			X:          float64(det.col) / float64(cols),
+			Y:          float64(det.row) / float64(rows),
+			Size:       float64(det.size) / float64(cols),
+			Confidence: q / (q + picoQualityScale),
+		})
+	}
+	return faces, nil
+}
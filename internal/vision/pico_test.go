@@ -0,0 +1,83 @@
+package vision
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testCascade is a one-node cascade that accepts a window whose center is
+// brighter than the pixel a quarter of the window above it
+func testCascade() []byte {
+	data := make([]byte, 8)                          // Header
+	data = binary.LittleEndian.AppendUint32(data, 1) // Depth
+	data = binary.LittleEndian.AppendUint32(data, 1) // Trees
+	data = append(data, 0, 0, byte(0xC0), 0)         // Center vs -64/256 of the window up
+	data = binary.LittleEndian.AppendUint32(data, math.Float32bits(1))
+	data = binary.LittleEndian.AppendUint32(data, math.Float32bits(-1))
+	return binary.LittleEndian.AppendUint32(data, math.Float32bits(0))
+}
+
+func TestParseCascade(t *testing.T) {
+	c, err := parseCascade(testCascade())
+	if err != nil {
+		t.Fatalf("parseCascade() error = %v", err)
+	}
+	if c.depth != 1 || c.trees != 1 || len(c.codes) != 8 || len(c.preds) != 2 || len(c.thresholds) != 1 {
+		t.Errorf("cascade = %+v", c)
+	}
+
+	full := testCascade()
+	for name, data := range map[string][]byte{
+		"short":     full[:12],
+		"truncated": full[:len(full)-1],
+		"no trees":  append(append([]byte(nil), full[:12]...), 0, 0, 0, 0),
+	} {
+		if _, err := parseCascade(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPicoDetector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facefinder")
+	if err := os.WriteFile(path, testCascade(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	detector, err := NewDetector(DetectorConfig{CascadePath: path, MinSize: 20})
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	if faces, _ := detector.Detect(img); len(faces) != 0 {
+		t.Errorf("blank image: %d faces", len(faces))
+	}
+
+	// A bright square in the middle of the frame
+	for y := 35; y < 65; y++ {
+		for x := 35; x < 65; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	faces, err := detector.Detect(img)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(faces) == 0 {
+		t.Fatal("no faces found")
+	}
+	for _, f := range faces {
+		if f.X < 0.3 || f.X > 0.7 || f.Confidence <= 0 || f.Confidence >= 1 {
+			t.Errorf("face = %+v, want it over the square", f)
+		}
+	}
+
+	if _, err := NewDetector(DetectorConfig{CascadePath: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected error for a missing cascade")
+	}
+}
@@ -0,0 +1,225 @@
+// Package vision detects faces in camera frames and fuses their bearings
+// with audio DOA, so a speaker the camera can see is localized more
+// precisely than by the mic array alone, and sound from a direction with
+// nobody in view (a TV, a reflection) is trusted less.
+package vision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/camera"
+)
+
+// Face is one detected face. Positions are relative to the frame.
+type Face struct {
+	X          float64 `json:"x"`          // Center, 0 = left edge, 1 = right edge
+	Y          float64 `json:"y"`          // Center, 0 = top, 1 = bottom
+	Size       float64 `json:"size"`       // Width as a fraction of the frame width
+	Confidence float64 `json:"confidence"` // 0-1
+	Bearing    float64 `json:"bearing"`    // Radians from the camera axis (+ = left)
+}
+
+// Detector finds faces in an image (X, Y, Size and Confidence; the worker
+// fills in Bearing)
+type Detector interface {
+	Detect(img image.Image) ([]Face, error)
+}
+
+// DetectorConfig configures the built-in detector
+type DetectorConfig struct {
+	CascadePath string // pico facefinder cascade file
+	MinSize     int    // Smallest face searched for (pixels)
+}
+
+// Config configures the face detection worker
+type Config struct {
+	FOV           float64       // Horizontal camera field of view (radians)
+	Interval      time.Duration // Minimum time between detections; frames in between are skipped
+	MinConfidence float64       // Faces below this are dropped
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		FOV:           80 * math.Pi / 180,
+		Interval:      200 * time.Millisecond,
+		MinConfidence: 0.3,
+	}
+}
+
+// Detection is the result of running the detector on one frame
+type Detection struct {
+	Faces     []Face    `json:"faces"`
+	FrameID   uint64    `json:"frame_id"`
+	Timestamp time.Time `json:"timestamp"` // Frame capture time
+}
+
+// TopicFaces carries face detections on the event bus
+var TopicFaces = bus.NewTopic[Detection]("vision.faces")
+
+// Worker runs the face detector on camera frames
+type Worker struct {
+	detector Detector
+	cfg      Config
+	logger   *slog.Logger
+
+	mu          sync.RWMutex
+	latest      Detection
+	lastRun     time.Time
+	onDetection func(Detection)
+
+	// Stats
+	processed atomic.Uint64
+	skipped   atomic.Uint64
+	errors    atomic.Uint64
+	faces     atomic.Uint64
+	totalNs   atomic.Int64
+}
+
+// NewWorker creates a face detection worker
+func NewWorker(detector Detector, cfg Config, logger *slog.Logger) *Worker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.FOV <= 0 || cfg.FOV >= math.Pi {
+		cfg.FOV = DefaultConfig().FOV
+	}
+
+	return &Worker{
+		detector: detector,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// OnDetection sets the callback for each processed frame
+func (w *Worker) OnDetection(callback func(Detection)) {
+	w.mu.Lock()
+	w.onDetection = callback
+	w.mu.Unlock()
+}
+
+// PublishTo routes detections to the event bus, replacing OnDetection
+func (w *Worker) PublishTo(b *bus.Bus) {
+	w.OnDetection(bus.Publisher(b, TopicFaces))
+}
+
+// Run processes frames until ctx is cancelled or frames closes. Give it a
+// small buffer: frames that arrive while the detector is busy are dropped.
+// Each frame is released once processed.
+func (w *Worker) Run(ctx context.Context, frames <-chan camera.Frame) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-frames:
+			if !ok {
+				return
+			}
+			if err := w.Process(frame); err != nil {
+				w.logger.Debug("face detection failed", "frame", frame.FrameID, "error", err)
+			}
+			frame.Release()
+		}
+	}
+}
+
+// Process detects faces in frame, unless the previous detection was less
+// than Interval ago
+func (w *Worker) Process(frame camera.Frame) error {
+	w.mu.Lock()
+	if !w.lastRun.IsZero() && time.Since(w.lastRun) < w.cfg.Interval {
+		w.mu.Unlock()
+		w.skipped.Add(1)
+		return nil
+	}
+	w.lastRun = time.Now()
+	w.mu.Unlock()
+
+	start := time.Now()
+	img, err := jpeg.Decode(bytes.NewReader(frame.Data))
+	if err != nil {
+		w.errors.Add(1)
+		return fmt.Errorf("decode frame: %w", err)
+	}
+	found, err := w.detector.Detect(img)
+	if err != nil {
+		w.errors.Add(1)
+		return fmt.Errorf("detect: %w", err)
+	}
+
+	faces := make([]Face, 0, len(found))
+	for _, f := range found {
+		if f.Confidence < w.cfg.MinConfidence {
+			continue
+		}
+		f.Bearing = w.bearing(f.X)
+		faces = append(faces, f)
+	}
+	w.processed.Add(1)
+	w.faces.Add(uint64(len(faces)))
+	w.totalNs.Add(int64(time.Since(start)))
+
+	det := Detection{Faces: faces, FrameID: frame.FrameID, Timestamp: frame.Timestamp}
+	if det.Timestamp.IsZero() {
+		det.Timestamp = time.Now()
+	}
+
+	w.mu.Lock()
+	w.latest = det
+	callback := w.onDetection
+	w.mu.Unlock()
+
+	if callback != nil {
+		callback(det)
+	}
+	return nil
+}
+
+// bearing maps a horizontal image position to an angle from the camera
+// axis through a pinhole model: the left edge is +FOV/2
+func (w *Worker) bearing(x float64) float64 {
+	return math.Atan((1 - 2*x) * math.Tan(w.cfg.FOV/2))
+}
+
+// Latest returns the most recent detection
+func (w *Worker) Latest() Detection {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.latest
+}
+
+// Stats contains worker statistics
+type Stats struct {
+	Processed uint64  `json:"processed"`
+	Skipped   uint64  `json:"skipped"` // Frames within Interval of the previous detection
+	Errors    uint64  `json:"errors"`
+	Faces     uint64  `json:"faces"`  // Faces found across all frames
+	AvgMs     float64 `json:"avg_ms"` // Mean decode + detect time
+	InView    int     `json:"in_view"`
+}
+
+// GetStats returns worker statistics
+func (w *Worker) GetStats() Stats {
+	stats := Stats{
+		Processed: w.processed.Load(),
+		Skipped:   w.skipped.Load(),
+		Errors:    w.errors.Load(),
+		Faces:     w.faces.Load(),
+		InView:    len(w.Latest().Faces),
+	}
+	if stats.Processed > 0 {
+		stats.AvgMs = float64(w.totalNs.Load()) / float64(stats.Processed) / 1e6
+	}
+	return stats
+}
@@ -0,0 +1,77 @@
+package vision
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/camera"
+)
+
+// fakeDetector returns fixed faces
+type fakeDetector struct {
+	faces []Face
+	calls int
+}
+
+func (d *fakeDetector) Detect(img image.Image) ([]Face, error) {
+	d.calls++
+	return append([]Face(nil), d.faces...), nil
+}
+
+func testFrame(t *testing.T, id uint64) camera.Frame {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 48)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return camera.Frame{Data: buf.Bytes(), Width: 64, Height: 48, FrameID: id, Timestamp: time.Now()}
+}
+
+func TestWorker_Bearings(t *testing.T) {
+	detector := &fakeDetector{faces: []Face{
+		{X: 0.5, Confidence: 0.9},
+		{X: 0, Confidence: 0.8},    // Left edge
+		{X: 0.75, Confidence: 0.6}, // Right of center
+		{X: 0.2, Confidence: 0.1},  // Below MinConfidence
+	}}
+	w := NewWorker(detector, Config{FOV: math.Pi / 2, Interval: time.Hour, MinConfidence: 0.3}, nil)
+
+	var got Detection
+	w.OnDetection(func(d Detection) { got = d })
+	if err := w.Process(testFrame(t, 3)); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if len(got.Faces) != 3 || got.FrameID != 3 {
+		t.Fatalf("detection = %+v, want 3 faces from frame 3", got)
+	}
+	want := []float64{0, math.Pi / 4, -math.Atan(0.5)}
+	for i, face := range got.Faces {
+		if math.Abs(face.Bearing-want[i]) > 1e-9 {
+			t.Errorf("face %d bearing = %.3f, want %.3f", i, face.Bearing, want[i])
+		}
+	}
+
+	// Within Interval the detector is not run again
+	w.Process(testFrame(t, 4))
+	if detector.calls != 1 || w.Latest().FrameID != 3 {
+		t.Errorf("detector ran %d times, latest frame %d; want 1 and 3", detector.calls, w.Latest().FrameID)
+	}
+	if stats := w.GetStats(); stats.Processed != 1 || stats.Skipped != 1 || stats.Faces != 3 || stats.InView != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWorker_BadFrame(t *testing.T) {
+	w := NewWorker(&fakeDetector{}, DefaultConfig(), nil)
+	if err := w.Process(camera.Frame{Data: []byte("nope")}); err == nil {
+		t.Error("expected an error for an undecodable frame")
+	}
+	if w.GetStats().Errors != 1 {
+		t.Errorf("Errors = %d, want 1", w.GetStats().Errors)
+	}
+}
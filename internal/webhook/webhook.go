@@ -0,0 +1,370 @@
+// Package webhook POSTs robot events (speech starting and stopping, zone
+// changes, cloud disconnects) to user-configured URLs, so home automation
+// can react when someone talks to the robot.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/cloud"
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Event types hooks can filter on
+const (
+	EventSpeechStart     = "speech_start"
+	EventSpeechEnd       = "speech_end"
+	EventZoneChange      = "zone_change"
+	EventCloudDisconnect = "cloud_disconnect"
+)
+
+// EventTypes lists every event type
+var EventTypes = []string{EventSpeechStart, EventSpeechEnd, EventZoneChange, EventCloudDisconnect}
+
+// Privacy reports whether privacy mode is on (implemented by privacy.Guard)
+type Privacy interface {
+	Enabled() bool
+}
+
+// private lists the events privacy mode holds back: they say when and
+// where someone is talking
+var private = map[string]bool{
+	EventSpeechStart: true,
+	EventSpeechEnd:   true,
+	EventZoneChange:  true,
+}
+
+// Hook is one endpoint and the events it wants
+type Hook struct {
+	URL     string            // text/template over Event, e.g. "http://ha.local/api/webhook/eva-{{.Type}}"
+	Events  []string          // Event types to send (empty = all)
+	Headers map[string]string // Extra request headers (e.g. Authorization)
+}
+
+// Config holds dispatcher configuration
+type Config struct {
+	Hooks          []Hook
+	RobotID        string        // Added to every payload
+	Timeout        time.Duration // Per request
+	MaxRetries     int           // Retries after the first attempt for network errors, 429 and 5xx
+	InitialBackoff time.Duration // Delay before the first retry, doubling up to MaxBackoff
+	MaxBackoff     time.Duration
+	QueueSize      int // Events buffered per hook; newer events are dropped when full
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Timeout:        5 * time.Second,
+		MaxRetries:     3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		QueueSize:      64,
+	}
+}
+
+// Event is the JSON body POSTed to a hook, and the data its URL template
+// is executed with
+type Event struct {
+	Type      string                 `json:"type"`
+	RobotID   string                 `json:"robot_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// hookWorker delivers one hook's events in order
+type hookWorker struct {
+	hook   Hook
+	url    *template.Template
+	events map[string]bool // nil = all
+	queue  chan Event
+}
+
+// Dispatcher fans events out to hooks, retrying failed deliveries
+type Dispatcher struct {
+	cfg        Config
+	logger     *slog.Logger
+	httpClient *http.Client
+	workers    []*hookWorker
+
+	mu        sync.Mutex
+	privacy   Privacy
+	lastError string
+
+	// Stats
+	delivered  atomic.Uint64
+	failed     atomic.Uint64
+	retries    atomic.Uint64
+	dropped    atomic.Uint64
+	suppressed atomic.Uint64
+}
+
+// templateFuncs are available in URL templates
+var templateFuncs = template.FuncMap{
+	"query": url.QueryEscape, // {{query .Data.to}}
+}
+
+// NewDispatcher parses the hooks' URL templates
+func NewDispatcher(cfg Config, logger *slog.Logger) (*Dispatcher, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	defaults := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaults.InitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = max(defaults.MaxBackoff, cfg.InitialBackoff)
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaults.QueueSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+
+	d := &Dispatcher{
+		cfg:        cfg,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+	for i, hook := range cfg.Hooks {
+		tmpl, err := template.New(fmt.Sprintf("hook%d", i)).Funcs(templateFuncs).Option("missingkey=zero").Parse(hook.URL)
+		if err != nil {
+			return nil, fmt.Errorf("hook %d url: %w", i, err)
+		}
+		w := &hookWorker{hook: hook, url: tmpl, queue: make(chan Event, cfg.QueueSize)}
+		if len(hook.Events) > 0 {
+			w.events = make(map[string]bool, len(hook.Events))
+			for _, ev := range hook.Events {
+				if !slices.Contains(EventTypes, ev) {
+					return nil, fmt.Errorf("hook %d: unknown event %q", i, ev)
+				}
+				w.events[ev] = true
+			}
+		}
+		d.workers = append(d.workers, w)
+	}
+	return d, nil
+}
+
+// SetPrivacy holds back speech and zone events while privacy mode is on
+func (d *Dispatcher) SetPrivacy(p Privacy) {
+	d.mu.Lock()
+	d.privacy = p
+	d.mu.Unlock()
+}
+
+// Run delivers queued events until ctx is cancelled (blocking, use goroutine)
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, w := range d.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ev := <-w.queue:
+					d.deliver(ctx, w, ev)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Send queues ev for every hook that wants it without blocking
+func (d *Dispatcher) Send(ev Event) {
+	d.mu.Lock()
+	privacy := d.privacy
+	d.mu.Unlock()
+	if private[ev.Type] && privacy != nil && privacy.Enabled() {
+		d.suppressed.Add(1)
+		return
+	}
+
+	if ev.RobotID == "" {
+		ev.RobotID = d.cfg.RobotID
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	for _, w := range d.workers {
+		if w.events != nil && !w.events[ev.Type] {
+			continue
+		}
+		select {
+		case w.queue <- ev:
+		default:
+			d.dropped.Add(1)
+			d.logger.Warn("webhook queue full, event dropped", "type", ev.Type)
+		}
+	}
+}
+
+// deliver POSTs ev to the hook, retrying with backoff
+func (d *Dispatcher) deliver(ctx context.Context, w *hookWorker, ev Event) {
+	var target strings.Builder
+	if err := w.url.Execute(&target, ev); err != nil {
+		d.recordFailure(ev, fmt.Errorf("render url: %w", err))
+		return
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.recordFailure(ev, fmt.Errorf("encode event: %w", err))
+		return
+	}
+
+	backoff := d.cfg.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := d.post(ctx, w.hook, target.String(), body)
+		if err == nil {
+			d.delivered.Add(1)
+			return
+		}
+		if !retry || attempt >= d.cfg.MaxRetries {
+			d.recordFailure(ev, err)
+			return
+		}
+
+		d.retries.Add(1)
+		d.logger.Debug("webhook failed, retrying", "type", ev.Type, "error", err, "retry_in", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, d.cfg.MaxBackoff)
+	}
+}
+
+// post sends one request; retry reports whether a failure is worth retrying
+func (d *Dispatcher) post(ctx context.Context, hook Hook, target string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range hook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return false, nil
+}
+
+func (d *Dispatcher) recordFailure(ev Event, err error) {
+	d.failed.Add(1)
+	d.mu.Lock()
+	d.lastError = err.Error()
+	d.mu.Unlock()
+	d.logger.Warn("webhook delivery failed", "type", ev.Type, "error", err)
+}
+
+// ForwardEvents turns utterance, zone and cloud connection events from the
+// bus into webhook events until ctx ends
+func (d *Dispatcher) ForwardEvents(ctx context.Context, b *bus.Bus) {
+	bus.Handle(ctx, b, doa.TopicUtterances, 0, func(ev doa.UtteranceEvent) {
+		eventType, at := EventSpeechStart, ev.Start
+		if ev.Type == doa.UtteranceEnd {
+			eventType, at = EventSpeechEnd, ev.End
+		}
+		data := map[string]interface{}{
+			"utterance_id": ev.ID,
+			"start":        ev.Start,
+			"angle":        ev.AvgAngle,
+			"peak_energy":  ev.PeakEnergy,
+			"speaker_id":   ev.SpeakerID,
+		}
+		if ev.Type == doa.UtteranceEnd {
+			data["end"] = ev.End
+			data["duration_ms"] = ev.DurationMs
+		}
+		d.Send(Event{Type: eventType, Timestamp: at, Data: data})
+	})
+
+	bus.Handle(ctx, b, doa.TopicZones, 0, func(ev doa.ZoneEvent) {
+		d.Send(Event{Type: EventZoneChange, Timestamp: ev.Timestamp, Data: map[string]interface{}{
+			"from":  ev.From,
+			"to":    ev.To,
+			"angle": ev.Angle,
+		}})
+	})
+
+	bus.Handle(ctx, b, cloud.TopicConnection, 0, func(ev cloud.ConnectionEvent) {
+		// Only an established connection dropping, not every failed retry
+		// or a deliberate shutdown
+		if ev.From != cloud.StateConnected || ev.To == cloud.StateConnected || ev.To == cloud.StateClosed {
+			return
+		}
+		d.Send(Event{Type: EventCloudDisconnect, Timestamp: ev.At, Data: map[string]interface{}{
+			"error": ev.Error,
+			"state": ev.To,
+		}})
+	})
+}
+
+// Stats contains dispatcher statistics
+type Stats struct {
+	Hooks      int    `json:"hooks"`
+	Delivered  uint64 `json:"delivered"`
+	Failed     uint64 `json:"failed"` // Gave up after retries
+	Retries    uint64 `json:"retries"`
+	Dropped    uint64 `json:"dropped"`    // Hook queue full
+	Suppressed uint64 `json:"suppressed"` // Held back by privacy mode
+	Queued     int    `json:"queued"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+// GetStats returns dispatcher statistics
+func (d *Dispatcher) GetStats() Stats {
+	queued := 0
+	for _, w := range d.workers {
+		queued += len(w.queue)
+	}
+	d.mu.Lock()
+	lastError := d.lastError
+	d.mu.Unlock()
+
+	return Stats{
+		Hooks:      len(d.workers),
+		Delivered:  d.delivered.Load(),
+		Failed:     d.failed.Load(),
+		Retries:    d.retries.Load(),
+		Dropped:    d.dropped.Load(),
+		Suppressed: d.suppressed.Load(),
+		Queued:     queued,
+		LastError:  lastError,
+	}
+}
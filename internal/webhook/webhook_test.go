@@ -0,0 +1,240 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/bus"
+	"github.com/teslashibe/go-eva/internal/cloud"
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// recorder is a webhook endpoint that remembers what it received
+type recorder struct {
+	mu     sync.Mutex
+	paths  []string
+	events []Event
+	auth   []string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var ev Event
+	json.NewDecoder(req.Body).Decode(&ev)
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.RequestURI())
+	r.events = append(r.events, ev)
+	r.auth = append(r.auth, req.Header.Get("Authorization"))
+	r.mu.Unlock()
+}
+
+func (r *recorder) received() ([]string, []Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.paths...), append([]Event(nil), r.events...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_TemplatesAndFilters(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.RobotID = "eva-1"
+	cfg.Hooks = []Hook{
+		{
+			URL:     srv.URL + "/speech/{{.Type}}",
+			Events:  []string{EventSpeechStart, EventSpeechEnd},
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		},
+		{URL: srv.URL + "/zone?to={{query .Data.to}}", Events: []string{EventZoneChange}},
+	}
+	d, err := NewDispatcher(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Send(Event{Type: EventSpeechStart})
+	d.Send(Event{Type: EventZoneChange, Data: map[string]interface{}{"to": "front left"}})
+	d.Send(Event{Type: EventCloudDisconnect}) // No hook wants it
+
+	waitFor(t, func() bool { return d.GetStats().Delivered == 2 })
+	paths, events := rec.received()
+	want := map[string]bool{"/speech/speech_start": true, "/zone?to=front+left": true}
+	for _, p := range paths {
+		if !want[p] {
+			t.Errorf("unexpected request %q", p)
+		}
+	}
+	for _, ev := range events {
+		if ev.RobotID != "eva-1" || ev.Timestamp.IsZero() {
+			t.Errorf("event = %+v, want robot id and timestamp", ev)
+		}
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for i, p := range rec.paths {
+		if p == "/speech/speech_start" && rec.auth[i] != "Bearer secret" {
+			t.Errorf("Authorization = %q", rec.auth[i])
+		}
+	}
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 4 * time.Millisecond
+	cfg.Hooks = []Hook{{URL: srv.URL + "/flaky"}, {URL: srv.URL + "/bad"}}
+	d, err := NewDispatcher(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Send(Event{Type: EventSpeechEnd})
+	waitFor(t, func() bool {
+		s := d.GetStats()
+		return s.Delivered+s.Failed == 2
+	})
+
+	// 503 is retried until it succeeds, 400 is not retried at all
+	stats := d.GetStats()
+	if stats.Delivered != 1 || stats.Failed != 1 || stats.Retries != 2 {
+		t.Errorf("stats = %+v, want 1 delivered, 1 failed, 2 retries", stats)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("flaky hook called %d times, want 3", calls.Load())
+	}
+}
+
+type privacySwitch struct{ on atomic.Bool }
+
+func (p *privacySwitch) Enabled() bool { return p.on.Load() }
+
+func TestDispatcher_Privacy(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Hooks = []Hook{{URL: srv.URL + "/{{.Type}}"}}
+	d, err := NewDispatcher(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	privacy := &privacySwitch{}
+	privacy.on.Store(true)
+	d.SetPrivacy(privacy)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// Speech and zones say where someone is; disconnects don't
+	d.Send(Event{Type: EventSpeechStart})
+	d.Send(Event{Type: EventZoneChange})
+	d.Send(Event{Type: EventCloudDisconnect})
+	waitFor(t, func() bool { return d.GetStats().Delivered == 1 })
+	if s := d.GetStats(); s.Suppressed != 2 {
+		t.Errorf("suppressed = %d, want 2", s.Suppressed)
+	}
+
+	privacy.on.Store(false)
+	d.Send(Event{Type: EventSpeechEnd})
+	waitFor(t, func() bool { return d.GetStats().Delivered == 2 })
+	paths, _ := rec.received()
+	if len(paths) != 2 || paths[0] != "/cloud_disconnect" || paths[1] != "/speech_end" {
+		t.Errorf("received %v, want cloud_disconnect then speech_end", paths)
+	}
+}
+
+func TestNewDispatcher_Invalid(t *testing.T) {
+	tests := map[string]Hook{
+		"bad template":  {URL: "http://x/{{.Type"},
+		"unknown event": {URL: "http://x/", Events: []string{"sneeze"}},
+	}
+	for name, hook := range tests {
+		if _, err := NewDispatcher(Config{Hooks: []Hook{hook}}, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestDispatcher_ForwardEvents(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Hooks = []Hook{{URL: srv.URL + "/{{.Type}}"}}
+	d, err := NewDispatcher(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := bus.New(nil)
+	d.ForwardEvents(ctx, b)
+	go d.Run(ctx)
+
+	now := time.Now()
+	bus.Publish(b, doa.TopicUtterances, doa.UtteranceEvent{Type: doa.UtteranceStart, ID: 1, Start: now})
+	bus.Publish(b, doa.TopicZones, doa.ZoneEvent{From: "left", To: "front", Timestamp: now})
+	// Failed retries and shutdown are not disconnects
+	bus.Publish(b, cloud.TopicConnection, cloud.ConnectionEvent{From: cloud.StateConnecting, To: cloud.StateBackoff})
+	bus.Publish(b, cloud.TopicConnection, cloud.ConnectionEvent{From: cloud.StateConnected, To: cloud.StateClosed})
+	bus.Publish(b, cloud.TopicConnection, cloud.ConnectionEvent{From: cloud.StateConnected, To: cloud.StateBackoff, Error: "eof"})
+
+	waitFor(t, func() bool { return d.GetStats().Delivered == 3 })
+	time.Sleep(20 * time.Millisecond)
+	paths, events := rec.received()
+	if len(paths) != 3 {
+		t.Fatalf("received %v, want 3 events", paths)
+	}
+	got := map[string]Event{}
+	for _, ev := range events {
+		got[ev.Type] = ev
+	}
+	if ev, ok := got[EventZoneChange]; !ok || ev.Data["to"] != "front" {
+		t.Errorf("zone_change = %+v", ev)
+	}
+	if ev, ok := got[EventCloudDisconnect]; !ok || ev.Data["error"] != "eof" {
+		t.Errorf("cloud_disconnect = %+v", ev)
+	}
+	if _, ok := got[EventSpeechStart]; !ok {
+		t.Errorf("no speech_start in %v", paths)
+	}
+}
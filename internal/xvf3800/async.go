@@ -0,0 +1,144 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// Errors returned by asynchronous device access
+var (
+	ErrNoReading    = errors.New("no DOA reading yet")
+	ErrStaleReading = errors.New("DOA reading is stale")
+	ErrQueueFull    = errors.New("device request queue full")
+	ErrClosed       = errors.New("device closed")
+)
+
+// snapshot is the outcome of the latest device read
+type snapshot struct {
+	reading doa.Reading
+	err     error
+	at      time.Time
+}
+
+// asyncReader owns a device from a single worker goroutine: it reads at its
+// own cadence and publishes the latest result, and runs other requests from a
+// bounded queue between reads, so callers never block on device I/O
+type asyncReader struct {
+	interval   time.Duration
+	staleAfter time.Duration
+	read       func() (doa.Reading, error)
+
+	latest atomic.Pointer[snapshot]
+	jobs   chan func()
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	// Stats
+	reads      atomic.Uint64
+	readErrors atomic.Uint64
+	queueFull  atomic.Uint64
+	readTimeNs atomic.Int64 // Duration of the latest read
+}
+
+// newAsyncReader starts the worker; read is only ever called from it
+func newAsyncReader(interval, staleAfter time.Duration, queueSize int, read func() (doa.Reading, error)) *asyncReader {
+	r := &asyncReader{
+		interval:   interval,
+		staleAfter: staleAfter,
+		read:       read,
+		jobs:       make(chan func(), queueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// run reads every interval and drains queued requests in between
+func (r *asyncReader) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.poll()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case job := <-r.jobs:
+			job()
+		case <-ticker.C:
+			r.poll()
+		}
+	}
+}
+
+// poll performs one read and publishes the result
+func (r *asyncReader) poll() {
+	start := time.Now()
+	reading, err := r.read()
+	r.readTimeNs.Store(int64(time.Since(start)))
+
+	r.reads.Add(1)
+	if err != nil {
+		r.readErrors.Add(1)
+	}
+	r.latest.Store(&snapshot{reading: reading, err: err, at: time.Now()})
+}
+
+// Latest returns the most recent reading without touching the device
+func (r *asyncReader) Latest() (doa.Reading, error) {
+	select {
+	case <-r.stop:
+		return doa.Reading{}, ErrClosed
+	default:
+	}
+
+	s := r.latest.Load()
+	if s == nil {
+		return doa.Reading{}, ErrNoReading
+	}
+	if s.err != nil {
+		return doa.Reading{}, s.err
+	}
+	if age := time.Since(s.at); age > r.staleAfter {
+		return doa.Reading{}, ErrStaleReading
+	}
+	return s.reading, nil
+}
+
+// Do runs fn on the worker goroutine and waits for its result. It fails fast
+// with ErrQueueFull instead of waiting for room in the queue.
+func (r *asyncReader) Do(ctx context.Context, fn func() error) error {
+	result := make(chan error, 1)
+	select {
+	case <-r.stop:
+		return ErrClosed
+	case r.jobs <- func() { result <- fn() }:
+	default:
+		r.queueFull.Add(1)
+		return ErrQueueFull
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.done:
+		return ErrClosed
+	}
+}
+
+// Stop ends the worker and waits for an in-flight read or request to finish
+func (r *asyncReader) Stop() {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+}
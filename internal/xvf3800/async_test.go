@@ -0,0 +1,129 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func TestAsyncReader_Latest(t *testing.T) {
+	var n atomic.Int64
+	r := newAsyncReader(5*time.Millisecond, time.Second, 4, func() (doa.Reading, error) {
+		return doa.Reading{Angle: float64(n.Add(1)), Timestamp: time.Now()}, nil
+	})
+	defer r.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for n.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	reading, err := r.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if reading.Angle < 1 {
+		t.Errorf("Angle = %v, want a published reading", reading.Angle)
+	}
+
+	r.Stop()
+	if _, err := r.Latest(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Latest() after Stop error = %v, want ErrClosed", err)
+	}
+}
+
+func TestAsyncReader_SlowReadDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int64
+	r := newAsyncReader(5*time.Millisecond, 50*time.Millisecond, 4, func() (doa.Reading, error) {
+		if calls.Add(1) > 1 {
+			<-release // Simulate a stalled control transfer
+		}
+		return doa.Reading{Angle: 1}, nil
+	})
+	defer func() {
+		close(release)
+		r.Stop()
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if _, err := r.Latest(); err != nil {
+		t.Errorf("Latest() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("Latest() took %v while the device was stalled", elapsed)
+	}
+
+	// Once the stall outlasts staleAfter, readers are told so
+	time.Sleep(60 * time.Millisecond)
+	if _, err := r.Latest(); !errors.Is(err, ErrStaleReading) {
+		t.Errorf("Latest() error = %v, want ErrStaleReading", err)
+	}
+}
+
+func TestAsyncReader_ReadError(t *testing.T) {
+	readErr := errors.New("transfer failed")
+	r := newAsyncReader(5*time.Millisecond, time.Second, 4, func() (doa.Reading, error) {
+		return doa.Reading{}, readErr
+	})
+	defer r.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for r.reads.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := r.Latest(); !errors.Is(err, readErr) {
+		t.Errorf("Latest() error = %v, want %v", err, readErr)
+	}
+	if r.readErrors.Load() == 0 {
+		t.Error("read errors should be counted")
+	}
+}
+
+func TestAsyncReader_Do(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int64
+	r := newAsyncReader(time.Hour, time.Hour, 1, func() (doa.Reading, error) {
+		if calls.Add(1) == 1 {
+			<-release // Hold the worker inside the first read
+		}
+		return doa.Reading{}, nil
+	})
+	defer r.Stop()
+
+	// One request fits in the queue; the next fails fast
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	queued := make(chan error, 1)
+	go func() { queued <- r.Do(context.Background(), func() error { return errors.New("job ran") }) }()
+
+	deadline := time.Now().Add(time.Second)
+	for len(r.jobs) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := r.Do(ctx, func() error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Do() error = %v, want ErrQueueFull", err)
+	}
+	if r.queueFull.Load() != 1 {
+		t.Errorf("queueFull = %d, want 1", r.queueFull.Load())
+	}
+
+	close(release)
+	select {
+	case err := <-queued:
+		if err == nil || err.Error() != "job ran" {
+			t.Errorf("queued Do() error = %v, want the job's result", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued job did not run")
+	}
+}
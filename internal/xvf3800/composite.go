@@ -0,0 +1,367 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// ErrNoControl is returned when the active backend has no control interface
+var ErrNoControl = errors.New("backend does not support parameter control")
+
+// Backend opens one DOA source in a CompositeSource chain
+type Backend struct {
+	Name string
+	Open func(logger *slog.Logger) (doa.Source, error)
+}
+
+// USBBackend opens the XVF3800 over libusb
+func USBBackend() Backend {
+	return USBBackendWithConfig(DefaultUSBSourceConfig())
+}
+
+// USBBackendWithConfig opens the XVF3800 over libusb with cfg
+func USBBackendWithConfig(cfg USBSourceConfig) Backend {
+	return Backend{
+		Name: "usb",
+		Open: func(logger *slog.Logger) (doa.Source, error) { return NewUSBSourceWithConfig(logger, cfg) },
+	}
+}
+
+// PythonBackend runs the Python subprocess reader
+func PythonBackend(cfg PythonConfig) Backend {
+	return Backend{
+		Name: "python",
+		Open: func(logger *slog.Logger) (doa.Source, error) { return NewPythonSource(cfg, logger) },
+	}
+}
+
+// MockBackend is the always-available last resort
+func MockBackend() Backend {
+	return Backend{
+		Name: "mock",
+		Open: func(*slog.Logger) (doa.Source, error) { return NewMockSource(), nil },
+	}
+}
+
+// CompositeConfig configures failover between backends
+type CompositeConfig struct {
+	ProbeInterval time.Duration // How often higher-priority backends are retried
+	FailAfter     int           // Consecutive read errors before failing over
+}
+
+// DefaultCompositeConfig returns sensible defaults
+func DefaultCompositeConfig() CompositeConfig {
+	return CompositeConfig{
+		ProbeInterval: 30 * time.Second,
+		FailAfter:     10,
+	}
+}
+
+// BackendStatus describes one backend in the chain
+type BackendStatus struct {
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	LastError string    `json:"last_error,omitempty"`
+	LastProbe time.Time `json:"last_probe,omitempty"`
+}
+
+// CompositeStatus describes the chain for /health
+type CompositeStatus struct {
+	Active    string          `json:"active"`
+	Failovers uint64          `json:"failovers"`
+	Backends  []BackendStatus `json:"backends"`
+}
+
+// CompositeSource reads from the highest-priority backend that works, fails
+// over down the chain on errors, and periodically re-probes the backends
+// above the active one so hardware that appears late is picked up
+type CompositeSource struct {
+	backends []Backend
+	cfg      CompositeConfig
+	logger   *slog.Logger
+
+	switchMu sync.Mutex // Serializes probing and failover
+
+	mu        sync.Mutex
+	active    doa.Source
+	activeIdx int
+	errors    int
+	failovers uint64
+	status    []BackendStatus
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCompositeSource opens the first working backend in priority order and
+// starts re-probing in the background
+func NewCompositeSource(backends []Backend, cfg CompositeConfig, logger *slog.Logger) (*CompositeSource, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = DefaultCompositeConfig().ProbeInterval
+	}
+	if cfg.FailAfter <= 0 {
+		cfg.FailAfter = DefaultCompositeConfig().FailAfter
+	}
+
+	c := &CompositeSource{
+		backends:  backends,
+		cfg:       cfg,
+		logger:    logger,
+		activeIdx: -1,
+		status:    make([]BackendStatus, len(backends)),
+		done:      make(chan struct{}),
+	}
+	for i, b := range backends {
+		c.status[i].Name = b.Name
+	}
+
+	for i := range backends {
+		if src := c.open(i); src != nil {
+			c.setActive(i, src)
+			break
+		}
+	}
+	if c.active == nil {
+		return nil, fmt.Errorf("no DOA backend available")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go c.probeLoop(ctx)
+
+	return c, nil
+}
+
+// open tries backend i and records the outcome
+func (c *CompositeSource) open(i int) doa.Source {
+	src, err := c.backends[i].Open(c.logger)
+
+	c.mu.Lock()
+	c.status[i].LastProbe = time.Now()
+	if err != nil {
+		c.status[i].LastError = err.Error()
+	} else {
+		c.status[i].LastError = ""
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		c.logger.Debug("DOA backend unavailable", "backend", c.backends[i].Name, "error", err)
+		return nil
+	}
+	return src
+}
+
+// setActive switches to src, closing the previous source
+func (c *CompositeSource) setActive(i int, src doa.Source) {
+	c.mu.Lock()
+	prev, prevIdx := c.active, c.activeIdx
+	c.active = src
+	c.activeIdx = i
+	c.errors = 0
+	for j := range c.status {
+		c.status[j].Active = j == i
+	}
+	if prev != nil {
+		c.failovers++
+	}
+	c.mu.Unlock()
+
+	if prev != nil {
+		prev.Close()
+		c.logger.Warn("DOA source switched",
+			"from", c.backends[prevIdx].Name,
+			"to", c.backends[i].Name,
+		)
+	} else {
+		c.logger.Info("DOA source selected", "backend", c.backends[i].Name)
+	}
+}
+
+// probeLoop retries higher-priority backends and replaces an unhealthy one
+func (c *CompositeSource) probeLoop(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.cfg.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.probe()
+		}
+	}
+}
+
+// probe switches to the best backend above the active one, if any opens
+func (c *CompositeSource) probe() {
+	c.switchMu.Lock()
+	defer c.switchMu.Unlock()
+
+	c.mu.Lock()
+	activeIdx := c.activeIdx
+	healthy := c.active != nil && c.active.Healthy()
+	c.mu.Unlock()
+
+	for i := 0; i < activeIdx; i++ {
+		if src := c.open(i); src != nil {
+			c.setActive(i, src)
+			return
+		}
+	}
+	if !healthy {
+		c.failoverLocked(activeIdx)
+	}
+}
+
+// failoverLocked replaces the failed backend with the best other one that
+// opens. Caller holds switchMu.
+func (c *CompositeSource) failoverLocked(failed int) {
+	for i := range c.backends {
+		if i == failed {
+			continue
+		}
+		if src := c.open(i); src != nil {
+			c.setActive(i, src)
+			return
+		}
+	}
+	c.logger.Warn("DOA failover found no other backend", "backend", c.backends[failed].Name)
+}
+
+// GetDOA reads from the active backend, failing over after repeated errors
+func (c *CompositeSource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	c.mu.Lock()
+	src, idx := c.active, c.activeIdx
+	c.mu.Unlock()
+
+	reading, err := src.GetDOA(ctx)
+
+	c.mu.Lock()
+	if c.active != src {
+		// Switched while reading
+		c.mu.Unlock()
+		return reading, err
+	}
+	if err == nil {
+		c.errors = 0
+		c.mu.Unlock()
+		return reading, nil
+	}
+	c.errors++
+	c.status[idx].LastError = err.Error()
+	fail := c.errors >= c.cfg.FailAfter
+	if fail {
+		c.errors = 0
+	}
+	c.mu.Unlock()
+
+	if fail {
+		c.switchMu.Lock()
+		c.mu.Lock()
+		stillActive := c.active == src
+		c.mu.Unlock()
+		if stillActive {
+			c.failoverLocked(idx)
+		}
+		c.switchMu.Unlock()
+	}
+	return reading, err
+}
+
+// Close stops probing and closes the active backend
+func (c *CompositeSource) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active != nil {
+		return c.active.Close()
+	}
+	return nil
+}
+
+// Healthy returns true if the active backend is operational
+func (c *CompositeSource) Healthy() bool {
+	c.mu.Lock()
+	src := c.active
+	c.mu.Unlock()
+	return src != nil && src.Healthy()
+}
+
+// Name returns the active backend's name
+func (c *CompositeSource) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.activeIdx < 0 {
+		return "none"
+	}
+	return c.backends[c.activeIdx].Name
+}
+
+// Status returns the active backend and per-backend probe results
+func (c *CompositeSource) Status() CompositeStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := CompositeStatus{
+		Failovers: c.failovers,
+		Backends:  append([]BackendStatus(nil), c.status...),
+	}
+	if c.activeIdx >= 0 {
+		status.Active = c.backends[c.activeIdx].Name
+	}
+	return status
+}
+
+// Active returns the active backend's source
+func (c *CompositeSource) Active() doa.Source {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+// controller returns the active backend as a ParamController
+func (c *CompositeSource) controller() (ParamController, error) {
+	c.mu.Lock()
+	src := c.active
+	c.mu.Unlock()
+
+	ctrl, ok := src.(ParamController)
+	if !ok {
+		return nil, fmt.Errorf("active DOA backend %q: %w", src.Name(), ErrNoControl)
+	}
+	return ctrl, nil
+}
+
+// ReadParam reads a parameter through the active backend
+func (c *CompositeSource) ReadParam(ctx context.Context, p Param) ([]float64, error) {
+	ctrl, err := c.controller()
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.ReadParam(ctx, p)
+}
+
+// WriteParam writes a parameter through the active backend
+func (c *CompositeSource) WriteParam(ctx context.Context, p Param, values []float64) error {
+	ctrl, err := c.controller()
+	if err != nil {
+		return err
+	}
+	return ctrl.WriteParam(ctx, p, values)
+}
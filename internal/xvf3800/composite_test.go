@@ -0,0 +1,168 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// flakySource fails reads while broken
+type flakySource struct {
+	name string
+
+	mu     sync.Mutex
+	broken bool
+	closed bool
+}
+
+func (f *flakySource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.broken {
+		return doa.Reading{}, errors.New("read failed")
+	}
+	return doa.Reading{Timestamp: time.Now()}, nil
+}
+
+func (f *flakySource) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *flakySource) Healthy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.broken
+}
+
+func (f *flakySource) Name() string { return f.name }
+
+func (f *flakySource) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func (f *flakySource) setBroken(broken bool) {
+	f.mu.Lock()
+	f.broken = broken
+	f.mu.Unlock()
+}
+
+// switchableBackend opens only while available is set
+type switchableBackend struct {
+	mu        sync.Mutex
+	available bool
+	opened    []*flakySource
+}
+
+func (b *switchableBackend) backend(name string) Backend {
+	return Backend{
+		Name: name,
+		Open: func(*slog.Logger) (doa.Source, error) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if !b.available {
+				return nil, errors.New(name + " unavailable")
+			}
+			src := &flakySource{name: name}
+			b.opened = append(b.opened, src)
+			return src, nil
+		},
+	}
+}
+
+func (b *switchableBackend) setAvailable(available bool) {
+	b.mu.Lock()
+	b.available = available
+	b.mu.Unlock()
+}
+
+func (b *switchableBackend) last() *flakySource {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.opened[len(b.opened)-1]
+}
+
+func TestCompositeSource_PromotesLateBackend(t *testing.T) {
+	usb := &switchableBackend{}
+	python := &switchableBackend{available: true}
+
+	c, err := NewCompositeSource([]Backend{
+		usb.backend("usb"),
+		python.backend("python"),
+		MockBackend(),
+	}, CompositeConfig{ProbeInterval: 10 * time.Millisecond, FailAfter: 3}, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeSource() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.Name() != "python" {
+		t.Fatalf("active = %s, want python", c.Name())
+	}
+
+	// USB enumerates late; the probe loop should switch up to it
+	usb.setAvailable(true)
+	deadline := time.Now().Add(time.Second)
+	for c.Name() != "usb" || !python.last().isClosed() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for promotion to usb and python to close")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	status := c.Status()
+	if status.Active != "usb" || status.Failovers != 1 || !status.Backends[0].Active {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestCompositeSource_FailsOverOnErrors(t *testing.T) {
+	usb := &switchableBackend{available: true}
+
+	c, err := NewCompositeSource([]Backend{
+		usb.backend("usb"),
+		MockBackend(),
+	}, CompositeConfig{ProbeInterval: time.Hour, FailAfter: 3}, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeSource() error = %v", err)
+	}
+	defer c.Close()
+
+	usb.last().setBroken(true)
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetDOA(context.Background()); err == nil {
+			t.Fatalf("read %d: expected error from broken usb", i)
+		}
+	}
+
+	if c.Name() != "mock" {
+		t.Fatalf("active = %s, want mock after %d errors", c.Name(), 3)
+	}
+	if _, err := c.GetDOA(context.Background()); err != nil {
+		t.Errorf("GetDOA() after failover error = %v", err)
+	}
+	if c.Status().Backends[0].LastError == "" {
+		t.Error("expected usb last_error to be recorded")
+	}
+
+	// Parameter control follows the active backend
+	if _, err := c.ReadParam(context.Background(), DefaultParams()[0]); err != nil {
+		t.Errorf("ReadParam() via mock error = %v", err)
+	}
+}
+
+func TestCompositeSource_NoBackends(t *testing.T) {
+	unavailable := &switchableBackend{}
+	if _, err := NewCompositeSource([]Backend{unavailable.backend("usb")}, DefaultCompositeConfig(), nil); err == nil {
+		t.Error("expected error when no backend opens")
+	}
+}
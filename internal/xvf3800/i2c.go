@@ -0,0 +1,414 @@
+package xvf3800
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/io/i2c"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// DefaultI2CAddress is the XVF3800's 7-bit I2C address
+const DefaultI2CAddress = 0x2C
+
+// i2cDevice is the bus access I2CSource needs; *i2c.Device implements it
+type i2cDevice interface {
+	Read(buf []byte) error
+	Write(buf []byte) error
+	Close() error
+}
+
+// I2CSourceConfig configures the I2C source
+type I2CSourceConfig struct {
+	Bus                  string // I2C bus device (e.g. "/dev/i2c-1")
+	Address              int    // 7-bit device address
+	MaxConsecutiveErrors int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	PollInterval         time.Duration // Worker read cadence
+	StaleAfter           time.Duration // GetDOA fails once the latest reading is older than this
+	QueueSize            int           // Pending parameter reads/writes before ErrQueueFull
+}
+
+// DefaultI2CSourceConfig returns sensible defaults
+func DefaultI2CSourceConfig() I2CSourceConfig {
+	usb := DefaultUSBSourceConfig()
+	return I2CSourceConfig{
+		Bus:                  "/dev/i2c-1",
+		Address:              DefaultI2CAddress,
+		MaxConsecutiveErrors: usb.MaxConsecutiveErrors,
+		InitialBackoff:       usb.InitialBackoff,
+		MaxBackoff:           usb.MaxBackoff,
+		PollInterval:         usb.PollInterval,
+		StaleAfter:           usb.StaleAfter,
+		QueueSize:            usb.QueueSize,
+	}
+}
+
+// I2CSource reads the XVF3800 over I2C, for boards that wire its control
+// interface to the host's I2C bus instead of USB. Commands use the same
+// resid/cmdid framing as USB: a read writes [resid, 0x80|cmdid, length]
+// and then reads length bytes, the first being the status byte; a write
+// sends [resid, cmdid, length, payload...].
+type I2CSource struct {
+	cfg    I2CSourceConfig
+	logger *slog.Logger
+	worker *asyncReader
+	open   func() (i2cDevice, error)
+
+	mu     sync.Mutex
+	dev    i2cDevice
+	closed bool
+
+	// Health tracking
+	healthy           bool
+	consecutiveErrors int
+	lastError         error
+	lastErrorTime     time.Time
+	backoff           time.Duration
+	reconnects        uint64
+	reconnectFailures uint64
+}
+
+// NewI2CSource opens the XVF3800 on an I2C bus
+func NewI2CSource(cfg I2CSourceConfig, logger *slog.Logger) (*I2CSource, error) {
+	return newI2CSource(cfg, func() (i2cDevice, error) {
+		return i2c.Open(&i2c.Devfs{Dev: cfg.Bus}, cfg.Address)
+	}, logger)
+}
+
+// newI2CSource creates a source that opens its device with open
+func newI2CSource(cfg I2CSourceConfig, open func() (i2cDevice, error), logger *slog.Logger) (*I2CSource, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	defaults := DefaultI2CSourceConfig()
+	if cfg.Bus == "" {
+		cfg.Bus = defaults.Bus
+	}
+	if cfg.Address == 0 {
+		cfg.Address = defaults.Address
+	}
+	if cfg.MaxConsecutiveErrors <= 0 {
+		cfg.MaxConsecutiveErrors = defaults.MaxConsecutiveErrors
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaults.InitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaults.MaxBackoff
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaults.StaleAfter
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaults.QueueSize
+	}
+
+	s := &I2CSource{
+		cfg:     cfg,
+		logger:  logger,
+		open:    open,
+		healthy: true,
+		backoff: cfg.InitialBackoff,
+	}
+
+	dev, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XVF3800 on %s at 0x%02X: %w", cfg.Bus, cfg.Address, err)
+	}
+	// Probe so a missing device fails here rather than on the first poll
+	if _, err := s.command(dev, gpoResID, doaCmdID, 8); err != nil {
+		dev.Close()
+		return nil, fmt.Errorf("XVF3800 not responding on %s at 0x%02X: %w", cfg.Bus, cfg.Address, err)
+	}
+	s.dev = dev
+	s.worker = newAsyncReader(cfg.PollInterval, cfg.StaleAfter, cfg.QueueSize, s.readDOA)
+
+	logger.Info("I2C DOA source initialized",
+		"bus", cfg.Bus,
+		"address", fmt.Sprintf("0x%02X", cfg.Address),
+		"poll_interval", cfg.PollInterval,
+	)
+	return s, nil
+}
+
+// i2cHeader frames a command; I2C carries the resid and length in one byte
+// each
+func i2cHeader(resid uint16, cmdid uint8, n int) ([]byte, error) {
+	if resid > 0xFF || n > 0xFF {
+		return nil, fmt.Errorf("resid %d or length %d does not fit I2C framing", resid, n)
+	}
+	return []byte{byte(resid), cmdid, byte(n)}, nil
+}
+
+// command reads n payload bytes of resid/cmdid and checks the status byte
+func (s *I2CSource) command(dev i2cDevice, resid uint16, cmdid uint8, n int) ([]byte, error) {
+	header, err := i2cHeader(resid, 0x80|cmdid, n+1)
+	if err != nil {
+		return nil, err
+	}
+	if err := dev.Write(header); err != nil {
+		return nil, fmt.Errorf("I2C write failed: %w", err)
+	}
+	data := make([]byte, n+1)
+	if err := dev.Read(data); err != nil {
+		return nil, fmt.Errorf("I2C read failed: %w", err)
+	}
+	if data[0] != 0 {
+		return nil, fmt.Errorf("device returned error status: %d", data[0])
+	}
+	return data[1:], nil
+}
+
+// GetDOA returns the worker's latest direction of arrival reading
+func (s *I2CSource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	return s.worker.Latest()
+}
+
+// readDOA reads the direction of arrival from the device (worker only)
+func (s *I2CSource) readDOA() (doa.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureOpen(); err != nil {
+		return doa.Reading{}, err
+	}
+
+	start := time.Now()
+	data, err := s.command(s.dev, gpoResID, doaCmdID, 8) // Angle and speech flag (float32 each)
+	if err != nil {
+		s.recordError(err)
+		return doa.Reading{}, err
+	}
+	s.recordSuccess()
+
+	rawAngle := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[0:4])))
+	speaking := math.Float32frombits(binary.LittleEndian.Uint32(data[4:8])) != 0
+	latency := time.Since(start)
+
+	// Speech energy and per-mic azimuths are optional
+	var energy, azimuths [4]float64
+	if values, err := s.command(s.dev, aecResID, aecSpEnergyCmdID, 16); err == nil {
+		energy = decodeFloat4(values)
+	}
+	if values, err := s.command(s.dev, aecResID, aecAzimuthCmdID, 16); err == nil {
+		azimuths = decodeFloat4(values)
+	}
+
+	return doa.Reading{
+		Angle:        doa.ToEvaAngle(rawAngle),
+		RawAngle:     rawAngle,
+		Speaking:     speaking,
+		Timestamp:    time.Now(),
+		LatencyMs:    latency.Milliseconds(),
+		SpeechEnergy: energy,
+		MicAzimuths:  azimuths,
+		TotalEnergy:  sumEnergy(energy),
+	}, nil
+}
+
+// decodeFloat4 unpacks four little-endian float32s
+func decodeFloat4(data []byte) [4]float64 {
+	var out [4]float64
+	for i := range out {
+		out[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4 : i*4+4])))
+	}
+	return out
+}
+
+// ReadParam reads a control parameter
+func (s *I2CSource) ReadParam(ctx context.Context, p Param) ([]float64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := i2cHeader(p.ResID, p.CmdID, p.Type.Size()*p.Count+1); err != nil {
+		return nil, err
+	}
+
+	var values []float64
+	err := s.worker.Do(ctx, func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := s.ensureOpen(); err != nil {
+			return err
+		}
+		data, err := s.command(s.dev, p.ResID, p.CmdID, p.Type.Size()*p.Count)
+		if err != nil {
+			s.recordError(err)
+			return err
+		}
+		values, err = DecodeParamValues(p.Type, data, p.Count)
+		return err
+	})
+	return values, err
+}
+
+// WriteParam writes a control parameter
+func (s *I2CSource) WriteParam(ctx context.Context, p Param, values []float64) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(values) != p.Count {
+		return fmt.Errorf("expected %d values, got %d", p.Count, len(values))
+	}
+	payload, err := EncodeParamValues(p.Type, values)
+	if err != nil {
+		return err
+	}
+	header, err := i2cHeader(p.ResID, p.CmdID, len(payload))
+	if err != nil {
+		return err
+	}
+
+	return s.worker.Do(ctx, func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := s.ensureOpen(); err != nil {
+			return err
+		}
+		if err := s.dev.Write(append(header, payload...)); err != nil {
+			err = fmt.Errorf("I2C write failed: %w", err)
+			s.recordError(err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ensureOpen reopens the device after it was dropped, with backoff (caller
+// holds mu)
+func (s *I2CSource) ensureOpen() error {
+	if s.closed {
+		return ErrClosed
+	}
+	if s.dev != nil {
+		return nil
+	}
+
+	time.Sleep(s.backoff)
+	s.backoff = min(s.backoff*2, s.cfg.MaxBackoff)
+
+	dev, err := s.open()
+	if err != nil {
+		s.reconnectFailures++
+		s.logger.Warn("I2C reconnect failed", "error", err)
+		return fmt.Errorf("reopen XVF3800: %w", err)
+	}
+	s.reconnects++
+	s.dev = dev
+	s.logger.Info("I2C reconnect successful")
+	return nil
+}
+
+func (s *I2CSource) recordError(err error) {
+	s.consecutiveErrors++
+	s.lastError = err
+	s.lastErrorTime = time.Now()
+
+	if s.consecutiveErrors >= s.cfg.MaxConsecutiveErrors {
+		if s.healthy {
+			s.logger.Warn("I2C source marked unhealthy, will attempt reconnect",
+				"consecutive_errors", s.consecutiveErrors,
+				"last_error", err,
+			)
+		}
+		s.healthy = false
+		if s.dev != nil {
+			s.dev.Close()
+			s.dev = nil
+		}
+	}
+}
+
+func (s *I2CSource) recordSuccess() {
+	if s.consecutiveErrors > 0 {
+		s.logger.Info("I2C source recovered", "previous_errors", s.consecutiveErrors)
+	}
+	s.consecutiveErrors = 0
+	s.healthy = true
+	s.backoff = s.cfg.InitialBackoff
+}
+
+// Close stops the worker and releases the bus
+func (s *I2CSource) Close() error {
+	s.worker.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.dev != nil {
+		s.dev.Close()
+		s.dev = nil
+	}
+	s.logger.Info("I2C source closed")
+	return nil
+}
+
+// Healthy returns true if the source is operational
+func (s *I2CSource) Healthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.healthy
+}
+
+// Name returns the source type name
+func (s *I2CSource) Name() string {
+	return "i2c"
+}
+
+// Stats returns I2C source statistics
+func (s *I2CSource) Stats() I2CStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastErr string
+	if s.lastError != nil {
+		lastErr = s.lastError.Error()
+	}
+	return I2CStats{
+		Bus:               s.cfg.Bus,
+		Address:           s.cfg.Address,
+		Healthy:           s.healthy,
+		ConsecutiveErrors: s.consecutiveErrors,
+		LastError:         lastErr,
+		LastErrorTime:     s.lastErrorTime,
+		DeviceConnected:   s.dev != nil,
+		Reads:             s.worker.reads.Load(),
+		ReadErrors:        s.worker.readErrors.Load(),
+		LastReadMs:        float64(s.worker.readTimeNs.Load()) / float64(time.Millisecond),
+		Reconnects:        s.reconnects,
+		ReconnectFailures: s.reconnectFailures,
+	}
+}
+
+// I2CStats contains I2C source statistics
+type I2CStats struct {
+	Bus               string    `json:"bus"`
+	Address           int       `json:"address"`
+	Healthy           bool      `json:"healthy"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	LastError         string    `json:"last_error,omitempty"`
+	LastErrorTime     time.Time `json:"last_error_time,omitempty"`
+	DeviceConnected   bool      `json:"device_connected"`
+	Reads             uint64    `json:"reads"`
+	ReadErrors        uint64    `json:"read_errors"`
+	LastReadMs        float64   `json:"last_read_ms"` // Duration of the worker's latest DOA read
+	Reconnects        uint64    `json:"reconnects"`
+	ReconnectFailures uint64    `json:"reconnect_failures"`
+}
@@ -0,0 +1,185 @@
+package xvf3800
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeI2C emulates the XVF3800's I2C command framing
+type fakeI2C struct {
+	mu      sync.Mutex
+	values  map[[2]byte][]byte // Payload by resid/cmdid
+	pending []byte             // Header of the read in progress
+	status  byte               // Status byte returned on reads
+	fail    error              // Returned by every transfer when set
+	closed  bool
+}
+
+func newFakeI2C() *fakeI2C {
+	return &fakeI2C{values: map[[2]byte][]byte{
+		{gpoResID, doaCmdID}: float32s(1.5, 1),
+	}}
+}
+
+func float32s(values ...float32) []byte {
+	buf := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func (f *fakeI2C) Write(buf []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != nil {
+		return f.fail
+	}
+	if len(buf) < 3 {
+		return errors.New("short frame")
+	}
+	if buf[1]&0x80 != 0 {
+		f.pending = buf
+		return nil
+	}
+	if int(buf[2]) != len(buf)-3 {
+		return errors.New("length does not match payload")
+	}
+	f.values[[2]byte{buf[0], buf[1]}] = append([]byte(nil), buf[3:]...)
+	return nil
+}
+
+func (f *fakeI2C) Read(buf []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != nil {
+		return f.fail
+	}
+	if f.pending == nil || int(f.pending[2]) != len(buf) {
+		return errors.New("read without a matching command")
+	}
+	clear(buf)
+	buf[0] = f.status
+	copy(buf[1:], f.values[[2]byte{f.pending[0], f.pending[1] &^ 0x80}])
+	f.pending = nil
+	return nil
+}
+
+func (f *fakeI2C) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeI2C) set(fn func(f *fakeI2C)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(f)
+}
+
+func testI2CConfig() I2CSourceConfig {
+	cfg := DefaultI2CSourceConfig()
+	cfg.PollInterval = 5 * time.Millisecond
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	cfg.MaxConsecutiveErrors = 2
+	return cfg
+}
+
+func TestI2CSource_GetDOA(t *testing.T) {
+	dev := newFakeI2C()
+	dev.values[[2]byte{aecResID, aecSpEnergyCmdID}] = float32s(1, 2, 3, 4)
+	s, err := newI2CSource(testI2CConfig(), func() (i2cDevice, error) { return dev, nil }, nil)
+	if err != nil {
+		t.Fatalf("newI2CSource() error = %v", err)
+	}
+	defer s.Close()
+
+	reading, lastErr := s.GetDOA(context.Background())
+	for deadline := time.Now().Add(time.Second); lastErr != nil && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		reading, lastErr = s.GetDOA(context.Background())
+	}
+	if lastErr != nil {
+		t.Fatalf("GetDOA() error = %v", lastErr)
+	}
+	if reading.RawAngle != 1.5 || !reading.Speaking || reading.TotalEnergy != 10 {
+		t.Errorf("reading = %+v", reading)
+	}
+	if s.Name() != "i2c" || !s.Healthy() || !s.Stats().DeviceConnected {
+		t.Errorf("stats = %+v", s.Stats())
+	}
+}
+
+func TestI2CSource_ProbeFails(t *testing.T) {
+	dev := newFakeI2C()
+	dev.status = 3
+	if _, err := newI2CSource(testI2CConfig(), func() (i2cDevice, error) { return dev, nil }, nil); err == nil {
+		t.Fatal("newI2CSource() succeeded with an error status")
+	}
+	if !dev.closed {
+		t.Error("device not closed after a failed probe")
+	}
+}
+
+func TestI2CSource_Reconnect(t *testing.T) {
+	dev := newFakeI2C()
+	s, err := newI2CSource(testI2CConfig(), func() (i2cDevice, error) { return dev, nil }, nil)
+	if err != nil {
+		t.Fatalf("newI2CSource() error = %v", err)
+	}
+	defer s.Close()
+
+	dev.set(func(f *fakeI2C) { f.fail = errors.New("nack") })
+	for deadline := time.Now().Add(time.Second); s.Healthy() && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s.Healthy() {
+		t.Fatal("source still healthy after repeated errors")
+	}
+
+	dev.set(func(f *fakeI2C) { f.fail = nil })
+	for deadline := time.Now().Add(time.Second); !s.Healthy() && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := s.Stats(); !stats.Healthy || stats.Reconnects == 0 {
+		t.Errorf("after recovery stats = %+v", stats)
+	}
+}
+
+func TestI2CSource_Params(t *testing.T) {
+	dev := newFakeI2C()
+	s, err := newI2CSource(testI2CConfig(), func() (i2cDevice, error) { return dev, nil }, nil)
+	if err != nil {
+		t.Fatalf("newI2CSource() error = %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	p := Param{Name: "AEC_SPENERGY_VALUES", ResID: aecResID, CmdID: aecSpEnergyCmdID, Type: ParamFloat, Count: 4}
+	if err := s.WriteParam(ctx, p, []float64{0.5, 1, 1.5, 2}); err != nil {
+		t.Fatalf("WriteParam() error = %v", err)
+	}
+	values, err := s.ReadParam(ctx, p)
+	if err != nil {
+		t.Fatalf("ReadParam() error = %v", err)
+	}
+	if len(values) != 4 || values[0] != 0.5 || values[3] != 2 {
+		t.Errorf("ReadParam() = %v", values)
+	}
+
+	wide := p
+	wide.ResID = 300
+	if _, err := s.ReadParam(ctx, wide); err == nil {
+		t.Error("ReadParam() accepted a resid that does not fit I2C framing")
+	}
+	if s.Stats().ConsecutiveErrors != 0 {
+		t.Error("a framing error counted against device health")
+	}
+}
@@ -0,0 +1,132 @@
+package xvf3800
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// USB control commands, used as the command label
+const (
+	cmdDOA        = "doa"
+	cmdSpEnergy   = "spenergy"
+	cmdAzimuth    = "azimuth"
+	cmdParamRead  = "param_read"
+	cmdParamWrite = "param_write"
+)
+
+// USBMetrics collects USB transfer metrics. It outlives individual sources,
+// so counters keep accumulating when the composite source reopens the device.
+type USBMetrics struct {
+	transferLatency *prometheus.HistogramVec // command
+	transferErrors  *prometheus.CounterVec   // command
+	statusErrors    *prometheus.CounterVec   // command, code
+	reconnects      *prometheus.CounterVec   // result
+}
+
+// NewUSBMetrics creates an empty metric set
+func NewUSBMetrics() *USBMetrics {
+	return &USBMetrics{
+		transferLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_eva_xvf3800_usb_transfer_duration_seconds",
+			Help:    "XVF3800 USB control transfer latency",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 14), // 0.1ms to 0.8s
+		}, []string{"command"}),
+		transferErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_xvf3800_usb_transfer_errors_total",
+			Help: "XVF3800 USB control transfers that failed",
+		}, []string{"command"}),
+		statusErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_xvf3800_status_errors_total",
+			Help: "Non-zero status bytes returned by the XVF3800",
+		}, []string{"command", "code"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_eva_xvf3800_reconnects_total",
+			Help: "XVF3800 USB reconnect attempts by result",
+		}, []string{"result"}),
+	}
+}
+
+// RegisterMetrics registers the USB collectors
+func (m *USBMetrics) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(m.transferLatency, m.transferErrors, m.statusErrors, m.reconnects)
+}
+
+// transferStats accumulates one command's transfers
+type transferStats struct {
+	count  uint64
+	errors uint64
+	total  time.Duration
+	max    time.Duration
+}
+
+// TransferStats summarizes the control transfers of one command
+type TransferStats struct {
+	Count  uint64  `json:"count"`
+	Errors uint64  `json:"errors"`
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// observeTransfer records a control transfer's latency and outcome; status
+// is the device status byte of a read (0 = ok) (caller holds mu)
+func (u *USBSource) observeTransfer(command string, took time.Duration, err error, status byte) {
+	if u.transfers == nil {
+		u.transfers = make(map[string]*transferStats)
+	}
+	ts := u.transfers[command]
+	if ts == nil {
+		ts = &transferStats{}
+		u.transfers[command] = ts
+	}
+	ts.count++
+	ts.total += took
+	ts.max = max(ts.max, took)
+	u.metrics.transferLatency.WithLabelValues(command).Observe(took.Seconds())
+
+	switch {
+	case err != nil:
+		ts.errors++
+		u.metrics.transferErrors.WithLabelValues(command).Inc()
+	case status != 0:
+		ts.errors++
+		if u.statusErrors == nil {
+			u.statusErrors = make(map[uint8]uint64)
+		}
+		u.statusErrors[status]++
+		u.metrics.statusErrors.WithLabelValues(command, strconv.Itoa(int(status))).Inc()
+	}
+}
+
+// observeReconnect records a reconnect attempt (caller holds mu)
+func (u *USBSource) observeReconnect(err error) {
+	result := "ok"
+	u.reconnects++
+	if err != nil {
+		result = "failed"
+		u.reconnectFailures++
+	}
+	u.metrics.reconnects.WithLabelValues(result).Inc()
+}
+
+// transferSnapshot copies the per-command stats (caller holds mu)
+func (u *USBSource) transferSnapshot() (map[string]TransferStats, map[string]uint64) {
+	transfers := make(map[string]TransferStats, len(u.transfers))
+	for command, ts := range u.transfers {
+		transfers[command] = TransferStats{
+			Count:  ts.count,
+			Errors: ts.errors,
+			AvgMs:  float64(ts.total) / float64(ts.count) / float64(time.Millisecond),
+			MaxMs:  float64(ts.max) / float64(time.Millisecond),
+		}
+	}
+	var status map[string]uint64
+	if len(u.statusErrors) > 0 {
+		status = make(map[string]uint64, len(u.statusErrors))
+		for code, n := range u.statusErrors {
+			status[strconv.Itoa(int(code))] = n
+		}
+	}
+	return transfers, status
+}
@@ -2,6 +2,7 @@ package xvf3800
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"sync"
 	"time"
@@ -17,6 +18,7 @@ type MockSource struct {
 	healthy      bool
 	simulateWave bool
 	startTime    time.Time
+	params       map[uint32][]float64
 }
 
 // NewMockSource creates a new mock DOA source
@@ -98,3 +100,36 @@ func (m *MockSource) SetHealthy(healthy bool) {
 	m.healthy = healthy
 }
 
+
+// ReadParam returns the last written values (zeros if never written)
+func (m *MockSource) ReadParam(ctx context.Context, p Param) ([]float64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make([]float64, p.Count)
+	copy(values, m.params[paramKey(p.ResID, p.CmdID)])
+	return values, nil
+}
+
+// WriteParam stores values for later reads
+func (m *MockSource) WriteParam(ctx context.Context, p Param, values []float64) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(values) != p.Count {
+		return fmt.Errorf("expected %d values, got %d", p.Count, len(values))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.params == nil {
+		m.params = make(map[uint32][]float64)
+	}
+	m.params[paramKey(p.ResID, p.CmdID)] = append([]float64(nil), values...)
+	return nil
+}
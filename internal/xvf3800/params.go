@@ -0,0 +1,187 @@
+package xvf3800
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ParamType is the wire encoding of an XVF3800 control parameter
+type ParamType string
+
+// Parameter types from the XVF3800 host control protocol
+const (
+	ParamUint8   ParamType = "uint8"
+	ParamInt32   ParamType = "int32"
+	ParamUint32  ParamType = "uint32"
+	ParamFloat   ParamType = "float"
+	ParamRadians ParamType = "radians" // float, radians
+)
+
+// Size returns the encoded size of one value in bytes (0 if unknown)
+func (t ParamType) Size() int {
+	switch t {
+	case ParamUint8:
+		return 1
+	case ParamInt32, ParamUint32, ParamFloat, ParamRadians:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Param describes a control parameter addressed by resource and command ID
+type Param struct {
+	Name     string    `json:"name"`
+	ResID    uint16    `json:"resid"`
+	CmdID    uint8     `json:"cmdid"`
+	Type     ParamType `json:"type"`
+	Count    int       `json:"count"`
+	Writable bool      `json:"writable"`
+}
+
+// Validate checks that the parameter can be encoded
+func (p Param) Validate() error {
+	if p.Type.Size() == 0 {
+		return fmt.Errorf("unknown parameter type %q", p.Type)
+	}
+	if p.Count <= 0 || p.Count > 64 {
+		return fmt.Errorf("parameter count must be 1-64, got %d", p.Count)
+	}
+	if p.CmdID >= 0x80 {
+		return fmt.Errorf("cmdid must be below 0x80, got %d", p.CmdID)
+	}
+	return nil
+}
+
+// ParamController reads and writes raw control parameters
+type ParamController interface {
+	ReadParam(ctx context.Context, p Param) ([]float64, error)
+	WriteParam(ctx context.Context, p Param, values []float64) error
+}
+
+// EncodeParamValues packs values little-endian for a control write
+func EncodeParamValues(t ParamType, values []float64) ([]byte, error) {
+	size := t.Size()
+	if size == 0 {
+		return nil, fmt.Errorf("unknown parameter type %q", t)
+	}
+
+	buf := make([]byte, size*len(values))
+	for i, v := range values {
+		off := i * size
+		switch t {
+		case ParamUint8:
+			if v < 0 || v > math.MaxUint8 || v != math.Trunc(v) {
+				return nil, fmt.Errorf("value %v out of range for uint8", v)
+			}
+			buf[off] = uint8(v)
+		case ParamInt32:
+			if v < math.MinInt32 || v > math.MaxInt32 || v != math.Trunc(v) {
+				return nil, fmt.Errorf("value %v out of range for int32", v)
+			}
+			binary.LittleEndian.PutUint32(buf[off:], uint32(int32(v)))
+		case ParamUint32:
+			if v < 0 || v > math.MaxUint32 || v != math.Trunc(v) {
+				return nil, fmt.Errorf("value %v out of range for uint32", v)
+			}
+			binary.LittleEndian.PutUint32(buf[off:], uint32(v))
+		case ParamFloat, ParamRadians:
+			binary.LittleEndian.PutUint32(buf[off:], math.Float32bits(float32(v)))
+		}
+	}
+	return buf, nil
+}
+
+// DecodeParamValues unpacks count little-endian values
+func DecodeParamValues(t ParamType, data []byte, count int) ([]float64, error) {
+	size := t.Size()
+	if size == 0 {
+		return nil, fmt.Errorf("unknown parameter type %q", t)
+	}
+	if len(data) < size*count {
+		return nil, fmt.Errorf("short parameter data: got %d bytes, expected %d", len(data), size*count)
+	}
+
+	values := make([]float64, count)
+	for i := range values {
+		off := i * size
+		switch t {
+		case ParamUint8:
+			values[i] = float64(data[off])
+		case ParamInt32:
+			values[i] = float64(int32(binary.LittleEndian.Uint32(data[off:])))
+		case ParamUint32:
+			values[i] = float64(binary.LittleEndian.Uint32(data[off:]))
+		case ParamFloat, ParamRadians:
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[off:])))
+		}
+	}
+	return values, nil
+}
+
+// Allowlist restricts which parameters may be accessed remotely
+type Allowlist struct {
+	params map[uint32]Param
+	order  []Param
+}
+
+// NewAllowlist builds an allowlist, rejecting invalid or duplicate entries
+func NewAllowlist(params []Param) (*Allowlist, error) {
+	a := &Allowlist{params: make(map[uint32]Param, len(params))}
+	for _, p := range params {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("param %q: %w", p.Name, err)
+		}
+		key := paramKey(p.ResID, p.CmdID)
+		if _, dup := a.params[key]; dup {
+			return nil, fmt.Errorf("param %q: duplicate resid/cmdid %d/%d", p.Name, p.ResID, p.CmdID)
+		}
+		a.params[key] = p
+		a.order = append(a.order, p)
+	}
+	return a, nil
+}
+
+// Lookup returns the allowed parameter for a resid/cmdid pair
+func (a *Allowlist) Lookup(resID uint16, cmdID uint8) (Param, bool) {
+	p, ok := a.params[paramKey(resID, cmdID)]
+	return p, ok
+}
+
+// ByName returns the allowed parameter with the given name
+func (a *Allowlist) ByName(name string) (Param, bool) {
+	for _, p := range a.order {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// Params returns allowed parameters in configuration order
+func (a *Allowlist) Params() []Param {
+	out := make([]Param, len(a.order))
+	copy(out, a.order)
+	return out
+}
+
+func paramKey(resID uint16, cmdID uint8) uint32 {
+	return uint32(resID)<<8 | uint32(cmdID)
+}
+
+// DefaultParams are the read-only parameters go-eva already polls
+func DefaultParams() []Param {
+	return []Param{
+		{Name: "DOA_VALUE_RADIANS", ResID: gpoResID, CmdID: doaCmdID, Type: ParamRadians, Count: 2},
+		{Name: "AEC_MIC_ARRAY_GEO", ResID: aecResID, CmdID: aecMicArrayGeoCmdID, Type: ParamFloat, Count: 12},
+		{Name: "AEC_AZIMUTH_VALUES", ResID: aecResID, CmdID: aecAzimuthCmdID, Type: ParamRadians, Count: 4},
+		{Name: "AEC_SPENERGY_VALUES", ResID: aecResID, CmdID: aecSpEnergyCmdID, Type: ParamFloat, Count: 4},
+		VersionParam,
+	}
+}
+
+// VersionParam is the firmware version (major, minor, patch), a cheap read
+// that doesn't disturb the DSP
+var VersionParam = Param{Name: "VERSION", ResID: appResID, CmdID: versionCmdID, Type: ParamUint8, Count: 3}
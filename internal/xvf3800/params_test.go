@@ -0,0 +1,109 @@
+package xvf3800
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestParamValues_RoundTrip(t *testing.T) {
+	tests := []struct {
+		typ    ParamType
+		values []float64
+	}{
+		{ParamUint8, []float64{0, 1, 255}},
+		{ParamInt32, []float64{-5, 0, 123456}},
+		{ParamUint32, []float64{0, 4000000000}},
+		{ParamFloat, []float64{0.5, -1.25, 3}},
+		{ParamRadians, []float64{math.Pi / 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.typ), func(t *testing.T) {
+			data, err := EncodeParamValues(tt.typ, tt.values)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if len(data) != tt.typ.Size()*len(tt.values) {
+				t.Fatalf("encoded %d bytes, want %d", len(data), tt.typ.Size()*len(tt.values))
+			}
+
+			got, err := DecodeParamValues(tt.typ, data, len(tt.values))
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.values[i]) > 1e-6 {
+					t.Errorf("value %d = %v, want %v", i, got[i], tt.values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeParamValues_OutOfRange(t *testing.T) {
+	if _, err := EncodeParamValues(ParamUint8, []float64{256}); err == nil {
+		t.Error("expected error for uint8 overflow")
+	}
+	if _, err := EncodeParamValues(ParamUint32, []float64{-1}); err == nil {
+		t.Error("expected error for negative uint32")
+	}
+	if _, err := EncodeParamValues(ParamInt32, []float64{1.5}); err == nil {
+		t.Error("expected error for fractional int32")
+	}
+	if _, err := EncodeParamValues("double", []float64{1}); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	allow, err := NewAllowlist(DefaultParams())
+	if err != nil {
+		t.Fatalf("NewAllowlist() error = %v", err)
+	}
+
+	p, ok := allow.Lookup(gpoResID, doaCmdID)
+	if !ok || p.Name != "DOA_VALUE_RADIANS" {
+		t.Errorf("expected DOA_VALUE_RADIANS, got %+v (ok=%v)", p, ok)
+	}
+	if p.Writable {
+		t.Error("default params should be read-only")
+	}
+	if _, ok := allow.Lookup(1, 1); ok {
+		t.Error("unlisted parameter should not be allowed")
+	}
+
+	dup := append(DefaultParams(), DefaultParams()[0])
+	if _, err := NewAllowlist(dup); err == nil {
+		t.Error("expected error for duplicate entries")
+	}
+	if _, err := NewAllowlist([]Param{{Name: "bad", Type: ParamFloat, Count: 0}}); err == nil {
+		t.Error("expected error for zero count")
+	}
+}
+
+func TestMockSource_Params(t *testing.T) {
+	source := NewMockSource()
+	ctx := context.Background()
+	p := Param{Name: "GAIN", ResID: 35, CmdID: 0, Type: ParamFloat, Count: 1, Writable: true}
+
+	if err := source.WriteParam(ctx, p, []float64{12.5}); err != nil {
+		t.Fatalf("WriteParam() error = %v", err)
+	}
+	got, err := source.ReadParam(ctx, p)
+	if err != nil {
+		t.Fatalf("ReadParam() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != 12.5 {
+		t.Errorf("ReadParam() = %v, want [12.5]", got)
+	}
+
+	if err := source.WriteParam(ctx, p, []float64{1, 2}); err == nil {
+		t.Error("expected error for wrong value count")
+	}
+}
+
+var (
+	_ ParamController = (*MockSource)(nil)
+	_ ParamController = (*USBSource)(nil)
+)
@@ -0,0 +1,145 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ProberConfig configures device health probing
+type ProberConfig struct {
+	Interval  time.Duration // Time between probes
+	Timeout   time.Duration // Per-probe limit, including time queued behind other requests
+	FailAfter int           // Consecutive failed probes before the device is reported down
+}
+
+// DefaultProberConfig returns sensible defaults
+func DefaultProberConfig() ProberConfig {
+	return ProberConfig{
+		Interval:  5 * time.Second,
+		Timeout:   time.Second,
+		FailAfter: 2,
+	}
+}
+
+// ProbeStatus is the outcome of device health probing
+type ProbeStatus struct {
+	Healthy   bool      `json:"healthy"`
+	Firmware  string    `json:"firmware,omitempty"` // major.minor.patch from the VERSION register
+	Failures  int       `json:"failures"`           // Consecutive failed probes
+	LastError string    `json:"last_error,omitempty"`
+	LastProbe time.Time `json:"last_probe"`
+}
+
+// Prober checks the device on its own schedule by reading the VERSION
+// register, so a disconnect is noticed even while nothing polls DOA. Reads
+// go through the source's request queue and never stall the tracker.
+type Prober struct {
+	ctrl   ParamController
+	cfg    ProberConfig
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	status ProbeStatus
+}
+
+// NewProber creates a prober for ctrl
+func NewProber(ctrl ParamController, cfg ProberConfig, logger *slog.Logger) *Prober {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	def := DefaultProberConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.FailAfter <= 0 {
+		cfg.FailAfter = def.FailAfter
+	}
+
+	return &Prober{
+		ctrl:   ctrl,
+		cfg:    cfg,
+		logger: logger,
+		status: ProbeStatus{Healthy: true},
+	}
+}
+
+// Run probes every interval until ctx is cancelled (blocking)
+func (p *Prober) Run(ctx context.Context) {
+	p.Probe(ctx)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Probe(ctx)
+		}
+	}
+}
+
+// Probe reads the firmware version once and updates the status
+func (p *Prober) Probe(ctx context.Context) {
+	pctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	values, err := p.ctrl.ReadParam(pctx, VersionParam)
+	if ctx.Err() != nil {
+		return // Shutting down, not a device failure
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status.LastProbe = time.Now()
+	switch {
+	case errors.Is(err, ErrNoControl):
+		// The active backend (e.g. the Python reader) can't be probed; its
+		// own read errors still drive Healthy()
+		p.status.Healthy = true
+		p.status.Failures = 0
+		p.status.Firmware = ""
+		p.status.LastError = ""
+	case err != nil:
+		p.status.Failures++
+		p.status.LastError = err.Error()
+		if p.status.Healthy && p.status.Failures >= p.cfg.FailAfter {
+			p.status.Healthy = false
+			p.logger.Warn("xvf3800 probe failing", "failures", p.status.Failures, "error", err)
+		}
+	default:
+		firmware := fmt.Sprintf("%d.%d.%d", int(values[0]), int(values[1]), int(values[2]))
+		if !p.status.Healthy {
+			p.logger.Info("xvf3800 probe recovered", "firmware", firmware)
+		} else if firmware != p.status.Firmware {
+			p.logger.Info("xvf3800 firmware", "version", firmware)
+		}
+		p.status = ProbeStatus{Healthy: true, Firmware: firmware, LastProbe: p.status.LastProbe}
+	}
+}
+
+// Status returns the latest probe outcome
+func (p *Prober) Status() ProbeStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status
+}
+
+// Check reports the latest probe outcome as a health probe without touching
+// the device
+func (p *Prober) Check(ctx context.Context) error {
+	status := p.Status()
+	if !status.Healthy {
+		return fmt.Errorf("device not responding: %s", status.LastError)
+	}
+	return nil
+}
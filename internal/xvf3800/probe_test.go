@@ -0,0 +1,71 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// fakeController answers VERSION reads, failing while err is set
+type fakeController struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (f *fakeController) ReadParam(ctx context.Context, p Param) ([]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []float64{2, 1, 4}, nil
+}
+
+func (f *fakeController) WriteParam(ctx context.Context, p Param, values []float64) error {
+	return nil
+}
+
+func (f *fakeController) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func TestProber_DetectsDisconnect(t *testing.T) {
+	ctrl := &fakeController{}
+	p := NewProber(ctrl, ProberConfig{FailAfter: 2}, nil)
+	ctx := context.Background()
+
+	p.Probe(ctx)
+	if s := p.Status(); !s.Healthy || s.Firmware != "2.1.4" {
+		t.Fatalf("status = %+v, want healthy with firmware 2.1.4", s)
+	}
+
+	// One failure is tolerated, the second marks the device down
+	ctrl.setErr(errors.New("no device"))
+	p.Probe(ctx)
+	if err := p.Check(ctx); err != nil {
+		t.Errorf("unhealthy after one failed probe: %v", err)
+	}
+	p.Probe(ctx)
+	if err := p.Check(ctx); err == nil {
+		t.Error("healthy after two failed probes")
+	}
+
+	ctrl.setErr(nil)
+	p.Probe(ctx)
+	if s := p.Status(); !s.Healthy || s.Failures != 0 || s.LastError != "" {
+		t.Errorf("status after recovery = %+v", s)
+	}
+}
+
+func TestProber_NoControl(t *testing.T) {
+	ctrl := &fakeController{err: ErrNoControl}
+	p := NewProber(ctrl, ProberConfig{FailAfter: 1}, nil)
+
+	p.Probe(context.Background())
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("backend without control reported unhealthy: %v", err)
+	}
+}
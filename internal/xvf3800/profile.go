@@ -0,0 +1,191 @@
+package xvf3800
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrUnknownProfile is returned when applying a profile that isn't defined
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// ProfileSetting is one parameter value a profile writes
+type ProfileSetting struct {
+	Param  Param     `json:"param"`
+	Values []float64 `json:"values"`
+}
+
+// Profile bundles parameter settings (AGC gain, AEC, ...) that suit one
+// environment, such as "quiet-room" or "noisy-cafe"
+type Profile struct {
+	Name     string           `json:"name"`
+	Settings []ProfileSetting `json:"settings"`
+}
+
+// Validate checks that every setting can be written
+func (p Profile) Validate() error {
+	if p.Name == "" {
+		return errors.New("profile name is required")
+	}
+	for _, s := range p.Settings {
+		if err := s.Param.Validate(); err != nil {
+			return fmt.Errorf("param %q: %w", s.Param.Name, err)
+		}
+		if !s.Param.Writable {
+			return fmt.Errorf("param %q is read-only", s.Param.Name)
+		}
+		if len(s.Values) != s.Param.Count {
+			return fmt.Errorf("param %q: expected %d values, got %d", s.Param.Name, s.Param.Count, len(s.Values))
+		}
+		if _, err := EncodeParamValues(s.Param.Type, s.Values); err != nil {
+			return fmt.Errorf("param %q: %w", s.Param.Name, err)
+		}
+	}
+	return nil
+}
+
+// savedProfile is the on-disk record of the applied profile
+type savedProfile struct {
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Profiles applies named profiles to the device and remembers the last one
+// applied, so it can be restored after a restart
+type Profiles struct {
+	ctrl   ParamController
+	file   string // Remembers the applied profile ("" = memory only)
+	logger *slog.Logger
+
+	profiles map[string]Profile
+	order    []string
+
+	mu        sync.Mutex
+	active    string
+	appliedAt time.Time
+}
+
+// NewProfiles validates profiles and prepares them for ctrl
+func NewProfiles(ctrl ParamController, profiles []Profile, file string, logger *slog.Logger) (*Profiles, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &Profiles{
+		ctrl:     ctrl,
+		file:     file,
+		logger:   logger,
+		profiles: make(map[string]Profile, len(profiles)),
+	}
+	for _, profile := range profiles {
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+		if _, dup := p.profiles[profile.Name]; dup {
+			return nil, fmt.Errorf("duplicate profile %q", profile.Name)
+		}
+		p.profiles[profile.Name] = profile
+		p.order = append(p.order, profile.Name)
+	}
+	return p, nil
+}
+
+// Apply writes every setting of the named profile in order, stopping at
+// the first failure
+func (p *Profiles) Apply(ctx context.Context, name string) error {
+	profile, ok := p.profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range profile.Settings {
+		if err := p.ctrl.WriteParam(ctx, s.Param, s.Values); err != nil {
+			return fmt.Errorf("profile %q: write %s: %w", name, s.Param.Name, err)
+		}
+	}
+	p.active = name
+	p.appliedAt = time.Now()
+	p.logger.Info("xvf3800 profile applied", "profile", name, "settings", len(profile.Settings))
+
+	if err := p.save(); err != nil {
+		p.logger.Warn("failed to remember xvf3800 profile", "error", err)
+	}
+	return nil
+}
+
+// Restore applies the profile remembered from the last run, or fallback if
+// none was saved ("" = leave the device alone)
+func (p *Profiles) Restore(ctx context.Context, fallback string) error {
+	name := fallback
+	if p.file != "" {
+		data, err := os.ReadFile(p.file)
+		switch {
+		case err == nil:
+			var saved savedProfile
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return fmt.Errorf("decode saved profile: %w", err)
+			}
+			if _, ok := p.profiles[saved.Name]; ok {
+				name = saved.Name
+			} else {
+				p.logger.Warn("saved xvf3800 profile no longer defined", "profile", saved.Name)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("read saved profile: %w", err)
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	return p.Apply(ctx, name)
+}
+
+// save writes the applied profile atomically (caller holds mu)
+func (p *Profiles) save() error {
+	if p.file == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(savedProfile{Name: p.active, AppliedAt: p.appliedAt})
+	if err != nil {
+		return fmt.Errorf("encode profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.file), 0755); err != nil {
+		return fmt.Errorf("create profile dir: %w", err)
+	}
+
+	tmp := p.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	if err := os.Rename(tmp, p.file); err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	return nil
+}
+
+// List returns the profiles in configuration order
+func (p *Profiles) List() []Profile {
+	out := make([]Profile, len(p.order))
+	for i, name := range p.order {
+		out[i] = p.profiles[name]
+	}
+	return out
+}
+
+// Active returns the last applied profile ("" if none) and when it was
+// applied
+func (p *Profiles) Active() (string, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active, p.appliedAt
+}
@@ -0,0 +1,79 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+var agcMaxGain = Param{Name: "PP_AGCMAXGAIN", ResID: 17, CmdID: 12, Type: ParamFloat, Count: 1, Writable: true}
+
+func testProfiles() []Profile {
+	return []Profile{
+		{Name: "quiet-room", Settings: []ProfileSetting{{Param: agcMaxGain, Values: []float64{8}}}},
+		{Name: "noisy-cafe", Settings: []ProfileSetting{{Param: agcMaxGain, Values: []float64{2}}}},
+	}
+}
+
+func TestProfiles_ApplyAndRestore(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "profile.json")
+	mock := NewMockSource()
+
+	profiles, err := NewProfiles(mock, testProfiles(), file, nil)
+	if err != nil {
+		t.Fatalf("NewProfiles() error = %v", err)
+	}
+	if err := profiles.Apply(ctx, "noisy-cafe"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if values, _ := mock.ReadParam(ctx, agcMaxGain); values[0] != 2 {
+		t.Errorf("gain = %v, want 2", values)
+	}
+	if name, at := profiles.Active(); name != "noisy-cafe" || at.IsZero() {
+		t.Errorf("Active() = %q, %v", name, at)
+	}
+	if err := profiles.Apply(ctx, "stadium"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Apply(unknown) error = %v, want ErrUnknownProfile", err)
+	}
+
+	// A restart restores the remembered profile over the configured one
+	mock = NewMockSource()
+	restarted, err := NewProfiles(mock, testProfiles(), file, nil)
+	if err != nil {
+		t.Fatalf("NewProfiles() error = %v", err)
+	}
+	if err := restarted.Restore(ctx, "quiet-room"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if name, _ := restarted.Active(); name != "noisy-cafe" {
+		t.Errorf("restored %q, want noisy-cafe", name)
+	}
+
+	// Without a saved profile the fallback is applied
+	fresh, _ := NewProfiles(NewMockSource(), testProfiles(), "", nil)
+	if err := fresh.Restore(ctx, "quiet-room"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if name, _ := fresh.Active(); name != "quiet-room" {
+		t.Errorf("restored %q, want quiet-room", name)
+	}
+}
+
+func TestNewProfiles_Invalid(t *testing.T) {
+	readOnly := agcMaxGain
+	readOnly.Writable = false
+
+	tests := map[string][]Profile{
+		"read-only param": {{Name: "a", Settings: []ProfileSetting{{Param: readOnly, Values: []float64{1}}}}},
+		"wrong count":     {{Name: "a", Settings: []ProfileSetting{{Param: agcMaxGain, Values: []float64{1, 2}}}}},
+		"missing name":    {{Settings: []ProfileSetting{{Param: agcMaxGain, Values: []float64{1}}}}},
+		"duplicate":       append(testProfiles(), testProfiles()[0]),
+	}
+	for name, profiles := range tests {
+		if _, err := NewProfiles(NewMockSource(), profiles, "", nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
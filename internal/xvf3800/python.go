@@ -0,0 +1,191 @@
+package xvf3800
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// PythonConfig configures the Python subprocess reader
+type PythonConfig struct {
+	Command      string        // Interpreter (default: "python3")
+	Args         []string      // Script and arguments
+	StartTimeout time.Duration // First reading must arrive within this
+	StaleAfter   time.Duration // Readings older than this are errors
+}
+
+// DefaultPythonConfig returns sensible defaults
+func DefaultPythonConfig() PythonConfig {
+	return PythonConfig{
+		Command:      "python3",
+		Args:         []string{"-u", "/usr/local/share/go-eva/xvf3800_doa.py"},
+		StartTimeout: 3 * time.Second,
+		StaleAfter:   time.Second,
+	}
+}
+
+// pythonReading is one JSON line from the reader script
+type pythonReading struct {
+	Angle    float64    `json:"angle"` // XVF3800 coordinates (radians)
+	Speaking bool       `json:"speaking"`
+	Energy   [4]float64 `json:"energy"`
+	Azimuths [4]float64 `json:"azimuths"`
+}
+
+// PythonSource reads DOA from a long-running Python script that prints one
+// JSON reading per line (scripts/xvf3800_doa.py, using pyusb). It covers
+// setups where libusb can't be opened from Go.
+type PythonSource struct {
+	cfg    PythonConfig
+	logger *slog.Logger
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	latest    doa.Reading
+	latestAt  time.Time
+	exited    bool
+	exitError error
+}
+
+// NewPythonSource starts the reader and waits for its first reading
+func NewPythonSource(cfg PythonConfig, logger *slog.Logger) (*PythonSource, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	defaults := DefaultPythonConfig()
+	if cfg.Command == "" {
+		cfg.Command = defaults.Command
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = defaults.StartTimeout
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaults.StaleAfter
+	}
+
+	if _, err := exec.LookPath(cfg.Command); err != nil {
+		return nil, fmt.Errorf("python reader: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("python reader stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("start python reader: %w", err)
+	}
+
+	p := &PythonSource{
+		cfg:    cfg,
+		logger: logger,
+		cancel: cancel,
+	}
+
+	first := make(chan struct{})
+	go p.read(cmd, bufio.NewScanner(stdout), first)
+
+	select {
+	case <-first:
+	case <-time.After(cfg.StartTimeout):
+		p.Close()
+		return nil, fmt.Errorf("python reader produced no reading within %v", cfg.StartTimeout)
+	}
+
+	p.mu.Lock()
+	exited, exitErr := p.exited, p.exitError
+	p.mu.Unlock()
+	if exited {
+		p.Close()
+		return nil, fmt.Errorf("python reader exited: %v", exitErr)
+	}
+
+	logger.Info("Python DOA source initialized", "command", cfg.Command, "args", cfg.Args)
+	return p, nil
+}
+
+// read parses readings until the process exits; first is closed on the
+// first reading or on exit
+func (p *PythonSource) read(cmd *exec.Cmd, scanner *bufio.Scanner, first chan struct{}) {
+	var once sync.Once
+	signal := func() { once.Do(func() { close(first) }) }
+	defer signal()
+
+	for scanner.Scan() {
+		var r pythonReading
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			p.logger.Debug("python reader: bad line", "error", err)
+			continue
+		}
+
+		now := time.Now()
+		p.mu.Lock()
+		p.latest = doa.Reading{
+			Angle:        doa.ToEvaAngle(r.Angle),
+			RawAngle:     r.Angle,
+			Speaking:     r.Speaking,
+			Timestamp:    now,
+			SpeechEnergy: r.Energy,
+			MicAzimuths:  r.Azimuths,
+			TotalEnergy:  sumEnergy(r.Energy),
+		}
+		p.latestAt = now
+		p.mu.Unlock()
+		signal()
+	}
+
+	err := cmd.Wait()
+	if err == nil {
+		err = scanner.Err()
+	}
+	if err == nil {
+		err = fmt.Errorf("exited")
+	}
+
+	p.mu.Lock()
+	p.exited = true
+	p.exitError = err
+	p.mu.Unlock()
+}
+
+// GetDOA returns the most recent reading from the script
+func (p *PythonSource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.exited {
+		return doa.Reading{}, fmt.Errorf("python reader exited: %v", p.exitError)
+	}
+	if age := time.Since(p.latestAt); age > p.cfg.StaleAfter {
+		return doa.Reading{}, fmt.Errorf("python reader stale: last reading %v ago", age.Round(time.Millisecond))
+	}
+	return p.latest, nil
+}
+
+// Close stops the reader process
+func (p *PythonSource) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Healthy returns true while the script is running and producing readings
+func (p *PythonSource) Healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.exited && time.Since(p.latestAt) <= p.cfg.StaleAfter
+}
+
+// Name returns the source type name
+func (p *PythonSource) Name() string {
+	return "python"
+}
@@ -0,0 +1,65 @@
+package xvf3800
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestPythonSource_ReadsJSONLines(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// A shell loop stands in for the Python reader
+	cfg := PythonConfig{
+		Command: "sh",
+		Args: []string{"-c", `while true; do
+echo 'not json'
+echo '{"angle": 1.5707963, "speaking": true, "energy": [1, 2, 3, 4], "azimuths": [0, 0, 0, 0]}'
+sleep 0.05
+done`},
+		StartTimeout: 2 * time.Second,
+		StaleAfter:   time.Second,
+	}
+
+	src, err := NewPythonSource(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewPythonSource() error = %v", err)
+	}
+	defer src.Close()
+
+	reading, err := src.GetDOA(context.Background())
+	if err != nil {
+		t.Fatalf("GetDOA() error = %v", err)
+	}
+	if math.Abs(reading.Angle) > 1e-6 || !reading.Speaking || reading.TotalEnergy != 10 {
+		t.Errorf("reading = %+v", reading)
+	}
+	if !src.Healthy() || src.Name() != "python" {
+		t.Error("expected healthy python source")
+	}
+}
+
+func TestPythonSource_ExitsWithoutReading(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	_, err := NewPythonSource(PythonConfig{
+		Command:      "sh",
+		Args:         []string{"-c", "echo 'XVF3800 not found' >&2; exit 1"},
+		StartTimeout: 2 * time.Second,
+	}, nil)
+	if err == nil {
+		t.Fatal("expected error when the reader exits")
+	}
+}
+
+func TestPythonSource_MissingCommand(t *testing.T) {
+	if _, err := NewPythonSource(PythonConfig{Command: "go-eva-no-such-python"}, nil); err == nil {
+		t.Error("expected error for missing interpreter")
+	}
+}
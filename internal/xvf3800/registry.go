@@ -0,0 +1,150 @@
+package xvf3800
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// ErrUnknownSource is returned by Open for a name nothing registered
+var ErrUnknownSource = errors.New("unknown DOA source")
+
+// Factory opens a registered DOA source. arg is the text after the colon in
+// a "name:arg" spec (e.g. the file in "replay:session.jsonl").
+type Factory func(arg string, opts Options, logger *slog.Logger) (doa.Source, error)
+
+// Options configures the registered sources; each factory reads its part
+type Options struct {
+	USB       USBSourceConfig
+	I2C       I2CSourceConfig
+	Python    PythonConfig
+	Replay    ReplayConfig // Path comes from the spec argument
+	Composite CompositeConfig
+	Chain     []string // Sources "auto" tries, in priority order
+}
+
+// DefaultOptions returns sensible defaults
+func DefaultOptions() Options {
+	return Options{
+		USB:       DefaultUSBSourceConfig(),
+		I2C:       DefaultI2CSourceConfig(),
+		Python:    DefaultPythonConfig(),
+		Replay:    ReplayConfig{Speed: 1},
+		Composite: DefaultCompositeConfig(),
+		Chain:     []string{"usb", "python", "mock"},
+	}
+}
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a DOA source available to Open under name. It panics if
+// the name is taken, so conflicting registrations fail at startup.
+func Register(name string, factory Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if name == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("xvf3800: invalid source name %q", name))
+	}
+	if _, dup := registry.factories[name]; dup {
+		panic(fmt.Sprintf("xvf3800: source %q registered twice", name))
+	}
+	registry.factories[name] = factory
+}
+
+// Sources returns the registered source names, sorted
+func Sources() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the source named by spec ("usb", "replay:session.jsonl", ...)
+func Open(spec string, opts Options, logger *slog.Logger) (doa.Source, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	name, arg, _ := strings.Cut(spec, ":")
+
+	registry.RLock()
+	factory, ok := registry.factories[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q (registered: %s)", ErrUnknownSource, name, strings.Join(Sources(), ", "))
+	}
+
+	source, err := factory(arg, opts, logger)
+	if err != nil {
+		return nil, fmt.Errorf("open %s source: %w", name, err)
+	}
+	return source, nil
+}
+
+// Built-in sources
+func init() {
+	Register("usb", func(_ string, opts Options, logger *slog.Logger) (doa.Source, error) {
+		return NewUSBSourceWithConfig(logger, opts.USB)
+	})
+	Register("i2c", func(_ string, opts Options, logger *slog.Logger) (doa.Source, error) {
+		return NewI2CSource(opts.I2C, logger)
+	})
+	Register("python", func(_ string, opts Options, logger *slog.Logger) (doa.Source, error) {
+		return NewPythonSource(opts.Python, logger)
+	})
+	Register("mock", func(string, Options, *slog.Logger) (doa.Source, error) {
+		return NewMockSource(), nil
+	})
+	Register("wave", func(string, Options, *slog.Logger) (doa.Source, error) {
+		return NewMockSourceWithWave(), nil
+	})
+	Register("replay", func(path string, opts Options, _ *slog.Logger) (doa.Source, error) {
+		if path == "" {
+			return nil, errors.New("replay needs a file: replay:<file.jsonl|file.csv>")
+		}
+		cfg := opts.Replay
+		cfg.Path = path
+		return NewReplaySource(cfg)
+	})
+	Register("auto", openAuto)
+}
+
+// openAuto chains opts.Chain in a CompositeSource with failover and
+// re-probing
+func openAuto(_ string, opts Options, logger *slog.Logger) (doa.Source, error) {
+	if len(opts.Chain) == 0 {
+		return nil, errors.New("no sources in the chain")
+	}
+
+	backends := make([]Backend, 0, len(opts.Chain))
+	for _, spec := range opts.Chain {
+		name, _, _ := strings.Cut(spec, ":")
+		if name == "auto" {
+			return nil, errors.New("the chain cannot contain auto")
+		}
+		registry.RLock()
+		_, ok := registry.factories[name]
+		registry.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%w %q in the chain", ErrUnknownSource, name)
+		}
+		backends = append(backends, Backend{
+			Name: spec,
+			Open: func(logger *slog.Logger) (doa.Source, error) { return Open(spec, opts, logger) },
+		})
+	}
+	return NewCompositeSource(backends, opts.Composite, logger)
+}
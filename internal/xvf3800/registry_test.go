@@ -0,0 +1,110 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+func TestOpen_BuiltIn(t *testing.T) {
+	for _, name := range []string{"auto", "mock", "python", "replay", "usb", "wave"} {
+		if !slices.Contains(Sources(), name) {
+			t.Errorf("Sources() is missing %q", name)
+		}
+	}
+
+	source, err := Open("wave", DefaultOptions(), nil)
+	if err != nil {
+		t.Fatalf("Open(wave) error = %v", err)
+	}
+	defer source.Close()
+	if mock, ok := source.(*MockSource); !ok || !mock.simulateWave {
+		t.Errorf("Open(wave) = %T, want a sweeping mock", source)
+	}
+
+	if _, err := Open("sonar", DefaultOptions(), nil); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("Open(sonar) error = %v, want ErrUnknownSource", err)
+	}
+	if _, err := Open("replay", DefaultOptions(), nil); err == nil {
+		t.Error("Open(replay) without a file should fail")
+	}
+}
+
+func TestOpen_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.csv")
+	os.WriteFile(path, []byte("timestamp,angle\n1000,0.5\n1050,0.6\n"), 0o644)
+
+	opts := DefaultOptions()
+	opts.Replay.Speed = 0
+	source, err := Open("replay:"+path, opts, nil)
+	if err != nil {
+		t.Fatalf("Open(replay) error = %v", err)
+	}
+	defer source.Close()
+
+	replay, ok := source.(*ReplaySource)
+	if !ok || replay.Len() != 2 || replay.cfg.Path != path {
+		t.Fatalf("Open(replay) = %T %+v", source, source)
+	}
+}
+
+func TestOpen_AutoChain(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Chain = []string{"python", "mock"}
+	opts.Python.Command = "/nonexistent/python3"
+
+	source, err := Open("auto", opts, nil)
+	if err != nil {
+		t.Fatalf("Open(auto) error = %v", err)
+	}
+	defer source.Close()
+
+	composite, ok := source.(*CompositeSource)
+	if !ok || composite.Name() != "mock" {
+		t.Fatalf("Open(auto) = %T named %q, want composite on mock", source, source.Name())
+	}
+
+	opts.Chain = []string{"usb", "sonar"}
+	if _, err := Open("auto", opts, nil); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("chain with an unknown source error = %v, want ErrUnknownSource", err)
+	}
+	opts.Chain = []string{"auto"}
+	if _, err := Open("auto", opts, nil); err == nil {
+		t.Error("a chain containing auto should fail")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-fixed", func(arg string, _ Options, _ *slog.Logger) (doa.Source, error) {
+		if arg == "" {
+			return nil, errors.New("need an angle")
+		}
+		return NewMockSource(), nil
+	})
+	t.Cleanup(func() {
+		registry.Lock()
+		delete(registry.factories, "test-fixed")
+		registry.Unlock()
+	})
+
+	source, err := Open("test-fixed:front", DefaultOptions(), nil)
+	if err != nil {
+		t.Fatalf("Open() registered source error = %v", err)
+	}
+	if _, err := source.GetDOA(context.Background()); err != nil {
+		t.Errorf("GetDOA() error = %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	Register("mock", nil)
+}
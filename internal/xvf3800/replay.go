@@ -0,0 +1,282 @@
+package xvf3800
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/teslashibe/go-eva/internal/doa"
+)
+
+// ErrReplayDone is returned once a non-looping replay has run out of readings
+var ErrReplayDone = errors.New("replay finished")
+
+// ReplayConfig configures playback of a recorded session
+type ReplayConfig struct {
+	Path  string  // JSONL (recorder export) or CSV trace
+	Speed float64 // Playback rate (1 = original timing, 4 = 4× faster, 0 = one reading per poll)
+	Loop  bool    // Start over at the end instead of failing
+}
+
+// ReplaySource replays recorded readings as a DOA source, for regression
+// testing tracking behavior against real sessions
+type ReplaySource struct {
+	cfg      ReplayConfig
+	readings []doa.Reading
+
+	mu      sync.Mutex
+	started time.Time // Wall time of the first GetDOA
+	next    int       // Step mode position
+	done    bool
+	doneCh  chan struct{}
+}
+
+// NewReplaySource loads a trace; the format is picked by file extension
+func NewReplaySource(cfg ReplayConfig) (*ReplaySource, error) {
+	if cfg.Speed < 0 {
+		return nil, fmt.Errorf("replay speed must not be negative, got %v", cfg.Speed)
+	}
+
+	f, err := os.Open(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay: %w", err)
+	}
+	defer f.Close()
+
+	var readings []doa.Reading
+	if strings.EqualFold(filepath.Ext(cfg.Path), ".csv") {
+		readings, err = parseReplayCSV(f)
+	} else {
+		readings, err = parseReplayJSONL(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read replay %s: %w", cfg.Path, err)
+	}
+	if len(readings) == 0 {
+		return nil, fmt.Errorf("replay %s has no readings", cfg.Path)
+	}
+
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].Timestamp.Before(readings[j].Timestamp)
+	})
+
+	return &ReplaySource{cfg: cfg, readings: readings, doneCh: make(chan struct{})}, nil
+}
+
+// parseReplayJSONL reads one doa.Reading (or recorded doa.Result) per line
+func parseReplayJSONL(r io.Reader) ([]doa.Reading, error) {
+	var readings []doa.Reading
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var reading doa.Reading
+		if err := json.Unmarshal([]byte(text), &reading); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		readings = append(readings, reading)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// parseReplayCSV reads a CSV trace with a header row. Columns: timestamp
+// (unix ms or RFC 3339), angle or raw_angle (radians), and optionally
+// speaking, total_energy, latency_ms.
+func parseReplayCSV(r io.Reader) ([]doa.Reading, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := col["timestamp"]; !ok {
+		return nil, fmt.Errorf("missing timestamp column")
+	}
+	_, hasAngle := col["angle"]
+	_, hasRaw := col["raw_angle"]
+	if !hasAngle && !hasRaw {
+		return nil, fmt.Errorf("missing angle or raw_angle column")
+	}
+
+	var readings []doa.Reading
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) (float64, error) {
+			s := field(name)
+			if s == "" {
+				return 0, nil
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: %s: %w", line, name, err)
+			}
+			return v, nil
+		}
+
+		var reading doa.Reading
+		if reading.Timestamp, err = parseReplayTime(field("timestamp")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if reading.RawAngle, err = number("raw_angle"); err != nil {
+			return nil, err
+		}
+		if hasAngle {
+			if reading.Angle, err = number("angle"); err != nil {
+				return nil, err
+			}
+		} else {
+			reading.Angle = doa.ToEvaAngle(reading.RawAngle)
+		}
+		if reading.TotalEnergy, err = number("total_energy"); err != nil {
+			return nil, err
+		}
+		latency, err := number("latency_ms")
+		if err != nil {
+			return nil, err
+		}
+		reading.LatencyMs = int64(latency)
+		if s := field("speaking"); s != "" {
+			if reading.Speaking, err = strconv.ParseBool(s); err != nil {
+				return nil, fmt.Errorf("line %d: speaking: %w", line, err)
+			}
+		}
+
+		readings = append(readings, reading)
+	}
+	return readings, nil
+}
+
+// parseReplayTime accepts unix milliseconds or RFC 3339
+func parseReplayTime(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q: want unix ms or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// GetDOA returns the recorded reading due at the current playback position,
+// stamped with the current time
+func (r *ReplaySource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done {
+		return doa.Reading{}, ErrReplayDone
+	}
+
+	now := time.Now()
+	var i int
+	if r.cfg.Speed == 0 {
+		if r.next >= len(r.readings) {
+			if !r.cfg.Loop {
+				r.finish()
+				return doa.Reading{}, ErrReplayDone
+			}
+			r.next = 0
+		}
+		i = r.next
+		r.next++
+	} else {
+		if r.started.IsZero() {
+			r.started = now
+		}
+		first := r.readings[0].Timestamp
+		length := r.readings[len(r.readings)-1].Timestamp.Sub(first)
+		pos := time.Duration(float64(now.Sub(r.started)) * r.cfg.Speed)
+		if pos > length {
+			if !r.cfg.Loop {
+				r.finish()
+				return doa.Reading{}, ErrReplayDone
+			}
+			// Wrap around; the extra millisecond keeps the last reading reachable
+			pos %= length + time.Millisecond
+		}
+		due := first.Add(pos)
+		i = sort.Search(len(r.readings), func(j int) bool {
+			return r.readings[j].Timestamp.After(due)
+		}) - 1
+		if i < 0 {
+			i = 0
+		}
+	}
+
+	reading := r.readings[i]
+	reading.Timestamp = now
+	return reading, nil
+}
+
+// finish marks a non-looping replay as finished (caller holds mu)
+func (r *ReplaySource) finish() {
+	r.done = true
+	close(r.doneCh)
+}
+
+// Done is closed when a non-looping replay runs out of readings
+func (r *ReplaySource) Done() <-chan struct{} {
+	return r.doneCh
+}
+
+// Len returns the number of recorded readings
+func (r *ReplaySource) Len() int {
+	return len(r.readings)
+}
+
+// Close releases resources
+func (r *ReplaySource) Close() error {
+	return nil
+}
+
+// Healthy returns true until a non-looping replay finishes
+func (r *ReplaySource) Healthy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.done
+}
+
+// Name returns the source type name
+func (r *ReplaySource) Name() string {
+	return "replay"
+}
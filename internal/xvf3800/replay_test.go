@@ -0,0 +1,92 @@
+package xvf3800
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTrace(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplaySource_StepJSONL(t *testing.T) {
+	path := writeTrace(t, "session.jsonl", `{"angle":0.1,"speaking":true,"timestamp":"2026-01-01T00:00:00Z","smoothed_angle":0.1}
+{"angle":0.2,"speaking":false,"timestamp":"2026-01-01T00:00:00.05Z"}
+`)
+
+	source, err := NewReplaySource(ReplayConfig{Path: path, Speed: 0})
+	if err != nil {
+		t.Fatalf("NewReplaySource() error = %v", err)
+	}
+	if source.Len() != 2 || source.Name() != "replay" {
+		t.Fatalf("unexpected source: len %d, name %s", source.Len(), source.Name())
+	}
+
+	ctx := context.Background()
+	first, _ := source.GetDOA(ctx)
+	second, _ := source.GetDOA(ctx)
+	if first.Angle != 0.1 || !first.Speaking || second.Angle != 0.2 {
+		t.Errorf("unexpected readings %+v, %+v", first, second)
+	}
+	if time.Since(first.Timestamp) > time.Second {
+		t.Error("replayed readings should carry the current time")
+	}
+
+	if _, err := source.GetDOA(ctx); !errors.Is(err, ErrReplayDone) {
+		t.Errorf("expected ErrReplayDone, got %v", err)
+	}
+	if source.Healthy() {
+		t.Error("finished replay should be unhealthy")
+	}
+	select {
+	case <-source.Done():
+	default:
+		t.Error("Done should be closed")
+	}
+}
+
+func TestReplaySource_TimedCSV(t *testing.T) {
+	path := writeTrace(t, "session.csv", `timestamp,raw_angle,speaking,total_energy
+1700000000000,1.5707963,true,12.5
+1700000010000,0,false,0
+`)
+
+	// 10s of recording at 1000× plays back in 10ms
+	source, err := NewReplaySource(ReplayConfig{Path: path, Speed: 1000, Loop: true})
+	if err != nil {
+		t.Fatalf("NewReplaySource() error = %v", err)
+	}
+
+	ctx := context.Background()
+	first, err := source.GetDOA(ctx)
+	if err != nil {
+		t.Fatalf("GetDOA() error = %v", err)
+	}
+	if math.Abs(first.Angle) > 1e-6 || !first.Speaking || first.TotalEnergy != 12.5 {
+		t.Errorf("unexpected first reading %+v", first)
+	}
+
+	time.Sleep(12 * time.Millisecond)
+	if _, err := source.GetDOA(ctx); err != nil {
+		t.Errorf("looping replay should not finish: %v", err)
+	}
+}
+
+func TestReplaySource_BadTrace(t *testing.T) {
+	if _, err := NewReplaySource(ReplayConfig{Path: writeTrace(t, "empty.jsonl", "")}); err == nil {
+		t.Error("empty trace should fail")
+	}
+	if _, err := NewReplaySource(ReplayConfig{Path: writeTrace(t, "bad.csv", "angle\n0.1\n")}); err == nil {
+		t.Error("CSV without timestamp should fail")
+	}
+}
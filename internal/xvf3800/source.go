@@ -25,15 +25,18 @@ func NewSource(logger *slog.Logger) (doa.Source, error) {
 	return nil, err
 }
 
-// NewSourceWithFallback creates a DOA source with mock fallback
-// Use this for development/testing when hardware is unavailable
+// NewSourceWithFallback creates a DOA source that prefers USB, then the
+// Python reader, then mock, and moves back up the chain when hardware
+// becomes available
 func NewSourceWithFallback(logger *slog.Logger) doa.Source {
-	source, err := NewSource(logger)
-	if err == nil {
-		return source
+	source, err := Open("auto", DefaultOptions(), logger)
+	if err != nil {
+		logger.Warn("using mock DOA source - no hardware available")
+		return NewMockSource()
 	}
 
-	logger.Warn("using mock DOA source - no hardware available")
-	return NewMockSource()
+	if source.Name() == "mock" {
+		logger.Warn("using mock DOA source until hardware is available")
+	}
+	return source
 }
-
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,17 +35,27 @@ const (
 	aecAzimuthCmdID     = 75 // AEC_AZIMUTH_VALUES: 4 floats (radians)
 	aecSpEnergyCmdID    = 80 // AEC_SPENERGY_VALUES: 4 floats (speech energy per mic)
 	aecMicArrayGeoCmdID = 74 // AEC_MIC_ARRAY_GEO: 12 floats (x,y,z for each mic)
+
+	// APPLICATION_SERVICER_RESID commands (resid=48)
+	appResID         = 48
+	versionCmdID     = 0 // VERSION: 3 uint8 (major, minor, patch)
+	bldMsgCmdID      = 1 // BLD_MSG: build message, 50 chars
+	bldRepoHashCmdID = 3 // BLD_REPO_HASH: source revision, 40 chars
 )
 
 // USBSource provides direct USB access to the XVF3800 audio DSP
-// This is the preferred, pure Go implementation
+// This is the preferred, pure Go implementation. All USB transfers happen on
+// a background worker; GetDOA returns its latest reading without blocking.
 type USBSource struct {
 	logger *slog.Logger
+	worker *asyncReader
 
-	mu     sync.Mutex
-	ctx    *gousb.Context
-	dev    *gousb.Device
-	closed bool
+	mu       sync.Mutex
+	ctx      *gousb.Context
+	dev      *gousb.Device
+	serial   string       // USB serial number, read on open
+	firmware FirmwareInfo // DSP firmware, read on open
+	closed   bool
 
 	// Health tracking
 	healthy           bool
@@ -56,6 +67,13 @@ type USBSource struct {
 	// Reconnection
 	reconnectBackoff time.Duration
 	maxBackoff       time.Duration
+
+	// Transfer stats
+	metrics           *USBMetrics
+	transfers         map[string]*transferStats
+	statusErrors      map[uint8]uint64
+	reconnects        uint64
+	reconnectFailures uint64
 }
 
 // USBSourceConfig configures the USB source
@@ -63,6 +81,10 @@ type USBSourceConfig struct {
 	MaxConsecutiveErrors int
 	InitialBackoff       time.Duration
 	MaxBackoff           time.Duration
+	PollInterval         time.Duration // Worker read cadence
+	StaleAfter           time.Duration // GetDOA fails once the latest reading is older than this
+	QueueSize            int           // Pending parameter reads/writes before ErrQueueFull
+	Metrics              *USBMetrics   // Shared transfer metrics (nil = private to the source)
 }
 
 // DefaultUSBSourceConfig returns sensible defaults
@@ -71,6 +93,9 @@ func DefaultUSBSourceConfig() USBSourceConfig {
 		MaxConsecutiveErrors: 5,
 		InitialBackoff:       100 * time.Millisecond,
 		MaxBackoff:           5 * time.Second,
+		PollInterval:         20 * time.Millisecond,
+		StaleAfter:           500 * time.Millisecond,
+		QueueSize:            16,
 	}
 }
 
@@ -91,6 +116,10 @@ func NewUSBSourceWithConfig(logger *slog.Logger, cfg USBSourceConfig) (*USBSourc
 		maxErrors:        cfg.MaxConsecutiveErrors,
 		reconnectBackoff: cfg.InitialBackoff,
 		maxBackoff:       cfg.MaxBackoff,
+		metrics:          cfg.Metrics,
+	}
+	if source.metrics == nil {
+		source.metrics = NewUSBMetrics()
 	}
 
 	// Open USB context
@@ -102,9 +131,22 @@ func NewUSBSourceWithConfig(logger *slog.Logger, cfg USBSourceConfig) (*USBSourc
 		return nil, err
 	}
 
+	defaults := DefaultUSBSourceConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaults.StaleAfter
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaults.QueueSize
+	}
+	source.worker = newAsyncReader(cfg.PollInterval, cfg.StaleAfter, cfg.QueueSize, source.readDOA)
+
 	logger.Info("USB DOA source initialized",
 		"vendor_id", fmt.Sprintf("0x%04X", VendorID),
 		"product_id", fmt.Sprintf("0x%04X", ProductID),
+		"poll_interval", cfg.PollInterval,
 	)
 
 	return source, nil
@@ -125,20 +167,158 @@ func (u *USBSource) openDevice() error {
 		u.logger.Debug("SetAutoDetach failed (non-fatal)", "error", err)
 	}
 
+	if serial, err := dev.SerialNumber(); err != nil {
+		u.logger.Debug("reading serial number failed (non-fatal)", "error", err)
+	} else {
+		u.serial = serial
+	}
+
 	u.dev = dev
 	u.healthy = true
 	u.consecutiveErrors = 0
 
+	// Re-read on every open: a reconnect may follow a firmware update
+	u.firmware = u.readFirmware()
+	u.logger.Info("xvf3800 firmware", "version", u.firmware.Version, "build", u.firmware.Build, "repo_hash", u.firmware.RepoHash)
+
 	return nil
 }
 
-// GetDOA returns the current direction of arrival
+// Serial returns the device's USB serial number ("" if it has none)
+func (u *USBSource) Serial() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.serial
+}
+
+// FirmwareInfo identifies the firmware running on the DSP
+type FirmwareInfo struct {
+	Version  string `json:"version,omitempty"`   // major.minor.patch from VERSION
+	Build    string `json:"build,omitempty"`     // BLD_MSG
+	RepoHash string `json:"repo_hash,omitempty"` // BLD_REPO_HASH
+}
+
+// String returns the version followed by the build details it has
+func (f FirmwareInfo) String() string {
+	s := f.Version
+	if f.Build != "" {
+		s += " (" + f.Build + ")"
+	}
+	if f.RepoHash != "" {
+		s += " " + f.RepoHash
+	}
+	return strings.TrimSpace(s)
+}
+
+// Firmware returns the firmware details read when the device was last opened
+func (u *USBSource) Firmware() FirmwareInfo {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.firmware
+}
+
+// readFirmware reads the version and build registers. Failures only leave
+// fields empty; older firmware lacks some of them. Caller holds mu.
+func (u *USBSource) readFirmware() FirmwareInfo {
+	var info FirmwareInfo
+	if data, err := u.readRegister(appResID, versionCmdID, 3); err == nil {
+		info.Version = fmt.Sprintf("%d.%d.%d", data[0], data[1], data[2])
+	} else {
+		u.logger.Debug("reading firmware version failed (non-fatal)", "error", err)
+	}
+	if data, err := u.readRegister(appResID, bldMsgCmdID, 50); err == nil {
+		info.Build = cString(data)
+	}
+	if data, err := u.readRegister(appResID, bldRepoHashCmdID, 40); err == nil {
+		info.RepoHash = cString(data)
+	}
+	return info
+}
+
+// readRegister reads size bytes from a control register without counting
+// failures against the device's health (caller holds mu)
+func (u *USBSource) readRegister(resID uint16, cmdID uint8, size int) ([]byte, error) {
+	data := make([]byte, 1+size)
+	n, err := u.control(cmdParamRead,
+		gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice,
+		0x80|uint16(cmdID),
+		resID,
+		data,
+	)
+	switch {
+	case err != nil:
+		return nil, err
+	case n < len(data):
+		return nil, fmt.Errorf("short read: got %d bytes, expected %d", n, len(data))
+	case data[0] != 0:
+		return nil, fmt.Errorf("device returned error status: %d", data[0])
+	}
+	return data[1:], nil
+}
+
+// cString returns a NUL-padded string register's text
+func cString(data []byte) string {
+	if i := strings.IndexByte(string(data), 0); i >= 0 {
+		data = data[:i]
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// USBDeviceInfo describes an attached XVF3800
+type USBDeviceInfo struct {
+	Bus     int    `json:"bus"`
+	Address int    `json:"address"`
+	Speed   string `json:"speed"`
+	Version string `json:"version"` // bcdDevice from the device descriptor
+	Serial  string `json:"serial,omitempty"`
+}
+
+// ListUSBDevices enumerates attached XVF3800s without claiming them
+func ListUSBDevices() ([]USBDeviceInfo, error) {
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+
+	devs, err := ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return desc.Vendor == VendorID && desc.Product == ProductID
+	})
+	defer func() {
+		for _, dev := range devs {
+			dev.Close()
+		}
+	}()
+	// Devices that opened are still reported when others failed
+	if err != nil && len(devs) == 0 {
+		return nil, fmt.Errorf("enumerate USB devices: %w", err)
+	}
+
+	infos := make([]USBDeviceInfo, 0, len(devs))
+	for _, dev := range devs {
+		info := USBDeviceInfo{
+			Bus:     dev.Desc.Bus,
+			Address: dev.Desc.Address,
+			Speed:   dev.Desc.Speed.String(),
+			Version: dev.Desc.Device.String(),
+		}
+		if serial, err := dev.SerialNumber(); err == nil {
+			info.Serial = serial
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetDOA returns the worker's latest direction of arrival reading
 func (u *USBSource) GetDOA(ctx context.Context) (doa.Reading, error) {
+	return u.worker.Latest()
+}
+
+// readDOA reads the direction of arrival from the device (worker only)
+func (u *USBSource) readDOA() (doa.Reading, error) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
 	if u.closed {
-		return doa.Reading{}, fmt.Errorf("device closed")
+		return doa.Reading{}, ErrClosed
 	}
 
 	// Check if we need to reconnect
@@ -156,9 +336,8 @@ func (u *USBSource) GetDOA(ctx context.Context) (doa.Reading, error) {
 	// wIndex: resid
 	data := make([]byte, 9) // 1 status byte + 2 floats (4 bytes each)
 
-	n, err := u.dev.Control(
+	n, err := u.control(cmdDOA,
 		gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice,
-		0,             // bRequest
 		0x80|doaCmdID, // wValue (read flag | cmdid)
 		gpoResID,      // wIndex (resid)
 		data,          // data buffer
@@ -213,9 +392,8 @@ func (u *USBSource) GetDOA(ctx context.Context) (doa.Reading, error) {
 func (u *USBSource) readEnhancedData() (energy [4]float64, azimuths [4]float64) {
 	// Read AEC_SPENERGY_VALUES (4 floats)
 	energyData := make([]byte, 17) // 1 status + 4 floats
-	n, err := u.dev.Control(
+	n, err := u.control(cmdSpEnergy,
 		gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice,
-		0,
 		0x80|aecSpEnergyCmdID,
 		aecResID,
 		energyData,
@@ -229,9 +407,8 @@ func (u *USBSource) readEnhancedData() (energy [4]float64, azimuths [4]float64)
 
 	// Read AEC_AZIMUTH_VALUES (4 floats in radians)
 	azimuthData := make([]byte, 17) // 1 status + 4 floats
-	n, err = u.dev.Control(
+	n, err = u.control(cmdAzimuth,
 		gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice,
-		0,
 		0x80|aecAzimuthCmdID,
 		aecResID,
 		azimuthData,
@@ -246,6 +423,21 @@ func (u *USBSource) readEnhancedData() (energy [4]float64, azimuths [4]float64)
 	return energy, azimuths
 }
 
+// control performs a vendor control transfer (bRequest 0) and records its
+// latency and outcome under command (caller holds mu)
+func (u *USBSource) control(command string, rType uint8, val, idx uint16, data []byte) (int, error) {
+	start := time.Now()
+	n, err := u.dev.Control(rType, 0, val, idx, data)
+
+	// Reads lead with the device status byte
+	var status byte
+	if err == nil && rType&gousb.ControlIn != 0 && n > 0 {
+		status = data[0]
+	}
+	u.observeTransfer(command, time.Since(start), err, status)
+	return n, err
+}
+
 // sumEnergy calculates total speech energy across all mics
 func sumEnergy(energy [4]float64) float64 {
 	var total float64
@@ -301,7 +493,9 @@ func (u *USBSource) reconnect() error {
 	}
 
 	// Try to reopen device
-	if err := u.openDevice(); err != nil {
+	err := u.openDevice()
+	u.observeReconnect(err)
+	if err != nil {
 		u.logger.Warn("USB reconnect failed", "error", err)
 		return err
 	}
@@ -310,8 +504,10 @@ func (u *USBSource) reconnect() error {
 	return nil
 }
 
-// Close releases the USB device
+// Close stops the worker and releases the USB device
 func (u *USBSource) Close() error {
+	u.worker.Stop()
+
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
@@ -357,6 +553,7 @@ func (u *USBSource) Stats() USBStats {
 	if u.lastError != nil {
 		lastErr = u.lastError.Error()
 	}
+	transfers, statusErrors := u.transferSnapshot()
 
 	return USBStats{
 		Healthy:           u.healthy,
@@ -364,6 +561,16 @@ func (u *USBSource) Stats() USBStats {
 		LastError:         lastErr,
 		LastErrorTime:     u.lastErrorTime,
 		DeviceConnected:   u.dev != nil,
+		Reads:             u.worker.reads.Load(),
+		ReadErrors:        u.worker.readErrors.Load(),
+		LastReadMs:        float64(u.worker.readTimeNs.Load()) / float64(time.Millisecond),
+		QueueLength:       len(u.worker.jobs),
+		QueueFull:         u.worker.queueFull.Load(),
+		Reconnects:        u.reconnects,
+		ReconnectFailures: u.reconnectFailures,
+		Firmware:          u.firmware,
+		Transfers:         transfers,
+		StatusErrors:      statusErrors,
 	}
 }
 
@@ -374,4 +581,111 @@ type USBStats struct {
 	LastError         string    `json:"last_error,omitempty"`
 	LastErrorTime     time.Time `json:"last_error_time,omitempty"`
 	DeviceConnected   bool      `json:"device_connected"`
+	Reads             uint64    `json:"reads"`
+	ReadErrors        uint64    `json:"read_errors"`
+	LastReadMs        float64   `json:"last_read_ms"` // Duration of the worker's latest DOA read
+	QueueLength       int       `json:"queue_length"`
+	QueueFull         uint64    `json:"queue_full"`
+	Reconnects        uint64    `json:"reconnects"`
+	ReconnectFailures uint64    `json:"reconnect_failures"`
+
+	Firmware     FirmwareInfo             `json:"firmware"`
+	Transfers    map[string]TransferStats `json:"transfers"`               // By command
+	StatusErrors map[string]uint64        `json:"status_errors,omitempty"` // By device status code
+}
+
+// ReadParam reads a control parameter (status byte followed by the values)
+func (u *USBSource) ReadParam(ctx context.Context, p Param) ([]float64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	var values []float64
+	err := u.worker.Do(ctx, func() error {
+		var err error
+		values, err = u.readParam(p)
+		return err
+	})
+	return values, err
+}
+
+// readParam performs a parameter read (worker only)
+func (u *USBSource) readParam(p Param) ([]float64, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 1+p.Type.Size()*p.Count)
+	n, err := u.control(cmdParamRead,
+		gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice,
+		0x80|uint16(p.CmdID), // read flag | cmdid
+		p.ResID,
+		data,
+	)
+	if err != nil {
+		u.recordError(err)
+		return nil, fmt.Errorf("USB control read failed: %w", err)
+	}
+	if n < len(data) {
+		return nil, fmt.Errorf("short read: got %d bytes, expected %d", n, len(data))
+	}
+	if data[0] != 0 {
+		return nil, fmt.Errorf("device returned error status: %d", data[0])
+	}
+
+	return DecodeParamValues(p.Type, data[1:], p.Count)
+}
+
+// WriteParam writes a control parameter
+func (u *USBSource) WriteParam(ctx context.Context, p Param, values []float64) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(values) != p.Count {
+		return fmt.Errorf("expected %d values, got %d", p.Count, len(values))
+	}
+
+	payload, err := EncodeParamValues(p.Type, values)
+	if err != nil {
+		return err
+	}
+
+	return u.worker.Do(ctx, func() error {
+		return u.writeParam(p, payload)
+	})
+}
+
+// writeParam performs a parameter write (worker only)
+func (u *USBSource) writeParam(p Param, payload []byte) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.ensureOpen(); err != nil {
+		return err
+	}
+
+	if _, err := u.control(cmdParamWrite,
+		gousb.ControlOut|gousb.ControlVendor|gousb.ControlDevice,
+		uint16(p.CmdID),
+		p.ResID,
+		payload,
+	); err != nil {
+		u.recordError(err)
+		return fmt.Errorf("USB control write failed: %w", err)
+	}
+	return nil
+}
+
+// ensureOpen reconnects if needed (caller holds mu)
+func (u *USBSource) ensureOpen() error {
+	if u.closed {
+		return ErrClosed
+	}
+	if u.dev == nil {
+		return u.reconnect()
+	}
+	return nil
 }
@@ -1,8 +1,12 @@
 package xvf3800
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestDefaultUSBSourceConfig(t *testing.T) {
@@ -55,3 +59,64 @@ func TestUSBSourceConstants(t *testing.T) {
 	}
 }
 
+
+func TestFirmwareInfo(t *testing.T) {
+	fw := FirmwareInfo{
+		Version:  "2.0.1",
+		Build:    cString([]byte("release build\x00\x00\x00")),
+		RepoHash: cString([]byte("3f2a9c1\x00garbage")),
+	}
+	if fw.Build != "release build" || fw.RepoHash != "3f2a9c1" {
+		t.Errorf("unexpected registers %+v", fw)
+	}
+	if got := fw.String(); got != "2.0.1 (release build) 3f2a9c1" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (FirmwareInfo{Version: "2.0.1"}).String(); got != "2.0.1" {
+		t.Errorf("String() without build = %q", got)
+	}
+}
+
+func TestUSBSourceTransferStats(t *testing.T) {
+	m := NewUSBMetrics()
+	u := &USBSource{metrics: m}
+
+	u.observeTransfer(cmdDOA, 2*time.Millisecond, nil, 0)
+	u.observeTransfer(cmdDOA, 4*time.Millisecond, nil, 3)
+	u.observeTransfer(cmdSpEnergy, time.Millisecond, errors.New("pipe"), 0)
+	u.observeReconnect(errors.New("not found"))
+	u.observeReconnect(nil)
+
+	transfers, status := u.transferSnapshot()
+	doaStats := transfers[cmdDOA]
+	if doaStats.Count != 2 || doaStats.Errors != 1 || doaStats.AvgMs != 3 || doaStats.MaxMs != 4 {
+		t.Errorf("doa stats = %+v, want 2 transfers, 1 error, 3ms avg, 4ms max", doaStats)
+	}
+	if transfers[cmdSpEnergy].Errors != 1 {
+		t.Errorf("spenergy errors = %d, want 1", transfers[cmdSpEnergy].Errors)
+	}
+	if status["3"] != 1 || len(status) != 1 {
+		t.Errorf("status errors = %v, want map[3:1]", status)
+	}
+	if u.reconnects != 2 || u.reconnectFailures != 1 {
+		t.Errorf("reconnects = %d (%d failed), want 2 (1 failed)", u.reconnects, u.reconnectFailures)
+	}
+
+	m.RegisterMetrics(prometheus.NewRegistry())
+	for _, tc := range []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{`go_eva_xvf3800_usb_transfer_errors_total{command="spenergy"}`, m.transferErrors.WithLabelValues(cmdSpEnergy), 1},
+		{`go_eva_xvf3800_status_errors_total{command="doa",code="3"}`, m.statusErrors.WithLabelValues(cmdDOA, "3"), 1},
+		{`go_eva_xvf3800_reconnects_total{result="failed"}`, m.reconnects.WithLabelValues("failed"), 1},
+	} {
+		if got := testutil.ToFloat64(tc.c); got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if n := testutil.CollectAndCount(m.transferLatency); n != 2 {
+		t.Errorf("transfer latency has %d commands, want 2", n)
+	}
+}
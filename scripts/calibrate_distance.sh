@@ -6,6 +6,8 @@ set -e
 
 ROBOT_IP="${ROBOT_IP:-192.168.68.63}"
 API_URL="http://${ROBOT_IP}:9000/api/audio/doa"
+CURL_AUTH=()
+[ -n "${EVA_API_KEY}" ] && CURL_AUTH=(-H "X-API-Key: ${EVA_API_KEY}")
 SAMPLES_PER_DISTANCE=20
 OUTPUT_FILE="calibration_data.json"
 
@@ -30,7 +32,7 @@ collect_samples() {
     sleep 1  # Give user time to start speaking
     
     for i in $(seq 1 $SAMPLES_PER_DISTANCE); do
-        result=$(curl -s "${API_URL}" 2>/dev/null)
+        result=$(curl -s "${CURL_AUTH[@]}" "${API_URL}" 2>/dev/null)
         speaking=$(echo "$result" | python3 -c "import sys,json; d=json.load(sys.stdin); print(d.get('speaking', False))" 2>/dev/null || echo "false")
         energy=$(echo "$result" | python3 -c "import sys,json; d=json.load(sys.stdin); print(d.get('total_energy', 0))" 2>/dev/null || echo "0")
         
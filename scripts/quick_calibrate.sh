@@ -4,6 +4,8 @@
 
 ROBOT_IP="${ROBOT_IP:-192.168.68.63}"
 API_URL="http://${ROBOT_IP}:9000/api/audio/doa"
+CURL_AUTH=()
+[ -n "${EVA_API_KEY}" ] && CURL_AUTH=(-H "X-API-Key: ${EVA_API_KEY}")
 DISTANCE="${1:-1.0}"
 DURATION=10
 
@@ -18,7 +20,7 @@ echo ""
 valid_energies=()
 
 for i in $(seq 1 $((DURATION * 4))); do
-    result=$(curl -s "${API_URL}" 2>/dev/null)
+    result=$(curl -s "${CURL_AUTH[@]}" "${API_URL}" 2>/dev/null)
     speaking=$(echo "$result" | python3 -c "import sys,json; d=json.load(sys.stdin); print(d.get('speaking', False))" 2>/dev/null || echo "false")
     energy=$(echo "$result" | python3 -c "import sys,json; d=json.load(sys.stdin); print(d.get('total_energy', 0))" 2>/dev/null || echo "0")
     angle=$(echo "$result" | python3 -c "import sys,json; d=json.load(sys.stdin); print(f\"{d.get('angle', 0):.2f}\")" 2>/dev/null || echo "0")
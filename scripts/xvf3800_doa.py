@@ -0,0 +1,65 @@
+#!/usr/bin/env python3
+"""XVF3800 DOA reader for go-eva's Python fallback source.
+
+Reads DOA, speech energy and per-mic azimuths over USB control transfers
+(pyusb) and prints one JSON object per line:
+
+    {"angle": 1.57, "speaking": true, "energy": [..4..], "azimuths": [..4..]}
+
+The angle is in XVF3800 coordinates (radians); go-eva converts it.
+Install: pip3 install pyusb
+"""
+
+import json
+import struct
+import sys
+import time
+
+import usb.core
+
+VENDOR_ID = 0x38FB
+PRODUCT_ID = 0x1001
+
+GPO_RESID = 20
+DOA_CMDID = 19  # DOA_VALUE_RADIANS: angle + speech flag
+AEC_RESID = 33
+AEC_AZIMUTH_CMDID = 75  # 4 floats (radians)
+AEC_SPENERGY_CMDID = 80  # 4 floats
+
+RATE_HZ = 20
+
+
+def read_floats(dev, resid, cmdid, count):
+    """Read count little-endian floats; the first byte is a status code."""
+    data = dev.ctrl_transfer(0xC0, 0, 0x80 | cmdid, resid, 1 + 4 * count, timeout=200)
+    if len(data) < 1 + 4 * count or data[0] != 0:
+        return None
+    return list(struct.unpack("<%df" % count, bytes(data[1:1 + 4 * count])))
+
+
+def main():
+    dev = usb.core.find(idVendor=VENDOR_ID, idProduct=PRODUCT_ID)
+    if dev is None:
+        print("XVF3800 not found", file=sys.stderr)
+        return 1
+
+    interval = 1.0 / RATE_HZ
+    while True:
+        start = time.monotonic()
+
+        doa = read_floats(dev, GPO_RESID, DOA_CMDID, 2)
+        if doa is not None:
+            energy = read_floats(dev, AEC_RESID, AEC_SPENERGY_CMDID, 4) or [0.0] * 4
+            azimuths = read_floats(dev, AEC_RESID, AEC_AZIMUTH_CMDID, 4) or [0.0] * 4
+            print(json.dumps({
+                "angle": doa[0],
+                "speaking": doa[1] != 0,
+                "energy": energy,
+                "azimuths": azimuths,
+            }), flush=True)
+
+        time.sleep(max(0.0, interval - (time.monotonic() - start)))
+
+
+if __name__ == "__main__":
+    sys.exit(main())